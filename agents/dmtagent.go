@@ -208,6 +208,7 @@ func (self *DiameterAgent) handlerCCR(c diam.Conn, m *diam.Message) {
 		utils.Logger.Err(fmt.Sprintf("<DiameterAgent> Unmarshaling message: %s, error: %s", m, err))
 		return
 	}
+	utils.ClockSkews.Observe(ccr.OriginHost, ccr.EventTimestamp)
 	cca := NewBareCCAFromCCR(ccr, self.cgrCfg.DiameterAgentCfg().OriginHost, self.cgrCfg.DiameterAgentCfg().OriginRealm)
 	var processed, lclProcessed bool
 	processorVars := make(map[string]string) // Shared between processors
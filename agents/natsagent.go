@@ -0,0 +1,134 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+	"github.com/cgrates/rpcclient"
+	"github.com/nats-io/nats.go"
+)
+
+func NewNatsAgent(cgrCfg *config.CGRConfig, cdrs rpcclient.RpcClientConnection) *NatsAgent {
+	return &NatsAgent{cgrCfg: cgrCfg, cdrs: cdrs}
+}
+
+// NatsAgent consumes the CDRs published on a NATS subject and passes them to CDRS
+type NatsAgent struct {
+	cgrCfg *config.CGRConfig             // reference for future config reloads
+	cdrs   rpcclient.RpcClientConnection // Connection towards CGR-CDRS component
+	conn   *nats.Conn
+}
+
+// ListenAndServe subscribes to the subject and blocks until the connection to the server is closed
+func (na *NatsAgent) ListenAndServe() (err error) {
+	cfg := na.cgrCfg.NatsAgentCfg()
+	closed := make(chan struct{})
+	if na.conn, err = nats.Connect(cfg.ServerURL, nats.Name(utils.CGRateS), nats.MaxReconnects(-1),
+		nats.ClosedHandler(func(*nats.Conn) { close(closed) })); err != nil {
+		return
+	}
+	if cfg.JetStream {
+		var js nats.JetStreamContext
+		if js, err = na.conn.JetStream(); err != nil {
+			na.conn.Close()
+			return
+		}
+		// durable consumer so CDRs published while we are down are delivered on reconnect
+		_, err = js.Subscribe(cfg.Subject, na.handleMessage, nats.Durable(cfg.ConsumerName), nats.ManualAck())
+	} else {
+		_, err = na.conn.QueueSubscribe(cfg.Subject, cfg.ConsumerName, na.handleMessage)
+	}
+	if err != nil {
+		na.conn.Close()
+		return
+	}
+	utils.Logger.Info(fmt.Sprintf("<NatsAgent> Consuming CDRs on subject <%s> from <%s>", cfg.Subject, cfg.ServerURL))
+	<-closed
+	return fmt.Errorf("connection to <%s> closed", cfg.ServerURL)
+}
+
+// handleMessage processes one CDR, JetStream messages are acknowledged once CDRS has stored them
+func (na *NatsAgent) handleMessage(msg *nats.Msg) {
+	cdr, err := na.cdrFromMessage(msg.Data)
+	if err != nil { // a malformed CDR will not get better on redelivery
+		utils.Logger.Err(fmt.Sprintf("<NatsAgent> error: <%s> ignoring message: %s", err.Error(), string(msg.Data)))
+		na.ack(msg)
+		return
+	}
+	var reply string
+	if err = na.cdrs.Call("CdrsV1.ProcessCDR", cdr, &reply); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<NatsAgent> error: <%s> processing CDR: %s", err.Error(), utils.ToJSON(cdr)))
+		if na.cgrCfg.NatsAgentCfg().JetStream {
+			msg.Nak() // redelivered later
+		}
+		return
+	}
+	na.ack(msg)
+}
+
+func (na *NatsAgent) ack(msg *nats.Msg) {
+	if !na.cgrCfg.NatsAgentCfg().JetStream {
+		return
+	}
+	if err := msg.Ack(); err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<NatsAgent> error: <%s> acknowledging message", err.Error()))
+	}
+}
+
+// cdrFromMessage decodes the message content according to the configured cdr_format
+func (na *NatsAgent) cdrFromMessage(content []byte) (cdr *engine.CDR, err error) {
+	switch na.cgrCfg.NatsAgentCfg().CDRFormat {
+	case utils.MetaJSONCDR: // as exported by *nats_json_cdr
+		cdr = new(engine.CDR)
+		if err = json.Unmarshal(content, cdr); err != nil {
+			return nil, err
+		}
+	case utils.MetaJSONMap: // flat event with CDR field names as keys
+		var ev map[string]string
+		if err = json.Unmarshal(content, &ev); err != nil {
+			return nil, err
+		}
+		timezone := utils.FirstNonEmpty(na.cgrCfg.NatsAgentCfg().Timezone, na.cgrCfg.DefaultTimezone)
+		cdr = &engine.CDR{ExtraFields: make(map[string]string), Cost: -1}
+		for fldName, fldVal := range ev {
+			switch fldName {
+			case utils.CGRID:
+				cdr.CGRID = fldVal
+			case utils.CDRHOST:
+				cdr.OriginHost = fldVal
+			case utils.CDRSOURCE:
+				cdr.Source = fldVal
+			default:
+				if err = cdr.ParseFieldValue(fldName, fldVal, timezone); err != nil {
+					return nil, err
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cdr_format: <%s>", na.cgrCfg.NatsAgentCfg().CDRFormat)
+	}
+	if cdr.CGRID == "" {
+		cdr.ComputeCGRID()
+	}
+	return
+}
@@ -0,0 +1,56 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestNatsAgentCdrFromMessage(t *testing.T) {
+	cgrCfg, _ := config.NewDefaultCGRConfig()
+	na := NewNatsAgent(cgrCfg, nil)
+	eCdr := &engine.CDR{OriginID: "dsafdsaf", ToR: utils.VOICE, RequestType: utils.META_PREPAID, Tenant: "cgrates.org",
+		Account: "1001", Destination: "1002", SetupTime: time.Date(2017, 5, 10, 13, 0, 0, 0, time.UTC),
+		Usage: 35 * time.Second, ExtraFields: map[string]string{"field_extr1": "val_extr1"}, Cost: -1}
+	cdr, err := na.cdrFromMessage([]byte(utils.ToJSON(eCdr)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eCdr.ComputeCGRID()
+	if cdr.CGRID != eCdr.CGRID || cdr.Usage != eCdr.Usage || cdr.ExtraFields["field_extr1"] != "val_extr1" {
+		t.Errorf("Unexpected CDR: %s", utils.ToJSON(cdr))
+	}
+	cgrCfg.NatsAgentCfg().CDRFormat = utils.MetaJSONMap
+	cdr, err = na.cdrFromMessage([]byte(`{"OriginID": "dsafdsaf", "ToR": "*voice", "Account": "1001", "Destination": "1002",
+		"SetupTime": "2017-05-10T13:00:00Z", "Usage": "35", "Source": "nats", "field_extr1": "val_extr1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cdr.CGRID != eCdr.CGRID || cdr.Usage != eCdr.Usage || cdr.Source != "nats" || cdr.Cost != -1 ||
+		cdr.ExtraFields["field_extr1"] != "val_extr1" {
+		t.Errorf("Unexpected CDR: %s", utils.ToJSON(cdr))
+	}
+	if _, err = na.cdrFromMessage([]byte(`{"Usage": "abc"}`)); err == nil {
+		t.Error("Expecting usage parsing error")
+	}
+}
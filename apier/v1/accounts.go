@@ -347,6 +347,157 @@ func (self *ApierV1) RemoveAccount(attr utils.AttrRemoveAccount, reply *string)
 	return nil
 }
 
+type AttrMergeAccounts struct {
+	SrcTenant    string
+	SrcAccount   string
+	DstTenant    string
+	DstAccount   string
+	AnnotateCDRs bool // mark the CDRs of the source account with the destination one
+}
+
+// MergeAccounts moves balances, triggers, action plans and aliases of the source account into the destination one, removing the source
+func (self *ApierV1) MergeAccounts(attr AttrMergeAccounts, reply *string) (err error) {
+	if missing := utils.MissingStructFields(&attr, []string{"SrcTenant", "SrcAccount", "DstTenant", "DstAccount"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	srcID := utils.AccountKey(attr.SrcTenant, attr.SrcAccount)
+	dstID := utils.AccountKey(attr.DstTenant, attr.DstAccount)
+	if srcID == dstID {
+		return errors.New("SAME_ACCOUNT")
+	}
+	_, err = guardian.Guardian.Guard(func() (interface{}, error) {
+		srcAcnt, err := self.DataDB.GetAccount(srcID)
+		if err != nil {
+			return 0, err
+		}
+		dstAcnt, err := self.DataDB.GetAccount(dstID)
+		if err == utils.ErrNotFound {
+			dstAcnt = &engine.Account{ID: dstID}
+		} else if err != nil {
+			return 0, err
+		}
+		dstAcnt.Merge(srcAcnt)
+		if err := self.DataDB.SetAccount(dstAcnt); err != nil {
+			return 0, err
+		}
+		// re-point the action plans
+		if _, err := guardian.Guardian.Guard(func() (interface{}, error) {
+			actionPlansMap, err := self.DataDB.GetAllActionPlans()
+			if err == utils.ErrNotFound {
+				return 0, nil
+			} else if err != nil {
+				return 0, err
+			}
+			for actionPlanID, ap := range actionPlansMap {
+				if _, exists := ap.AccountIDs[srcID]; !exists {
+					continue
+				}
+				delete(ap.AccountIDs, srcID)
+				ap.AccountIDs[dstID] = true
+				if err := self.DataDB.SetActionPlan(actionPlanID, ap, true, utils.NonTransactional); err != nil {
+					return 0, err
+				}
+			}
+			return 0, nil
+		}, 0, utils.ACTION_PLAN_PREFIX); err != nil {
+			return 0, err
+		}
+		if err := self.DataDB.RemoveAccount(srcID); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}, 0, srcID, dstID)
+	if err != nil {
+		if err == utils.ErrNotFound {
+			return err
+		}
+		return utils.NewErrServerError(err)
+	}
+	if apIDs, err := self.DataDB.GetAccountActionPlans(srcID, true, utils.NonTransactional); err == nil {
+		if err = self.DataDB.SetAccountActionPlans(dstID, apIDs, false); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	} else if err != utils.ErrNotFound {
+		return utils.NewErrServerError(err)
+	}
+	if err = self.DataDB.RemAccountActionPlans(srcID, nil); err != nil && err != utils.ErrNotFound {
+		return utils.NewErrServerError(err)
+	}
+	if err = self.DataDB.CacheDataFromDB(utils.AccountActionPlansPrefix, []string{srcID, dstID}, true); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if err = self.mergeAccountAliases(attr); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if attr.AnnotateCDRs {
+		cdrs, _, err := self.CdrDb.GetCDRs(&utils.CDRsFilter{Tenants: []string{attr.SrcTenant}, Accounts: []string{attr.SrcAccount}}, false)
+		if err != nil && err != utils.ErrNotFound {
+			return utils.NewErrServerError(err)
+		}
+		for _, cdr := range cdrs {
+			if cdr.ExtraFields == nil {
+				cdr.ExtraFields = make(map[string]string)
+			}
+			cdr.ExtraFields[utils.MergedIntoAccount] = dstID
+			if err := self.CdrDb.SetCDR(cdr, true); err != nil {
+				return utils.NewErrServerError(err)
+			}
+		}
+	}
+	*reply = OK
+	return nil
+}
+
+// mergeAccountAliases moves the aliases defined for the source account to the destination one
+// and replaces the source account as alias value
+func (self *ApierV1) mergeAccountAliases(attr AttrMergeAccounts) error {
+	keys, err := self.DataDB.GetKeysForPrefix(utils.ALIASES_PREFIX)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		al, err := self.DataDB.GetAlias(key[len(utils.ALIASES_PREFIX):], true, utils.NonTransactional)
+		if err != nil {
+			return err
+		}
+		oldID := al.GetId()
+		var changed bool
+		if al.Tenant == attr.SrcTenant && al.Account == attr.SrcAccount {
+			al.Tenant, al.Account = attr.DstTenant, attr.DstAccount
+			changed = true
+		}
+		if al.Tenant == attr.SrcTenant || al.Tenant == attr.DstTenant {
+			for _, value := range al.Values {
+				for orig, alias := range value.Pairs[utils.ACCOUNT] {
+					if alias == attr.SrcAccount {
+						value.Pairs[utils.ACCOUNT][orig] = attr.DstAccount
+						changed = true
+					}
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := self.DataDB.RemoveAlias(oldID, utils.NonTransactional); err != nil {
+			return err
+		}
+		if err := self.DataDB.SetAlias(al, utils.NonTransactional); err != nil {
+			return err
+		}
+		if err := self.DataDB.SetReverseAlias(al, utils.NonTransactional); err != nil {
+			return err
+		}
+		if err := self.DataDB.CacheDataFromDB(utils.ALIASES_PREFIX, []string{al.GetId()}, true); err != nil {
+			return err
+		}
+		if err := self.DataDB.CacheDataFromDB(utils.REVERSE_ALIASES_PREFIX, al.ReverseAliasIDs(), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (self *ApierV1) GetAccounts(attr utils.AttrGetAccounts, reply *[]interface{}) error {
 	if len(attr.Tenant) == 0 {
 		return utils.NewErrMandatoryIeMissing("Tenant")
@@ -417,6 +568,7 @@ type AttrAddBalance struct {
 	Overwrite      bool // When true it will reset if the balance is already there
 	Blocker        *bool
 	Disabled       *bool
+	Unit           *string // usage unit of the Value (eg: MB), empty for the base units
 }
 
 func (self *ApierV1) AddBalance(attr *AttrAddBalance, reply *string) error {
@@ -468,6 +620,7 @@ func (self *ApierV1) modifyBalance(aType string, attr *AttrAddBalance, reply *st
 			Weight:         attr.Weight,
 			Blocker:        attr.Blocker,
 			Disabled:       attr.Disabled,
+			Unit:           attr.Unit,
 		},
 	}
 	if attr.Directions != nil {
@@ -535,6 +688,7 @@ func (self *ApierV1) SetBalance(attr *utils.AttrSetBalance, reply *string) error
 			Weight:         attr.Weight,
 			Blocker:        attr.Blocker,
 			Disabled:       attr.Disabled,
+			Unit:           attr.Unit,
 		},
 	}
 	if attr.Value != nil {
@@ -595,6 +749,7 @@ func (self *ApierV1) RemoveBalances(attr *utils.AttrSetBalance, reply *string) e
 			Weight:         attr.Weight,
 			Blocker:        attr.Blocker,
 			Disabled:       attr.Disabled,
+			Unit:           attr.Unit,
 		},
 	}
 	if attr.Value != nil {
@@ -52,6 +52,16 @@ type ApierV1 struct {
 	CDRs        rpcclient.RpcClientConnection // FixMe: populate it from cgr-engine
 	ServManager *servmanager.ServiceManager   // Need to have them capitalize so we can export in V2
 	HTTPPoster  *utils.HTTPPoster
+	TpReaders   *engine.TpReaderPool // readers for the loads out of StorDb
+	Jobs        *utils.JobManager    // asynchronous jobs started over APIs
+}
+
+// tpReaderPool returns the pool serving the loads out of StorDb, a new one if not configured
+func (self *ApierV1) tpReaderPool() *engine.TpReaderPool {
+	if self.TpReaders == nil {
+		return engine.NewTpReaderPool(self.DataDB, self.StorDb, self.Config.DefaultTimezone, self.Config.TenantTimezones)
+	}
+	return self.TpReaders
 }
 
 func (self *ApierV1) GetDestination(dstId string, reply *engine.Destination) error {
@@ -168,11 +178,27 @@ func (self *ApierV1) SetDestination(attrs utils.AttrSetDestination, reply *strin
 }
 
 func (self *ApierV1) GetRatingPlan(rplnId string, reply *engine.RatingPlan) error {
-	if rpln, err := self.DataDB.GetRatingPlan(rplnId, false, utils.NonTransactional); err != nil {
+	rpln, err := self.DataDB.GetRatingPlan(rplnId, false, utils.NonTransactional)
+	if err != nil {
 		return utils.ErrNotFound
-	} else {
-		*reply = *rpln
 	}
+	if rpln, err = rpln.Unsplit(); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = *rpln
+	return nil
+}
+
+// GetRatingPlanSanityReport returns all the invalid ratings, timings and weekday gaps of a rating plan
+func (self *ApierV1) GetRatingPlanSanityReport(rplnId string, reply *engine.RatingPlanSanityReport) error {
+	rpln, err := self.DataDB.GetRatingPlan(rplnId, false, utils.NonTransactional)
+	if err != nil {
+		return utils.ErrNotFound
+	}
+	if rpln, err = rpln.Unsplit(); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = *rpln.SanityReport()
 	return nil
 }
 
@@ -202,6 +228,7 @@ func (self *ApierV1) LoadDestination(attrs AttrLoadDestination, reply *string) e
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if loaded, err := dbReader.LoadDestinationsFiltered(attrs.ID); err != nil {
 		return utils.NewErrServerError(err)
 	} else if !loaded {
@@ -220,6 +247,7 @@ func (self *ApierV1) LoadDerivedChargers(attrs utils.TPDerivedChargers, reply *s
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if err := dbReader.LoadDerivedChargersFiltered(&attrs, true); err != nil {
 		return utils.NewErrServerError(err)
 	}
@@ -241,6 +269,7 @@ func (self *ApierV1) LoadRatingPlan(attrs AttrLoadRatingPlan, reply *string) err
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if loaded, err := dbReader.LoadRatingPlansFiltered(attrs.RatingPlanId); err != nil {
 		return utils.NewErrServerError(err)
 	} else if !loaded {
@@ -259,6 +288,7 @@ func (self *ApierV1) LoadRatingProfile(attrs utils.TPRatingProfile, reply *strin
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if err := dbReader.LoadRatingProfilesFiltered(&attrs); err != nil {
 		return utils.NewErrServerError(err)
 	}
@@ -269,6 +299,52 @@ func (self *ApierV1) LoadRatingProfile(attrs utils.TPRatingProfile, reply *strin
 	return nil
 }
 
+type AttrReloadRatingProfile struct {
+	TPid            string
+	RatingProfileID string // eg: *out:cgrates.org:call:1001
+}
+
+// Reload one rating profile together with the rating plans, rates and destinations it references, from storDb into dataDb
+func (self *ApierV1) ReloadRatingProfile(attrs AttrReloadRatingProfile, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "RatingProfileID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
+	if err := dbReader.ReloadRatingProfile(attrs.RatingProfileID); err != nil {
+		if err == utils.ErrNotFound {
+			return err
+		}
+		return utils.NewErrServerError(err)
+	}
+	if err := self.DataDB.CacheDataFromDB(utils.RATING_PROFILE_PREFIX, []string{attrs.RatingProfileID}, true); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = OK
+	return nil
+}
+
+type AttrLintTariffPlan struct {
+	TPid string
+}
+
+// LintTariffPlan reports the objects of a TP in storDb which are defined but never referenced
+func (self *ApierV1) LintTariffPlan(attrs AttrLintTariffPlan, reply *[]string) error {
+	if len(attrs.TPid) == 0 {
+		return utils.NewErrMandatoryIeMissing("TPid")
+	}
+	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	issues, err := dbReader.Lint()
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if issues == nil {
+		issues = make([]string, 0)
+	}
+	*reply = issues
+	return nil
+}
+
 type AttrLoadSharedGroup struct {
 	TPid          string
 	SharedGroupId string
@@ -280,6 +356,7 @@ func (self *ApierV1) LoadSharedGroup(attrs AttrLoadSharedGroup, reply *string) e
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if err := dbReader.LoadSharedGroupsFiltered(attrs.SharedGroupId, true); err != nil {
 		return utils.NewErrServerError(err)
 	}
@@ -301,6 +378,7 @@ func (self *ApierV1) LoadCdrStats(attrs AttrLoadCdrStats, reply *string) error {
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if err := dbReader.LoadCdrStatsFiltered(attrs.CdrStatsId, true); err != nil {
 		return utils.NewErrServerError(err)
 	}
@@ -308,69 +386,91 @@ func (self *ApierV1) LoadCdrStats(attrs AttrLoadCdrStats, reply *string) error {
 	return nil
 }
 
-type AttrLoadTpFromStorDb struct {
-	TPid     string
-	FlushDb  bool // Flush dataDB before loading
-	DryRun   bool // Only simulate, no write
-	Validate bool // Run structural checks
+type AttrLoadResourceLimit struct {
+	TPid            string
+	ResourceLimitID string
 }
 
-// Loads complete data in a TP from storDb
-func (self *ApierV1) LoadTariffPlanFromStorDb(attrs AttrLoadTpFromStorDb, reply *string) error {
+// Load ResourceLimits from storDb into dataDb, maintaining their indexes.
+func (self *ApierV1) LoadResourceLimit(attrs AttrLoadResourceLimit, reply *string) error {
 	if len(attrs.TPid) == 0 {
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
-	if err := dbReader.LoadAll(); err != nil {
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
+	if err := dbReader.LoadResourceLimitsFiltered(attrs.ResourceLimitID, true); err != nil {
 		return utils.NewErrServerError(err)
 	}
-	if attrs.Validate {
-		if !dbReader.IsValid() {
-			*reply = OK
-			return errors.New("invalid data")
-		}
-	}
-	if attrs.DryRun {
-		*reply = OK
-		return nil // Mission complete, no errors
+	*reply = OK
+	return nil
+}
+
+type AttrLoadTpFromStorDb struct {
+	TPid              string
+	OverlayTPids      []string // Merged on top of TPid, in order
+	ConflictPolicy    string   // Objects defined in more than one TP <*error|*first|*last>, defaults to *error
+	CompressDsts      bool     // Collapse the redundant destination prefixes
+	DisableReverses   []string // Reverse indexes not to be rebuilt <*destinations|*account_action_plans|*aliases>
+	FlushDb           bool     // Flush dataDB before loading
+	DryRun            bool     // Only simulate, no write
+	Validate          bool     // Run structural checks
+	Categories        []string // Load only these categories, eg: *destinations, empty for all
+	ExcludeCategories []string // Categories not to be loaded
+}
+
+// Loads complete data in a TP from storDb
+func (self *ApierV1) LoadTariffPlanFromStorDb(attrs AttrLoadTpFromStorDb, reply *string) (err error) {
+	if len(attrs.TPid) == 0 {
+		return utils.NewErrMandatoryIeMissing("TPid")
 	}
-	if err := dbReader.WriteToDatabase(attrs.FlushDb, false, false); err != nil {
-		return utils.NewErrServerError(err)
+	var diff engine.TPLoadDiff
+	if !attrs.DryRun {
+		engine.NotifyTPLoad(utils.MetaTPLoadStarted, attrs.TPid, nil, nil)
+		defer func() { engine.NotifyTPLoadDone(attrs.TPid, diff, err) }()
 	}
-	utils.Logger.Info("ApierV1.LoadTariffPlanFromStorDb, reloading cache.")
-	for _, prfx := range []string{
-		utils.DESTINATION_PREFIX,
-		utils.REVERSE_DESTINATION_PREFIX,
-		utils.RATING_PLAN_PREFIX,
-		utils.RATING_PROFILE_PREFIX,
-		utils.ACTION_PREFIX,
-		utils.ACTION_PLAN_PREFIX,
-		utils.AccountActionPlansPrefix,
-		utils.ACTION_TRIGGER_PREFIX,
-		utils.SHARED_GROUP_PREFIX,
-		utils.DERIVEDCHARGERS_PREFIX,
-		utils.LCR_PREFIX} {
-		loadedIDs, _ := dbReader.GetLoadedIds(prfx)
-		if err := self.DataDB.CacheDataFromDB(prfx, loadedIDs, true); err != nil {
+	var aps, cstKeys, userKeys []string
+	loadFunc := func(dbReader *engine.TpReader) error {
+		dbReader.SetCompressDestinations(attrs.CompressDsts)
+		if err := dbReader.SetDisabledReverses(attrs.DisableReverses); err != nil {
 			return utils.NewErrServerError(err)
 		}
-	}
-	for _, prfx := range []string{
-		utils.ALIASES_PREFIX,
-		utils.REVERSE_ALIASES_PREFIX,
-		utils.ResourceLimitsPrefix} {
-		loadedIDs, _ := dbReader.GetLoadedIds(prfx)
-		if err := self.DataDB.CacheDataFromDB(prfx, loadedIDs, true); err != nil {
+		if err := dbReader.LoadCategories(attrs.Categories, attrs.ExcludeCategories); err != nil {
+			return utils.NewErrServerError(err)
+		}
+		if attrs.Validate {
+			if !dbReader.IsValid() {
+				*reply = OK
+				return errors.New("invalid data")
+			}
+		}
+		if attrs.DryRun {
+			return nil
+		}
+		if engine.TPLoadNotificationsEnabled() {
+			diff = dbReader.Diff()
+		}
+		utils.Logger.Info("ApierV1.LoadTariffPlanFromStorDb, writing data and reloading cache.")
+		dbReader.SetCacheReload(true, nil) // refresh the local cache for the loaded keys
+		if err := dbReader.WriteToDatabase(attrs.FlushDb, false, false); err != nil {
 			return utils.NewErrServerError(err)
 		}
+		aps, _ = dbReader.GetLoadedIds(utils.ACTION_PLAN_PREFIX)
+		cstKeys, _ = dbReader.GetLoadedIds(utils.CDR_STATS_PREFIX)
+		userKeys, _ = dbReader.GetLoadedIds(utils.USERS_PREFIX)
+		return nil
+	}
+	if len(attrs.OverlayTPids) != 0 {
+		err = self.tpReaderPool().LoadMerged(append([]string{attrs.TPid}, attrs.OverlayTPids...), attrs.ConflictPolicy, loadFunc)
+	} else {
+		err = self.tpReaderPool().Load(attrs.TPid, loadFunc)
+	}
+	if err != nil {
+		return err
+	}
+	if attrs.DryRun {
+		*reply = OK
+		return nil // Mission complete, no errors
 	}
-	aps, _ := dbReader.GetLoadedIds(utils.ACTION_PLAN_PREFIX)
-	cstKeys, _ := dbReader.GetLoadedIds(utils.CDR_STATS_PREFIX)
-	userKeys, _ := dbReader.GetLoadedIds(utils.USERS_PREFIX)
-
-	// relase tp data
-	dbReader.Init()
-
 	if len(aps) != 0 {
 		sched := self.ServManager.GetScheduler()
 		if sched != nil {
@@ -390,10 +490,31 @@ func (self *ApierV1) LoadTariffPlanFromStorDb(attrs AttrLoadTpFromStorDb, reply
 			return err
 		}
 	}
+	engine.NotifyTPLoaded()
 	*reply = OK
 	return nil
 }
 
+type AttrGetTPStatistics struct {
+	TPid              string
+	Categories        []string // Consider only these categories, eg: *destinations, empty for all
+	ExcludeCategories []string // Categories not to be considered
+}
+
+// GetTPStatistics loads the tariff plan from storDb without writing it and returns statistics about it
+func (self *ApierV1) GetTPStatistics(attrs AttrGetTPStatistics, reply *engine.TPStats) error {
+	if len(attrs.TPid) == 0 {
+		return utils.NewErrMandatoryIeMissing("TPid")
+	}
+	return self.tpReaderPool().Load(attrs.TPid, func(dbReader *engine.TpReader) error {
+		if err := dbReader.LoadCategories(attrs.Categories, attrs.ExcludeCategories); err != nil {
+			return utils.NewErrServerError(err)
+		}
+		*reply = *dbReader.Statistics()
+		return nil
+	})
+}
+
 func (self *ApierV1) ImportTariffPlanFromFolder(attrs utils.AttrImportTPFromFolder, reply *string) error {
 	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "FolderPath"}); len(missing) != 0 {
 		return utils.NewErrMandatoryIeMissing(missing...)
@@ -417,6 +538,9 @@ func (self *ApierV1) ImportTariffPlanFromFolder(attrs utils.AttrImportTPFromFold
 		Verbose:  false,
 		ImportId: attrs.RunId,
 	}
+	if len(attrs.Variables) != 0 {
+		csvImporter.Variables = &engine.TPVariables{Values: attrs.Variables}
+	}
 	if err := csvImporter.Run(); err != nil {
 		return utils.NewErrServerError(err)
 	}
@@ -465,7 +589,8 @@ func (self *ApierV1) SetRatingProfile(attrs AttrSetRatingProfile, reply *string)
 			return fmt.Errorf(fmt.Sprintf("%s:RatingPlanId:%s", utils.ErrNotFound.Error(), ra.RatingPlanId))
 		}
 		rpfl.RatingPlanActivations = append(rpfl.RatingPlanActivations, &engine.RatingPlanActivation{ActivationTime: at, RatingPlanId: ra.RatingPlanId,
-			FallbackKeys: utils.FallbackSubjKeys(tpRpf.Direction, tpRpf.Tenant, tpRpf.Category, ra.FallbackSubjects)})
+			FallbackKeys: utils.FallbackSubjKeys(tpRpf.Direction, tpRpf.Tenant, tpRpf.Category, ra.FallbackSubjects),
+			MaxCost:      ra.MaxCost, MaxCostStrategy: ra.MaxCostStrategy, TrafficShare: ra.TrafficShare})
 	}
 	if err := self.DataDB.SetRatingProfile(rpfl, utils.NonTransactional); err != nil {
 		return utils.NewErrServerError(err)
@@ -617,6 +742,7 @@ type AttrActionPlan struct {
 	WeekDays  string  // semicolon separated list of week day names this timing is valid on *any or empty supported
 	Time      string  // String representing the time this timing starts on, *asap supported
 	Weight    float64 // Binding's weight
+	CatchUp   string  // Executions missed while the scheduler was down: <""|*skip|*run_once_on_start|*run_all_missed>
 }
 
 func (self *ApierV1) SetActionPlan(attrs AttrSetActionPlan, reply *string) (err error) {
@@ -628,6 +754,9 @@ func (self *ApierV1) SetActionPlan(attrs AttrSetActionPlan, reply *string) (err
 		if missing := utils.MissingStructFields(at, requiredFields); len(missing) != 0 {
 			return fmt.Errorf("%s:Action:%s:%v", utils.ErrMandatoryIeMissing.Error(), at.ActionsId, missing)
 		}
+		if !utils.IsCatchUpPolicy(at.CatchUp) {
+			return fmt.Errorf("unsupported catch up policy: %s", at.CatchUp)
+		}
 	}
 	_, err = guardian.Guardian.Guard(func() (interface{}, error) {
 		var prevAccountIDs utils.StringMap
@@ -658,6 +787,7 @@ func (self *ApierV1) SetActionPlan(attrs AttrSetActionPlan, reply *string) (err
 				Weight:    apiAtm.Weight,
 				Timing:    &engine.RateInterval{Timing: timing},
 				ActionsID: apiAtm.ActionsId,
+				CatchUp:   apiAtm.CatchUp,
 			})
 		}
 		if err := self.DataDB.SetActionPlan(ap.Id, ap, true, utils.NonTransactional); err != nil {
@@ -724,6 +854,7 @@ func (self *ApierV1) LoadAccountActions(attrs utils.TPAccountActions, reply *str
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if _, err := guardian.Guardian.Guard(func() (interface{}, error) {
 		if err := dbReader.LoadAccountActionsFiltered(&attrs); err != nil {
 			return 0, err
@@ -757,6 +888,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 		cache.Flush()
 		return
 	}
+	prefixIDs := make(map[string][]string) // reloaded as one generation so readers never see it partially loaded
 	// Reload Destinations
 	dataIDs := make([]string, 0)
 	if attrs.DestinationIDs == nil {
@@ -767,9 +899,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.DESTINATION_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.DESTINATION_PREFIX] = dataIDs
 	// Reload ReverseDestinations
 	dataIDs = make([]string, 0)
 	if attrs.ReverseDestinationIDs == nil {
@@ -780,9 +910,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.REVERSE_DESTINATION_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.REVERSE_DESTINATION_PREFIX] = dataIDs
 	// RatingPlans
 	dataIDs = make([]string, 0)
 	if attrs.RatingPlanIDs == nil {
@@ -793,9 +921,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.RATING_PLAN_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.RATING_PLAN_PREFIX] = dataIDs
 	// RatingProfiles
 	dataIDs = make([]string, 0)
 	if attrs.RatingProfileIDs == nil {
@@ -806,9 +932,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.RATING_PROFILE_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.RATING_PROFILE_PREFIX] = dataIDs
 	// Actions
 	dataIDs = make([]string, 0)
 	if attrs.ActionIDs == nil {
@@ -819,9 +943,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.ACTION_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.ACTION_PREFIX] = dataIDs
 	// ActionPlans
 	dataIDs = make([]string, 0)
 	if attrs.ActionPlanIDs == nil {
@@ -832,9 +954,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.ACTION_PLAN_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.ACTION_PLAN_PREFIX] = dataIDs
 	// AccountActionPlans
 	dataIDs = make([]string, 0)
 	if attrs.AccountActionPlanIDs == nil {
@@ -845,9 +965,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.AccountActionPlansPrefix, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.AccountActionPlansPrefix] = dataIDs
 	// ActionTriggers
 	dataIDs = make([]string, 0)
 	if attrs.ActionTriggerIDs == nil {
@@ -858,9 +976,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.ACTION_TRIGGER_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.ACTION_TRIGGER_PREFIX] = dataIDs
 	// SharedGroups
 	dataIDs = make([]string, 0)
 	if attrs.SharedGroupIDs == nil {
@@ -871,9 +987,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.SHARED_GROUP_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.SHARED_GROUP_PREFIX] = dataIDs
 	// LCR Profiles
 	dataIDs = make([]string, 0)
 	if attrs.LCRids == nil {
@@ -884,9 +998,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.LCR_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.LCR_PREFIX] = dataIDs
 	// DerivedChargers
 	dataIDs = make([]string, 0)
 	if attrs.DerivedChargerIDs == nil {
@@ -897,9 +1009,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.DERIVEDCHARGERS_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.DERIVEDCHARGERS_PREFIX] = dataIDs
 	// Aliases
 	dataIDs = make([]string, 0)
 	if attrs.AliasIDs == nil {
@@ -910,9 +1020,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.ALIASES_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.ALIASES_PREFIX] = dataIDs
 	// ReverseAliases
 	dataIDs = make([]string, 0)
 	if attrs.ReverseAliasIDs == nil {
@@ -923,9 +1031,7 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.REVERSE_ALIASES_PREFIX, dataIDs, true); err != nil {
-		return
-	}
+	prefixIDs[utils.REVERSE_ALIASES_PREFIX] = dataIDs
 	// ResourceLimits
 	dataIDs = make([]string, 0)
 	if attrs.ResourceLimitIDs == nil {
@@ -936,7 +1042,8 @@ func (self *ApierV1) ReloadCache(attrs utils.AttrReloadCache, reply *string) (er
 			dataIDs[idx] = dId
 		}
 	}
-	if err = self.DataDB.CacheDataFromDB(utils.ResourceLimitsPrefix, dataIDs, true); err != nil {
+	prefixIDs[utils.ResourceLimitsPrefix] = dataIDs
+	if err = self.DataDB.CacheDataGenerationFromDB(prefixIDs, true); err != nil {
 		return
 	}
 	*reply = utils.OK
@@ -1130,6 +1237,16 @@ func (self *ApierV1) FlushCache(args utils.AttrReloadCache, reply *string) (err
 	return
 }
 
+// GetClockSkews returns the clock skews observed towards the agents/peers sending us timestamps
+func (self *ApierV1) GetClockSkews(ignr string, reply *map[string]*utils.ClockSkewStats) error {
+	skews := utils.ClockSkews.Stats()
+	if len(skews) == 0 {
+		return utils.ErrNotFound
+	}
+	*reply = skews
+	return nil
+}
+
 func (self *ApierV1) GetCacheStats(attrs utils.AttrCacheStats, reply *utils.CacheStats) error {
 	cs := new(utils.CacheStats)
 	cs.Destinations = cache.CountEntries(utils.DESTINATION_PREFIX)
@@ -1145,6 +1262,10 @@ func (self *ApierV1) GetCacheStats(attrs utils.AttrCacheStats, reply *utils.Cach
 	cs.Aliases = cache.CountEntries(utils.ALIASES_PREFIX)
 	cs.ReverseAliases = cache.CountEntries(utils.REVERSE_ALIASES_PREFIX)
 	cs.ResourceLimits = cache.CountEntries(utils.ResourceLimitsPrefix)
+	cs.DestinationsBytes = cache.CountBytes(utils.DESTINATION_PREFIX)
+	cs.DestinationsEvictions = cache.CountEvictions(utils.DESTINATION_PREFIX)
+	cs.RatingPlansBytes = cache.CountBytes(utils.RATING_PLAN_PREFIX)
+	cs.RatingPlansEvictions = cache.CountEvictions(utils.RATING_PLAN_PREFIX)
 	if self.CdrStatsSrv != nil {
 		var queueIds []string
 		if err := self.CdrStatsSrv.Call("CDRStatsV1.GetQueueIds", 0, &queueIds); err != nil {
@@ -1422,7 +1543,7 @@ func (v1 *ApierV1) GetCacheKeys(args utils.ArgsCacheKeys, reply *utils.ArgsCache
 	return
 }
 
-func (self *ApierV1) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder, reply *string) error {
+func (self *ApierV1) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder, reply *string) (err error) {
 	if len(attrs.FolderPath) == 0 {
 		return fmt.Errorf("%s:%s", utils.ErrMandatoryIeMissing.Error(), "FolderPath")
 	}
@@ -1434,7 +1555,12 @@ func (self *ApierV1) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 	} else if !fi.IsDir() {
 		return utils.ErrInvalidPath
 	}
-	loader := engine.NewTpReader(self.DataDB, engine.NewFileCSVStorage(utils.CSV_SEP,
+	var diff engine.TPLoadDiff
+	if !attrs.DryRun {
+		engine.NotifyTPLoad(utils.MetaTPLoadStarted, attrs.FolderPath, nil, nil)
+		defer func() { engine.NotifyTPLoadDone(attrs.FolderPath, diff, err) }()
+	}
+	csvStorage := engine.NewFileCSVStorage(utils.CSV_SEP,
 		path.Join(attrs.FolderPath, utils.DESTINATIONS_CSV),
 		path.Join(attrs.FolderPath, utils.TIMINGS_CSV),
 		path.Join(attrs.FolderPath, utils.RATES_CSV),
@@ -1452,8 +1578,20 @@ func (self *ApierV1) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 		path.Join(attrs.FolderPath, utils.USERS_CSV),
 		path.Join(attrs.FolderPath, utils.ALIASES_CSV),
 		path.Join(attrs.FolderPath, utils.ResourceLimitsCsv),
-	), "", self.Config.DefaultTimezone)
-	if err := loader.LoadAll(); err != nil {
+		path.Join(attrs.FolderPath, utils.ExchangeRatesCsv),
+		path.Join(attrs.FolderPath, utils.HolidayCalendarsCsv),
+		path.Join(attrs.FolderPath, utils.DialPlansCsv),
+	)
+	if len(attrs.Variables) != 0 {
+		csvStorage.SetVariables(&engine.TPVariables{Values: attrs.Variables})
+	}
+	loader := engine.NewTpReader(self.DataDB, csvStorage, "", self.Config.DefaultTimezone)
+	loader.SetTenantTimezones(self.Config.TenantTimezones)
+	loader.SetCompressDestinations(attrs.CompressDsts)
+	if err := loader.SetDisabledReverses(attrs.DisableReverses); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if err := loader.LoadCategories(attrs.Categories, attrs.ExcludeCategories); err != nil {
 		return utils.NewErrServerError(err)
 	}
 	if attrs.DryRun {
@@ -1467,36 +1605,14 @@ func (self *ApierV1) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 		}
 	}
 
+	if engine.TPLoadNotificationsEnabled() {
+		diff = loader.Diff()
+	}
+	utils.Logger.Info("ApierV1.LoadTariffPlanFromFolder, writing data and reloading cache.")
+	loader.SetCacheReload(true, nil) // refresh the local cache for the loaded keys
 	if err := loader.WriteToDatabase(attrs.FlushDb, false, false); err != nil {
 		return utils.NewErrServerError(err)
 	}
-	utils.Logger.Info("ApierV1.LoadTariffPlanFromFolder, reloading cache.")
-	for _, prfx := range []string{
-		utils.DESTINATION_PREFIX,
-		utils.REVERSE_DESTINATION_PREFIX,
-		utils.RATING_PLAN_PREFIX,
-		utils.RATING_PROFILE_PREFIX,
-		utils.ACTION_PREFIX,
-		utils.ACTION_PLAN_PREFIX,
-		utils.AccountActionPlansPrefix,
-		utils.ACTION_TRIGGER_PREFIX,
-		utils.SHARED_GROUP_PREFIX,
-		utils.DERIVEDCHARGERS_PREFIX,
-		utils.LCR_PREFIX} {
-		loadedIDs, _ := loader.GetLoadedIds(prfx)
-		if err := self.DataDB.CacheDataFromDB(prfx, loadedIDs, true); err != nil {
-			return utils.NewErrServerError(err)
-		}
-	}
-	for _, prfx := range []string{
-		utils.ALIASES_PREFIX,
-		utils.REVERSE_ALIASES_PREFIX,
-		utils.ResourceLimitsPrefix} {
-		loadedIDs, _ := loader.GetLoadedIds(prfx)
-		if err := self.DataDB.CacheDataFromDB(prfx, loadedIDs, true); err != nil {
-			return utils.NewErrServerError(err)
-		}
-	}
 	aps, _ := loader.GetLoadedIds(utils.ACTION_PLAN_PREFIX)
 	cstKeys, _ := loader.GetLoadedIds(utils.CDR_STATS_PREFIX)
 	userKeys, _ := loader.GetLoadedIds(utils.USERS_PREFIX)
@@ -1523,6 +1639,7 @@ func (self *ApierV1) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 			return err
 		}
 	}
+	engine.NotifyTPLoaded()
 	*reply = utils.OK
 	return nil
 }
@@ -1775,6 +1892,11 @@ func (v1 *ApierV1) ReplayFailedPosts(args ArgsReplyFailedPosts, reply *string) (
 					chn.Close()
 				}
 			}
+		case utils.MetaNATSjsonCDR, utils.MetaNATSjsonMap:
+			var natsPoster *utils.NATSPoster
+			if natsPoster, err = utils.NATSPostersCache.GetNATSPoster(ffn.Address, v1.Config.PosterAttempts, failedReqsOutDir); err == nil {
+				err = natsPoster.Post(fileContent, file.Name())
+			}
 		default:
 			err = fmt.Errorf("unsupported replication transport: %s", ffn.Transport)
 		}
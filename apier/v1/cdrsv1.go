@@ -25,6 +25,7 @@ import (
 // Receive CDRs via RPC methods
 type CdrsV1 struct {
 	CdrSrv *engine.CdrServer
+	Jobs   *utils.JobManager // tracks asynchronous rating
 }
 
 // Designed for CGR internal usage
@@ -65,13 +66,41 @@ func (self *CdrsV1) RateCDRs(attrs utils.AttrRateCdrs, reply *string) error {
 	if err != nil {
 		return utils.NewErrServerError(err)
 	}
-	if err := self.CdrSrv.RateCDRs(cdrsFltr, attrs.SendToStats); err != nil {
+	if err := self.CdrSrv.RateCDRsWithJob(cdrsFltr, attrs.SendToStats, attrs.RatingAsOfSetupTime, nil); err != nil {
 		return utils.NewErrServerError(err)
 	}
 	*reply = utils.OK
 	return nil
 }
 
+// RateCDRsAsync (re)rates as a job, returning its ID, status is available via ApierV1.GetJobStatus
+func (self *CdrsV1) RateCDRsAsync(attrs utils.AttrRateCdrs, reply *string) error {
+	if self.Jobs == nil {
+		return errJobsNotEnabled
+	}
+	cdrsFltr, err := attrs.AsCDRsFilter(self.CdrSrv.Timezone())
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = self.Jobs.StartJob("RateCDRs", true, func(job *utils.Job) (interface{}, error) {
+		if err := self.CdrSrv.RateCDRsWithJob(cdrsFltr, attrs.SendToStats, attrs.RatingAsOfSetupTime, job); err != nil {
+			return nil, err
+		}
+		return utils.OK, nil
+	})
+	return nil
+}
+
 func (self *CdrsV1) StoreSMCost(attr engine.AttrCDRSStoreSMCost, reply *string) error {
 	return self.CdrSrv.V1StoreSMCost(attr, reply)
 }
+
+// GetUnratedBacklog returns the number of CDRs stored with rating errors, per error
+func (self *CdrsV1) GetUnratedBacklog(ignored string, reply *map[string]int64) error {
+	return self.CdrSrv.V1GetUnratedBacklog(ignored, reply)
+}
+
+// RetryUnratedCDRs re-rates the CDRs stored with rating errors, replying with the number still failing
+func (self *CdrsV1) RetryUnratedCDRs(ignored string, reply *int64) error {
+	return self.CdrSrv.V1RetryUnratedCDRs(ignored, reply)
+}
@@ -56,3 +56,179 @@ func (apier *ApierV1) GetDataCost(attrs AttrGetDataCost, reply *engine.DataCost)
 	}
 	return nil
 }
+
+type AttrGetCostSimulation struct {
+	Direction, Category, Tenant, Account, Subject, Destination, TOR string
+	Usage                                                           string // duration of the simulated call, ie: 60s
+	TimeStart, TimeEnd                                              string // range of call start times
+	Step                                                            string // *hourly(default) or *daily
+}
+
+// GetCostSimulation returns the cost of the same call started at each step of the time range, together with the rating applied
+func (apier *ApierV1) GetCostSimulation(attrs AttrGetCostSimulation, reply *[]*engine.CostSample) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"Tenant", "Destination", "Usage", "TimeStart", "TimeEnd"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	usage, err := utils.ParseDurationWithSecs(attrs.Usage)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	tStart, err := utils.ParseTimeDetectLayout(attrs.TimeStart, apier.Config.DefaultTimezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	tEnd, err := utils.ParseTimeDetectLayout(attrs.TimeEnd, apier.Config.DefaultTimezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	cs := &engine.CostSimulation{
+		Direction:   utils.FirstNonEmpty(attrs.Direction, utils.OUT),
+		Category:    utils.FirstNonEmpty(attrs.Category, apier.Config.DefaultCategory),
+		Tenant:      attrs.Tenant,
+		Account:     attrs.Account,
+		Subject:     attrs.Subject,
+		Destination: attrs.Destination,
+		TOR:         attrs.TOR,
+		Usage:       usage,
+		TimeStart:   tStart,
+		TimeEnd:     tEnd,
+		Step:        attrs.Step,
+	}
+	var samples []*engine.CostSample
+	if err := apier.Responder.SimulateCosts(cs, &samples); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = samples
+	return nil
+}
+
+type AttrGetMaxUsageQuotes struct {
+	Direction, Category, Tenant, Account, Subject, TOR string
+	Destinations                                       []string
+	SetupTime                                          string // start of the quoted calls, *now by default
+	Usage                                              string // upper limit of the quoted usage, max_call_duration by default
+}
+
+// GetMaxUsageQuotes returns the usage affordable by the account towards each of the destinations, together with its cost
+func (apier *ApierV1) GetMaxUsageQuotes(attrs AttrGetMaxUsageQuotes, reply *[]*engine.MaxUsageQuote) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"Account", "Destinations"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	usage := apier.Config.MaxCallDuration
+	if attrs.Usage != "" {
+		var err error
+		if usage, err = utils.ParseDurationWithSecs(attrs.Usage); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	}
+	sTime, err := utils.ParseTimeDetectLayout(utils.FirstNonEmpty(attrs.SetupTime, utils.META_NOW), apier.Config.DefaultTimezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	mq := &engine.MaxUsageQuotes{
+		Direction:    utils.FirstNonEmpty(attrs.Direction, utils.OUT),
+		Category:     utils.FirstNonEmpty(attrs.Category, apier.Config.DefaultCategory),
+		Tenant:       utils.FirstNonEmpty(attrs.Tenant, apier.Config.DefaultTenant),
+		Account:      attrs.Account,
+		Subject:      attrs.Subject,
+		TOR:          utils.FirstNonEmpty(attrs.TOR, utils.VOICE),
+		Destinations: attrs.Destinations,
+		TimeStart:    sTime,
+		Usage:        usage,
+	}
+	var quotes []*engine.MaxUsageQuote
+	if err := apier.Responder.GetMaxUsageQuotes(mq, &quotes); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = quotes
+	return nil
+}
+
+type AttrExplainRateInterval struct {
+	Direction, Category, Tenant, Subject, Destination string
+	Time                                              string // moment to explain, *now by default
+}
+
+// ExplainRateInterval reports the rate intervals matching the time and destination, the applied one first, with the rule deciding each
+func (apier *ApierV1) ExplainRateInterval(attrs AttrExplainRateInterval, reply *engine.RateIntervalExplanation) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"Subject", "Destination"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	t, err := utils.ParseTimeDetectLayout(utils.FirstNonEmpty(attrs.Time, utils.META_NOW), apier.Config.DefaultTimezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	cd := &engine.CallDescriptor{
+		Direction:   utils.FirstNonEmpty(attrs.Direction, utils.OUT),
+		Category:    utils.FirstNonEmpty(attrs.Category, apier.Config.DefaultCategory),
+		Tenant:      utils.FirstNonEmpty(attrs.Tenant, apier.Config.DefaultTenant),
+		Subject:     attrs.Subject,
+		Destination: attrs.Destination,
+		TimeStart:   t,
+		TimeEnd:     t.Add(time.Second),
+	}
+	expl, err := cd.ExplainRateInterval()
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *expl
+	return nil
+}
+
+type AttrGetCost struct {
+	Direction, Category, Tenant, Account, Subject, Destination, TOR string
+	AnswerTime                                                      string // *now by default
+	Usage                                                           string // duration of the call, ie: 60s
+}
+
+// GetCostBatch returns the costs of many calls in one request, rated in parallel and in the order received
+func (apier *ApierV1) GetCostBatch(attrs []*AttrGetCost, reply *[]*engine.BatchCost) error {
+	if len(attrs) == 0 {
+		return utils.NewErrMandatoryIeMissing("Calls")
+	}
+	cds := make([]*engine.CallDescriptor, len(attrs))
+	for i, attr := range attrs {
+		if missing := utils.MissingStructFields(attr, []string{"Account", "Destination", "Usage"}); len(missing) != 0 {
+			return utils.NewErrMandatoryIeMissing(missing...)
+		}
+		usage, err := utils.ParseDurationWithSecs(attr.Usage)
+		if err != nil {
+			return utils.NewErrServerError(err)
+		}
+		aTime, err := utils.ParseTimeDetectLayout(utils.FirstNonEmpty(attr.AnswerTime, utils.META_NOW), apier.Config.DefaultTimezone)
+		if err != nil {
+			return utils.NewErrServerError(err)
+		}
+		cds[i] = &engine.CallDescriptor{
+			Direction:     utils.FirstNonEmpty(attr.Direction, utils.OUT),
+			Category:      utils.FirstNonEmpty(attr.Category, apier.Config.DefaultCategory),
+			Tenant:        utils.FirstNonEmpty(attr.Tenant, apier.Config.DefaultTenant),
+			Account:       attr.Account,
+			Subject:       attr.Subject,
+			Destination:   attr.Destination,
+			TOR:           utils.FirstNonEmpty(attr.TOR, utils.VOICE),
+			TimeStart:     aTime,
+			TimeEnd:       aTime.Add(usage),
+			DurationIndex: usage,
+		}
+	}
+	var costs []*engine.BatchCost
+	if err := apier.Responder.GetCostBatch(cds, &costs); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = costs
+	return nil
+}
+
+// GetDebugRecords returns the rating traces of the call with originID, flagged for debugging with cgr_flags debug
+func (apier *ApierV1) GetDebugRecords(originID string, reply *[]*engine.DebugRecord) error {
+	if originID == "" {
+		return utils.NewErrMandatoryIeMissing("OriginID")
+	}
+	drs, err := engine.GetDebugRecords(originID)
+	if err != nil {
+		return err
+	}
+	*reply = drs
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/scheduler"
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	diagnosticLatencySamples = 10
+	diagnosticLoadHistory    = 20 // number of load instances included
+)
+
+type AttrGetDiagnosticBundle struct {
+	LatencySamples int // number of DataDB reads to time, defaults to 10
+}
+
+// SchedulerState is the scheduler section of the diagnostic bundle
+type SchedulerState struct {
+	Running bool
+	Queue   []*scheduler.ScheduledAction
+}
+
+// DataDBLatency is the DataDB section of the diagnostic bundle
+type DataDBLatency struct {
+	Samples       []time.Duration
+	Min, Max, Avg time.Duration
+	Errors        []string
+}
+
+// diagnosticSection returns the content of a bundle file, the error in place of it if it could not be collected
+func diagnosticSection(content interface{}, err error) interface{} {
+	if err != nil && err != utils.ErrNotFound {
+		return map[string]string{"Error": err.Error()}
+	}
+	return content
+}
+
+// GetDiagnosticBundle collects the engine state useful to troubleshooting into a zip archive, returned base64 encoded
+// The configuration is included with the credentials redacted so the archive can be attached to support tickets
+func (v1 *ApierV1) GetDiagnosticBundle(attrs AttrGetDiagnosticBundle, reply *string) error {
+	files := make(map[string]interface{})
+	files["config.json"] = diagnosticSection(v1.Config.AsRedactedMap())
+	cs := new(utils.CacheStats)
+	files["cache_stats.json"] = diagnosticSection(cs, v1.GetCacheStats(utils.AttrCacheStats{}, cs))
+	snapshots, err := engine.GetTPSnapshots(v1.DataDB)
+	manifests := make([]*engine.TPSnapshot, len(snapshots))
+	for i, tps := range snapshots {
+		manifests[i] = tps.Manifest()
+	}
+	files["tariffplans.json"] = diagnosticSection(manifests, err)
+	files["load_history.json"] = diagnosticSection(v1.DataDB.GetLoadHistory(diagnosticLoadHistory, false, utils.NonTransactional))
+	schedState := new(SchedulerState)
+	if v1.ServManager != nil {
+		if sched := v1.ServManager.GetScheduler(); sched != nil {
+			schedState.Running = true
+			schedState.Queue = sched.GetScheduledActions(scheduler.ArgsGetScheduledActions{})
+		}
+	}
+	files["scheduler.json"] = schedState
+	samples := attrs.LatencySamples
+	if samples <= 0 {
+		samples = diagnosticLatencySamples
+	}
+	files["datadb_latency.json"] = dataDBLatency(v1.DataDB, samples)
+	bundle, err := diagnosticArchive(files, strings.Join(utils.Logger.RecentErrors(), "\n"))
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = base64.StdEncoding.EncodeToString(bundle)
+	return nil
+}
+
+// dataDBLatency times a number of existence checks against the DataDB
+func dataDBLatency(dataDB engine.DataDB, samples int) (lat *DataDBLatency) {
+	lat = new(DataDBLatency)
+	var total time.Duration
+	for i := 0; i < samples; i++ {
+		start := time.Now()
+		if _, err := dataDB.HasData(utils.ACCOUNT_PREFIX, utils.META_NONE); err != nil {
+			lat.Errors = append(lat.Errors, err.Error())
+			continue
+		}
+		d := time.Now().Sub(start)
+		lat.Samples = append(lat.Samples, d)
+		if lat.Min == 0 || d < lat.Min {
+			lat.Min = d
+		}
+		if d > lat.Max {
+			lat.Max = d
+		}
+		total += d
+	}
+	if len(lat.Samples) != 0 {
+		lat.Avg = total / time.Duration(len(lat.Samples))
+	}
+	return
+}
+
+// diagnosticArchive writes the sections as JSON files together with the error log into a zip
+func diagnosticArchive(files map[string]interface{}, errLog string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for fName, content := range files {
+		b, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		f, err := w.Create(fName)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(b); err != nil {
+			return nil, err
+		}
+	}
+	f, err := w.Create("errors.log")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write([]byte(errLog)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
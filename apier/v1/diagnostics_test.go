@@ -0,0 +1,84 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestGetDiagnosticBundle(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.DataDbPass = "secretDataDBPass"
+	apier := &ApierV1{DataDB: apierAcntsAcntStorage, Config: cfg}
+	utils.Logger.Err("<DiagnosticTest> test error")
+	var reply string
+	if err := apier.GetDiagnosticBundle(AttrGetDiagnosticBundle{LatencySamples: 3}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := base64.StdEncoding.DecodeString(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files[f.Name], err = ioutil.ReadAll(rc); err != nil {
+			t.Fatal(err)
+		}
+		rc.Close()
+	}
+	for _, fName := range []string{"config.json", "cache_stats.json", "tariffplans.json",
+		"load_history.json", "scheduler.json", "datadb_latency.json", "errors.log"} {
+		if _, has := files[fName]; !has {
+			t.Errorf("Missing file: %s", fName)
+		}
+	}
+	if bytes.Contains(files["config.json"], []byte("secretDataDBPass")) {
+		t.Error("Password not redacted")
+	}
+	if !bytes.Contains(files["errors.log"], []byte("<DiagnosticTest> test error")) {
+		t.Errorf("Unexpected errors log: %s", files["errors.log"])
+	}
+	var lat DataDBLatency
+	if err := json.Unmarshal(files["datadb_latency.json"], &lat); err != nil {
+		t.Error(err)
+	} else if len(lat.Samples) != 3 {
+		t.Errorf("Unexpected latency: %+v", lat)
+	}
+	var sched SchedulerState
+	if err := json.Unmarshal(files["scheduler.json"], &sched); err != nil {
+		t.Error(err)
+	} else if sched.Running {
+		t.Error("Scheduler should not be running")
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"errors"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+var errJobsNotEnabled = errors.New("JOBS_NOT_ENABLED")
+
+type AttrJobID struct {
+	ID string
+}
+
+// startJob runs handler as a job which cannot be cancelled, returning its ID in reply
+func (v1 *ApierV1) startJob(name string, reply *string, handler func(job *utils.Job) (interface{}, error)) error {
+	if v1.Jobs == nil {
+		return errJobsNotEnabled
+	}
+	*reply = v1.Jobs.StartJob(name, false, handler)
+	return nil
+}
+
+// GetJobStatus returns the status and progress of an asynchronous job
+func (v1 *ApierV1) GetJobStatus(attrs AttrJobID, reply *utils.JobStatus) error {
+	if len(attrs.ID) == 0 {
+		return utils.NewErrMandatoryIeMissing("ID")
+	}
+	if v1.Jobs == nil {
+		return errJobsNotEnabled
+	}
+	js, err := v1.Jobs.GetJobStatus(attrs.ID)
+	if err != nil {
+		return err
+	}
+	*reply = *js
+	return nil
+}
+
+// GetJobs returns the status of all the asynchronous jobs known
+func (v1 *ApierV1) GetJobs(ignr string, reply *[]*utils.JobStatus) error {
+	if v1.Jobs == nil {
+		return errJobsNotEnabled
+	}
+	jss := v1.Jobs.GetJobsStatus()
+	if len(jss) == 0 {
+		return utils.ErrNotFound
+	}
+	*reply = jss
+	return nil
+}
+
+// GetJobResult returns the result of a completed job
+func (v1 *ApierV1) GetJobResult(attrs AttrJobID, reply *interface{}) error {
+	if len(attrs.ID) == 0 {
+		return utils.NewErrMandatoryIeMissing("ID")
+	}
+	if v1.Jobs == nil {
+		return errJobsNotEnabled
+	}
+	result, err := v1.Jobs.GetJobResult(attrs.ID)
+	if err != nil {
+		return err
+	}
+	*reply = result
+	return nil
+}
+
+// CancelJob requests the cancellation of a running job, only the ones rating CDRs can be cancelled
+func (v1 *ApierV1) CancelJob(attrs AttrJobID, reply *string) error {
+	if len(attrs.ID) == 0 {
+		return utils.NewErrMandatoryIeMissing("ID")
+	}
+	if v1.Jobs == nil {
+		return errJobsNotEnabled
+	}
+	if err := v1.Jobs.CancelJob(attrs.ID); err != nil {
+		return err
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// LoadTariffPlanFromStorDbAsync loads the tariff plan as a job, returning the job ID
+func (v1 *ApierV1) LoadTariffPlanFromStorDbAsync(attrs AttrLoadTpFromStorDb, reply *string) error {
+	if len(attrs.TPid) == 0 {
+		return utils.NewErrMandatoryIeMissing("TPid")
+	}
+	return v1.startJob("LoadTariffPlanFromStorDb", reply, func(job *utils.Job) (interface{}, error) {
+		var rpl string
+		err := v1.LoadTariffPlanFromStorDb(attrs, &rpl)
+		return rpl, err
+	})
+}
+
+// LoadTariffPlanFromFolderAsync loads the tariff plan as a job, returning the job ID
+func (v1 *ApierV1) LoadTariffPlanFromFolderAsync(attrs utils.AttrLoadTpFromFolder, reply *string) error {
+	if len(attrs.FolderPath) == 0 {
+		return utils.NewErrMandatoryIeMissing("FolderPath")
+	}
+	return v1.startJob("LoadTariffPlanFromFolder", reply, func(job *utils.Job) (interface{}, error) {
+		var rpl string
+		err := v1.LoadTariffPlanFromFolder(attrs, &rpl)
+		return rpl, err
+	})
+}
+
+// ComputeReverseDestinationsAsync rebuilds the reverse destinations as a job, returning the job ID
+func (v1 *ApierV1) ComputeReverseDestinationsAsync(ignr string, reply *string) error {
+	return v1.startJob("ComputeReverseDestinations", reply, func(job *utils.Job) (interface{}, error) {
+		var rpl string
+		err := v1.ComputeReverseDestinations(ignr, &rpl)
+		return rpl, err
+	})
+}
+
+// ComputeReverseAliasesAsync rebuilds the reverse aliases as a job, returning the job ID
+func (v1 *ApierV1) ComputeReverseAliasesAsync(ignr string, reply *string) error {
+	return v1.startJob("ComputeReverseAliases", reply, func(job *utils.Job) (interface{}, error) {
+		var rpl string
+		err := v1.ComputeReverseAliases(ignr, &rpl)
+		return rpl, err
+	})
+}
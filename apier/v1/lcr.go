@@ -19,6 +19,7 @@ package v1
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cgrates/cgrates/engine"
 	"github.com/cgrates/cgrates/utils"
@@ -80,3 +81,48 @@ func (self *ApierV1) GetLcrSuppliers(lcrReq engine.LcrRequest, suppliers *string
 	}
 	return nil
 }
+
+type AttrLcrSupplierFeedback struct {
+	Tenant   string
+	Supplier string
+	Success  bool // false counts towards the consecutive failures blacklisting the supplier
+}
+
+// Reports the outcome of an attempt towards an LCR supplier
+func (self *ApierV1) ReportLcrSupplierFeedback(attrs AttrLcrSupplierFeedback, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"Supplier"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if attrs.Tenant == "" {
+		attrs.Tenant = self.Config.DefaultTenant
+	}
+	engine.LCRBlacklist().ReportAttempt(attrs.Tenant, attrs.Supplier, attrs.Success)
+	*reply = utils.OK
+	return nil
+}
+
+// Returns the blacklisted suppliers, indexed on tenant:supplier, with the time their blacklisting expires
+func (self *ApierV1) GetLcrBlacklist(ignored string, reply *map[string]time.Time) error {
+	*reply = engine.LCRBlacklist().Blacklisted()
+	return nil
+}
+
+type AttrRemoveLcrBlacklist struct {
+	Tenant   string
+	Supplier string
+}
+
+// Removes a supplier from the LCR blacklist before its cooldown expires
+func (self *ApierV1) RemoveLcrBlacklist(attrs AttrRemoveLcrBlacklist, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"Supplier"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if attrs.Tenant == "" {
+		attrs.Tenant = self.Config.DefaultTenant
+	}
+	if err := engine.LCRBlacklist().Clear(attrs.Tenant, attrs.Supplier); err != nil {
+		return err
+	}
+	*reply = utils.OK
+	return nil
+}
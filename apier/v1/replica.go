@@ -0,0 +1,61 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"sync"
+
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ReplicaV1 stores the data fed by the primary engine into a read-only replica
+type ReplicaV1 struct {
+	DataDB  engine.DataDB
+	CdrDb   engine.CdrStorage
+	seqMux  sync.Mutex
+	acntSeq map[string]uint64 // sequence of the last account state stored
+}
+
+// Replicate stores the CDRs and the accounts replicated by the primary, ignoring account states older than the stored ones
+func (self *ReplicaV1) Replicate(batch engine.ReplicaBatch, reply *string) error {
+	self.seqMux.Lock()
+	defer self.seqMux.Unlock()
+	if self.acntSeq == nil {
+		self.acntSeq = make(map[string]uint64)
+	}
+	for _, acc := range batch.Accounts {
+		if acc.Sequence <= self.acntSeq[acc.Account.ID] {
+			continue
+		}
+		if err := self.DataDB.SetAccount(acc.Account); err != nil {
+			return utils.NewErrServerError(err)
+		}
+		self.acntSeq[acc.Account.ID] = acc.Sequence
+	}
+	if len(batch.CDRs) != 0 && self.CdrDb == nil {
+		return utils.NewErrServerError(utils.ErrNotImplemented) // no StorDB to replicate into
+	}
+	for _, cdr := range batch.CDRs {
+		if err := self.CdrDb.SetCDR(cdr, true); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	}
+	*reply = utils.OK
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"testing"
+
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestReplicaReplicateSequence(t *testing.T) {
+	dataDB, _ := engine.NewMapStorage()
+	rpl := &ReplicaV1{DataDB: dataDB}
+	newBatch := func(seq uint64, value float64) engine.ReplicaBatch {
+		return engine.ReplicaBatch{Accounts: []*engine.ReplicaAccount{&engine.ReplicaAccount{Sequence: seq,
+			Account: &engine.Account{ID: "cgrates.org:1001", BalanceMap: map[string]engine.Balances{
+				utils.MONETARY: engine.Balances{&engine.Balance{Value: value}}}}}}}
+	}
+	var reply string
+	for _, batch := range []engine.ReplicaBatch{newBatch(2, 7), newBatch(1, 10)} { // older state arriving late
+		if err := rpl.Replicate(batch, &reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if acc, err := dataDB.GetAccount("cgrates.org:1001"); err != nil {
+		t.Fatal(err)
+	} else if acc.BalanceMap[utils.MONETARY][0].Value != 7 {
+		t.Errorf("Newer account state overwritten: %+v", acc.BalanceMap[utils.MONETARY][0])
+	}
+}
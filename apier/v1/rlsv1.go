@@ -79,3 +79,8 @@ func (rlsv1 *RLsV1) AllocateResource(args utils.AttrRLsResourceUsage, reply *str
 func (rlsv1 *RLsV1) ReleaseResource(args utils.AttrRLsResourceUsage, reply *string) error {
 	return rlsv1.rls.V1ReleaseResource(args, reply)
 }
+
+// GetResourceUsageSeries returns the utilization time series of a ResourceLimit
+func (rlsv1 *RLsV1) GetResourceUsageSeries(args utils.AttrRLsUsageSeries, reply *[]*engine.ResourceUsageSample) error {
+	return rlsv1.rls.V1GetResourceUsageSeries(args, reply)
+}
@@ -198,3 +198,67 @@ func (self *ApierV1) ExecuteScheduledActions(attr AttrsExecuteScheduledActions,
 	*reply = utils.OK
 	return nil
 }
+
+type AttrGetActionPlanSchedule struct {
+	ActionPlanID     string
+	ActionTimingUUID string // restrict the preview to one action timing, all of them if empty
+	NrExecutions     int    // number of executions to compute for each action timing, defaults to 10
+	Timezone         string // location used to compute the executions, defaults to general default_timezone
+	TimeStart        string // compute the executions following this time, defaults to now
+}
+
+type ActionTimingSchedule struct {
+	ActionTimingUUID string
+	ActionsID        string
+	Weight           float64
+	ASAP             bool // executed once when loaded, hence no NextExecutions
+	NextExecutions   []time.Time
+}
+
+// GetActionPlanSchedule previews the next executions of the action timings within an ActionPlan
+func (self *ApierV1) GetActionPlanSchedule(attr AttrGetActionPlanSchedule, reply *[]*ActionTimingSchedule) error {
+	if missing := utils.MissingStructFields(&attr, []string{"ActionPlanID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if attr.NrExecutions == 0 {
+		attr.NrExecutions = 10
+	}
+	if attr.Timezone == "" {
+		attr.Timezone = self.Config.DefaultTimezone
+	}
+	loc, err := time.LoadLocation(attr.Timezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	now := time.Now()
+	if attr.TimeStart != "" {
+		if now, err = utils.ParseTimeDetectLayout(attr.TimeStart, attr.Timezone); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	}
+	now = now.In(loc)
+	apl, err := self.DataDB.GetActionPlan(attr.ActionPlanID, false, utils.NonTransactional)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	if apl == nil {
+		return utils.ErrNotFound
+	}
+	var schedules []*ActionTimingSchedule
+	for _, at := range apl.ActionTimings {
+		if attr.ActionTimingUUID != "" && at.Uuid != attr.ActionTimingUUID {
+			continue
+		}
+		nextTimes, err := at.GetNextStartTimes(now, attr.NrExecutions)
+		if err != nil {
+			return utils.NewErrServerError(err)
+		}
+		schedules = append(schedules, &ActionTimingSchedule{ActionTimingUUID: at.Uuid, ActionsID: at.ActionsID,
+			Weight: at.Weight, ASAP: at.IsASAP(), NextExecutions: nextTimes})
+	}
+	if len(schedules) == 0 {
+		return utils.ErrNotFound
+	}
+	*reply = schedules
+	return nil
+}
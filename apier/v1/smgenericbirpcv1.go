@@ -44,6 +44,7 @@ func (self *SMGenericBiRpcV1) Handlers() map[string]interface{} {
 		"SMGenericV1.GetActiveSessionsCount":  self.GetActiveSessionsCount,
 		"SMGenericV1.GetṔassiveSessions":      self.GetṔassiveSessions,
 		"SMGenericV1.GetPassiveSessionsCount": self.GetPassiveSessionsCount,
+		"SMGenericV1.GetSessionLegs":          self.GetSessionLegs,
 		"SMGenericV1.ReplicateActiveSessions": self.ReplicateActiveSessions,
 	}
 }
@@ -99,6 +100,10 @@ func (self *SMGenericBiRpcV1) GetPassiveSessionsCount(clnt *rpc2.Client, attrs m
 	return self.sm.BiRPCV1GetPassiveSessionsCount(clnt, attrs, reply)
 }
 
+func (self *SMGenericBiRpcV1) GetSessionLegs(clnt *rpc2.Client, args sessionmanager.ArgsGetSessionLegs, reply *sessionmanager.SessionLegs) error {
+	return self.sm.BiRPCV1GetSessionLegs(clnt, args, reply)
+}
+
 func (self *SMGenericBiRpcV1) ReplicateActiveSessions(clnt *rpc2.Client, args sessionmanager.ArgsReplicateSessions, reply *string) error {
 	return self.sm.BiRPCV1ReplicateActiveSessions(clnt, args, reply)
 }
@@ -86,6 +86,10 @@ func (self *SMGenericV1) GetPassiveSessionsCount(attrs map[string]string, reply
 	return self.SMG.BiRPCV1GetPassiveSessionsCount(nil, attrs, reply)
 }
 
+func (self *SMGenericV1) GetSessionLegs(args sessionmanager.ArgsGetSessionLegs, reply *sessionmanager.SessionLegs) error {
+	return self.SMG.BiRPCV1GetSessionLegs(nil, args, reply)
+}
+
 func (self *SMGenericV1) SetPassiveSessions(args sessionmanager.ArgsSetPassiveSessions, reply *string) error {
 	return self.SMG.BiRPCV1SetPassiveSessions(nil, args, reply)
 }
@@ -0,0 +1,57 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// Sets the split charging referenced out of derived chargers in dataDB
+func (self *ApierV1) SetSplitCharging(spc engine.SplitCharging, reply *string) error {
+	if missing := utils.MissingStructFields(&spc, []string{"ID", "Payers"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if _, err := spc.Shares(); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if err := self.DataDB.SetSplitCharging(&spc, utils.NonTransactional); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// Returns the split charging out of dataDB
+func (self *ApierV1) GetSplitCharging(id string, reply *engine.SplitCharging) error {
+	spc, err := self.DataDB.GetSplitCharging(id, false, utils.NonTransactional)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *spc
+	return nil
+}
+
+// Removes the split charging out of dataDB
+func (self *ApierV1) RemoveSplitCharging(id string, reply *string) error {
+	if err := self.DataDB.RemoveSplitCharging(id, utils.NonTransactional); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = utils.OK
+	return nil
+}
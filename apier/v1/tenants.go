@@ -0,0 +1,74 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+type AttrBootstrapTenant struct {
+	TemplateTenant  string // tenant the definitions are copied from
+	Tenant          string // new tenant
+	Overwrite       bool   // replace the definitions already existing for the new tenant
+	ReloadScheduler bool   // schedule the copied action plans
+}
+
+// BootstrapTenant copies the rating profiles, derived chargers, action plans and stats queues of TemplateTenant to Tenant,
+// replacing TemplateTenant in their IDs (or prefixing them with Tenant)
+func (self *ApierV1) BootstrapTenant(attrs AttrBootstrapTenant, reply *engine.TenantBootstrap) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TemplateTenant", "Tenant"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	tnbIface, err := guardian.Guardian.Guard(func() (interface{}, error) {
+		return engine.NewTenantBootstrapper(self.DataDB, attrs.TemplateTenant, attrs.Tenant).Bootstrap(attrs.Overwrite)
+	}, 0, utils.ACTION_PLAN_PREFIX)
+	if err != nil {
+		if err == utils.ErrExists || err == utils.ErrInvalidTenant {
+			return err
+		}
+		return utils.NewErrServerError(err)
+	}
+	tnb := tnbIface.(*engine.TenantBootstrap)
+	for prefix, ids := range map[string][]string{
+		utils.RATING_PROFILE_PREFIX:  tnb.RatingProfiles,
+		utils.DERIVEDCHARGERS_PREFIX: tnb.DerivedChargers,
+		utils.ACTION_PLAN_PREFIX:     tnb.ActionPlans,
+	} {
+		if len(ids) == 0 {
+			continue
+		}
+		if err := self.DataDB.CacheDataFromDB(prefix, ids, true); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	}
+	if len(tnb.CdrStats) != 0 && self.CdrStatsSrv != nil {
+		var out int
+		if err := self.CdrStatsSrv.Call("CDRStatsV1.ReloadQueues", tnb.CdrStats, &out); err != nil {
+			return err
+		}
+	}
+	if attrs.ReloadScheduler && len(tnb.ActionPlans) != 0 {
+		if sched := self.ServManager.GetScheduler(); sched != nil {
+			sched.Reload()
+		}
+	}
+	*reply = *tnb
+	return nil
+}
@@ -20,9 +20,12 @@ package v1
 // Tariff plan related APIs
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/cgrates/cgrates/engine"
 	"github.com/cgrates/cgrates/utils"
@@ -96,3 +99,38 @@ func (self *ApierV1) ImportTPZipFile(attrs AttrImportTPZipFile, reply *string) e
 	*reply = utils.OK
 	return nil
 }
+
+// GetTPLoadData exposes the LoadReader methods of storDb so remote engines can load tariff plans out of it
+func (self *ApierV1) GetTPLoadData(attrs utils.AttrGetTPLoadData, reply *json.RawMessage) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"Method"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	mthd, hasIt := reflect.TypeOf((*engine.LoadReader)(nil)).Elem().MethodByName(attrs.Method)
+	if !hasIt {
+		return utils.ErrNotImplemented
+	}
+	if mthd.Type.NumIn() != len(attrs.Args) {
+		return utils.NewErrServerError(fmt.Errorf("method %s expects %d arguments", attrs.Method, mthd.Type.NumIn()))
+	}
+	args := make([]reflect.Value, len(attrs.Args))
+	for i, rawArg := range attrs.Args {
+		arg := reflect.New(mthd.Type.In(i))
+		if err := json.Unmarshal(rawArg, arg.Interface()); err != nil {
+			return utils.NewErrServerError(err)
+		}
+		args[i] = arg.Elem()
+	}
+	out := reflect.ValueOf(self.StorDb).MethodByName(attrs.Method).Call(args)
+	if errIf := out[1].Interface(); errIf != nil {
+		if err := errIf.(error); err != utils.ErrNotFound {
+			return utils.NewErrServerError(err)
+		}
+		return utils.ErrNotFound
+	}
+	result, err := json.Marshal(out[0].Interface())
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = result
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// testTPLoadStorage serves only the TP data queried in tests
+type testTPLoadStorage struct {
+	engine.LoadStorage
+	dsts []*utils.TPDestination
+}
+
+func (lds *testTPLoadStorage) GetTPDestinations(tpid, id string) (dsts []*utils.TPDestination, err error) {
+	for _, dst := range lds.dsts {
+		if dst.TPid == tpid && (id == "" || dst.ID == id) {
+			dsts = append(dsts, dst)
+		}
+	}
+	if len(dsts) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return
+}
+
+// testTPLoadDataConn routes the RPCLoadReader queries towards an ApierV1
+type testTPLoadDataConn struct {
+	apier *ApierV1
+}
+
+func (conn *testTPLoadDataConn) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	return conn.apier.GetTPLoadData(args.(utils.AttrGetTPLoadData), reply.(*json.RawMessage))
+}
+
+func TestRPCLoadReader(t *testing.T) {
+	eDsts := []*utils.TPDestination{&utils.TPDestination{TPid: "TP_STAGING", ID: "DST_1002", Prefixes: []string{"1002", "+491002"}}}
+	lr := engine.NewRPCLoadReader(&testTPLoadDataConn{apier: &ApierV1{StorDb: &testTPLoadStorage{dsts: eDsts}}})
+	if dsts, err := lr.GetTPDestinations("TP_STAGING", "DST_1002"); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eDsts, dsts) {
+		t.Errorf("Expecting: %+v, received: %+v", eDsts[0], dsts)
+	}
+	if _, err := lr.GetTPDestinations("TP_PRODUCTION", ""); err != utils.ErrNotFound {
+		t.Errorf("Expecting not found, received: %v", err)
+	}
+	var reply json.RawMessage
+	apierTP := &ApierV1{StorDb: &testTPLoadStorage{}}
+	if err := apierTP.GetTPLoadData(utils.AttrGetTPLoadData{Method: "SetTPDestinations"}, &reply); err != utils.ErrNotImplemented {
+		t.Errorf("Only LoadReader methods should be served, received: %v", err)
+	}
+	if err := apierTP.GetTPLoadData(utils.AttrGetTPLoadData{Method: "GetTPDestinations"}, &reply); err == nil {
+		t.Error("Expecting error on missing arguments")
+	}
+}
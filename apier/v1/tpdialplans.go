@@ -0,0 +1,103 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// Creates a new dial plan within a tariff plan
+func (self *ApierV1) SetTPDialPlan(attr utils.TPDialPlan, reply *string) error {
+	if missing := utils.MissingStructFields(&attr, []string{"TPid", "Tenant"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if len(attr.Rules) == 0 {
+		return utils.NewErrMandatoryIeMissing("Rules")
+	}
+	if _, err := engine.APItoDialPlan(&attr); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if err := self.StorDb.SetTPDialPlans([]*utils.TPDialPlan{&attr}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+type AttrGetTPDialPlan struct {
+	TPid   string // Tariff plan id
+	Tenant string // Tenant the dial plan applies to
+}
+
+// Queries specific dial plan on Tariff plan
+func (self *ApierV1) GetTPDialPlan(attr AttrGetTPDialPlan, reply *utils.TPDialPlan) error {
+	if missing := utils.MissingStructFields(&attr, []string{"TPid", "Tenant"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if dps, err := self.StorDb.GetTPDialPlans(attr.TPid, attr.Tenant); err != nil {
+		return utils.APIErrorHandler(err)
+	} else if len(dps) == 0 {
+		return utils.ErrNotFound
+	} else {
+		*reply = *dps[0]
+	}
+	return nil
+}
+
+type AttrGetTPDialPlanIds struct {
+	TPid string // Tariff plan id
+	utils.Paginator
+}
+
+// Queries dial plan identities on specific tariff plan.
+func (self *ApierV1) GetTPDialPlanIds(attrs AttrGetTPDialPlanIds, reply *[]string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if ids, err := self.StorDb.GetTpTableIds(attrs.TPid, utils.TBLTPDialPlans, utils.TPDistinctIds{"tenant"}, nil, &attrs.Paginator); err != nil {
+		return utils.NewErrServerError(err)
+	} else if ids == nil {
+		return utils.ErrNotFound
+	} else {
+		*reply = ids
+	}
+	return nil
+}
+
+// Removes specific dial plan on Tariff plan
+func (self *ApierV1) RemTPDialPlan(attrs AttrGetTPDialPlan, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "Tenant"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := self.StorDb.RemTpData(utils.TBLTPDialPlans, attrs.TPid, map[string]string{"tenant": attrs.Tenant}); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// Returns the dial plan loaded in dataDB
+func (self *ApierV1) GetDialPlan(tenant string, reply *engine.DialPlan) error {
+	dp, err := self.DataDB.GetDialPlan(tenant, false, utils.NonTransactional)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *dp
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// Creates a new exchange rate within a tariff plan
+func (self *ApierV1) SetTPExchangeRate(attr utils.TPExchangeRate, reply *string) error {
+	if missing := utils.MissingStructFields(&attr, []string{"TPid", "FromCurrency", "ToCurrency", "Rate"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if _, err := engine.APItoExchangeRate(&attr); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if err := self.StorDb.SetTPExchangeRates([]*utils.TPExchangeRate{&attr}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+type AttrGetTPExchangeRates struct {
+	TPid         string // Tariff plan id
+	FromCurrency string // Optional, all the exchange rates if empty
+}
+
+// Queries the exchange rates on Tariff plan
+func (self *ApierV1) GetTPExchangeRates(attr AttrGetTPExchangeRates, reply *[]*utils.TPExchangeRate) error {
+	if missing := utils.MissingStructFields(&attr, []string{"TPid"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if ers, err := self.StorDb.GetTPExchangeRates(attr.TPid, attr.FromCurrency); err != nil {
+		return utils.APIErrorHandler(err)
+	} else if len(ers) == 0 {
+		return utils.ErrNotFound
+	} else {
+		*reply = ers
+	}
+	return nil
+}
+
+type AttrRemTPExchangeRate struct {
+	TPid         string // Tariff plan id
+	FromCurrency string
+	ToCurrency   string
+}
+
+// Removes specific exchange rate on Tariff plan
+func (self *ApierV1) RemTPExchangeRate(attrs AttrRemTPExchangeRate, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "FromCurrency", "ToCurrency"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := self.StorDb.RemTpData(utils.TBLTPExchangeRates, attrs.TPid,
+		map[string]string{"from_currency": attrs.FromCurrency, "to_currency": attrs.ToCurrency}); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+type AttrGetExchangeRate struct {
+	FromCurrency string
+	ToCurrency   string
+}
+
+// Returns the exchange rate loaded in dataDB
+func (self *ApierV1) GetExchangeRate(attrs AttrGetExchangeRate, reply *engine.ExchangeRate) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"FromCurrency", "ToCurrency"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	er, err := self.DataDB.GetExchangeRate(utils.ConcatenatedKey(attrs.FromCurrency, attrs.ToCurrency), false, utils.NonTransactional)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *er
+	return nil
+}
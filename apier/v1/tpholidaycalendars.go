@@ -0,0 +1,103 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// Creates a new holiday calendar within a tariff plan
+func (self *ApierV1) SetTPHolidayCalendar(attr utils.TPHolidayCalendar, reply *string) error {
+	if missing := utils.MissingStructFields(&attr, []string{"TPid", "ID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if len(attr.Holidays) == 0 {
+		return utils.NewErrMandatoryIeMissing("Holidays")
+	}
+	if _, err := engine.APItoHolidayCalendar(&attr); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if err := self.StorDb.SetTPHolidayCalendars([]*utils.TPHolidayCalendar{&attr}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+type AttrGetTPHolidayCalendar struct {
+	TPid string // Tariff plan id
+	ID   string // Holiday calendar id
+}
+
+// Queries specific holiday calendar on Tariff plan
+func (self *ApierV1) GetTPHolidayCalendar(attr AttrGetTPHolidayCalendar, reply *utils.TPHolidayCalendar) error {
+	if missing := utils.MissingStructFields(&attr, []string{"TPid", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if hcs, err := self.StorDb.GetTPHolidayCalendars(attr.TPid, attr.ID); err != nil {
+		return utils.APIErrorHandler(err)
+	} else if len(hcs) == 0 {
+		return utils.ErrNotFound
+	} else {
+		*reply = *hcs[0]
+	}
+	return nil
+}
+
+type AttrGetTPHolidayCalendarIds struct {
+	TPid string // Tariff plan id
+	utils.Paginator
+}
+
+// Queries holiday calendar identities on specific tariff plan.
+func (self *ApierV1) GetTPHolidayCalendarIds(attrs AttrGetTPHolidayCalendarIds, reply *[]string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if ids, err := self.StorDb.GetTpTableIds(attrs.TPid, utils.TBLTPHolidayCalendars, utils.TPDistinctIds{"tag"}, nil, &attrs.Paginator); err != nil {
+		return utils.NewErrServerError(err)
+	} else if ids == nil {
+		return utils.ErrNotFound
+	} else {
+		*reply = ids
+	}
+	return nil
+}
+
+// Removes specific holiday calendar on Tariff plan
+func (self *ApierV1) RemTPHolidayCalendar(attrs AttrGetTPHolidayCalendar, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := self.StorDb.RemTpData(utils.TBLTPHolidayCalendars, attrs.TPid, map[string]string{"tag": attrs.ID}); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// Returns the holiday calendar loaded in dataDB
+func (self *ApierV1) GetHolidayCalendar(id string, reply *engine.HolidayCalendar) error {
+	hc, err := self.DataDB.GetHolidayCalendar(id, false, utils.NonTransactional)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *hc
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v1
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// GetTPSnapshots returns the versions of the rating configuration available for rollback, newest first
+func (self *ApierV1) GetTPSnapshots(ignored string, reply *[]*engine.TPSnapshot) error {
+	snapshots, err := engine.GetTPSnapshots(self.DataDB)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if len(snapshots) == 0 {
+		return utils.ErrNotFound
+	}
+	*reply = snapshots
+	return nil
+}
+
+type AttrRollbackTPSnapshot struct {
+	ID string // snapshot version to roll back to
+}
+
+// RollbackTPSnapshot restores the rating configuration written by a previous load
+func (self *ApierV1) RollbackTPSnapshot(attrs AttrRollbackTPSnapshot, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"ID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	tps, err := engine.RollbackTPSnapshot(self.DataDB, attrs.ID)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	utils.Logger.Info("ApierV1.RollbackTPSnapshot, reloading cache.")
+	for _, prfx := range []string{
+		utils.DESTINATION_PREFIX,
+		utils.REVERSE_DESTINATION_PREFIX,
+		utils.RATING_PLAN_PREFIX,
+		utils.RATING_PROFILE_PREFIX,
+		utils.LCR_PREFIX} {
+		if ids, has := tps.LoadedIDs[prfx]; has {
+			if err := self.DataDB.CacheDataFromDB(prfx, ids, true); err != nil {
+				return utils.NewErrServerError(err)
+			}
+		}
+	}
+	*reply = utils.OK
+	return nil
+}
@@ -48,6 +48,7 @@ func (self *ApierV2) LoadRatingProfile(attrs AttrLoadRatingProfile, reply *strin
 	}
 	tpRpf := &utils.TPRatingProfile{TPid: attrs.TPid}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if err := dbReader.LoadRatingProfilesFiltered(tpRpf); err != nil {
 		return utils.NewErrServerError(err)
 	}
@@ -69,6 +70,7 @@ func (self *ApierV2) LoadAccountActions(attrs AttrLoadAccountActions, reply *str
 		return utils.NewErrMandatoryIeMissing("TPid")
 	}
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	tpAa := &utils.TPAccountActions{TPid: attrs.TPid}
 	tpAa.SetAccountActionsId(attrs.AccountActionsId)
 	if _, err := guardian.Guardian.Guard(func() (interface{}, error) {
@@ -100,6 +102,7 @@ func (self *ApierV2) LoadDerivedChargers(attrs AttrLoadDerivedChargers, reply *s
 	tpDc := &utils.TPDerivedChargers{TPid: attrs.TPid}
 	tpDc.SetDerivedChargersId(attrs.DerivedChargersId)
 	dbReader := engine.NewTpReader(self.DataDB, self.StorDb, attrs.TPid, self.Config.DefaultTimezone)
+	dbReader.SetTenantTimezones(self.Config.TenantTimezones)
 	if err := dbReader.LoadDerivedChargersFiltered(tpDc, true); err != nil {
 		return utils.NewErrServerError(err)
 	}
@@ -110,7 +113,7 @@ func (self *ApierV2) LoadDerivedChargers(attrs AttrLoadDerivedChargers, reply *s
 	return nil
 }
 
-func (self *ApierV2) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder, reply *utils.LoadInstance) error {
+func (self *ApierV2) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder, reply *utils.LoadInstance) (err error) {
 	if len(attrs.FolderPath) == 0 {
 		return fmt.Errorf("%s:%s", utils.ErrMandatoryIeMissing.Error(), "FolderPath")
 	}
@@ -122,7 +125,12 @@ func (self *ApierV2) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 	} else if !fi.IsDir() {
 		return utils.ErrInvalidPath
 	}
-	loader := engine.NewTpReader(self.DataDB, engine.NewFileCSVStorage(utils.CSV_SEP,
+	var diff engine.TPLoadDiff
+	if !attrs.DryRun {
+		engine.NotifyTPLoad(utils.MetaTPLoadStarted, attrs.FolderPath, nil, nil)
+		defer func() { engine.NotifyTPLoadDone(attrs.FolderPath, diff, err) }()
+	}
+	csvStorage := engine.NewFileCSVStorage(utils.CSV_SEP,
 		path.Join(attrs.FolderPath, utils.DESTINATIONS_CSV),
 		path.Join(attrs.FolderPath, utils.TIMINGS_CSV),
 		path.Join(attrs.FolderPath, utils.RATES_CSV),
@@ -140,8 +148,20 @@ func (self *ApierV2) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 		path.Join(attrs.FolderPath, utils.USERS_CSV),
 		path.Join(attrs.FolderPath, utils.ALIASES_CSV),
 		path.Join(attrs.FolderPath, utils.ResourceLimitsCsv),
-	), "", self.Config.DefaultTimezone)
-	if err := loader.LoadAll(); err != nil {
+		path.Join(attrs.FolderPath, utils.ExchangeRatesCsv),
+		path.Join(attrs.FolderPath, utils.HolidayCalendarsCsv),
+		path.Join(attrs.FolderPath, utils.DialPlansCsv),
+	)
+	if len(attrs.Variables) != 0 {
+		csvStorage.SetVariables(&engine.TPVariables{Values: attrs.Variables})
+	}
+	loader := engine.NewTpReader(self.DataDB, csvStorage, "", self.Config.DefaultTimezone)
+	loader.SetTenantTimezones(self.Config.TenantTimezones)
+	loader.SetCompressDestinations(attrs.CompressDsts)
+	if err := loader.SetDisabledReverses(attrs.DisableReverses); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if err := loader.LoadCategories(attrs.Categories, attrs.ExcludeCategories); err != nil {
 		return utils.NewErrServerError(err)
 	}
 	if attrs.DryRun {
@@ -155,11 +175,15 @@ func (self *ApierV2) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 		}
 	}
 
+	if engine.TPLoadNotificationsEnabled() {
+		diff = loader.Diff()
+	}
 	if err := loader.WriteToDatabase(attrs.FlushDb, false, false); err != nil {
 		return utils.NewErrServerError(err)
 	}
 
 	utils.Logger.Info("ApierV2.LoadTariffPlanFromFolder, reloading cache.")
+	prefixIDs := make(map[string][]string) // reloaded as one generation so the rating never sees it partially loaded
 	for _, prfx := range []string{
 		utils.DESTINATION_PREFIX,
 		utils.REVERSE_DESTINATION_PREFIX,
@@ -171,20 +195,14 @@ func (self *ApierV2) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 		utils.ACTION_TRIGGER_PREFIX,
 		utils.SHARED_GROUP_PREFIX,
 		utils.DERIVEDCHARGERS_PREFIX,
-		utils.LCR_PREFIX} {
-		loadedIDs, _ := loader.GetLoadedIds(prfx)
-		if err := self.DataDB.CacheDataFromDB(prfx, loadedIDs, true); err != nil {
-			return utils.NewErrServerError(err)
-		}
-	}
-	for _, prfx := range []string{
+		utils.LCR_PREFIX,
 		utils.ALIASES_PREFIX,
 		utils.REVERSE_ALIASES_PREFIX,
 		utils.ResourceLimitsPrefix} {
-		loadedIDs, _ := loader.GetLoadedIds(prfx)
-		if err := self.DataDB.CacheDataFromDB(prfx, loadedIDs, true); err != nil {
-			return utils.NewErrServerError(err)
-		}
+		prefixIDs[prfx], _ = loader.GetLoadedIds(prfx)
+	}
+	if err := self.DataDB.CacheDataGenerationFromDB(prefixIDs, true); err != nil {
+		return utils.NewErrServerError(err)
 	}
 	aps, _ := loader.GetLoadedIds(utils.ACTION_PLAN_PREFIX)
 	cstKeys, _ := loader.GetLoadedIds(utils.CDR_STATS_PREFIX)
@@ -212,6 +230,7 @@ func (self *ApierV2) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 			return err
 		}
 	}
+	engine.NotifyTPLoaded()
 	loadHistList, err := self.DataDB.GetLoadHistory(1, true, utils.NonTransactional)
 	if err != nil {
 		return err
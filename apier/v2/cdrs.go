@@ -62,6 +62,57 @@ func (apier *ApierV2) CountCdrs(attrs utils.RPCCDRsFilter, reply *int64) error {
 	return nil
 }
 
+type AttrGetCdrsAggregates struct {
+	utils.RPCCDRsFilter
+	GroupBy []string // Group the totals on these fields <Account|Destination|*day|ExtraInfo>, empty for overall totals
+}
+
+// GetCdrsAggregates returns the totals of the CDRs matching the filters, computed inside StorDB
+func (apier *ApierV2) GetCdrsAggregates(attrs AttrGetCdrsAggregates, reply *[]*utils.CDRsAggregate) error {
+	cdrsFltr, err := attrs.AsCDRsFilter(apier.Config.DefaultTimezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	aggrs, err := apier.CdrDb.GetCDRsAggregates(cdrsFltr, attrs.GroupBy)
+	if err != nil {
+		if err.Error() != utils.NotFoundCaps {
+			err = utils.NewErrServerError(err)
+		}
+		return err
+	}
+	*reply = aggrs
+	return nil
+}
+
+type AttrGetCombinedCdr struct {
+	MasterOriginID string   // OriginID of the master session, referenced by legs in MasterOriginID extra field
+	OriginHost     string   // OriginHost of the master session
+	RunIDs         []string // Restrict the view to these runs, all if empty
+}
+
+// GetCombinedCdr returns the master and leg CDRs of a multi-leg call, with usage and cost aggregated per run
+func (apier *ApierV2) GetCombinedCdr(attrs AttrGetCombinedCdr, reply *engine.CombinedCDR) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"MasterOriginID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	var cdrs []*engine.CDR
+	for _, cdrsFltr := range []*utils.CDRsFilter{
+		{CGRIDs: []string{utils.Sha1(attrs.MasterOriginID, attrs.OriginHost)}, RunIDs: attrs.RunIDs},
+		{ExtraFields: map[string]string{utils.MasterOriginID: attrs.MasterOriginID}, RunIDs: attrs.RunIDs},
+	} {
+		fltrCDRs, _, err := apier.CdrDb.GetCDRs(cdrsFltr, false)
+		if err != nil && err.Error() != utils.NotFoundCaps {
+			return utils.NewErrServerError(err)
+		}
+		cdrs = append(cdrs, fltrCDRs...)
+	}
+	if len(cdrs) == 0 {
+		return utils.ErrNotFound
+	}
+	*reply = *engine.NewCombinedCDR(attrs.MasterOriginID, cdrs)
+	return nil
+}
+
 // Receive CDRs via RPC methods, not included with APIer because it has way less dependencies and can be standalone
 type CdrsV2 struct {
 	v1.CdrsV1
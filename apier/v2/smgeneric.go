@@ -33,6 +33,11 @@ func (smgv2 *SMGenericV2) GetMaxUsage(ev sessionmanager.SMGenericEvent, maxUsage
 	return smgv2.SMG.BiRPCV2GetMaxUsage(nil, ev, maxUsage)
 }
 
+// GetMaxUsageWithHints returns maxUsage with block reasons and hints for the configured destination classes
+func (smgv2 *SMGenericV2) GetMaxUsageWithHints(ev sessionmanager.SMGenericEvent, reply *sessionmanager.MaxUsageWithHints) error {
+	return smgv2.SMG.BiRPCV2GetMaxUsageWithHints(nil, ev, reply)
+}
+
 // Called on session start, returns the maximum number of seconds the session can last
 func (smgv2 *SMGenericV2) InitiateSession(ev sessionmanager.SMGenericEvent, maxUsage *time.Duration) error {
 	return smgv2.SMG.BiRPCV2InitiateSession(nil, ev, maxUsage)
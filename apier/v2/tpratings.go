@@ -0,0 +1,89 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package v2
+
+// This file deals with rating related TP objects, validating them against their references before storing
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func (self *ApierV2) tpValidator() *engine.TPValidator {
+	return engine.NewTPValidator(self.StorDb, self.DataDB)
+}
+
+// Creates a new rate within a tariff plan, rejecting unparsable rate slots
+func (self *ApierV2) SetTPRate(attrs utils.TPRate, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "ID", "RateSlots"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := self.tpValidator().ValidateTPRate(&attrs); err != nil {
+		return err
+	}
+	if err := self.StorDb.SetTPRates([]*utils.TPRate{&attrs}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// Creates a new DestinationRate profile within a tariff plan, destinations and rates need to be defined upfront
+func (self *ApierV2) SetTPDestinationRate(attrs utils.TPDestinationRate, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "ID", "DestinationRates"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := self.tpValidator().ValidateTPDestinationRate(&attrs); err != nil {
+		return err
+	}
+	if err := self.StorDb.SetTPDestinationRates([]*utils.TPDestinationRate{&attrs}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// Creates a new RatingPlan within a tariff plan, timings and destination rates need to be defined upfront
+func (self *ApierV2) SetTPRatingPlan(attrs utils.TPRatingPlan, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "ID", "RatingPlanBindings"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := self.tpValidator().ValidateTPRatingPlan(&attrs); err != nil {
+		return err
+	}
+	if err := self.StorDb.SetTPRatingPlans([]*utils.TPRatingPlan{&attrs}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// Creates a new RatingProfile within a tariff plan, rating plans need to be defined upfront
+func (self *ApierV2) SetTPRatingProfile(attrs utils.TPRatingProfile, reply *string) error {
+	if missing := utils.MissingStructFields(&attrs, []string{"TPid", "LoadId", "Tenant", "Category", "Direction", "Subject", "RatingPlanActivations"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := self.tpValidator().ValidateTPRatingProfile(&attrs); err != nil {
+		return err
+	}
+	if err := self.StorDb.SetTPRatingProfiles([]*utils.TPRatingProfile{&attrs}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
@@ -5,6 +5,7 @@ go install github.com/cgrates/cgrates/cmd/cgr-engine
 go install github.com/cgrates/cgrates/cmd/cgr-tester
 go install github.com/cgrates/cgrates/cmd/cgr-console
 go install github.com/cgrates/cgrates/cmd/cgr-loader
+go install github.com/cgrates/cgrates/cmd/cgr-tpgen
 
 
 GIT_LAST_LOG=$(git log -1)
@@ -16,5 +17,7 @@ go install -ldflags "-X 'github.com/cgrates/cgrates/utils.GitLastLog=$GIT_LAST_L
 cc=$?
 go install -ldflags "-X 'github.com/cgrates/cgrates/utils.GitLastLog=$GIT_LAST_LOG'" github.com/cgrates/cgrates/cmd/cgr-tester
 ct=$?
+go install -ldflags "-X 'github.com/cgrates/cgrates/utils.GitLastLog=$GIT_LAST_LOG'" github.com/cgrates/cgrates/cmd/cgr-tpgen
+cg=$?
 
-exit $cr || $cl || $cc || $ct
+exit $cr || $cl || $cc || $ct || $cg
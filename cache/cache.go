@@ -190,6 +190,20 @@ func CountEntries(prefix string) (result int) {
 	return cache.CountEntriesForPrefix(prefix)
 }
 
+// CountBytes returns the estimated size of the entries cached for prefix, 0 when max_bytes is not configured
+func CountBytes(prefix string) int64 {
+	cacheMux.RLock()
+	defer cacheMux.RUnlock()
+	return cache.CountBytesForPrefix(prefix)
+}
+
+// CountEvictions returns the number of entries evicted for prefix in order to stay within the configured bounds
+func CountEvictions(prefix string) int64 {
+	cacheMux.RLock()
+	defer cacheMux.RUnlock()
+	return cache.CountEvictionsForPrefix(prefix)
+}
+
 func GetEntryKeys(prefix string) (keys []string) {
 	cacheMux.RLock()
 	defer cacheMux.RUnlock()
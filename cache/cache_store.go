@@ -23,7 +23,6 @@ import (
 
 	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/utils"
-	lru "github.com/hashicorp/golang-lru"
 )
 
 type cacheStore interface {
@@ -33,6 +32,8 @@ type cacheStore interface {
 	DeletePrefix(string)
 	CountEntriesForPrefix(string) int
 	GetKeysForPrefix(string) []string
+	CountBytesForPrefix(string) int64
+	CountEvictionsForPrefix(string) int64
 }
 
 // easy to be counted exported by prefix
@@ -93,6 +94,16 @@ func (cs cacheDoubleStore) CountEntriesForPrefix(prefix string) int {
 	return 0
 }
 
+// CountBytesForPrefix always returns 0, the store being unbounded
+func (cs *cacheDoubleStore) CountBytesForPrefix(prefix string) int64 {
+	return 0
+}
+
+// CountEvictionsForPrefix always returns 0, the store being unbounded
+func (cs *cacheDoubleStore) CountEvictionsForPrefix(prefix string) int64 {
+	return 0
+}
+
 func (cs cacheDoubleStore) GetKeysForPrefix(prefix string) (keys []string) {
 	cs.RLock()
 	defer cs.RUnlock()
@@ -108,74 +119,74 @@ func (cs cacheDoubleStore) GetKeysForPrefix(prefix string) (keys []string) {
 }
 
 // easy to be counted exported by prefix
-type lrustore map[string]*lru.Cache
+type lrustore map[string]*lruBytes
 
 func newLruStore() lrustore {
-	c := make(map[string]*lru.Cache)
+	c := make(map[string]*lruBytes)
 	if cfg != nil && cfg.Destinations != nil {
-		c[utils.DESTINATION_PREFIX], _ = lru.New(cfg.Destinations.Limit)
+		c[utils.DESTINATION_PREFIX], _ = newLRUBytes(cfg.Destinations.Limit, cfg.Destinations.MaxBytes)
 	} else {
-		c[utils.DESTINATION_PREFIX], _ = lru.New(10000)
+		c[utils.DESTINATION_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.ReverseDestinations != nil {
-		c[utils.REVERSE_DESTINATION_PREFIX], _ = lru.New(cfg.ReverseDestinations.Limit)
+		c[utils.REVERSE_DESTINATION_PREFIX], _ = newLRUBytes(cfg.ReverseDestinations.Limit, cfg.ReverseDestinations.MaxBytes)
 	} else {
-		c[utils.REVERSE_DESTINATION_PREFIX], _ = lru.New(10000)
+		c[utils.REVERSE_DESTINATION_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.RatingPlans != nil {
-		c[utils.RATING_PLAN_PREFIX], _ = lru.New(cfg.RatingPlans.Limit)
+		c[utils.RATING_PLAN_PREFIX], _ = newLRUBytes(cfg.RatingPlans.Limit, cfg.RatingPlans.MaxBytes)
 	} else {
-		c[utils.RATING_PLAN_PREFIX], _ = lru.New(10000)
+		c[utils.RATING_PLAN_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.RatingProfiles != nil {
-		c[utils.RATING_PROFILE_PREFIX], _ = lru.New(cfg.RatingProfiles.Limit)
+		c[utils.RATING_PROFILE_PREFIX], _ = newLRUBytes(cfg.RatingProfiles.Limit, cfg.RatingProfiles.MaxBytes)
 	} else {
-		c[utils.RATING_PROFILE_PREFIX], _ = lru.New(10000)
+		c[utils.RATING_PROFILE_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.Lcr != nil {
-		c[utils.LCR_PREFIX], _ = lru.New(cfg.Lcr.Limit)
+		c[utils.LCR_PREFIX], _ = newLRUBytes(cfg.Lcr.Limit, cfg.Lcr.MaxBytes)
 	} else {
-		c[utils.LCR_PREFIX], _ = lru.New(10000)
+		c[utils.LCR_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.CdrStats != nil {
-		c[utils.CDR_STATS_PREFIX], _ = lru.New(cfg.CdrStats.Limit)
+		c[utils.CDR_STATS_PREFIX], _ = newLRUBytes(cfg.CdrStats.Limit, cfg.CdrStats.MaxBytes)
 	} else {
-		c[utils.CDR_STATS_PREFIX], _ = lru.New(10000)
+		c[utils.CDR_STATS_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.Actions != nil {
-		c[utils.ACTION_PREFIX], _ = lru.New(cfg.Actions.Limit)
+		c[utils.ACTION_PREFIX], _ = newLRUBytes(cfg.Actions.Limit, cfg.Actions.MaxBytes)
 	} else {
-		c[utils.ACTION_PREFIX], _ = lru.New(10000)
+		c[utils.ACTION_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.ActionPlans != nil {
-		c[utils.ACTION_PLAN_PREFIX], _ = lru.New(cfg.ActionPlans.Limit)
+		c[utils.ACTION_PLAN_PREFIX], _ = newLRUBytes(cfg.ActionPlans.Limit, cfg.ActionPlans.MaxBytes)
 	} else {
-		c[utils.ACTION_PLAN_PREFIX], _ = lru.New(10000)
+		c[utils.ACTION_PLAN_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.AccountActionPlans != nil {
-		c[utils.AccountActionPlansPrefix], _ = lru.New(cfg.AccountActionPlans.Limit)
+		c[utils.AccountActionPlansPrefix], _ = newLRUBytes(cfg.AccountActionPlans.Limit, cfg.AccountActionPlans.MaxBytes)
 	} else {
-		c[utils.AccountActionPlansPrefix], _ = lru.New(10000)
+		c[utils.AccountActionPlansPrefix], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.ActionTriggers != nil {
-		c[utils.ACTION_TRIGGER_PREFIX], _ = lru.New(cfg.ActionTriggers.Limit)
+		c[utils.ACTION_TRIGGER_PREFIX], _ = newLRUBytes(cfg.ActionTriggers.Limit, cfg.ActionTriggers.MaxBytes)
 	} else {
-		c[utils.ACTION_TRIGGER_PREFIX], _ = lru.New(10000)
+		c[utils.ACTION_TRIGGER_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.SharedGroups != nil {
-		c[utils.SHARED_GROUP_PREFIX], _ = lru.New(cfg.SharedGroups.Limit)
+		c[utils.SHARED_GROUP_PREFIX], _ = newLRUBytes(cfg.SharedGroups.Limit, cfg.SharedGroups.MaxBytes)
 	} else {
-		c[utils.SHARED_GROUP_PREFIX], _ = lru.New(10000)
+		c[utils.SHARED_GROUP_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.Aliases != nil {
-		c[utils.ALIASES_PREFIX], _ = lru.New(cfg.Aliases.Limit)
+		c[utils.ALIASES_PREFIX], _ = newLRUBytes(cfg.Aliases.Limit, cfg.Aliases.MaxBytes)
 	} else {
-		c[utils.ALIASES_PREFIX], _ = lru.New(10000)
+		c[utils.ALIASES_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 	if cfg != nil && cfg.ReverseAliases != nil {
-		c[utils.REVERSE_ALIASES_PREFIX], _ = lru.New(cfg.ReverseAliases.Limit)
+		c[utils.REVERSE_ALIASES_PREFIX], _ = newLRUBytes(cfg.ReverseAliases.Limit, cfg.ReverseAliases.MaxBytes)
 	} else {
-		c[utils.REVERSE_ALIASES_PREFIX], _ = lru.New(10000)
+		c[utils.REVERSE_ALIASES_PREFIX], _ = newLRUBytes(10000, 0)
 	}
 
 	return c
@@ -186,7 +197,7 @@ func (cs lrustore) Put(key string, value interface{}) {
 	mp, ok := cs[prefix]
 	if !ok {
 		var err error
-		mp, err = lru.New(10000)
+		mp, err = newLRUBytes(10000, 0)
 		if err != nil {
 			return
 		}
@@ -213,7 +224,9 @@ func (cs lrustore) Delete(key string) {
 }
 
 func (cs lrustore) DeletePrefix(prefix string) {
-	delete(cs, prefix)
+	if keyMap, ok := cs[prefix]; ok { // keep the configured bounds
+		keyMap.Purge()
+	}
 }
 
 func (cs lrustore) CountEntriesForPrefix(prefix string) int {
@@ -223,6 +236,20 @@ func (cs lrustore) CountEntriesForPrefix(prefix string) int {
 	return 0
 }
 
+func (cs lrustore) CountBytesForPrefix(prefix string) int64 {
+	if m, ok := cs[prefix]; ok {
+		return m.Bytes()
+	}
+	return 0
+}
+
+func (cs lrustore) CountEvictionsForPrefix(prefix string) int64 {
+	if m, ok := cs[prefix]; ok {
+		return m.Evictions()
+	}
+	return 0
+}
+
 func (cs lrustore) GetKeysForPrefix(prefix string) (keys []string) {
 	prefix, key := prefix[:PREFIX_LEN], prefix[PREFIX_LEN:]
 	if keyMap, ok := cs[prefix]; ok {
@@ -149,3 +149,49 @@ func TestCacheConcurrent(t *testing.T) {
 	s.Prefix = "+491"
 	wg.Wait()
 }
+
+func TestCacheLRUBytesLimit(t *testing.T) {
+	lb, err := newLRUBytes(2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		lb.Add(key, key)
+	}
+	if lb.Len() != 2 || lb.Evictions() != 1 || lb.Bytes() != 0 {
+		t.Errorf("Unexpected len: %d, evictions: %d, bytes: %d", lb.Len(), lb.Evictions(), lb.Bytes())
+	}
+	if _, has := lb.Get("a"); has {
+		t.Error("Least recently used item not evicted")
+	}
+}
+
+func TestCacheLRUBytesMaxBytes(t *testing.T) {
+	itmSize := itemSize("a", "value1") // same size for all the items below
+	lb, err := newLRUBytes(100, int(2*itmSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lb.Add("a", "value1")
+	lb.Add("b", "value2")
+	lb.Get("a") // b becomes the least recently used
+	lb.Add("c", "value3")
+	if lb.Len() != 2 || lb.Evictions() != 1 || lb.Bytes() != 2*itmSize {
+		t.Errorf("Unexpected len: %d, evictions: %d, bytes: %d", lb.Len(), lb.Evictions(), lb.Bytes())
+	}
+	if _, has := lb.Get("b"); has {
+		t.Error("Least recently used item not evicted")
+	}
+	lb.Add("c", "value") // replacing accounts the new size
+	if lb.Bytes() != 2*itmSize-1 {
+		t.Errorf("Unexpected bytes: %d", lb.Bytes())
+	}
+	lb.Remove("a") // removals are not evictions
+	if lb.Evictions() != 1 || lb.Bytes() != itmSize-1 {
+		t.Errorf("Unexpected evictions: %d, bytes: %d", lb.Evictions(), lb.Bytes())
+	}
+	lb.Purge()
+	if lb.Len() != 0 || lb.Bytes() != 0 {
+		t.Errorf("Unexpected len: %d, bytes: %d", lb.Len(), lb.Bytes())
+	}
+}
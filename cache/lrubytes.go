@@ -0,0 +1,131 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package cache
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// lruBytes is an LRU bounded on the number of items and optionally on their estimated size in bytes,
+// counting the items evicted to stay within the bounds
+type lruBytes struct {
+	sync.Mutex
+	lru       *simplelru.LRU
+	maxBytes  int64            // 0 disables size accounting
+	sizes     map[string]int64 // estimated size per key
+	bytes     int64
+	evictions int64
+}
+
+func newLRUBytes(limit, maxBytes int) (lb *lruBytes, err error) {
+	lb = &lruBytes{maxBytes: int64(maxBytes)}
+	if lb.maxBytes > 0 {
+		lb.sizes = make(map[string]int64)
+	}
+	if lb.lru, err = simplelru.NewLRU(limit, lb.onRemove); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// onRemove is called by lru with the lock held
+func (lb *lruBytes) onRemove(key, value interface{}) {
+	if lb.sizes == nil {
+		return
+	}
+	lb.bytes -= lb.sizes[key.(string)]
+	delete(lb.sizes, key.(string))
+}
+
+// itemSize estimates the memory used by an item out of its JSON encoding
+func itemSize(key string, value interface{}) int64 {
+	size := int64(len(key))
+	if b, err := json.Marshal(value); err == nil {
+		size += int64(len(b))
+	}
+	return size
+}
+
+func (lb *lruBytes) Add(key string, value interface{}) {
+	lb.Lock()
+	defer lb.Unlock()
+	if lb.sizes != nil {
+		if lb.lru.Contains(key) {
+			lb.lru.Remove(key) // account the new size
+		}
+		size := itemSize(key, value)
+		lb.sizes[key] = size
+		lb.bytes += size
+	}
+	if lb.lru.Add(key, value) {
+		lb.evictions++
+	}
+	for lb.sizes != nil && lb.bytes > lb.maxBytes {
+		if _, _, ok := lb.lru.RemoveOldest(); !ok {
+			break
+		}
+		lb.evictions++
+	}
+}
+
+func (lb *lruBytes) Get(key string) (interface{}, bool) {
+	lb.Lock()
+	defer lb.Unlock()
+	return lb.lru.Get(key)
+}
+
+func (lb *lruBytes) Remove(key string) {
+	lb.Lock()
+	lb.lru.Remove(key)
+	lb.Unlock()
+}
+
+func (lb *lruBytes) Purge() {
+	lb.Lock()
+	lb.lru.Purge()
+	lb.Unlock()
+}
+
+func (lb *lruBytes) Keys() []interface{} {
+	lb.Lock()
+	defer lb.Unlock()
+	return lb.lru.Keys()
+}
+
+func (lb *lruBytes) Len() int {
+	lb.Lock()
+	defer lb.Unlock()
+	return lb.lru.Len()
+}
+
+// Bytes returns the estimated size of the cached items, 0 without size accounting
+func (lb *lruBytes) Bytes() int64 {
+	lb.Lock()
+	defer lb.Unlock()
+	return lb.bytes
+}
+
+// Evictions returns the number of items evicted to stay within the bounds
+func (lb *lruBytes) Evictions() int64 {
+	lb.Lock()
+	defer lb.Unlock()
+	return lb.evictions
+}
@@ -60,6 +60,7 @@ const (
 var (
 	cfgDir            = flag.String("config_dir", utils.CONFIG_DIR, "Configuration directory path.")
 	version           = flag.Bool("version", false, "Prints the application version.")
+	checkConfig       = flag.Bool("check_config", false, "Verify the configuration inside config_dir and exit without starting the engine.")
 	pidFile           = flag.String("pid", "", "Write pid file")
 	cpuprofile        = flag.String("cpuprofile", "", "write cpu profile to file")
 	scheduledShutdown = flag.String("scheduled_shutdown", "", "shutdown the engine after this duration")
@@ -282,6 +283,21 @@ func startRadiusAgent(internalSMGChan chan *sessionmanager.SMGeneric, exitChan c
 	exitChan <- true
 }
 
+func startNatsAgent(internalCdrSChan chan rpcclient.RpcClientConnection, exitChan chan bool) {
+	utils.Logger.Info("Starting CGRateS NatsAgent service")
+	cdrsConn, err := engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+		cfg.NatsAgentCfg().CDRsConns, internalCdrSChan, cfg.InternalTtl)
+	if err != nil {
+		utils.Logger.Crit(fmt.Sprintf("<NatsAgent> Could not connect to CDRS: %s", err.Error()))
+		exitChan <- true
+		return
+	}
+	if err = agents.NewNatsAgent(cfg, cdrsConn).ListenAndServe(); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<NatsAgent> error: <%s>", err.Error()))
+	}
+	exitChan <- true
+}
+
 func startSmFreeSWITCH(internalRaterChan, internalCDRSChan, rlsChan chan rpcclient.RpcClientConnection, cdrDb engine.CdrStorage, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS SMFreeSWITCH service")
 	var ralsConn, cdrsConn, rlsConn *rpcclient.RpcClientPool
@@ -390,7 +406,7 @@ func startSmOpenSIPS(internalRaterChan, internalCDRSChan chan rpcclient.RpcClien
 func startCDRS(internalCdrSChan chan rpcclient.RpcClientConnection, cdrDb engine.CdrStorage, dataDB engine.DataDB,
 	internalRaterChan chan rpcclient.RpcClientConnection, internalPubSubSChan chan rpcclient.RpcClientConnection,
 	internalUserSChan chan rpcclient.RpcClientConnection, internalAliaseSChan chan rpcclient.RpcClientConnection,
-	internalCdrStatSChan chan rpcclient.RpcClientConnection, server *utils.Server, exitChan chan bool) {
+	internalCdrStatSChan chan rpcclient.RpcClientConnection, jobs *utils.JobManager, server *utils.Server, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS CDRS service.")
 	var ralConn, pubSubConn, usersConn, aliasesConn, statsConn *rpcclient.RpcClientPool
 	if len(cfg.CDRSRaterConns) != 0 { // Conn pool towards RAL
@@ -444,7 +460,7 @@ func startCDRS(internalCdrSChan chan rpcclient.RpcClientConnection, cdrDb engine
 	utils.Logger.Info("Registering CDRS HTTP Handlers.")
 	cdrServer.RegisterHandlersToServer(server)
 	utils.Logger.Info("Registering CDRS RPC service.")
-	cdrSrv := v1.CdrsV1{CdrSrv: cdrServer}
+	cdrSrv := v1.CdrsV1{CdrSrv: cdrServer, Jobs: jobs}
 	server.RpcRegister(&cdrSrv)
 	server.RpcRegister(&v2.CdrsV2{CdrsV1: cdrSrv})
 	// Make the cdr server available for internal communication
@@ -609,6 +625,16 @@ func main() {
 		fmt.Println(utils.GetCGRVersion())
 		return
 	}
+	if *checkConfig {
+		if errs := config.ValidateCGRConfigFolder(*cfgDir); len(errs) != 0 {
+			for _, err := range errs {
+				fmt.Println(err)
+			}
+			os.Exit(1)
+		}
+		fmt.Println("Configuration OK")
+		return
+	}
 	if *pidFile != "" {
 		writePid()
 	}
@@ -669,6 +695,41 @@ func main() {
 			return
 		}
 		defer dataDB.Close()
+		if cfg.DataDbBreaker.MaxFailures > 0 {
+			dataDB = engine.NewBreakerDataDB(dataDB, utils.NewCircuitBreaker(cfg.DataDbBreaker.MaxFailures,
+				cfg.DataDbBreaker.SlowCall, cfg.DataDbBreaker.OpenInterval))
+		}
+		if tierCfg := cfg.DataDbLocalTier; tierCfg.DbType != "" {
+			var localDB engine.DataDB
+			if tierCfg.DbType == utils.MetaInternal {
+				localDB, err = engine.NewMapStorage()
+			} else {
+				localDB, err = engine.ConfigureDataStorage(tierCfg.DbType, tierCfg.DbHost, tierCfg.DbPort,
+					tierCfg.DbName, "", tierCfg.DbPassword, cfg.DBDataEncoding, cfg.CacheConfig, cfg.LoadHistorySize)
+			}
+			if err != nil {
+				utils.Logger.Crit(fmt.Sprintf("Could not configure local dataDb tier: %s exiting!", err))
+				return
+			}
+			defer localDB.Close()
+			dataDB = engine.NewTieredDataDB(localDB, dataDB, tierCfg.HotTTL)
+		}
+		dataDB = engine.NewCoalescingDataDB(dataDB)
+		if cfg.DataDbDestinationsIndex {
+			destIdxDB, err := engine.NewDestinationsIndexDataDB(dataDB, cfg.DataDbDestIndexType)
+			if err != nil {
+				utils.Logger.Crit(fmt.Sprintf("Could not configure destinations index: %s exiting!", err))
+				return
+			}
+			if err := destIdxDB.LoadIndex(); err != nil {
+				utils.Logger.Crit(fmt.Sprintf("Could not load destinations index: %s exiting!", err))
+				return
+			}
+			dataDB = destIdxDB
+		} else if err := engine.LoadIPPrefixLengths(dataDB); err != nil {
+			utils.Logger.Crit(fmt.Sprintf("Could not load IP destination prefixes: %s exiting!", err))
+			return
+		}
 		engine.SetDataStorage(dataDB)
 		if err := engine.CheckVersion(nil); err != nil {
 			fmt.Println(err.Error())
@@ -686,16 +747,72 @@ func main() {
 		// loadDb,cdrDb and storDb are all mapped on the same stordb storage
 		loadDb = storDb.(engine.LoadStorage)
 		cdrDb = storDb.(engine.CdrStorage)
+		if cfg.StorDBCdrsClickHouse.URL != "" { // CDRs into ClickHouse, SMCosts remaining in stor_db
+			chCdrs, err := engine.NewClickHouseCdrStorage(cdrDb, cfg.StorDBCdrsClickHouse.URL, cfg.StorDBCdrsClickHouse.User,
+				cfg.StorDBCdrsClickHouse.Password, cfg.StorDBCdrsClickHouse.BatchSize,
+				cfg.StorDBCdrsClickHouse.FlushInterval, cfg.StorDBCdrsClickHouse.TTL)
+			if err != nil {
+				utils.Logger.Crit(fmt.Sprintf("Could not configure ClickHouse CDR storage: %s exiting!", err))
+				return
+			}
+			defer chCdrs.Close()
+			cdrDb = chCdrs
+		}
+		if len(cfg.StorDBCDRSIndexedFields) != 0 {
+			if err := cdrDb.SetCDRIndexedFields(cfg.StorDBCDRSIndexedFields); err != nil {
+				utils.Logger.Crit(fmt.Sprintf("Could not index CDR fields: %s exiting!", err))
+				return
+			}
+		}
+		if cfg.StorDBBreaker.MaxFailures > 0 {
+			cdrDb = engine.NewBreakerCdrStorage(cdrDb, utils.NewCircuitBreaker(cfg.StorDBBreaker.MaxFailures,
+				cfg.StorDBBreaker.SlowCall, cfg.StorDBBreaker.OpenInterval))
+		}
 		engine.SetCdrStorage(cdrDb)
 	}
 
 	engine.SetRoundingDecimals(cfg.RoundingDecimals)
+	utils.SetDecimalPrecision(cfg.DecimalPrecision)
+	engine.SetTPSnapshotsSize(cfg.TPSnapshotsSize)
+	engine.SetRatingPlanSplitSize(cfg.DataDbRpSplitSize)
 	engine.SetRpSubjectPrefixMatching(cfg.RpSubjectPrefixMatching)
+	engine.SetRpFallbackMaxDepth(cfg.RpFallbackMaxDepth)
+	engine.SetRpCompiledPlans(cfg.RpCompiledPlans)
 	engine.SetLcrSubjectPrefixMatching(cfg.LcrSubjectPrefixMatching)
+	engine.SetLCRBlacklist(cfg.LcrBlacklistFailures, cfg.LcrBlacklistCooldown)
+	engine.SetEmergencyDestinations(cfg.EmergencyDestinations)
+	engine.SetBalanceExpiryNotify(cfg.ExpiryNotifyDays, cfg.ExpiryNotifyActions)
+	if cfg.RALsBalanceNotifyAddress != "" {
+		engine.SetBalanceNotifier(engine.NewBalancePoster(cfg.RALsBalanceNotifyAddress, cfg.PosterAttempts, cfg.FailedPostsDir,
+			utils.NewHTTPPoster(cfg.HttpSkipTlsVerify, cfg.ReplyTimeout)))
+	}
+	if len(cfg.TPLoadNotifyAddresses) != 0 {
+		engine.SetTPLoadNotifier(engine.NewTPLoadPoster(cfg.TPLoadNotifyAddresses, cfg.PosterAttempts, cfg.FailedPostsDir,
+			utils.NewHTTPPoster(cfg.HttpSkipTlsVerify, cfg.ReplyTimeout)), cfg.InstanceID)
+	}
+	engine.SetDebugRecordsTTL(cfg.DebugRecordsTTL)
+	engine.SetNotifier(utils.MetaMail, engine.NewSMTPNotifier(cfg.MailerServer, cfg.MailerAuthUser, cfg.MailerAuthPass, cfg.MailerFromAddr))
+	switch cfg.SmsTransport {
+	case utils.MetaHTTP:
+		engine.SetNotifier(utils.SMS, engine.NewHTTPSMSNotifier(cfg.SmsAddress, cfg.SmsAuthUser, cfg.SmsAuthPass, cfg.SmsFromAddr,
+			cfg.HttpSkipTlsVerify, cfg.ReplyTimeout))
+	case utils.MetaSMPP:
+		engine.SetNotifier(utils.SMS, engine.NewSMPPNotifier(cfg.SmsAddress, cfg.SmsAuthUser, cfg.SmsAuthPass, cfg.SmsFromAddr))
+	}
+	if replicationConns := cfg.ReplicationCfg().ReplicationConns; len(replicationConns) != 0 {
+		replicaConns, err := engine.NewRPCPool(rpcclient.POOL_BROADCAST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+			replicationConns, nil, cfg.InternalTtl)
+		if err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<Replication> Could not connect to replicas: %s exiting!", err))
+			return
+		}
+		engine.SetReplicaFeeder(engine.NewReplicaFeeder(replicaConns, cfg.ReplicationCfg().ReplicationInterval))
+	}
 	stopHandled := false
 
 	// Rpc/http server
 	server := new(utils.Server)
+	server.SetReadOnly(cfg.ReplicationCfg().ReadOnly)
 
 	// Async starts here, will follow cgrates.json start order
 
@@ -711,24 +828,33 @@ func main() {
 	internalSMGChan := make(chan *sessionmanager.SMGeneric, 1)
 	internalRLSChan := make(chan rpcclient.RpcClientConnection, 1)
 
+	// Asynchronous jobs started over APIs, shared between services
+	jobs := utils.NewJobManager(cfg.JobsTTL)
+	utils.ClockSkews.SetThreshold(cfg.ClockSkewThreshold)
+
 	// Start ServiceManager
 	srvManager := servmanager.NewServiceManager(cfg, dataDB, exitChan, cacheDoneChan)
+	engine.SetSchedulerReloader(func() {
+		if sched := srvManager.GetScheduler(); sched != nil {
+			sched.Reload()
+		}
+	})
 
 	// Start rater service
 	if cfg.RALsEnabled {
 		go startRater(internalRaterChan, cacheDoneChan, internalCdrStatSChan, internalHistorySChan, internalPubSubSChan, internalUserSChan, internalAliaseSChan,
-			srvManager, server, dataDB, loadDb, cdrDb, &stopHandled, exitChan)
+			srvManager, jobs, server, dataDB, loadDb, cdrDb, &stopHandled, exitChan)
 	}
 
-	// Start Scheduler
-	if cfg.SchedulerEnabled {
+	// Start Scheduler, the read-only replicas get the scheduled changes out of the primary
+	if cfg.SchedulerEnabled && !cfg.ReplicationCfg().ReadOnly {
 		go srvManager.StartScheduler(true)
 	}
 
 	// Start CDR Server
 	if cfg.CDRSEnabled {
 		go startCDRS(internalCdrSChan, cdrDb, dataDB,
-			internalRaterChan, internalPubSubSChan, internalUserSChan, internalAliaseSChan, internalCdrStatSChan, server, exitChan)
+			internalRaterChan, internalPubSubSChan, internalUserSChan, internalAliaseSChan, internalCdrStatSChan, jobs, server, exitChan)
 	}
 
 	// Start CDR Stats server
@@ -778,6 +904,10 @@ func main() {
 		go startRadiusAgent(internalSMGChan, exitChan)
 	}
 
+	if cfg.NatsAgentCfg().Enabled {
+		go startNatsAgent(internalCdrSChan, exitChan)
+	}
+
 	// Start HistoryS service
 	if cfg.HistoryServerEnabled {
 		go startHistoryServer(internalHistorySChan, server, exitChan)
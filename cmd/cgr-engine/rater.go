@@ -44,7 +44,7 @@ import (
 func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneChan chan struct{},
 	internalCdrStatSChan chan rpcclient.RpcClientConnection, internalHistorySChan chan rpcclient.RpcClientConnection,
 	internalPubSubSChan chan rpcclient.RpcClientConnection, internalUserSChan chan rpcclient.RpcClientConnection, internalAliaseSChan chan rpcclient.RpcClientConnection,
-	serviceManager *servmanager.ServiceManager, server *utils.Server,
+	serviceManager *servmanager.ServiceManager, jobs *utils.JobManager, server *utils.Server,
 	dataDB engine.DataDB, loadDb engine.LoadStorage, cdrDb engine.CdrStorage, stopHandled *bool, exitChan chan bool) {
 	var waitTasks []chan struct{}
 
@@ -200,7 +200,8 @@ func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneC
 	responder := &engine.Responder{ExitChan: exitChan}
 	responder.SetTimeToLive(cfg.ResponseCacheTTL, nil)
 	apierRpcV1 := &v1.ApierV1{StorDb: loadDb, DataDB: dataDB, CdrDb: cdrDb,
-		Config: cfg, Responder: responder, ServManager: serviceManager, HTTPPoster: utils.NewHTTPPoster(cfg.HttpSkipTlsVerify, cfg.ReplyTimeout)}
+		Config: cfg, Responder: responder, ServManager: serviceManager, HTTPPoster: utils.NewHTTPPoster(cfg.HttpSkipTlsVerify, cfg.ReplyTimeout),
+		TpReaders: engine.NewTpReaderPool(dataDB, loadDb, cfg.DefaultTimezone, cfg.TenantTimezones), Jobs: jobs}
 	if cdrStats != nil { // ToDo: Fix here properly the init of stats
 		responder.Stats = cdrStats
 		apierRpcV1.CdrStatsSrv = cdrStats
@@ -214,6 +215,9 @@ func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneC
 	server.RpcRegister(responder)
 	server.RpcRegister(apierRpcV1)
 	server.RpcRegister(apierRpcV2)
+	if cfg.ReplicationCfg().ReadOnly {
+		server.RpcRegister(&v1.ReplicaV1{DataDB: dataDB, CdrDb: cdrDb})
+	}
 
 	utils.RegisterRpcParams("", &engine.Stats{})
 	utils.RegisterRpcParams("", &v1.CDRStatsV1{})
@@ -18,9 +18,11 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -56,14 +58,17 @@ var (
 	dbdata_encoding = flag.String("dbdata_encoding", cgrConfig.DBDataEncoding, "The encoding used to store object data in strings")
 
 	flush           = flag.Bool("flushdb", false, "Flush the database before importing")
-	tpid            = flag.String("tpid", "", "The tariff plan id from the database")
-	dataPath        = flag.String("path", "./", "The path to folder containing the data files")
+	tpid            = flag.String("tpid", "", "The tariff plan id from the database, multiple ones separated by ; are merged in order, eg: BASE;CUSTOMER1")
+	tpidConflicts   = flag.String("tpid_conflicts", utils.MetaError, "Resolve the objects defined in more than one of the merged tpids <*error|*first|*last>")
+	dataPath        = flag.String("path", "./", "The path to folder, archive (.zip|.tar|.tar.gz|.tgz), workbook (.xlsx) or workbook URL (eg: Google Sheets) containing the data files")
 	version         = flag.Bool("version", false, "Prints the application version.")
 	verbose         = flag.Bool("verbose", false, "Enable detailed verbose logging output")
 	dryRun          = flag.Bool("dry_run", false, "When true will not save loaded data to dataDb but just parse it for consistency and errors.")
 	validate        = flag.Bool("validate", false, "When true will run various check on the loaded data to check for structural errors")
+	lint            = flag.Bool("lint", false, "Report the rates, timings, actions and destinations defined but not referenced")
 	stats           = flag.Bool("stats", false, "Generates statsistics about given data.")
 	fromStorDb      = flag.Bool("from_stordb", false, "Load the tariff plan from storDb to dataDb")
+	fromRPC         = flag.String("from_rpc", "", "Load the tariff plan with -tpid from the storDb of the engine reachable over RPC at this address")
 	toStorDb        = flag.Bool("to_stordb", false, "Import the tariff plan from files to storDb")
 	rpcEncoding     = flag.String("rpc_encoding", "json", "RPC encoding used <gob|json>")
 	historyServer   = flag.String("historys", cgrConfig.RPCJSONListen, "The history server address:port, empty to disable automatic history archiving")
@@ -72,8 +77,20 @@ var (
 	usersAddress    = flag.String("users", cgrConfig.RPCJSONListen, "Users service to contact for data reloads, empty to disable automatic data reloads")
 	runId           = flag.String("runid", "", "Uniquely identify an import/load, postpended to some automatic fields")
 	loadHistorySize = flag.Int("load_history_size", cgrConfig.LoadHistorySize, "Limit the number of records in the load history")
+	tpSnapshotsSize = flag.Int("tp_snapshots_size", cgrConfig.TPSnapshotsSize, "Limit the number of tariff plan snapshots kept for rollbacks, 0 to disable")
+	rpSplitSize     = flag.Int("rp_split_size", cgrConfig.DataDbRpSplitSize, "Store rating plans with more destinations split and load them per destination on demand, 0 to disable")
 	timezone        = flag.String("timezone", cgrConfig.DefaultTimezone, `Timezone for timestamps where not specified <""|UTC|Local|$IANA_TZ_DB>`)
+	tenantTimezones = flag.String("tenant_timezones", "", "Timezone overrides per tenant, eg: cgrates.org:Europe/Berlin;itsyscom.com:UTC")
+	compressDsts    = flag.Bool("compress_destinations", false, "Collapse the redundant destination prefixes, eg: all 10 children of a prefix into their parent")
 	disable_reverse = flag.Bool("disable_reverse_mappings", false, "Will disable reverse mappings rebuilding")
+	disableRevFor   = flag.String("disable_reverse_for", "", "Disable rebuilding only these reverse mappings, separated by ;, eg: *destinations;*account_action_plans;*aliases")
+	categories      = flag.String("categories", "", "Load only these categories, separated by ;, eg: *destinations;*rates;*rating_plans")
+	exclCategories  = flag.String("exclude_categories", "", "Do not load these categories, separated by ;, eg: *account_actions;*aliases")
+	varsFile        = flag.String("vars_file", "", "File with NAME=VALUE definitions for the ${NAME} references inside the tariff plan files")
+	xlsxMappings    = flag.String("xlsx_mappings", "", "JSON file with the sheet layout of the workbook, eg: {\"Rates\": {\"Sheet\": \"Pricing\", \"Columns\": [\"B\", \"A\"], \"SkipRows\": 1}}")
+	varsEnv         = flag.Bool("vars_env", false, "Resolve the ${NAME} references missing from -vars_file out of the environment")
+	tolerant        = flag.Bool("tolerant", false, "Quarantine the malformed rows of the tariff plan files instead of aborting the load of their category")
+	quarantineRpt   = flag.String("quarantine_report", "", "CSV file receiving the rows quarantined in tolerant mode as: file,line,column,reason")
 )
 
 func main() {
@@ -198,6 +215,21 @@ func main() {
 		log.Print("Done migrating!")
 		return
 	}
+	var tpVars *engine.TPVariables // expanded inside the tariff plan files
+	if *varsFile != "" || *varsEnv {
+		tpVars = &engine.TPVariables{UseEnv: *varsEnv}
+		if *varsFile != "" {
+			if tpVars.Values, err = engine.LoadTPVariablesFile(*varsFile); err != nil {
+				log.Fatalf("Could not read variables file %s: %v", *varsFile, err)
+			}
+		}
+	}
+	var sheetMappings engine.XLSXMappings
+	if *xlsxMappings != "" {
+		if sheetMappings, err = engine.LoadXLSXMappingsFile(*xlsxMappings); err != nil {
+			log.Fatalf("Could not read sheet mappings %s: %v", *xlsxMappings, err)
+		}
+	}
 	// Init necessary db connections, only if not already
 	if !*dryRun { // make sure we do not need db connections on dry run, also not importing into any stordb
 		if *fromStorDb {
@@ -227,12 +259,14 @@ func main() {
 				log.Fatal("TPid required, please define it via *-tpid* command argument.")
 			}
 			csvImporter := engine.TPCSVImporter{
-				TPid:     *tpid,
-				StorDb:   storDb,
-				DirPath:  *dataPath,
-				Sep:      ',',
-				Verbose:  *verbose,
-				ImportId: *runId,
+				TPid:      *tpid,
+				StorDb:    storDb,
+				DirPath:   *dataPath,
+				Sep:       ',',
+				Verbose:   *verbose,
+				ImportId:  *runId,
+				Variables: tpVars,
+				Mappings:  sheetMappings,
 			}
 			if errImport := csvImporter.Run(); errImport != nil {
 				log.Fatal(errImport)
@@ -242,6 +276,25 @@ func main() {
 	}
 	if *fromStorDb { // Load Tariff Plan from storDb into dataDb
 		loader = storDb
+	} else if *fromRPC != "" { // Load Tariff Plan from the storDb of another engine into dataDb
+		remoteConn, err := rpcclient.NewRpcClient("tcp", *fromRPC, 3, 3,
+			time.Duration(1*time.Second), time.Duration(5*time.Minute), *rpcEncoding, nil, false)
+		if err != nil {
+			log.Fatalf("Could not connect to remote engine %s: %s", *fromRPC, err.Error())
+		}
+		loader = engine.NewRPCLoadReader(remoteConn)
+	} else if engine.IsTPArchive(*dataPath) { // Load csv files out of an archive to dataDb
+		if loader, err = engine.NewArchiveCSVStorage(',', *dataPath); err != nil {
+			log.Fatalf("Could not read archive %s: %v", *dataPath, err)
+		}
+	} else if engine.IsRemoteWorkbook(*dataPath) { // Load the sheets of a downloaded workbook to dataDb
+		if loader, err = engine.NewRemoteXLSXStorage(',', *dataPath, sheetMappings); err != nil {
+			log.Fatalf("Could not read workbook %s: %v", *dataPath, err)
+		}
+	} else if strings.HasSuffix(*dataPath, utils.XLSXSuffix) { // Load the sheets of a workbook to dataDb
+		if loader, err = engine.NewXLSXStorage(',', *dataPath, sheetMappings); err != nil {
+			log.Fatalf("Could not read workbook %s: %v", *dataPath, err)
+		}
 	} else { // Default load from csv files to dataDb
 		/*for fn, v := range engine.FileValidators {
 			err := engine.ValidateCSVData(path.Join(*dataPath, fn), v.Rule)
@@ -267,21 +320,91 @@ func main() {
 			path.Join(*dataPath, utils.USERS_CSV),
 			path.Join(*dataPath, utils.ALIASES_CSV),
 			path.Join(*dataPath, utils.ResourceLimitsCsv),
+			path.Join(*dataPath, utils.ExchangeRatesCsv),
+			path.Join(*dataPath, utils.HolidayCalendarsCsv),
+			path.Join(*dataPath, utils.DialPlansCsv),
 		)
 	}
-	tpReader := engine.NewTpReader(dataDB, loader, *tpid, *timezone)
-	err = tpReader.LoadAll()
+	csvStorage, isCSV := loader.(*engine.CSVStorage)
+	if isCSV {
+		csvStorage.SetVariables(tpVars)
+		csvStorage.SetTolerant(*tolerant)
+	}
+	engine.SetTPSnapshotsSize(*tpSnapshotsSize)
+	engine.SetRatingPlanSplitSize(*rpSplitSize)
+	var tpReader *engine.TpReader
+	if tpids := strings.Split(*tpid, utils.INFIELD_SEP); len(tpids) > 1 { // base tariff plan with overlays
+		if tpReader, err = engine.NewMergedTpReader(dataDB, loader, tpids, *tpidConflicts, *timezone); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		tpReader = engine.NewTpReader(dataDB, loader, *tpid, *timezone)
+	}
+	if *tenantTimezones != "" {
+		tenantTZs := make(map[string]string)
+		for _, tenantTZ := range strings.Split(*tenantTimezones, utils.INFIELD_SEP) {
+			tzSplt := strings.SplitN(tenantTZ, utils.CONCATENATED_KEY_SEP, 2)
+			if len(tzSplt) != 2 {
+				log.Fatalf("Invalid tenant timezone: %s", tenantTZ)
+			}
+			if _, err := time.LoadLocation(tzSplt[1]); err != nil {
+				log.Fatalf("Invalid timezone for tenant %s: %s", tzSplt[0], err.Error())
+			}
+			tenantTZs[tzSplt[0]] = tzSplt[1]
+		}
+		tpReader.SetTenantTimezones(tenantTZs)
+	}
+	tpReader.SetCompressDestinations(*compressDsts)
+	if *disableRevFor != "" {
+		if err := tpReader.SetDisabledReverses(strings.Split(*disableRevFor, utils.INFIELD_SEP)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var includeCategs, excludeCategs []string
+	if *categories != "" {
+		includeCategs = strings.Split(*categories, utils.INFIELD_SEP)
+	}
+	if *exclCategories != "" {
+		excludeCategs = strings.Split(*exclCategories, utils.INFIELD_SEP)
+	}
+	err = tpReader.LoadCategories(includeCategs, excludeCategs)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if isCSV && len(csvStorage.QuarantinedRows()) != 0 {
+		log.Printf("WARNING: %d rows quarantined", len(csvStorage.QuarantinedRows()))
+		if *quarantineRpt != "" {
+			fRpt, err := os.Create(*quarantineRpt)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err = engine.WriteQuarantineReport(fRpt, csvStorage.QuarantinedRows()); err != nil {
+				log.Fatal(err)
+			}
+			fRpt.Close()
+		}
+	}
 	if *stats {
-		tpReader.ShowStatistics()
+		if statsJSON, err := json.MarshalIndent(tpReader.Statistics(), "", "  "); err != nil {
+			log.Fatal(err)
+		} else {
+			fmt.Println(string(statsJSON))
+		}
 	}
 	if *validate {
 		if !tpReader.IsValid() {
 			return
 		}
 	}
+	if *lint {
+		issues, err := tpReader.Lint()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, issue := range issues {
+			log.Print(issue)
+		}
+	}
 	if *dryRun { // We were just asked to parse the data, not saving it
 		return
 	}
@@ -337,25 +460,18 @@ func main() {
 	if err := tpReader.WriteToDatabase(*flush, *verbose, *disable_reverse); err != nil {
 		log.Fatal("Could not write to database: ", err)
 	}
+	if rater != nil { // refresh the rater cache with the written keys, the data is already stored
+		if *verbose {
+			log.Print("Reloading cache")
+		}
+		tpReader.SetCacheReload(true, rater)
+		if err := tpReader.ReloadCache(*flush); err != nil {
+			log.Printf("WARNING: Got error on cache reload: %s\n", err.Error())
+		}
+	}
 	if len(*historyServer) != 0 && *verbose {
 		log.Print("Wrote history.")
 	}
-	var dstIds, revDstIDs, rplIds, rpfIds, actIds, aapIDs, shgIds, alsIds, lcrIds, dcsIds, rlIDs, aatIDs, ralsIDs []string
-	if rater != nil {
-		dstIds, _ = tpReader.GetLoadedIds(utils.DESTINATION_PREFIX)
-		revDstIDs, _ = tpReader.GetLoadedIds(utils.REVERSE_DESTINATION_PREFIX)
-		rplIds, _ = tpReader.GetLoadedIds(utils.RATING_PLAN_PREFIX)
-		rpfIds, _ = tpReader.GetLoadedIds(utils.RATING_PROFILE_PREFIX)
-		actIds, _ = tpReader.GetLoadedIds(utils.ACTION_PREFIX)
-		aapIDs, _ = tpReader.GetLoadedIds(utils.AccountActionPlansPrefix)
-		shgIds, _ = tpReader.GetLoadedIds(utils.SHARED_GROUP_PREFIX)
-		alsIds, _ = tpReader.GetLoadedIds(utils.ALIASES_PREFIX)
-		lcrIds, _ = tpReader.GetLoadedIds(utils.LCR_PREFIX)
-		dcsIds, _ = tpReader.GetLoadedIds(utils.DERIVEDCHARGERS_PREFIX)
-		rlIDs, _ = tpReader.GetLoadedIds(utils.ResourceLimitsPrefix)
-		aatIDs, _ = tpReader.GetLoadedIds(utils.ACTION_TRIGGER_PREFIX)
-		ralsIDs, _ = tpReader.GetLoadedIds(utils.REVERSE_ALIASES_PREFIX)
-	}
 	aps, _ := tpReader.GetLoadedIds(utils.ACTION_PLAN_PREFIX)
 	var statsQueueIds []string
 	if cdrstats != nil {
@@ -368,34 +484,9 @@ func main() {
 	// release the reader with it's structures
 	tpReader.Init()
 
-	// Reload scheduler and cache
+	// Reload scheduler
 	if rater != nil {
 		reply := ""
-
-		// Reload cache first since actions could be calling info from within
-		if *verbose {
-			log.Print("Reloading cache")
-		}
-		if err = rater.Call("ApierV1.ReloadCache", utils.AttrReloadCache{ArgsCache: utils.ArgsCache{
-			DestinationIDs:        &dstIds,
-			ReverseDestinationIDs: &revDstIDs,
-			RatingPlanIDs:         &rplIds,
-			RatingProfileIDs:      &rpfIds,
-			ActionIDs:             &actIds,
-			ActionPlanIDs:         &aps,
-			AccountActionPlanIDs:  &aapIDs,
-			ActionTriggerIDs:      &aatIDs,
-			SharedGroupIDs:        &shgIds,
-			LCRids:                &lcrIds,
-			DerivedChargerIDs:     &dcsIds,
-			AliasIDs:              &alsIds,
-			ReverseAliasIDs:       &ralsIDs,
-			ResourceLimitIDs:      &rlIDs},
-			FlushAll: *flush,
-		}, &reply); err != nil {
-			log.Printf("WARNING: Got error on cache reload: %s\n", err.Error())
-		}
-
 		if len(aps) != 0 {
 			if *verbose {
 				log.Print("Reloading scheduler")
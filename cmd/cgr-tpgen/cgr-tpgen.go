@@ -0,0 +1,141 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"unicode/utf8"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+var (
+	cgrConfig, _ = config.NewDefaultCGRConfig()
+	specPath     = flag.String("spec", "", "Path to the JSON tariff plan specification, see data/tpgen/spec.json")
+	dataPath     = flag.String("path", "./", "The path to the folder where the .csv files will be generated")
+	fieldSep     = flag.String("field_sep", ",", `Separator for csv fields`)
+	version      = flag.Bool("version", false, "Prints the application version.")
+
+	loadDestinations = flag.Int("load_destinations", 0, "Generate a synthetic tariff plan for load testing with this number of destinations, instead of using -spec")
+	loadPrefixes     = flag.Int("load_prefixes", 1, "Prefixes of each synthetic destination")
+	loadRatingPlans  = flag.Int("load_rating_plans", 1, "Synthetic rating plans, the accounts are spread over them")
+	loadAccounts     = flag.Int("load_accounts", 0, "Synthetic accounts, each with its own rating profile")
+	loadBalance      = flag.Float64("load_balance", 10, "Monetary balance of each synthetic account")
+	loadTenant       = flag.String("load_tenant", "cgrates.org", "Tenant of the synthetic tariff plan")
+	loadSeed         = flag.Int64("load_seed", 1, "Random seed, the same seed generates the same synthetic tariff plan")
+
+	toDataDB        = flag.Bool("to_datadb", false, "Load the generated .csv files into DataDb")
+	flush           = flag.Bool("flushdb", false, "Flush the DataDb before loading")
+	datadb_type     = flag.String("datadb_type", cgrConfig.DataDbType, "The type of the DataDb database <redis>")
+	datadb_host     = flag.String("datadb_host", cgrConfig.DataDbHost, "The DataDb host to connect to.")
+	datadb_port     = flag.String("datadb_port", cgrConfig.DataDbPort, "The DataDb port to bind to.")
+	datadb_name     = flag.String("datadb_name", cgrConfig.DataDbName, "The name/number of the DataDb to connect to.")
+	datadb_user     = flag.String("datadb_user", cgrConfig.DataDbUser, "The DataDb user to sign in as.")
+	datadb_pass     = flag.String("datadb_passwd", cgrConfig.DataDbPass, "The DataDb user's password.")
+	dbdata_encoding = flag.String("dbdata_encoding", cgrConfig.DBDataEncoding, "The encoding used to store object data in strings")
+)
+
+func main() {
+	flag.Parse()
+	if *version {
+		fmt.Println(utils.GetCGRVersion())
+		return
+	}
+	if *specPath == "" && *loadDestinations == 0 {
+		log.Fatal("Missing tariff plan specification, use -spec or -load_destinations")
+	}
+	sep, _ := utf8.DecodeRuneInString(*fieldSep)
+	if sep == utf8.RuneError {
+		log.Fatalf("Invalid field separator: %s", *fieldSep)
+	}
+	var tpData map[string][]interface{}
+	var err error
+	if *loadDestinations != 0 {
+		tpData, err = engine.GenerateLoadTestTP(&engine.TPGenLoadSpec{TPid: "TP_LOAD", Tenant: *loadTenant, Category: utils.CALL,
+			Destinations: *loadDestinations, PrefixesPerDestination: *loadPrefixes, RatingPlans: *loadRatingPlans,
+			Accounts: *loadAccounts, Balance: *loadBalance, Seed: *loadSeed})
+	} else {
+		var specContent []byte
+		if specContent, err = ioutil.ReadFile(*specPath); err != nil {
+			log.Fatal(err)
+		}
+		var spec engine.TPGenSpec
+		if err := json.Unmarshal(specContent, &spec); err != nil {
+			log.Fatalf("Cannot parse specification: %s", err.Error())
+		}
+		tpData, err = engine.GenerateTP(&spec)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(*dataPath, 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := engine.WriteTPCSVs(*dataPath, sep, tpData); err != nil {
+		log.Fatal(err)
+	}
+	for fName, mdls := range tpData {
+		log.Printf("Generated %s with %d records", fName, len(mdls))
+	}
+	if !*toDataDB {
+		return
+	}
+	dataDB, err := engine.ConfigureDataStorage(*datadb_type, *datadb_host, *datadb_port, *datadb_name,
+		*datadb_user, *datadb_pass, *dbdata_encoding, cgrConfig.CacheConfig, cgrConfig.LoadHistorySize)
+	if err != nil {
+		log.Fatalf("Could not open DataDb connection: %s", err.Error())
+	}
+	defer dataDB.Close()
+	tpReader := engine.NewTpReader(dataDB, engine.NewFileCSVStorage(sep,
+		path.Join(*dataPath, utils.DESTINATIONS_CSV),
+		path.Join(*dataPath, utils.TIMINGS_CSV),
+		path.Join(*dataPath, utils.RATES_CSV),
+		path.Join(*dataPath, utils.DESTINATION_RATES_CSV),
+		path.Join(*dataPath, utils.RATING_PLANS_CSV),
+		path.Join(*dataPath, utils.RATING_PROFILES_CSV),
+		path.Join(*dataPath, utils.SHARED_GROUPS_CSV),
+		path.Join(*dataPath, utils.LCRS_CSV),
+		path.Join(*dataPath, utils.ACTIONS_CSV),
+		path.Join(*dataPath, utils.ACTION_PLANS_CSV),
+		path.Join(*dataPath, utils.ACTION_TRIGGERS_CSV),
+		path.Join(*dataPath, utils.ACCOUNT_ACTIONS_CSV),
+		path.Join(*dataPath, utils.DERIVED_CHARGERS_CSV),
+		path.Join(*dataPath, utils.CDR_STATS_CSV),
+		path.Join(*dataPath, utils.USERS_CSV),
+		path.Join(*dataPath, utils.ALIASES_CSV),
+		path.Join(*dataPath, utils.ResourceLimitsCsv),
+		path.Join(*dataPath, utils.ExchangeRatesCsv),
+		path.Join(*dataPath, utils.HolidayCalendarsCsv),
+		path.Join(*dataPath, utils.DialPlansCsv),
+	), "", cgrConfig.DefaultTimezone)
+	if err := tpReader.LoadAll(); err != nil {
+		log.Fatal(err)
+	}
+	if err := tpReader.WriteToDatabase(*flush, false, false); err != nil {
+		log.Fatalf("Could not write to DataDb: %s", err.Error())
+	}
+	log.Print("Generated tariff plan loaded into DataDb, reload the engine cache to use it")
+}
@@ -0,0 +1,51 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package config
+
+import (
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// CircuitBreakerCfg configures the circuit breaker protecting database access
+type CircuitBreakerCfg struct {
+	MaxFailures  int // 0 disables the circuit breaker
+	SlowCall     time.Duration
+	OpenInterval time.Duration
+}
+
+func (self *CircuitBreakerCfg) loadFromJsonCfg(jsnCfg *CircuitBreakerJsonCfg) (err error) {
+	if jsnCfg == nil {
+		return nil
+	}
+	if jsnCfg.Max_failures != nil {
+		self.MaxFailures = *jsnCfg.Max_failures
+	}
+	if jsnCfg.Slow_call != nil {
+		if self.SlowCall, err = utils.ParseDurationWithSecs(*jsnCfg.Slow_call); err != nil {
+			return
+		}
+	}
+	if jsnCfg.Open_interval != nil {
+		if self.OpenInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Open_interval); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -25,6 +25,7 @@ import (
 
 type CacheParamConfig struct {
 	Limit    int
+	MaxBytes int // estimated size of the cached items above which the least recently used are evicted, 0 to disable
 	TTL      time.Duration
 	Precache bool
 }
@@ -37,6 +38,9 @@ func (self *CacheParamConfig) loadFromJsonCfg(jsnCfg *CacheParamJsonCfg) error {
 	if jsnCfg.Limit != nil {
 		self.Limit = *jsnCfg.Limit
 	}
+	if jsnCfg.Max_bytes != nil {
+		self.MaxBytes = *jsnCfg.Max_bytes
+	}
 	if jsnCfg.Ttl != nil {
 		if self.TTL, err = utils.ParseDurationWithSecs(*jsnCfg.Ttl); err != nil {
 			return err
@@ -32,6 +32,8 @@ type CdreConfig struct {
 	FieldSeparator      rune
 	UsageMultiplyFactor utils.FieldMultiplyFactor
 	CostMultiplyFactor  float64
+	RunIDs              []string // export only the CDRs of these charging runs, all if empty
+	SumRuns             bool     // export one record per call out of the selected runs, costs summed
 	HeaderFields        []*CfgCdrField
 	ContentFields       []*CfgCdrField
 	TrailerFields       []*CfgCdrField
@@ -74,6 +76,15 @@ func (self *CdreConfig) loadFromJsonCfg(jsnCfg *CdreJsonCfg) error {
 	if jsnCfg.Cost_multiply_factor != nil {
 		self.CostMultiplyFactor = *jsnCfg.Cost_multiply_factor
 	}
+	if jsnCfg.Run_ids != nil {
+		self.RunIDs = nil
+		for _, runID := range *jsnCfg.Run_ids {
+			self.RunIDs = append(self.RunIDs, runID)
+		}
+	}
+	if jsnCfg.Sum_runs != nil {
+		self.SumRuns = *jsnCfg.Sum_runs
+	}
 	if jsnCfg.Header_fields != nil {
 		if self.HeaderFields, err = CfgCdrFieldsFromCdrFieldsJsonCfg(*jsnCfg.Header_fields); err != nil {
 			return err
@@ -105,6 +116,11 @@ func (self *CdreConfig) Clone() *CdreConfig {
 		clnCdre.UsageMultiplyFactor[k] = v
 	}
 	clnCdre.CostMultiplyFactor = self.CostMultiplyFactor
+	if self.RunIDs != nil {
+		clnCdre.RunIDs = make([]string, len(self.RunIDs))
+		copy(clnCdre.RunIDs, self.RunIDs)
+	}
+	clnCdre.SumRuns = self.SumRuns
 	clnCdre.HeaderFields = make([]*CfgCdrField, len(self.HeaderFields))
 	for idx, fld := range self.HeaderFields {
 		clonedVal := *fld
@@ -0,0 +1,63 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package config
+
+import (
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// CdrsClickHouseCfg configures the ClickHouse server storing the CDRs in place of stor_db
+type CdrsClickHouseCfg struct {
+	URL           string // HTTP interface, empty keeps the CDRs in stor_db
+	User          string
+	Password      string
+	BatchSize     int           // CDRs inserted at once, 1 to insert each as received
+	FlushInterval time.Duration // incomplete batches are inserted after this interval
+	TTL           time.Duration // CDRs older are dropped by ClickHouse, 0 to keep them
+}
+
+func (self *CdrsClickHouseCfg) loadFromJsonCfg(jsnCfg *CdrsClickHouseJsonCfg) (err error) {
+	if jsnCfg == nil {
+		return nil
+	}
+	if jsnCfg.Url != nil {
+		self.URL = *jsnCfg.Url
+	}
+	if jsnCfg.User != nil {
+		self.User = *jsnCfg.User
+	}
+	if jsnCfg.Password != nil {
+		self.Password = *jsnCfg.Password
+	}
+	if jsnCfg.Batch_size != nil {
+		self.BatchSize = *jsnCfg.Batch_size
+	}
+	if jsnCfg.Flush_interval != nil {
+		if self.FlushInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Flush_interval); err != nil {
+			return
+		}
+	}
+	if jsnCfg.Ttl != nil {
+		if self.TTL, err = utils.ParseDurationWithSecs(*jsnCfg.Ttl); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -64,12 +64,18 @@ func NewDefaultCGRConfig() (*CGRConfig, error) {
 	cfg.DataFolderPath = "/usr/share/cgrates/"
 	cfg.SmGenericConfig = new(SmGenericConfig)
 	cfg.CacheConfig = new(CacheConfig)
+	cfg.DataDbBreaker = new(CircuitBreakerCfg)
+	cfg.DataDbLocalTier = new(DataDBTierCfg)
+	cfg.StorDBBreaker = new(CircuitBreakerCfg)
+	cfg.StorDBCdrsClickHouse = new(CdrsClickHouseCfg)
 	cfg.SmFsConfig = new(SmFsConfig)
 	cfg.SmKamConfig = new(SmKamConfig)
 	cfg.SmOsipsConfig = new(SmOsipsConfig)
 	cfg.smAsteriskCfg = new(SMAsteriskCfg)
 	cfg.diameterAgentCfg = new(DiameterAgentCfg)
 	cfg.radiusAgentCfg = new(RadiusAgentCfg)
+	cfg.natsAgentCfg = new(NatsAgentCfg)
+	cfg.replicationCfg = new(ReplicationCfg)
 	cfg.ConfigReloads = make(map[string]chan struct{})
 	cfg.ConfigReloads[utils.CDRC] = make(chan struct{}, 1)
 	cfg.ConfigReloads[utils.CDRC] <- struct{}{} // Unlock the channel
@@ -122,6 +128,18 @@ func NewCGRConfigFromJsonStringWithDefaults(cfgJsonStr string) (*CGRConfig, erro
 
 // Reads all .json files out of a folder/subfolders and loads them up in lexical order
 func NewCGRConfigFromFolder(cfgDir string) (*CGRConfig, error) {
+	cfg, err := loadCGRConfigFromFolder(cfgDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkConfigSanity(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadCGRConfigFromFolder loads the json files inside cfgDir on top of defaults, without sanity checks
+func loadCGRConfigFromFolder(cfgDir string) (*CGRConfig, error) {
 	cfg, err := NewDefaultCGRConfig()
 	if err != nil {
 		return nil, err
@@ -168,9 +186,6 @@ func NewCGRConfigFromFolder(cfgDir string) (*CGRConfig, error) {
 			return nil, fmt.Errorf("No config file found on path %s", cfgDir)
 		}
 	}
-	if err := cfg.checkConfigSanity(); err != nil {
-		return nil, err
-	}
 	return cfg, nil
 }
 
@@ -184,6 +199,12 @@ type CGRConfig struct {
 	DataDbUser               string // The user to sign in as.
 	DataDbPass               string // The user's password.
 	LoadHistorySize          int    // Maximum number of records to archive in load history
+	TPSnapshotsSize          int    // Maximum number of tariff plan snapshots to keep for rollbacks
+	DataDbDestinationsIndex  bool   // Match destinations out of an in-memory prefix tree
+	DataDbDestIndexType      string // Layout of the destinations index: <*tree|*sharded>
+	DataDbRpSplitSize        int    // Number of destinations above which rating plans are stored split, 0 to disable
+	DataDbBreaker            *CircuitBreakerCfg
+	DataDbLocalTier          *DataDBTierCfg
 	StorDBType               string // Should reflect the database type used to store logs
 	StorDBHost               string // The host to connect to. Values that start with / are for UNIX domain sockets.
 	StorDBPort               string // Th e port to bind to.
@@ -193,6 +214,9 @@ type CGRConfig struct {
 	StorDBMaxOpenConns       int    // Maximum database connections opened
 	StorDBMaxIdleConns       int    // Maximum idle connections to keep opened
 	StorDBCDRSIndexes        []string
+	StorDBCDRSIndexedFields  []string // CDR extra fields stored as indexed columns
+	StorDBBreaker            *CircuitBreakerCfg
+	StorDBCdrsClickHouse     *CdrsClickHouseCfg
 	DBDataEncoding           string // The encoding used to store object data in strings: <msgpack|json>
 	CacheConfig              *CacheConfig
 	RPCJSONListen            string            // RPC JSON listening address
@@ -206,6 +230,7 @@ type CGRConfig struct {
 	DefaultCategory          string            // set default type of record
 	DefaultTenant            string            // set default tenant
 	DefaultTimezone          string            // default timezone for timestamps where not specified <""|UTC|Local|$IANA_TZ_DB>
+	TenantTimezones          map[string]string // timezone overrides per tenant used when loading tariff plans
 	Reconnects               int               // number of recconect attempts in case of connection lost <-1 for infinite | nb>
 	ConnectTimeout           time.Duration     // timeout for RPC connection attempts
 	ReplyTimeout             time.Duration     // timeout replies if not reaching back
@@ -213,12 +238,17 @@ type CGRConfig struct {
 	ResponseCacheTTL         time.Duration     // the life span of a cached response
 	InternalTtl              time.Duration     // maximum duration to wait for internal connections before giving up
 	RoundingDecimals         int               // Number of decimals to round end prices at
+	DecimalPrecision         int               // Number of decimals kept by the fixed-point cost arithmetic
 	HttpSkipTlsVerify        bool              // If enabled Http Client will accept any TLS certificate
 	TpExportPath             string            // Path towards export folder for offline Tariff Plans
 	PosterAttempts           int
 	FailedPostsDir           string          // Directory path where we store failed http requests
 	MaxCallDuration          time.Duration   // The maximum call duration (used by responder when querying DerivedCharging) // ToDo: export it in configuration file
 	LockingTimeout           time.Duration   // locking mechanism timeout to avoid deadlocks
+	JobsTTL                  time.Duration   // keep finished asynchronous jobs for this long, 0 to keep them forever
+	ClockSkewThreshold       time.Duration   // warn on peer timestamps differing more than this from our clock, 0 to disable
+	TPLoadNotifyAddresses    []string        // where to publish the tariff plan load events, empty to disable
+	DebugRecordsTTL          time.Duration   // keep the records of the calls flagged for debugging for this long, 0 to keep them forever
 	LogLevel                 int             // system wide log level, nothing higher than this will be logged
 	RALsEnabled              bool            // start standalone server (no balancer)
 	RALsCDRStatSConns        []*HaPoolConfig // address where to reach the cdrstats service. Empty to disable stats gathering  <""|internal|x.y.z.y:1234>
@@ -226,8 +256,17 @@ type CGRConfig struct {
 	RALsPubSubSConns         []*HaPoolConfig
 	RALsUserSConns           []*HaPoolConfig
 	RALsAliasSConns          []*HaPoolConfig
-	RpSubjectPrefixMatching  bool // enables prefix matching for the rating profile subject
-	LcrSubjectPrefixMatching bool // enables prefix matching for the lcr subject
+	RpSubjectPrefixMatching  bool          // enables prefix matching for the rating profile subject
+	RpFallbackMaxDepth       int           // maximum number of fallback subjects followed when rating
+	RpCompiledPlans          []string      // rating plans compiled into flat rate tables when loaded
+	LcrSubjectPrefixMatching bool          // enables prefix matching for the lcr subject
+	LcrBlacklistFailures     int           // consecutive failures after which a supplier is excluded from LCR, 0 to disable
+	LcrBlacklistCooldown     time.Duration // how long a supplier stays blacklisted
+	LowCreditGrace           time.Duration // usage allowed to started sessions after the balance is exhausted
+	EmergencyDestinations    []string      // destination IDs authorized regardless of the balance
+	ExpiryNotifyDays         []int         // days before the expiry of new balances when ExpiryNotifyActions are scheduled
+	ExpiryNotifyActions      string        // actions warning about the balance expiry
+	RALsBalanceNotifyAddress string        // where to publish balance changes, empty to disable
 	SchedulerEnabled         bool
 	CDRSEnabled              bool              // Enable CDR Server service
 	CDRSExtraFields          []*utils.RSRField // Extra fields to store in CDRs
@@ -240,6 +279,8 @@ type CGRConfig struct {
 	CDRSAliaseSConns         []*HaPoolConfig // address where to reach the aliases service: <""|internal|x.y.z.y:1234>
 	CDRSStatSConns           []*HaPoolConfig // address where to reach the cdrstats service. Empty to disable stats gathering  <""|internal|x.y.z.y:1234>
 	CDRSOnlineCDRExports     []string        // list of CDRE templates to use for real-time CDR exports
+	CDRSUnratedRetryOnLoad   bool            // re-rate the CDRs with rating errors after tariff plan loads
+	CDRSShadowRatingSubject  string          // subject of the candidate rating profile computing CDR ShadowCost
 	CDRStatsEnabled          bool            // Enable CDR Stats service
 	CDRStatsSaveInterval     time.Duration   // Save interval duration
 	CdreProfiles             map[string]*CdreConfig
@@ -251,6 +292,8 @@ type CGRConfig struct {
 	smAsteriskCfg            *SMAsteriskCfg           // SMAsterisk Configuration
 	diameterAgentCfg         *DiameterAgentCfg        // DiameterAgent configuration
 	radiusAgentCfg           *RadiusAgentCfg          // RadiusAgent configuration
+	natsAgentCfg             *NatsAgentCfg            // NatsAgent configuration
+	replicationCfg           *ReplicationCfg          // Replication towards read-only engines
 	HistoryServerEnabled     bool                     // Starts History as server: <true|false>.
 	HistoryDir               string                   // Location on disk where to store history files.
 	HistorySaveInterval      time.Duration            // The timout duration between pubsub writes
@@ -263,6 +306,11 @@ type CGRConfig struct {
 	MailerAuthUser           string                   // Authenticate to email server using this user
 	MailerAuthPass           string                   // Authenticate to email server with this password
 	MailerFromAddr           string                   // From address used when sending emails out
+	SmsTransport             string                   // How the *sms actions are delivered: <""|*http|*smpp>
+	SmsAddress               string                   // HTTP gateway URL or SMPP server address
+	SmsAuthUser              string                   // HTTP basic auth user or SMPP system_id
+	SmsAuthPass              string                   // HTTP basic auth password or SMPP password
+	SmsFromAddr              string                   // Sender of the SMS messages
 	DataFolderPath           string                   // Path towards data folder, for tests internal usage, not loading out of .json options
 	sureTaxCfg               *SureTaxCfg              // Load here SureTax configuration, as pointer so we can have runtime reloads in the future
 	ConfigReloads            map[string]chan struct{} // Signals to specific entities that a config reload should occur
@@ -272,8 +320,25 @@ type CGRConfig struct {
 }
 
 func (self *CGRConfig) checkConfigSanity() error {
+	if self.DecimalPrecision < 0 || self.DecimalPrecision > utils.MaxDecimalPrecision {
+		return fmt.Errorf("decimal_precision must be between 0 and %d", utils.MaxDecimalPrecision)
+	}
+	if self.StorDBCdrsClickHouse.URL != "" && self.StorDBCdrsClickHouse.BatchSize < 1 {
+		return errors.New("cdrs_clickhouse batch_size needs to be at least 1")
+	}
+	if self.SmsTransport != "" {
+		if !utils.IsSliceMember([]string{utils.MetaHTTP, utils.MetaSMPP}, self.SmsTransport) {
+			return fmt.Errorf("sms transport %s not supported", self.SmsTransport)
+		}
+		if self.SmsAddress == "" {
+			return errors.New("sms address needs to be configured together with the transport")
+		}
+	}
 	// Rater checks
 	if self.RALsEnabled {
+		if self.RpFallbackMaxDepth < 1 {
+			return errors.New("rp_fallback_max_depth needs to be at least 1")
+		}
 		for _, connCfg := range self.RALsCDRStatSConns {
 			if connCfg.Address == utils.MetaInternal && !self.CDRStatsEnabled {
 				return errors.New("CDRStats not enabled but requested by Rater component.")
@@ -379,6 +444,17 @@ func (self *CGRConfig) checkConfigSanity() error {
 				return errors.New("<SMGeneric> CDRS not enabled but referenced by SMGeneric component")
 			}
 		}
+		if self.SmGenericConfig.SessionTTLPolicy != "" &&
+			!utils.NewStringMap(utils.MetaLastUpdate, utils.ZERO, utils.MetaMaxReservation)[self.SmGenericConfig.SessionTTLPolicy] {
+			return fmt.Errorf("<SMGeneric> unsupported session_ttl_policy: %s", self.SmGenericConfig.SessionTTLPolicy)
+		}
+		if len(self.SmGenericConfig.DedupKeys) != 0 &&
+			!utils.IsSliceMember([]string{utils.MetaFirst, utils.MetaLast}, self.SmGenericConfig.DedupMergePolicy) {
+			return fmt.Errorf("<SMGeneric> unsupported dedup_merge_policy: %s", self.SmGenericConfig.DedupMergePolicy)
+		}
+		if len(self.SmGenericConfig.DedupKeys) != 0 && self.SmGenericConfig.DedupTTL <= 0 {
+			return errors.New("<SMGeneric> dedup_ttl needs to be positive when dedup_keys are defined")
+		}
 	}
 	// SMFreeSWITCH checks
 	if self.SmFsConfig.Enabled {
@@ -483,6 +559,13 @@ func (self *CGRConfig) checkConfigSanity() error {
 			}
 		}
 	}
+	if self.natsAgentCfg.Enabled {
+		for _, cdrsConn := range self.natsAgentCfg.CDRsConns {
+			if cdrsConn.Address == utils.MetaInternal && !self.CDRSEnabled {
+				return errors.New("CDRS not enabled but referenced by NatsAgent component")
+			}
+		}
+	}
 	// ResourceLimiter checks
 	if self.resourceLimiterCfg != nil && self.resourceLimiterCfg.Enabled {
 		for _, connCfg := range self.resourceLimiterCfg.CDRStatConns {
@@ -490,6 +573,9 @@ func (self *CGRConfig) checkConfigSanity() error {
 				return errors.New("CDRStats not enabled but requested by ResourceLimiter component.")
 			}
 		}
+		if self.resourceLimiterCfg.UsageSamplesInterval != 0 && self.resourceLimiterCfg.UsageSamplesSize <= 0 {
+			return errors.New("ResourceLimiter usage_samples_size needs to be positive when usage sampling is enabled.")
+		}
 	}
 	return nil
 }
@@ -594,6 +680,16 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		return err
 	}
 
+	jsnNatsAgentCfg, err := jsnCfg.NatsAgentJsonCfg()
+	if err != nil {
+		return err
+	}
+
+	jsnReplicationCfg, err := jsnCfg.ReplicationJsonCfg()
+	if err != nil {
+		return err
+	}
+
 	jsnHistServCfg, err := jsnCfg.HistServJsonCfg()
 	if err != nil {
 		return err
@@ -624,6 +720,11 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		return err
 	}
 
+	jsnSmsCfg, err := jsnCfg.SmsJsonCfg()
+	if err != nil {
+		return err
+	}
+
 	jsnSureTaxCfg, err := jsnCfg.SureTaxJsonCfg()
 	if err != nil {
 		return err
@@ -651,6 +752,24 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		if jsnDataDbCfg.Load_history_size != nil {
 			self.LoadHistorySize = *jsnDataDbCfg.Load_history_size
 		}
+		if jsnDataDbCfg.Tp_snapshots_size != nil {
+			self.TPSnapshotsSize = *jsnDataDbCfg.Tp_snapshots_size
+		}
+		if jsnDataDbCfg.Destinations_index != nil {
+			self.DataDbDestinationsIndex = *jsnDataDbCfg.Destinations_index
+		}
+		if jsnDataDbCfg.Dst_index_type != nil {
+			self.DataDbDestIndexType = *jsnDataDbCfg.Dst_index_type
+		}
+		if jsnDataDbCfg.Rp_split_size != nil {
+			self.DataDbRpSplitSize = *jsnDataDbCfg.Rp_split_size
+		}
+		if err := self.DataDbBreaker.loadFromJsonCfg(jsnDataDbCfg.Circuit_breaker); err != nil {
+			return err
+		}
+		if err := self.DataDbLocalTier.loadFromJsonCfg(jsnDataDbCfg.Local_tier); err != nil {
+			return err
+		}
 	}
 
 	if jsnStorDbCfg != nil {
@@ -681,6 +800,15 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		if jsnStorDbCfg.Cdrs_indexes != nil {
 			self.StorDBCDRSIndexes = *jsnStorDbCfg.Cdrs_indexes
 		}
+		if jsnStorDbCfg.Cdrs_indexed_fields != nil {
+			self.StorDBCDRSIndexedFields = *jsnStorDbCfg.Cdrs_indexed_fields
+		}
+		if err := self.StorDBBreaker.loadFromJsonCfg(jsnStorDbCfg.Circuit_breaker); err != nil {
+			return err
+		}
+		if err := self.StorDBCdrsClickHouse.loadFromJsonCfg(jsnStorDbCfg.Cdrs_clickhouse); err != nil {
+			return err
+		}
 	}
 
 	if jsnGeneralCfg != nil {
@@ -723,6 +851,9 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		if jsnGeneralCfg.Rounding_decimals != nil {
 			self.RoundingDecimals = *jsnGeneralCfg.Rounding_decimals
 		}
+		if jsnGeneralCfg.Decimal_precision != nil {
+			self.DecimalPrecision = *jsnGeneralCfg.Decimal_precision
+		}
 		if jsnGeneralCfg.Http_skip_tls_verify != nil {
 			self.HttpSkipTlsVerify = *jsnGeneralCfg.Http_skip_tls_verify
 		}
@@ -738,6 +869,15 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		if jsnGeneralCfg.Default_timezone != nil {
 			self.DefaultTimezone = *jsnGeneralCfg.Default_timezone
 		}
+		if jsnGeneralCfg.Tenant_timezones != nil {
+			self.TenantTimezones = make(map[string]string, len(*jsnGeneralCfg.Tenant_timezones))
+			for tenant, tz := range *jsnGeneralCfg.Tenant_timezones {
+				if _, err := time.LoadLocation(tz); err != nil {
+					return fmt.Errorf("invalid timezone <%s> for tenant <%s>: %s", tz, tenant, err.Error())
+				}
+				self.TenantTimezones[tenant] = tz
+			}
+		}
 		if jsnGeneralCfg.Internal_ttl != nil {
 			if self.InternalTtl, err = utils.ParseDurationWithSecs(*jsnGeneralCfg.Internal_ttl); err != nil {
 				return err
@@ -748,6 +888,24 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 				return err
 			}
 		}
+		if jsnGeneralCfg.Jobs_ttl != nil {
+			if self.JobsTTL, err = utils.ParseDurationWithSecs(*jsnGeneralCfg.Jobs_ttl); err != nil {
+				return err
+			}
+		}
+		if jsnGeneralCfg.Clock_skew_threshold != nil {
+			if self.ClockSkewThreshold, err = utils.ParseDurationWithSecs(*jsnGeneralCfg.Clock_skew_threshold); err != nil {
+				return err
+			}
+		}
+		if jsnGeneralCfg.Tpload_notify_addresses != nil {
+			self.TPLoadNotifyAddresses = *jsnGeneralCfg.Tpload_notify_addresses
+		}
+		if jsnGeneralCfg.Debug_records_ttl != nil {
+			if self.DebugRecordsTTL, err = utils.ParseDurationWithSecs(*jsnGeneralCfg.Debug_records_ttl); err != nil {
+				return err
+			}
+		}
 		if jsnGeneralCfg.Log_level != nil {
 			self.LogLevel = *jsnGeneralCfg.Log_level
 		}
@@ -828,9 +986,40 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		if jsnRALsCfg.Rp_subject_prefix_matching != nil {
 			self.RpSubjectPrefixMatching = *jsnRALsCfg.Rp_subject_prefix_matching
 		}
+		if jsnRALsCfg.Rp_fallback_max_depth != nil {
+			self.RpFallbackMaxDepth = *jsnRALsCfg.Rp_fallback_max_depth
+		}
+		if jsnRALsCfg.Rp_compiled_plans != nil {
+			self.RpCompiledPlans = *jsnRALsCfg.Rp_compiled_plans
+		}
 		if jsnRALsCfg.Lcr_subject_prefix_matching != nil {
 			self.LcrSubjectPrefixMatching = *jsnRALsCfg.Lcr_subject_prefix_matching
 		}
+		if jsnRALsCfg.Lcr_blacklist_failures != nil {
+			self.LcrBlacklistFailures = *jsnRALsCfg.Lcr_blacklist_failures
+		}
+		if jsnRALsCfg.Lcr_blacklist_cooldown != nil {
+			if self.LcrBlacklistCooldown, err = utils.ParseDurationWithSecs(*jsnRALsCfg.Lcr_blacklist_cooldown); err != nil {
+				return err
+			}
+		}
+		if jsnRALsCfg.Low_credit_grace != nil {
+			if self.LowCreditGrace, err = utils.ParseDurationWithSecs(*jsnRALsCfg.Low_credit_grace); err != nil {
+				return err
+			}
+		}
+		if jsnRALsCfg.Emergency_destinations != nil {
+			self.EmergencyDestinations = *jsnRALsCfg.Emergency_destinations
+		}
+		if jsnRALsCfg.Expiry_notify_days != nil {
+			self.ExpiryNotifyDays = *jsnRALsCfg.Expiry_notify_days
+		}
+		if jsnRALsCfg.Expiry_notify_actions != nil {
+			self.ExpiryNotifyActions = *jsnRALsCfg.Expiry_notify_actions
+		}
+		if jsnRALsCfg.Balance_notify_address != nil {
+			self.RALsBalanceNotifyAddress = *jsnRALsCfg.Balance_notify_address
+		}
 	}
 	if jsnSchedCfg != nil && jsnSchedCfg.Enabled != nil {
 		self.SchedulerEnabled = *jsnSchedCfg.Enabled
@@ -893,6 +1082,12 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 				self.CDRSOnlineCDRExports = append(self.CDRSOnlineCDRExports, expProfile)
 			}
 		}
+		if jsnCdrsCfg.Unrated_retry_on_load != nil {
+			self.CDRSUnratedRetryOnLoad = *jsnCdrsCfg.Unrated_retry_on_load
+		}
+		if jsnCdrsCfg.Shadow_rating_subject != nil {
+			self.CDRSShadowRatingSubject = *jsnCdrsCfg.Shadow_rating_subject
+		}
 	}
 
 	if jsnCdrstatsCfg != nil {
@@ -1008,6 +1203,18 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		}
 	}
 
+	if jsnNatsAgentCfg != nil {
+		if err := self.natsAgentCfg.loadFromJsonCfg(jsnNatsAgentCfg); err != nil {
+			return err
+		}
+	}
+
+	if jsnReplicationCfg != nil {
+		if err := self.replicationCfg.loadFromJsonCfg(jsnReplicationCfg); err != nil {
+			return err
+		}
+	}
+
 	if jsnHistServCfg != nil {
 		if jsnHistServCfg.Enabled != nil {
 			self.HistoryServerEnabled = *jsnHistServCfg.Enabled
@@ -1067,6 +1274,24 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) error {
 		}
 	}
 
+	if jsnSmsCfg != nil {
+		if jsnSmsCfg.Transport != nil {
+			self.SmsTransport = *jsnSmsCfg.Transport
+		}
+		if jsnSmsCfg.Address != nil {
+			self.SmsAddress = *jsnSmsCfg.Address
+		}
+		if jsnSmsCfg.Auth_user != nil {
+			self.SmsAuthUser = *jsnSmsCfg.Auth_user
+		}
+		if jsnSmsCfg.Auth_password != nil {
+			self.SmsAuthPass = *jsnSmsCfg.Auth_password
+		}
+		if jsnSmsCfg.From_address != nil {
+			self.SmsFromAddr = *jsnSmsCfg.From_address
+		}
+	}
+
 	if jsnSureTaxCfg != nil { // New config for SureTax
 		if self.sureTaxCfg, err = NewSureTaxCfgWithDefaults(); err != nil {
 			return err
@@ -1096,6 +1321,14 @@ func (self *CGRConfig) RadiusAgentCfg() *RadiusAgentCfg {
 	return self.radiusAgentCfg
 }
 
+func (self *CGRConfig) NatsAgentCfg() *NatsAgentCfg {
+	return self.natsAgentCfg
+}
+
+func (self *CGRConfig) ReplicationCfg() *ReplicationCfg {
+	return self.replicationCfg
+}
+
 // ToDo: fix locking here
 func (self *CGRConfig) ResourceLimiterCfg() *ResourceLimiterConfig {
 	return self.resourceLimiterCfg
@@ -50,12 +50,15 @@ const (
 	OSIPS_JSN            = "opensips"
 	DA_JSN               = "diameter_agent"
 	RA_JSN               = "radius_agent"
+	NatsAgentJSN         = "nats_agent"
+	ReplicationJSN       = "replication"
 	HISTSERV_JSN         = "historys"
 	PUBSUBSERV_JSN       = "pubsubs"
 	ALIASESSERV_JSN      = "aliases"
 	USERSERV_JSN         = "users"
 	RESOURCELIMITER_JSON = "rls"
 	MAILER_JSN           = "mailer"
+	SMS_JSN              = "sms"
 	SURETAX_JSON         = "suretax"
 )
 
@@ -286,6 +289,30 @@ func (self CgrJsonCfg) DiameterAgentJsonCfg() (*DiameterAgentJsonCfg, error) {
 	return cfg, nil
 }
 
+func (self CgrJsonCfg) ReplicationJsonCfg() (*ReplicationJsonCfg, error) {
+	rawCfg, hasKey := self[ReplicationJSN]
+	if !hasKey {
+		return nil, nil
+	}
+	cfg := new(ReplicationJsonCfg)
+	if err := json.Unmarshal(*rawCfg, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (self CgrJsonCfg) NatsAgentJsonCfg() (*NatsAgentJsonCfg, error) {
+	rawCfg, hasKey := self[NatsAgentJSN]
+	if !hasKey {
+		return nil, nil
+	}
+	cfg := new(NatsAgentJsonCfg)
+	if err := json.Unmarshal(*rawCfg, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 func (self CgrJsonCfg) RadiusAgentJsonCfg() (*RadiusAgentJsonCfg, error) {
 	rawCfg, hasKey := self[RA_JSN]
 	if !hasKey {
@@ -370,6 +397,18 @@ func (self CgrJsonCfg) MailerJsonCfg() (*MailerJsonCfg, error) {
 	return cfg, nil
 }
 
+func (self CgrJsonCfg) SmsJsonCfg() (*SmsJsonCfg, error) {
+	rawCfg, hasKey := self[SMS_JSN]
+	if !hasKey {
+		return nil, nil
+	}
+	cfg := new(SmsJsonCfg)
+	if err := json.Unmarshal(*rawCfg, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 func (self CgrJsonCfg) SureTaxJsonCfg() (*SureTaxJsonCfg, error) {
 	rawCfg, hasKey := self[SURETAX_JSON]
 	if !hasKey {
@@ -38,25 +38,31 @@ func TestDfNewdfCgrJsonCfgFromReader(t *testing.T) {
 
 func TestDfGeneralJsonCfg(t *testing.T) {
 	eCfg := &GeneralJsonCfg{
-		Instance_id:          utils.StringPointer(""),
-		Log_level:            utils.IntPointer(utils.LOGLEVEL_INFO),
-		Http_skip_tls_verify: utils.BoolPointer(false),
-		Rounding_decimals:    utils.IntPointer(5),
-		Dbdata_encoding:      utils.StringPointer("msgpack"),
-		Tpexport_dir:         utils.StringPointer("/var/spool/cgrates/tpe"),
-		Poster_attempts:      utils.IntPointer(3),
-		Failed_posts_dir:     utils.StringPointer("/var/spool/cgrates/failed_posts"),
-		Default_request_type: utils.StringPointer(utils.META_RATED),
-		Default_category:     utils.StringPointer("call"),
-		Default_tenant:       utils.StringPointer("cgrates.org"),
-		Default_timezone:     utils.StringPointer("Local"),
-		Connect_attempts:     utils.IntPointer(3),
-		Reconnects:           utils.IntPointer(-1),
-		Connect_timeout:      utils.StringPointer("1s"),
-		Reply_timeout:        utils.StringPointer("2s"),
-		Response_cache_ttl:   utils.StringPointer("0s"),
-		Internal_ttl:         utils.StringPointer("2m"),
-		Locking_timeout:      utils.StringPointer("5s"),
+		Instance_id:             utils.StringPointer(""),
+		Log_level:               utils.IntPointer(utils.LOGLEVEL_INFO),
+		Http_skip_tls_verify:    utils.BoolPointer(false),
+		Rounding_decimals:       utils.IntPointer(5),
+		Decimal_precision:       utils.IntPointer(9),
+		Dbdata_encoding:         utils.StringPointer("msgpack"),
+		Tpexport_dir:            utils.StringPointer("/var/spool/cgrates/tpe"),
+		Poster_attempts:         utils.IntPointer(3),
+		Failed_posts_dir:        utils.StringPointer("/var/spool/cgrates/failed_posts"),
+		Default_request_type:    utils.StringPointer(utils.META_RATED),
+		Default_category:        utils.StringPointer("call"),
+		Default_tenant:          utils.StringPointer("cgrates.org"),
+		Default_timezone:        utils.StringPointer("Local"),
+		Tenant_timezones:        &map[string]string{},
+		Connect_attempts:        utils.IntPointer(3),
+		Reconnects:              utils.IntPointer(-1),
+		Connect_timeout:         utils.StringPointer("1s"),
+		Reply_timeout:           utils.StringPointer("2s"),
+		Response_cache_ttl:      utils.StringPointer("0s"),
+		Internal_ttl:            utils.StringPointer("2m"),
+		Locking_timeout:         utils.StringPointer("5s"),
+		Jobs_ttl:                utils.StringPointer("1h"),
+		Clock_skew_threshold:    utils.StringPointer("0s"),
+		Tpload_notify_addresses: &[]string{},
+		Debug_records_ttl:       utils.StringPointer("1h"),
 	}
 	if gCfg, err := dfCgrJsonCfg.GeneralJsonCfg(); err != nil {
 		t.Error(err)
@@ -67,11 +73,11 @@ func TestDfGeneralJsonCfg(t *testing.T) {
 
 func TestCacheJsonCfg(t *testing.T) {
 	eCfg := &CacheJsonCfg{
-		Destinations: &CacheParamJsonCfg{Limit: utils.IntPointer(10000),
+		Destinations: &CacheParamJsonCfg{Limit: utils.IntPointer(10000), Max_bytes: utils.IntPointer(0),
 			Ttl: utils.StringPointer("0s"), Precache: utils.BoolPointer(false)},
 		Reverse_destinations: &CacheParamJsonCfg{Limit: utils.IntPointer(10000),
 			Ttl: utils.StringPointer("0s"), Precache: utils.BoolPointer(false)},
-		Rating_plans: &CacheParamJsonCfg{Limit: utils.IntPointer(10000),
+		Rating_plans: &CacheParamJsonCfg{Limit: utils.IntPointer(10000), Max_bytes: utils.IntPointer(0),
 			Ttl: utils.StringPointer("0s"), Precache: utils.BoolPointer(true)},
 		Rating_profiles: &CacheParamJsonCfg{Limit: utils.IntPointer(10000),
 			Ttl: utils.StringPointer("0s"), Precache: utils.BoolPointer(false)},
@@ -119,13 +125,30 @@ func TestDfListenJsonCfg(t *testing.T) {
 
 func TestDfDbJsonCfg(t *testing.T) {
 	eCfg := &DbJsonCfg{
-		Db_type:           utils.StringPointer("redis"),
-		Db_host:           utils.StringPointer("127.0.0.1"),
-		Db_port:           utils.IntPointer(6379),
-		Db_name:           utils.StringPointer("10"),
-		Db_user:           utils.StringPointer("cgrates"),
-		Db_password:       utils.StringPointer(""),
-		Load_history_size: utils.IntPointer(10),
+		Db_type:            utils.StringPointer("redis"),
+		Db_host:            utils.StringPointer("127.0.0.1"),
+		Db_port:            utils.IntPointer(6379),
+		Db_name:            utils.StringPointer("10"),
+		Db_user:            utils.StringPointer("cgrates"),
+		Db_password:        utils.StringPointer(""),
+		Load_history_size:  utils.IntPointer(10),
+		Tp_snapshots_size:  utils.IntPointer(5),
+		Destinations_index: utils.BoolPointer(false),
+		Dst_index_type:     utils.StringPointer(utils.MetaTree),
+		Rp_split_size:      utils.IntPointer(0),
+		Circuit_breaker: &CircuitBreakerJsonCfg{
+			Max_failures:  utils.IntPointer(0),
+			Slow_call:     utils.StringPointer("0s"),
+			Open_interval: utils.StringPointer("5s"),
+		},
+		Local_tier: &DataDBTierJsonCfg{
+			Db_type:     utils.StringPointer(""),
+			Db_host:     utils.StringPointer("127.0.0.1"),
+			Db_port:     utils.IntPointer(6379),
+			Db_name:     utils.StringPointer("11"),
+			Db_password: utils.StringPointer(""),
+			Hot_ttl:     utils.StringPointer("1h"),
+		},
 	}
 	if cfg, err := dfCgrJsonCfg.DbJsonCfg(DATADB_JSN); err != nil {
 		t.Error(err)
@@ -133,15 +156,29 @@ func TestDfDbJsonCfg(t *testing.T) {
 		t.Error("Received: ", cfg)
 	}
 	eCfg = &DbJsonCfg{
-		Db_type:        utils.StringPointer("mysql"),
-		Db_host:        utils.StringPointer("127.0.0.1"),
-		Db_port:        utils.IntPointer(3306),
-		Db_name:        utils.StringPointer("cgrates"),
-		Db_user:        utils.StringPointer("cgrates"),
-		Db_password:    utils.StringPointer(""),
-		Max_open_conns: utils.IntPointer(100),
-		Max_idle_conns: utils.IntPointer(10),
-		Cdrs_indexes:   utils.StringSlicePointer([]string{}),
+		Db_type:             utils.StringPointer("mysql"),
+		Db_host:             utils.StringPointer("127.0.0.1"),
+		Db_port:             utils.IntPointer(3306),
+		Db_name:             utils.StringPointer("cgrates"),
+		Db_user:             utils.StringPointer("cgrates"),
+		Db_password:         utils.StringPointer(""),
+		Max_open_conns:      utils.IntPointer(100),
+		Max_idle_conns:      utils.IntPointer(10),
+		Cdrs_indexes:        utils.StringSlicePointer([]string{}),
+		Cdrs_indexed_fields: utils.StringSlicePointer([]string{}),
+		Circuit_breaker: &CircuitBreakerJsonCfg{
+			Max_failures:  utils.IntPointer(0),
+			Slow_call:     utils.StringPointer("0s"),
+			Open_interval: utils.StringPointer("5s"),
+		},
+		Cdrs_clickhouse: &CdrsClickHouseJsonCfg{
+			Url:            utils.StringPointer(""),
+			User:           utils.StringPointer("default"),
+			Password:       utils.StringPointer(""),
+			Batch_size:     utils.IntPointer(1000),
+			Flush_interval: utils.StringPointer("1s"),
+			Ttl:            utils.StringPointer("0s"),
+		},
 	}
 	if cfg, err := dfCgrJsonCfg.DbJsonCfg(STORDB_JSN); err != nil {
 		t.Error(err)
@@ -153,7 +190,11 @@ func TestDfDbJsonCfg(t *testing.T) {
 func TestDfRalsJsonCfg(t *testing.T) {
 	eCfg := &RalsJsonCfg{Enabled: utils.BoolPointer(false), Cdrstats_conns: &[]*HaPoolJsonCfg{},
 		Historys_conns: &[]*HaPoolJsonCfg{}, Pubsubs_conns: &[]*HaPoolJsonCfg{}, Users_conns: &[]*HaPoolJsonCfg{}, Aliases_conns: &[]*HaPoolJsonCfg{},
-		Rp_subject_prefix_matching: utils.BoolPointer(false), Lcr_subject_prefix_matching: utils.BoolPointer(false)}
+		Rp_subject_prefix_matching: utils.BoolPointer(false), Rp_fallback_max_depth: utils.IntPointer(3), Rp_compiled_plans: &[]string{}, Lcr_subject_prefix_matching: utils.BoolPointer(false),
+		Lcr_blacklist_failures: utils.IntPointer(0), Lcr_blacklist_cooldown: utils.StringPointer("5m"),
+		Low_credit_grace: utils.StringPointer("0s"), Emergency_destinations: &[]string{},
+		Expiry_notify_days: &[]int{}, Expiry_notify_actions: utils.StringPointer(""),
+		Balance_notify_address: utils.StringPointer("")}
 	if cfg, err := dfCgrJsonCfg.RalsJsonCfg(); err != nil {
 		t.Error(err)
 	} else if !reflect.DeepEqual(eCfg, cfg) {
@@ -181,11 +222,13 @@ func TestDfCdrsJsonCfg(t *testing.T) {
 			&HaPoolJsonCfg{
 				Address: utils.StringPointer("*internal"),
 			}},
-		Pubsubs_conns:      &[]*HaPoolJsonCfg{},
-		Users_conns:        &[]*HaPoolJsonCfg{},
-		Aliases_conns:      &[]*HaPoolJsonCfg{},
-		Cdrstats_conns:     &[]*HaPoolJsonCfg{},
-		Online_cdr_exports: &[]string{},
+		Pubsubs_conns:         &[]*HaPoolJsonCfg{},
+		Users_conns:           &[]*HaPoolJsonCfg{},
+		Aliases_conns:         &[]*HaPoolJsonCfg{},
+		Cdrstats_conns:        &[]*HaPoolJsonCfg{},
+		Online_cdr_exports:    &[]string{},
+		Unrated_retry_on_load: utils.BoolPointer(false),
+		Shadow_rating_subject: utils.StringPointer(""),
 	}
 	if cfg, err := dfCgrJsonCfg.CdrsJsonCfg(); err != nil {
 		t.Error(err)
@@ -268,6 +311,8 @@ func TestDfCdreJsonCfgs(t *testing.T) {
 			Field_separator:       utils.StringPointer(","),
 			Usage_multiply_factor: &map[string]float64{utils.ANY: 1.0},
 			Cost_multiply_factor:  utils.Float64Pointer(1.0),
+			Run_ids:               &[]string{},
+			Sum_runs:              utils.BoolPointer(false),
 			Header_fields:         &eFields,
 			Content_fields:        &eContentFlds,
 			Trailer_fields:        &eFields,
@@ -407,12 +452,18 @@ func TestSmGenericJsonCfg(t *testing.T) {
 			&HaPoolJsonCfg{
 				Address: utils.StringPointer(utils.MetaInternal),
 			}},
-		Smg_replication_conns: &[]*HaPoolJsonCfg{},
-		Debit_interval:        utils.StringPointer("0s"),
-		Min_call_duration:     utils.StringPointer("0s"),
-		Max_call_duration:     utils.StringPointer("3h"),
-		Session_ttl:           utils.StringPointer("0s"),
-		Session_indexes:       utils.StringSlicePointer([]string{}),
+		Smg_replication_conns:  &[]*HaPoolJsonCfg{},
+		Debit_interval:         utils.StringPointer("0s"),
+		Min_call_duration:      utils.StringPointer("0s"),
+		Max_call_duration:      utils.StringPointer("3h"),
+		Session_ttl:            utils.StringPointer("0s"),
+		Session_ttl_policy:     utils.StringPointer(""),
+		Session_indexes:        utils.StringSlicePointer([]string{}),
+		Dedup_keys:             utils.StringSlicePointer([]string{}),
+		Dedup_merge_policy:     utils.StringPointer(utils.MetaFirst),
+		Dedup_ttl:              utils.StringPointer("1h"),
+		Auth_hint_destinations: &map[string]string{},
+		Interim_updates:        &map[string]*InterimUpdateJsonCfg{},
 	}
 	if cfg, err := dfCgrJsonCfg.SmGenericJsonCfg(); err != nil {
 		t.Error(err)
@@ -593,6 +644,40 @@ func TestRadiusAgentJsonCfg(t *testing.T) {
 	}
 }
 
+func TestNatsAgentJsonCfg(t *testing.T) {
+	eCfg := &NatsAgentJsonCfg{
+		Enabled:       utils.BoolPointer(false),
+		Server_url:    utils.StringPointer("nats://127.0.0.1:4222"),
+		Subject:       utils.StringPointer("cgrates_cdrs"),
+		Jetstream:     utils.BoolPointer(false),
+		Consumer_name: utils.StringPointer("cgrates"),
+		Cdr_format:    utils.StringPointer(utils.MetaJSONCDR),
+		Timezone:      utils.StringPointer(""),
+		Cdrs_conns: &[]*HaPoolJsonCfg{
+			&HaPoolJsonCfg{
+				Address: utils.StringPointer(utils.MetaInternal),
+			}},
+	}
+	if cfg, err := dfCgrJsonCfg.NatsAgentJsonCfg(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eCfg, cfg) {
+		t.Errorf("Received: %s", utils.ToJSON(cfg))
+	}
+}
+
+func TestDfReplicationJsonCfg(t *testing.T) {
+	eCfg := &ReplicationJsonCfg{
+		Read_only:            utils.BoolPointer(false),
+		Replication_conns:    &[]*HaPoolJsonCfg{},
+		Replication_interval: utils.StringPointer("1s"),
+	}
+	if cfg, err := dfCgrJsonCfg.ReplicationJsonCfg(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eCfg, cfg) {
+		t.Errorf("Received: %s", utils.ToJSON(cfg))
+	}
+}
+
 func TestDfHistServJsonCfg(t *testing.T) {
 	eCfg := &HistServJsonCfg{
 		Enabled:       utils.BoolPointer(false),
@@ -642,9 +727,11 @@ func TestDfUserServJsonCfg(t *testing.T) {
 
 func TestDfResourceLimiterSJsonCfg(t *testing.T) {
 	eCfg := &ResourceLimiterServJsonCfg{
-		Enabled:             utils.BoolPointer(false),
-		Cdrstats_conns:      &[]*HaPoolJsonCfg{},
-		Cache_dump_interval: utils.StringPointer("0s"),
+		Enabled:                utils.BoolPointer(false),
+		Cdrstats_conns:         &[]*HaPoolJsonCfg{},
+		Cache_dump_interval:    utils.StringPointer("0s"),
+		Usage_samples_interval: utils.StringPointer("0s"),
+		Usage_samples_size:     utils.IntPointer(288),
 	}
 	if cfg, err := dfCgrJsonCfg.ResourceLimiterJsonCfg(); err != nil {
 		t.Error(err)
@@ -667,6 +754,21 @@ func TestDfMailerJsonCfg(t *testing.T) {
 	}
 }
 
+func TestDfSmsJsonCfg(t *testing.T) {
+	eCfg := &SmsJsonCfg{
+		Transport:     utils.StringPointer(""),
+		Address:       utils.StringPointer(""),
+		Auth_user:     utils.StringPointer(""),
+		Auth_password: utils.StringPointer(""),
+		From_address:  utils.StringPointer("CGRateS"),
+	}
+	if cfg, err := dfCgrJsonCfg.SmsJsonCfg(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eCfg, cfg) {
+		t.Error("Received: ", cfg)
+	}
+}
+
 func TestDfSureTaxJsonCfg(t *testing.T) {
 	eCfg := &SureTaxJsonCfg{
 		Url:                     utils.StringPointer(""),
@@ -19,6 +19,7 @@ package config
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -145,6 +146,9 @@ func TestCgrCfgJSONDefaultsGeneral(t *testing.T) {
 	if cgrCfg.RoundingDecimals != 5 {
 		t.Error(cgrCfg.RoundingDecimals)
 	}
+	if cgrCfg.DecimalPrecision != 9 {
+		t.Error(cgrCfg.DecimalPrecision)
+	}
 	if cgrCfg.DBDataEncoding != "msgpack" {
 		t.Error(cgrCfg.DBDataEncoding)
 	}
@@ -169,6 +173,9 @@ func TestCgrCfgJSONDefaultsGeneral(t *testing.T) {
 	if cgrCfg.DefaultTimezone != "Local" {
 		t.Error(cgrCfg.DefaultTimezone)
 	}
+	if len(cgrCfg.TenantTimezones) != 0 {
+		t.Error(cgrCfg.TenantTimezones)
+	}
 	if cgrCfg.ConnectAttempts != 3 {
 		t.Error(cgrCfg.ConnectAttempts)
 	}
@@ -190,6 +197,18 @@ func TestCgrCfgJSONDefaultsGeneral(t *testing.T) {
 	if cgrCfg.LockingTimeout != 5*time.Second {
 		t.Error(cgrCfg.LockingTimeout)
 	}
+	if cgrCfg.JobsTTL != time.Hour {
+		t.Error(cgrCfg.JobsTTL)
+	}
+	if cgrCfg.ClockSkewThreshold != 0 {
+		t.Error(cgrCfg.ClockSkewThreshold)
+	}
+	if len(cgrCfg.TPLoadNotifyAddresses) != 0 {
+		t.Error(cgrCfg.TPLoadNotifyAddresses)
+	}
+	if cgrCfg.DebugRecordsTTL != time.Hour {
+		t.Error(cgrCfg.DebugRecordsTTL)
+	}
 	if cgrCfg.LogLevel != 6 {
 		t.Error(cgrCfg.LogLevel)
 	}
@@ -229,6 +248,25 @@ func TestCgrCfgJSONDefaultsjsnDataDb(t *testing.T) {
 	if cgrCfg.LoadHistorySize != 10 {
 		t.Error(cgrCfg.LoadHistorySize)
 	}
+	if cgrCfg.TPSnapshotsSize != 5 {
+		t.Error(cgrCfg.TPSnapshotsSize)
+	}
+	if cgrCfg.DataDbDestinationsIndex {
+		t.Error(cgrCfg.DataDbDestinationsIndex)
+	}
+	if cgrCfg.DataDbDestIndexType != utils.MetaTree {
+		t.Error(cgrCfg.DataDbDestIndexType)
+	}
+	if cgrCfg.DataDbRpSplitSize != 0 {
+		t.Error(cgrCfg.DataDbRpSplitSize)
+	}
+	if eBreaker := (&CircuitBreakerCfg{OpenInterval: 5 * time.Second}); !reflect.DeepEqual(eBreaker, cgrCfg.DataDbBreaker) {
+		t.Errorf("Expecting: %+v, received: %+v", eBreaker, cgrCfg.DataDbBreaker)
+	}
+	eTier := &DataDBTierCfg{DbHost: "127.0.0.1", DbPort: "6379", DbName: "11", HotTTL: time.Hour}
+	if !reflect.DeepEqual(eTier, cgrCfg.DataDbLocalTier) {
+		t.Errorf("Expecting: %+v, received: %+v", eTier, cgrCfg.DataDbLocalTier)
+	}
 }
 
 func TestCgrCfgJSONDefaultsStorDB(t *testing.T) {
@@ -260,6 +298,13 @@ func TestCgrCfgJSONDefaultsStorDB(t *testing.T) {
 	if !reflect.DeepEqual(cgrCfg.StorDBCDRSIndexes, Eslice) {
 		t.Error(cgrCfg.StorDBCDRSIndexes)
 	}
+	if !reflect.DeepEqual(cgrCfg.StorDBCDRSIndexedFields, Eslice) {
+		t.Error(cgrCfg.StorDBCDRSIndexedFields)
+	}
+	eClickHouse := &CdrsClickHouseCfg{User: "default", BatchSize: 1000, FlushInterval: time.Second}
+	if !reflect.DeepEqual(eClickHouse, cgrCfg.StorDBCdrsClickHouse) {
+		t.Errorf("Expecting: %+v, received: %+v", eClickHouse, cgrCfg.StorDBCdrsClickHouse)
+	}
 }
 
 func TestCgrCfgJSONDefaultsRALs(t *testing.T) {
@@ -287,9 +332,33 @@ func TestCgrCfgJSONDefaultsRALs(t *testing.T) {
 	if cgrCfg.RpSubjectPrefixMatching != false {
 		t.Error(cgrCfg.RpSubjectPrefixMatching)
 	}
+	if cgrCfg.RpFallbackMaxDepth != 3 {
+		t.Error(cgrCfg.RpFallbackMaxDepth)
+	}
+	if len(cgrCfg.RpCompiledPlans) != 0 {
+		t.Error(cgrCfg.RpCompiledPlans)
+	}
 	if cgrCfg.LcrSubjectPrefixMatching != false {
 		t.Error(cgrCfg.LcrSubjectPrefixMatching)
 	}
+	if cgrCfg.LcrBlacklistFailures != 0 {
+		t.Error(cgrCfg.LcrBlacklistFailures)
+	}
+	if cgrCfg.LcrBlacklistCooldown != 5*time.Minute {
+		t.Error(cgrCfg.LcrBlacklistCooldown)
+	}
+	if cgrCfg.LowCreditGrace != 0 {
+		t.Error(cgrCfg.LowCreditGrace)
+	}
+	if len(cgrCfg.EmergencyDestinations) != 0 {
+		t.Error(cgrCfg.EmergencyDestinations)
+	}
+	if len(cgrCfg.ExpiryNotifyDays) != 0 || cgrCfg.ExpiryNotifyActions != "" {
+		t.Error(cgrCfg.ExpiryNotifyDays, cgrCfg.ExpiryNotifyActions)
+	}
+	if cgrCfg.RALsBalanceNotifyAddress != "" {
+		t.Error(cgrCfg.RALsBalanceNotifyAddress)
+	}
 }
 
 func TestCgrCfgJSONDefaultsScheduler(t *testing.T) {
@@ -334,6 +403,12 @@ func TestCgrCfgJSONDefaultsCDRS(t *testing.T) {
 	if cgrCfg.CDRSOnlineCDRExports != nil {
 		t.Error(cgrCfg.CDRSOnlineCDRExports)
 	}
+	if cgrCfg.CDRSUnratedRetryOnLoad {
+		t.Error(cgrCfg.CDRSUnratedRetryOnLoad)
+	}
+	if cgrCfg.CDRSShadowRatingSubject != "" {
+		t.Error(cgrCfg.CDRSShadowRatingSubject)
+	}
 }
 
 func TestCgrCfgJSONDefaultsCDRStats(t *testing.T) {
@@ -395,6 +470,11 @@ func TestCgrCfgJSONDefaultsSMGenericCfg(t *testing.T) {
 		MaxCallDuration:     3 * time.Hour,
 		SessionTTL:          0 * time.Second,
 		SessionIndexes:      utils.StringMap{},
+		DedupKeys:           []string{},
+		DedupMergePolicy:    utils.MetaFirst,
+		DedupTTL:            time.Duration(1 * time.Hour),
+		AuthHintDsts:        map[string]string{},
+		InterimUpdates:      map[string]*InterimUpdateCfg{},
 	}
 
 	if !reflect.DeepEqual(cgrCfg.SmGenericConfig, eSmGeCfg) {
@@ -514,9 +594,11 @@ func TestCgrCfgJSONDefaultsUserS(t *testing.T) {
 
 func TestCgrCfgJSONDefaultsResLimCfg(t *testing.T) {
 	eResLiCfg := &ResourceLimiterConfig{
-		Enabled:           false,
-		CDRStatConns:      []*HaPoolConfig{},
-		CacheDumpInterval: 0 * time.Second,
+		Enabled:              false,
+		CDRStatConns:         []*HaPoolConfig{},
+		CacheDumpInterval:    0 * time.Second,
+		UsageSamplesInterval: 0,
+		UsageSamplesSize:     288,
 	}
 
 	if !reflect.DeepEqual(cgrCfg.resourceLimiterCfg, eResLiCfg) {
@@ -598,6 +680,24 @@ func TestCgrCfgJSONDefaultsMailer(t *testing.T) {
 	}
 }
 
+func TestCgrCfgJSONDefaultsSms(t *testing.T) {
+	if cgrCfg.SmsTransport != "" || cgrCfg.SmsAddress != "" || cgrCfg.SmsAuthUser != "" || cgrCfg.SmsAuthPass != "" {
+		t.Errorf("Unexpected sms config: %s %s %s %s", cgrCfg.SmsTransport, cgrCfg.SmsAddress, cgrCfg.SmsAuthUser, cgrCfg.SmsAuthPass)
+	}
+	if cgrCfg.SmsFromAddr != "CGRateS" {
+		t.Error(cgrCfg.SmsFromAddr)
+	}
+	cfg, _ := NewDefaultCGRConfig()
+	cfg.SmsTransport = utils.MetaSMPP
+	if err := cfg.checkConfigSanity(); err == nil {
+		t.Error("Expecting error on missing sms address")
+	}
+	cfg.SmsAddress = "127.0.0.1:2775"
+	if err := cfg.checkConfigSanity(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestCgrCfgJSONDefaultsSureTax(t *testing.T) {
 	localt, err := time.LoadLocation("Local")
 	if err != nil {
@@ -635,3 +735,86 @@ func TestCgrCfgJSONDefaultsSureTax(t *testing.T) {
 		t.Errorf("received: %+v, expecting: %+v", cgrCfg.sureTaxCfg, eSureTaxCfg)
 	}
 }
+
+func TestCgrCfgValidate(t *testing.T) {
+	if errs := cgrCfg.Validate(); len(errs) != 0 {
+		t.Errorf("Unexpected errors on defaults: %v", errs)
+	}
+	jsnCfg := `{
+"cache": {"destinations": {"limit": 0, "precache": true}},
+"cdre": {"http_export": {"export_format": "*http_json_cdr", "export_path": "/var/spool/cgrates/cdre"}},
+"diameter_agent": {"enabled": true, "sm_generic_conns": []},
+}`
+	cfg, err := NewCGRConfigFromJsonStringWithDefaults(jsnCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := cfg.Validate()
+	if len(errs) != 3 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	for _, errPrfx := range []string{"<CDRE> profile <http_export>", "<DiameterAgent>", "<Cache> Destinations"} {
+		var found bool
+		for _, err := range errs {
+			if strings.HasPrefix(err.Error(), errPrfx) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("No error starting with %s in %v", errPrfx, errs)
+		}
+	}
+}
+
+func TestCgrCfgAsRedactedMap(t *testing.T) {
+	cfg, _ := NewDefaultCGRConfig()
+	cfg.DataDbPass = "secretDataDBPass"
+	cfg.SmFsConfig.EventSocketConns = []*FsConnConfig{&FsConnConfig{Address: "127.0.0.1:8021", Password: "ClueCon"}}
+	cfgMap, err := cfg.AsRedactedMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfgMap["DataDbPass"] != RedactedValue {
+		t.Errorf("Expecting: %s, received: %v", RedactedValue, cfgMap["DataDbPass"])
+	}
+	if cfgMap["StorDBPass"] != "" { // no password configured
+		t.Errorf("Expecting empty password, received: %v", cfgMap["StorDBPass"])
+	}
+	if cfgMap["MailerAuthPass"] != RedactedValue {
+		t.Errorf("Expecting: %s, received: %v", RedactedValue, cfgMap["MailerAuthPass"])
+	}
+	if cfgMap["DataDbHost"] != cfg.DataDbHost {
+		t.Errorf("Expecting: %s, received: %v", cfg.DataDbHost, cfgMap["DataDbHost"])
+	}
+	fsConn := cfgMap["SmFsConfig"].(map[string]interface{})["EventSocketConns"].([]interface{})[0].(map[string]interface{})
+	if fsConn["Password"] != RedactedValue || fsConn["Address"] != "127.0.0.1:8021" {
+		t.Errorf("Unexpected connection: %+v", fsConn)
+	}
+	if _, has := cfgMap["ConfigReloads"]; has {
+		t.Error("ConfigReloads should not be exported")
+	}
+	if _, has := cfgMap["SureTaxCfg"]; !has {
+		t.Error("SureTaxCfg missing")
+	}
+}
+
+func TestCgrCfgSmGenericInterimUpdates(t *testing.T) {
+	jsnCfg := `{
+"sm_generic": {"interim_updates": {
+	"*default": {"min_interval": "10s"},
+	"10.0.0.1": {"extrapolate_usage": true, "out_of_order_tolerance": "2s"},
+}},
+}`
+	cfg, err := NewCGRConfigFromJsonStringWithDefaults(jsnCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eIuCfg := &InterimUpdateCfg{ExtrapolateUsage: true, OutOfOrderTolerance: 2 * time.Second}
+	if iuCfg := cfg.SmGenericConfig.InterimUpdateCfg("10.0.0.1"); !reflect.DeepEqual(eIuCfg, iuCfg) {
+		t.Errorf("Expecting: %+v, received: %+v", eIuCfg, iuCfg)
+	}
+	eIuCfg = &InterimUpdateCfg{MinInterval: 10 * time.Second}
+	if iuCfg := cfg.SmGenericConfig.InterimUpdateCfg("10.0.0.2"); !reflect.DeepEqual(eIuCfg, iuCfg) {
+		t.Errorf("Expecting: %+v, received: %+v", eIuCfg, iuCfg)
+	}
+}
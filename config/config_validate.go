@@ -0,0 +1,119 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ValidateCGRConfigFolder loads the configuration out of cfgDir and returns all the problems found instead of stopping at the first one
+func ValidateCGRConfigFolder(cfgDir string) (errs []error) {
+	cfg, err := loadCGRConfigFromFolder(cfgDir)
+	if err != nil {
+		return []error{err}
+	}
+	return cfg.Validate()
+}
+
+// Validate runs the sanity checks done on load together with the cross-subsystem ones
+func (self *CGRConfig) Validate() (errs []error) {
+	if err := self.checkConfigSanity(); err != nil {
+		errs = append(errs, err)
+	}
+	return append(errs, self.checkCrossReferences()...)
+}
+
+// checkCrossReferences reports the references between subsystems which would fail at runtime
+func (self *CGRConfig) checkCrossReferences() (errs []error) {
+	// CDR export profiles
+	for prflID, cdreCfg := range self.CdreProfiles {
+		if !utils.IsSliceMember(append([]string{}, utils.CDRExportFormats...), cdreCfg.ExportFormat) {
+			errs = append(errs, fmt.Errorf("<CDRE> profile <%s> has unsupported export_format <%s>, use one of: %s",
+				prflID, cdreCfg.ExportFormat, strings.Join(utils.CDRExportFormats, ", ")))
+			continue
+		}
+		switch cdreCfg.ExportFormat {
+		case utils.MetaHTTPjsonCDR, utils.MetaHTTPjsonMap, utils.MetaHTTPjson, utils.META_HTTP_POST:
+			if !strings.HasPrefix(cdreCfg.ExportPath, "http://") && !strings.HasPrefix(cdreCfg.ExportPath, "https://") {
+				errs = append(errs, fmt.Errorf("<CDRE> profile <%s> exports with <%s> but export_path <%s> is not an http(s) URL",
+					prflID, cdreCfg.ExportFormat, cdreCfg.ExportPath))
+			}
+		case utils.MetaAMQPjsonCDR, utils.MetaAMQPjsonMap:
+			if !strings.HasPrefix(cdreCfg.ExportPath, "amqp://") && !strings.HasPrefix(cdreCfg.ExportPath, "amqps://") {
+				errs = append(errs, fmt.Errorf("<CDRE> profile <%s> exports with <%s> but export_path <%s> is not an amqp(s) URL",
+					prflID, cdreCfg.ExportFormat, cdreCfg.ExportPath))
+			}
+		case utils.MetaNATSjsonCDR, utils.MetaNATSjsonMap:
+			if !strings.HasPrefix(cdreCfg.ExportPath, "nats://") {
+				errs = append(errs, fmt.Errorf("<CDRE> profile <%s> exports with <%s> but export_path <%s> is not a nats URL",
+					prflID, cdreCfg.ExportFormat, cdreCfg.ExportPath))
+			}
+		}
+	}
+	if !self.CDRSEnabled && len(self.CDRSOnlineCDRExports) != 0 {
+		errs = append(errs, fmt.Errorf("<CDRS> online_cdr_exports %v defined but cdrs is not enabled", self.CDRSOnlineCDRExports))
+	}
+	// CDRC instances
+	for _, cdrcCfgs := range self.CdrcProfiles {
+		for _, cdrcInst := range cdrcCfgs {
+			if cdrcInst.Enabled && cdrcInst.CdrInDir == cdrcInst.CdrOutDir {
+				errs = append(errs, fmt.Errorf("<CDRC> instance <%s> has the same cdr_in_dir and cdr_out_dir <%s>, processed files would be read again",
+					cdrcInst.ID, cdrcInst.CdrInDir))
+			}
+		}
+	}
+	// Agents
+	if self.diameterAgentCfg.Enabled && len(self.diameterAgentCfg.SMGenericConns) == 0 {
+		errs = append(errs, fmt.Errorf("<DiameterAgent> enabled but no sm_generic_conns defined"))
+	}
+	if self.radiusAgentCfg.Enabled && len(self.radiusAgentCfg.SMGenericConns) == 0 {
+		errs = append(errs, fmt.Errorf("<RadiusAgent> enabled but no sm_generic_conns defined"))
+	}
+	if self.natsAgentCfg.Enabled {
+		if len(self.natsAgentCfg.CDRsConns) == 0 {
+			errs = append(errs, fmt.Errorf("<NatsAgent> enabled but no cdrs_conns defined"))
+		}
+		if !strings.HasPrefix(self.natsAgentCfg.ServerURL, "nats://") {
+			errs = append(errs, fmt.Errorf("<NatsAgent> server_url <%s> is not a nats URL", self.natsAgentCfg.ServerURL))
+		}
+		if !utils.IsSliceMember([]string{utils.MetaJSONCDR, utils.MetaJSONMap}, self.natsAgentCfg.CDRFormat) {
+			errs = append(errs, fmt.Errorf("<NatsAgent> unsupported cdr_format <%s>", self.natsAgentCfg.CDRFormat))
+		}
+	}
+	// Cache partitions
+	if self.CacheConfig != nil {
+		cacheVal := reflect.ValueOf(self.CacheConfig).Elem()
+		for i := 0; i < cacheVal.NumField(); i++ {
+			cacheParam, canCast := cacheVal.Field(i).Interface().(*CacheParamConfig)
+			if !canCast || cacheParam == nil || !cacheParam.Precache {
+				continue
+			}
+			partition := cacheVal.Type().Field(i).Name
+			if cacheParam.Limit == 0 {
+				errs = append(errs, fmt.Errorf("<Cache> %s has precache enabled but limit 0 disables caching it, increase the limit or disable precache", partition))
+			} else if cacheParam.TTL != 0 {
+				errs = append(errs, fmt.Errorf("<Cache> %s has precache enabled but ttl %v will expire the precached items", partition, cacheParam.TTL))
+			}
+		}
+	}
+	return
+}
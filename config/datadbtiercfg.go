@@ -0,0 +1,62 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// DataDBTierCfg configures the local store keeping the hot objects in front of a remote data_db
+type DataDBTierCfg struct {
+	DbType     string // empty disables tiering
+	DbHost     string
+	DbPort     string
+	DbName     string
+	DbPassword string
+	HotTTL     time.Duration // hot objects not accessed within this interval are demoted
+}
+
+func (self *DataDBTierCfg) loadFromJsonCfg(jsnCfg *DataDBTierJsonCfg) (err error) {
+	if jsnCfg == nil {
+		return nil
+	}
+	if jsnCfg.Db_type != nil {
+		self.DbType = *jsnCfg.Db_type
+	}
+	if jsnCfg.Db_host != nil {
+		self.DbHost = *jsnCfg.Db_host
+	}
+	if jsnCfg.Db_port != nil {
+		self.DbPort = strconv.Itoa(*jsnCfg.Db_port)
+	}
+	if jsnCfg.Db_name != nil {
+		self.DbName = *jsnCfg.Db_name
+	}
+	if jsnCfg.Db_password != nil {
+		self.DbPassword = *jsnCfg.Db_password
+	}
+	if jsnCfg.Hot_ttl != nil {
+		if self.HotTTL, err = utils.ParseDurationWithSecs(*jsnCfg.Hot_ttl); err != nil {
+			return
+		}
+	}
+	return
+}
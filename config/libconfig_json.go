@@ -19,25 +19,31 @@ package config
 
 // General config section
 type GeneralJsonCfg struct {
-	Instance_id          *string
-	Log_level            *int
-	Http_skip_tls_verify *bool
-	Rounding_decimals    *int
-	Dbdata_encoding      *string
-	Tpexport_dir         *string
-	Poster_attempts      *int
-	Failed_posts_dir     *string
-	Default_request_type *string
-	Default_category     *string
-	Default_tenant       *string
-	Default_timezone     *string
-	Connect_attempts     *int
-	Reconnects           *int
-	Connect_timeout      *string
-	Reply_timeout        *string
-	Response_cache_ttl   *string
-	Internal_ttl         *string
-	Locking_timeout      *string
+	Instance_id             *string
+	Log_level               *int
+	Http_skip_tls_verify    *bool
+	Rounding_decimals       *int
+	Decimal_precision       *int
+	Dbdata_encoding         *string
+	Tpexport_dir            *string
+	Poster_attempts         *int
+	Failed_posts_dir        *string
+	Default_request_type    *string
+	Default_category        *string
+	Default_tenant          *string
+	Default_timezone        *string
+	Tenant_timezones        *map[string]string
+	Connect_attempts        *int
+	Reconnects              *int
+	Connect_timeout         *string
+	Reply_timeout           *string
+	Response_cache_ttl      *string
+	Internal_ttl            *string
+	Locking_timeout         *string
+	Jobs_ttl                *string
+	Clock_skew_threshold    *string
+	Tpload_notify_addresses *[]string
+	Debug_records_ttl       *string
 }
 
 // Listen config section
@@ -57,16 +63,51 @@ type HTTPJsonCfg struct {
 
 // Database config
 type DbJsonCfg struct {
-	Db_type           *string
-	Db_host           *string
-	Db_port           *int
-	Db_name           *string
-	Db_user           *string
-	Db_password       *string
-	Max_open_conns    *int // Used only in case of storDb
-	Max_idle_conns    *int
-	Load_history_size *int // Used in case of dataDb to limit the length of the loads history
-	Cdrs_indexes      *[]string
+	Db_type             *string
+	Db_host             *string
+	Db_port             *int
+	Db_name             *string
+	Db_user             *string
+	Db_password         *string
+	Max_open_conns      *int // Used only in case of storDb
+	Max_idle_conns      *int
+	Load_history_size   *int    // Used in case of dataDb to limit the length of the loads history
+	Tp_snapshots_size   *int    // Used in case of dataDb to limit the number of rating snapshots
+	Destinations_index  *bool   // Used in case of dataDb to match destinations out of an in-memory prefix tree
+	Dst_index_type      *string // Used in case of dataDb to choose the layout of the destinations index
+	Rp_split_size       *int    // Used in case of dataDb to split the rating plans with many destinations
+	Cdrs_indexes        *[]string
+	Cdrs_indexed_fields *[]string // Used in case of storDb to mirror CDR extra fields into indexed columns
+	Circuit_breaker     *CircuitBreakerJsonCfg
+	Local_tier          *DataDBTierJsonCfg     // Used in case of dataDb to keep the hot objects in a local store
+	Cdrs_clickhouse     *CdrsClickHouseJsonCfg // Used in case of storDb to keep the CDRs in ClickHouse
+}
+
+// ClickHouse server storing the CDRs
+type CdrsClickHouseJsonCfg struct {
+	Url            *string
+	User           *string
+	Password       *string
+	Batch_size     *int
+	Flush_interval *string
+	Ttl            *string
+}
+
+// Local store in front of a remote dataDb
+type DataDBTierJsonCfg struct {
+	Db_type     *string
+	Db_host     *string
+	Db_port     *int
+	Db_name     *string
+	Db_password *string
+	Hot_ttl     *string
+}
+
+// Circuit breaker protecting database access
+type CircuitBreakerJsonCfg struct {
+	Max_failures  *int
+	Slow_call     *string
+	Open_interval *string
 }
 
 // Rater config section
@@ -78,7 +119,16 @@ type RalsJsonCfg struct {
 	Aliases_conns               *[]*HaPoolJsonCfg
 	Users_conns                 *[]*HaPoolJsonCfg
 	Rp_subject_prefix_matching  *bool
+	Rp_fallback_max_depth       *int
+	Rp_compiled_plans           *[]string
 	Lcr_subject_prefix_matching *bool
+	Lcr_blacklist_failures      *int
+	Lcr_blacklist_cooldown      *string
+	Low_credit_grace            *string
+	Emergency_destinations      *[]string
+	Expiry_notify_days          *[]int
+	Expiry_notify_actions       *string
+	Balance_notify_address      *string
 }
 
 // Scheduler config section
@@ -88,17 +138,19 @@ type SchedulerJsonCfg struct {
 
 // Cdrs config section
 type CdrsJsonCfg struct {
-	Enabled             *bool
-	Extra_fields        *[]string
-	Store_cdrs          *bool
-	Cdr_account_summary *bool
-	Sm_cost_retries     *int
-	Rals_conns          *[]*HaPoolJsonCfg
-	Pubsubs_conns       *[]*HaPoolJsonCfg
-	Users_conns         *[]*HaPoolJsonCfg
-	Aliases_conns       *[]*HaPoolJsonCfg
-	Cdrstats_conns      *[]*HaPoolJsonCfg
-	Online_cdr_exports  *[]string
+	Enabled               *bool
+	Extra_fields          *[]string
+	Store_cdrs            *bool
+	Cdr_account_summary   *bool
+	Sm_cost_retries       *int
+	Rals_conns            *[]*HaPoolJsonCfg
+	Pubsubs_conns         *[]*HaPoolJsonCfg
+	Users_conns           *[]*HaPoolJsonCfg
+	Aliases_conns         *[]*HaPoolJsonCfg
+	Cdrstats_conns        *[]*HaPoolJsonCfg
+	Online_cdr_exports    *[]string
+	Unrated_retry_on_load *bool
+	Shadow_rating_subject *string
 }
 
 type CdrReplicationJsonCfg struct {
@@ -148,6 +200,8 @@ type CdreJsonCfg struct {
 	Field_separator       *string
 	Usage_multiply_factor *map[string]float64
 	Cost_multiply_factor  *float64
+	Run_ids               *[]string
+	Sum_runs              *bool
 	Header_fields         *[]*CdrFieldJsonCfg
 	Content_fields        *[]*CdrFieldJsonCfg
 	Trailer_fields        *[]*CdrFieldJsonCfg
@@ -182,19 +236,32 @@ type CdrcJsonCfg struct {
 
 // SM-Generic config section
 type SmGenericJsonCfg struct {
-	Enabled               *bool
-	Listen_bijson         *string
-	Rals_conns            *[]*HaPoolJsonCfg
-	Cdrs_conns            *[]*HaPoolJsonCfg
-	Smg_replication_conns *[]*HaPoolJsonCfg
-	Debit_interval        *string
-	Min_call_duration     *string
-	Max_call_duration     *string
-	Session_ttl           *string
-	Session_ttl_max_delay *string
-	Session_ttl_last_used *string
-	Session_ttl_usage     *string
-	Session_indexes       *[]string
+	Enabled                *bool
+	Listen_bijson          *string
+	Rals_conns             *[]*HaPoolJsonCfg
+	Cdrs_conns             *[]*HaPoolJsonCfg
+	Smg_replication_conns  *[]*HaPoolJsonCfg
+	Debit_interval         *string
+	Min_call_duration      *string
+	Max_call_duration      *string
+	Session_ttl            *string
+	Session_ttl_max_delay  *string
+	Session_ttl_last_used  *string
+	Session_ttl_usage      *string
+	Session_ttl_policy     *string
+	Session_indexes        *[]string
+	Dedup_keys             *[]string
+	Dedup_merge_policy     *string
+	Dedup_ttl              *string
+	Auth_hint_destinations *map[string]string
+	Interim_updates        *map[string]*InterimUpdateJsonCfg
+}
+
+// Interim updates handling of one agent
+type InterimUpdateJsonCfg struct {
+	Min_interval           *string
+	Extrapolate_usage      *bool
+	Out_of_order_tolerance *string
 }
 
 // SM-FreeSWITCH config section
@@ -241,9 +308,10 @@ type SMAsteriskJsonCfg struct {
 }
 
 type CacheParamJsonCfg struct {
-	Limit    *int
-	Ttl      *string
-	Precache *bool
+	Limit     *int
+	Max_bytes *int
+	Ttl       *string
+	Precache  *bool
 }
 
 type CacheJsonCfg struct {
@@ -356,6 +424,25 @@ type RadiusAgentJsonCfg struct {
 	Request_processors   *[]*RAReqProcessorJsnCfg
 }
 
+// Replication towards read-only engines
+type ReplicationJsonCfg struct {
+	Read_only            *bool
+	Replication_conns    *[]*HaPoolJsonCfg
+	Replication_interval *string
+}
+
+// NATS Agent configuration section
+type NatsAgentJsonCfg struct {
+	Enabled       *bool
+	Server_url    *string
+	Subject       *string
+	Jetstream     *bool
+	Consumer_name *string
+	Cdr_format    *string
+	Timezone      *string
+	Cdrs_conns    *[]*HaPoolJsonCfg
+}
+
 type RAReqProcessorJsnCfg struct {
 	Id                  *string
 	Dry_run             *bool
@@ -392,9 +479,11 @@ type UserServJsonCfg struct {
 
 // ResourceLimiter service config section
 type ResourceLimiterServJsonCfg struct {
-	Enabled             *bool
-	Cdrstats_conns      *[]*HaPoolJsonCfg
-	Cache_dump_interval *string
+	Enabled                *bool
+	Cdrstats_conns         *[]*HaPoolJsonCfg
+	Cache_dump_interval    *string
+	Usage_samples_interval *string
+	Usage_samples_size     *int
 }
 
 // Mailer config section
@@ -405,6 +494,15 @@ type MailerJsonCfg struct {
 	From_address  *string
 }
 
+// Sms config section
+type SmsJsonCfg struct {
+	Transport     *string
+	Address       *string
+	Auth_user     *string
+	Auth_password *string
+	From_address  *string
+}
+
 // SureTax config section
 type SureTaxJsonCfg struct {
 	Url                     *string
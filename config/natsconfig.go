@@ -0,0 +1,65 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package config
+
+// NatsAgentCfg is the configuration of the agent consuming CDRs out of NATS
+type NatsAgentCfg struct {
+	Enabled      bool
+	ServerURL    string
+	Subject      string
+	JetStream    bool   // consume through a JetStream durable consumer instead of core NATS
+	ConsumerName string // durable consumer on JetStream, queue group on core NATS
+	CDRFormat    string
+	Timezone     string
+	CDRsConns    []*HaPoolConfig
+}
+
+func (self *NatsAgentCfg) loadFromJsonCfg(jsnCfg *NatsAgentJsonCfg) error {
+	if jsnCfg == nil {
+		return nil
+	}
+	if jsnCfg.Enabled != nil {
+		self.Enabled = *jsnCfg.Enabled
+	}
+	if jsnCfg.Server_url != nil {
+		self.ServerURL = *jsnCfg.Server_url
+	}
+	if jsnCfg.Subject != nil {
+		self.Subject = *jsnCfg.Subject
+	}
+	if jsnCfg.Jetstream != nil {
+		self.JetStream = *jsnCfg.Jetstream
+	}
+	if jsnCfg.Consumer_name != nil {
+		self.ConsumerName = *jsnCfg.Consumer_name
+	}
+	if jsnCfg.Cdr_format != nil {
+		self.CDRFormat = *jsnCfg.Cdr_format
+	}
+	if jsnCfg.Timezone != nil {
+		self.Timezone = *jsnCfg.Timezone
+	}
+	if jsnCfg.Cdrs_conns != nil {
+		self.CDRsConns = make([]*HaPoolConfig, len(*jsnCfg.Cdrs_conns))
+		for idx, jsnHaCfg := range *jsnCfg.Cdrs_conns {
+			self.CDRsConns[idx] = NewDfltHaPoolConfig()
+			self.CDRsConns[idx].loadFromJsonCfg(jsnHaCfg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+)
+
+const RedactedValue = "*redacted"
+
+// field names holding credentials
+var secretFieldRgx = regexp.MustCompile(`(?i)pass|secret|token|validationkey`)
+
+// AsRedactedMap returns the configuration as generic map with the credentials masked, safe to be shared for diagnostics
+func (self *CGRConfig) AsRedactedMap() (cfgMap map[string]interface{}, err error) {
+	cfgMap = make(map[string]interface{})
+	v := reflect.ValueOf(self).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		fld := v.Type().Field(i)
+		if fld.PkgPath != "" { // unexported, added bellow using the getters
+			continue
+		}
+		if cfgMap[fld.Name], err = redactedValue(fld.Name, v.Field(i).Interface()); err != nil {
+			delete(cfgMap, fld.Name) // not serializable, ie: reload channels
+		}
+	}
+	for fldName, val := range map[string]interface{}{
+		"SMAsteriskCfg":      self.smAsteriskCfg,
+		"DiameterAgentCfg":   self.diameterAgentCfg,
+		"RadiusAgentCfg":     self.radiusAgentCfg,
+		"NatsAgentCfg":       self.natsAgentCfg,
+		"ResourceLimiterCfg": self.resourceLimiterCfg,
+		"SureTaxCfg":         self.sureTaxCfg,
+	} {
+		if cfgMap[fldName], err = redactedValue(fldName, val); err != nil {
+			return nil, err
+		}
+	}
+	return cfgMap, nil
+}
+
+// redactedValue converts val into its JSON representation and masks the credentials inside
+func redactedValue(name string, val interface{}) (interface{}, error) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	var jsnVal interface{}
+	if err := json.Unmarshal(b, &jsnVal); err != nil {
+		return nil, err
+	}
+	return redactJSONValue(name, jsnVal), nil
+}
+
+func redactJSONValue(name string, val interface{}) interface{} {
+	if secretFieldRgx.MatchString(name) {
+		if val == nil || val == "" { // keep the information that no credentials were set
+			return val
+		}
+		return RedactedValue
+	}
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for k, fldVal := range v {
+			v[k] = redactJSONValue(k, fldVal)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSONValue(name, item)
+		}
+	}
+	return val
+}
@@ -0,0 +1,53 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package config
+
+import (
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ReplicationCfg configures feeding read-only replicas with the CDRs and accounts of a primary engine
+type ReplicationCfg struct {
+	ReadOnly            bool            // serve only the query APIs, the data being fed by the primary
+	ReplicationConns    []*HaPoolConfig // replicas fed by this engine
+	ReplicationInterval time.Duration   // batch the feed, 0 to send each update as it happens
+}
+
+func (self *ReplicationCfg) loadFromJsonCfg(jsnCfg *ReplicationJsonCfg) (err error) {
+	if jsnCfg == nil {
+		return nil
+	}
+	if jsnCfg.Read_only != nil {
+		self.ReadOnly = *jsnCfg.Read_only
+	}
+	if jsnCfg.Replication_conns != nil {
+		self.ReplicationConns = make([]*HaPoolConfig, len(*jsnCfg.Replication_conns))
+		for idx, jsnHaCfg := range *jsnCfg.Replication_conns {
+			self.ReplicationConns[idx] = NewDfltHaPoolConfig()
+			self.ReplicationConns[idx].loadFromJsonCfg(jsnHaCfg)
+		}
+	}
+	if jsnCfg.Replication_interval != nil {
+		if self.ReplicationInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Replication_interval); err != nil {
+			return
+		}
+	}
+	return
+}
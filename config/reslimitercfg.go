@@ -24,9 +24,11 @@ import (
 )
 
 type ResourceLimiterConfig struct {
-	Enabled           bool
-	CDRStatConns      []*HaPoolConfig // Connections towards CDRStatS
-	CacheDumpInterval time.Duration   // Dump regularly from cache into dataDB
+	Enabled              bool
+	CDRStatConns         []*HaPoolConfig // Connections towards CDRStatS
+	CacheDumpInterval    time.Duration   // Dump regularly from cache into dataDB
+	UsageSamplesInterval time.Duration   // Sample the utilization of each ResourceLimit at this interval, 0 to disable
+	UsageSamplesSize     int             // Number of samples kept per ResourceLimit
 }
 
 func (rlcfg *ResourceLimiterConfig) loadFromJsonCfg(jsnCfg *ResourceLimiterServJsonCfg) (err error) {
@@ -48,5 +50,13 @@ func (rlcfg *ResourceLimiterConfig) loadFromJsonCfg(jsnCfg *ResourceLimiterServJ
 			return err
 		}
 	}
+	if jsnCfg.Usage_samples_interval != nil {
+		if rlcfg.UsageSamplesInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Usage_samples_interval); err != nil {
+			return err
+		}
+	}
+	if jsnCfg.Usage_samples_size != nil {
+		rlcfg.UsageSamplesSize = *jsnCfg.Usage_samples_size
+	}
 	return nil
 }
@@ -100,7 +100,48 @@ type SmGenericConfig struct {
 	SessionTTLMaxDelay  *time.Duration
 	SessionTTLLastUsed  *time.Duration
 	SessionTTLUsage     *time.Duration
+	SessionTTLPolicy    string // usage charged for sessions timing-out: <""|*last_update|*zero|*max_reservation>
 	SessionIndexes      utils.StringMap
+	DedupKeys           []string                     // correlate events reported by redundant agents on these fields, empty disables dedup
+	DedupMergePolicy    string                       // conflicting fields out of duplicate events: <*first|*last>
+	DedupTTL            time.Duration                // keep the correlation data this long after the last event
+	AuthHintDsts        map[string]string            // destination classes with sample numbers, authorized additionally for hints
+	InterimUpdates      map[string]*InterimUpdateCfg // interim updates handling per agent OriginHost, *default for the others
+}
+
+// InterimUpdateCfg tunes the processing of the session updates reported by one agent
+type InterimUpdateCfg struct {
+	MinInterval         time.Duration // updates closer than this to the previous one are answered out of the last reservation, without debit
+	ExtrapolateUsage    bool          // consider the time elapsed since the previous update as used when LastUsed is missing
+	OutOfOrderTolerance time.Duration // accept updates with EventTime older than the last processed one within this limit
+}
+
+func (self *InterimUpdateCfg) loadFromJsonCfg(jsnCfg *InterimUpdateJsonCfg) (err error) {
+	if jsnCfg == nil {
+		return nil
+	}
+	if jsnCfg.Min_interval != nil {
+		if self.MinInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Min_interval); err != nil {
+			return
+		}
+	}
+	if jsnCfg.Extrapolate_usage != nil {
+		self.ExtrapolateUsage = *jsnCfg.Extrapolate_usage
+	}
+	if jsnCfg.Out_of_order_tolerance != nil {
+		if self.OutOfOrderTolerance, err = utils.ParseDurationWithSecs(*jsnCfg.Out_of_order_tolerance); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// InterimUpdateCfg returns the interim updates handling of the agent, nil for the default one
+func (self *SmGenericConfig) InterimUpdateCfg(originHost string) *InterimUpdateCfg {
+	if iuCfg, has := self.InterimUpdates[originHost]; has {
+		return iuCfg
+	}
+	return self.InterimUpdates[utils.META_DEFAULT]
 }
 
 func (self *SmGenericConfig) loadFromJsonCfg(jsnCfg *SmGenericJsonCfg) error {
@@ -169,9 +210,35 @@ func (self *SmGenericConfig) loadFromJsonCfg(jsnCfg *SmGenericJsonCfg) error {
 			self.SessionTTLLastUsed = &sessionTTLLastUsed
 		}
 	}
+	if jsnCfg.Session_ttl_policy != nil {
+		self.SessionTTLPolicy = *jsnCfg.Session_ttl_policy
+	}
 	if jsnCfg.Session_indexes != nil {
 		self.SessionIndexes = utils.StringMapFromSlice(*jsnCfg.Session_indexes)
 	}
+	if jsnCfg.Dedup_keys != nil {
+		self.DedupKeys = *jsnCfg.Dedup_keys
+	}
+	if jsnCfg.Dedup_merge_policy != nil {
+		self.DedupMergePolicy = *jsnCfg.Dedup_merge_policy
+	}
+	if jsnCfg.Dedup_ttl != nil {
+		if self.DedupTTL, err = utils.ParseDurationWithSecs(*jsnCfg.Dedup_ttl); err != nil {
+			return err
+		}
+	}
+	if jsnCfg.Auth_hint_destinations != nil {
+		self.AuthHintDsts = *jsnCfg.Auth_hint_destinations
+	}
+	if jsnCfg.Interim_updates != nil {
+		self.InterimUpdates = make(map[string]*InterimUpdateCfg, len(*jsnCfg.Interim_updates))
+		for agent, jsnIuCfg := range *jsnCfg.Interim_updates {
+			self.InterimUpdates[agent] = new(InterimUpdateCfg)
+			if err = self.InterimUpdates[agent].loadFromJsonCfg(jsnIuCfg); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,43 @@
+--
+-- Table structure for table `cdrs`
+--
+-- Rows are versioned by updated_at, the latest version of the same CDR
+-- (tenant, setup day, cgrid, run_id, origin_id) replacing the older ones on merges.
+-- Retention is configured out of stor_db.cdrs_clickhouse.ttl.
+--
+
+DROP TABLE IF EXISTS cdrs;
+CREATE TABLE cdrs (
+  order_id Int64,
+  cgrid String,
+  run_id LowCardinality(String),
+  origin_host LowCardinality(String),
+  source LowCardinality(String),
+  origin_id String,
+  tor LowCardinality(String),
+  request_type LowCardinality(String),
+  direction LowCardinality(String),
+  tenant LowCardinality(String),
+  category LowCardinality(String),
+  account String,
+  subject String,
+  destination String,
+  setup_time DateTime64(9, 'UTC'),
+  pdd Float64,
+  answer_time DateTime64(9, 'UTC'),
+  `usage` Float64,
+  supplier LowCardinality(String),
+  disconnect_cause LowCardinality(String),
+  extra_fields Map(String, String),
+  cost_source LowCardinality(String),
+  cost Float64,
+  shadow_cost Float64,
+  cost_details String CODEC(ZSTD),
+  account_summary String CODEC(ZSTD),
+  extra_info String,
+  created_at DateTime64(9, 'UTC'),
+  updated_at DateTime64(9, 'UTC'),
+  INDEX idx_account account TYPE bloom_filter GRANULARITY 4
+) ENGINE = ReplacingMergeTree(updated_at)
+PARTITION BY toYYYYMM(setup_time)
+ORDER BY (tenant, toDate(setup_time), cgrid, run_id, origin_id);
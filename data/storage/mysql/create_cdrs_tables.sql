@@ -27,6 +27,7 @@ CREATE TABLE cdrs (
   extra_fields text NOT NULL,
   cost_source varchar(64) NOT NULL,
   cost DECIMAL(20,4) NOT NULL,
+  shadow_cost DECIMAL(20,4) NOT NULL DEFAULT 0,
   cost_details text,
   account_summary text,
   extra_info text,
@@ -27,7 +27,8 @@ CREATE TABLE `tp_destinations` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `tpid` varchar(64) NOT NULL,
   `tag` varchar(64) NOT NULL,
-  `prefix` varchar(24) NOT NULL,
+  `prefix` varchar(64) NOT NULL,
+  `type` varchar(8) NOT NULL,
   `created_at` TIMESTAMP,
   PRIMARY KEY (`id`),
   KEY `tpid` (`tpid`),
@@ -48,7 +49,7 @@ CREATE TABLE `tp_rates` (
   `rate` decimal(7,4) NOT NULL,
   `rate_unit` varchar(16) NOT NULL,
   `rate_increment` varchar(16) NOT NULL,
-  `group_interval_start` varchar(16) NOT NULL,
+  `group_interval_start` varchar(32) NOT NULL,
   `created_at` TIMESTAMP,
   PRIMARY KEY (`id`),
   UNIQUE KEY `unique_tprate` (`tpid`,`tag`,`group_interval_start`),
@@ -114,11 +115,15 @@ CREATE TABLE `tp_rating_profiles` (
   `rating_plan_tag` varchar(64) NOT NULL,
   `fallback_subjects` varchar(64),
   `cdr_stat_queue_ids` varchar(64),
+  `deactivation_time` varchar(24),
+  `max_cost` decimal(7,4),
+  `max_cost_strategy` varchar(16),
+  `traffic_share` decimal(5,2),
   `created_at` TIMESTAMP,
   PRIMARY KEY (`id`),
    KEY `tpid` (`tpid`),
   KEY `tpid_loadid` (`tpid`, `loadid`),
-  UNIQUE KEY `tpid_loadid_tenant_category_dir_subj_atime` (`tpid`,`loadid`, `tenant`,`category`,`direction`,`subject`,`activation_time`)
+  UNIQUE KEY `tpid_loadid_tenant_category_dir_subj_atime` (`tpid`,`loadid`, `tenant`,`category`,`direction`,`subject`,`activation_time`,`rating_plan_tag`)
 );
 
 --
@@ -411,6 +416,61 @@ CREATE TABLE tp_resource_limits (
   UNIQUE KEY `unique_tp_resource_limits` (`tpid`, `tag`, `filter_type`, `filter_field_name`)
 );
 
+--
+-- Table structure for table `tp_exchange_rates`
+--
+
+DROP TABLE IF EXISTS tp_exchange_rates;
+CREATE TABLE tp_exchange_rates (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `tpid` varchar(64) NOT NULL,
+  `from_currency` varchar(3) NOT NULL,
+  `to_currency` varchar(3) NOT NULL,
+  `rate` DECIMAL(10,6) NOT NULL,
+  `created_at` TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `tpid` (`tpid`),
+  UNIQUE KEY `unique_tp_exchange_rates` (`tpid`, `from_currency`, `to_currency`)
+);
+
+--
+-- Table structure for table `tp_holiday_calendars`
+--
+
+DROP TABLE IF EXISTS tp_holiday_calendars;
+CREATE TABLE tp_holiday_calendars (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `tpid` varchar(64) NOT NULL,
+  `tag` varchar(64) NOT NULL,
+  `date` varchar(10) NOT NULL,
+  `week_day` varchar(1) NOT NULL,
+  `created_at` TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `tpid` (`tpid`),
+  UNIQUE KEY `unique_tp_holiday_calendars` (`tpid`, `tag`, `date`)
+);
+
+--
+-- Table structure for table `tp_dial_plans`
+--
+
+DROP TABLE IF EXISTS tp_dial_plans;
+CREATE TABLE tp_dial_plans (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `tpid` varchar(64) NOT NULL,
+  `tenant` varchar(64) NOT NULL,
+  `prefix` varchar(24) NOT NULL,
+  `min_length` int(11) NOT NULL,
+  `max_length` int(11) NOT NULL,
+  `strip` int(11) NOT NULL,
+  `add` varchar(24) NOT NULL,
+  `weight` DECIMAL(8,2) NOT NULL,
+  `created_at` TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `tpid` (`tpid`),
+  UNIQUE KEY `unique_tp_dial_plans` (`tpid`, `tenant`, `prefix`, `min_length`, `max_length`)
+);
+
 DROP TABLE IF EXISTS versions;
 CREATE TABLE versions (
   `id` int(11) NOT NULL AUTO_INCREMENT,
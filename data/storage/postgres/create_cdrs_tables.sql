@@ -27,6 +27,7 @@ CREATE TABLE cdrs (
  extra_fields jsonb NOT NULL,
  cost_source VARCHAR(64) NOT NULL,
  cost NUMERIC(20,4) DEFAULT NULL,
+ shadow_cost NUMERIC(20,4) DEFAULT NULL,
  cost_details jsonb,
  account_summary jsonb,
  extra_info text,
@@ -26,7 +26,8 @@ CREATE TABLE tp_destinations (
   id SERIAL PRIMARY KEY,
   tpid VARCHAR(64) NOT NULL,
   tag VARCHAR(64) NOT NULL,
-  prefix VARCHAR(24) NOT NULL,
+  prefix VARCHAR(64) NOT NULL,
+  type VARCHAR(8) NOT NULL,
   created_at TIMESTAMP WITH TIME ZONE,
   UNIQUE (tpid, tag, prefix)
 );
@@ -46,7 +47,7 @@ CREATE TABLE tp_rates (
   rate NUMERIC(7,4) NOT NULL,
   rate_unit VARCHAR(16) NOT NULL,
   rate_increment VARCHAR(16) NOT NULL,
-  group_interval_start VARCHAR(16) NOT NULL,
+  group_interval_start VARCHAR(32) NOT NULL,
   created_at TIMESTAMP WITH TIME ZONE,
   UNIQUE (tpid, tag, group_interval_start)
 );
@@ -110,8 +111,12 @@ CREATE TABLE tp_rating_profiles (
   rating_plan_tag VARCHAR(64) NOT NULL,
   fallback_subjects VARCHAR(64),
   cdr_stat_queue_ids VARCHAR(64),
+  deactivation_time VARCHAR(24),
+  max_cost NUMERIC(7,4),
+  max_cost_strategy VARCHAR(16),
+  traffic_share NUMERIC(5,2),
   created_at TIMESTAMP WITH TIME ZONE,
-  UNIQUE (tpid, loadid, tenant, category, direction, subject, activation_time)
+  UNIQUE (tpid, loadid, tenant, category, direction, subject, activation_time, rating_plan_tag)
 );
 CREATE INDEX tpratingprofiles_tpid_idx ON tp_rating_profiles (tpid);
 CREATE INDEX tpratingprofiles_idx ON tp_rating_profiles (tpid,loadid,direction,tenant,category,subject);
@@ -405,6 +410,58 @@ CREATE TABLE tp_resource_limits (
 CREATE INDEX tp_resource_limits_idx ON tp_resource_limits (tpid);
 CREATE INDEX tp_resource_limits_unique ON tp_resource_limits  ("tpid", "tag", "filter_type", "filter_field_name");
 
+--
+-- Table structure for table `tp_exchange_rates`
+--
+
+DROP TABLE IF EXISTS tp_exchange_rates;
+CREATE TABLE tp_exchange_rates (
+  "id" SERIAL PRIMARY KEY,
+  "tpid" varchar(64) NOT NULL,
+  "from_currency" varchar(3) NOT NULL,
+  "to_currency" varchar(3) NOT NULL,
+  "rate" NUMERIC(10,6) NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX tp_exchange_rates_tpid ON tp_exchange_rates (tpid);
+CREATE UNIQUE INDEX tp_exchange_rates_unique ON tp_exchange_rates ("tpid", "from_currency", "to_currency");
+
+--
+-- Table structure for table `tp_holiday_calendars`
+--
+
+DROP TABLE IF EXISTS tp_holiday_calendars;
+CREATE TABLE tp_holiday_calendars (
+  "id" SERIAL PRIMARY KEY,
+  "tpid" varchar(64) NOT NULL,
+  "tag" varchar(64) NOT NULL,
+  "date" varchar(10) NOT NULL,
+  "week_day" varchar(1) NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX tp_holiday_calendars_tpid ON tp_holiday_calendars (tpid);
+CREATE UNIQUE INDEX tp_holiday_calendars_unique ON tp_holiday_calendars ("tpid", "tag", "date");
+
+--
+-- Table structure for table `tp_dial_plans`
+--
+
+DROP TABLE IF EXISTS tp_dial_plans;
+CREATE TABLE tp_dial_plans (
+  "id" SERIAL PRIMARY KEY,
+  "tpid" varchar(64) NOT NULL,
+  "tenant" varchar(64) NOT NULL,
+  "prefix" varchar(24) NOT NULL,
+  "min_length" INTEGER NOT NULL,
+  "max_length" INTEGER NOT NULL,
+  "strip" INTEGER NOT NULL,
+  "add" varchar(24) NOT NULL,
+  "weight" NUMERIC(8,2) NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX tp_dial_plans_tpid ON tp_dial_plans (tpid);
+CREATE UNIQUE INDEX tp_dial_plans_unique ON tp_dial_plans ("tpid", "tenant", "prefix", "min_length", "max_length");
+
 DROP TABLE IF EXISTS versions;
 CREATE TABLE versions (
   "id" SERIAL PRIMARY KEY,
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -40,9 +41,67 @@ type Account struct {
 	ActionTriggers    ActionTriggers
 	AllowNegative     bool
 	Disabled          bool
+	TierUsage         map[string]*TierUsage // usage counted for tiered ratings, indexed on tier key
+	ParentID          string                // account the monetary debits fall through to once the own balances are exhausted
+	ParentCap         float64               // monetary value allowed out of the parent balances, 0 for unlimited
+	ParentUsed        float64               // monetary value debited out of the parent balances since the parent was set
 	executingTriggers bool
 }
 
+// TierUsage is the usage counted within one billing period of a tiered rating
+type TierUsage struct {
+	PeriodStart time.Time
+	Usage       time.Duration
+}
+
+// tierPeriodStart returns the start of the billing period containing t
+func tierPeriodStart(period string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch period {
+	case utils.MetaDaily:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	case utils.MetaWeekly:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location()) // weeks start on Monday
+	case utils.MetaMonthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case utils.MetaYearly:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// tierUsageAt returns the usage counted in the billing periods containing t
+func (acc *Account) tierUsageAt(t time.Time) map[string]time.Duration {
+	usage := make(map[string]time.Duration, len(acc.TierUsage))
+	for key, tu := range acc.TierUsage {
+		period := strings.SplitN(key, utils.CONCATENATED_KEY_SEP, 2)[0]
+		if tierPeriodStart(period, t).Equal(tu.PeriodStart) {
+			usage[key] = tu.Usage
+		}
+	}
+	return usage
+}
+
+// countTierUsage adds the usage of timespans rated with tiered ratings
+func (acc *Account) countTierUsage(tss TimeSpans) {
+	for _, ts := range tss {
+		if ts.RateInterval == nil || ts.RateInterval.Rating == nil || ts.RateInterval.Rating.TierPeriod == "" {
+			continue
+		}
+		key := ts.RateInterval.Rating.tierKey()
+		periodStart := tierPeriodStart(ts.RateInterval.Rating.TierPeriod, ts.TimeStart)
+		if acc.TierUsage == nil {
+			acc.TierUsage = make(map[string]*TierUsage)
+		}
+		tu, has := acc.TierUsage[key]
+		if !has || !tu.PeriodStart.Equal(periodStart) {
+			tu = &TierUsage{PeriodStart: periodStart}
+			acc.TierUsage[key] = tu
+		}
+		tu.Usage += ts.GetDuration()
+	}
+}
+
 // User's available minutes for the specified destination
 func (ub *Account) getCreditForPrefix(cd *CallDescriptor) (duration time.Duration, credit float64, balances Balances) {
 	creditBalances := ub.getBalancesForPrefix(cd.Destination, cd.Category, cd.Direction, utils.MONETARY, "")
@@ -87,6 +146,205 @@ func (ub *Account) getCreditForPrefix(cd *CallDescriptor) (duration time.Duratio
 	return
 }
 
+// setQuotaAction sets a *generic balance restored to its value at the beginning of each period in ExtraParameters
+func (acc *Account) setQuotaAction(a *Action) error {
+	if a == nil || a.Balance == nil {
+		return errors.New("nil action")
+	}
+	if a.Balance.GetType() != utils.GENERIC {
+		return fmt.Errorf("quotas are supported only on %s balances", utils.GENERIC)
+	}
+	if a.Balance.GetID() == "" {
+		return errors.New("missing balance id")
+	}
+	now := time.Now()
+	periodStart, err := utils.GetPeriodStart(a.ExtraParameters, now)
+	if err != nil {
+		return err
+	}
+	if err := acc.setBalanceAction(a); err != nil {
+		return err
+	}
+	for _, b := range acc.BalanceMap[utils.GENERIC] {
+		if b.ID == a.Balance.GetID() && !b.IsExpired() {
+			b.QuotaValue = a.Balance.GetValue()
+			b.QuotaPeriod = a.ExtraParameters
+			b.QuotaReset = periodStart
+			break
+		}
+	}
+	return nil
+}
+
+// setDripAction sets a balance granted its value over each cycle in ExtraParameters, released in equal steps
+func (acc *Account) setDripAction(a *Action) error {
+	if a == nil || a.Balance == nil {
+		return errors.New("nil action")
+	}
+	if a.Balance.GetID() == "" {
+		return errors.New("missing balance id")
+	}
+	var drip struct {
+		Cycle string
+		Step  string
+	}
+	if err := json.Unmarshal([]byte(a.ExtraParameters), &drip); err != nil {
+		return err
+	}
+	now := time.Now()
+	cycleStart, err := utils.GetPeriodStart(drip.Cycle, now)
+	if err != nil {
+		return err
+	}
+	if _, total, err := dripSteps(cycleStart, drip.Cycle, drip.Step, now); err != nil {
+		return err
+	} else if total < 2 {
+		return fmt.Errorf("drip step %s not shorter than the cycle %s", drip.Step, drip.Cycle)
+	}
+	if err := acc.setBalanceAction(a); err != nil {
+		return err
+	}
+	for _, b := range acc.BalanceMap[a.Balance.GetType()] {
+		if b.ID == a.Balance.GetID() && !b.IsExpired() {
+			b.DripValue = b.Value
+			b.DripCycle = drip.Cycle
+			b.DripStep = drip.Step
+			b.DripReleased = time.Time{}
+			b.releaseDrip(now)
+			break
+		}
+	}
+	return nil
+}
+
+// setTORQuotasAction sets the per ToR priorities and reservations defined in ExtraParameters on the balance with the action's balance ID
+func (acc *Account) setTORQuotasAction(a *Action) error {
+	if a == nil || a.Balance == nil {
+		return errors.New("nil action")
+	}
+	if a.Balance.GetID() == "" {
+		return errors.New("missing balance id")
+	}
+	var torQuotas struct {
+		Priorities   map[string]float64
+		Reservations map[string]float64
+	}
+	if err := json.Unmarshal([]byte(a.ExtraParameters), &torQuotas); err != nil {
+		return err
+	}
+	for _, balances := range acc.BalanceMap {
+		for _, b := range balances {
+			if b.ID == a.Balance.GetID() && !b.IsExpired() {
+				b.TORPriorities = torQuotas.Priorities
+				b.TORReservations = torQuotas.Reservations
+				return nil
+			}
+		}
+	}
+	return utils.ErrNotFound
+}
+
+// convertBalanceAction moves the action Value out of the balances matching its filter into the balance defined
+// in ExtraParameters, eg: {"BalanceType":"*monetary","BalanceID":"MAIN","Rate":0.01}, credited with Value*Rate;
+// the account is left untouched if the value is not fully available. Returns the credit done, for logging
+func (acc *Account) convertBalanceAction(a *Action) (*Action, error) {
+	if a == nil || a.Balance == nil || a.Balance.GetType() == "" {
+		return nil, errors.New("missing balance type")
+	}
+	value := a.Balance.GetValue()
+	if value <= 0 {
+		return nil, fmt.Errorf("invalid value to convert: %v", value)
+	}
+	var conv struct {
+		BalanceType string
+		BalanceID   string
+		Rate        float64
+	}
+	if err := json.Unmarshal([]byte(a.ExtraParameters), &conv); err != nil {
+		return nil, err
+	}
+	if conv.BalanceType == "" || conv.Rate <= 0 {
+		return nil, fmt.Errorf("invalid conversion: %s", a.ExtraParameters)
+	}
+	if conv.BalanceID == "" && conv.BalanceType != utils.MONETARY {
+		return nil, errors.New("missing balance id to convert to")
+	}
+	var target *Balance
+	if conv.BalanceID == "" {
+		target = acc.GetDefaultMoneyBalance()
+	} else {
+		for _, b := range acc.BalanceMap[conv.BalanceType] {
+			if b.ID == conv.BalanceID && !b.IsExpired() {
+				target = b
+				break
+			}
+		}
+	}
+	var sources Balances
+	var available float64
+	for _, b := range acc.BalanceMap[a.Balance.GetType()] {
+		if b != target && !b.IsExpired() && !b.Disabled && b.GetValue() > 0 && b.MatchFilter(a.Balance, false) {
+			sources = append(sources, b)
+			available += b.GetValue()
+		}
+	}
+	if utils.Round(available, globalRoundingDecimals, utils.ROUNDING_MIDDLE) < value {
+		return nil, fmt.Errorf("insufficient %s to convert: %v out of %v", a.Balance.GetType(), available, value)
+	}
+	if target == nil {
+		if acc.BalanceMap == nil {
+			acc.BalanceMap = make(map[string]Balances)
+		}
+		target = &Balance{Uuid: utils.GenUUID(), ID: conv.BalanceID}
+		acc.BalanceMap[conv.BalanceType] = append(acc.BalanceMap[conv.BalanceType], target)
+	}
+	sources.Sort()
+	remaining := value
+	for _, b := range sources {
+		debit := math.Min(b.GetValue(), remaining)
+		b.SubstractValue(debit)
+		if remaining -= debit; remaining <= 0 {
+			break
+		}
+	}
+	credit := utils.Round(value*conv.Rate, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	target.AddValue(credit)
+	a.balanceValue = sources.GetTotalValue()
+	return &Action{Id: a.Id, ActionType: a.ActionType, ExtraParameters: a.ExtraParameters,
+		Balance: &BalanceFilter{Uuid: utils.StringPointer(target.Uuid), ID: utils.StringPointer(target.ID),
+			Type: utils.StringPointer(conv.BalanceType), Value: &utils.ValueFormula{Static: credit}},
+		balanceValue: target.GetValue()}, nil
+}
+
+// setCurrencyAction sets the currency in ExtraParameters on the *monetary balance with the action BalanceId,
+// on all of them if no BalanceId is given; an empty currency restores the default one
+func (acc *Account) setCurrencyAction(a *Action) error {
+	if a == nil {
+		return errors.New("nil action")
+	}
+	if a.ExtraParameters != "" && !utils.IsCurrencyCode(a.ExtraParameters) {
+		return fmt.Errorf("invalid currency: %s", a.ExtraParameters)
+	}
+	var balanceID string
+	if a.Balance != nil {
+		balanceID = a.Balance.GetID()
+	}
+	if balanceID == "" && len(acc.BalanceMap[utils.MONETARY]) == 0 {
+		acc.GetDefaultMoneyBalance()
+	}
+	found := false
+	for _, b := range acc.BalanceMap[utils.MONETARY] {
+		if balanceID == "" || b.ID == balanceID {
+			b.Currency = a.ExtraParameters
+			found = true
+		}
+	}
+	if !found {
+		return utils.ErrNotFound
+	}
+	return nil
+}
+
 // sets all the fields of the balance
 func (acc *Account) setBalanceAction(a *Action) error {
 	if a == nil {
@@ -143,12 +401,16 @@ func (acc *Account) setBalanceAction(a *Action) error {
 	if a.Balance.ID != nil && *a.Balance.ID == utils.META_DEFAULT { // treat it separately since modifyBalance sets expiry and others parameters, not specific for *default
 		if a.Balance.Value != nil {
 			balance.ID = *a.Balance.ID
+			balance.valueDelta += a.Balance.GetValue() - balance.Value
 			balance.Value = a.Balance.GetValue()
 			balance.SetDirty() // Mark the balance as dirty since we have modified and it should be checked by action triggers
 		}
 	} else {
 		a.Balance.ModifyBalance(balance)
 	}
+	if !found {
+		scheduleExpiryNotifications(acc.ID, balance)
+	}
 	// modify if necessary the shared groups here
 	if !found || !previousSharedGroups.Equal(balance.SharedGroups) {
 		_, err := guardian.Guardian.Guard(func() (interface{}, error) {
@@ -230,6 +492,8 @@ func (ub *Account) debitBalanceAction(a *Action, reset bool) error {
 			}
 		}
 		bClone.dirty = true // Mark the balance as dirty since we have modified and it should be checked by action triggers
+		// new balance, the whole value is a change
+		bClone.valueDelta = bClone.GetValue()
 		a.balanceValue = bClone.GetValue()
 		bClone.Uuid = utils.GenUUID() // alway overwrite the uuid for consistency
 		// load ValueFactor if defined in extra parametrs
@@ -243,6 +507,7 @@ func (ub *Account) debitBalanceAction(a *Action, reset bool) error {
 			}
 		}
 		ub.BalanceMap[balanceType] = append(ub.BalanceMap[balanceType], bClone)
+		scheduleExpiryNotifications(ub.ID, bClone)
 		_, err := guardian.Guardian.Guard(func() (interface{}, error) {
 			sgs := make([]string, len(bClone.SharedGroups))
 			i := 0
@@ -283,8 +548,10 @@ func (ub *Account) getBalancesForPrefix(prefix, category, direction, tor string,
 		balances = append(balances, ub.BalanceMap[utils.GENERIC]...)
 	}
 	var usefulBalances Balances
+	now := time.Now()
 	for _, b := range balances {
-
+		b.resetQuota(now)
+		b.releaseDrip(now)
 		if b.Disabled {
 			continue
 		}
@@ -303,32 +570,30 @@ func (ub *Account) getBalancesForPrefix(prefix, category, direction, tor string,
 		b.account = ub
 
 		if len(b.DestinationIDs) > 0 && b.DestinationIDs[utils.ANY] == false {
-			for _, p := range utils.SplitPrefix(prefix, MIN_PREFIX_MATCH) {
-				if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-					foundResult := false
-					allInclude := true // whether it is excluded or included
-					for _, dId := range destIDs {
-						inclDest, found := b.DestinationIDs[dId]
-						if found {
-							foundResult = true
-							allInclude = allInclude && inclDest
-						}
+			for _, dm := range matchReverseDestinations(dataStorage, prefix) {
+				foundResult := false
+				allInclude := true // whether it is excluded or included
+				for _, dId := range dm.DestIDs {
+					inclDest, found := b.DestinationIDs[dId]
+					if found {
+						foundResult = true
+						allInclude = allInclude && inclDest
 					}
-					// check wheter all destination ids in the balance were exclusions
-					allExclude := true
-					for _, inclDest := range b.DestinationIDs {
-						if inclDest {
-							allExclude = false
-							break
-						}
+				}
+				// check wheter all destination ids in the balance were exclusions
+				allExclude := true
+				for _, inclDest := range b.DestinationIDs {
+					if inclDest {
+						allExclude = false
+						break
 					}
-					if foundResult || allExclude {
-						if allInclude {
-							b.precision = len(p)
-							usefulBalances = append(usefulBalances, b)
-						} else {
-							b.precision = 1 // fake to exit the outer loop
-						}
+				}
+				if foundResult || allExclude {
+					if allInclude {
+						b.precision = len(dm.Prefix)
+						usefulBalances = append(usefulBalances, b)
+					} else {
+						b.precision = 1 // fake to exit the outer loop
 					}
 				}
 				if b.precision > 0 {
@@ -339,11 +604,18 @@ func (ub *Account) getBalancesForPrefix(prefix, category, direction, tor string,
 			usefulBalances = append(usefulBalances, b)
 		}
 	}
+	// apply the ToR specific priorities
+	for _, b := range usefulBalances {
+		if w, has := b.TORPriorities[tor]; has {
+			b.torWeight = utils.Float64Pointer(w)
+		}
+	}
 	// resort by precision
 	usefulBalances.Sort()
 	// clear precision
 	for _, b := range usefulBalances {
 		b.precision = 0
+		b.torWeight = nil
 	}
 	return usefulBalances
 }
@@ -373,6 +645,8 @@ func (account *Account) getAlldBalancesForPrefix(destination, category, directio
 func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun bool, goNegative bool) (cc *CallCost, err error) {
 	usefulUnitBalances := ub.getAlldBalancesForPrefix(cd.Destination, cd.Category, cd.Direction, cd.TOR)
 	usefulMoneyBalances := ub.getAlldBalancesForPrefix(cd.Destination, cd.Category, cd.Direction, utils.MONETARY)
+	parentBalances := ub.getParentBalances(cd.Destination, cd.Category, cd.Direction)
+	usefulMoneyBalances = append(usefulMoneyBalances, parentBalances...)
 	//utils.Logger.Info(fmt.Sprintf("%+v, %+v", usefulMoneyBalances, usefulUnitBalances))
 	//utils.Logger.Info(fmt.Sprintf("STARTCD: %+v", cd))
 	//log.Printf("%+v, %+v", usefulMoneyBalances, usefulUnitBalances)
@@ -499,7 +773,9 @@ func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun boo
 
 		if initialLength == 0 {
 			// this is the first add, debit the connect fee
-			ok, debitedConnectFeeBalance = ub.DebitConnectionFee(cc, usefulMoneyBalances, count, true)
+			if ok, debitedConnectFeeBalance, err = ub.DebitConnectionFee(cc, usefulMoneyBalances, count, true); err != nil {
+				return nil, err
+			}
 		}
 		//log.Printf("Left CC: %+v ", leftCC)
 		// get the default money balanance
@@ -514,15 +790,16 @@ func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun boo
 			}
 
 			if tsIndex == 0 && ts.RateInterval.Rating.ConnectFee > 0 && cc.deductConnectFee && ok {
-
+				_, cfExRate, _ := debitedConnectFeeBalance.convertCost(ts.RateInterval.Rating.ConnectFee, ts.RateInterval.Rating.Currency)
 				inc := &Increment{
 					Duration: 0,
 					Cost:     ts.RateInterval.Rating.ConnectFee,
 					BalanceInfo: &DebitInfo{
 						Monetary: &MonetaryInfo{
-							UUID:  debitedConnectFeeBalance.Uuid,
-							ID:    debitedConnectFeeBalance.ID,
-							Value: debitedConnectFeeBalance.Value,
+							UUID:         debitedConnectFeeBalance.Uuid,
+							ID:           debitedConnectFeeBalance.ID,
+							Value:        debitedConnectFeeBalance.Value,
+							ExchangeRate: cfExRate,
 						},
 						AccountID: ub.ID,
 					},
@@ -539,13 +816,17 @@ func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun boo
 					continue
 				}
 
-				cost := increment.Cost
 				defaultBalance := ub.GetDefaultMoneyBalance()
+				cost, exRate, errConv := defaultBalance.convertCost(increment.Cost, ts.RateInterval.Rating.Currency)
+				if errConv != nil { // never debit the cost in a currency the balance does not hold
+					return nil, errConv
+				}
 				defaultBalance.SubstractValue(cost)
 				increment.BalanceInfo.Monetary = &MonetaryInfo{
-					UUID:  defaultBalance.Uuid,
-					ID:    defaultBalance.ID,
-					Value: defaultBalance.Value,
+					UUID:         defaultBalance.Uuid,
+					ID:           defaultBalance.ID,
+					Value:        defaultBalance.Value,
+					ExchangeRate: exRate,
 				}
 				increment.BalanceInfo.AccountID = ub.ID
 				increment.paid = true
@@ -566,6 +847,7 @@ func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun boo
 
 COMMIT:
 	if !dryRun {
+		ub.settleParentBalances(parentBalances)
 		// save darty shared balances
 		usefulMoneyBalances.SaveDirtyBalances(ub)
 		usefulUnitBalances.SaveDirtyBalances(ub)
@@ -691,6 +973,35 @@ func (acc *Account) SetRecurrent(a *Action, recurrent bool) {
 	}
 }
 
+// Sets, increments and/or re-arms the thresholds of the matching action triggers
+// as described in the ExtraParameters of the action, eg: {"GroupID":"FRAUD","ThresholdDelta":50,"Rearm":true}
+func (acc *Account) SetTriggerThreshold(a *Action) error {
+	params := struct {
+		ThresholdValue *float64
+		ThresholdDelta float64
+		Rearm          bool
+	}{}
+	if err := json.Unmarshal([]byte(a.ExtraParameters), &params); err != nil {
+		return err
+	}
+	if params.ThresholdValue == nil && params.ThresholdDelta == 0 && !params.Rearm {
+		return utils.ErrMandatoryIeMissing
+	}
+	for _, at := range acc.ActionTriggers {
+		if !at.Match(a) {
+			continue
+		}
+		if params.ThresholdValue != nil {
+			at.ThresholdValue = *params.ThresholdValue
+		}
+		at.ThresholdValue += params.ThresholdDelta
+		if params.Rearm {
+			at.Executed = false
+		}
+	}
+	return nil
+}
+
 // Increments the counter for the type
 func (acc *Account) countUnits(amount float64, kind string, cc *CallCost, b *Balance) {
 	acc.UnitCounters.addUnits(amount, kind, cc, b)
@@ -813,6 +1124,9 @@ func (account *Account) GetUniqueSharedGroupMembers(cd *CallDescriptor) (utils.S
 			memberIds[memberID] = true
 		}
 	}
+	if account.ParentID != "" { // debited together with its members
+		memberIds[account.ParentID] = true
+	}
 	return memberIds, nil
 }
 
@@ -828,33 +1142,94 @@ func (acc *Account) Clone() *Account {
 		ActionTriggers: nil, // not used when cloned (dryRun)
 		AllowNegative:  acc.AllowNegative,
 		Disabled:       acc.Disabled,
+		ParentID:       acc.ParentID,
+		ParentCap:      acc.ParentCap,
+		ParentUsed:     acc.ParentUsed,
 	}
 	for key, balanceChain := range acc.BalanceMap {
 		newAcc.BalanceMap[key] = balanceChain.Clone()
 	}
+	if acc.TierUsage != nil {
+		newAcc.TierUsage = make(map[string]*TierUsage, len(acc.TierUsage))
+		for key, tu := range acc.TierUsage {
+			newAcc.TierUsage[key] = &TierUsage{PeriodStart: tu.PeriodStart, Usage: tu.Usage}
+		}
+	}
 	return newAcc
 }
 
-func (acc *Account) DebitConnectionFee(cc *CallCost, usefulMoneyBalances Balances, count bool, block bool) (bool, Balance) {
+// Merge moves the balances and triggers of src into acc.
+// Balances with the same ID and expiry are combined, the rest are appended, renamed on ID conflicts.
+func (acc *Account) Merge(src *Account) {
+	if acc.BalanceMap == nil {
+		acc.BalanceMap = make(map[string]Balances)
+	}
+	for balType, srcBalances := range src.BalanceMap {
+		for _, srcBal := range srcBalances {
+			var merged, idConflict bool
+			for _, b := range acc.BalanceMap[balType] {
+				if srcBal.ID == "" || b.ID != srcBal.ID {
+					continue
+				}
+				if !b.ExpirationDate.Equal(srcBal.ExpirationDate) {
+					idConflict = true
+					continue
+				}
+				b.AddValue(srcBal.GetValue())
+				merged = true
+				break
+			}
+			if merged {
+				continue
+			}
+			nb := srcBal.Clone()
+			nb.Uuid = utils.GenUUID()
+			if idConflict {
+				nb.ID = utils.ConcatenatedKey(nb.ID, src.ID)
+			}
+			acc.BalanceMap[balType] = append(acc.BalanceMap[balType], nb)
+		}
+	}
+	for _, srcAT := range src.ActionTriggers {
+		var exists bool
+		for _, at := range acc.ActionTriggers {
+			if at.UniqueID == srcAT.UniqueID {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			acc.ActionTriggers = append(acc.ActionTriggers, srcAT.Clone())
+		}
+	}
+	acc.InitCounters()
+}
+
+func (acc *Account) DebitConnectionFee(cc *CallCost, usefulMoneyBalances Balances, count bool, block bool) (bool, Balance, error) {
 	var debitedBalance Balance
 
 	if cc.deductConnectFee {
 		connectFee := cc.GetConnectFee()
+		currency := cc.GetCurrency()
 		//log.Print("CONNECT FEE: %f", connectFee)
 		connectFeePaid := false
 		for _, b := range usefulMoneyBalances {
-			if b.GetValue() >= connectFee {
-				b.SubstractValue(connectFee)
+			fee, _, err := b.convertCost(connectFee, currency)
+			if err != nil { // no exchange rate towards the balance currency
+				continue
+			}
+			if b.GetValue() >= fee {
+				b.SubstractValue(fee)
 				// the conect fee is not refundable!
 				if count {
-					acc.countUnits(connectFee, utils.MONETARY, cc, b)
+					acc.countUnits(fee, utils.MONETARY, cc, b)
 				}
 				connectFeePaid = true
 				debitedBalance = *b
 				break
 			}
 			if b.Blocker && block { // stop here
-				return false, debitedBalance
+				return false, debitedBalance, nil
 			}
 		}
 		// debit connect fee
@@ -862,15 +1237,19 @@ func (acc *Account) DebitConnectionFee(cc *CallCost, usefulMoneyBalances Balance
 			cc.negativeConnectFee = true
 			// there are no money for the connect fee; go negative
 			b := acc.GetDefaultMoneyBalance()
-			b.SubstractValue(connectFee)
+			fee, _, err := b.convertCost(connectFee, currency)
+			if err != nil {
+				return false, debitedBalance, err
+			}
+			b.SubstractValue(fee)
 			debitedBalance = *b
 			// the conect fee is not refundable!
 			if count {
-				acc.countUnits(connectFee, utils.MONETARY, cc, b)
+				acc.countUnits(fee, utils.MONETARY, cc, b)
 			}
 		}
 	}
-	return true, debitedBalance
+	return true, debitedBalance, nil
 }
 
 func (acc *Account) matchActionFilter(condition string) (bool, error) {
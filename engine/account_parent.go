@@ -0,0 +1,91 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// SetParent attaches the account to the parent in ExtraParameters, eg: {"Account":"master","Cap":50},
+// its monetary debits falling through to the parent balances for up to Cap (0 for unlimited).
+// Setting it again restarts counting the cap, an empty Account detaches it.
+func (acc *Account) SetParent(a *Action) error {
+	params := struct {
+		Account string
+		Cap     float64
+	}{}
+	if err := json.Unmarshal([]byte(a.ExtraParameters), &params); err != nil {
+		return err
+	}
+	acc.ParentID, acc.ParentCap, acc.ParentUsed = "", 0, 0
+	if params.Account == "" {
+		return nil
+	}
+	parentID := params.Account
+	if !strings.Contains(parentID, utils.CONCATENATED_KEY_SEP) { // same tenant
+		parentID = utils.ConcatenatedKey(strings.Split(acc.ID, utils.CONCATENATED_KEY_SEP)[0], parentID)
+	}
+	if parentID == acc.ID {
+		return utils.ErrInvalidParent
+	}
+	if _, err := dataStorage.GetAccount(parentID); err != nil {
+		return err
+	}
+	acc.ParentID = parentID
+	acc.ParentCap = params.Cap
+	return nil
+}
+
+// getParentBalances returns the monetary balances of the parent account, holding back out of their value
+// what exceeds the cap left to this account
+func (acc *Account) getParentBalances(destination, category, direction string) Balances {
+	if acc.ParentID == "" {
+		return nil
+	}
+	parent, err := dataStorage.GetAccount(acc.ParentID)
+	if err != nil || parent.Disabled {
+		return nil
+	}
+	balances := parent.getBalancesForPrefix(destination, category, direction, utils.MONETARY, "")
+	capLeft := math.Inf(1)
+	if acc.ParentCap > 0 {
+		capLeft = math.Max(acc.ParentCap-acc.ParentUsed, 0)
+	}
+	for _, b := range balances {
+		if b.Value > capLeft {
+			b.heldValue = b.Value - capLeft
+			b.Value = capLeft
+		}
+		b.childValue = b.Value
+		capLeft -= math.Max(b.Value, 0)
+	}
+	return balances
+}
+
+// settleParentBalances counts what was debited out of the parent balances and restores their held back value
+func (acc *Account) settleParentBalances(parentBalances Balances) {
+	for _, b := range parentBalances {
+		acc.ParentUsed += b.childValue - b.Value
+		b.Value += b.heldValue
+		b.heldValue = 0
+	}
+}
@@ -18,6 +18,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -786,6 +787,70 @@ func TestDebitCreditSubjectMinutes(t *testing.T) {
 	}
 }
 
+func TestDebitCreditSubjectMinutesExchangeRate(t *testing.T) {
+	if err := dataStorage.SetExchangeRate(&ExchangeRate{ID: utils.ConcatenatedKey("USD", "EUR"), FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.8}, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	newCD := func(currency string) *CallDescriptor {
+		cc := &CallCost{
+			Tenant:      "vdf",
+			Category:    "0",
+			Direction:   utils.OUT,
+			Destination: "0723045326",
+			Timespans: []*TimeSpan{
+				&TimeSpan{
+					TimeStart:     time.Date(2013, 9, 24, 10, 48, 0, 0, time.UTC),
+					TimeEnd:       time.Date(2013, 9, 24, 10, 49, 10, 0, time.UTC),
+					DurationIndex: 0,
+					RateInterval:  &RateInterval{Rating: &RIRate{Currency: currency, Rates: RateGroups{&Rate{GroupIntervalStart: 0, Value: 1, RateIncrement: 10 * time.Second, RateUnit: time.Second}}}},
+				},
+			},
+			TOR:              utils.VOICE,
+			deductConnectFee: true,
+		}
+		return &CallDescriptor{
+			Tenant:        cc.Tenant,
+			Category:      "0",
+			TimeStart:     cc.Timespans[0].TimeStart,
+			TimeEnd:       cc.Timespans[0].TimeEnd,
+			Direction:     cc.Direction,
+			Destination:   cc.Destination,
+			TOR:           cc.TOR,
+			DurationIndex: cc.GetDuration(),
+			testCallcost:  cc,
+		}
+	}
+	newAccount := func() *Account {
+		return &Account{ID: "other", BalanceMap: map[string]Balances{
+			utils.VOICE:    Balances{&Balance{Uuid: "testb", Categories: utils.NewStringMap("0"), Value: 250, Weight: 10, DestinationIDs: utils.StringMap{"NAT": true}, RatingSubject: "minu"}},
+			utils.MONETARY: Balances{&Balance{Uuid: "moneya", ID: utils.META_DEFAULT, Value: 350, Currency: "EUR"}},
+		}}
+	}
+	acc := newAccount()
+	cc, err := acc.debitCreditBalance(newCD("USD"), false, false, true)
+	if err != nil {
+		t.Fatal("Error debiting balance: ", err)
+	}
+	if cc.Timespans[0].Increments[0].BalanceInfo.Monetary.UUID != "moneya" ||
+		cc.Timespans[0].Increments[0].BalanceInfo.Monetary.ExchangeRate != 0.8 {
+		t.Errorf("Error setting exchange rate to increment: %+v", cc.Timespans[0].Increments[0].BalanceInfo.Monetary)
+	}
+	if acc.BalanceMap[utils.VOICE][0].GetValue() != 180 ||
+		acc.BalanceMap[utils.MONETARY][0].GetValue() != 294 {
+		t.Errorf("Error converting the money debited with the minutes: %+v, %+v",
+			acc.BalanceMap[utils.VOICE][0].GetValue(), acc.BalanceMap[utils.MONETARY][0].GetValue())
+	}
+	acc = newAccount()
+	cc = newCD("GBP").testCallcost
+	cc.Timespans[0].RateInterval.Rating.ConnectFee = 400
+	if _, _, err := acc.DebitConnectionFee(cc, acc.BalanceMap[utils.MONETARY], false, true); err == nil {
+		t.Error("Expecting error for missing exchange rate")
+	}
+	if acc.BalanceMap[utils.MONETARY][0].GetValue() != 350 {
+		t.Errorf("Unconverted connect fee debited: %+v", acc.BalanceMap[utils.MONETARY][0].GetValue())
+	}
+}
+
 func TestDebitCreditSubjectMoney(t *testing.T) {
 	cc := &CallCost{
 		Tenant:      "vdf",
@@ -1921,6 +1986,97 @@ func TestAccountAsAccountDigest(t *testing.T) {
 	}
 }
 
+func TestAccountSetQuota(t *testing.T) {
+	acnt := &Account{ID: "cgrates.org:quota1"}
+	a := &Action{ActionType: SET_QUOTA, ExtraParameters: utils.MetaMonthly,
+		Balance: &BalanceFilter{ID: utils.StringPointer("API_CALLS"), Type: utils.StringPointer(utils.GENERIC),
+			Value: &utils.ValueFormula{Static: 1000}}}
+	if err := acnt.setQuotaAction(a); err != nil {
+		t.Fatal(err)
+	}
+	if len(acnt.BalanceMap[utils.GENERIC]) != 1 {
+		t.Fatalf("Unexpected balances: %s", utils.ToJSON(acnt.BalanceMap))
+	}
+	b := acnt.BalanceMap[utils.GENERIC][0]
+	monthStart, _ := utils.GetPeriodStart(utils.MetaMonthly, time.Now())
+	if b.Value != 1000 || b.QuotaValue != 1000 || b.QuotaPeriod != utils.MetaMonthly || !b.QuotaReset.Equal(monthStart) {
+		t.Errorf("Unexpected balance: %s", utils.ToJSON(b))
+	}
+	b.Value = 3
+	acnt.getBalancesForPrefix("", "", utils.OUT, utils.GENERIC, "")
+	if b.Value != 3 {
+		t.Errorf("Quota reset within the same period, value: %v", b.Value)
+	}
+	b.QuotaReset = monthStart.AddDate(0, -1, 0)
+	if blcs := acnt.getBalancesForPrefix("", "", utils.OUT, utils.GENERIC, ""); len(blcs) != 1 {
+		t.Errorf("Unexpected balances: %s", utils.ToJSON(blcs))
+	}
+	if b.Value != 1000 || !b.QuotaReset.Equal(monthStart) {
+		t.Errorf("Quota not reset: %s", utils.ToJSON(b))
+	}
+	a.Balance.Type = utils.StringPointer(utils.MONETARY)
+	if err := acnt.setQuotaAction(a); err == nil {
+		t.Error("Expecting error on non generic balance")
+	}
+	a.Balance.Type = utils.StringPointer(utils.GENERIC)
+	a.ExtraParameters = "*fortnightly"
+	if err := acnt.setQuotaAction(a); err == nil {
+		t.Error("Expecting error on unsupported period")
+	}
+}
+
+func TestAccountSetDrip(t *testing.T) {
+	cycleStart := time.Date(2017, time.May, 1, 0, 0, 0, 0, time.UTC)
+	if started, total, err := dripSteps(cycleStart, utils.MetaMonthly, utils.MetaDaily, cycleStart.Add(225*time.Hour)); err != nil ||
+		started != 10 || total != 31 {
+		t.Error(started, total, err)
+	}
+	if started, total, err := dripSteps(cycleStart, utils.MetaMonthly, utils.MetaWeekly, cycleStart.Add(225*time.Hour)); err != nil ||
+		started != 2 || total != 5 {
+		t.Error(started, total, err)
+	}
+	acnt := &Account{ID: "cgrates.org:drip1"}
+	a := &Action{ActionType: SET_DRIP, ExtraParameters: `{"Cycle":"*monthly","Step":"*daily"}`,
+		Balance: &BalanceFilter{ID: utils.StringPointer("FAIR_USE"), Type: utils.StringPointer(utils.DATA),
+			Value: &utils.ValueFormula{Static: 3100}}}
+	if err := acnt.setDripAction(a); err != nil {
+		t.Fatal(err)
+	}
+	if len(acnt.BalanceMap[utils.DATA]) != 1 {
+		t.Fatalf("Unexpected balances: %s", utils.ToJSON(acnt.BalanceMap))
+	}
+	b := acnt.BalanceMap[utils.DATA][0]
+	now := time.Now()
+	monthStart, _ := utils.GetPeriodStart(utils.MetaMonthly, now)
+	started, total, _ := dripSteps(monthStart, utils.MetaMonthly, utils.MetaDaily, now)
+	dayValue := 3100 / float64(total)
+	if b.DripValue != 3100 || utils.Round(b.Value, 4, utils.ROUNDING_MIDDLE) != utils.Round(dayValue*float64(started), 4, utils.ROUNDING_MIDDLE) {
+		t.Errorf("Unexpected balance: %s", utils.ToJSON(b))
+	}
+	// only the days after the first one are released
+	b.Value = 1
+	b.DripReleased = monthStart
+	acnt.getBalancesForPrefix("", "", utils.OUT, utils.DATA, "")
+	if utils.Round(b.Value, 4, utils.ROUNDING_MIDDLE) != utils.Round(1+dayValue*float64(started-1), 4, utils.ROUNDING_MIDDLE) {
+		t.Errorf("Wrong released value: %v", b.Value)
+	}
+	// leftovers of the previous cycle are dropped
+	b.Value = 1000
+	b.DripReleased = monthStart.Add(-time.Hour)
+	acnt.getBalancesForPrefix("", "", utils.OUT, utils.DATA, "")
+	if utils.Round(b.Value, 4, utils.ROUNDING_MIDDLE) != utils.Round(dayValue*float64(started), 4, utils.ROUNDING_MIDDLE) {
+		t.Errorf("Wrong value on new cycle: %v", b.Value)
+	}
+	a.ExtraParameters = `{"Cycle":"*daily","Step":"*monthly"}`
+	if err := acnt.setDripAction(a); err == nil {
+		t.Error("Expecting error on step longer than the cycle")
+	}
+	a.ExtraParameters = `{"Cycle":"*monthly","Step":"*fortnightly"}`
+	if err := acnt.setDripAction(a); err == nil {
+		t.Error("Expecting error on unsupported step")
+	}
+}
+
 /*********************************** Benchmarks *******************************/
 
 func BenchmarkGetSecondForPrefix(b *testing.B) {
@@ -1959,3 +2115,260 @@ func BenchmarkGetSecondsForPrefix(b *testing.B) {
 		ub1.getCreditForPrefix(cd)
 	}
 }
+
+func TestAccountMerge(t *testing.T) {
+	expiry := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dst := &Account{ID: "cgrates.org:dst",
+		BalanceMap: map[string]Balances{
+			utils.MONETARY: Balances{&Balance{Uuid: "d1", ID: utils.META_DEFAULT, Value: 10}},
+			utils.VOICE:    Balances{&Balance{Uuid: "d2", ID: "minutes", Value: 60, ExpirationDate: expiry}}},
+		ActionTriggers: ActionTriggers{&ActionTrigger{ID: "T1", UniqueID: "t1", ThresholdType: utils.TRIGGER_MAX_BALANCE,
+			Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY)}}},
+	}
+	src := &Account{ID: "cgrates.org:src",
+		BalanceMap: map[string]Balances{
+			utils.MONETARY: Balances{&Balance{Uuid: "s1", ID: utils.META_DEFAULT, Value: 5}},
+			utils.VOICE:    Balances{&Balance{Uuid: "s2", ID: "minutes", Value: 30}},
+			utils.SMS:      Balances{&Balance{Uuid: "s3", ID: "sms", Value: 100}}},
+		ActionTriggers: ActionTriggers{
+			&ActionTrigger{ID: "T1", UniqueID: "t1", ThresholdType: utils.TRIGGER_MAX_BALANCE,
+				Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY)}},
+			&ActionTrigger{ID: "T2", UniqueID: "t2", ThresholdType: utils.TRIGGER_MIN_EVENT_COUNTER,
+				Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY)}}},
+	}
+	dst.Merge(src)
+	if len(dst.BalanceMap[utils.MONETARY]) != 1 || dst.BalanceMap[utils.MONETARY][0].GetValue() != 15 {
+		t.Errorf("Unexpected monetary balances: %s", utils.ToJSON(dst.BalanceMap[utils.MONETARY]))
+	}
+	if vBals := dst.BalanceMap[utils.VOICE]; len(vBals) != 2 ||
+		vBals[0].GetValue() != 60 || vBals[1].ID != "minutes:cgrates.org:src" || vBals[1].GetValue() != 30 {
+		t.Errorf("Unexpected voice balances: %s", utils.ToJSON(vBals))
+	}
+	if sBals := dst.BalanceMap[utils.SMS]; len(sBals) != 1 || sBals[0].Uuid == "s3" {
+		t.Errorf("Unexpected sms balances: %s", utils.ToJSON(sBals))
+	}
+	if len(dst.ActionTriggers) != 2 {
+		t.Errorf("Unexpected triggers: %s", utils.ToJSON(dst.ActionTriggers))
+	}
+	if len(dst.UnitCounters[utils.MONETARY]) != 1 {
+		t.Errorf("Unexpected counters: %s", utils.ToJSON(dst.UnitCounters))
+	}
+}
+
+func TestAccountTORQuotas(t *testing.T) {
+	acnt := &Account{ID: "cgrates.org:torquotas", BalanceMap: map[string]Balances{
+		utils.GENERIC: Balances{
+			&Balance{Uuid: "shared", ID: "SHARED", Value: 100, Weight: 10, RatingSubject: "*zero1s"},
+			&Balance{Uuid: "bonus", ID: "BONUS", Value: 5, Weight: 5, RatingSubject: "*zero1s"},
+		}}}
+	a := &Action{ActionType: SET_TOR_QUOTAS, Balance: &BalanceFilter{ID: utils.StringPointer("SHARED")},
+		ExtraParameters: `{"Priorities":{"*data":1},"Reservations":{"*voice":80}}`}
+	if err := acnt.setTORQuotasAction(a); err != nil {
+		t.Fatal(err)
+	}
+	shared := acnt.BalanceMap[utils.GENERIC][0]
+	if shared.TORPriorities[utils.DATA] != 1 || shared.TORReservations[utils.VOICE] != 80 {
+		t.Fatalf("Unexpected balance: %s", utils.ToJSON(shared))
+	}
+	if blcs := acnt.getBalancesForPrefix("", "", utils.OUT, utils.DATA, ""); len(blcs) != 2 || blcs[0].ID != "BONUS" {
+		t.Errorf("Unexpected data balances order: %s", utils.ToJSON(blcs))
+	}
+	if blcs := acnt.getBalancesForPrefix("", "", utils.OUT, utils.VOICE, ""); len(blcs) != 2 || blcs[0].ID != "SHARED" {
+		t.Errorf("Unexpected voice balances order: %s", utils.ToJSON(blcs))
+	}
+	cd := &CallDescriptor{
+		TimeStart:   time.Date(2017, 1, 1, 10, 0, 0, 0, time.UTC),
+		TimeEnd:     time.Date(2017, 1, 1, 10, 0, 25, 0, time.UTC),
+		Direction:   utils.OUT,
+		Destination: "0723",
+		TOR:         utils.DATA,
+	}
+	cd.DurationIndex = cd.TimeEnd.Sub(cd.TimeStart)
+	if _, err := acnt.debitCreditBalance(cd, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	// bonus drained first, the shared one stops at the voice reservation
+	if bonus := acnt.BalanceMap[utils.GENERIC][1]; bonus.GetValue() != 0 {
+		t.Errorf("Unexpected bonus balance: %v", bonus.GetValue())
+	}
+	if shared.GetValue() != 80 {
+		t.Errorf("Voice reservation not kept: %v", shared.GetValue())
+	}
+	cd.TimeEnd = cd.TimeStart.Add(time.Second)
+	cd.DurationIndex = time.Second
+	if _, err := acnt.debitCreditBalance(cd, false, false, true); err == nil {
+		t.Error("Expecting error when debiting data from the reserved units")
+	} else if shared.GetValue() != 80 {
+		t.Errorf("Voice reservation not kept: %v", shared.GetValue())
+	}
+	cd.TOR = utils.VOICE
+	cd.TimeEnd = cd.TimeStart.Add(40 * time.Second)
+	cd.DurationIndex = 40 * time.Second
+	if _, err := acnt.debitCreditBalance(cd, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if shared.GetValue() != 40 {
+		t.Errorf("Voice not using its reservation: %v", shared.GetValue())
+	}
+	a.Balance.ID = utils.StringPointer("MISSING")
+	if err := acnt.setTORQuotasAction(a); err != utils.ErrNotFound {
+		t.Errorf("Expecting not found, received: %v", err)
+	}
+}
+
+func TestDebitDataBalanceUnit(t *testing.T) {
+	bf := &BalanceFilter{}
+	if err := bf.SetUnits("2;MB"); err != nil || bf.GetValue() != 2 || bf.GetUnit() != "MB" {
+		t.Fatal(err, bf.GetValue(), bf.GetUnit())
+	}
+	if err := (&BalanceFilter{}).SetUnits("2;XB"); err == nil {
+		t.Error("Expecting error for unsupported unit")
+	}
+	b := bf.CreateBalance()
+	b.Uuid, b.RatingSubject = "testdata", "*zero65536s"
+	if usage := b.availableUsage(utils.DATA); usage != 2*(1<<20)*time.Second {
+		t.Error("Wrong available usage: ", usage)
+	}
+	if usage := (&Balance{Value: 1e10, Unit: "GB"}).availableUsage(utils.DATA); usage != time.Duration(math.MaxInt64) {
+		t.Error("Available usage not capped: ", usage)
+	}
+	if usage := (&Balance{Value: 10, Factor: ValueFactor{utils.DATA: 2}}).availableUsage(utils.DATA); usage != 10*time.Second {
+		t.Error("Factor applied to the available usage: ", usage)
+	}
+	tStart := time.Date(2017, 5, 10, 13, 0, 0, 0, time.UTC)
+	cd := &CallDescriptor{
+		TimeStart:   tStart,
+		TimeEnd:     tStart.Add(3 * (1 << 19) * time.Second), // 1.5MB reported in bytes
+		Direction:   utils.OUT,
+		Destination: utils.ANY,
+		Category:    "data",
+		TOR:         utils.DATA,
+	}
+	acc := &Account{ID: "cgrates.org:data", BalanceMap: map[string]Balances{utils.DATA: Balances{b}}}
+	if _, err := acc.debitCreditBalance(cd, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if val := acc.BalanceMap[utils.DATA][0].GetValue(); val != 0.5 {
+		t.Error("Wrong balance value: ", val)
+	}
+}
+
+func TestDebitParentCap(t *testing.T) {
+	newCD := func() *CallDescriptor {
+		return &CallDescriptor{Tenant: "vdf", Category: "0", Direction: utils.OUT, Destination: "0723045326",
+			TimeStart: time.Date(2013, 9, 24, 10, 48, 0, 0, time.UTC), TimeEnd: time.Date(2013, 9, 24, 10, 50, 0, 0, time.UTC),
+			DurationIndex: 2 * time.Minute}
+	}
+	cc, err := newCD().GetCost()
+	if err != nil || cc.Cost <= 70 {
+		t.Fatalf("Unexpected cost: %+v, err: %v", cc, err)
+	}
+	master := &Account{ID: "cgrates.org:master", BalanceMap: map[string]Balances{
+		utils.MONETARY: Balances{&Balance{Uuid: "master_money", Value: 200}}}}
+	dataStorage.SetAccount(master)
+	ext := &Account{ID: "cgrates.org:ext", BalanceMap: map[string]Balances{
+		utils.MONETARY: Balances{&Balance{Uuid: "ext_money", Value: 20}}}}
+	if err := ext.SetParent(&Action{ExtraParameters: `{"Account":"master","Cap":50}`}); err != nil {
+		t.Fatal(err)
+	}
+	if ext.ParentID != master.ID || ext.ParentCap != 50 {
+		t.Fatalf("Wrong parent: %s, cap: %v", ext.ParentID, ext.ParentCap)
+	}
+	// own balance first, falling through to the parent up to the cap, the rest going negative
+	if _, err := ext.debitCreditBalance(newCD(), false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	master, _ = dataStorage.GetAccount(master.ID)
+	if debt := ext.GetDefaultMoneyBalance().GetValue(); debt != 70-cc.Cost || // 20 own and 50 out of the parent
+		master.BalanceMap[utils.MONETARY][0].GetValue() != 150 || ext.ParentUsed != 50 {
+		t.Errorf("Wrong values, debt: %v, master: %v, used: %v", debt,
+			master.BalanceMap[utils.MONETARY][0].GetValue(), ext.ParentUsed)
+	}
+	// cap reached
+	if _, err := ext.debitCreditBalance(newCD(), false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if master, _ = dataStorage.GetAccount(master.ID); master.BalanceMap[utils.MONETARY][0].GetValue() != 150 {
+		t.Errorf("Parent debited over the cap: %v", master.BalanceMap[utils.MONETARY][0].GetValue())
+	}
+	// setting the parent again restarts counting the cap
+	if err := ext.SetParent(&Action{ExtraParameters: `{"Account":"cgrates.org:master","Cap":50}`}); err != nil {
+		t.Fatal(err)
+	}
+	if cc, err = ext.debitCreditBalance(newCD(), false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if master, _ = dataStorage.GetAccount(master.ID); master.BalanceMap[utils.MONETARY][0].GetValue() != 100 || ext.ParentUsed != 50 {
+		t.Errorf("Wrong values, master: %v, used: %v", master.BalanceMap[utils.MONETARY][0].GetValue(), ext.ParentUsed)
+	}
+	// refunds out of the parent give back the cap
+	dataStorage.SetAccount(ext)
+	refundCD := &CallDescriptor{Tenant: "cgrates.org", Account: "ext", Direction: utils.OUT, TOR: utils.VOICE}
+	for _, ts := range cc.Timespans {
+		refundCD.Increments = append(refundCD.Increments, ts.Increments...)
+	}
+	if err := refundCD.RefundIncrements(); err != nil {
+		t.Fatal(err)
+	}
+	master, _ = dataStorage.GetAccount(master.ID)
+	if ext, _ = dataStorage.GetAccount(ext.ID); master.BalanceMap[utils.MONETARY][0].GetValue() != 150 || ext.ParentUsed != 0 {
+		t.Errorf("Wrong values after refund, master: %v, used: %v", master.BalanceMap[utils.MONETARY][0].GetValue(), ext.ParentUsed)
+	}
+	if err := ext.SetParent(&Action{ExtraParameters: `{"Account":"ext"}`}); err != utils.ErrInvalidParent {
+		t.Error("Expecting invalid parent, got: ", err)
+	}
+}
+
+func TestAccountRolloverBalances(t *testing.T) {
+	bundle := &Balance{Uuid: "bundle1", ID: "BUNDLE", Value: 100, Weight: 10}
+	acnt := &Account{ID: "cgrates.org:rollover1", BalanceMap: map[string]Balances{utils.VOICE: Balances{bundle}}}
+	a := &Action{ActionType: SET_ROLLOVER, ExtraParameters: `{"Policy":"*capped","Cap":30}`,
+		Balance: &BalanceFilter{ID: utils.StringPointer("BUNDLE")}}
+	if err := acnt.setRolloverAction(a); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	monthStart, _ := utils.GetPeriodStart(utils.MetaMonthly, now)
+	if bundle.RolloverPeriod != utils.MetaMonthly || !bundle.RolloverReset.Equal(monthStart) {
+		t.Errorf("Unexpected balance: %s", utils.ToJSON(bundle))
+	}
+	acnt.rolloverBalances(now) // current period not ended yet
+	if len(acnt.BalanceMap[utils.VOICE]) != 1 || bundle.Value != 100 {
+		t.Fatalf("Unexpected balances: %s", utils.ToJSON(acnt.BalanceMap))
+	}
+	bundle.RolloverReset = monthStart.AddDate(0, -1, 0)
+	acnt.rolloverBalances(now)
+	if len(acnt.BalanceMap[utils.VOICE]) != 2 {
+		t.Fatalf("Unexpected balances: %s", utils.ToJSON(acnt.BalanceMap))
+	}
+	rolled := acnt.BalanceMap[utils.VOICE][1]
+	if bundle.Value != 0 || rolled.Value != 30 || rolled.Weight != 11 || rolled.RolloverPolicy != "" ||
+		rolled.ID != "BUNDLE_rollover_"+monthStart.Format("20060102") || !rolled.ExpirationDate.IsZero() {
+		t.Errorf("Unexpected balances: %s", utils.ToJSON(acnt.BalanceMap))
+	}
+	acnt.rolloverBalances(now) // already rolled over in this period
+	if len(acnt.BalanceMap[utils.VOICE]) != 2 {
+		t.Errorf("Unexpected balances: %s", utils.ToJSON(acnt.BalanceMap))
+	}
+	a.ExtraParameters = `{"Policy":"*expiring","Periods":2}`
+	if err := acnt.setRolloverAction(a); err != nil {
+		t.Fatal(err)
+	}
+	bundle.Value = 45
+	bundle.RolloverReset = monthStart.AddDate(0, -1, 0)
+	acnt.rolloverBalances(now)
+	if len(acnt.BalanceMap[utils.VOICE]) != 3 {
+		t.Fatalf("Unexpected balances: %s", utils.ToJSON(acnt.BalanceMap))
+	}
+	if rolled := acnt.BalanceMap[utils.VOICE][2]; rolled.Value != 45 || !rolled.ExpirationDate.Equal(monthStart.AddDate(0, 2, 0)) {
+		t.Errorf("Unexpected rolled balance: %s", utils.ToJSON(rolled))
+	}
+	a.ExtraParameters = `{"Policy":"*capped"}`
+	if err := acnt.setRolloverAction(a); err == nil {
+		t.Error("Expecting error on missing cap")
+	}
+	a.ExtraParameters = `{"Policy":"*forever"}`
+	if err := acnt.setRolloverAction(a); err == nil {
+		t.Error("Expecting error on unsupported policy")
+	}
+}
@@ -49,7 +49,9 @@ type Action struct {
 	ExpirationString string // must stay as string because it can have relative values like 1month
 	Weight           float64
 	Balance          *BalanceFilter
-	balanceValue     float64 // balance value after action execution, used with cdrlog
+	balanceValue     float64        // balance value after action execution, used with cdrlog
+	credit           *Action        // balance credited by the conversion, used with cdrlog
+	trigger          *ActionTrigger // trigger executing the action, used with http_post
 }
 
 const (
@@ -57,11 +59,16 @@ const (
 	RESET_TRIGGERS            = "*reset_triggers"
 	SET_RECURRENT             = "*set_recurrent"
 	UNSET_RECURRENT           = "*unset_recurrent"
+	SET_TRIGGER_THRESHOLD     = "*set_trigger_threshold"
+	SET_PARENT                = "*set_parent"
 	ALLOW_NEGATIVE            = "*allow_negative"
 	DENY_NEGATIVE             = "*deny_negative"
 	RESET_ACCOUNT             = "*reset_account"
 	REMOVE_ACCOUNT            = "*remove_account"
 	SET_BALANCE               = "*set_balance"
+	SET_QUOTA                 = "*set_quota"
+	SET_TOR_QUOTAS            = "*set_tor_quotas"
+	SET_CURRENCY              = "*set_currency"
 	REMOVE_BALANCE            = "*remove_balance"
 	TOPUP_RESET               = "*topup_reset"
 	TOPUP                     = "*topup"
@@ -78,6 +85,13 @@ const (
 	SET_DDESTINATIONS         = "*set_ddestinations"
 	TRANSFER_MONETARY_DEFAULT = "*transfer_monetary_default"
 	CGR_RPC                   = "*cgr_rpc"
+	CONVERT_BALANCE           = "*convert_balance"
+	SET_DRIP                  = "*set_drip"
+	SET_ROLLOVER              = "*set_rollover"
+	ROLLOVER_BALANCES         = "*rollover_balances"
+	HTTP_POST                 = "*http_post"
+	MAIL                      = "*mail"
+	SEND_SMS                  = "*sms"
 )
 
 func (a *Action) Clone() *Action {
@@ -95,6 +109,8 @@ func getActionFunc(typ string) (actionTypeFunc, bool) {
 		RESET_TRIGGERS:            resetTriggersAction,
 		SET_RECURRENT:             setRecurrentAction,
 		UNSET_RECURRENT:           unsetRecurrentAction,
+		SET_TRIGGER_THRESHOLD:     setTriggerThresholdAction,
+		SET_PARENT:                setParentAction,
 		ALLOW_NEGATIVE:            allowNegativeAction,
 		DENY_NEGATIVE:             denyNegativeAction,
 		RESET_ACCOUNT:             resetAccountAction,
@@ -112,8 +128,18 @@ func getActionFunc(typ string) (actionTypeFunc, bool) {
 		REMOVE_ACCOUNT:            removeAccountAction,
 		REMOVE_BALANCE:            removeBalanceAction,
 		SET_BALANCE:               setBalanceAction,
+		SET_QUOTA:                 setQuotaAction,
+		SET_TOR_QUOTAS:            setTORQuotasAction,
+		SET_CURRENCY:              setCurrencyAction,
 		TRANSFER_MONETARY_DEFAULT: transferMonetaryDefaultAction,
 		CGR_RPC:                   cgrRPCAction,
+		CONVERT_BALANCE:           convertBalanceAction,
+		SET_DRIP:                  setDripAction,
+		SET_ROLLOVER:              setRolloverAction,
+		ROLLOVER_BALANCES:         rolloverBalancesAction,
+		HTTP_POST:                 httpPostAction,
+		MAIL:                      notifyAction(utils.MetaMail),
+		SEND_SMS:                  notifyAction(utils.SMS),
 	}
 	f, exists := actionFuncMap[typ]
 	return f, exists
@@ -212,10 +238,17 @@ func cdrLogAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions)
 
 	// set stored cdr values
 	var cdrs []*CDR
+	var logged Actions
 	for _, action := range acs {
-		if !utils.IsSliceMember([]string{DEBIT, DEBIT_RESET, TOPUP, TOPUP_RESET}, action.ActionType) || action.Balance == nil {
+		if !utils.IsSliceMember([]string{DEBIT, DEBIT_RESET, TOPUP, TOPUP_RESET, CONVERT_BALANCE}, action.ActionType) || action.Balance == nil {
 			continue // Only log specific actions
 		}
+		logged = append(logged, action)
+		if action.credit != nil { // conversions are logged as the debit followed by the credit
+			logged = append(logged, action.credit)
+		}
+	}
+	for _, action := range logged {
 		cdr := &CDR{RunID: action.ActionType, Source: CDRLOG, SetupTime: time.Now(), AnswerTime: time.Now(), OriginID: utils.GenUUID(), ExtraFields: make(map[string]string)}
 		cdr.CGRID = utils.Sha1(cdr.OriginID, cdr.SetupTime.String())
 		cdr.Usage = time.Duration(1) * time.Second
@@ -275,6 +308,20 @@ func unsetRecurrentAction(ub *Account, sq *StatsQueueTriggered, a *Action, acs A
 	return
 }
 
+func setTriggerThresholdAction(ub *Account, sq *StatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if ub == nil {
+		return errors.New("nil account")
+	}
+	return ub.SetTriggerThreshold(a)
+}
+
+func setParentAction(ub *Account, sq *StatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if ub == nil {
+		return errors.New("nil account")
+	}
+	return ub.SetParent(a)
+}
+
 func allowNegativeAction(ub *Account, sq *StatsQueueTriggered, a *Action, acs Actions) (err error) {
 	if ub == nil {
 		return errors.New("nil account")
@@ -634,6 +681,44 @@ func setBalanceAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Acti
 	return acc.setBalanceAction(a)
 }
 
+// setQuotaAction creates or updates a *generic balance with Units reset at the beginning of each period in ExtraParameters, eg: *monthly
+func setQuotaAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+	if acc == nil {
+		return fmt.Errorf("nil account for %s action", utils.ToJSON(a))
+	}
+	return acc.setQuotaAction(a)
+}
+
+func setTORQuotasAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+	if acc == nil {
+		return fmt.Errorf("nil account for %s action", utils.ToJSON(a))
+	}
+	return acc.setTORQuotasAction(a)
+}
+
+func setCurrencyAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+	if acc == nil {
+		return fmt.Errorf("nil account for %s action", utils.ToJSON(a))
+	}
+	return acc.setCurrencyAction(a)
+}
+
+// convertBalanceAction moves value between balance types at the rate in ExtraParameters
+func setDripAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+	if acc == nil {
+		return fmt.Errorf("nil account for %s action", utils.ToJSON(a))
+	}
+	return acc.setDripAction(a)
+}
+
+func convertBalanceAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if acc == nil {
+		return fmt.Errorf("nil account for %s action", utils.ToJSON(a))
+	}
+	a.credit, err = acc.convertBalanceAction(a)
+	return
+}
+
 func transferMonetaryDefaultAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
 	if acc == nil {
 		utils.Logger.Err("*transfer_monetary_default called without account")
@@ -781,3 +866,20 @@ func (apl Actions) Clone() (interface{}, error) {
 	}
 	return cln, nil
 }
+
+// setRolloverAction sets the policy carrying the unused value of the balance into the next period
+func setRolloverAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+	if acc == nil {
+		return fmt.Errorf("nil account for %s action", utils.ToJSON(a))
+	}
+	return acc.setRolloverAction(a)
+}
+
+// rolloverBalancesAction applies the rollover policies of the account balances, scheduled at the end of their period
+func rolloverBalancesAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+	if acc == nil {
+		return fmt.Errorf("nil account for %s action", utils.ToJSON(a))
+	}
+	acc.rolloverBalances(time.Now())
+	return nil
+}
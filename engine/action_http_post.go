@@ -0,0 +1,206 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	httpPostWorkers   = 4    // concurrent *http_post requests
+	httpPostQueueSize = 1000 // requests waiting for a worker before the actions start failing
+)
+
+var (
+	httpPostQueue     chan *httpPostRequest
+	httpPostQueueOnce sync.Once
+)
+
+// httpPostRequest is the ExtraParameters of the *http_post action, once the template executed
+type httpPostRequest struct {
+	URL      string
+	Method   string            // defaults to POST
+	Headers  map[string]string // eg: Authorization
+	Body     json.RawMessage   // sent as it is, the template data as JSON if missing
+	Attempts int               // defaults to poster_attempts
+	Backoff  string            // wait before the first retry, doubled after each failed attempt, defaults to 1s
+	backoff  time.Duration
+}
+
+/*
+*http_post ExtraParameters are a template with the same delimiters and objects as *cgr_rpc, plus:
+
+Trigger - the ActionTrigger executing the actions, if any
+Balances - the total value of each balance type, eg: << index .Balances "*monetary" >>
+
+and the json function to embed values, eg:
+
+{"URL":"https://crm.example.com/hooks/credit","Headers":{"Authorization":"Bearer xyz"},
+"Body":{"account":<< json .Account.ID >>,"threshold":<< json .Trigger.ThresholdValue >>,"balances":<< json .Balances >>}}
+*/
+func httpPostAction(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+	tmpl, err := parseActionTemplate(HTTP_POST, a.ExtraParameters)
+	if err != nil {
+		return err
+	}
+	data := newActionTemplateData(acc, sq, a, acs)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	req := new(httpPostRequest)
+	if err := json.Unmarshal(buf.Bytes(), req); err != nil {
+		return err
+	}
+	if req.URL == "" {
+		return errors.New("missing URL")
+	}
+	if req.Method == "" {
+		req.Method = http.MethodPost
+	}
+	if req.Attempts < 1 {
+		req.Attempts = config.CgrConfig().PosterAttempts
+	}
+	if req.backoff = time.Second; req.Backoff != "" {
+		if req.backoff, err = utils.ParseDurationWithSecs(req.Backoff); err != nil {
+			return err
+		}
+	}
+	if len(req.Body) == 0 {
+		if req.Body, err = json.Marshal(struct {
+			Account  *AccountSummary
+			Trigger  *ActionTrigger
+			Balances map[string]float64
+		}{accountSummary(acc), data.Trigger, data.Balances}); err != nil {
+			return err
+		}
+	}
+	httpPostQueueOnce.Do(startHTTPPostWorkers)
+	select {
+	case httpPostQueue <- req:
+		return nil
+	default:
+		return utils.ErrResourceUnavailable
+	}
+}
+
+// actionTemplateData is the data the templates of the *http_post, *mail and *sms actions are executed with
+type actionTemplateData struct {
+	Account  *Account
+	Sq       *StatsQueueTriggered
+	Action   *Action
+	Actions  Actions
+	Trigger  *ActionTrigger
+	Balances map[string]float64
+}
+
+func newActionTemplateData(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) *actionTemplateData {
+	data := &actionTemplateData{Account: acc, Sq: sq, Action: a, Actions: acs, Trigger: a.trigger, Balances: make(map[string]float64)}
+	if data.Trigger == nil && sq != nil {
+		data.Trigger = sq.Trigger
+	}
+	if acc != nil {
+		for balanceType, balances := range acc.BalanceMap {
+			data.Balances[balanceType] = balances.GetTotalValue()
+		}
+	}
+	return data
+}
+
+// parseActionTemplate parses the templates of the actions using << >> as delimiters and the json function
+func parseActionTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Delims("<<", ">>").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		}}).Parse(text)
+}
+
+func accountSummary(acc *Account) *AccountSummary {
+	if acc == nil {
+		return nil
+	}
+	return acc.AsAccountSummary()
+}
+
+func startHTTPPostWorkers() {
+	httpPostQueue = make(chan *httpPostRequest, httpPostQueueSize)
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: config.CgrConfig().HttpSkipTlsVerify}},
+		Timeout:   config.CgrConfig().ReplyTimeout}
+	for i := 0; i < httpPostWorkers; i++ {
+		go func() {
+			for req := range httpPostQueue {
+				if err := req.send(client); err != nil {
+					utils.Logger.Err(fmt.Sprintf("<%s> Failed %s %s after %d attempts, error: %s",
+						HTTP_POST, req.Method, req.URL, req.Attempts, err.Error()))
+				}
+			}
+		}()
+	}
+}
+
+// send retries with exponential backoff on connection errors, 5xx and 429 replies
+func (req *httpPostRequest) send(client *http.Client) (err error) {
+	backoff := req.backoff
+	for i := 0; i < req.Attempts; i++ {
+		if i != 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var retry bool
+		if retry, err = req.sendOnce(client); err == nil || !retry {
+			return
+		}
+	}
+	return
+}
+
+func (req *httpPostRequest) sendOnce(client *http.Client) (retry bool, err error) {
+	httpReq, err := http.NewRequest(req.Method, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for hdr, val := range req.Headers {
+		httpReq.Header.Set(hdr, val)
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body) // so the connection can be reused
+	if resp.StatusCode > 299 {
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests,
+			fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	return false, nil
+}
@@ -32,14 +32,16 @@ const (
 )
 
 type ActionTiming struct {
-	Uuid         string
-	Timing       *RateInterval
-	ActionsID    string
-	Weight       float64
-	actions      Actions
-	accountIDs   utils.StringMap // copy of action plans accounts
-	actionPlanID string          // the id of the belonging action plan (info only)
-	stCache      time.Time       // cached time of the next start
+	Uuid          string
+	Timing        *RateInterval
+	ActionsID     string
+	Weight        float64
+	CatchUp       string    // executions missed while the scheduler was down: <""|*skip|*run_once_on_start|*run_all_missed>
+	LastExecution time.Time // scheduled time of the last execution
+	actions       Actions
+	accountIDs    utils.StringMap // copy of action plans accounts
+	actionPlanID  string          // the id of the belonging action plan (info only)
+	stCache       time.Time       // cached time of the next start
 }
 
 type Task struct {
@@ -81,11 +83,20 @@ func (at *ActionTiming) GetNextStartTime(now time.Time) (t time.Time) {
 	if !at.stCache.IsZero() {
 		return at.stCache
 	}
+	if !at.normalizeTiming() {
+		return
+	}
+	at.stCache = cronexpr.MustParse(at.Timing.Timing.CronString()).Next(now)
+	return at.stCache
+}
+
+// normalizeTiming fills in the timing defaults needed to build the cron expression,
+// returns false if there is no timing defined
+func (at *ActionTiming) normalizeTiming() bool {
 	i := at.Timing
 	if i == nil || i.Timing == nil {
-		return
+		return false
 	}
-	// Normalize
 	if i.Timing.StartTime == "" {
 		i.Timing.StartTime = "00:00:00"
 	}
@@ -95,8 +106,49 @@ func (at *ActionTiming) GetNextStartTime(now time.Time) (t time.Time) {
 	if len(i.Timing.Months) > 0 && len(i.Timing.MonthDays) == 0 {
 		i.Timing.MonthDays = append(i.Timing.MonthDays, 1)
 	}
-	at.stCache = cronexpr.MustParse(i.Timing.CronString()).Next(now)
-	return at.stCache
+	return true
+}
+
+// MissedExecutions returns, according to the CatchUp policy, the scheduled times between the last execution and now
+// which were not executed: only the latest one for *run_once_on_start, the first maxItems ones for *run_all_missed
+func (at *ActionTiming) MissedExecutions(now time.Time, maxItems int) (missed []time.Time, err error) {
+	if at.LastExecution.IsZero() || !at.normalizeTiming() || at.IsASAP() {
+		return
+	}
+	if at.CatchUp == "" || at.CatchUp == utils.MetaSkip {
+		return
+	}
+	if !utils.IsCatchUpPolicy(at.CatchUp) {
+		return nil, fmt.Errorf("unsupported catch up policy: %s", at.CatchUp)
+	}
+	expr, err := cronexpr.Parse(at.Timing.Timing.CronString())
+	if err != nil {
+		return nil, err
+	}
+	for t := expr.Next(at.LastExecution); !t.IsZero() && t.Before(now); t = expr.Next(t) {
+		if at.CatchUp == utils.MetaRunOnceOnStart {
+			missed = []time.Time{t}
+			continue
+		}
+		if len(missed) == maxItems {
+			break
+		}
+		missed = append(missed, t)
+	}
+	return
+}
+
+// GetNextStartTimes computes the next n execution times following now, in the location of now
+// ASAP timings are executed once at load time so they have no scheduled executions
+func (at *ActionTiming) GetNextStartTimes(now time.Time, n int) ([]time.Time, error) {
+	if n <= 0 || !at.normalizeTiming() || at.IsASAP() {
+		return nil, nil
+	}
+	expr, err := cronexpr.Parse(at.Timing.Timing.CronString())
+	if err != nil {
+		return nil, err
+	}
+	return expr.NextN(now, uint(n)), nil
 }
 
 // To be deleted after the above solution proves reliable
@@ -346,6 +398,11 @@ func (at *ActionTiming) Execute(successActions, failedActions chan *Action) (err
 				}
 			}
 			if !transactionFailed && !removeAccountActionFound {
+				reason := utils.MetaActions
+				if at.ActionsID != "" {
+					reason = utils.ConcatenatedKey(reason, at.ActionsID)
+				}
+				acc.publishBalanceChanges(reason)
 				dataStorage.SetAccount(acc)
 			}
 			return 0, nil
@@ -20,6 +20,7 @@ package engine
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/utils"
@@ -56,3 +57,77 @@ func TestCacheGetCloned(t *testing.T) {
 		t.Errorf("Expecting: %+v, received: %+v", at1, at1Cloned)
 	}
 }
+
+func TestActionTimingGetNextStartTimes(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	// daily execution crossing the DST change
+	at := &ActionTiming{Timing: &RateInterval{Timing: &RITiming{StartTime: "01:00:00"}}}
+	now := time.Date(2017, 3, 24, 12, 0, 0, 0, loc)
+	eSts := []time.Time{
+		time.Date(2017, 3, 25, 1, 0, 0, 0, loc),
+		time.Date(2017, 3, 26, 1, 0, 0, 0, loc),
+		time.Date(2017, 3, 27, 1, 0, 0, 0, loc),
+	}
+	if sts, err := at.GetNextStartTimes(now, 3); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eSts, sts) {
+		t.Errorf("Expecting: %+v, received: %+v", eSts, sts)
+	} else if dur := sts[2].Sub(sts[1]); dur != 23*time.Hour {
+		t.Errorf("Unexpected duration over DST change: %v", dur)
+	}
+	// monthly execution skipping the months without the day
+	at = &ActionTiming{Timing: &RateInterval{Timing: &RITiming{MonthDays: utils.MonthDays{31}, StartTime: "00:00:00"}}}
+	eSts = []time.Time{
+		time.Date(2017, 3, 31, 0, 0, 0, 0, loc),
+		time.Date(2017, 5, 31, 0, 0, 0, 0, loc),
+	}
+	if sts, err := at.GetNextStartTimes(now, 2); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eSts, sts) {
+		t.Errorf("Expecting: %+v, received: %+v", eSts, sts)
+	}
+	at = &ActionTiming{Timing: &RateInterval{Timing: &RITiming{StartTime: utils.ASAP}}}
+	if sts, err := at.GetNextStartTimes(now, 2); err != nil || len(sts) != 0 {
+		t.Errorf("Unexpected ASAP executions: %+v, err: %v", sts, err)
+	}
+}
+
+func TestActionTimingMissedExecutions(t *testing.T) {
+	at := &ActionTiming{
+		Timing:        &RateInterval{Timing: &RITiming{MonthDays: utils.MonthDays{1}, StartTime: "00:00:00"}},
+		LastExecution: time.Date(2017, 1, 1, 0, 0, 0, 0, time.Local),
+	}
+	now := time.Date(2017, 4, 15, 0, 0, 0, 0, time.Local)
+	if missed, err := at.MissedExecutions(now, 10); err != nil || len(missed) != 0 {
+		t.Errorf("Unexpected missed executions with default policy: %+v, err: %v", missed, err)
+	}
+	at.CatchUp = utils.MetaRunAllMissed
+	eMissed := []time.Time{
+		time.Date(2017, 2, 1, 0, 0, 0, 0, time.Local),
+		time.Date(2017, 3, 1, 0, 0, 0, 0, time.Local),
+		time.Date(2017, 4, 1, 0, 0, 0, 0, time.Local),
+	}
+	if missed, err := at.MissedExecutions(now, 10); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eMissed, missed) {
+		t.Errorf("Expecting: %+v, received: %+v", eMissed, missed)
+	}
+	if missed, err := at.MissedExecutions(now, 2); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eMissed[:2], missed) {
+		t.Errorf("Expecting: %+v, received: %+v", eMissed[:2], missed)
+	}
+	at.CatchUp = utils.MetaRunOnceOnStart
+	if missed, err := at.MissedExecutions(now, 10); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eMissed[2:], missed) {
+		t.Errorf("Expecting: %+v, received: %+v", eMissed[2:], missed)
+	}
+	at.CatchUp = "*unsupported"
+	if _, err := at.MissedExecutions(now, 10); err == nil {
+		t.Error("Expecting error for unsupported policy")
+	}
+}
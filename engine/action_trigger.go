@@ -92,6 +92,11 @@ func (at *ActionTrigger) Execute(ub *Account, sq *StatsQueueTriggered) (err erro
 			transactionFailed = false
 			break
 		}
+		if a.ActionType == HTTP_POST { // the request can be built out of the trigger, the cached action stays untouched
+			trgA := *a
+			trgA.trigger = at
+			a = &trgA
+		}
 		//go utils.Logger.Info(fmt.Sprintf("Executing %v, %v: %v", ub, sq, a))
 		if err := actionFunction(ub, sq, a, aac); err != nil {
 			utils.Logger.Err(fmt.Sprintf("Error executing action %s: %v!", a.ActionType, err))
@@ -112,6 +117,7 @@ func (at *ActionTrigger) Execute(ub *Account, sq *StatsQueueTriggered) (err erro
 			"Id":        at.ID,
 			"ActionIds": at.ActionsID,
 		})
+		ub.publishBalanceChanges(utils.ConcatenatedKey(utils.MetaActionTrigger, at.ActionsID))
 		dataStorage.SetAccount(ub)
 	}
 	return
@@ -20,6 +20,9 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
@@ -804,6 +807,78 @@ func TestActionResetTriggresActionFilter(t *testing.T) {
 	}
 }
 
+func TestActionSetTriggerThreshold(t *testing.T) {
+	ub := &Account{
+		ID: "TEST_UB",
+		ActionTriggers: ActionTriggers{
+			&ActionTrigger{ID: "FRAUD", UniqueID: "f1", Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY)}, ThresholdValue: 100, Executed: true},
+			&ActionTrigger{ID: "FRAUD", UniqueID: "f2", Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY)}, ThresholdValue: 200, Executed: true},
+			&ActionTrigger{ID: "OTHER", UniqueID: "o1", Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY)}, ThresholdValue: 10, Executed: true},
+		},
+	}
+	a := &Action{ActionType: SET_TRIGGER_THRESHOLD, ExtraParameters: `{"GroupID":"FRAUD","ThresholdDelta":50,"Rearm":true}`, Balance: &BalanceFilter{}}
+	if err := setTriggerThresholdAction(ub, nil, a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if ub.ActionTriggers[0].ThresholdValue != 150 || ub.ActionTriggers[1].ThresholdValue != 250 ||
+		ub.ActionTriggers[0].Executed || ub.ActionTriggers[1].Executed {
+		t.Errorf("Set trigger threshold failed: %s", utils.ToIJSON(ub.ActionTriggers))
+	}
+	if ub.ActionTriggers[2].ThresholdValue != 10 || !ub.ActionTriggers[2].Executed {
+		t.Errorf("Set trigger threshold changed other group: %s", utils.ToIJSON(ub.ActionTriggers[2]))
+	}
+	a.ExtraParameters = `{"UniqueID":"o1","ThresholdValue":20}`
+	if err := setTriggerThresholdAction(ub, nil, a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if ub.ActionTriggers[2].ThresholdValue != 20 || !ub.ActionTriggers[2].Executed || ub.ActionTriggers[0].ThresholdValue != 150 {
+		t.Errorf("Set trigger threshold failed: %s", utils.ToIJSON(ub.ActionTriggers))
+	}
+	a.ExtraParameters = `{"UniqueID":"o1"}`
+	if err := setTriggerThresholdAction(ub, nil, a, nil); err != utils.ErrMandatoryIeMissing {
+		t.Error("Expecting missing parameters error, got: ", err)
+	}
+}
+
+func TestActionTopupSchedulesExpiryNotifications(t *testing.T) {
+	SetBalanceExpiryNotify([]int{7, 3, 1}, "EXPIRY_WARN")
+	defer SetBalanceExpiryNotify(nil, "")
+	reloaded := make(chan struct{}, 1)
+	SetSchedulerReloader(func() { reloaded <- struct{}{} })
+	defer SetSchedulerReloader(nil)
+	ub := &Account{ID: "cgrates.org:expiry_notify", BalanceMap: make(map[string]Balances)}
+	expiry := time.Now().Add(5 * 24 * time.Hour)
+	a := &Action{ActionType: TOPUP, Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY),
+		Value: &utils.ValueFormula{Static: 10}, ExpirationDate: &expiry}}
+	if err := topupAction(ub, nil, a, nil); err != nil {
+		t.Fatal(err)
+	}
+	b := ub.BalanceMap[utils.MONETARY][0]
+	apl, err := dataStorage.GetActionPlan(expiryNotifyPlanID(b), false, utils.NonTransactional)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !apl.AccountIDs[ub.ID] || len(apl.ActionTimings) != 2 { // 7 days before is already in the past
+		t.Fatalf("Unexpected action plan: %s", utils.ToJSON(apl))
+	}
+	for i, days := range []int{3, 1} {
+		at := apl.ActionTimings[i]
+		notifyTime := expiry.AddDate(0, 0, -days).Local()
+		if at.ActionsID != "EXPIRY_WARN" || at.Timing.Timing.MonthDays[0] != notifyTime.Day() ||
+			at.Timing.Timing.StartTime != notifyTime.Format("15:04:05") {
+			t.Errorf("Unexpected action timing: %s", utils.ToJSON(at))
+		}
+	}
+	if apIDs, err := dataStorage.GetAccountActionPlans(ub.ID, false, utils.NonTransactional); err != nil || !utils.IsSliceMember(apIDs, apl.Id) {
+		t.Errorf("Account action plans: %v, err: %v", apIDs, err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Error("Scheduler not reloaded")
+	}
+}
+
 func TestActionSetPostpaid(t *testing.T) {
 	ub := &Account{
 		ID:             "TEST_UB",
@@ -2250,6 +2325,75 @@ func TestActionCdrlogBalanceValue(t *testing.T) {
 	}
 }
 
+func TestActionConvertBalance(t *testing.T) {
+	if err := dataStorage.SetAccount(&Account{
+		ID: "cgrates.org:conv",
+		BalanceMap: map[string]Balances{
+			utils.GENERIC: Balances{
+				&Balance{ID: "LOYALTY1", Uuid: utils.GenUUID(), Value: 100, Weight: 20},
+				&Balance{ID: "LOYALTY2", Uuid: utils.GenUUID(), Value: 50, Weight: 10},
+			},
+			utils.MONETARY: Balances{&Balance{ID: "MAIN", Uuid: utils.GenUUID(), Value: 5}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	convert := &Action{
+		Id:              "CONVERT_LOYALTY",
+		ActionType:      CONVERT_BALANCE,
+		ExtraParameters: `{"BalanceType":"*monetary","BalanceID":"MAIN","Rate":0.01}`,
+		Balance: &BalanceFilter{Type: utils.StringPointer(utils.GENERIC),
+			Value: &utils.ValueFormula{Static: 120}},
+	}
+	at := &ActionTiming{
+		accountIDs: utils.StringMap{"cgrates.org:conv": true},
+		Timing:     &RateInterval{},
+		actions: []*Action{convert,
+			&Action{
+				Id:              "LOG",
+				ActionType:      CDRLOG,
+				ExtraParameters: `{"BalanceID":"BalanceID","BalanceValue":"BalanceValue"}`,
+			},
+		},
+	}
+	if err := at.Execute(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	acc, err := dataStorage.GetAccount("cgrates.org:conv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.BalanceMap[utils.GENERIC][0].Value != 0 ||
+		acc.BalanceMap[utils.GENERIC][1].Value != 30 ||
+		acc.BalanceMap[utils.MONETARY][0].Value != 6.2 {
+		t.Errorf("Conversion didn't work: %s", utils.ToJSON(acc.BalanceMap))
+	}
+	cdrs := make([]*CDR, 0)
+	json.Unmarshal([]byte(at.actions[1].ExpirationString), &cdrs)
+	if len(cdrs) != 2 ||
+		cdrs[0].ToR != utils.GENERIC || cdrs[0].Cost != 120 || cdrs[0].ExtraFields["BalanceValue"] != "30" ||
+		cdrs[1].ToR != utils.MONETARY || cdrs[1].Cost != 1.2 || cdrs[1].ExtraFields["BalanceValue"] != "6.2" ||
+		cdrs[1].ExtraFields["BalanceID"] != "MAIN" {
+		t.Errorf("Wrong cdrlogs: %s", utils.ToIJSON(cdrs))
+	}
+	// not enough points left, nothing converted
+	if _, err := acc.convertBalanceAction(convert); err == nil {
+		t.Error("Expecting insufficient balance error")
+	}
+	if acc.BalanceMap[utils.GENERIC].GetTotalValue() != 30 || acc.BalanceMap[utils.MONETARY][0].Value != 6.2 {
+		t.Errorf("Balances changed on failed conversion: %s", utils.ToJSON(acc.BalanceMap))
+	}
+	// missing target created
+	convert.Balance.Value = &utils.ValueFormula{Static: 30}
+	convert.ExtraParameters = `{"BalanceType":"*sms","BalanceID":"BONUS_SMS","Rate":0.5}`
+	if _, err := acc.convertBalanceAction(convert); err != nil {
+		t.Error(err)
+	} else if len(acc.BalanceMap[utils.SMS]) != 1 || acc.BalanceMap[utils.SMS][0].ID != "BONUS_SMS" ||
+		acc.BalanceMap[utils.SMS][0].Value != 15 {
+		t.Errorf("Unexpected target balance: %s", utils.ToJSON(acc.BalanceMap[utils.SMS]))
+	}
+}
+
 type TestRPCParameters struct {
 	status string
 }
@@ -2446,3 +2590,63 @@ func BenchmarkUUID(b *testing.B) {
 		b.StartTimer()
 	}
 }
+
+func TestActionHTTPPost(t *testing.T) {
+	type received struct {
+		method, auth, body string
+	}
+	reqs := make(chan *received, 3)
+	var failed bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !failed { // the first attempt is retried
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		reqs <- &received{r.Method, r.Header.Get("Authorization"), string(body)}
+	}))
+	defer srv.Close()
+	acc := &Account{ID: "cgrates.org:httppost", BalanceMap: map[string]Balances{
+		utils.MONETARY: Balances{&Balance{Value: 3}, &Balance{Value: 1.5}}}}
+	at := &ActionTrigger{ID: "LOW_CREDIT", ThresholdType: utils.TRIGGER_MIN_BALANCE, ThresholdValue: 5}
+	a := &Action{ActionType: HTTP_POST, trigger: at, ExtraParameters: `{"URL":"` + srv.URL + `","Method":"PUT",
+		"Headers":{"Authorization":"Bearer crm"},"Backoff":"1ms","Attempts":2,
+		"Body":{"account":<< json .Account.ID >>,"trigger":<< json .Trigger.ID >>,"credit":<< index .Balances "*monetary" >>}}`}
+	if err := httpPostAction(acc, nil, a, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case rcv := <-reqs:
+		if rcv.method != "PUT" || rcv.auth != "Bearer crm" ||
+			rcv.body != `{"account":"cgrates.org:httppost","trigger":"LOW_CREDIT","credit":4.5}` {
+			t.Errorf("Unexpected request: %+v", rcv)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Request not received")
+	}
+	a.ExtraParameters = `{"URL":"` + srv.URL + `"}`
+	if err := httpPostAction(acc, nil, a, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case rcv := <-reqs:
+		var body struct {
+			Account  *AccountSummary
+			Trigger  *ActionTrigger
+			Balances map[string]float64
+		}
+		if err := json.Unmarshal([]byte(rcv.body), &body); err != nil {
+			t.Fatal(err)
+		}
+		if rcv.method != "POST" || body.Account.ID != "httppost" || body.Trigger.ID != at.ID || body.Balances[utils.MONETARY] != 4.5 {
+			t.Errorf("Unexpected request: %+v", rcv)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Request not received")
+	}
+	a.ExtraParameters = `{"Method":"POST"}`
+	if err := httpPostAction(acc, nil, a, nil); err == nil {
+		t.Error("Expecting error on missing URL")
+	}
+}
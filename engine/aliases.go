@@ -329,20 +329,18 @@ func (am *AliasHandler) GetMatchingAlias(attr *AttrMatchingAlias, result *string
 		return utils.ErrNotFound
 	}
 	// check destination ids
-	for _, p := range utils.SplitPrefix(attr.Destination, MIN_PREFIX_MATCH) {
-		if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-			for _, value := range values {
-				for _, dId := range destIDs {
-					if value.DestinationId == utils.ANY || value.DestinationId == dId {
-						if origAliasMap, ok := value.Pairs[attr.Target]; ok {
-							if alias, ok := origAliasMap[attr.Original]; ok || attr.Original == "" || attr.Original == utils.ANY {
-								*result = alias
-								return nil
-							}
-							if alias, ok := origAliasMap[utils.ANY]; ok {
-								*result = alias
-								return nil
-							}
+	for _, dm := range matchReverseDestinations(dataStorage, attr.Destination) {
+		for _, value := range values {
+			for _, dId := range dm.DestIDs {
+				if value.DestinationId == utils.ANY || value.DestinationId == dId {
+					if origAliasMap, ok := value.Pairs[attr.Target]; ok {
+						if alias, ok := origAliasMap[attr.Original]; ok || attr.Original == "" || attr.Original == utils.ANY {
+							*result = alias
+							return nil
+						}
+						if alias, ok := origAliasMap[utils.ANY]; ok {
+							*result = alias
+							return nil
 						}
 					}
 				}
@@ -380,10 +378,8 @@ func (am *AliasHandler) Call(serviceMethod string, args interface{}, reply inter
 	return err
 }
 
-func LoadAlias(attr *AttrMatchingAlias, in interface{}, extraFields string) error {
-	if aliasService == nil {
-		return nil
-	}
+// matchingAliasPairs returns the alias pairs matching attr, nil if none matching
+func matchingAliasPairs(attr *AttrMatchingAlias) (AliasPairs, error) {
 	response := Alias{}
 	if err := aliasService.Call("AliasesV1.GetAlias", &Alias{
 		Direction: attr.Direction,
@@ -393,7 +389,7 @@ func LoadAlias(attr *AttrMatchingAlias, in interface{}, extraFields string) erro
 		Subject:   attr.Subject,
 		Context:   attr.Context,
 	}, &response); err != nil {
-		return err
+		return nil, err
 	}
 
 	// sort according to weight
@@ -408,28 +404,39 @@ func LoadAlias(attr *AttrMatchingAlias, in interface{}, extraFields string) erro
 
 	if rightPairs == nil {
 		// check destination ids
-		for _, p := range utils.SplitPrefix(attr.Destination, MIN_PREFIX_MATCH) {
-			if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-				for _, value := range values {
-					for _, dId := range destIDs {
-						if value.DestinationId == utils.ANY || value.DestinationId == dId {
-							rightPairs = value.Pairs
-						}
-						if rightPairs != nil {
-							break
-						}
+		for _, dm := range matchReverseDestinations(dataStorage, attr.Destination) {
+			for _, value := range values {
+				for _, dId := range dm.DestIDs {
+					if value.DestinationId == utils.ANY || value.DestinationId == dId {
+						rightPairs = value.Pairs
 					}
 					if rightPairs != nil {
 						break
 					}
 				}
+				if rightPairs != nil {
+					break
+				}
 			}
 			if rightPairs != nil {
 				break
 			}
 		}
 	}
+	return rightPairs, nil
+}
 
+func LoadAlias(attr *AttrMatchingAlias, in interface{}, extraFields string) error {
+	if err := normalizeDestination(attr, in); err != nil {
+		return err
+	}
+	if aliasService == nil {
+		return nil
+	}
+	rightPairs, err := matchingAliasPairs(attr)
+	if err != nil {
+		return err
+	}
 	if rightPairs != nil {
 		// change values in the given object
 		v := reflect.ValueOf(in)
@@ -274,3 +274,37 @@ func TestAliasesCache(t *testing.T) {
 		t.Error("Error getting reverse alias 2: ", ra2)
 	}
 }
+
+func TestAliasesDialPlanNormalization(t *testing.T) {
+	dp := &DialPlan{Tenant: "dialplan.org", Rules: []*DialPlanRule{
+		&DialPlanRule{Prefix: "00", Strip: 2, Weight: 30},
+		&DialPlanRule{Prefix: "+", Strip: 1, Weight: 30},
+		&DialPlanRule{Prefix: "0", Strip: 1, Add: "49", Weight: 20},
+		&DialPlanRule{MaxLength: 8, Add: "4930", Weight: 10},
+	}}
+	for number, eNumber := range map[string]string{
+		"0049 30 1234567": "49301234567",
+		"+1 (555) 12-34":  "15551234",
+		"030-1234567":     "49301234567",
+		"1234567":         "49301234567",
+		"123456789":       "123456789",
+	} {
+		if rcv, _ := dp.Normalize(number); rcv != eNumber {
+			t.Errorf("Number: %s, expecting: %s, received: %s", number, eNumber, rcv)
+		}
+	}
+	if err := dataStorage.SetDialPlan(dp, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	defer dataStorage.RemoveDialPlan(dp.Tenant, utils.NonTransactional)
+	cd := &CallDescriptor{Direction: utils.OUT, Tenant: "dialplan.org", Category: "call",
+		Account: "dan", Subject: "dan", Destination: "0301234567"}
+	attr := &AttrMatchingAlias{Direction: cd.Direction, Tenant: cd.Tenant, Category: cd.Category,
+		Account: cd.Account, Subject: cd.Subject, Context: utils.ALIAS_CONTEXT_RATING, Destination: cd.Destination}
+	if err := LoadAlias(attr, cd, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
+		t.Error(err)
+	}
+	if cd.Destination != "49301234567" || attr.Destination != "49301234567" {
+		t.Errorf("Unexpected destinations, event: %s, alias attributes: %s", cd.Destination, attr.Destination)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+var (
+	expiryNotifyDays    []int  // days before the expiry of new balances when the notification actions are scheduled
+	expiryNotifyActions string // actions executed on the account to warn about the balance expiry
+	schedulerReloader   func() // picks up the action plans created by the engine, nil if the scheduler is not running here
+)
+
+// SetBalanceExpiryNotify sets the ladder of days before the expiry of new balances when the actions are scheduled
+func SetBalanceExpiryNotify(days []int, actionsID string) {
+	expiryNotifyDays = days
+	expiryNotifyActions = actionsID
+}
+
+// SetSchedulerReloader sets the function reloading the scheduler after the engine created action plans
+func SetSchedulerReloader(reload func()) {
+	schedulerReloader = reload
+}
+
+// expiryNotifyPlanID returns the ID of the action plan warning about the expiry of the balance
+func expiryNotifyPlanID(b *Balance) string {
+	return utils.ConcatenatedKey(utils.MetaBalanceExpiry, b.Uuid)
+}
+
+// newExpiryNotifyPlan returns the action plan running the notification actions the configured days before
+// the balance expires, nil if none of them is still in the future
+func newExpiryNotifyPlan(acntID string, b *Balance, now time.Time) *ActionPlan {
+	if expiryNotifyActions == "" || b.ExpirationDate.IsZero() {
+		return nil
+	}
+	apl := &ActionPlan{Id: expiryNotifyPlanID(b), AccountIDs: utils.StringMap{acntID: true}}
+	for _, days := range expiryNotifyDays {
+		notifyTime := b.ExpirationDate.AddDate(0, 0, -days).Local() // the scheduler works in local time
+		if !notifyTime.After(now) {
+			continue
+		}
+		apl.ActionTimings = append(apl.ActionTimings, &ActionTiming{
+			Uuid: utils.GenUUID(),
+			Timing: &RateInterval{Timing: &RITiming{
+				Years:     utils.Years{notifyTime.Year()},
+				Months:    utils.Months{notifyTime.Month()},
+				MonthDays: utils.MonthDays{notifyTime.Day()},
+				StartTime: notifyTime.Format("15:04:05"),
+			}},
+			ActionsID: expiryNotifyActions,
+			Weight:    float64(days), // the earlier notification first if more are due at once
+		})
+	}
+	if len(apl.ActionTimings) == 0 {
+		return nil
+	}
+	return apl
+}
+
+// scheduleExpiryNotifications stores the action plan warning about the expiry of a new balance
+func scheduleExpiryNotifications(acntID string, b *Balance) {
+	apl := newExpiryNotifyPlan(acntID, b, time.Now())
+	if apl == nil {
+		return
+	}
+	_, err := guardian.Guardian.Guard(func() (interface{}, error) {
+		if err := dataStorage.SetActionPlan(apl.Id, apl, true, utils.NonTransactional); err != nil {
+			return 0, err
+		}
+		if err := dataStorage.SetAccountActionPlans(acntID, []string{apl.Id}, false); err != nil {
+			return 0, err
+		}
+		if err := dataStorage.CacheDataFromDB(utils.ACTION_PLAN_PREFIX, []string{apl.Id}, true); err != nil {
+			return 0, err
+		}
+		return 0, dataStorage.CacheDataFromDB(utils.AccountActionPlansPrefix, []string{acntID}, true)
+	}, 0, utils.ACTION_PLAN_PREFIX+apl.Id)
+	if err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<BalanceExpiry> cannot schedule notifications for balance %s of account %s: %v", b.Uuid, acntID, err))
+		return
+	}
+	if schedulerReloader != nil {
+		go schedulerReloader()
+	}
+}
@@ -19,6 +19,7 @@ package engine
 
 import (
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/cgrates/cgrates/utils"
@@ -41,6 +42,7 @@ type BalanceFilter struct {
 	Disabled       *bool
 	Factor         *ValueFactor
 	Blocker        *bool
+	Unit           *string
 }
 
 func (bp *BalanceFilter) CreateBalance() *Balance {
@@ -60,6 +62,7 @@ func (bp *BalanceFilter) CreateBalance() *Balance {
 		Disabled:       bp.GetDisabled(),
 		Factor:         bp.GetFactor(),
 		Blocker:        bp.GetBlocker(),
+		Unit:           bp.GetUnit(),
 	}
 	return b.Clone()
 }
@@ -86,6 +89,10 @@ func (bf *BalanceFilter) Clone() *BalanceFilter {
 		result.Type = new(string)
 		*result.Type = *bf.Type
 	}
+	if bf.Unit != nil {
+		result.Unit = new(string)
+		*result.Unit = *bf.Unit
+	}
 	if bf.ExpirationDate != nil {
 		result.ExpirationDate = new(time.Time)
 		*result.ExpirationDate = *bf.ExpirationDate
@@ -168,6 +175,9 @@ func (bf *BalanceFilter) LoadFromBalance(b *Balance) *BalanceFilter {
 	if b.Blocker {
 		bf.Blocker = &b.Blocker
 	}
+	if b.Unit != "" {
+		bf.Unit = &b.Unit
+	}
 	bf.Timings = b.Timings
 	return bf
 }
@@ -299,6 +309,26 @@ func (bp *BalanceFilter) GetFactor() ValueFactor {
 	return *bp.Factor
 }
 
+func (bp *BalanceFilter) GetUnit() string {
+	if bp == nil || bp.Unit == nil {
+		return ""
+	}
+	return *bp.Unit
+}
+
+// SetUnits parses the balance value optionally followed by the usage unit it is given in (eg: 100;MB)
+func (bp *BalanceFilter) SetUnits(units string) (err error) {
+	if idx := strings.LastIndex(units, utils.INFIELD_SEP); idx != -1 {
+		if _, err = utils.UsageUnitFactor(units[idx+1:]); err != nil {
+			return
+		}
+		bp.Unit = utils.StringPointer(units[idx+1:])
+		units = units[:idx]
+	}
+	bp.Value, err = utils.ParseBalanceFilterValue(units)
+	return
+}
+
 func (bp *BalanceFilter) EmptyExpirationDate() bool {
 	if bp.ExpirationDate == nil {
 		return true
@@ -317,6 +347,7 @@ func (bf *BalanceFilter) ModifyBalance(b *Balance) {
 		b.Directions = *bf.Directions
 	}
 	if bf.Value != nil {
+		b.valueDelta += bf.GetValue() - b.Value
 		b.Value = bf.GetValue()
 	}
 	if bf.ExpirationDate != nil {
@@ -346,5 +377,8 @@ func (bf *BalanceFilter) ModifyBalance(b *Balance) {
 	if bf.Disabled != nil {
 		b.Disabled = *bf.Disabled
 	}
+	if bf.Unit != nil {
+		b.Unit = *bf.Unit
+	}
 	b.SetDirty() // Mark the balance as dirty since we have modified and it should be checked by action triggers
 }
@@ -0,0 +1,197 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	balanceNotifyWorkers   = 4    // concurrent notifications, the ones of an account being always sent by the same worker
+	balanceNotifyQueueSize = 1000 // notifications waiting for each worker before being dropped
+)
+
+var (
+	balanceNotifier        BalanceNotifier
+	balanceNotifyQueues    []chan *balanceNotifyRequest
+	balanceNotifyQueueOnce sync.Once
+)
+
+// balanceNotifyRequest keeps the notifier set at publish time so the queued notifications survive SetBalanceNotifier
+type balanceNotifyRequest struct {
+	notifier BalanceNotifier
+	bn       *BalanceNotification
+}
+
+// SetBalanceNotifier sets the notifier receiving every balance mutation, nil disables notifications
+func SetBalanceNotifier(bn BalanceNotifier) {
+	balanceNotifier = bn
+}
+
+// BalanceNotification is the compact event published whenever a balance value changes
+type BalanceNotification struct {
+	Account     string
+	BalanceType string
+	BalanceUUID string
+	BalanceID   string
+	Delta       float64 // value change since the previous notification
+	Value       float64 // resulting balance value
+	Reason      string  // what triggered the change, eg: *debit, *refund, *actions:TOPUP_10
+	Time        time.Time
+}
+
+// BalanceNotifier publishes balance notifications towards an external system
+type BalanceNotifier interface {
+	NotifyBalance(*BalanceNotification) error
+}
+
+// NewBalancePoster creates a BalanceNotifier posting JSON towards address,
+// amqp:// addresses are published into a queue, nats:// ones on a subject, anything else is considered HTTP
+func NewBalancePoster(address string, attempts int, fallbackDir string, httpPoster *utils.HTTPPoster) *BalancePoster {
+	return &BalancePoster{newJSONPoster(address, attempts, fallbackDir, httpPoster)}
+}
+
+// BalancePoster is the BalanceNotifier implementation based on the CDR export posters
+type BalancePoster struct {
+	*jsonPoster
+}
+
+func (bp *BalancePoster) NotifyBalance(bn *BalanceNotification) (err error) {
+	body, err := json.Marshal(bn)
+	if err != nil {
+		return
+	}
+	return bp.post(utils.BalancesPoster, body)
+}
+
+func newJSONPoster(address string, attempts int, fallbackDir string, httpPoster *utils.HTTPPoster) *jsonPoster {
+	transport := utils.MetaHTTPjson
+	if strings.HasPrefix(address, "amqp://") {
+		transport = utils.MetaAMQPjsonMap
+	} else if strings.HasPrefix(address, "nats://") {
+		transport = utils.MetaNATSjsonMap
+	}
+	return &jsonPoster{address: address, transport: transport, attempts: attempts,
+		fallbackDir: fallbackDir, httpPoster: httpPoster}
+}
+
+// jsonPoster posts JSON bodies over the transport deduced out of the address
+type jsonPoster struct {
+	address     string
+	transport   string
+	attempts    int
+	fallbackDir string
+	httpPoster  *utils.HTTPPoster
+}
+
+// post sends body, module identifying the failed posts to be replayed
+func (jp *jsonPoster) post(module string, body []byte) (err error) {
+	ffn := &utils.FallbackFileName{Module: module, Transport: jp.transport,
+		Address: jp.address, RequestID: utils.GenUUID()}
+	fallbackFileName := ffn.AsString()
+	switch jp.transport {
+	case utils.MetaAMQPjsonMap:
+		var amqpPoster *utils.AMQPPoster
+		if amqpPoster, err = utils.AMQPPostersCache.GetAMQPPoster(jp.address, jp.attempts, jp.fallbackDir); err != nil {
+			return
+		}
+		chn, err := amqpPoster.Post(nil, utils.CONTENT_JSON, body, fallbackFileName)
+		if chn != nil {
+			chn.Close()
+		}
+		return err
+	case utils.MetaNATSjsonMap:
+		var natsPoster *utils.NATSPoster
+		if natsPoster, err = utils.NATSPostersCache.GetNATSPoster(jp.address, jp.attempts, jp.fallbackDir); err != nil {
+			return
+		}
+		return natsPoster.Post(body, fallbackFileName)
+	default:
+		fallbackPath := utils.META_NONE
+		if jp.fallbackDir != utils.META_NONE {
+			fallbackPath = path.Join(jp.fallbackDir, fallbackFileName)
+		}
+		_, err = jp.httpPoster.Post(jp.address, utils.CONTENT_JSON, body, jp.attempts, fallbackPath)
+	}
+	return
+}
+
+// publishBalanceChanges notifies the value changes accumulated on the account balances since the last call
+func (acc *Account) publishBalanceChanges(reason string) {
+	if replicaFeeder != nil {
+		replicaFeeder.QueueAccount(acc)
+	}
+	now := time.Now()
+	for balanceType, balances := range acc.BalanceMap {
+		for _, b := range balances {
+			if b.valueDelta == 0 {
+				continue
+			}
+			bn := &BalanceNotification{
+				Account:     acc.ID,
+				BalanceType: balanceType,
+				BalanceUUID: b.Uuid,
+				BalanceID:   b.ID,
+				Delta:       utils.Round(b.valueDelta, globalRoundingDecimals, utils.ROUNDING_MIDDLE),
+				Value:       b.Value,
+				Reason:      reason,
+				Time:        now,
+			}
+			b.valueDelta = 0
+			if balanceNotifier == nil {
+				continue
+			}
+			queueBalanceNotification(&balanceNotifyRequest{notifier: balanceNotifier, bn: bn})
+		}
+	}
+}
+
+// queueBalanceNotification hands req to the worker of its account, preserving the order of the account notifications
+func queueBalanceNotification(req *balanceNotifyRequest) {
+	balanceNotifyQueueOnce.Do(startBalanceNotifyWorkers)
+	h := fnv.New32a()
+	h.Write([]byte(req.bn.Account))
+	select {
+	case balanceNotifyQueues[h.Sum32()%balanceNotifyWorkers] <- req:
+	default:
+		utils.Logger.Warning(fmt.Sprintf("<BalanceNotifier> Queue full, dropping balance change for account <%s>, balance <%s>",
+			req.bn.Account, req.bn.BalanceUUID))
+	}
+}
+
+func startBalanceNotifyWorkers() {
+	balanceNotifyQueues = make([]chan *balanceNotifyRequest, balanceNotifyWorkers)
+	for i := range balanceNotifyQueues {
+		balanceNotifyQueues[i] = make(chan *balanceNotifyRequest, balanceNotifyQueueSize)
+		go func(queue chan *balanceNotifyRequest) {
+			for req := range queue {
+				if err := req.notifier.NotifyBalance(req.bn); err != nil {
+					utils.Logger.Warning(fmt.Sprintf("<BalanceNotifier> Failed notifying balance change for account <%s>, error: %s", req.bn.Account, err.Error()))
+				}
+			}
+		}(balanceNotifyQueues[i])
+	}
+}
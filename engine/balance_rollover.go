@@ -0,0 +1,133 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// setRolloverAction sets on the balance with BalanceId the rollover policy in ExtraParameters,
+// eg: {"Policy":"*capped","Period":"*monthly","Cap":300}
+func (acc *Account) setRolloverAction(a *Action) error {
+	if a == nil || a.Balance == nil {
+		return errors.New("nil action")
+	}
+	if a.Balance.GetID() == "" {
+		return errors.New("missing balance id")
+	}
+	var rollover struct {
+		Policy  string
+		Period  string
+		Cap     float64
+		Periods int
+	}
+	if err := json.Unmarshal([]byte(a.ExtraParameters), &rollover); err != nil {
+		return err
+	}
+	if rollover.Period == "" {
+		rollover.Period = utils.MetaMonthly
+	}
+	periodStart, err := utils.GetPeriodStart(rollover.Period, time.Now())
+	if err != nil {
+		return err
+	}
+	switch rollover.Policy {
+	case "", utils.META_NONE, utils.MetaRolloverFull:
+	case utils.MetaRolloverCapped:
+		if rollover.Cap <= 0 {
+			return fmt.Errorf("invalid rollover cap: %v", rollover.Cap)
+		}
+	case utils.MetaRolloverExpiring:
+		if rollover.Periods < 1 {
+			return fmt.Errorf("invalid rollover periods: %d", rollover.Periods)
+		}
+	default:
+		return fmt.Errorf("unsupported rollover policy: %s", rollover.Policy)
+	}
+	for _, balances := range acc.BalanceMap {
+		for _, b := range balances {
+			if b.ID == a.Balance.GetID() && !b.IsExpired() {
+				b.RolloverPolicy = rollover.Policy
+				b.RolloverPeriod = rollover.Period
+				b.RolloverCap = rollover.Cap
+				b.RolloverPeriods = rollover.Periods
+				b.RolloverReset = periodStart // the current period is rolled over at its end
+				b.SetDirty()
+				return nil
+			}
+		}
+	}
+	return utils.ErrNotFound
+}
+
+// rolloverBalances applies the rollover policies of the balances whose period ended
+func (acc *Account) rolloverBalances(now time.Time) {
+	for balanceType, balances := range acc.BalanceMap {
+		for _, b := range balances {
+			if rolled := b.rollover(now); rolled != nil {
+				acc.BalanceMap[balanceType] = append(acc.BalanceMap[balanceType], rolled)
+			}
+		}
+	}
+}
+
+// rollover moves the value left unused at the end of the rollover period into a new balance, according to the policy,
+// returning it or nil if nothing was carried
+func (b *Balance) rollover(now time.Time) (rolled *Balance) {
+	if b.RolloverPolicy == "" || b.RolloverPolicy == utils.META_NONE {
+		return
+	}
+	periodStart, err := utils.GetPeriodStart(b.RolloverPeriod, now)
+	if err != nil || !b.RolloverReset.Before(periodStart) {
+		return
+	}
+	b.RolloverReset = periodStart
+	b.SetDirty()
+	if b.Disabled || b.IsExpired() || b.Value <= 0 {
+		return
+	}
+	carried := b.Value
+	if b.RolloverPolicy == utils.MetaRolloverCapped && carried > b.RolloverCap {
+		carried = b.RolloverCap
+	}
+	rolled = b.Clone()
+	rolled.Uuid = utils.GenUUID()
+	rolled.ID = fmt.Sprintf("%s_rollover_%s", b.ID, periodStart.Format("20060102"))
+	rolled.Value = carried
+	rolled.Weight = b.Weight + 1 // carried units used before the new grant
+	rolled.QuotaValue, rolled.QuotaPeriod, rolled.QuotaReset = 0, "", time.Time{}
+	rolled.DripValue, rolled.DripCycle, rolled.DripStep, rolled.DripReleased = 0, "", "", time.Time{}
+	rolled.RolloverPolicy, rolled.RolloverPeriod, rolled.RolloverCap, rolled.RolloverPeriods, rolled.RolloverReset = "", "", 0, 0, time.Time{}
+	if b.RolloverPolicy == utils.MetaRolloverExpiring {
+		expiry := periodStart
+		for i := 0; i < b.RolloverPeriods; i++ {
+			expiry, _ = utils.AddPeriod(b.RolloverPeriod, expiry)
+		}
+		rolled.ExpirationDate = expiry
+	}
+	rolled.valueDelta = carried
+	rolled.SetDirty()
+	b.valueDelta -= b.Value
+	b.Value = 0
+	return
+}
@@ -20,6 +20,7 @@ package engine
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,24 +31,44 @@ import (
 
 // Can hold different units as seconds or monetary
 type Balance struct {
-	Uuid           string //system wide unique
-	ID             string // account wide unique
-	Value          float64
-	Directions     utils.StringMap
-	ExpirationDate time.Time
-	Weight         float64
-	DestinationIDs utils.StringMap
-	RatingSubject  string
-	Categories     utils.StringMap
-	SharedGroups   utils.StringMap
-	Timings        []*RITiming
-	TimingIDs      utils.StringMap
-	Disabled       bool
-	Factor         ValueFactor
-	Blocker        bool
-	precision      int
-	account        *Account // used to store ub reference for shared balances
-	dirty          bool
+	Uuid            string //system wide unique
+	ID              string // account wide unique
+	Value           float64
+	Directions      utils.StringMap
+	ExpirationDate  time.Time
+	Weight          float64
+	DestinationIDs  utils.StringMap
+	RatingSubject   string
+	Categories      utils.StringMap
+	SharedGroups    utils.StringMap
+	Timings         []*RITiming
+	TimingIDs       utils.StringMap
+	Disabled        bool
+	Factor          ValueFactor
+	Blocker         bool
+	QuotaValue      float64            // value restored at the beginning of each QuotaPeriod
+	QuotaPeriod     string             // *hourly, *daily, *weekly, *monthly or *yearly, empty if the balance has no quota
+	QuotaReset      time.Time          // beginning of the current quota period
+	TORPriorities   map[string]float64 // overrides Weight when ordering the balances debited for a ToR
+	TORReservations map[string]float64 // units kept for a ToR, not available when debiting the others
+	Currency        string             // currency of a *monetary balance, empty for the default one
+	Unit            string             // usage unit the value is given in (eg: MB), empty for the base units
+	DripValue       float64            // grant released gradually over each DripCycle, 0 if the balance is not dripped
+	DripCycle       string             // period of the grant, the value not used until its end is dropped
+	DripStep        string             // period of the equal parts the grant is released in
+	DripReleased    time.Time          // last time the drip was released
+	RolloverPolicy  string             // what is carried out of the unused value at the end of each RolloverPeriod: <""|*none|*full|*capped|*expiring>
+	RolloverPeriod  string             // period the unused value is rolled over at the end of
+	RolloverCap     float64            // most units carried each period by *capped
+	RolloverPeriods int                // periods the units carried by *expiring stay usable
+	RolloverReset   time.Time          // beginning of the period the balance was last rolled over in
+	precision       int
+	torWeight       *float64 // Weight override for the ToR being debited
+	account         *Account // used to store ub reference for shared balances
+	heldValue       float64  // value held back from the debiting child account by its parent cap
+	childValue      float64  // value left to the debiting child account
+	dirty           bool
+	valueDelta      float64 // value change not yet published to the balance notifier
 }
 
 func (b *Balance) Equal(o *Balance) bool {
@@ -67,7 +88,8 @@ func (b *Balance) Equal(o *Balance) bool {
 		b.Categories.Equal(o.Categories) &&
 		b.SharedGroups.Equal(o.SharedGroups) &&
 		b.Disabled == o.Disabled &&
-		b.Blocker == o.Blocker
+		b.Blocker == o.Blocker &&
+		b.Unit == o.Unit
 }
 
 func (b *Balance) MatchFilter(o *BalanceFilter, skipIds bool) bool {
@@ -89,7 +111,8 @@ func (b *Balance) MatchFilter(o *BalanceFilter, skipIds bool) bool {
 		(o.Categories == nil || b.Categories.Includes(*o.Categories)) &&
 		(o.TimingIDs == nil || b.TimingIDs.Includes(*o.TimingIDs)) &&
 		(o.SharedGroups == nil || b.SharedGroups.Includes(*o.SharedGroups)) &&
-		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject)
+		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject) &&
+		(o.Unit == nil || b.Unit == *o.Unit)
 }
 
 func (b *Balance) HardMatchFilter(o *BalanceFilter, skipIds bool) bool {
@@ -111,7 +134,8 @@ func (b *Balance) HardMatchFilter(o *BalanceFilter, skipIds bool) bool {
 		(o.Categories == nil || b.Categories.Equal(*o.Categories)) &&
 		(o.TimingIDs == nil || b.TimingIDs.Equal(*o.TimingIDs)) &&
 		(o.SharedGroups == nil || b.SharedGroups.Equal(*o.SharedGroups)) &&
-		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject)
+		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject) &&
+		(o.Unit == nil || b.Unit == *o.Unit)
 }
 
 // the default balance has standard Id
@@ -168,19 +192,45 @@ func (b *Balance) Clone() *Balance {
 		return nil
 	}
 	n := &Balance{
-		Uuid:           b.Uuid,
-		ID:             b.ID,
-		Value:          b.Value, // this value is in seconds
-		ExpirationDate: b.ExpirationDate,
-		Weight:         b.Weight,
-		RatingSubject:  b.RatingSubject,
-		Categories:     b.Categories,
-		SharedGroups:   b.SharedGroups,
-		TimingIDs:      b.TimingIDs,
-		Timings:        b.Timings, // should not be a problem with aliasing
-		Blocker:        b.Blocker,
-		Disabled:       b.Disabled,
-		dirty:          b.dirty,
+		Uuid:            b.Uuid,
+		ID:              b.ID,
+		Value:           b.Value, // this value is in seconds
+		ExpirationDate:  b.ExpirationDate,
+		Weight:          b.Weight,
+		RatingSubject:   b.RatingSubject,
+		Categories:      b.Categories,
+		SharedGroups:    b.SharedGroups,
+		TimingIDs:       b.TimingIDs,
+		Timings:         b.Timings, // should not be a problem with aliasing
+		Blocker:         b.Blocker,
+		Disabled:        b.Disabled,
+		QuotaValue:      b.QuotaValue,
+		QuotaPeriod:     b.QuotaPeriod,
+		QuotaReset:      b.QuotaReset,
+		Currency:        b.Currency,
+		Unit:            b.Unit,
+		DripValue:       b.DripValue,
+		DripCycle:       b.DripCycle,
+		DripStep:        b.DripStep,
+		DripReleased:    b.DripReleased,
+		RolloverPolicy:  b.RolloverPolicy,
+		RolloverPeriod:  b.RolloverPeriod,
+		RolloverCap:     b.RolloverCap,
+		RolloverPeriods: b.RolloverPeriods,
+		RolloverReset:   b.RolloverReset,
+		dirty:           b.dirty,
+	}
+	if b.TORPriorities != nil {
+		n.TORPriorities = make(map[string]float64, len(b.TORPriorities))
+		for tor, w := range b.TORPriorities {
+			n.TORPriorities[tor] = w
+		}
+	}
+	if b.TORReservations != nil {
+		n.TORReservations = make(map[string]float64, len(b.TORReservations))
+		for tor, units := range b.TORReservations {
+			n.TORReservations[tor] = units
+		}
 	}
 	if b.DestinationIDs != nil {
 		n.DestinationIDs = b.DestinationIDs.Clone()
@@ -191,14 +241,73 @@ func (b *Balance) Clone() *Balance {
 	return n
 }
 
+// resetQuota restores the balance value to its quota once a new quota period started
+func (b *Balance) resetQuota(now time.Time) {
+	if b.QuotaPeriod == "" {
+		return
+	}
+	periodStart, err := utils.GetPeriodStart(b.QuotaPeriod, now)
+	if err != nil || !b.QuotaReset.Before(periodStart) {
+		return
+	}
+	b.valueDelta += b.QuotaValue - b.Value
+	b.Value = b.QuotaValue
+	b.QuotaReset = periodStart
+	b.SetDirty()
+}
+
+// dripSteps returns the steps of the cycle starting at cycleStart already started at t and their total
+func dripSteps(cycleStart time.Time, cycle, step string, t time.Time) (started, total int, err error) {
+	cycleEnd, err := utils.AddPeriod(cycle, cycleStart)
+	if err != nil {
+		return
+	}
+	for stepStart := cycleStart; stepStart.Before(cycleEnd); {
+		total++
+		if !stepStart.After(t) {
+			started++
+		}
+		if stepStart, err = utils.AddPeriod(step, stepStart); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// releaseDrip adds the parts of the drip grant released since the last call, a new cycle starts over from zero
+func (b *Balance) releaseDrip(now time.Time) {
+	if b.DripCycle == "" {
+		return
+	}
+	cycleStart, err := utils.GetPeriodStart(b.DripCycle, now)
+	if err != nil {
+		return
+	}
+	started, total, err := dripSteps(cycleStart, b.DripCycle, b.DripStep, now)
+	if err != nil || total == 0 {
+		return
+	}
+	var released int
+	if b.DripReleased.Before(cycleStart) {
+		b.valueDelta -= b.Value
+		b.Value = 0
+	} else if released, _, _ = dripSteps(cycleStart, b.DripCycle, b.DripStep, b.DripReleased); released == started {
+		return
+	}
+	value := utils.Round(b.DripValue*float64(started)/float64(total), globalRoundingDecimals, utils.ROUNDING_MIDDLE) -
+		utils.Round(b.DripValue*float64(released)/float64(total), globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	b.Value += value
+	b.valueDelta += value
+	b.DripReleased = now
+	b.SetDirty()
+}
+
 func (b *Balance) getMatchingPrefixAndDestID(dest string) (prefix, destId string) {
 	if len(b.DestinationIDs) != 0 && b.DestinationIDs[utils.ANY] == false {
-		for _, p := range utils.SplitPrefix(dest, MIN_PREFIX_MATCH) {
-			if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-				for _, dID := range destIDs {
-					if b.DestinationIDs[dID] == true {
-						return p, dID
-					}
+		for _, dm := range matchReverseDestinations(dataStorage, dest) {
+			for _, dID := range dm.DestIDs {
+				if b.DestinationIDs[dID] == true {
+					return utils.DestinationKeyPrefix(dm.Prefix), dID
 				}
 			}
 		}
@@ -209,7 +318,7 @@ func (b *Balance) getMatchingPrefixAndDestID(dest string) (prefix, destId string
 // Returns the available number of seconds for a specified credit
 func (b *Balance) GetMinutesForCredit(origCD *CallDescriptor, initialCredit float64) (duration time.Duration, credit float64) {
 	cd := origCD.Clone()
-	availableDuration := time.Duration(b.GetValue()) * time.Second
+	availableDuration := b.availableUsage(origCD.TOR)
 	duration = availableDuration
 	credit = initialCredit
 	cc, err := b.GetCost(cd, false)
@@ -293,17 +402,38 @@ func (b *Balance) SubstractValue(amount float64) {
 }
 
 func (b *Balance) SetValue(amount float64) {
+	prevValue := b.Value
 	b.Value = amount
 	b.Value = utils.Round(b.GetValue(), globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	b.valueDelta += b.Value - prevValue
 	b.dirty = true
 }
 
+// availableValue returns the value usable by tor, leaving aside the units reserved for other ToRs
+func (b *Balance) availableValue(tor string) float64 {
+	value := b.GetValue()
+	for rsrvTOR, units := range b.TORReservations {
+		if rsrvTOR != tor {
+			value -= units
+		}
+	}
+	return value
+}
+
+// sortWeight returns the weight used when ordering balances
+func (b *Balance) sortWeight() float64 {
+	if b.torWeight != nil {
+		return *b.torWeight
+	}
+	return b.Weight
+}
+
 func (b *Balance) SetDirty() {
 	b.dirty = true
 }
 
 func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Balances, count bool, dryRun, debitConnectFee bool) (cc *CallCost, err error) {
-	if !b.IsActiveAt(cd.TimeStart) || b.GetValue() <= 0 {
+	if !b.IsActiveAt(cd.TimeStart) || b.availableValue(cd.TOR) <= 0 {
 		return
 	}
 	if duration, err := utils.ParseZeroRatingSubject(b.RatingSubject); err == nil {
@@ -346,11 +476,8 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 		for incIndex, inc := range ts.Increments {
 			//log.Printf("INCREMENET: %+v", inc)
 
-			amount := inc.Duration.Seconds()
-			if b.Factor != nil {
-				amount = utils.Round(amount/b.Factor.GetValue(cd.TOR), globalRoundingDecimals, utils.ROUNDING_UP)
-			}
-			if b.GetValue() >= amount {
+			amount := b.usageAmount(inc.Duration, cd.TOR)
+			if b.availableValue(cd.TOR) >= amount {
 				b.SubstractValue(amount)
 				inc.BalanceInfo.Unit = &UnitInfo{
 					UUID:          b.Uuid,
@@ -393,7 +520,9 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 		}
 		if debitConnectFee {
 			// this is the first add, debit the connect fee
-			if ok, debitedConnectFeeBalance = ub.DebitConnectionFee(cc, moneyBalances, count, true); !ok {
+			if ok, debitedConnectFeeBalance, err = ub.DebitConnectionFee(cc, moneyBalances, count, true); err != nil {
+				return nil, err
+			} else if !ok {
 				// found blocker balance
 				return nil, nil
 			}
@@ -412,15 +541,16 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 			}
 
 			if tsIndex == 0 && ts.RateInterval.Rating.ConnectFee > 0 && debitConnectFee && cc.deductConnectFee && ok {
-
+				_, cfExRate, _ := debitedConnectFeeBalance.convertCost(ts.RateInterval.Rating.ConnectFee, ts.RateInterval.Rating.Currency)
 				inc := &Increment{
 					Duration: 0,
 					Cost:     ts.RateInterval.Rating.ConnectFee,
 					BalanceInfo: &DebitInfo{
 						Monetary: &MonetaryInfo{
-							UUID:  debitedConnectFeeBalance.Uuid,
-							ID:    debitedConnectFeeBalance.ID,
-							Value: debitedConnectFeeBalance.Value,
+							UUID:         debitedConnectFeeBalance.Uuid,
+							ID:           debitedConnectFeeBalance.ID,
+							Value:        debitedConnectFeeBalance.Value,
+							ExchangeRate: cfExRate,
 						},
 						AccountID: ub.ID,
 					},
@@ -430,7 +560,10 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 				ts.Increments = append(incs, ts.Increments...)
 			}
 
-			maxCost, strategy := ts.RateInterval.GetMaxCost()
+			maxCost, strategy := ts.getMaxCost()
+			if tsIndex == 0 && strategy == utils.MAX_COST_CAP && ts.RateInterval.Rating.ConnectFee > 0 && debitConnectFee && cc.deductConnectFee && ok {
+				cd.MaxCostSoFar += ts.RateInterval.Rating.ConnectFee // the connect fee counts towards the cap
+			}
 			for incIndex, inc := range ts.Increments {
 
 				if tsIndex == 0 && incIndex == 0 && ts.RateInterval.Rating.ConnectFee > 0 && debitConnectFee && cc.deductConnectFee && ok {
@@ -439,9 +572,9 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 				}
 
 				// debit minutes and money
-				amount := inc.Duration.Seconds()
-				if b.Factor != nil {
-					amount = utils.Round(amount/b.Factor.GetValue(cd.TOR), globalRoundingDecimals, utils.ROUNDING_UP)
+				amount := b.usageAmount(inc.Duration, cd.TOR)
+				if strategy == utils.MAX_COST_CAP && cd.MaxCostSoFar < maxCost && cd.MaxCostSoFar+inc.Cost > maxCost {
+					inc.Cost = maxCost - cd.MaxCostSoFar // charge only up to the cap
 				}
 				cost := inc.Cost
 				inc.paid = false
@@ -459,7 +592,7 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 						return cc, nil
 					}
 				}
-				if strategy == utils.MAX_COST_FREE && cd.MaxCostSoFar >= maxCost {
+				if (strategy == utils.MAX_COST_FREE || strategy == utils.MAX_COST_CAP) && cd.MaxCostSoFar >= maxCost {
 					cost, inc.Cost = 0.0, 0.0
 					inc.BalanceInfo.Monetary = &MonetaryInfo{
 						UUID:         b.Uuid,
@@ -476,13 +609,18 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 					continue
 				}
 				var moneyBal *Balance
+				var moneyAmount, exRate float64
 				for _, mb := range moneyBalances {
-					if mb.GetValue() >= cost {
-						moneyBal = mb
+					mbAmount, mbExRate, err := mb.convertCost(cost, ts.RateInterval.Rating.Currency)
+					if err != nil { // no exchange rate towards the balance currency
+						continue
+					}
+					if mb.availableValue(cd.TOR) >= mbAmount {
+						moneyBal, moneyAmount, exRate = mb, mbAmount, mbExRate
 						break
 					}
 				}
-				if (cost == 0 || moneyBal != nil) && b.GetValue() >= amount {
+				if (cost == 0 || moneyBal != nil) && b.availableValue(cd.TOR) >= amount {
 					b.SubstractValue(amount)
 					inc.BalanceInfo.Unit = &UnitInfo{
 						UUID:          b.Uuid,
@@ -495,11 +633,12 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 					}
 					inc.BalanceInfo.AccountID = ub.ID
 					if cost != 0 {
-						moneyBal.SubstractValue(cost)
+						moneyBal.SubstractValue(moneyAmount)
 						inc.BalanceInfo.Monetary = &MonetaryInfo{
-							UUID:  moneyBal.Uuid,
-							ID:    moneyBal.ID,
-							Value: moneyBal.Value,
+							UUID:         moneyBal.Uuid,
+							ID:           moneyBal.ID,
+							Value:        moneyBal.Value,
+							ExchangeRate: exRate,
 						}
 						cd.MaxCostSoFar += cost
 					}
@@ -507,7 +646,7 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 					if count {
 						ub.countUnits(amount, cc.TOR, cc, b)
 						if cost != 0 {
-							ub.countUnits(cost, utils.MONETARY, cc, moneyBal)
+							ub.countUnits(moneyAmount, utils.MONETARY, cc, moneyBal)
 						}
 					}
 				} else {
@@ -532,7 +671,7 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 }
 
 func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Balances, count bool, dryRun, debitConnectFee bool) (cc *CallCost, err error) {
-	if !b.IsActiveAt(cd.TimeStart) || b.GetValue() <= 0 {
+	if !b.IsActiveAt(cd.TimeStart) || b.availableValue(cd.TOR) <= 0 {
 		return
 	}
 	//log.Print("B: ", utils.ToJSON(b))
@@ -548,7 +687,9 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 	if debitConnectFee {
 
 		// this is the first add, debit the connect fee
-		if ok, debitedConnectFeeBalance = ub.DebitConnectionFee(cc, moneyBalances, count, true); !ok {
+		if ok, debitedConnectFeeBalance, err = ub.DebitConnectionFee(cc, moneyBalances, count, true); err != nil {
+			return nil, err
+		} else if !ok {
 			// balance is blocker
 			return nil, nil
 		}
@@ -570,15 +711,16 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 		}
 
 		if tsIndex == 0 && ts.RateInterval.Rating.ConnectFee > 0 && debitConnectFee && cc.deductConnectFee && ok {
-
+			_, cfExRate, _ := debitedConnectFeeBalance.convertCost(ts.RateInterval.Rating.ConnectFee, ts.RateInterval.Rating.Currency)
 			inc := &Increment{
 				Duration: 0,
 				Cost:     ts.RateInterval.Rating.ConnectFee,
 				BalanceInfo: &DebitInfo{
 					Monetary: &MonetaryInfo{
-						UUID:  debitedConnectFeeBalance.Uuid,
-						ID:    debitedConnectFeeBalance.ID,
-						Value: debitedConnectFeeBalance.Value,
+						UUID:         debitedConnectFeeBalance.Uuid,
+						ID:           debitedConnectFeeBalance.ID,
+						Value:        debitedConnectFeeBalance.Value,
+						ExchangeRate: cfExRate,
 					},
 					AccountID: ub.ID,
 				},
@@ -588,7 +730,10 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 			ts.Increments = append(incs, ts.Increments...)
 		}
 
-		maxCost, strategy := ts.RateInterval.GetMaxCost()
+		maxCost, strategy := ts.getMaxCost()
+		if tsIndex == 0 && strategy == utils.MAX_COST_CAP && ts.RateInterval.Rating.ConnectFee > 0 && debitConnectFee && cc.deductConnectFee && ok {
+			cd.MaxCostSoFar += ts.RateInterval.Rating.ConnectFee // the connect fee counts towards the cap
+		}
 		//log.Printf("Timing: %+v", ts.RateInterval.Timing)
 		//log.Printf("Rate: %+v", ts.RateInterval.Rating)
 		for incIndex, inc := range ts.Increments {
@@ -600,7 +745,13 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 				continue
 			}
 
-			amount := inc.Cost
+			if strategy == utils.MAX_COST_CAP && cd.MaxCostSoFar < maxCost && cd.MaxCostSoFar+inc.Cost > maxCost {
+				inc.Cost = maxCost - cd.MaxCostSoFar // charge only up to the cap
+			}
+			amount, exRate, err := b.convertCost(inc.Cost, ts.RateInterval.Rating.Currency)
+			if err != nil {
+				return nil, err
+			}
 			inc.paid = false
 			if strategy == utils.MAX_COST_DISCONNECT && cd.MaxCostSoFar >= maxCost {
 				// cat the entire current timespan
@@ -616,7 +767,7 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 					return cc, nil
 				}
 			}
-			if strategy == utils.MAX_COST_FREE && cd.MaxCostSoFar >= maxCost {
+			if (strategy == utils.MAX_COST_FREE || strategy == utils.MAX_COST_CAP) && cd.MaxCostSoFar >= maxCost {
 				amount, inc.Cost = 0.0, 0.0
 				inc.BalanceInfo.Monetary = &MonetaryInfo{
 					UUID:  b.Uuid,
@@ -637,13 +788,14 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 				continue
 			}
 
-			if b.GetValue() >= amount {
+			if b.availableValue(cd.TOR) >= amount {
 				b.SubstractValue(amount)
-				cd.MaxCostSoFar += amount
+				cd.MaxCostSoFar += inc.Cost
 				inc.BalanceInfo.Monetary = &MonetaryInfo{
-					UUID:  b.Uuid,
-					ID:    b.ID,
-					Value: b.Value,
+					UUID:         b.Uuid,
+					ID:           b.ID,
+					Value:        b.Value,
+					ExchangeRate: exRate,
 				}
 				inc.BalanceInfo.AccountID = ub.ID
 				if b.RatingSubject != "" {
@@ -677,9 +829,21 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 	return cc, nil
 }
 
+// convertCost returns the cost rated in currency as amount of the balance together with
+// the exchange rate applied, 0 if the currencies match or one of them is the default
+func (b *Balance) convertCost(cost float64, currency string) (amount, exRate float64, err error) {
+	if b.Currency == "" || currency == "" || b.Currency == currency {
+		return cost, 0, nil
+	}
+	if exRate, err = getExchangeRate(currency, b.Currency); err != nil {
+		return
+	}
+	return cost * exRate, exRate, nil
+}
+
 // Converts the balance towards compressed information to be displayed
 func (b *Balance) AsBalanceSummary(typ string) *BalanceSummary {
-	bd := &BalanceSummary{UUID: b.Uuid, ID: b.ID, Type: typ, Value: b.Value, Disabled: b.Disabled}
+	bd := &BalanceSummary{UUID: b.Uuid, ID: b.ID, Type: typ, Value: b.Value, Disabled: b.Disabled, Currency: b.Currency}
 	if bd.ID == "" {
 		bd.ID = b.Uuid
 	}
@@ -702,7 +866,7 @@ func (bc Balances) Swap(i, j int) {
 // we need the better ones at the beginning
 func (bc Balances) Less(j, i int) bool {
 	return bc[i].precision < bc[j].precision ||
-		(bc[i].precision == bc[j].precision && bc[i].Weight < bc[j].Weight)
+		(bc[i].precision == bc[j].precision && bc[i].sortWeight() < bc[j].sortWeight())
 
 }
 
@@ -793,12 +957,52 @@ func (bc Balances) SaveDirtyBalances(acc *Account) {
 			}
 		}
 		if b.account != nil && b.account != acc && b.dirty && savedAccounts[b.account.ID] == false {
+			b.account.publishBalanceChanges(utils.MetaDebit)
 			dataStorage.SetAccount(b.account)
 			savedAccounts[b.account.ID] = true
 		}
 	}
 }
 
+// unitFactor returns the usage base units within one unit of the balance value
+func (b *Balance) unitFactor(tor string) float64 {
+	factor := b.usageUnitFactor()
+	if b.Factor != nil {
+		factor *= b.Factor.GetValue(tor)
+	}
+	return factor
+}
+
+// usageUnitFactor returns the usage base units within one Unit of the balance, ignoring the Factor
+func (b *Balance) usageUnitFactor() float64 {
+	if b.Unit != "" {
+		if unitFactor, err := utils.UsageUnitFactor(b.Unit); err == nil {
+			return unitFactor
+		}
+	}
+	return 1.0
+}
+
+// usageAmount converts the usage into the units of the balance value
+func (b *Balance) usageAmount(usage time.Duration, tor string) float64 {
+	amount := usage.Seconds()
+	if factor := b.unitFactor(tor); factor != 1 {
+		amount = utils.Round(amount/factor, globalRoundingDecimals, utils.ROUNDING_UP)
+	}
+	return amount
+}
+
+// availableUsage converts the available value of the balance into usage, capped at the maximum duration
+func (b *Balance) availableUsage(tor string) time.Duration {
+	seconds := b.availableValue(tor) * b.usageUnitFactor()
+	if maxSeconds := float64(math.MaxInt64 / int64(time.Second)); seconds >= maxSeconds {
+		return time.Duration(math.MaxInt64)
+	} else if seconds <= -maxSeconds {
+		return time.Duration(math.MinInt64)
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 type ValueFactor map[string]float64
 
 func (f ValueFactor) GetValue(tor string) float64 {
@@ -815,4 +1019,5 @@ type BalanceSummary struct {
 	Type     string // *voice, *data, etc
 	Value    float64
 	Disabled bool
+	Currency string
 }
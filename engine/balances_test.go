@@ -19,6 +19,7 @@ package engine
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cgrates/cgrates/utils"
 )
@@ -242,3 +243,82 @@ func TestBalanceIsDefault(t *testing.T) {
 		t.Errorf("Balance should be default: %+v", b)
 	}
 }
+
+type testBalanceNotifier chan *BalanceNotification
+
+func (tbn testBalanceNotifier) NotifyBalance(bn *BalanceNotification) error {
+	tbn <- bn
+	return nil
+}
+
+func TestBalancePublishChanges(t *testing.T) {
+	tbn := make(testBalanceNotifier, 1)
+	SetBalanceNotifier(tbn)
+	defer SetBalanceNotifier(nil)
+	b := &Balance{Uuid: "testuuid", ID: "TEST_BAL", Value: 10}
+	acc := &Account{ID: "cgrates.org:notif", BalanceMap: map[string]Balances{utils.MONETARY: Balances{b}}}
+	b.SubstractValue(2.5)
+	b.AddValue(0.5)
+	acc.publishBalanceChanges(utils.MetaDebit)
+	select {
+	case bn := <-tbn:
+		if bn.Account != acc.ID || bn.BalanceType != utils.MONETARY || bn.BalanceUUID != b.Uuid ||
+			bn.Delta != -2 || bn.Value != 8 || bn.Reason != utils.MetaDebit {
+			t.Errorf("Unexpected notification: %+v", bn)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No balance notification received")
+	}
+	acc.publishBalanceChanges(utils.MetaDebit) // nothing changed since last publish
+	select {
+	case bn := <-tbn:
+		t.Errorf("Unexpected notification: %+v", bn)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBalancePublishChangesOrdered(t *testing.T) {
+	tbn := make(testBalanceNotifier)
+	SetBalanceNotifier(tbn)
+	defer SetBalanceNotifier(nil)
+	b := &Balance{Uuid: "testuuid", ID: "TEST_BAL", Value: 100}
+	acc := &Account{ID: "cgrates.org:notif_ordered", BalanceMap: map[string]Balances{utils.MONETARY: Balances{b}}}
+	for i := 0; i < 20; i++ { // more than the workers so out of order delivery would show
+		b.SubstractValue(1)
+		acc.publishBalanceChanges(utils.MetaDebit)
+	}
+	for i := 0; i < 20; i++ {
+		select {
+		case bn := <-tbn:
+			if eVal := float64(99 - i); bn.Value != eVal || bn.Delta != -1 {
+				t.Fatalf("Expecting value %v, received notification: %+v", eVal, bn)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Missing balance notification %d", i)
+		}
+	}
+}
+
+func TestBalanceConvertCost(t *testing.T) {
+	if err := dataStorage.SetExchangeRate(&ExchangeRate{ID: utils.ConcatenatedKey("USD", "EUR"), FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.8}, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	b := &Balance{Currency: "EUR"}
+	if amount, exRate, err := b.convertCost(10, "USD"); err != nil {
+		t.Error(err)
+	} else if amount != 8 || exRate != 0.8 {
+		t.Errorf("Unexpected conversion: %v, %v", amount, exRate)
+	}
+	b = &Balance{Currency: "USD"}
+	if amount, exRate, err := b.convertCost(8, "EUR"); err != nil {
+		t.Error(err)
+	} else if amount != 10 || exRate != 1.25 {
+		t.Errorf("Unexpected inverse conversion: %v, %v", amount, exRate)
+	}
+	if amount, exRate, err := b.convertCost(8, ""); err != nil || amount != 8 || exRate != 0 {
+		t.Errorf("Unexpected conversion without currency: %v, %v, %v", amount, exRate, err)
+	}
+	if _, _, err := b.convertCost(8, "GBP"); err == nil {
+		t.Error("Expecting error for missing exchange rate")
+	}
+}
@@ -28,18 +28,93 @@ import (
 type CallCost struct {
 	Direction, Category, Tenant, Subject, Account, Destination, TOR string
 	Cost                                                            float64
+	Currency                                                        string // currency of Cost, empty for the default one
 	Timespans                                                       TimeSpans
 	RatedUsage                                                      float64
 	AccountSummary                                                  *AccountSummary
+	Rounding                                                        *RoundingInfo      // explains the rounding applied on Cost
+	Explanation                                                     []*CostExplanation // rating applied per timespan, populated on CallDescriptor.Explain
+	RatingVariant                                                   string             // rating plan of the A/B tested activation used, empty if not tested
 	deductConnectFee                                                bool
 	negativeConnectFee                                              bool // the connect fee went negative on default balance
 	maxCostDisconect                                                bool
 }
 
+// RoundingInfo keeps the raw and the rounded cost so the applied rounding can be explained
+type RoundingInfo struct {
+	Method      string
+	Decimals    int
+	RawCost     float64
+	RoundedCost float64
+}
+
+// CostExplanation details the rating applied on one of the timespans
+type CostExplanation struct {
+	TimeStart, TimeEnd time.Time
+	Cost               float64
+	RatingPlanID       string
+	MatchedSubject     string
+	MatchedPrefix      string
+	MatchedDestID      string
+	TimingID           string // timing tag in the tariff plan
+	StartTime          string // start of the rate interval within the day
+	Weight             float64
+	ConnectFee         float64 // charged once, at the start of the call
+	GroupIntervalStart time.Duration
+	Rate               float64
+	RateIncrement      time.Duration
+	RateUnit           time.Duration
+	RoundingMethod     string
+	RoundingDecimals   int
+}
+
+// Explain lists the rating plan, rate interval and rate group applied on each timespan
+func (cc *CallCost) Explain() (expl []*CostExplanation) {
+	expl = make([]*CostExplanation, len(cc.Timespans))
+	for i, ts := range cc.Timespans {
+		expl[i] = &CostExplanation{TimeStart: ts.TimeStart, TimeEnd: ts.TimeEnd, Cost: ts.Cost,
+			RatingPlanID: ts.RatingPlanId, MatchedSubject: ts.MatchedSubject,
+			MatchedPrefix: ts.MatchedPrefix, MatchedDestID: ts.MatchedDestId}
+		if ts.RateInterval == nil {
+			continue
+		}
+		expl[i].Weight = ts.RateInterval.Weight
+		if ts.RateInterval.Timing != nil {
+			expl[i].TimingID = ts.RateInterval.Timing.ID
+			expl[i].StartTime = ts.RateInterval.Timing.StartTime
+		}
+		if ts.RateInterval.Rating == nil {
+			continue
+		}
+		if i == 0 {
+			expl[i].ConnectFee = ts.RateInterval.Rating.ConnectFee
+		}
+		expl[i].RoundingMethod = ts.RateInterval.Rating.RoundingMethod
+		expl[i].RoundingDecimals = ts.RateInterval.Rating.RoundingDecimals
+		if rate := ts.RateInterval.getRate(ts.GetGroupStart()); rate != nil {
+			expl[i].GroupIntervalStart = rate.GroupIntervalStart
+			expl[i].Rate = rate.Value
+			expl[i].RateIncrement = rate.RateIncrement
+			expl[i].RateUnit = rate.RateUnit
+		}
+	}
+	return
+}
+
 // Merges the received timespan if they are similar (same activation period, same interval, same minute info.
 func (cc *CallCost) Merge(other *CallCost) {
 	cc.Timespans = append(cc.Timespans, other.Timespans...)
 	cc.Cost += other.Cost
+	if cc.Currency == "" {
+		cc.Currency = other.Currency
+	}
+	if other.Rounding != nil {
+		if cc.Rounding == nil {
+			cc.Rounding = &RoundingInfo{Method: other.Rounding.Method, Decimals: other.Rounding.Decimals}
+		}
+		cc.Rounding.RawCost += other.Rounding.RawCost
+		cc.Rounding.RoundedCost += other.Rounding.RoundedCost
+	}
 }
 
 func (cc *CallCost) GetStartTime() time.Time {
@@ -72,6 +147,16 @@ func (cc *CallCost) UpdateRatedUsage() time.Duration {
 	return totalDuration
 }
 
+// GetCurrency returns the currency of the rates used, empty for the default one
+func (cc *CallCost) GetCurrency() string {
+	for _, ts := range cc.Timespans {
+		if ts.RateInterval != nil && ts.RateInterval.Rating != nil && ts.RateInterval.Rating.Currency != "" {
+			return ts.RateInterval.Rating.Currency
+		}
+	}
+	return ""
+}
+
 func (cc *CallCost) GetConnectFee() float64 {
 	if len(cc.Timespans) == 0 ||
 		cc.Timespans[0].RateInterval == nil ||
@@ -158,6 +243,19 @@ func (cc *CallCost) GetLongestRounding() (roundingDecimals int, roundingMethod s
 	return
 }
 
+// roundCost applies the longest rounding of the timespans on Cost and records it
+func (cc *CallCost) roundCost() {
+	roundingDecimals, roundingMethod := cc.GetLongestRounding()
+	rawCost := cc.Cost
+	cc.Cost = utils.Round(cc.Cost, roundingDecimals, roundingMethod)
+	cc.Rounding = &RoundingInfo{
+		Method:      roundingMethod,
+		Decimals:    roundingDecimals,
+		RawCost:     rawCost,
+		RoundedCost: cc.Cost,
+	}
+}
+
 func (cc *CallCost) AsJSON() string {
 	return utils.ToJSON(cc)
 }
@@ -169,16 +267,15 @@ func (cc *CallCost) UpdateCost() {
 }
 
 func (cc *CallCost) updateCost() {
-	cost := 0.0
+	var cost utils.DecimalSum
 	//if cc.deductConnectFee { // add back the connectFee
 	//	cost += cc.GetConnectFee()
 	//}
 	for _, ts := range cc.Timespans {
 		ts.Cost = ts.CalculateCost()
-		cost += ts.Cost
-		cost = utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE) // just get rid of the extra decimals
+		cost.AddMul(ts.Cost, 1)
 	}
-	cc.Cost = cost
+	cc.Cost = utils.Round(cost.Float64(), globalRoundingDecimals, utils.ROUNDING_MIDDLE) // just get rid of the extra decimals
 }
 
 // Round creates the RoundIncrements in timespans
@@ -187,6 +284,7 @@ func (cc *CallCost) Round() {
 		return
 	}
 	var totalCorrectionCost float64
+	rawCost := cc.Cost
 	for _, ts := range cc.Timespans {
 		if len(ts.Increments) == 0 {
 			continue // safe check
@@ -212,6 +310,13 @@ func (cc *CallCost) Round() {
 		}
 	}
 	cc.Cost += totalCorrectionCost
+	roundingDecimals, roundingMethod := cc.GetLongestRounding()
+	cc.Rounding = &RoundingInfo{
+		Method:      roundingMethod,
+		Decimals:    roundingDecimals,
+		RawCost:     rawCost,
+		RoundedCost: cc.Cost,
+	}
 }
 
 func (cc *CallCost) GetRoundIncrements() (roundIncrements Increments) {
@@ -237,7 +342,7 @@ func (cc *CallCost) MatchCCFilter(bf *BalanceFilter) bool {
 	// match destination ids
 	foundMatchingDestID := false
 	if bf.DestinationIDs != nil && cc.Destination != "" {
-		for _, p := range utils.SplitPrefix(cc.Destination, MIN_PREFIX_MATCH) {
+		for _, p := range splitDestination(cc.Destination) {
 			if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
 				for _, dID := range destIDs {
 					if _, ok := (*bf.DestinationIDs)[dID]; ok {
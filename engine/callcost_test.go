@@ -18,6 +18,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -173,6 +174,32 @@ func TestCallCostGetDuration(t *testing.T) {
 	}
 }
 
+func TestCallCostRoundingInfo(t *testing.T) {
+	cc := &CallCost{
+		Cost: 0.12345,
+		Timespans: TimeSpans{
+			&TimeSpan{
+				RateInterval: &RateInterval{Rating: &RIRate{
+					RoundingMethod: utils.ROUNDING_HALF_EVEN, RoundingDecimals: 4}},
+			},
+		},
+	}
+	cc.roundCost()
+	eRounding := &RoundingInfo{Method: utils.ROUNDING_HALF_EVEN, Decimals: 4,
+		RawCost: 0.12345, RoundedCost: 0.1234}
+	if cc.Cost != 0.1234 {
+		t.Errorf("Expecting: 0.1234, received: %v", cc.Cost)
+	} else if !reflect.DeepEqual(eRounding, cc.Rounding) {
+		t.Errorf("Expecting: %+v, received: %+v", eRounding, cc.Rounding)
+	}
+	other := &CallCost{Cost: 0.1, Rounding: &RoundingInfo{Method: utils.ROUNDING_HALF_EVEN,
+		Decimals: 4, RawCost: 0.1, RoundedCost: 0.1}}
+	cc.Merge(other)
+	if cc.Rounding.RawCost != 0.22345 || cc.Rounding.RoundedCost != 0.2234 {
+		t.Errorf("Wrong merged rounding: %+v", cc.Rounding)
+	}
+}
+
 func TestCallCostToDataCostError(t *testing.T) {
 	cd := &CallDescriptor{
 		Direction:   "*out",
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 
 	"sort"
 	"strings"
@@ -67,6 +68,7 @@ var (
 	dataStorage              DataDB
 	cdrStorage               CdrStorage
 	debitPeriod              = 10 * time.Second
+	rpFallbackMaxDepth       = RECURSION_MAX_DEPTH
 	globalRoundingDecimals   = 6
 	historyScribe            rpcclient.RpcClientConnection
 	pubSubServer             rpcclient.RpcClientConnection
@@ -94,6 +96,11 @@ func SetLcrSubjectPrefixMatching(flag bool) {
 	lcrSubjectPrefixMatching = flag
 }
 
+// SetRpFallbackMaxDepth limits the rating subjects followed in one fallback chain
+func SetRpFallbackMaxDepth(depth int) {
+	rpFallbackMaxDepth = depth
+}
+
 /*
 Sets the database for CDR storing, used by *cdrlog in first place
 */
@@ -149,8 +156,17 @@ type CallDescriptor struct {
 	ForceDuration       bool // for Max debit if less than duration return err
 	PerformRounding     bool // flag for rating info rounding
 	DryRun              bool
-	DenyNegativeAccount bool // prevent account going on negative during debit
+	Explain             bool                     // add the rating applied per timespan to the CallCost
+	DenyNegativeAccount bool                     // prevent account going on negative during debit
+	TierUsage           map[string]time.Duration // usage in the current billing period of tiered ratings, populated out of account on debit
+	RatingAsOf          time.Time                // rate with the rating data configured at this moment, zero for the current one
+	CostShare           float64                  // fraction of the cost charged out of a split charging, 0 for the whole cost
+	Debug               bool                     // trace the rating bypassing the cache, storing the records under OriginID
+	OriginID            string                   // identifies the call within the debug records
 	account             *Account
+	debugTrace          []string
+	debugCallCost       *CallCost
+	ratingHistory       *ratingHistory
 	testCallcost        *CallCost // testing purpose only!
 }
 
@@ -189,11 +205,23 @@ Restores the activation periods for the specified prefix from storage.
 */
 func (cd *CallDescriptor) LoadRatingPlans() (err error) {
 	var rec int
-	err, rec = cd.getRatingPlansForPrefix(cd.GetKey(cd.Subject), 1)
+	err, rec = cd.getRatingPlansForPrefix(cd.GetKey(cd.Subject), 1, nil)
+	if err == utils.ErrNotFound && rec == 1 {
+		// try the wildcard subjects, most specific first
+		for _, wcKey := range ratingProfileSubjectWildcards(cd.GetKey(cd.Subject)) {
+			if err, rec = cd.getRatingPlansForPrefix(wcKey, 1, nil); err != utils.ErrNotFound || rec != 1 {
+				break
+			}
+		}
+	}
 	if err == utils.ErrNotFound && rec == 1 {
 		//if err != nil || !cd.continousRatingInfos() {
 		// use the default subject only if the initial one was not found
-		err, _ = cd.getRatingPlansForPrefix(cd.GetKey(FALLBACK_SUBJECT), 1)
+		err, _ = cd.getRatingPlansForPrefix(cd.GetKey(FALLBACK_SUBJECT), 1, nil)
+	}
+	if err != nil && err != utils.ErrNotFound { // misconfigured fallback chain
+		utils.Logger.Err(fmt.Sprintf("Rating plans for destination %s and subject: %s cannot be loaded: %s", cd.Destination, cd.GetKey(cd.Subject), err.Error()))
+		return err
 	}
 	//load the rating plans
 	if err != nil {
@@ -205,19 +233,35 @@ func (cd *CallDescriptor) LoadRatingPlans() (err error) {
 		utils.Logger.Err(fmt.Sprintf("Destination %s not authorized for account: %s, subject: %s", cd.Destination, cd.GetAccountKey(), cd.GetKey(cd.Subject)))
 		return utils.ErrUnauthorizedDestination
 	}
+	for _, ri := range cd.RatingInfos {
+		cd.debugf("matched subject: %s, rating plan: %s, destination: %s, prefix: %s, active from: %s, fallback keys: %v",
+			ri.MatchedSubject, ri.RatingPlanId, ri.MatchedDestId, ri.MatchedPrefix, ri.ActivationTime, ri.FallbackKeys)
+	}
 	return
 }
 
 // FIXME: this method is not exhaustive but will cover 99% of cases just good
 // it will not cover very long calls with very short activation periods for rates
-func (cd *CallDescriptor) getRatingPlansForPrefix(key string, recursionDepth int) (error, int) {
-	if recursionDepth > RECURSION_MAX_DEPTH {
-		return utils.ErrMaxRecursionDepth, recursionDepth
+// fallbackChain holds the subject keys which fell back towards key
+func (cd *CallDescriptor) getRatingPlansForPrefix(key string, recursionDepth int, fallbackChain []string) (error, int) {
+	fallbackChain = append(fallbackChain[:len(fallbackChain):len(fallbackChain)], key) // never share the backing array between branches
+	for _, chainKey := range fallbackChain[:len(fallbackChain)-1] {
+		if chainKey == key {
+			return utils.NewErrFallbackLoop(fallbackChain), recursionDepth
+		}
+	}
+	if recursionDepth > rpFallbackMaxDepth {
+		return utils.NewErrMaxRecursionDepth(fallbackChain), recursionDepth
+	}
+	rdg, err := cd.ratingData()
+	if err != nil {
+		return err, recursionDepth
 	}
-	rpf, err := RatingProfileSubjectPrefixMatching(key)
+	rpf, err := ratingProfileSubjectPrefixMatching(rdg, key)
 	if err != nil || rpf == nil {
 		return utils.ErrNotFound, recursionDepth
 	}
+	cd.debugf("rating profile %s found for key %s", rpf.Id, key)
 	if err = rpf.GetRatingPlansForPrefix(cd); err != nil || !cd.continousRatingInfos() {
 		// try rating profile fallback
 		recursionDepth++
@@ -229,10 +273,13 @@ func (cd *CallDescriptor) getRatingPlansForPrefix(key string, recursionDepth int
 			}
 			if len(ri.FallbackKeys) > 0 {
 				tempCD := &CallDescriptor{
-					Category:    cd.Category,
-					Direction:   cd.Direction,
-					Tenant:      cd.Tenant,
-					Destination: cd.Destination,
+					Category:      cd.Category,
+					Direction:     cd.Direction,
+					Tenant:        cd.Tenant,
+					Destination:   cd.Destination,
+					RatingAsOf:    cd.RatingAsOf,
+					Debug:         cd.Debug,
+					ratingHistory: cd.ratingHistory,
 				}
 				if index == 0 {
 					tempCD.TimeStart = cd.TimeStart
@@ -245,8 +292,10 @@ func (cd *CallDescriptor) getRatingPlansForPrefix(key string, recursionDepth int
 					tempCD.TimeEnd = cd.RatingInfos[index+1].ActivationTime
 				}
 				for _, fbk := range ri.FallbackKeys {
-					if err, _ := tempCD.getRatingPlansForPrefix(fbk, recursionDepth); err != nil {
+					if err, _ := tempCD.getRatingPlansForPrefix(fbk, recursionDepth, fallbackChain); err == utils.ErrNotFound {
 						continue
+					} else if err != nil {
+						return err, recursionDepth
 					}
 					// extract the rate infos and break
 					for newIndex, newRI := range tempCD.RatingInfos {
@@ -338,6 +387,11 @@ func (cd *CallDescriptor) splitInTimeSpans() (timespans []*TimeSpan) {
 	if len(cd.RatingInfos) == 0 {
 		return
 	}
+	if cd.TierUsage != nil {
+		for _, ri := range cd.RatingInfos {
+			ri.tierUsage = cd.TierUsage
+		}
+	}
 	firstSpan.setRatingInfo(cd.RatingInfos[0])
 	if cd.TOR == utils.VOICE {
 		// split on rating plans
@@ -453,7 +507,25 @@ func (cd *CallDescriptor) GetDuration() time.Duration {
 /*
 Creates a CallCost structure with the cost information calculated for the received CallDescriptor.
 */
-func (cd *CallDescriptor) GetCost() (*CallCost, error) {
+func (cd *CallDescriptor) GetCost() (cc *CallCost, err error) {
+	cd.debugf("GetCost for account: %s, subject: %s, destination: %s, from %s to %s",
+		cd.GetAccountKey(), cd.GetKey(cd.Subject), cd.Destination, cd.TimeStart, cd.TimeEnd)
+	defer func() { cd.storeDebugRecord("GetCost", cc, err) }()
+	if err = preRatingHooks(cd); err != nil {
+		return nil, err
+	}
+	if cc, err = cd.calculateCost(); err != nil {
+		return
+	}
+	if err = postRatingHooks(cd, cc); err != nil {
+		return nil, err
+	}
+	cd.debugf("cost: %v %s", cc.Cost, cc.Currency)
+	return
+}
+
+// calculateCost does the cost calculation of GetCost, without the RatingHooks
+func (cd *CallDescriptor) calculateCost() (*CallCost, error) {
 	cd.account = nil // make sure it's not cached
 	cc, err := cd.getCost()
 	if err != nil || cd.GetDuration() == 0 {
@@ -469,7 +541,7 @@ func (cd *CallDescriptor) GetCost() (*CallCost, error) {
 		}
 		//log.Printf("TS: %+v", ts)
 		// handle max cost
-		maxCost, strategy := ts.RateInterval.GetMaxCost()
+		maxCost, strategy := ts.getMaxCost()
 
 		ts.Cost = ts.CalculateCost()
 		cost += ts.Cost
@@ -477,7 +549,7 @@ func (cd *CallDescriptor) GetCost() (*CallCost, error) {
 		//log.Print("Before: ", cost)
 		if strategy != "" && maxCost > 0 {
 			//log.Print("HERE: ", strategy, maxCost)
-			if strategy == utils.MAX_COST_FREE && cd.MaxCostSoFar >= maxCost {
+			if (strategy == utils.MAX_COST_FREE || strategy == utils.MAX_COST_CAP) && cd.MaxCostSoFar >= maxCost {
 				cost = maxCost
 				cd.MaxCostSoFar = maxCost
 			}
@@ -486,10 +558,12 @@ func (cd *CallDescriptor) GetCost() (*CallCost, error) {
 		//log.Print("Cost: ", cost)
 	}
 	cc.Cost = cost
+	cc.Currency = cc.GetCurrency()
 	// global rounding
-	roundingDecimals, roundingMethod := cc.GetLongestRounding()
-	cc.Cost = utils.Round(cc.Cost, roundingDecimals, roundingMethod)
-
+	cc.roundCost()
+	if cd.Explain {
+		cc.Explanation = cc.Explain()
+	}
 	return cc, nil
 }
 
@@ -524,6 +598,13 @@ func (cd *CallDescriptor) getCost() (*CallCost, error) {
 		return &CallCost{Cost: -1}, err
 	}
 	timespans := cd.splitInTimeSpans()
+	if cd.CostShare != 0 {
+		for _, ts := range timespans {
+			if ts.RateInterval != nil {
+				ts.RateInterval = ts.RateInterval.withCostShare(cd.CostShare)
+			}
+		}
+	}
 	cost := 0.0
 
 	for i, ts := range timespans {
@@ -535,21 +616,47 @@ func (cd *CallDescriptor) getCost() (*CallCost, error) {
 		}
 		cost += ts.CalculateCost()
 	}
+	cost += cd.addMinCharge(timespans, cost)
 
 	//startIndex := len(fmt.Sprintf("%s:%s:%s:", cd.Direction, cd.Tenant, cd.Category))
 	cc := cd.CreateCallCost()
 	cc.Cost = cost
 	cc.Timespans = timespans
 
+	cc.Currency = cc.GetCurrency()
 	// global rounding
-	roundingDecimals, roundingMethod := cc.GetLongestRounding()
-	cc.Cost = utils.Round(cc.Cost, roundingDecimals, roundingMethod)
+	cc.roundCost()
 	//utils.Logger.Info(fmt.Sprintf("<Rater> Get Cost: %s => %v", cd.GetKey(), cc))
+	cc.RatingVariant = cc.Timespans.ratingVariant()
+	cd.debugf("rated %d timespans, cost before connect fee and max cost: %v", len(cc.Timespans), cc.Cost)
+	cd.retainCallCost(cc)
 	cc.Timespans.Compress()
 	cc.UpdateRatedUsage()
 	return cc, err
 }
 
+// addMinCharge itemizes the shortfall up to the minimum charge of the rating as a zero duration increment
+// on the last timespan, it applies only once per call and returns the added cost
+func (cd *CallDescriptor) addMinCharge(timespans TimeSpans, cost float64) float64 {
+	if cd.LoopIndex != 0 || cd.DurationIndex != cd.GetDuration() ||
+		len(timespans) == 0 || timespans[0].RateInterval == nil {
+		return 0
+	}
+	rating := timespans[0].RateInterval.Rating
+	if rating.MinCharge <= cost ||
+		(rating.MinChargeUsage != 0 && cd.DurationIndex >= rating.MinChargeUsage) {
+		return 0
+	}
+	shortfall := utils.Round(rating.MinCharge-cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	ts := timespans[len(timespans)-1]
+	ts.Increments = append(ts.Increments, &Increment{
+		Cost:        shortfall,
+		BalanceInfo: &DebitInfo{},
+	})
+	ts.Cost += shortfall
+	return shortfall
+}
+
 /*
 Returns the approximate max allowed session for user balance. It will try the max amount received in the call descriptor
 If the user has no credit then it will return 0.
@@ -562,6 +669,9 @@ func (origCD *CallDescriptor) getMaxSessionDuration(origAcc *Account) (time.Dura
 	if account.AllowNegative {
 		return -1, nil
 	}
+	if origCD.isEmergency() {
+		return origCD.TimeEnd.Sub(origCD.TimeStart), nil
+	}
 	// for zero duration index
 	if origCD.DurationIndex < origCD.TimeEnd.Sub(origCD.TimeStart) {
 		origCD.DurationIndex = origCD.TimeEnd.Sub(origCD.TimeStart)
@@ -667,7 +777,13 @@ func (cd *CallDescriptor) debit(account *Account, dryRun bool, goNegative bool)
 	if cd.TOR == "" {
 		cd.TOR = utils.VOICE
 	}
+	if cd.TierUsage == nil && len(account.TierUsage) != 0 {
+		cd.TierUsage = account.tierUsageAt(cd.TimeStart)
+	}
 	//log.Printf("Debit CD: %+v", cd)
+	if cd.Debug {
+		cd.debugf("balances before debit: %s", utils.ToJSON(account.AsAccountSummary().BalanceSummaries))
+	}
 	cc, err = account.debitCreditBalance(cd, !dryRun, dryRun, goNegative)
 	//log.Printf("HERE: %+v %v", cc, err)
 	if err != nil {
@@ -676,8 +792,17 @@ func (cd *CallDescriptor) debit(account *Account, dryRun bool, goNegative bool)
 	}
 	cc.updateCost()
 	cc.UpdateRatedUsage()
+	if !dryRun {
+		account.countTierUsage(cc.Timespans)
+	}
+	cc.RatingVariant = cc.Timespans.ratingVariant()
+	if cd.Debug {
+		cd.debugf("debited %v %s for %v usage, balances after debit: %s", cc.Cost, cc.Currency, cc.RatedUsage, utils.ToJSON(account.AsAccountSummary().BalanceSummaries))
+	}
+	cd.retainCallCost(cc)
 	cc.Timespans.Compress()
 	if !dryRun {
+		account.publishBalanceChanges(utils.MetaDebit)
 		dataStorage.SetAccount(account)
 	}
 	if cd.PerformRounding {
@@ -694,6 +819,9 @@ func (cd *CallDescriptor) debit(account *Account, dryRun bool, goNegative bool)
 }
 
 func (cd *CallDescriptor) Debit() (cc *CallCost, err error) {
+	cd.debugf("Debit for account: %s, subject: %s, destination: %s, from %s to %s",
+		cd.GetAccountKey(), cd.GetKey(cd.Subject), cd.Destination, cd.TimeStart, cd.TimeEnd)
+	defer func() { cd.storeDebugRecord("Debit", cc, err) }()
 	cd.account = nil // make sure it's not cached
 	_, err = guardian.Guardian.Guard(func() (iface interface{}, err error) {
 		// lock all group members
@@ -728,6 +856,9 @@ func (cd *CallDescriptor) Debit() (cc *CallCost, err error) {
 // This methods combines the Debit and GetMaxSessionDuration and will debit the max available time as returned
 // by the GetMaxSessionDuration method. The amount filed has to be filled in call descriptor.
 func (cd *CallDescriptor) MaxDebit() (cc *CallCost, err error) {
+	cd.debugf("MaxDebit for account: %s, subject: %s, destination: %s, from %s to %s",
+		cd.GetAccountKey(), cd.GetKey(cd.Subject), cd.Destination, cd.TimeStart, cd.TimeEnd)
+	defer func() { cd.storeDebugRecord("MaxDebit", cc, err) }()
 	cd.account = nil // make sure it's not cached
 	_, err = guardian.Guardian.Guard(func() (iface interface{}, err error) {
 		account, err := cd.getAccount()
@@ -747,6 +878,7 @@ func (cd *CallDescriptor) MaxDebit() (cc *CallCost, err error) {
 		}
 		_, err = guardian.Guardian.Guard(func() (iface interface{}, err error) {
 			remainingDuration, err := cd.getMaxSessionDuration(account)
+			cd.debugf("max session duration: %s out of requested %s, error: %v", remainingDuration, cd.GetDuration(), err)
 			if err != nil && cd.GetDuration() > 0 {
 				return nil, err
 			}
@@ -780,7 +912,7 @@ func (cd *CallDescriptor) MaxDebit() (cc *CallCost, err error) {
 				cd.DurationIndex -= initialDuration - remainingDuration
 			}
 			//log.Print("Remaining duration: ", remainingDuration)
-			cc, err = cd.debit(account, cd.DryRun, !cd.DenyNegativeAccount)
+			cc, err = cd.debit(account, cd.DryRun, !cd.DenyNegativeAccount || cd.isEmergency())
 			if err == nil {
 				cc.AccountSummary = cd.AccountSummary()
 			}
@@ -795,16 +927,24 @@ func (cd *CallDescriptor) MaxDebit() (cc *CallCost, err error) {
 // refundIncrements has no locks
 func (cd *CallDescriptor) refundIncrements() (err error) {
 	accountsCache := make(map[string]*Account)
-	for _, increment := range cd.Increments {
-		account, found := accountsCache[increment.BalanceInfo.AccountID]
+	getAccount := func(acntID string) *Account {
+		account, found := accountsCache[acntID]
 		if !found {
-			if acc, err := dataStorage.GetAccount(increment.BalanceInfo.AccountID); err == nil && acc != nil {
+			if acc, err := dataStorage.GetAccount(acntID); err == nil && acc != nil {
 				account = acc
-				accountsCache[increment.BalanceInfo.AccountID] = account
-				// will save the account only once at the end of the function
-				defer dataStorage.SetAccount(account)
+				accountsCache[acntID] = account
 			}
 		}
+		return account
+	}
+	defer func() { // will save the accounts only once at the end of the function
+		for _, acc := range accountsCache {
+			acc.publishBalanceChanges(utils.MetaRefund)
+			dataStorage.SetAccount(acc)
+		}
+	}()
+	for _, increment := range cd.Increments {
+		account := getAccount(increment.BalanceInfo.AccountID)
 		if account == nil {
 			utils.Logger.Warning(fmt.Sprintf("Could not get the account to be refunded: %s", increment.BalanceInfo.AccountID))
 			continue
@@ -817,16 +957,23 @@ func (cd *CallDescriptor) refundIncrements() (err error) {
 			if balance = account.BalanceMap[unitType].GetBalance(increment.BalanceInfo.Unit.UUID); balance == nil {
 				return
 			}
-			balance.AddValue(increment.Duration.Seconds())
-			account.countUnits(-increment.Duration.Seconds(), unitType, cc, balance)
+			amount := balance.usageAmount(increment.Duration, unitType)
+			balance.AddValue(amount)
+			account.countUnits(-amount, unitType, cc, balance)
 		}
 		// check money too
 		if increment.BalanceInfo.Monetary != nil && increment.BalanceInfo.Monetary.UUID != "" {
 			if balance = account.BalanceMap[utils.MONETARY].GetBalance(increment.BalanceInfo.Monetary.UUID); balance == nil {
 				return
 			}
-			balance.AddValue(increment.Cost)
-			account.countUnits(-increment.Cost, utils.MONETARY, cc, balance)
+			amount := increment.BalanceInfo.Monetary.balanceAmount(increment.Cost)
+			balance.AddValue(amount)
+			account.countUnits(-amount, utils.MONETARY, cc, balance)
+			if cd.Account != "" && account.ID != cd.GetAccountKey() { // paid out of the parent of the charged account
+				if child := getAccount(cd.GetAccountKey()); child != nil && child.ParentID == account.ID {
+					child.ParentUsed = math.Max(child.ParentUsed-amount, 0)
+				}
+			}
 		}
 	}
 	return
@@ -846,6 +993,9 @@ func (cd *CallDescriptor) RefundIncrements() (err error) {
 			accMap[utils.ACCOUNT_PREFIX+increment.BalanceInfo.AccountID] = true
 		}
 	}
+	if cd.Account != "" && len(accMap) != 0 { // might count the refunds out of its parent
+		accMap[utils.ACCOUNT_PREFIX+cd.GetAccountKey()] = true
+	}
 	_, err = guardian.Guardian.Guard(func() (iface interface{}, err error) {
 		err = cd.refundIncrements()
 		return
@@ -864,7 +1014,10 @@ func (cd *CallDescriptor) refundRounding() (err error) {
 				account = acc
 				accountsCache[increment.BalanceInfo.AccountID] = account
 				// will save the account only once at the end of the function
-				defer dataStorage.SetAccount(account)
+				defer func(acc *Account) {
+					acc.publishBalanceChanges(utils.MetaRefundRounding)
+					dataStorage.SetAccount(acc)
+				}(account)
 			}
 		}
 		if account == nil {
@@ -877,8 +1030,9 @@ func (cd *CallDescriptor) refundRounding() (err error) {
 			if balance = account.BalanceMap[utils.MONETARY].GetBalance(increment.BalanceInfo.Monetary.UUID); balance == nil {
 				return
 			}
-			balance.AddValue(-increment.Cost)
-			account.countUnits(increment.Cost, utils.MONETARY, cc, balance)
+			amount := increment.BalanceInfo.Monetary.balanceAmount(increment.Cost)
+			balance.AddValue(-amount)
+			account.countUnits(amount, utils.MONETARY, cc, balance)
 		}
 	}
 	return
@@ -934,7 +1088,31 @@ func (cd *CallDescriptor) Clone() *CallDescriptor {
 		DryRun:          cd.DryRun,
 		CgrID:           cd.CgrID,
 		RunID:           cd.RunID,
+		TierUsage:       cd.TierUsage,
+		RatingAsOf:      cd.RatingAsOf,
+		CostShare:       cd.CostShare,
+		Debug:           cd.Debug,
+		OriginID:        cd.OriginID,
+		ratingHistory:   cd.ratingHistory,
+	}
+}
+
+// ratingData returns the source of the rating data, rebuilding the history out of the TPSnapshots for RatingAsOf
+func (cd *CallDescriptor) ratingData() (rdg ratingDataGetter, err error) {
+	if cd.RatingAsOf.IsZero() {
+		rdg = dataStorage
+	} else {
+		if cd.ratingHistory == nil {
+			if cd.ratingHistory, err = newRatingHistory(dataStorage, cd.RatingAsOf); err != nil {
+				return
+			}
+		}
+		rdg = cd.ratingHistory
 	}
+	if cd.Debug {
+		rdg = uncachedRatingData{rdg}
+	}
+	return
 }
 
 func (cd *CallDescriptor) GetLCRFromStorage() (*LCR, error) {
@@ -997,6 +1175,9 @@ func (cd *CallDescriptor) GetLCR(stats rpcclient.RpcClientConnection, lcrFltr *L
 			lcrCD.Subject = supplier
 			lcrCD.Category = lcrCost.Entry.RPCategory
 			fullSupplier := utils.ConcatenatedKey(lcrCD.Direction, lcrCD.Tenant, lcrCD.Category, lcrCD.Subject)
+			if lcrBlacklist.IsBlacklisted(lcrCD.Tenant, supplier) {
+				continue
+			}
 			var cc *CallCost
 			var err error
 			if cd.account, err = dataStorage.GetAccount(lcrCD.GetAccountKey()); err == nil {
@@ -1056,6 +1237,9 @@ func (cd *CallDescriptor) GetLCR(stats rpcclient.RpcClientConnection, lcrFltr *L
 			lcrCD.Account = supplier
 			lcrCD.Subject = supplier
 			fullSupplier := utils.ConcatenatedKey(lcrCD.Direction, lcrCD.Tenant, lcrCD.Category, lcrCD.Subject)
+			if lcrBlacklist.IsBlacklisted(lcrCD.Tenant, supplier) {
+				continue
+			}
 			var qosSortParams []string
 			var asrValues sort.Float64Slice
 			var pddValues sort.Float64Slice
@@ -20,6 +20,8 @@ package engine
 import (
 	"log"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -907,6 +909,31 @@ func TestDebitRatingInfoOnZeroTime(t *testing.T) {
 	}
 }
 
+func TestMaxSessionTimeLowCreditPolicy(t *testing.T) {
+	cd := &CallDescriptor{
+		Direction:   "*out",
+		Category:    "call",
+		Tenant:      "cgrates.org",
+		Subject:     "block",
+		Account:     "block_empty",
+		Destination: "0723",
+		TimeStart:   time.Date(2016, 1, 13, 14, 0, 0, 0, time.UTC),
+		TimeEnd:     time.Date(2016, 1, 13, 14, 30, 0, 0, time.UTC),
+	}
+	defer SetEmergencyDestinations(nil)
+	if result, err := cd.Clone().GetMaxSessionDuration(); err != nil || result != 0 {
+		t.Errorf("Expected %v was %v (%v)", 0, result, err)
+	}
+	SetEmergencyDestinations([]string{"NAT"})
+	if result, err := cd.Clone().GetMaxSessionDuration(); err != nil || result != 30*time.Minute {
+		t.Errorf("Expected %v was %v (%v)", 30*time.Minute, result, err)
+	}
+	SetEmergencyDestinations([]string{"GERMANY"})
+	if result, err := cd.Clone().GetMaxSessionDuration(); err != nil || result != 0 {
+		t.Errorf("Expected %v was %v (%v)", 0, result, err)
+	}
+}
+
 func TestMaxDebitRatingInfoOnZeroTime(t *testing.T) {
 	ap, _ := dataStorage.GetActionPlan("TOPUP10_AT", false, utils.NonTransactional)
 	for _, at := range ap.ActionTimings {
@@ -1776,3 +1803,389 @@ func BenchmarkStorageMultipleGetSessionTime(b *testing.B) {
 		cd.GetMaxSessionDuration()
 	}
 }
+
+func TestCalldescTieredRating(t *testing.T) {
+	rs := &utils.RateSlot{GroupIntervalStart: "1000m;*monthly", RateUnit: "1m", RateIncrement: "1m"}
+	if err := rs.SetDurations(); err != nil || rs.TierPeriod() != utils.MetaMonthly {
+		t.Fatal(err, rs.TierPeriod())
+	}
+	ri := &RateInterval{
+		Timing: &RITiming{StartTime: "00:00:00"},
+		Rating: &RIRate{
+			TierPeriod: utils.MetaMonthly,
+			Rates: RateGroups{
+				&Rate{Value: 2, RateIncrement: time.Minute, RateUnit: time.Minute},
+				&Rate{GroupIntervalStart: rs.GroupIntervalStartDuration(), Value: 1, RateIncrement: time.Minute, RateUnit: time.Minute},
+			},
+		},
+	}
+	tStart := time.Date(2017, time.May, 10, 13, 0, 0, 0, time.UTC)
+	acc := &Account{ID: "cgrates.org:tiered", TierUsage: map[string]*TierUsage{
+		ri.Rating.tierKey(): &TierUsage{PeriodStart: time.Date(2017, time.May, 1, 0, 0, 0, 0, time.UTC), Usage: 995 * time.Minute}}}
+	cd := &CallDescriptor{
+		TimeStart:     tStart,
+		TimeEnd:       tStart.Add(10 * time.Minute),
+		DurationIndex: 10 * time.Minute,
+		TOR:           utils.VOICE,
+		TierUsage:     acc.tierUsageAt(tStart),
+		RatingInfos:   RatingInfos{&RatingInfo{ActivationTime: tStart.Add(-time.Hour), RateIntervals: RateIntervalList{ri}}},
+	}
+	tss := TimeSpans(cd.splitInTimeSpans())
+	if len(tss) != 2 || tss[0].GetDuration() != 5*time.Minute {
+		t.Fatalf("Wrong tier split: %s", utils.ToJSON(tss))
+	}
+	if rate, _, _ := tss[0].RateInterval.GetRateParameters(tss[0].GetGroupStart()); rate != 2 {
+		t.Error("Wrong first tier rate: ", rate)
+	}
+	if rate, _, _ := tss[1].RateInterval.GetRateParameters(tss[1].GetGroupStart()); rate != 1 {
+		t.Error("Wrong second tier rate: ", rate)
+	}
+	acc.countTierUsage(tss)
+	if tu := acc.TierUsage[ri.Rating.tierKey()]; tu.Usage != 1005*time.Minute {
+		t.Error("Wrong tier usage: ", tu.Usage)
+	}
+	// next month starts counting from zero
+	if usage := acc.tierUsageAt(time.Date(2017, time.June, 1, 0, 0, 0, 0, time.UTC)); len(usage) != 0 {
+		t.Error("Usage carried to the next period: ", usage)
+	}
+}
+
+func TestCalldescMinCharge(t *testing.T) {
+	ri := &RateInterval{
+		Timing: &RITiming{StartTime: "00:00:00"},
+		Rating: &RIRate{
+			ConnectFee:     0.1,
+			MinCharge:      0.5,
+			MinChargeUsage: 30 * time.Second,
+			Rates:          RateGroups{&Rate{Value: 0.6, RateIncrement: time.Second, RateUnit: time.Minute}},
+		},
+	}
+	tStart := time.Date(2017, time.May, 10, 13, 0, 0, 0, time.UTC)
+	rate := func(usage time.Duration) (TimeSpans, float64) {
+		cd := &CallDescriptor{
+			TimeStart:     tStart,
+			TimeEnd:       tStart.Add(usage),
+			DurationIndex: usage,
+			TOR:           utils.VOICE,
+			RatingInfos:   RatingInfos{&RatingInfo{ActivationTime: tStart.Add(-time.Hour), RateIntervals: RateIntervalList{ri}}},
+		}
+		tss := TimeSpans(cd.splitInTimeSpans())
+		cost := ri.Rating.ConnectFee
+		for _, ts := range tss {
+			ts.createIncrementsSlice()
+			cost += ts.CalculateCost()
+		}
+		return tss, cost + cd.addMinCharge(tss, cost)
+	}
+	tss, cost := rate(10 * time.Second)
+	if cost != 0.5 {
+		t.Error("Wrong cost: ", cost)
+	}
+	last := tss[len(tss)-1]
+	if inc := last.Increments[len(last.Increments)-1]; inc.Duration != 0 || inc.Cost != 0.3 {
+		t.Errorf("Wrong minimum charge increment: %s", utils.ToJSON(inc))
+	}
+	if last.CalculateCost() != 0.4 {
+		t.Error("Minimum charge not in the timespan cost: ", last.CalculateCost())
+	}
+	// calls over the minimum charge usage pay only their own cost
+	if tss, cost = rate(35 * time.Second); utils.Round(cost, 4, utils.ROUNDING_MIDDLE) != 0.45 || len(tss[0].Increments) != 35 {
+		t.Error("Wrong cost: ", cost, len(tss[0].Increments))
+	}
+}
+
+func TestCalldescMillisecondIncrements(t *testing.T) {
+	ri := &RateInterval{
+		Timing: &RITiming{StartTime: "00:00:00"},
+		Rating: &RIRate{
+			RoundingMethod:   utils.ROUNDING_MIDDLE,
+			RoundingDecimals: 4,
+			Rates: RateGroups{
+				&Rate{Value: 0.01, RateIncrement: 100 * time.Millisecond, RateUnit: time.Second},
+				&Rate{GroupIntervalStart: 300 * time.Millisecond, Value: 0.001, RateIncrement: 50 * time.Millisecond, RateUnit: 100 * time.Millisecond},
+			},
+		},
+	}
+	tStart := time.Date(2017, time.May, 10, 13, 0, 0, 0, time.UTC)
+	cd := &CallDescriptor{
+		TimeStart:     tStart,
+		TimeEnd:       tStart.Add(1230 * time.Millisecond),
+		DurationIndex: 1230 * time.Millisecond,
+		TOR:           utils.VOICE,
+		RatingInfos:   RatingInfos{&RatingInfo{ActivationTime: tStart.Add(-time.Hour), RateIntervals: RateIntervalList{ri}}},
+	}
+	tss := cd.roundTimeSpansToIncrement(cd.splitInTimeSpans())
+	if len(tss) != 2 {
+		t.Fatalf("Unexpected timespans: %s", utils.ToJSON(tss))
+	}
+	eCosts, eIncs := []float64{0.003, 0.0095}, []int{3, 19}
+	for i, ts := range tss {
+		ts.createIncrementsSlice()
+		if cost := utils.Round(ts.CalculateCost(), 4, utils.ROUNDING_MIDDLE); cost != eCosts[i] || len(ts.Increments) != eIncs[i] {
+			t.Errorf("Timespan %d: cost %v, increments %d", i, cost, len(ts.Increments))
+		}
+	}
+	// usage rounded up to the 50ms increment of the second rate
+	if !tss[1].TimeEnd.Equal(tStart.Add(1250 * time.Millisecond)) {
+		t.Error("Unexpected end time: ", tss[1].TimeEnd)
+	}
+}
+
+func TestCalldescGetCostExplain(t *testing.T) {
+	cd := &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "vdf", Subject: "rif", Destination: "0256",
+		TimeStart: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC),
+		TimeEnd:   time.Date(2012, time.February, 2, 18, 1, 0, 0, time.UTC)}
+	cc, err := cd.GetCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.Explanation != nil {
+		t.Errorf("Unexpected explanation: %s", utils.ToJSON(cc.Explanation))
+	}
+	cd.Explain = true
+	if cc, err = cd.GetCost(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.Explanation) != 2 {
+		t.Fatalf("Unexpected explanation: %s", utils.ToJSON(cc.Explanation))
+	}
+	eExpl := []*CostExplanation{
+		&CostExplanation{TimeStart: cd.TimeStart, TimeEnd: time.Date(2012, time.February, 2, 18, 0, 0, 0, time.UTC), Cost: 60,
+			RatingPlanID: "EVENING", MatchedSubject: "*out:vdf:0:rif", MatchedPrefix: "0256", MatchedDestID: "NAT",
+			TimingID: "WORKDAYS_00", StartTime: "00:00:00", Weight: 10, ConnectFee: 1,
+			Rate: 1, RateIncrement: time.Second, RateUnit: time.Second,
+			RoundingMethod: utils.ROUNDING_MIDDLE, RoundingDecimals: 4},
+		&CostExplanation{TimeStart: time.Date(2012, time.February, 2, 18, 0, 0, 0, time.UTC), TimeEnd: cd.TimeEnd, Cost: 30,
+			RatingPlanID: "EVENING", MatchedSubject: "*out:vdf:0:rif", MatchedPrefix: "0256", MatchedDestID: "NAT",
+			TimingID: "WORKDAYS_18", StartTime: "18:00:00", Weight: 10,
+			Rate: 0.5, RateIncrement: time.Second, RateUnit: time.Second,
+			RoundingMethod: utils.ROUNDING_MIDDLE, RoundingDecimals: 4},
+	}
+	if !reflect.DeepEqual(eExpl, cc.Explanation) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eExpl), utils.ToJSON(cc.Explanation))
+	}
+}
+
+func TestCalldescFallbackLoop(t *testing.T) {
+	for _, rpf := range []*RatingProfile{
+		&RatingProfile{Id: "*out:fbloop:0:a", RatingPlanActivations: RatingPlanActivations{&RatingPlanActivation{
+			ActivationTime: time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC), RatingPlanId: "EVENING", FallbackKeys: []string{"*out:fbloop:0:b"}}}},
+		&RatingProfile{Id: "*out:fbloop:0:b", RatingPlanActivations: RatingPlanActivations{&RatingPlanActivation{
+			ActivationTime: time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC), RatingPlanId: "EVENING", FallbackKeys: []string{"*out:fbloop:0:a"}}}},
+	} {
+		if err := dataStorage.SetRatingProfile(rpf, utils.NonTransactional); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cd := &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "fbloop", Subject: "a", Destination: "99999",
+		TimeStart: time.Date(2012, time.February, 2, 17, 30, 0, 0, time.UTC),
+		TimeEnd:   time.Date(2012, time.February, 2, 17, 31, 0, 0, time.UTC)}
+	eErr := "FALLBACK_LOOP:*out:fbloop:0:a->*out:fbloop:0:b->*out:fbloop:0:a"
+	if err := cd.LoadRatingPlans(); err == nil || err.Error() != eErr {
+		t.Errorf("Expecting: %s, received: %v", eErr, err)
+	}
+}
+
+func TestCalldescFallbackMaxDepth(t *testing.T) {
+	for _, rpf := range []*RatingProfile{
+		&RatingProfile{Id: "*out:fbdepth:0:a", RatingPlanActivations: RatingPlanActivations{&RatingPlanActivation{
+			ActivationTime: time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC), RatingPlanId: "EVENING", FallbackKeys: []string{"*out:fbdepth:0:b"}}}},
+		&RatingProfile{Id: "*out:fbdepth:0:b", RatingPlanActivations: RatingPlanActivations{&RatingPlanActivation{
+			ActivationTime: time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC), RatingPlanId: "EVENING", FallbackKeys: []string{"*out:fbdepth:0:c"}}}},
+		&RatingProfile{Id: "*out:fbdepth:0:c", RatingPlanActivations: RatingPlanActivations{&RatingPlanActivation{
+			ActivationTime: time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC), RatingPlanId: "STANDARD"}}},
+	} {
+		if err := dataStorage.SetRatingProfile(rpf, utils.NonTransactional); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cd := &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "fbdepth", Subject: "a", Destination: "49",
+		TimeStart: time.Date(2012, time.February, 2, 17, 30, 0, 0, time.UTC),
+		TimeEnd:   time.Date(2012, time.February, 2, 17, 31, 0, 0, time.UTC)}
+	if err := cd.LoadRatingPlans(); err != nil {
+		t.Fatal(err)
+	}
+	SetRpFallbackMaxDepth(2)
+	defer SetRpFallbackMaxDepth(RECURSION_MAX_DEPTH)
+	cd.RatingInfos = nil
+	eErr := "MAX_RECURSION_DEPTH:*out:fbdepth:0:a->*out:fbdepth:0:b->*out:fbdepth:0:c"
+	if err := cd.LoadRatingPlans(); err == nil || err.Error() != eErr {
+		t.Errorf("Expecting: %s, received: %v", eErr, err)
+	}
+}
+
+func TestCalldescGetCostRatingAsOf(t *testing.T) {
+	snapshotTime := time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for i, factor := range []float64{2, 3} { // tariff as of snapshotTime and its change one day later
+		rp, err := dataStorage.GetRatingPlan("EVENING", true, utils.NonTransactional)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rirate := range rp.Ratings {
+			for _, rate := range rirate.Rates {
+				rate.Value *= factor
+			}
+		}
+		if _, err := dataStorage.GetRatingPlan("EVENING", true, utils.NonTransactional); err != nil { // cache back the current version
+			t.Fatal(err)
+		}
+		tps := &TPSnapshot{SnapshotTime: snapshotTime.AddDate(0, 0, i), RatingPlans: []*RatingPlan{rp}}
+		tps.ID = strconv.FormatInt(tps.SnapshotTime.UnixNano(), 10)
+		if err := dataStorage.SetTPSnapshot(tps); err != nil {
+			t.Fatal(err)
+		}
+		defer dataStorage.RemoveTPSnapshot(tps.ID)
+	}
+	cd := &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "vdf", Subject: "rif", Destination: "0256",
+		TimeStart: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC),
+		TimeEnd:   time.Date(2012, time.February, 2, 18, 1, 0, 0, time.UTC)}
+	if cc, err := cd.Clone().GetCost(); err != nil {
+		t.Fatal(err)
+	} else if cc.Cost != 91 {
+		t.Errorf("Unexpected cost: %v", cc.Cost)
+	}
+	cd.RatingAsOf = snapshotTime.Add(time.Hour)
+	if cc, err := cd.Clone().GetCost(); err != nil {
+		t.Fatal(err)
+	} else if cc.Cost != 181 {
+		t.Errorf("Unexpected cost: %v", cc.Cost)
+	}
+	cd.RatingAsOf = snapshotTime.Add(-time.Hour) // before any snapshot, current data
+	if cc, err := cd.Clone().GetCost(); err != nil {
+		t.Fatal(err)
+	} else if cc.Cost != 91 {
+		t.Errorf("Unexpected cost: %v", cc.Cost)
+	}
+}
+
+type surchargeRatingHook struct {
+	surcharge  float64
+	preRated   []string
+	preRateErr error
+}
+
+func (hook *surchargeRatingHook) PreRating(cd *CallDescriptor) error {
+	hook.preRated = append(hook.preRated, cd.Subject)
+	return hook.preRateErr
+}
+
+func (hook *surchargeRatingHook) PostRating(cd *CallDescriptor, cc *CallCost) error {
+	cc.Cost += hook.surcharge
+	return nil
+}
+
+func TestCalldescRatingHooks(t *testing.T) {
+	hook := &surchargeRatingHook{surcharge: 9}
+	RegisterRatingHook(hook)
+	defer func() { ratingHooks = nil }()
+	cd := &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "vdf", Subject: "rif", Destination: "0256",
+		TimeStart: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC),
+		TimeEnd:   time.Date(2012, time.February, 2, 18, 1, 0, 0, time.UTC)}
+	if cc, err := cd.Clone().GetCost(); err != nil {
+		t.Fatal(err)
+	} else if cc.Cost != 100 {
+		t.Errorf("Unexpected cost: %v", cc.Cost)
+	}
+	if !reflect.DeepEqual([]string{"rif"}, hook.preRated) {
+		t.Errorf("Unexpected pre rated subjects: %v", hook.preRated)
+	}
+	hook.preRateErr = utils.ErrUnauthorizedDestination
+	if _, err := cd.Clone().GetCost(); err != utils.ErrUnauthorizedDestination {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCalldescRatingProfileMaxCost(t *testing.T) {
+	rpf, err := dataStorage.GetRatingProfile("*out:vdf:0:rif", false, utils.NonTransactional)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capped := &RatingProfile{Id: "*out:vdf:0:rifcapped"}
+	for _, rpa := range rpf.RatingPlanActivations {
+		cappedRpa := *rpa
+		cappedRpa.MaxCost = 50
+		cappedRpa.MaxCostStrategy = utils.MAX_COST_CAP
+		capped.RatingPlanActivations = append(capped.RatingPlanActivations, &cappedRpa)
+	}
+	if err := dataStorage.SetRatingProfile(capped, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataStorage.SetAccount(&Account{ID: "vdf:rifcapped", BalanceMap: map[string]Balances{
+		utils.MONETARY: Balances{&Balance{Value: 100, Weight: 10}}}}); err != nil {
+		t.Fatal(err)
+	}
+	cd := &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "vdf", Subject: "rifcapped", Account: "rifcapped",
+		Destination: "0256", TimeStart: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC),
+		TimeEnd: time.Date(2012, time.February, 2, 18, 1, 0, 0, time.UTC)}
+	if cc, err := cd.Clone().GetCost(); err != nil {
+		t.Fatal(err)
+	} else if cc.Cost != 50 {
+		t.Errorf("Unexpected cost: %v", cc.Cost)
+	}
+	if cc, err := cd.Clone().Debit(); err != nil {
+		t.Fatal(err)
+	} else if cc.Cost != 50 {
+		t.Errorf("Unexpected debited cost: %v", cc.Cost)
+	}
+	if acnt, err := dataStorage.GetAccount("vdf:rifcapped"); err != nil {
+		t.Fatal(err)
+	} else if val := acnt.BalanceMap[utils.MONETARY].GetTotalValue(); val != 50 {
+		t.Errorf("Unexpected balance: %v", val)
+	}
+}
+
+func TestCalldescDebugRecords(t *testing.T) {
+	if err := dataStorage.SetAccount(&Account{ID: "vdf:rifdebug", BalanceMap: map[string]Balances{
+		utils.MONETARY: Balances{&Balance{Value: 100, Weight: 10}}}}); err != nil {
+		t.Fatal(err)
+	}
+	cd := &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "vdf", Subject: "rif", Account: "rifdebug",
+		Destination: "0256", TimeStart: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC),
+		TimeEnd: time.Date(2012, time.February, 2, 18, 1, 0, 0, time.UTC), OriginID: "dbg_origin", RunID: utils.META_DEFAULT}
+	if _, err := cd.Clone().GetCost(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetDebugRecords("dbg_origin"); err != utils.ErrNotFound {
+		t.Errorf("Records without debug flag: %v", err)
+	}
+	cd.Debug = true
+	ccCost, err := cd.Clone().GetCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ccDebit, err := cd.Clone().Debit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	drs, err := GetDebugRecords("dbg_origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drs) != 2 || drs[0].Method != "GetCost" || drs[1].Method != "Debit" {
+		t.Fatalf("Unexpected records: %s", utils.ToJSON(drs))
+	}
+	for i, cc := range []*CallCost{ccCost, ccDebit} {
+		if drs[i].RunID != utils.META_DEFAULT || drs[i].Error != "" || len(drs[i].Trace) < 2 {
+			t.Errorf("Unexpected record: %s", utils.ToJSON(drs[i]))
+		}
+		if drs[i].CallCost == nil || drs[i].CallCost.Cost != cc.Cost ||
+			len(drs[i].CallCost.Timespans[0].Increments) <= len(cc.Timespans[0].Increments) { // not compressed
+			t.Errorf("Unexpected CallCost: %s", utils.ToJSON(drs[i].CallCost))
+		}
+	}
+	if !strings.Contains(strings.Join(drs[1].Trace, "\n"), "balances after debit") {
+		t.Errorf("Unexpected trace: %v", drs[1].Trace)
+	}
+	debugRecords.Lock()
+	debugRecords.ttl = time.Minute
+	debugRecords.Unlock()
+	if _, err := debugRecords.get("dbg_origin", time.Now().Add(time.Minute)); err != utils.ErrNotFound {
+		t.Errorf("Expired records returned: %v", err)
+	}
+	SetDebugRecordsTTL(time.Hour)
+	cdr := &CDR{CGRID: "dbg_cgrid", OriginID: "dbg_origin", RunID: utils.META_DEFAULT, Usage: time.Minute,
+		ExtraFields: map[string]string{utils.CGRFlags: utils.FlagForceDuration + utils.INFIELD_SEP + utils.FlagDebug}}
+	if cd := cdrCallDescriptor(cdr, false); !cd.Debug || cd.OriginID != "dbg_origin" || cd.CgrID != "dbg_cgrid" {
+		t.Errorf("Unexpected CallDescriptor: %+v", cd)
+	}
+}
@@ -101,6 +101,7 @@ type CDR struct {
 	ExtraFields     map[string]string // Extra fields to be stored in CDR
 	CostSource      string            // The source of this cost
 	Cost            float64
+	ShadowCost      float64         // Cost out of the candidate rating profile, never charged, -1 if it could not be computed
 	CostDetails     *CallCost       // Attach the cost details to CDR when possible
 	AccountSummary  *AccountSummary // Store AccountSummary information
 	ExtraInfo       string          // Container for extra information related to this CDR, eg: populated with error reason in case of error on calculation
@@ -202,6 +203,8 @@ func (cdr *CDR) FieldAsString(rsrFld *utils.RSRField) string {
 		return rsrFld.ParseValue(strconv.FormatBool(cdr.Rated))
 	case utils.COST:
 		return rsrFld.ParseValue(strconv.FormatFloat(cdr.Cost, 'f', -1, 64)) // Recommended to use FormatCost
+	case utils.ShadowCost:
+		return rsrFld.ParseValue(strconv.FormatFloat(cdr.ShadowCost, 'f', -1, 64))
 	case utils.COST_DETAILS:
 		return rsrFld.ParseValue(cdr.CostDetailsJson())
 	case utils.ACCOUNT_SUMMARY:
@@ -0,0 +1,61 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// CombinedCDR is the view over the CDRs of a multi-leg call (conference, transfers), correlated by MasterOriginID
+type CombinedCDR struct {
+	MasterOriginID string
+	SetupTime      time.Time                // earliest SetupTime out of legs
+	Legs           []*ExternalCDR           // master CDRs first, then legs ordered on SetupTime
+	Usage          map[string]time.Duration // aggregated usage, per RunID
+	Cost           map[string]float64       // aggregated cost, per RunID, unrated legs not considered
+}
+
+// NewCombinedCDR builds the combined view out of the master and leg CDRs
+func NewCombinedCDR(masterOriginID string, cdrs []*CDR) *CombinedCDR {
+	cmbCDR := &CombinedCDR{MasterOriginID: masterOriginID,
+		Usage: make(map[string]time.Duration), Cost: make(map[string]float64)}
+	sort.SliceStable(cdrs, func(i, j int) bool {
+		iMaster, jMaster := cdrs[i].OriginID == masterOriginID, cdrs[j].OriginID == masterOriginID
+		if iMaster != jMaster {
+			return iMaster
+		}
+		return cdrs[i].SetupTime.Before(cdrs[j].SetupTime)
+	})
+	for _, cdr := range cdrs {
+		if cmbCDR.SetupTime.IsZero() || cdr.SetupTime.Before(cmbCDR.SetupTime) {
+			cmbCDR.SetupTime = cdr.SetupTime
+		}
+		cmbCDR.Usage[cdr.RunID] += cdr.Usage
+		if cdr.Cost != -1 {
+			cmbCDR.Cost[cdr.RunID] += cdr.Cost
+		}
+		cmbCDR.Legs = append(cmbCDR.Legs, cdr.AsExternalCDR())
+	}
+	for runID, cost := range cmbCDR.Cost {
+		cmbCDR.Cost[runID] = utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	}
+	return cmbCDR
+}
@@ -585,3 +585,27 @@ func TestCDRAsExportMap(t *testing.T) {
 		t.Errorf("Expecting: %+v, received: %+v", eCDRMp, cdrMp)
 	}
 }
+
+func TestNewCombinedCDR(t *testing.T) {
+	cdrs := []*CDR{
+		&CDR{OriginID: "leg2", RunID: utils.META_DEFAULT, SetupTime: time.Date(2017, 1, 9, 10, 2, 0, 0, time.UTC),
+			Usage: time.Duration(30 * time.Second), Cost: -1, ExtraFields: map[string]string{utils.MasterOriginID: "conf1"}},
+		&CDR{OriginID: "leg1", RunID: utils.META_DEFAULT, SetupTime: time.Date(2017, 1, 9, 10, 1, 0, 0, time.UTC),
+			Usage: time.Duration(time.Minute), Cost: 0.6, ExtraFields: map[string]string{utils.MasterOriginID: "conf1"}},
+		&CDR{OriginID: "conf1", RunID: utils.META_DEFAULT, SetupTime: time.Date(2017, 1, 9, 10, 0, 0, 0, time.UTC),
+			Usage: time.Duration(2 * time.Minute), Cost: 1.2},
+	}
+	cmbCDR := NewCombinedCDR("conf1", cdrs)
+	if len(cmbCDR.Legs) != 3 || cmbCDR.Legs[0].OriginID != "conf1" || cmbCDR.Legs[1].OriginID != "leg1" {
+		t.Errorf("Unexpected legs: %+v", cmbCDR.Legs)
+	}
+	if !cmbCDR.SetupTime.Equal(time.Date(2017, 1, 9, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected SetupTime: %v", cmbCDR.SetupTime)
+	}
+	if cmbCDR.Usage[utils.META_DEFAULT] != time.Duration(210*time.Second) {
+		t.Errorf("Unexpected usage: %v", cmbCDR.Usage)
+	}
+	if cmbCDR.Cost[utils.META_DEFAULT] != 1.8 {
+		t.Errorf("Unexpected cost: %v", cmbCDR.Cost)
+	}
+}
@@ -52,6 +52,9 @@ const (
 func NewCDRExporter(cdrs []*CDR, exportTemplate *config.CdreConfig, exportFormat, exportPath, fallbackPath, exportID string,
 	synchronous bool, attempts int, fieldSeparator rune, usageMultiplyFactor utils.FieldMultiplyFactor,
 	costMultiplyFactor float64, roundingDecimals int, httpSkipTlsCheck bool, httpPoster *utils.HTTPPoster) (*CDRExporter, error) {
+	if exportTemplate != nil {
+		cdrs = selectExportRuns(cdrs, exportTemplate.RunIDs, exportTemplate.SumRuns, roundingDecimals)
+	}
 	if len(cdrs) == 0 { // Nothing to export
 		return nil, nil
 	}
@@ -75,6 +78,52 @@ func NewCDRExporter(cdrs []*CDR, exportTemplate *config.CdreConfig, exportFormat
 	return cdre, nil
 }
 
+// selectExportRuns filters cdrs on runIDs, optionally summing the runs of each call into one CDR
+func selectExportRuns(cdrs []*CDR, runIDs []string, sumRuns bool, roundingDecimals int) []*CDR {
+	if len(runIDs) == 0 && !sumRuns {
+		return cdrs
+	}
+	var selected []*CDR
+	for _, cdr := range cdrs {
+		if cdr == nil {
+			continue
+		}
+		if len(runIDs) != 0 && !utils.IsSliceMember(append([]string{}, runIDs...), cdr.RunID) {
+			continue
+		}
+		selected = append(selected, cdr)
+	}
+	if !sumRuns {
+		return selected
+	}
+	var summed []*CDR
+	callCDRs := make(map[string]*CDR) // summed CDR per CGRID
+	for _, cdr := range selected {
+		sumCDR, has := callCDRs[cdr.CGRID]
+		if !has {
+			sumCDR = cdr.Clone()
+			sumCDR.RunID = utils.MetaSum
+			sumCDR.Cost = -1
+			sumCDR.CostDetails = nil
+			if sumCDR.ExtraFields == nil {
+				sumCDR.ExtraFields = make(map[string]string)
+			}
+			callCDRs[cdr.CGRID] = sumCDR
+			summed = append(summed, sumCDR)
+		}
+		sumCDR.ExtraFields[utils.COST+"_"+cdr.RunID] = strconv.FormatFloat(cdr.Cost, 'f', -1, 64)
+		sumCDR.ExtraFields[utils.USAGE+"_"+cdr.RunID] = cdr.FieldAsString(&utils.RSRField{Id: utils.USAGE})
+		if cdr.Cost == -1 { // unrated runs not considered in the sum
+			continue
+		}
+		if sumCDR.Cost == -1 {
+			sumCDR.Cost = 0
+		}
+		sumCDR.Cost = utils.Round(sumCDR.Cost+cdr.Cost, roundingDecimals, utils.ROUNDING_MIDDLE)
+	}
+	return summed
+}
+
 type CDRExporter struct {
 	sync.RWMutex
 	cdrs                []*CDR
@@ -205,13 +254,13 @@ func (cdre *CDRExporter) composeTrailer() (err error) {
 func (cdre *CDRExporter) postCdr(cdr *CDR) (err error) {
 	var body interface{}
 	switch cdre.exportFormat {
-	case utils.MetaHTTPjsonCDR, utils.MetaAMQPjsonCDR:
+	case utils.MetaHTTPjsonCDR, utils.MetaAMQPjsonCDR, utils.MetaNATSjsonCDR:
 		jsn, err := json.Marshal(cdr)
 		if err != nil {
 			return err
 		}
 		body = jsn
-	case utils.MetaHTTPjsonMap, utils.MetaAMQPjsonMap:
+	case utils.MetaHTTPjsonMap, utils.MetaAMQPjsonMap, utils.MetaNATSjsonMap:
 		expMp, err := cdr.AsExportMap(cdre.exportTemplate.ContentFields, cdre.httpSkipTlsCheck, nil, cdre.roundingDecimals)
 		if err != nil {
 			return err
@@ -258,6 +307,11 @@ func (cdre *CDRExporter) postCdr(cdr *CDR) (err error) {
 				chn.Close()
 			}
 		}
+	case utils.MetaNATSjsonCDR, utils.MetaNATSjsonMap:
+		var natsPoster *utils.NATSPoster
+		if natsPoster, err = utils.NATSPostersCache.GetNATSPoster(cdre.exportPath, cdre.attempts, cdre.fallbackPath); err == nil {
+			err = natsPoster.Post(body.([]byte), fallbackFileName)
+		}
 	}
 	return
 }
@@ -90,3 +90,42 @@ func TestAlternativeFieldSeparator(t *testing.T) {
 		t.Error("Unexpected TotalCost: ", cdre.TotalCost())
 	}
 }
+
+func TestCsvCdrWriterSumRuns(t *testing.T) {
+	writer := &bytes.Buffer{}
+	cfg, _ := config.NewDefaultCGRConfig()
+	cdreCfg := cfg.CdreProfiles["*default"].Clone()
+	cdreCfg.RunIDs = []string{utils.DEFAULT_RUNID, "reseller"}
+	cdreCfg.SumRuns = true
+	cdreCfg.ContentFields = append(cdreCfg.ContentFields[:2],
+		&config.CfgCdrField{Tag: "DefaultCost", Type: utils.META_COMPOSED, Value: utils.ParseRSRFieldsMustCompile("Cost_*default", utils.INFIELD_SEP)},
+		&config.CfgCdrField{Tag: "ResellerCost", Type: utils.META_COMPOSED, Value: utils.ParseRSRFieldsMustCompile("Cost_reseller", utils.INFIELD_SEP)},
+		&config.CfgCdrField{Tag: "Cost", Type: utils.META_COMPOSED, Value: utils.ParseRSRFieldsMustCompile(utils.COST, utils.INFIELD_SEP)})
+	var cdrs []*CDR
+	for _, run := range []struct {
+		runID string
+		cost  float64
+	}{{utils.DEFAULT_RUNID, 1.01}, {"reseller", 0.5}, {"supplier", 0.3}} {
+		cdrs = append(cdrs, &CDR{CGRID: "cgrid1", ToR: utils.VOICE, OriginID: "dsafdsaf", RequestType: utils.META_RATED,
+			Direction: "*out", Tenant: "cgrates.org", Category: "call", Account: "1001", Subject: "1001", Destination: "1002",
+			Usage: 10 * time.Second, RunID: run.runID, Cost: run.cost})
+	}
+	cdre, err := NewCDRExporter(cdrs, cdreCfg, utils.MetaFileCSV, "", "", "sumexport",
+		true, 1, ',', map[string]float64{}, 0.0, cfg.RoundingDecimals, cfg.HttpSkipTlsVerify, nil)
+	if err != nil {
+		t.Fatal("Unexpected error received: ", err)
+	}
+	if err = cdre.processCDRs(); err != nil {
+		t.Error(err)
+	}
+	if err := cdre.writeCsv(csv.NewWriter(writer)); err != nil {
+		t.Error("Unexpected error: ", err)
+	}
+	expected := `cgrid1,*sum,1.01,0.5,1.51000`
+	if result := strings.TrimSpace(writer.String()); result != expected {
+		t.Errorf("Expected: \n%s received: \n%s.", expected, result)
+	}
+	if cdre.TotalExportedCdrs() != 1 {
+		t.Error("Unexpected exported CDRs: ", cdre.TotalExportedCdrs())
+	}
+}
@@ -22,7 +22,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cgrates/cgrates/cache"
@@ -84,9 +86,13 @@ func NewCdrServer(cgrCfg *config.CGRConfig, cdrDb CdrStorage, dataDB DataDB, rat
 	if stats == nil || reflect.ValueOf(stats).IsNil() {
 		stats = nil
 	}
-	return &CdrServer{cgrCfg: cgrCfg, cdrDb: cdrDb, dataDB: dataDB,
+	cdrs := &CdrServer{cgrCfg: cgrCfg, cdrDb: cdrDb, dataDB: dataDB,
 		rals: rater, pubsub: pubsub, users: users, aliases: aliases, stats: stats, guard: guardian.Guardian,
-		httpPoster: utils.NewHTTPPoster(cgrCfg.HttpSkipTlsVerify, cgrCfg.ReplyTimeout)}, nil
+		httpPoster: utils.NewHTTPPoster(cgrCfg.HttpSkipTlsVerify, cgrCfg.ReplyTimeout)}
+	if cgrCfg.CDRSUnratedRetryOnLoad && rater != nil {
+		RegisterTPLoadListener(cdrs.retryUnratedOnLoad)
+	}
+	return cdrs, nil
 }
 
 type CdrServer struct {
@@ -101,6 +107,8 @@ type CdrServer struct {
 	guard         *guardian.GuardianLock
 	responseCache *cache.ResponseCache
 	httpPoster    *utils.HTTPPoster // used for replication
+	retryMux      sync.Mutex        // one unrated CDRs retry at a time
+	retryQueued   int32             // a retry on load waits for the running one
 }
 
 func (self *CdrServer) Timezone() string {
@@ -186,6 +194,9 @@ func (self *CdrServer) processCdr(cdr *CDR) (err error) {
 			return err // Error is propagated back and we don't continue processing the CDR if we cannot store it
 		}
 	}
+	if cdr.Supplier != "" { // Unanswered attempts count towards supplier blacklisting
+		lcrBlacklist.ReportAttempt(cdr.Tenant, cdr.Supplier, cdr.Usage > 0)
+	}
 	// Attach raw CDR to stats
 	if self.stats != nil { // Send raw CDR to stats
 		var out int
@@ -195,13 +206,14 @@ func (self *CdrServer) processCdr(cdr *CDR) (err error) {
 		self.replicateCDRs([]*CDR{cdr})
 	}
 	if self.rals != nil && !cdr.Rated { // CDRs not rated will be processed by Rating
-		go self.deriveRateStoreStatsReplicate(cdr, self.cgrCfg.CDRSStoreCdrs, self.stats != nil, len(self.cgrCfg.CDRSOnlineCDRExports) != 0)
+		go self.deriveRateStoreStatsReplicate(cdr, self.cgrCfg.CDRSStoreCdrs, self.stats != nil, len(self.cgrCfg.CDRSOnlineCDRExports) != 0, false)
 	}
 	return nil
 }
 
 // Returns error if not able to properly store the CDR, mediation is async since we can always recover offline
-func (self *CdrServer) deriveRateStoreStatsReplicate(cdr *CDR, store, stats, replicate bool) error {
+// ratingAsOfSetup rates with the rating data configured at the CDR SetupTime
+func (self *CdrServer) deriveRateStoreStatsReplicate(cdr *CDR, store, stats, replicate, ratingAsOfSetup bool) error {
 	cdrRuns, err := self.deriveCdrs(cdr)
 	if err != nil {
 		utils.Logger.Err(fmt.Sprintf("<CDRS> Deriving CDR %+v, got error: %s", cdr, err.Error()))
@@ -225,7 +237,7 @@ func (self *CdrServer) deriveRateStoreStatsReplicate(cdr *CDR, store, stats, rep
 			utils.Logger.Err(fmt.Sprintf("<CDRS> Aliasing CDR %+v, got error: %s", cdrRun, err.Error()))
 			continue
 		}
-		rcvRatedCDRs, err := self.rateCDR(cdrRun)
+		rcvRatedCDRs, err := self.rateCDR(cdrRun, ratingAsOfSetup)
 		if err != nil {
 			cdrRun.Cost = -1.0 // If there was an error, mark the CDR
 			cdrRun.ExtraInfo = err.Error()
@@ -268,6 +280,9 @@ func (self *CdrServer) deriveRateStoreStatsReplicate(cdr *CDR, store, stats, rep
 				utils.Logger.Err(fmt.Sprintf("<CDRS> Storing rated CDR %+v, got error: %s", ratedCDR, err.Error()))
 			}
 		}
+		if replicaFeeder != nil {
+			replicaFeeder.QueueCDRs(ratedCDRs)
+		}
 	}
 	// Attach CDR to stats
 	if stats { // Send CDR to stats
@@ -294,7 +309,7 @@ func (self *CdrServer) deriveCdrs(cdr *CDR) ([]*CDR, error) {
 	if err := LoadUserProfile(cdr, utils.EXTRA_FIELDS); err != nil {
 		return nil, err
 	}
-	if err := LoadAlias(&AttrMatchingAlias{
+	aliasAttr := &AttrMatchingAlias{
 		Destination: cdr.Destination,
 		Direction:   cdr.Direction,
 		Tenant:      cdr.Tenant,
@@ -302,7 +317,8 @@ func (self *CdrServer) deriveCdrs(cdr *CDR) ([]*CDR, error) {
 		Account:     cdr.Account,
 		Subject:     cdr.Subject,
 		Context:     utils.ALIAS_CONTEXT_RATING,
-	}, cdr, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
+	}
+	if err := LoadAlias(aliasAttr, cdr, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
 		return nil, err
 	}
 	attrsDC := &utils.AttrDerivedChargers{Tenant: cdr.Tenant, Category: cdr.Category, Direction: cdr.Direction,
@@ -312,11 +328,15 @@ func (self *CdrServer) deriveCdrs(cdr *CDR) ([]*CDR, error) {
 		utils.Logger.Err(fmt.Sprintf("Could not get derived charging for cgrid %s, error: %s", cdr.CGRID, err.Error()))
 		return nil, err
 	}
+	srcCDR, err := ResolveDerivedChargersReferences(cdr, dcs.Chargers, aliasAttr)
+	if err != nil {
+		return nil, err
+	}
 	for _, dc := range dcs.Chargers {
 		runFilters, _ := utils.ParseRSRFields(dc.RunFilters, utils.INFIELD_SEP)
 		matchingAllFilters := true
 		for _, dcRunFilter := range runFilters {
-			if !dcRunFilter.FilterPasses(cdr.FieldAsString(dcRunFilter)) {
+			if !dcRunFilter.FilterPasses(srcCDR.FieldAsString(dcRunFilter)) {
 				matchingAllFilters = false
 				break
 			}
@@ -345,7 +365,7 @@ func (self *CdrServer) deriveCdrs(cdr *CDR) ([]*CDR, error) {
 			dcExtraFields = append(dcExtraFields, &utils.RSRField{Id: key})
 		}
 
-		forkedCdr, err := cdr.ForkCdr(dc.RunID, dcRequestTypeFld, dcDirFld, dcTenantFld, dcCategoryFld, dcAcntFld, dcSubjFld, dcDstFld,
+		forkedCdr, err := srcCDR.ForkCdr(dc.RunID, dcRequestTypeFld, dcDirFld, dcTenantFld, dcCategoryFld, dcAcntFld, dcSubjFld, dcDstFld,
 			dcSTimeFld, dcPddFld, dcATimeFld, dcDurFld, dcSupplFld, dcDCauseFld, dcRatedFld, dcCostFld, dcExtraFields, true, self.cgrCfg.DefaultTimezone)
 		if err != nil {
 			utils.Logger.Err(fmt.Sprintf("Could not fork CGR with cgrid %s, run: %s, error: %s", cdr.CGRID, dc.RunID, err.Error()))
@@ -354,14 +374,31 @@ func (self *CdrServer) deriveCdrs(cdr *CDR) ([]*CDR, error) {
 		if !forkedCdr.Rated {
 			forkedCdr.Cost = -1.0 // Make sure that un-rated CDRs start with Cost -1
 		}
-		cdrRuns = append(cdrRuns, forkedCdr)
+		if dc.SplitChargingID == "" {
+			cdrRuns = append(cdrRuns, forkedCdr)
+			continue
+		}
+		if utils.IsSliceMember([]string{utils.META_PREPAID, utils.PREPAID}, forkedCdr.RequestType) {
+			return nil, utils.ErrSplitChargingPrepaid // charged by the sessions, out of the run account
+		}
+		spc, err := self.dataDB.GetSplitCharging(dc.SplitChargingID, false, utils.NonTransactional)
+		if err != nil {
+			utils.Logger.Err(fmt.Sprintf("Could not get split charging %s for cgrid %s, run: %s, error: %s", dc.SplitChargingID, cdr.CGRID, dc.RunID, err.Error()))
+			return nil, err
+		}
+		payerCDRs, err := spc.splitCDR(forkedCdr)
+		if err != nil {
+			utils.Logger.Err(fmt.Sprintf("Could not split CGR with cgrid %s, run: %s, error: %s", cdr.CGRID, dc.RunID, err.Error()))
+			return nil, err
+		}
+		cdrRuns = append(cdrRuns, payerCDRs...)
 	}
 	return cdrRuns, nil
 }
 
 // rateCDR will populate cost field
 // Returns more than one rated CDR in case of SMCost retrieved based on prefix
-func (self *CdrServer) rateCDR(cdr *CDR) ([]*CDR, error) {
+func (self *CdrServer) rateCDR(cdr *CDR, ratingAsOfSetup bool) ([]*CDR, error) {
 	var qryCC *CallCost
 	var err error
 	if cdr.RequestType == utils.META_NONE {
@@ -397,29 +434,60 @@ func (self *CdrServer) rateCDR(cdr *CDR) ([]*CDR, error) {
 				cdrClone.Cost = smCost.CostDetails.Cost
 				cdrClone.CostDetails = smCost.CostDetails
 				cdrClone.CostSource = smCost.CostSource
+				recordRatingVariant(cdrClone, smCost.CostDetails)
 				cdrsRated = append(cdrsRated, cdrClone)
 			}
+			self.shadowRateCDRs(cdrsRated, ratingAsOfSetup)
 			return cdrsRated, nil
 		} else { //calculate CDR as for pseudoprepaid
 			utils.Logger.Warning(fmt.Sprintf("<Cdrs> WARNING: Could not find CallCostLog for cgrid: %s, source: %s, runid: %s, will recalculate", cdr.CGRID, utils.SESSION_MANAGER_SOURCE, cdr.RunID))
-			qryCC, err = self.getCostFromRater(cdr)
+			qryCC, err = self.getCostFromRater(cdr, ratingAsOfSetup)
 		}
 	} else {
-		qryCC, err = self.getCostFromRater(cdr)
+		qryCC, err = self.getCostFromRater(cdr, ratingAsOfSetup)
 	}
 	if err != nil {
 		return nil, err
 	} else if qryCC != nil {
 		cdr.Cost = qryCC.Cost
 		cdr.CostDetails = qryCC
+		recordRatingVariant(cdr, qryCC)
 	}
+	self.shadowRateCDRs([]*CDR{cdr}, ratingAsOfSetup)
 	return []*CDR{cdr}, nil
 }
 
-// Retrive the cost from engine
-func (self *CdrServer) getCostFromRater(cdr *CDR) (*CallCost, error) {
-	cc := new(CallCost)
-	var err error
+// recordRatingVariant marks on the CDR the A/B tested rating plan it was rated with
+func recordRatingVariant(cdr *CDR, cc *CallCost) {
+	if cc == nil || cc.RatingVariant == "" {
+		return
+	}
+	if cdr.ExtraFields == nil {
+		cdr.ExtraFields = make(map[string]string)
+	}
+	cdr.ExtraFields[utils.RatingVariant] = cc.RatingVariant
+}
+
+// shadowRateCDRs populates the ShadowCost of the rated CDRs out of the candidate rating profile, never charging it
+func (self *CdrServer) shadowRateCDRs(cdrs []*CDR, ratingAsOfSetup bool) {
+	if self.cgrCfg.CDRSShadowRatingSubject == "" {
+		return
+	}
+	for _, cdr := range cdrs {
+		cd := cdrCallDescriptor(cdr, ratingAsOfSetup)
+		cd.Subject = self.cgrCfg.CDRSShadowRatingSubject
+		cc := new(CallCost)
+		if err := self.rals.Call("Responder.GetCost", cd, cc); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<CDRS> Shadow rating CDR with cgrid: %s, runid: %s, got error: %s", cdr.CGRID, cdr.RunID, err.Error()))
+			cdr.ShadowCost = -1
+			continue
+		}
+		cdr.ShadowCost = cc.Cost
+	}
+}
+
+// cdrCallDescriptor returns the CallDescriptor used to rate the CDR
+func cdrCallDescriptor(cdr *CDR, ratingAsOfSetup bool) *CallDescriptor {
 	timeStart := cdr.AnswerTime
 	if timeStart.IsZero() { // Fix for FreeSWITCH unanswered calls
 		timeStart = cdr.SetupTime
@@ -437,6 +505,29 @@ func (self *CdrServer) getCostFromRater(cdr *CDR) (*CallCost, error) {
 		DurationIndex:   cdr.Usage,
 		PerformRounding: true,
 	}
+	if ratingAsOfSetup {
+		cd.RatingAsOf = cdr.SetupTime
+	}
+	if share, has := cdr.ExtraFields[utils.CostShare]; has {
+		var err error
+		if cd.CostShare, err = strconv.ParseFloat(share, 64); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<CDRS> Invalid cost share %q for cgrid: %s, runid: %s", share, cdr.CGRID, cdr.RunID))
+		}
+	}
+	if hasCGRFlag(cdr.ExtraFields, utils.FlagDebug) {
+		cd.Debug = true
+		cd.OriginID = cdr.OriginID
+		cd.CgrID = cdr.CGRID
+		cd.RunID = cdr.RunID
+	}
+	return cd
+}
+
+// Retrive the cost from engine
+func (self *CdrServer) getCostFromRater(cdr *CDR, ratingAsOfSetup bool) (*CallCost, error) {
+	cc := new(CallCost)
+	var err error
+	cd := cdrCallDescriptor(cdr, ratingAsOfSetup)
 	if utils.IsSliceMember([]string{utils.META_PSEUDOPREPAID, utils.META_POSTPAID, utils.META_PREPAID, utils.PSEUDOPREPAID, utils.POSTPAID, utils.PREPAID}, cdr.RequestType) { // Prepaid - Cost can be recalculated in case of missing records from SM
 		err = self.rals.Call("Responder.Debit", cd, cc)
 	} else {
@@ -469,12 +560,22 @@ func (self *CdrServer) replicateCDRs(cdrs []*CDR) (err error) {
 
 // Called by rate/re-rate API, FixMe: deprecate it once new APIer structure is operational
 func (self *CdrServer) RateCDRs(cdrFltr *utils.CDRsFilter, sendToStats bool) error {
+	return self.RateCDRsWithJob(cdrFltr, sendToStats, false, nil)
+}
+
+// RateCDRsWithJob (re-)rates the CDRs, reporting progress to job and stopping on its cancellation
+// ratingAsOfSetup rates each CDR with the rating data configured at its SetupTime
+func (self *CdrServer) RateCDRsWithJob(cdrFltr *utils.CDRsFilter, sendToStats, ratingAsOfSetup bool, job *utils.Job) error {
 	cdrs, _, err := self.cdrDb.GetCDRs(cdrFltr, false)
 	if err != nil {
 		return err
 	}
-	for _, cdr := range cdrs {
-		if err := self.deriveRateStoreStatsReplicate(cdr, self.cgrCfg.CDRSStoreCdrs, sendToStats, len(self.cgrCfg.CDRSOnlineCDRExports) != 0); err != nil {
+	for i, cdr := range cdrs {
+		if job.Cancelled() {
+			return utils.ErrJobCancelled
+		}
+		job.SetProgress(i, len(cdrs))
+		if err := self.deriveRateStoreStatsReplicate(cdr, self.cgrCfg.CDRSStoreCdrs, sendToStats, len(self.cgrCfg.CDRSOnlineCDRExports) != 0, ratingAsOfSetup); err != nil {
 			utils.Logger.Err(fmt.Sprintf("<CDRS> Processing CDR %+v, got error: %s", cdr, err.Error()))
 		}
 	}
@@ -592,8 +693,9 @@ func (self *CdrServer) V1RateCDRs(attrs utils.AttrRateCDRs, reply *string) error
 	if attrs.ReplicateCDRs != nil {
 		replicate = *attrs.ReplicateCDRs
 	}
+	ratingAsOfSetup := attrs.RatingAsOfSetupTime != nil && *attrs.RatingAsOfSetupTime
 	for _, cdr := range cdrs {
-		if err := self.deriveRateStoreStatsReplicate(cdr, storeCDRs, sendToStats, replicate); err != nil {
+		if err := self.deriveRateStoreStatsReplicate(cdr, storeCDRs, sendToStats, replicate, ratingAsOfSetup); err != nil {
 			utils.Logger.Err(fmt.Sprintf("<CDRS> Processing CDR %+v, got error: %s", cdr, err.Error()))
 		}
 	}
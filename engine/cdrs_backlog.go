@@ -0,0 +1,104 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// unratedCDRsFilter matches the derived CDRs stored with rating errors
+func unratedCDRsFilter() *utils.CDRsFilter {
+	return &utils.CDRsFilter{NotRunIDs: []string{utils.MetaRaw},
+		MinCost: utils.Float64Pointer(-1.0), MaxCost: utils.Float64Pointer(0.0)}
+}
+
+// UnratedBacklog returns the number of CDRs stored with rating errors, indexed on error, counted inside StorDB
+func (self *CdrServer) UnratedBacklog() (map[string]int64, error) {
+	aggrs, err := self.cdrDb.GetCDRsAggregates(unratedCDRsFilter(), []string{utils.ExtraInfo})
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	backlog := make(map[string]int64)
+	for _, aggr := range aggrs {
+		errClass := aggr.ExtraInfo
+		if errClass == "" {
+			errClass = utils.MetaUnknown
+		}
+		backlog[errClass] += aggr.Count
+	}
+	return backlog, nil
+}
+
+// RetryUnratedCDRs re-rates the CDRs stored with rating errors, returning the ones still failing
+// One retry runs at a time so the CDRs are not rated, and their accounts debited, twice
+func (self *CdrServer) RetryUnratedCDRs() (remaining int64, err error) {
+	self.retryMux.Lock()
+	defer self.retryMux.Unlock()
+	return self.retryUnratedCDRs()
+}
+
+// retryUnratedCDRs does the retry, retryMux should be held by the caller
+func (self *CdrServer) retryUnratedCDRs() (remaining int64, err error) {
+	if err = self.RateCDRs(unratedCDRsFilter(), self.stats != nil); err != nil && err != utils.ErrNotFound {
+		return
+	}
+	backlog, err := self.UnratedBacklog()
+	if err != nil {
+		return
+	}
+	for errClass, cnt := range backlog {
+		utils.Logger.Warning(fmt.Sprintf("<CDRS> %d CDRs still unrated with error: %s", cnt, errClass))
+		remaining += cnt
+	}
+	return
+}
+
+// retryUnratedOnLoad is registered as tariff plan load listener
+// Loads happening during a retry queue one more, the loads queued together sharing it
+func (self *CdrServer) retryUnratedOnLoad() {
+	if !atomic.CompareAndSwapInt32(&self.retryQueued, 0, 1) {
+		return // the queued retry will rate with this load too
+	}
+	self.retryMux.Lock()
+	defer self.retryMux.Unlock()
+	atomic.StoreInt32(&self.retryQueued, 0) // loads from now on need a new retry
+	if _, err := self.retryUnratedCDRs(); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<CDRS> Retrying unrated CDRs, got error: %s", err.Error()))
+	}
+}
+
+// V1GetUnratedBacklog returns the CDRs stored with rating errors, counted per error
+func (self *CdrServer) V1GetUnratedBacklog(ignored string, reply *map[string]int64) error {
+	backlog, err := self.UnratedBacklog()
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = backlog
+	return nil
+}
+
+// V1RetryUnratedCDRs re-rates the CDRs stored with rating errors, replying with the number still failing
+func (self *CdrServer) V1RetryUnratedCDRs(ignored string, reply *int64) (err error) {
+	if *reply, err = self.RetryUnratedCDRs(); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	return
+}
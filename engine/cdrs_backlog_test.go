@@ -0,0 +1,122 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// backlogCdrStorage counts the unrated CDRs queries, blocking the first rating one until released
+type backlogCdrStorage struct {
+	CdrStorage
+	aggrs    []*utils.CDRsAggregate
+	groupBy  []string
+	entered  chan struct{}
+	release  chan struct{}
+	mux      sync.Mutex
+	getCDRs  int
+	running  int
+	parallel bool // two ratings were running together
+}
+
+func (bcs *backlogCdrStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR, int64, error) {
+	bcs.mux.Lock()
+	bcs.getCDRs++
+	first := bcs.getCDRs == 1
+	bcs.running++
+	if bcs.running > 1 {
+		bcs.parallel = true
+	}
+	bcs.mux.Unlock()
+	if first {
+		close(bcs.entered)
+		<-bcs.release
+	}
+	bcs.mux.Lock()
+	bcs.running--
+	bcs.mux.Unlock()
+	return nil, 0, utils.ErrNotFound
+}
+
+func (bcs *backlogCdrStorage) GetCDRsAggregates(qryFltr *utils.CDRsFilter, groupBy []string) ([]*utils.CDRsAggregate, error) {
+	bcs.groupBy = groupBy
+	if len(bcs.aggrs) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return bcs.aggrs, nil
+}
+
+func TestCdrServerUnratedBacklog(t *testing.T) {
+	cdrDb := &backlogCdrStorage{aggrs: []*utils.CDRsAggregate{
+		&utils.CDRsAggregate{ExtraInfo: utils.ErrNotFound.Error(), Count: 3},
+		&utils.CDRsAggregate{Count: 1}}}
+	cdrS := &CdrServer{cdrDb: cdrDb}
+	eBacklog := map[string]int64{utils.ErrNotFound.Error(): 3, utils.MetaUnknown: 1}
+	if backlog, err := cdrS.UnratedBacklog(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eBacklog, backlog) {
+		t.Errorf("Expecting: %+v, received: %+v", eBacklog, backlog)
+	}
+	if !reflect.DeepEqual([]string{utils.ExtraInfo}, cdrDb.groupBy) {
+		t.Errorf("Unexpected group by: %v", cdrDb.groupBy)
+	}
+	if cdrDb.getCDRs != 0 {
+		t.Errorf("CDRs queried %d times for counting", cdrDb.getCDRs)
+	}
+	cdrDb.aggrs = nil
+	if backlog, err := cdrS.UnratedBacklog(); err != nil {
+		t.Error(err)
+	} else if len(backlog) != 0 {
+		t.Errorf("Unexpected backlog: %+v", backlog)
+	}
+}
+
+func TestCdrServerRetryUnratedOnLoad(t *testing.T) {
+	cdrDb := &backlogCdrStorage{entered: make(chan struct{}), release: make(chan struct{})}
+	cdrS := &CdrServer{cdrDb: cdrDb}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		cdrS.retryUnratedOnLoad()
+		wg.Done()
+	}()
+	<-cdrDb.entered
+	go func() { // queued behind the running retry
+		cdrS.retryUnratedOnLoad()
+		wg.Done()
+	}()
+	for atomic.LoadInt32(&cdrS.retryQueued) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cdrS.retryUnratedOnLoad() // sharing the queued retry
+	cdrS.retryUnratedOnLoad()
+	close(cdrDb.release)
+	wg.Wait()
+	if cdrDb.getCDRs != 2 {
+		t.Errorf("Expecting 2 retries, received: %d", cdrDb.getCDRs)
+	}
+	if cdrDb.parallel {
+		t.Error("Retries running in parallel")
+	}
+}
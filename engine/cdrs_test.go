@@ -0,0 +1,185 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestCdrServerShadowRate(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.CDRSShadowRatingSubject = "minu"
+	cdrS := &CdrServer{cgrCfg: cfg, rals: new(Responder)}
+	cdr := &CDR{CGRID: "shadow1", RunID: utils.META_DEFAULT, ToR: utils.VOICE, RequestType: utils.META_RATED,
+		Direction: utils.OUT, Tenant: "vdf", Category: "0", Account: "rif", Subject: "rif", Destination: "0256",
+		SetupTime: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC), AnswerTime: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC),
+		Usage: 2 * time.Minute}
+	cd := cdrCallDescriptor(cdr, false)
+	cd.Subject = cfg.CDRSShadowRatingSubject
+	eCC, err := cd.GetCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cdrs, err := cdrS.rateCDR(cdr.Clone(), false); err != nil {
+		t.Fatal(err)
+	} else if len(cdrs) != 1 || cdrs[0].Cost != 91 || cdrs[0].ShadowCost != eCC.Cost {
+		t.Errorf("Unexpected costs: %+v", cdrs)
+	}
+	cfg.CDRSShadowRatingSubject = "one" // candidate not covering the call, live rating unaffected
+	if cdrs, err := cdrS.rateCDR(cdr.Clone(), false); err != nil {
+		t.Fatal(err)
+	} else if cdrs[0].Cost != 91 || cdrs[0].ShadowCost != -1 {
+		t.Errorf("Unexpected costs: %+v", cdrs)
+	}
+	cfg.CDRSShadowRatingSubject = ""
+	if cdrs, err := cdrS.rateCDR(cdr.Clone(), false); err != nil {
+		t.Fatal(err)
+	} else if cdrs[0].ShadowCost != 0 {
+		t.Errorf("Unexpected shadow cost: %v", cdrs[0].ShadowCost)
+	}
+}
+
+func TestCdrServerSplitCharging(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cdrS := &CdrServer{cgrCfg: cfg, dataDB: dataStorage, rals: new(Responder)}
+	if err := dataStorage.SetSplitCharging(&SplitCharging{ID: "SPC_CORP",
+		Payers: []*SplitPayer{&SplitPayer{Account: "corp", Weight: 70}, &SplitPayer{Account: "spcemp", Weight: 30}}},
+		utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	dc, _ := utils.NewDerivedCharger("split", "", "^"+utils.META_RATED, utils.META_DEFAULT, utils.META_DEFAULT, utils.META_DEFAULT, utils.META_DEFAULT,
+		"^rif", utils.META_DEFAULT, utils.META_DEFAULT, utils.META_DEFAULT, utils.META_DEFAULT, utils.META_DEFAULT, utils.META_DEFAULT,
+		utils.META_DEFAULT, utils.META_DEFAULT, utils.META_DEFAULT)
+	dc.SplitChargingID = "SPC_CORP"
+	if err := dataStorage.SetDerivedChargers(utils.DerivedChargersKey(utils.OUT, "vdf", "0", "spcemp", "spcemp"),
+		&utils.DerivedChargers{Chargers: []*utils.DerivedCharger{dc}}, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	cdr := &CDR{CGRID: "split1", RunID: utils.MetaRaw, ToR: utils.VOICE, RequestType: utils.META_NONE,
+		Direction: utils.OUT, Tenant: "vdf", Category: "0", Account: "spcemp", Subject: "spcemp", Destination: "0256",
+		SetupTime: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC), AnswerTime: time.Date(2012, time.February, 2, 17, 59, 0, 0, time.UTC),
+		Usage: 2 * time.Minute, ExtraFields: map[string]string{}}
+	cdrRuns, err := cdrS.deriveCdrs(cdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cdrRuns) != 3 {
+		t.Fatalf("Unexpected runs: %+v", cdrRuns)
+	}
+	var total float64
+	for i, eAcnt := range []string{"corp", "spcemp"} {
+		payerCDR := cdrRuns[i+1]
+		if payerCDR.CGRID != cdr.CGRID || payerCDR.RunID != "split:"+eAcnt || payerCDR.Account != eAcnt ||
+			payerCDR.Subject != "rif" || payerCDR.ExtraFields[utils.SplitChargingID] != "SPC_CORP" {
+			t.Errorf("Unexpected payer CDR: %+v", payerCDR)
+		}
+		rated, err := cdrS.rateCDR(payerCDR, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += rated[0].Cost
+	}
+	if cdrRuns[1].Cost != 63.7 || cdrRuns[2].Cost != 27.3 || total != 91 {
+		t.Errorf("Unexpected split costs: %v, %v", cdrRuns[1].Cost, cdrRuns[2].Cost)
+	}
+	// the run is not dropped silently when it cannot be split
+	dc.SplitChargingID = "SPC_MISSING"
+	dataStorage.SetDerivedChargers(utils.DerivedChargersKey(utils.OUT, "vdf", "0", "spcemp", "spcemp"),
+		&utils.DerivedChargers{Chargers: []*utils.DerivedCharger{dc}}, utils.NonTransactional)
+	if _, err := cdrS.deriveCdrs(cdr); err != utils.ErrNotFound {
+		t.Errorf("Expecting not found, received: %v", err)
+	}
+	dc.SplitChargingID, dc.RequestTypeField = "SPC_CORP", "^"+utils.META_PREPAID
+	dataStorage.SetDerivedChargers(utils.DerivedChargersKey(utils.OUT, "vdf", "0", "spcemp", "spcemp"),
+		&utils.DerivedChargers{Chargers: []*utils.DerivedCharger{dc}}, utils.NonTransactional)
+	if _, err := cdrS.deriveCdrs(cdr); err != utils.ErrSplitChargingPrepaid {
+		t.Errorf("Expecting %v, received: %v", utils.ErrSplitChargingPrepaid, err)
+	}
+}
+
+type replicaTestConn struct {
+	fail    bool
+	batches []*ReplicaBatch
+}
+
+func (rc *replicaTestConn) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	if rc.fail {
+		return utils.ErrServerError
+	}
+	rc.batches = append(rc.batches, args.(*ReplicaBatch))
+	return nil
+}
+
+func TestReplicaFeederFlush(t *testing.T) {
+	conn := &replicaTestConn{fail: true}
+	rf := &ReplicaFeeder{conns: conn, interval: time.Hour, accounts: make(map[string]*ReplicaAccount)}
+	rf.QueueCDRs([]*CDR{&CDR{CGRID: "cgrid1", RunID: utils.META_DEFAULT, Cost: 1}})
+	rf.QueueAccount(&Account{ID: "cgrates.org:1001", BalanceMap: map[string]Balances{utils.MONETARY: Balances{&Balance{Value: 10}}}})
+	if err := rf.Flush(); err == nil {
+		t.Error("Expecting error")
+	}
+	// newer account state queued while the replicas were down wins over the requeued one
+	rf.QueueAccount(&Account{ID: "cgrates.org:1001", BalanceMap: map[string]Balances{utils.MONETARY: Balances{&Balance{Value: 7}}}})
+	conn.fail = false
+	if err := rf.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.batches) != 1 {
+		t.Fatalf("Unexpected batches: %+v", conn.batches)
+	}
+	if batch := conn.batches[0]; len(batch.CDRs) != 1 || batch.CDRs[0].CGRID != "cgrid1" ||
+		len(batch.Accounts) != 1 || batch.Accounts[0].Sequence != 2 ||
+		batch.Accounts[0].Account.BalanceMap[utils.MONETARY][0].Value != 7 {
+		t.Errorf("Unexpected batch: %+v", batch)
+	}
+	if err := rf.Flush(); err != nil || len(conn.batches) != 1 { // nothing left to send
+		t.Errorf("Unexpected flush: %v, batches: %d", err, len(conn.batches))
+	}
+}
+
+func TestReplicaFeederNoIntervalOrder(t *testing.T) {
+	conn := new(replicaTestConn)
+	rf := NewReplicaFeeder(conn, 0)
+	for i := 0; i < 50; i++ {
+		rf.QueueAccount(&Account{ID: "cgrates.org:1001", BalanceMap: map[string]Balances{utils.MONETARY: Balances{&Balance{Value: float64(i)}}}})
+	}
+	var sent []*ReplicaAccount
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		rf.flushMux.Lock()
+		sent = sent[:0]
+		for _, batch := range conn.batches {
+			sent = append(sent, batch.Accounts...)
+		}
+		rf.flushMux.Unlock()
+		if len(sent) != 0 && sent[len(sent)-1].Account.BalanceMap[utils.MONETARY][0].Value == 49 {
+			break
+		}
+	}
+	if len(sent) == 0 || sent[len(sent)-1].Account.BalanceMap[utils.MONETARY][0].Value != 49 {
+		t.Fatalf("Last account state not replicated: %+v", sent)
+	}
+	for i := 1; i < len(sent); i++ {
+		if sent[i].Sequence <= sent[i-1].Sequence {
+			t.Errorf("Account states sent out of order: %d after %d", sent[i].Sequence, sent[i-1].Sequence)
+		}
+	}
+}
@@ -121,15 +121,10 @@ func (cs *CdrStats) AcceptCdr(cdr *CDR) bool {
 	}
 	if len(cs.DestinationIds) > 0 {
 		found := false
-		for _, p := range utils.SplitPrefix(cdr.Destination, MIN_PREFIX_MATCH) {
-			if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-				for _, idID := range destIDs {
-					if utils.IsSliceMember(cs.DestinationIds, idID) {
-						found = true
-						break
-					}
-				}
-				if found {
+		for _, dm := range matchReverseDestinations(dataStorage, cdr.Destination) {
+			for _, idID := range dm.DestIDs {
+				if utils.IsSliceMember(cs.DestinationIds, idID) {
+					found = true
 					break
 				}
 			}
@@ -0,0 +1,52 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import "fmt"
+
+// limits the amount of calls rated by one batch
+const MaxCostBatchSize = 10000
+
+// BatchCost is the outcome of rating one of the calls in a batch
+type BatchCost struct {
+	CallCost *CallCost
+	Error    string // the call could not be rated
+}
+
+// GetCostBatch rates the calls in parallel, returning their costs in the order received so one failure does not affect the others
+func (rs *Responder) GetCostBatch(args []*CallDescriptor, reply *[]*BatchCost) error {
+	if len(args) > MaxCostBatchSize {
+		return fmt.Errorf("more than %d calls", MaxCostBatchSize)
+	}
+	costs := make([]*BatchCost, len(args))
+	processConcurrently(len(args), func(i int) error {
+		if args[i] == nil {
+			costs[i] = &BatchCost{Error: "missing call descriptor"}
+			return nil
+		}
+		var cc CallCost
+		if err := rs.GetCost(args[i], &cc); err != nil {
+			costs[i] = &BatchCost{Error: err.Error()}
+			return nil
+		}
+		costs[i] = &BatchCost{CallCost: &cc}
+		return nil
+	})
+	*reply = costs
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// limits the amount of rating done by one simulation
+const MaxCostSimulationSamples = 10000
+
+// CostSimulation rates the same call started at regular steps within a time range
+type CostSimulation struct {
+	Direction, Category, Tenant, Account, Subject, Destination, TOR string
+	Usage                                                           time.Duration
+	TimeStart, TimeEnd                                              time.Time // range of call start times
+	Step                                                            string    // *hourly(default) or *daily
+}
+
+// startTimes returns the call start times to be rated
+func (cs *CostSimulation) startTimes() (sTimes []time.Time, err error) {
+	if cs.TimeEnd.Before(cs.TimeStart) {
+		return nil, fmt.Errorf("TimeEnd before TimeStart")
+	}
+	var nextStart func(t time.Time) time.Time
+	switch cs.Step {
+	case "", utils.MetaHourly:
+		nextStart = func(t time.Time) time.Time { return t.Add(time.Hour) }
+	case utils.MetaDaily:
+		nextStart = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	default:
+		return nil, fmt.Errorf("unsupported step: %s", cs.Step)
+	}
+	for sTime := cs.TimeStart; !sTime.After(cs.TimeEnd); sTime = nextStart(sTime) {
+		if len(sTimes) == MaxCostSimulationSamples {
+			return nil, fmt.Errorf("more than %d samples", MaxCostSimulationSamples)
+		}
+		sTimes = append(sTimes, sTime)
+	}
+	return
+}
+
+// CostSample is the cost of the simulated call started at TimeStart
+type CostSample struct {
+	TimeStart time.Time
+	Cost      float64
+	Currency  string
+	Ratings   []*CostSampleRating // ratings applied, one per timespan
+	Error     string              // the call could not be rated at this time
+}
+
+// CostSampleRating shows which rating plan activation and rate interval priced part of the call
+type CostSampleRating struct {
+	TimeStart, TimeEnd time.Time
+	Cost               float64
+	MatchedSubject     string
+	MatchedDestId      string
+	RatingPlanId       string
+	ActivationTime     time.Time
+	RateInterval       *RateInterval
+}
+
+// NewCostSample builds the sample out of the CallCost rated for the simulated call
+func NewCostSample(timeStart time.Time, cc *CallCost) *CostSample {
+	cs := &CostSample{TimeStart: timeStart, Cost: cc.Cost, Currency: cc.Currency}
+	for _, ts := range cc.Timespans {
+		csr := &CostSampleRating{
+			TimeStart:      ts.TimeStart,
+			TimeEnd:        ts.TimeEnd,
+			Cost:           ts.Cost,
+			MatchedSubject: ts.MatchedSubject,
+			MatchedDestId:  ts.MatchedDestId,
+			RatingPlanId:   ts.RatingPlanId,
+			RateInterval:   ts.RateInterval,
+		}
+		if ts.ratingInfo != nil {
+			csr.ActivationTime = ts.ratingInfo.ActivationTime
+		}
+		cs.Ratings = append(cs.Ratings, csr)
+	}
+	return cs
+}
+
+// SimulateCosts rates the call at each step of the time range, used to check rate decks before going live
+func (rs *Responder) SimulateCosts(arg *CostSimulation, reply *[]*CostSample) error {
+	sTimes, err := arg.startTimes()
+	if err != nil {
+		return err
+	}
+	samples := make([]*CostSample, len(sTimes))
+	for i, sTime := range sTimes {
+		cd := &CallDescriptor{
+			Direction:     arg.Direction,
+			Category:      arg.Category,
+			Tenant:        arg.Tenant,
+			Account:       arg.Account,
+			Subject:       arg.Subject,
+			Destination:   arg.Destination,
+			TOR:           arg.TOR,
+			TimeStart:     sTime,
+			TimeEnd:       sTime.Add(arg.Usage),
+			DurationIndex: arg.Usage,
+		}
+		var cc CallCost
+		if err := rs.GetCost(cd, &cc); err != nil {
+			samples[i] = &CostSample{TimeStart: sTime, Error: err.Error()}
+			continue
+		}
+		samples[i] = NewCostSample(sTime, &cc)
+	}
+	*reply = samples
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+const debugRecordsPerOriginID = 100 // the oldest records are dropped past this limit
+
+var debugRecords = &debugStore{records: make(map[string][]*DebugRecord), ttl: time.Hour}
+
+// SetDebugRecordsTTL sets how long the records of the debugged calls are kept
+func SetDebugRecordsTTL(ttl time.Duration) {
+	debugRecords.Lock()
+	debugRecords.ttl = ttl
+	debugRecords.Unlock()
+}
+
+// GetDebugRecords returns the records of the debugged calls with originID, oldest first
+func GetDebugRecords(originID string) ([]*DebugRecord, error) {
+	return debugRecords.get(originID, time.Now())
+}
+
+// DebugRecord keeps the trace of one rating request out of a call flagged for debugging
+type DebugRecord struct {
+	OriginID string
+	CGRID    string
+	RunID    string
+	Method   string // <GetCost|Debit|MaxDebit>
+	Time     time.Time
+	Trace    []string  // verbose steps taken while rating
+	CallCost *CallCost // the uncompressed CallCost, with all its increments
+	Error    string
+}
+
+// debugStore keeps the DebugRecords in memory, indexed on OriginID
+type debugStore struct {
+	sync.RWMutex
+	records map[string][]*DebugRecord
+	ttl     time.Duration
+}
+
+func (ds *debugStore) set(dr *DebugRecord) {
+	ds.Lock()
+	defer ds.Unlock()
+	ds.removeExpired(dr.Time)
+	drs := append(ds.records[dr.OriginID], dr)
+	if len(drs) > debugRecordsPerOriginID {
+		drs = drs[len(drs)-debugRecordsPerOriginID:]
+	}
+	ds.records[dr.OriginID] = drs
+}
+
+func (ds *debugStore) get(originID string, now time.Time) ([]*DebugRecord, error) {
+	ds.RLock()
+	defer ds.RUnlock()
+	var drs []*DebugRecord
+	for _, dr := range ds.records[originID] {
+		if ds.ttl == 0 || now.Sub(dr.Time) < ds.ttl {
+			drs = append(drs, dr)
+		}
+	}
+	if len(drs) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return drs, nil
+}
+
+// removeExpired drops the OriginIDs whose last record is older than ttl, has no locks
+func (ds *debugStore) removeExpired(now time.Time) {
+	if ds.ttl == 0 {
+		return
+	}
+	for originID, drs := range ds.records {
+		if now.Sub(drs[len(drs)-1].Time) >= ds.ttl {
+			delete(ds.records, originID)
+		}
+	}
+}
+
+// hasCGRFlag checks the flags received in the CGRFlags extra field
+func hasCGRFlag(extraFields map[string]string, flag string) bool {
+	flagsStr, hasFlags := extraFields[utils.CGRFlags]
+	if !hasFlags {
+		return false
+	}
+	_, has := utils.StringMapFromSlice(strings.Split(flagsStr, utils.INFIELD_SEP))[flag]
+	return has
+}
+
+// debugf adds a step to the trace of the calls flagged for debugging
+func (cd *CallDescriptor) debugf(format string, args ...interface{}) {
+	if !cd.Debug {
+		return
+	}
+	cd.debugTrace = append(cd.debugTrace, time.Now().Format(time.RFC3339Nano)+" "+fmt.Sprintf(format, args...))
+}
+
+// retainCallCost copies the CallCost before compressing it so the debug record has all of its increments
+func (cd *CallDescriptor) retainCallCost(cc *CallCost) {
+	if !cd.Debug || cc == nil {
+		return
+	}
+	cd.debugCallCost = new(CallCost)
+	if b, err := json.Marshal(cc); err != nil {
+		cd.debugf("cannot retain the CallCost: %s", err.Error())
+	} else if err = json.Unmarshal(b, cd.debugCallCost); err != nil {
+		cd.debugf("cannot retain the CallCost: %s", err.Error())
+	}
+}
+
+// storeDebugRecord writes the trace of a rating request flagged for debugging into the debug store
+func (cd *CallDescriptor) storeDebugRecord(method string, cc *CallCost, err error) {
+	if !cd.Debug {
+		return
+	}
+	dr := &DebugRecord{
+		OriginID: cd.OriginID,
+		CGRID:    cd.CgrID,
+		RunID:    cd.RunID,
+		Method:   method,
+		Time:     time.Now(),
+		Trace:    cd.debugTrace,
+		CallCost: cd.debugCallCost,
+	}
+	if dr.CallCost == nil {
+		dr.CallCost = cc
+	}
+	if err != nil {
+		dr.Error = err.Error()
+	}
+	debugRecords.set(dr)
+	cd.debugTrace, cd.debugCallCost = nil, nil
+	utils.Logger.Info(fmt.Sprintf("<Rater> Stored debug record of %s for OriginID: %s, RunID: %s", method, cd.OriginID, cd.RunID))
+}
+
+// uncachedRatingData reads the rating data of the calls flagged for debugging bypassing the cache
+type uncachedRatingData struct {
+	ratingDataGetter
+}
+
+func (urd uncachedRatingData) GetRatingPlan(id string, skipCache bool, transactionID string) (*RatingPlan, error) {
+	return urd.ratingDataGetter.GetRatingPlan(id, true, transactionID)
+}
+
+func (urd uncachedRatingData) GetRatingProfile(key string, skipCache bool, transactionID string) (*RatingProfile, error) {
+	return urd.ratingDataGetter.GetRatingProfile(key, true, transactionID)
+}
+
+func (urd uncachedRatingData) GetReverseDestination(prefix string, skipCache bool, transactionID string) ([]string, error) {
+	return urd.ratingDataGetter.GetReverseDestination(prefix, true, transactionID)
+}
@@ -19,6 +19,7 @@ package engine
 
 import (
 	"encoding/json"
+	"net"
 	"strings"
 
 	"github.com/cgrates/cgrates/utils"
@@ -39,7 +40,7 @@ type Destination struct {
 	Prefixes []string
 }
 
-// returns prefix precision
+// returns prefix precision, network length for IP prefixes
 func (d *Destination) containsPrefix(prefix string) int {
 	if d == nil {
 		return 0
@@ -48,6 +49,13 @@ func (d *Destination) containsPrefix(prefix string) int {
 		if strings.Index(prefix, p) == 0 {
 			return len(p)
 		}
+		if !utils.IsIPPrefix(p) {
+			continue
+		}
+		if _, ipNet, _ := net.ParseCIDR(p); ipNet.Contains(net.ParseIP(prefix)) {
+			ones, _ := ipNet.Mask.Size()
+			return ones
+		}
 	}
 	return 0
 }
@@ -76,6 +84,34 @@ func (d *Destination) GetHistoryRecord(deleted bool) history.Record {
 	}
 }
 
+// destinationSplitter is implemented by the DataDBs able to narrow down the reverse destination keys to check
+type destinationSplitter interface {
+	SplitDestination(dst string) []string
+}
+
+// splitDestination returns the reverse destination keys to check for dst, longest first
+func splitDestination(dst string) []string {
+	if ds, canCast := dataStorage.(destinationSplitter); canCast {
+		return ds.SplitDestination(dst)
+	}
+	return utils.SplitDestination(dst, MIN_PREFIX_MATCH)
+}
+
+// LoadIPPrefixLengths records the network lengths of the IP reverse destinations stored in dataDB,
+// so matching IP addresses probes only those
+func LoadIPPrefixLengths(dataDB DataDB) (err error) {
+	for _, family := range []string{utils.MetaIPv4, utils.MetaIPv6} {
+		keys, err := dataDB.GetKeysForPrefix(utils.REVERSE_DESTINATION_PREFIX + family + ":")
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			utils.AddIPPrefixKey(key[len(utils.REVERSE_DESTINATION_PREFIX):])
+		}
+	}
+	return
+}
+
 // Reverse search in cache to see if prefix belongs to destination id
 func CachedDestHasPrefix(destId, prefix string) bool {
 	if cached, err := dataStorage.GetReverseDestination(prefix, false, utils.NonTransactional); err == nil {
@@ -108,3 +144,36 @@ func CachedDestHasPrefix(destId, prefix string) bool {
 	}
 }
 */
+
+// destinationMatch holds the destination IDs of one prefix matched by a number
+type destinationMatch struct {
+	Prefix  string
+	DestIDs []string
+}
+
+type reverseDestinationGetter interface {
+	GetReverseDestination(key string, skipCache bool, transactionID string) ([]string, error)
+}
+
+// reverseDestinationsMatcher is implemented by the DataDBs resolving all the prefixes of a number in one go
+type reverseDestinationsMatcher interface {
+	MatchReverseDestinations(dst string) ([]string, [][]string)
+}
+
+// matchReverseDestinations returns the prefixes of dst having destinations, longest first
+func matchReverseDestinations(rdg reverseDestinationGetter, dst string) (dms []*destinationMatch) {
+	if m, canCast := rdg.(reverseDestinationsMatcher); canCast {
+		prefixes, destIDs := m.MatchReverseDestinations(dst)
+		dms = make([]*destinationMatch, len(prefixes))
+		for i, p := range prefixes {
+			dms[i] = &destinationMatch{Prefix: p, DestIDs: destIDs[i]}
+		}
+		return
+	}
+	for _, p := range splitDestination(dst) {
+		if destIDs, err := rdg.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
+			dms = append(dms, &destinationMatch{Prefix: p, DestIDs: destIDs})
+		}
+	}
+	return
+}
@@ -0,0 +1,98 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sort"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// isDialPrefix returns true for the prefixes made of digits, optionally led by +
+func isDialPrefix(prfx string) bool {
+	for i, r := range prfx {
+		if (r < '0' || r > '9') && (r != '+' || i != 0) {
+			return false
+		}
+	}
+	return len(prfx) != 0
+}
+
+// compressDestinations collapses the redundant prefixes of dsts, returning the number of prefixes removed:
+// prefixes covered by a shorter one of the same destination are dropped and complete sets of 10 child digits are replaced by their parent.
+// Prefixes are only touched when no other destination has prefixes starting with the resulting one, so the longest match of numbers
+// longer than the collapsed prefixes stays the same
+func compressDestinations(dsts map[string]*Destination) (removed int) {
+	owners := make(map[string]utils.StringMap) // destinations having prefixes starting with the key
+	for dstID, dst := range dsts {
+		for _, prfx := range dst.Prefixes {
+			for i := 1; i <= len(prfx); i++ {
+				if _, has := owners[prfx[:i]]; !has {
+					owners[prfx[:i]] = make(utils.StringMap)
+				}
+				owners[prfx[:i]][dstID] = true
+			}
+		}
+	}
+	for _, dst := range dsts {
+		prfxs := make(utils.StringMap)
+		var others []string // IP prefixes or other formats, left untouched
+		for _, prfx := range dst.Prefixes {
+			if isDialPrefix(prfx) {
+				prfxs[prfx] = true
+			} else {
+				others = append(others, prfx)
+			}
+		}
+		for prfx := range prfxs { // covered by a shorter prefix
+			for i := 1; i < len(prfx); i++ {
+				if prfxs[prfx[:i]] && len(owners[prfx[:i]]) == 1 {
+					delete(prfxs, prfx)
+					break
+				}
+			}
+		}
+		for collapsed := true; collapsed; {
+			collapsed = false
+			children := make(map[string]int)
+			for prfx := range prfxs {
+				if parent := prfx[:len(prfx)-1]; isDialPrefix(parent) && parent != "+" {
+					children[parent]++
+				}
+			}
+			for parent, nrChildren := range children {
+				if nrChildren != 10 || len(owners[parent]) != 1 {
+					continue
+				}
+				for digit := '0'; digit <= '9'; digit++ {
+					delete(prfxs, parent+string(digit))
+				}
+				prfxs[parent] = true
+				collapsed = true
+			}
+		}
+		if len(prfxs)+len(others) == len(dst.Prefixes) {
+			continue
+		}
+		removed += len(dst.Prefixes) - len(prfxs) - len(others)
+		compressed := prfxs.Slice()
+		sort.Strings(compressed)
+		dst.Prefixes = append(compressed, others...)
+	}
+	return
+}
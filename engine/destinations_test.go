@@ -19,6 +19,7 @@ package engine
 
 import (
 	"encoding/json"
+	"reflect"
 
 	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/utils"
@@ -143,6 +144,32 @@ func TestCleanStalePrefixes(t *testing.T) {
 	}
 }*/
 
+func TestDestinationIPPrefixes(t *testing.T) {
+	lan := &Destination{Id: "TEST_LAN", Prefixes: []string{"10.10.0.0/16", "2001:db8::/32"}}
+	if err := dataStorage.SetDestination(lan, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataStorage.SetReverseDestination(lan, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if precision := lan.containsPrefix("10.10.3.4"); precision != 16 {
+		t.Errorf("Unexpected precision: %d", precision)
+	}
+	for dst, eMatched := range map[string]string{"10.10.3.4": "10.10.0.0/16", "2001:db8:1::1": "2001:db8::/32", "10.11.3.4": ""} {
+		var matched string
+		for _, p := range utils.SplitDestination(dst, MIN_PREFIX_MATCH) {
+			if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil &&
+				utils.IsSliceMember(destIDs, lan.Id) {
+				matched = utils.DestinationKeyPrefix(p)
+				break
+			}
+		}
+		if matched != eMatched {
+			t.Errorf("Destination %s, expecting match: <%s>, received: <%s>", dst, eMatched, matched)
+		}
+	}
+}
+
 /********************************* Benchmarks **********************************/
 
 func BenchmarkDestinationStorageStoreRestore(b *testing.B) {
@@ -152,3 +179,28 @@ func BenchmarkDestinationStorageStoreRestore(b *testing.B) {
 		dataStorage.GetDestination(nationale.Id, true, utils.NonTransactional)
 	}
 }
+
+func TestCompressDestinations(t *testing.T) {
+	dsts := map[string]*Destination{
+		"DST_DE_MOBILE": &Destination{Id: "DST_DE_MOBILE",
+			Prefixes: []string{"+4915", "+49150", "+491600", "+491601", "+491602", "+491603", "+491604",
+				"+491605", "+491606", "+491607", "+491608", "+491609", "+4917"}},
+		"DST_DE_PREMIUM": &Destination{Id: "DST_DE_PREMIUM",
+			Prefixes: []string{"+49170", "+49171", "+49172", "+49173", "+49174", "+49175", "+49176", "+49177", "+49178", "+49179"}},
+		"DST_LAN": &Destination{Id: "DST_LAN", Prefixes: []string{"10.0.0.0/8"}},
+	}
+	if removed := compressDestinations(dsts); removed != 10 {
+		t.Errorf("Unexpected prefixes removed: %d", removed)
+	}
+	eDst := &Destination{Id: "DST_DE_MOBILE", Prefixes: []string{"+4915", "+49160", "+4917"}}
+	if !reflect.DeepEqual(eDst, dsts["DST_DE_MOBILE"]) {
+		t.Errorf("Expecting: %+v, received: %+v", eDst, dsts["DST_DE_MOBILE"])
+	}
+	// +4917 owned by DST_DE_MOBILE too, so the children are kept
+	if len(dsts["DST_DE_PREMIUM"].Prefixes) != 10 {
+		t.Errorf("Unexpected destination: %+v", dsts["DST_DE_PREMIUM"])
+	}
+	if !reflect.DeepEqual([]string{"10.0.0.0/8"}, dsts["DST_LAN"].Prefixes) {
+		t.Errorf("Unexpected destination: %+v", dsts["DST_LAN"])
+	}
+}
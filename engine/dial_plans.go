@@ -0,0 +1,95 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// numberFormatting are the characters dropped out of the dialed numbers before applying the dial plan
+var numberFormatting = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// DialPlan normalizes the numbers dialed within a tenant to E.164 before destination matching
+type DialPlan struct {
+	Tenant string
+	Rules  []*DialPlanRule // sorted by weight, first matching one applies
+}
+
+type DialPlanRule struct {
+	Prefix    string
+	MinLength int
+	MaxLength int
+	Strip     int
+	Add       string
+	Weight    float64
+}
+
+func (dpr *DialPlanRule) matches(number string) bool {
+	return strings.HasPrefix(number, dpr.Prefix) &&
+		len(number) >= dpr.MinLength &&
+		(dpr.MaxLength == 0 || len(number) <= dpr.MaxLength)
+}
+
+// Normalize returns the number rewritten by the first matching rule, false if no rule matched
+func (dp *DialPlan) Normalize(number string) (string, bool) {
+	number = numberFormatting.Replace(number)
+	for _, dpr := range dp.Rules {
+		if !dpr.matches(number) {
+			continue
+		}
+		strip := dpr.Strip
+		if strip > len(number) {
+			strip = len(number)
+		}
+		return dpr.Add + number[strip:], true
+	}
+	return number, false
+}
+
+// normalizeDestination applies the dial plan of the tenant on the destination of both the alias attributes and the event
+func normalizeDestination(attr *AttrMatchingAlias, in interface{}) error {
+	if dataStorage == nil || attr.Destination == "" || attr.Destination == utils.ANY {
+		return nil
+	}
+	dp, err := dataStorage.GetDialPlan(attr.Tenant, false, utils.NonTransactional)
+	if err != nil {
+		if err == utils.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	normalized, matched := dp.Normalize(attr.Destination)
+	if !matched || normalized == attr.Destination {
+		return nil
+	}
+	v := reflect.ValueOf(in)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		if field := v.FieldByName(utils.DESTINATION); field.IsValid() && field.Kind() == reflect.String &&
+			field.CanSet() && field.String() == attr.Destination {
+			field.SetString(normalized)
+		}
+	}
+	attr.Destination = normalized
+	return nil
+}
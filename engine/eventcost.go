@@ -216,11 +216,11 @@ func (ec *EventCost) ResetCounters() {
 // ComputeCost iterates through Charges, computing EventCost.Cost
 func (ec *EventCost) GetCost() float64 {
 	if ec.Cost == nil {
-		var cost float64
+		var dCost utils.DecimalSum
 		for _, ci := range ec.Charges {
-			cost += ci.TotalCost()
+			dCost.AddMul(ci.TotalCost(), 1)
 		}
-		cost = utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+		cost := utils.Round(dCost.Float64(), globalRoundingDecimals, utils.ROUNDING_MIDDLE)
 		ec.Cost = &cost
 	}
 	return *ec.Cost
@@ -0,0 +1,52 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ExchangeRate converts the costs rated in FromCurrency towards the balances held in ToCurrency
+type ExchangeRate struct {
+	ID           string // FromCurrency:ToCurrency
+	FromCurrency string
+	ToCurrency   string
+	Rate         float64 // units of ToCurrency for one unit of FromCurrency
+}
+
+// getExchangeRate returns the rate converting from towards to, falling back on the inverse of the to -> from one
+func getExchangeRate(from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	er, err := dataStorage.GetExchangeRate(utils.ConcatenatedKey(from, to), false, utils.NonTransactional)
+	if err == nil {
+		return er.Rate, nil
+	} else if err != utils.ErrNotFound {
+		return 0, err
+	}
+	if er, err = dataStorage.GetExchangeRate(utils.ConcatenatedKey(to, from), false, utils.NonTransactional); err != nil {
+		if err == utils.ErrNotFound {
+			err = fmt.Errorf("no exchange rate for %s -> %s", from, to)
+		}
+		return 0, err
+	}
+	return 1 / er.Rate, nil
+}
@@ -17,7 +17,11 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 */
 package engine
 
-import "github.com/cgrates/cgrates/utils"
+import (
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
 
 // Handles retrieving of DerivedChargers profile based on longest match from DataDb
 func HandleGetDerivedChargers(dataDB DataDB, attrs *utils.AttrDerivedChargers) (*utils.DerivedChargers, error) {
@@ -43,15 +47,91 @@ func DerivedChargersMatchesDest(dcs *utils.DerivedChargers, dest string) bool {
 		return true
 	}
 	// check destination ids
-	for _, p := range utils.SplitPrefix(dest, MIN_PREFIX_MATCH) {
-		if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-			for _, dId := range destIDs {
-				includeDest, found := dcs.DestinationIDs[dId]
-				if found {
-					return includeDest
-				}
+	for _, dm := range matchReverseDestinations(dataStorage, dest) {
+		for _, dId := range dm.DestIDs {
+			includeDest, found := dcs.DestinationIDs[dId]
+			if found {
+				return includeDest
 			}
 		}
 	}
 	return false
 }
+
+// derivedChargersReferences returns the user profile attributes and alias targets referenced by the derived chargers fields
+func derivedChargersReferences(dcs []*utils.DerivedCharger) (usrAttrs, alsTargets []string) {
+	var rsrFlds []*utils.RSRField
+	for _, dc := range dcs {
+		if runFltrs, err := utils.ParseRSRFields(dc.RunFilters, utils.INFIELD_SEP); err == nil {
+			rsrFlds = append(rsrFlds, runFltrs...)
+		}
+		for _, fldStr := range []string{dc.RequestTypeField, dc.DirectionField, dc.TenantField, dc.CategoryField,
+			dc.AccountField, dc.SubjectField, dc.DestinationField, dc.SetupTimeField, dc.PDDField, dc.AnswerTimeField,
+			dc.UsageField, dc.SupplierField, dc.DisconnectCauseField, dc.RatedField, dc.CostField} {
+			if rsrFld, err := utils.NewRSRField(fldStr); err == nil && rsrFld != nil {
+				rsrFlds = append(rsrFlds, rsrFld)
+			}
+		}
+	}
+	for _, rsrFld := range rsrFlds {
+		if rsrFld.IsStatic() {
+			continue
+		}
+		if strings.HasPrefix(rsrFld.Id, utils.UserFieldPrefix) {
+			usrAttrs = append(usrAttrs, rsrFld.Id[len(utils.UserFieldPrefix):])
+		} else if strings.HasPrefix(rsrFld.Id, utils.AliasFieldPrefix) {
+			alsTargets = append(alsTargets, rsrFld.Id[len(utils.AliasFieldPrefix):])
+		}
+	}
+	return
+}
+
+// ResolveDerivedChargersReferences returns a copy of the CDR having the user profile attributes and alias outputs
+// referenced by the derived chargers (eg: *user.billing_group, *alias.Subject) populated as ExtraFields
+func ResolveDerivedChargersReferences(cdr *CDR, dcs []*utils.DerivedCharger, aliasAttr *AttrMatchingAlias) (*CDR, error) {
+	usrAttrs, alsTargets := derivedChargersReferences(dcs)
+	if len(usrAttrs) == 0 && len(alsTargets) == 0 {
+		return cdr, nil
+	}
+	resolved := cdr.Clone()
+	if resolved.ExtraFields == nil {
+		resolved.ExtraFields = make(map[string]string)
+	}
+	if len(usrAttrs) != 0 && userService != nil {
+		up := &UserProfile{Tenant: cdr.Tenant, Profile: map[string]string{utils.ACCOUNT: cdr.Account}}
+		var ups UserProfiles
+		if err := userService.Call("UsersV1.GetUsers", up, &ups); err != nil {
+			return nil, err
+		}
+		if len(ups) != 0 {
+			for _, attr := range usrAttrs {
+				if val, has := ups[0].Profile[attr]; has {
+					resolved.ExtraFields[utils.UserFieldPrefix+attr] = val
+				}
+			}
+		}
+	}
+	if len(alsTargets) != 0 && aliasService != nil && aliasAttr != nil {
+		pairs, err := matchingAliasPairs(aliasAttr)
+		if err != nil && err != utils.ErrNotFound {
+			return nil, err
+		}
+		for _, target := range alsTargets {
+			origAlias, has := pairs[target]
+			if !has {
+				continue
+			}
+			crntVal := cdr.FieldAsString(&utils.RSRField{Id: target})
+			for original, alias := range origAlias {
+				if original == crntVal || alias == crntVal { // exact match or alias already applied on the field
+					resolved.ExtraFields[utils.AliasFieldPrefix+target] = alias
+					break
+				}
+				if original == "" || original == utils.ANY {
+					resolved.ExtraFields[utils.AliasFieldPrefix+target] = alias
+				}
+			}
+		}
+	}
+	return resolved, nil
+}
@@ -18,6 +18,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/cgrates/cgrates/utils"
@@ -136,3 +137,50 @@ func TestHandleDeivedChargersMatchDestNegativeSpec(t *testing.T) {
 		t.Error("Derived charger failed to match dest")
 	}
 }
+
+type testAliasGetter Alias
+
+func (tag *testAliasGetter) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	*reply.(*Alias) = Alias(*tag)
+	return nil
+}
+
+func TestResolveDerivedChargersReferences(t *testing.T) {
+	savedUsers, savedAliases := userService, aliasService
+	defer func() { userService, aliasService = savedUsers, savedAliases }()
+	userService = &UserMap{
+		table: map[string]map[string]string{
+			"cgrates.org:dan": map[string]string{utils.ACCOUNT: "dan", "billing_group": "gold"},
+		},
+		index: make(map[string]map[string]bool),
+	}
+	aliasService = &testAliasGetter{Values: AliasValues{&AliasValue{DestinationId: utils.ANY, Weight: 10,
+		Pairs: AliasPairs{"BillingPlan": map[string]string{utils.ANY: "premium"}, utils.SUBJECT: map[string]string{"1001": "rif"}}}}}
+	cdr := &CDR{Tenant: "cgrates.org", Account: "dan", Subject: "rif", Destination: "1002",
+		ExtraFields: map[string]string{"field_extr1": "val_extr1"}}
+	dcs := []*utils.DerivedCharger{
+		&utils.DerivedCharger{RunID: "noref", SubjectField: utils.META_DEFAULT},
+	}
+	if resolved, err := ResolveDerivedChargersReferences(cdr, dcs, &AttrMatchingAlias{}); err != nil {
+		t.Error(err)
+	} else if resolved != cdr {
+		t.Error("Should not clone without references")
+	}
+	dcs = append(dcs, &utils.DerivedCharger{RunID: "group", RunFilters: "~*alias.BillingPlan:s/^premium$//",
+		SubjectField: utils.UserFieldPrefix + "billing_group", CategoryField: utils.AliasFieldPrefix + utils.SUBJECT})
+	resolved, err := ResolveDerivedChargersReferences(cdr, dcs, &AttrMatchingAlias{Destination: utils.ANY})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eExtraFields := map[string]string{"field_extr1": "val_extr1", "*user.billing_group": "gold",
+		"*alias.BillingPlan": "premium", "*alias.Subject": "rif"}
+	if !reflect.DeepEqual(eExtraFields, resolved.ExtraFields) {
+		t.Errorf("Expecting: %+v, received: %+v", eExtraFields, resolved.ExtraFields)
+	}
+	if len(cdr.ExtraFields) != 1 {
+		t.Errorf("Original CDR modified: %+v", cdr.ExtraFields)
+	}
+	if subj := resolved.GetSubject(dcs[1].SubjectField); subj != "gold" {
+		t.Errorf("Unexpected subject: %s", subj)
+	}
+}
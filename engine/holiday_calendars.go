@@ -0,0 +1,62 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	holidayDateLayout      = "2006-01-02"
+	holidayRecurringLayout = "01-02"
+)
+
+// HolidayCalendar lists the dates rated as another week day (eg: public holidays charged as Sunday)
+type HolidayCalendar struct {
+	ID       string
+	Holidays map[string]time.Weekday // YYYY-MM-DD or MM-DD for the yearly recurring ones
+}
+
+// WeekDayOf returns the week day class of t if it falls on a holiday, the exact date taking precedence over the recurring one
+func (hc *HolidayCalendar) WeekDayOf(t time.Time) (time.Weekday, bool) {
+	if wd, has := hc.Holidays[t.Format(holidayDateLayout)]; has {
+		return wd, true
+	}
+	wd, has := hc.Holidays[t.Format(holidayRecurringLayout)]
+	return wd, has
+}
+
+// holidayWeekDay returns the week day class of t out of the first calendar listing it as holiday
+func holidayWeekDay(calendarIDs []string, t time.Time) (time.Weekday, bool) {
+	for _, calID := range calendarIDs {
+		hc, err := dataStorage.GetHolidayCalendar(calID, false, utils.NonTransactional)
+		if err != nil {
+			if err != utils.ErrNotFound {
+				utils.Logger.Warning(fmt.Sprintf("<Rating> could not get holiday calendar %s: %v", calID, err))
+			}
+			continue
+		}
+		if wd, has := hc.WeekDayOf(t); has {
+			return wd, true
+		}
+	}
+	return t.Weekday(), false
+}
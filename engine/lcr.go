@@ -277,7 +277,7 @@ func (es LCREntriesSorter) Sort() {
 
 func (lcra *LCRActivation) GetLCREntryForPrefix(destination string) *LCREntry {
 	var potentials LCREntriesSorter
-	for _, p := range utils.SplitPrefix(destination, MIN_PREFIX_MATCH) {
+	for _, p := range splitDestination(destination) {
 		if destIDs, err := dataStorage.GetReverseDestination(p, true, utils.NonTransactional); err == nil {
 			for _, dId := range destIDs {
 				for _, entry := range lcra.Entries {
@@ -0,0 +1,127 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+var lcrBlacklist = NewSupplierBlacklist(0, 0)
+
+// SetLCRBlacklist configures the automatic supplier blacklisting, maxFailures 0 disables it
+func SetLCRBlacklist(maxFailures int, cooldown time.Duration) {
+	lcrBlacklist = NewSupplierBlacklist(maxFailures, cooldown)
+}
+
+// LCRBlacklist returns the supplier blacklist used by LCR queries
+func LCRBlacklist() *SupplierBlacklist {
+	return lcrBlacklist
+}
+
+func NewSupplierBlacklist(maxFailures int, cooldown time.Duration) *SupplierBlacklist {
+	return &SupplierBlacklist{maxFailures: maxFailures, cooldown: cooldown,
+		failures: make(map[string]int), blacklisted: make(map[string]time.Time)}
+}
+
+// SupplierBlacklist excludes suppliers from LCR for a cooldown window after consecutive failures
+type SupplierBlacklist struct {
+	sync.RWMutex
+	maxFailures int
+	cooldown    time.Duration
+	failures    map[string]int       // consecutive failures, indexed on tenant:supplier
+	blacklisted map[string]time.Time // blacklisted until
+}
+
+// ReportAttempt records the outcome of an attempt towards supplier, blacklisting it when the failures limit is reached
+func (sb *SupplierBlacklist) ReportAttempt(tenant, supplier string, success bool) {
+	if sb.maxFailures <= 0 {
+		return
+	}
+	key := utils.ConcatenatedKey(tenant, supplier)
+	sb.Lock()
+	defer sb.Unlock()
+	if success {
+		delete(sb.failures, key)
+		return
+	}
+	sb.failures[key] += 1
+	if sb.failures[key] < sb.maxFailures {
+		return
+	}
+	delete(sb.failures, key)
+	until := time.Now().Add(sb.cooldown)
+	sb.blacklisted[key] = until
+	utils.Logger.Warning(fmt.Sprintf("<LCR> Blacklisting supplier <%s> until %s after %d consecutive failures",
+		key, until.Format(time.RFC3339), sb.maxFailures))
+}
+
+// IsBlacklisted checks if the supplier should be excluded from LCR results
+func (sb *SupplierBlacklist) IsBlacklisted(tenant, supplier string) bool {
+	if sb.maxFailures <= 0 {
+		return false
+	}
+	key := utils.ConcatenatedKey(tenant, supplier)
+	sb.RLock()
+	until, has := sb.blacklisted[key]
+	sb.RUnlock()
+	if !has {
+		return false
+	}
+	if time.Now().Before(until) {
+		return true
+	}
+	sb.Lock()
+	if until, has = sb.blacklisted[key]; has && !time.Now().Before(until) {
+		delete(sb.blacklisted, key)
+		utils.Logger.Info(fmt.Sprintf("<LCR> Supplier <%s> blacklisting expired", key))
+	}
+	sb.Unlock()
+	return false
+}
+
+// Clear removes the supplier from blacklist before its cooldown expires
+func (sb *SupplierBlacklist) Clear(tenant, supplier string) error {
+	key := utils.ConcatenatedKey(tenant, supplier)
+	sb.Lock()
+	defer sb.Unlock()
+	delete(sb.failures, key)
+	if _, has := sb.blacklisted[key]; !has {
+		return utils.ErrNotFound
+	}
+	delete(sb.blacklisted, key)
+	utils.Logger.Info(fmt.Sprintf("<LCR> Supplier <%s> blacklisting cleared", key))
+	return nil
+}
+
+// Blacklisted returns the active blacklist entries with their expiry times
+func (sb *SupplierBlacklist) Blacklisted() map[string]time.Time {
+	sb.RLock()
+	defer sb.RUnlock()
+	now := time.Now()
+	bl := make(map[string]time.Time, len(sb.blacklisted))
+	for key, until := range sb.blacklisted {
+		if now.Before(until) {
+			bl[key] = until
+		}
+	}
+	return bl
+}
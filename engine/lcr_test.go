@@ -789,3 +789,53 @@ func TestLCRCostSuppliersLoadAllOverMisingParams(t *testing.T) {
 		t.Error("Error soring on load distribution: ", utils.ToIJSON(lcrCost))
 	}
 }
+
+func TestLcrGetBlacklistedSupplier(t *testing.T) {
+	cd := &CallDescriptor{
+		TimeStart:   time.Date(2015, 04, 06, 17, 40, 0, 0, time.UTC),
+		TimeEnd:     time.Date(2015, 04, 06, 17, 41, 0, 0, time.UTC),
+		Tenant:      "cgrates.org",
+		Direction:   "*in",
+		Category:    "call",
+		Destination: "0723098765",
+		Account:     "rif",
+		Subject:     "rif",
+	}
+	lcr, err := cd.GetLCR(nil, nil, nil)
+	if err != nil || len(lcr.SupplierCosts) == 0 {
+		t.Fatalf("Bad lcr: %+v, %v", lcr, err)
+	}
+	dtcs, _ := utils.NewDTCSFromRPKey(lcr.SupplierCosts[0].Supplier)
+	SetLCRBlacklist(2, time.Hour)
+	defer SetLCRBlacklist(0, 0)
+	lcrBlacklist.ReportAttempt(cd.Tenant, dtcs.Subject, false)
+	lcrBlacklist.ReportAttempt(cd.Tenant, dtcs.Subject, true) // success resets the consecutive failures
+	lcrBlacklist.ReportAttempt(cd.Tenant, dtcs.Subject, false)
+	if lcrBlacklist.IsBlacklisted(cd.Tenant, dtcs.Subject) {
+		t.Error("Supplier blacklisted on non consecutive failures")
+	}
+	lcrBlacklist.ReportAttempt(cd.Tenant, dtcs.Subject, false)
+	if bl := lcrBlacklist.Blacklisted(); len(bl) != 1 {
+		t.Errorf("Unexpected blacklist: %+v", bl)
+	}
+	if lcrBl, err := cd.GetLCR(nil, nil, nil); err != nil {
+		t.Error(err)
+	} else {
+		for _, sc := range lcrBl.SupplierCosts {
+			if sc.Supplier == lcr.SupplierCosts[0].Supplier {
+				t.Error("Blacklisted supplier in LCR: ", sc.Supplier)
+			}
+		}
+	}
+	if err := lcrBlacklist.Clear(cd.Tenant, dtcs.Subject); err != nil {
+		t.Error(err)
+	} else if err := lcrBlacklist.Clear(cd.Tenant, dtcs.Subject); err != utils.ErrNotFound {
+		t.Error("Expecting not found, got: ", err)
+	}
+	lcrBlacklist.cooldown = 0
+	lcrBlacklist.ReportAttempt(cd.Tenant, dtcs.Subject, false)
+	lcrBlacklist.ReportAttempt(cd.Tenant, dtcs.Subject, false)
+	if lcrBlacklist.IsBlacklisted(cd.Tenant, dtcs.Subject) {
+		t.Error("Blacklisting not expired")
+	}
+}
@@ -94,19 +94,20 @@ func (cIl *ChargingInterval) EndTime(cIlST time.Time) (et time.Time) {
 // Cost computes the total cost on this ChargingInterval
 func (cIl *ChargingInterval) Cost() float64 {
 	if cIl.cost == nil {
-		var cost float64
+		var dCost utils.DecimalSum
 		for _, incr := range cIl.Increments {
-			cost += incr.Cost * float64(incr.CompressFactor)
+			dCost.AddMul(incr.Cost, int64(incr.CompressFactor))
 		}
-		cost = utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+		cost := utils.Round(dCost.Float64(), globalRoundingDecimals, utils.ROUNDING_MIDDLE)
 		cIl.cost = &cost
 	}
 	return *cIl.cost
 }
 
 func (cIl *ChargingInterval) TotalCost() float64 {
-	return utils.Round((cIl.Cost() * float64(cIl.CompressFactor)),
-		globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	var cost utils.DecimalSum
+	cost.AddMul(cIl.Cost(), int64(cIl.CompressFactor))
+	return utils.Round(cost.Float64(), globalRoundingDecimals, utils.ROUNDING_MIDDLE)
 }
 
 // Clone returns a new instance of ChargingInterval with independent data
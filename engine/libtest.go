@@ -127,6 +127,9 @@ func LoadTariffPlanFromFolder(tpPath, timezone string, dataDB DataDB, disable_re
 		path.Join(tpPath, utils.USERS_CSV),
 		path.Join(tpPath, utils.ALIASES_CSV),
 		path.Join(tpPath, utils.ResourceLimitsCsv),
+		path.Join(tpPath, utils.ExchangeRatesCsv),
+		path.Join(tpPath, utils.HolidayCalendarsCsv),
+		path.Join(tpPath, utils.DialPlansCsv),
 	), "", timezone)
 	if err := loader.LoadAll(); err != nil {
 		return utils.NewErrServerError(err)
@@ -18,8 +18,13 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"bytes"
+	"errors"
 	"log"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -279,7 +284,7 @@ var csvr *TpReader
 
 func init() {
 	csvr = NewTpReader(dataStorage, NewStringCSVStorage(',', destinations, timings, rates, destinationRates, ratingPlans, ratingProfiles,
-		sharedGroups, lcrs, actions, actionPlans, actionTriggers, accountActions, derivedCharges, cdrStats, users, aliases, resLimits), testTPID, "")
+		sharedGroups, lcrs, actions, actionPlans, actionTriggers, accountActions, derivedCharges, cdrStats, users, aliases, resLimits, "", "", ""), testTPID, "")
 	if err := csvr.LoadDestinations(); err != nil {
 		log.Print("error in LoadDestinations:", err)
 	}
@@ -694,7 +699,7 @@ func TestLoadRatingPlans(t *testing.T) {
 				MonthDays: utils.MonthDays{},
 				WeekDays:  utils.WeekDays{1, 2, 3, 4, 5},
 				StartTime: "00:00:00",
-				tag:       "WORKDAYS_00",
+				ID:        "WORKDAYS_00",
 			},
 			"2d9ca6c4": &RITiming{
 				Years:     utils.Years{},
@@ -702,7 +707,7 @@ func TestLoadRatingPlans(t *testing.T) {
 				MonthDays: utils.MonthDays{},
 				WeekDays:  utils.WeekDays{1, 2, 3, 4, 5},
 				StartTime: "18:00:00",
-				tag:       "WORKDAYS_18",
+				ID:        "WORKDAYS_18",
 			},
 			"ec8ed374": &RITiming{
 				Years:     utils.Years{},
@@ -710,7 +715,7 @@ func TestLoadRatingPlans(t *testing.T) {
 				MonthDays: utils.MonthDays{},
 				WeekDays:  utils.WeekDays{time.Saturday, time.Sunday},
 				StartTime: "00:00:00",
-				tag:       "WEEKENDS",
+				ID:        "WEEKENDS",
 			},
 			"83429156": &RITiming{
 				Years:     utils.Years{},
@@ -718,7 +723,7 @@ func TestLoadRatingPlans(t *testing.T) {
 				MonthDays: utils.MonthDays{},
 				WeekDays:  utils.WeekDays{},
 				StartTime: "00:00:00",
-				tag:       "*any",
+				ID:        "*any",
 			},
 		},
 		Ratings: map[string]*RIRate{
@@ -844,7 +849,7 @@ func TestLoadRatingPlans(t *testing.T) {
 		StartTime:  "00:00:00",
 		EndTime:    "",
 		cronString: "",
-		tag:        utils.ANY,
+		ID:         utils.ANY,
 	}
 
 	if !reflect.DeepEqual(csvr.ratingPlans["ANY_PLAN"].Timings["1323e132"], anyTiming) {
@@ -1413,3 +1418,581 @@ func TestLoadResourceLimits(t *testing.T) {
 	}
 
 }
+
+func TestLoadTenantTimezones(t *testing.T) {
+	tpr := NewTpReader(nil, csvr.lr, testTPID, "UTC")
+	tpr.SetTenantTimezones(map[string]string{"vdf": "Europe/Berlin"})
+	if tz := tpr.tenantTimezone("vdf"); tz != "Europe/Berlin" {
+		t.Error("Unexpected timezone: ", tz)
+	}
+	if tz := tpr.tenantTimezone("cgrates.org"); tz != "UTC" {
+		t.Error("Unexpected timezone: ", tz)
+	}
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	if at, err := tpr.parseActivationTime("2016-07-01 00:00:00", "vdf"); err != nil {
+		t.Error(err)
+	} else if eAt := time.Date(2016, 7, 1, 0, 0, 0, 0, loc); !at.Equal(eAt) {
+		t.Errorf("Expecting: %v, received: %v", eAt, at)
+	}
+	if at, err := tpr.parseActivationTime("2012-01-01T00:00:00Z", "vdf"); err != nil {
+		t.Error(err)
+	} else if eAt := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC); !at.Equal(eAt) {
+		t.Errorf("Expecting: %v, received: %v", eAt, at)
+	}
+	eAtrsTZ := map[string]string{"STANDARD_TRIGGER": "Europe/Berlin", "STANDARD_TRIGGERS": "UTC"}
+	if atrsTZ, err := tpr.actionTriggersTimezones(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eAtrsTZ, atrsTZ) {
+		t.Errorf("Expecting: %v, received: %v", eAtrsTZ, atrsTZ)
+	}
+}
+
+func TestLoadCategories(t *testing.T) {
+	tpr := NewTpReader(dataStorage, csvr.lr, testTPID, "")
+	if err := tpr.LoadCategories([]string{utils.MetaDestinations, utils.MetaRatingPlans}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpr.ratingPlans) != len(csvr.ratingPlans) || len(tpr.destinationRates) != len(csvr.destinationRates) {
+		t.Errorf("Unexpected rating plans: %d, destination rates: %d", len(tpr.ratingPlans), len(tpr.destinationRates))
+	}
+	if len(tpr.ratingProfiles) != 0 || len(tpr.accountActions) != 0 || len(tpr.aliases) != 0 {
+		t.Error("Loaded categories not included")
+	}
+	tpr.Init()
+	if err := tpr.LoadCategories(nil, []string{utils.MetaAccountActions, utils.MetaAliases}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpr.ratingProfiles) != len(csvr.ratingProfiles) || len(tpr.accountActions) != 0 || len(tpr.aliases) != 0 {
+		t.Errorf("Unexpected rating profiles: %d, account actions: %d, aliases: %d",
+			len(tpr.ratingProfiles), len(tpr.accountActions), len(tpr.aliases))
+	}
+	if err := tpr.LoadCategories([]string{"*unknown"}, nil); err == nil {
+		t.Error("Expecting error on unsupported category")
+	}
+	tpr.Init()
+	if err := tpr.LoadCategories([]string{utils.MetaAccountActions}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpr.accountActions) != len(csvr.accountActions) || len(tpr.actionPlans) != len(csvr.actionPlans) ||
+		len(tpr.actions) != len(csvr.actions) || len(tpr.actionsTriggers) != len(csvr.actionsTriggers) {
+		t.Errorf("Unexpected account actions: %d, action plans: %d, actions: %d, action triggers: %d",
+			len(tpr.accountActions), len(tpr.actionPlans), len(tpr.actions), len(tpr.actionsTriggers))
+	}
+	tpr.Init()
+	if err := tpr.LoadCategories([]string{utils.MetaAccountActions}, []string{utils.MetaActions}); err == nil ||
+		err.Error() != "cannot exclude load category *actions, required by *action_plans" {
+		t.Errorf("Expecting error on excluded dependency, received: %v", err)
+	}
+}
+
+func TestTpReaderCheckIntegrity(t *testing.T) {
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP,
+		"DST_INTEGRITY,4910\n",                             // destinations
+		"TM_INTEGRITY,*any,*any,*any,*any,00:00:00\n",      // timings
+		"RT_INTEGRITY,0,0.1,60s,1s,0s\n",                   // rates
+		"DR_INTEGRITY,DST_INTEGRITY,RT_MISSING,*up,4,0,\n", // destination rates
+		"RP_INTEGRITY,DR_INTEGRITY,TM_MISSING,10\n",        // rating plans
+		"", "", "", "",
+		"AP_INTEGRITY,ACT_MISSING,TM_INTEGRITY,10\n", // action plans
+		"",
+		"cgrates.org,integrity,AP_MISSING,ATR_MISSING,false,false\n", // account actions
+		"", "", "", "", "", "", "", "")
+	tpr := NewTpReader(dataStorage, csvStorage, testTPID, "")
+	err := tpr.LoadCategories([]string{utils.MetaRatingPlans, utils.MetaActions,
+		utils.MetaActionPlans, utils.MetaActionTriggers, utils.MetaAccountActions}, nil)
+	if err == nil {
+		t.Fatal("Expecting dangling references error")
+	}
+	for _, ref := range []string{"DestinationRate <DR_INTEGRITY> references missing Rate <RT_MISSING>",
+		"RatingPlan <RP_INTEGRITY> references missing Timing <TM_MISSING>",
+		"ActionPlan <AP_INTEGRITY> references missing Actions <ACT_MISSING>",
+		"AccountActions <cgrates.org:integrity> references missing ActionPlan <AP_MISSING>",
+		"AccountActions <cgrates.org:integrity> references missing ActionTriggers <ATR_MISSING>"} {
+		if !strings.Contains(err.Error(), ref) {
+			t.Errorf("Missing <%s> in error: %s", ref, err.Error())
+		}
+	}
+	if err := tpr.WriteToDatabase(false, false, false); err == nil {
+		t.Error("Expecting WriteToDatabase to refuse dangling references")
+	}
+}
+
+func TestTpReaderReloadRatingProfile(t *testing.T) {
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP,
+		"DST_RELOAD,4930\n",                         // destinations
+		"TM_RELOAD,*any,*any,*any,*any,00:00:00\n",  // timings
+		"RT_RELOAD,0,0.2,60s,1s,0s\n",               // rates
+		"DR_RELOAD,DST_RELOAD,RT_RELOAD,*up,4,0,\n", // destination rates
+		"RP_RELOAD,DR_RELOAD,TM_RELOAD,10\n"+ // rating plans
+			"RP_BROKEN,DR_MISSING,TM_RELOAD,10\n",
+		"*out,cgrates.org,call,reload:1,2012-01-01T00:00:00Z,RP_RELOAD,,\n"+ // rating profiles
+			"*out,cgrates.org,call,broken,2012-01-01T00:00:00Z,RP_BROKEN,,\n",
+		"", "", "", "", "", "", "", "", "", "", "", "", "", "")
+	tpr := NewTpReader(dataStorage, csvStorage, testTPID, "")
+	if err := tpr.ReloadRatingProfile("*out:cgrates.org:call:reload:1"); err != nil {
+		t.Fatal(err)
+	}
+	if rpf, err := dataStorage.GetRatingProfile("*out:cgrates.org:call:reload:1", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if len(rpf.RatingPlanActivations) != 1 || rpf.RatingPlanActivations[0].RatingPlanId != "RP_RELOAD" {
+		t.Errorf("Unexpected rating profile: %+v", rpf)
+	}
+	if _, err := dataStorage.GetRatingPlan("RP_RELOAD", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	if _, err := dataStorage.GetDestination("DST_RELOAD", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	if err := tpr.ReloadRatingProfile("*out:cgrates.org:call:broken"); err == nil {
+		t.Error("Expecting error on missing DestinationRates")
+	}
+	if _, err := dataStorage.GetRatingProfile("*out:cgrates.org:call:broken", true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+	if err := tpr.ReloadRatingProfile("*out:cgrates.org:call:missing"); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+}
+
+func TestTpReaderHolidayCalendars(t *testing.T) {
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP, "",
+		"TM_WEEKEND,*any,*any,*any,6;7,00:00:00;;;HOL_LOAD\n", // timings
+		"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "",
+		"HOL_LOAD,12-25,\nHOL_LOAD,2017-04-17,6\n", "") // holiday calendars
+	dataDB, _ := NewMapStorage()
+	tpr := NewTpReader(dataDB, csvStorage, testTPID, "")
+	if err := tpr.LoadCategories([]string{utils.MetaTimings, utils.MetaHolidayCalendars}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if tm := tpr.timings["TM_WEEKEND"]; tm == nil || !reflect.DeepEqual([]string{"HOL_LOAD"}, tm.HolidayCalendars) {
+		t.Errorf("Unexpected timing: %+v", tm)
+	}
+	if err := tpr.WriteToDatabase(false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	eHC := &HolidayCalendar{ID: "HOL_LOAD", Holidays: map[string]time.Weekday{"12-25": time.Sunday, "2017-04-17": time.Saturday}}
+	if rcv, err := dataDB.GetHolidayCalendar("HOL_LOAD", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eHC, rcv) {
+		t.Errorf("Expecting: %+v, received: %+v", eHC, rcv)
+	}
+	if _, err := APItoHolidayCalendar(&utils.TPHolidayCalendar{ID: "HOL_BAD",
+		Holidays: []*utils.TPHoliday{&utils.TPHoliday{Date: "02-30"}}}); err == nil {
+		t.Error("Expecting error for invalid holiday date")
+	}
+}
+
+func TestTpReaderDialPlans(t *testing.T) {
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP,
+		"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "",
+		"cgrates.org,0,,,1,49,20\ncgrates.org,00,,,2,,30\ncgrates.org,,,8,0,4930,10\n") // dial plans
+	dataDB, _ := NewMapStorage()
+	tpr := NewTpReader(dataDB, csvStorage, testTPID, "")
+	if err := tpr.LoadCategories([]string{utils.MetaDialPlans}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpr.WriteToDatabase(false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	eDP := &DialPlan{Tenant: "cgrates.org", Rules: []*DialPlanRule{
+		&DialPlanRule{Prefix: "00", Strip: 2, Weight: 30},
+		&DialPlanRule{Prefix: "0", Strip: 1, Add: "49", Weight: 20},
+		&DialPlanRule{MaxLength: 8, Add: "4930", Weight: 10},
+	}}
+	if rcv, err := dataDB.GetDialPlan("cgrates.org", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eDP, rcv) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eDP), utils.ToJSON(rcv))
+	}
+	if _, err := APItoDialPlan(&utils.TPDialPlan{Tenant: "bad.org",
+		Rules: []*utils.TPDialPlanRule{&utils.TPDialPlanRule{MinLength: 10, MaxLength: 8}}}); err == nil {
+		t.Error("Expecting error for invalid length range")
+	}
+}
+
+func TestTpReaderHooks(t *testing.T) {
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP, "DST_HOOK,4940\nDST_HOOK2,4941\n",
+		"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "")
+	dataDB, _ := NewMapStorage()
+	tpr := NewTpReader(dataDB, csvStorage, testTPID, "")
+	if err := tpr.RegisterHook("*before_load", utils.MetaDestinations, nil); err == nil {
+		t.Error("Expecting error on unsupported stage")
+	}
+	if err := tpr.RegisterHook(TPHookAfterLoad, "*unknown", nil); err == nil {
+		t.Error("Expecting error on unsupported category")
+	}
+	var stages []string
+	if err := RegisterTpReaderHook(TPHookAfterLoad, utils.MetaDestinations, func(tpr *TpReader, categ string) error {
+		stages = append(stages, "global")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		globalTpHooksMux.Lock()
+		delete(globalTpHooks, tpHookKey{TPHookAfterLoad, utils.MetaDestinations})
+		globalTpHooksMux.Unlock()
+	}()
+	tpr.RegisterHook(TPHookAfterLoad, utils.MetaDestinations, func(tpr *TpReader, categ string) error {
+		stages = append(stages, categ)
+		for dstID := range tpr.LoadedDestinations() { // alias generated out of each destination
+			tpr.AddAlias(&Alias{Direction: utils.OUT, Tenant: "cgrates.org", Category: "call", Account: dstID,
+				Subject: utils.ANY, Context: utils.ALIAS_CONTEXT_RATING,
+				Values: AliasValues{&AliasValue{DestinationId: dstID, Pairs: AliasPairs{"Subject": map[string]string{dstID: "hook"}}, Weight: 10}}})
+		}
+		return nil
+	})
+	tpr.RegisterHook(TPHookAfterWrite, utils.MetaAccountActions, func(tpr *TpReader, categ string) error {
+		stages = append(stages, TPHookAfterWrite)
+		_, err := tpr.DataDB().GetAlias(utils.ConcatenatedKey(utils.OUT, "cgrates.org", "call", "DST_HOOK", utils.ANY, utils.ALIAS_CONTEXT_RATING),
+			true, utils.NonTransactional)
+		return err
+	})
+	if err := tpr.LoadCategories([]string{utils.MetaDestinations}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpr.WriteToDatabase(false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if eStages := []string{"global", utils.MetaDestinations, TPHookAfterWrite}; !reflect.DeepEqual(eStages, stages) {
+		t.Errorf("Expecting: %v, received: %v", eStages, stages)
+	}
+	tpr.RegisterHook(TPHookAfterLoad, utils.MetaDestinations, func(tpr *TpReader, categ string) error {
+		return errors.New("invalid prefix")
+	})
+	if err := tpr.LoadCategories([]string{utils.MetaDestinations}, nil); err == nil ||
+		err.Error() != "*after_load hook for *destinations: invalid prefix" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestTpReaderDisabledReverses(t *testing.T) {
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP, "DST_NOREV,4940\n",
+		"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "")
+	dataDB, _ := NewMapStorage()
+	tpr := NewTpReader(dataDB, csvStorage, testTPID, "")
+	if err := tpr.SetDisabledReverses([]string{utils.MetaRatingPlans}); err == nil {
+		t.Error("Expecting error on unsupported reverse category")
+	}
+	if err := tpr.SetDisabledReverses([]string{utils.MetaDestinations, utils.MetaAliases}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpr.LoadDestinations(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpr.WriteToDatabase(false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataDB.GetReverseDestination("4940", true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting reverse destinations not rebuilt, received: %v", err)
+	}
+	tpr.SetDisabledReverses(nil)
+	if err := tpr.WriteToDatabase(false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if rcv, err := dataDB.GetReverseDestination("4940", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual([]string{"DST_NOREV"}, rcv) {
+		t.Errorf("Unexpected reverse destinations: %v", rcv)
+	}
+}
+
+type testCacheReloader struct {
+	method string
+	args   utils.AttrReloadCache
+}
+
+func (tcr *testCacheReloader) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	tcr.method = serviceMethod
+	tcr.args = args.(utils.AttrReloadCache)
+	*reply.(*string) = utils.OK
+	return nil
+}
+
+func TestTpReaderReloadCache(t *testing.T) {
+	tpr := NewTpReader(dataStorage, csvr.lr, testTPID, "")
+	if err := tpr.LoadCategories([]string{utils.MetaDestinations, utils.MetaRatingPlans}, nil); err != nil {
+		t.Fatal(err)
+	}
+	rater := new(testCacheReloader)
+	tpr.SetCacheReload(true, rater)
+	if err := tpr.ReloadCache(false); err != nil {
+		t.Fatal(err)
+	}
+	if rater.method != "ApierV1.ReloadCache" || rater.args.FlushAll {
+		t.Errorf("Unexpected call: %s, args: %s", rater.method, utils.ToJSON(rater.args))
+	}
+	if len(*rater.args.RatingPlanIDs) != len(tpr.ratingPlans) ||
+		len(*rater.args.DestinationIDs) != len(tpr.destinations) {
+		t.Errorf("Unexpected reload args: %s", utils.ToJSON(rater.args))
+	}
+	if rater.args.ActionIDs == nil || len(*rater.args.ActionIDs) != 0 { // nil would reload all actions
+		t.Errorf("Unexpected ActionIDs: %+v", rater.args.ActionIDs)
+	}
+	tpr.SetCacheReload(true, nil)
+	cacheRecorder := &testCacheGenerationsDataDB{DataDB: dataStorage}
+	tpr.dataStorage = cacheRecorder
+	if err := tpr.ReloadCache(false); err != nil {
+		t.Error(err)
+	}
+	if len(cacheRecorder.generations) != 1 || cacheRecorder.prefixCalls != 0 {
+		t.Fatalf("Expecting one generation, received: %d, prefix reloads: %d", len(cacheRecorder.generations), cacheRecorder.prefixCalls)
+	}
+	if rpIDs := cacheRecorder.generations[0][utils.RATING_PLAN_PREFIX]; len(rpIDs) != len(tpr.ratingPlans) {
+		t.Errorf("Unexpected rating plans reloaded: %v", rpIDs)
+	}
+}
+
+// testCacheGenerationsDataDB records the cache reloads
+type testCacheGenerationsDataDB struct {
+	DataDB
+	generations []map[string][]string
+	prefixCalls int
+}
+
+func (db *testCacheGenerationsDataDB) CacheDataFromDB(prfx string, ids []string, mustBeCached bool) error {
+	db.prefixCalls++
+	return db.DataDB.CacheDataFromDB(prfx, ids, mustBeCached)
+}
+
+func (db *testCacheGenerationsDataDB) CacheDataGenerationFromDB(prefixIDs map[string][]string, mustBeCached bool) error {
+	db.generations = append(db.generations, prefixIDs)
+	return db.DataDB.CacheDataGenerationFromDB(prefixIDs, mustBeCached)
+}
+
+func TestCSVLoadErrorPosition(t *testing.T) {
+	for rates, ePos := range map[string][2]int{
+		"#Tag,ConnectFee,Rate,RateUnit,RateIncrement,GroupIntervalStart\nR1,0,0.1,60s,1s,0s\n\nR2,0,0.1x,60s,1s,0s\n": [2]int{4, 3},
+		"R1,0,0.1,60s,1s,0s\nR2,0,0.1,60s\n":                  [2]int{2, 1},
+		"R1,0,0.1,60s,1s,0s\n# comment\nR3,0,0.1,60s,1x,0s\n": [2]int{3, 0},
+	} {
+		csvStorage := NewStringCSVStorage(utils.CSV_SEP, "", "", rates, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "")
+		_, err := csvStorage.GetTPRates(testTPID, "")
+		if le, canCast := err.(*CSVLoadError); !canCast {
+			t.Errorf("Unexpected error: %v", err)
+		} else if le.Line != ePos[0] || le.Column != ePos[1] {
+			t.Errorf("Expecting line %d, column %d, received: %s", ePos[0], ePos[1], le.Error())
+		}
+	}
+}
+
+func TestCSVStorageTolerant(t *testing.T) {
+	rates := "R1,0,0.1,60s,1s,0s\nR2,0,0.1x,60s,1s,0s\nR3,0,0.1,60s\nR4,0,0.2,60s,1s,0s\n"
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP, "", "", rates, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "")
+	if _, err := csvStorage.GetTPRates(testTPID, ""); err == nil { // strict by default
+		t.Error("Expecting error")
+	}
+	csvStorage = NewStringCSVStorage(utils.CSV_SEP, "", "", rates, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "")
+	csvStorage.SetTolerant(true)
+	tpRates, err := csvStorage.GetTPRates(testTPID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rateIDs []string
+	for _, tpRate := range tpRates {
+		rateIDs = append(rateIDs, tpRate.ID)
+	}
+	sort.Strings(rateIDs)
+	if !reflect.DeepEqual([]string{"R1", "R4"}, rateIDs) {
+		t.Errorf("Unexpected rates loaded: %v", rateIDs)
+	}
+	rows := csvStorage.QuarantinedRows()
+	if len(rows) != 2 || rows[0].Line != 2 || rows[0].Column != 3 || rows[1].Line != 3 {
+		t.Fatalf("Unexpected quarantined rows: %+v", rows)
+	}
+	var rpt bytes.Buffer
+	if err := WriteQuarantineReport(&rpt, rows); err != nil {
+		t.Error(err)
+	} else if eRpt := ",2,3,\"invalid value \"\"0.1x\"\" for field TpRate.Rate\"\n,3,1,wrong number of fields\n"; rpt.String() != eRpt {
+		t.Errorf("Unexpected report: %s", rpt.String())
+	}
+}
+
+func TestTpReaderStatistics(t *testing.T) {
+	stats := csvr.Statistics()
+	if stats.Destinations != len(csvr.destinations) ||
+		stats.RatingPlans != len(csvr.ratingPlans) ||
+		stats.ResourceLimits != len(csvr.resLimits) {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	var nrDsts int
+	for _, cnt := range stats.PrefixesDistribution {
+		nrDsts += cnt
+	}
+	if nrDsts != stats.Destinations {
+		t.Errorf("Prefixes distribution: %v not covering %d destinations", stats.PrefixesDistribution, stats.Destinations)
+	}
+	if len(stats.RateIntervalsDistribution) == 0 {
+		t.Error("Empty rate intervals distribution")
+	}
+	if stats.MemoryEstimates["Destinations"] <= 0 {
+		t.Errorf("Unexpected memory estimates: %v", stats.MemoryEstimates)
+	}
+	emptyStats := NewTpReader(nil, nil, "", "UTC").Statistics()
+	if emptyStats.Destinations != 0 || emptyStats.AvgPrefixes != 0 {
+		t.Errorf("Unexpected stats for empty reader: %+v", emptyStats)
+	}
+}
+
+type testRLLoadReader struct {
+	LoadReader
+	rls []*utils.TPResourceLimit
+}
+
+func (lr *testRLLoadReader) GetTPResourceLimits(tpid, id string) (rls []*utils.TPResourceLimit, err error) {
+	for _, rl := range lr.rls {
+		if id == "" || rl.ID == id {
+			rls = append(rls, rl)
+		}
+	}
+	return
+}
+
+func TestLoadResourceLimitsFilteredIndexes(t *testing.T) {
+	dataDB, _ := NewMapStorage()
+	lr := &testRLLoadReader{rls: []*utils.TPResourceLimit{
+		&utils.TPResourceLimit{TPid: testTPID, ID: "RL_FLTR", Limit: "2",
+			Filters: []*utils.TPRequestFilter{&utils.TPRequestFilter{Type: MetaString, FieldName: "Account", Values: []string{"1001"}}}},
+	}}
+	tpr := NewTpReader(dataDB, lr, testTPID, "UTC")
+	if err := tpr.LoadResourceLimitsFiltered("RL_FLTR", true); err != nil {
+		t.Fatal(err)
+	}
+	if rlIDs, err := dataDB.MatchReqFilterIndex(utils.ResourceLimitsIndex, "Account:1001"); err != nil {
+		t.Error(err)
+	} else if !rlIDs["RL_FLTR"] {
+		t.Errorf("Unexpected index: %+v", rlIDs)
+	}
+	lr.rls[0].Filters[0].Values = []string{"1002"} // changed filters
+	if err := tpr.LoadResourceLimitsFiltered("RL_FLTR", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataDB.MatchReqFilterIndex(utils.ResourceLimitsIndex, "Account:1001"); err != utils.ErrNotFound {
+		t.Errorf("Stale index, error: %v", err)
+	}
+	if rlIDs, err := dataDB.MatchReqFilterIndex(utils.ResourceLimitsIndex, "Account:1002"); err != nil {
+		t.Error(err)
+	} else if !rlIDs["RL_FLTR"] {
+		t.Errorf("Unexpected index: %+v", rlIDs)
+	}
+	lr.rls = nil // removed from tariff plan
+	if err := tpr.LoadResourceLimitsFiltered("RL_FLTR", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataDB.GetResourceLimit("RL_FLTR", true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("ResourceLimit not removed, error: %v", err)
+	}
+	if idxs, err := dataDB.GetReqFilterIndexes(utils.ResourceLimitsIndex); err != nil {
+		t.Error(err)
+	} else if len(idxs) != 0 {
+		t.Errorf("Unexpected indexes: %+v", idxs)
+	}
+}
+
+func TestTpReaderLint(t *testing.T) {
+	csvStorage := NewStringCSVStorage(utils.CSV_SEP,
+		"DST_USED,4950\nDST_LCR,4951\nDST_BAL,4952\nDST_UNUSED,4953\n",                                                       // destinations
+		"TM_USED,*any,*any,*any,*any,00:00:00\nTM_AP,*any,*any,*any,*any,00:00:00\nTM_UNUSED,*any,*any,*any,*any,00:00:00\n", // timings
+		"RT_USED,0,0.1,60s,1s,0s\nRT_UNUSED,0,0.2,60s,1s,0s\n",                                                               // rates
+		"DR_USED,DST_USED,RT_USED,*up,4,0,\nDR_UNUSED,DST_UNUSED,RT_USED,*up,4,0,\n",                                         // destination rates
+		"RP_LINT,DR_USED,TM_USED,10\n",                                                                                       // rating plans
+		"", "",
+		"*in,cgrates.org,call,*any,*any,DST_LCR,rif_lcr,*static,suppl1,2012-01-01T00:00:00Z,10\n",                                          // lcrs
+		"ACT_AP,*topup_reset,,,,*monetary,*out,,DST_BAL,,,*unlimited,,10,10,false,false,10\nACT_UNUSED,*log,,,,,,,,,,,,,,false,false,10\n", // actions
+		"AP_LINT,ACT_AP,TM_AP,10\n", // action plans
+		"", "", "", "", "", "", "", "", "", "")
+	tpr := NewTpReader(nil, csvStorage, testTPID, "")
+	issues, err := tpr.Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eIssues := []string{
+		"Actions <ACT_UNUSED> not attached to any ActionPlan or ActionTrigger",
+		"Destination <DST_UNUSED> not used by any RatingPlan, LCR or balance filter",
+		"DestinationRate <DR_UNUSED> not referenced by any RatingPlan",
+		"Rate <RT_UNUSED> not referenced by any DestinationRate",
+		"Timing <TM_UNUSED> not used by any RatingPlan or ActionPlan",
+	}
+	if !reflect.DeepEqual(eIssues, issues) {
+		t.Errorf("Expecting: %q, received: %q", eIssues, issues)
+	}
+}
+
+// countingLoadReader counts the prerequisite queries done by the filtered loads
+type countingLoadReader struct {
+	LoadReader
+	mux   sync.Mutex
+	calls map[string]int
+}
+
+func (clr *countingLoadReader) count(key string) {
+	clr.mux.Lock()
+	clr.calls[key]++
+	clr.mux.Unlock()
+}
+
+func (clr *countingLoadReader) GetTPTimings(tpid, id string) ([]*utils.ApierTPTiming, error) {
+	clr.count("timing:" + id)
+	return clr.LoadReader.GetTPTimings(tpid, id)
+}
+
+func (clr *countingLoadReader) GetTPRates(tpid, id string) ([]*utils.TPRate, error) {
+	clr.count("rate:" + id)
+	return clr.LoadReader.GetTPRates(tpid, id)
+}
+
+func (clr *countingLoadReader) GetTPDestinationRates(tpid, id string, pg *utils.Paginator) ([]*utils.TPDestinationRate, error) {
+	clr.count("destination_rate:" + id)
+	return clr.LoadReader.GetTPDestinationRates(tpid, id, pg)
+}
+
+func (clr *countingLoadReader) GetTPActions(tpid, id string) ([]*utils.TPActions, error) {
+	clr.count("actions:" + id)
+	return clr.LoadReader.GetTPActions(tpid, id)
+}
+
+func TestLoadFilteredSharedLookups(t *testing.T) {
+	dataDB, _ := NewMapStorage()
+	clr := &countingLoadReader{LoadReader: csvr.lr, calls: make(map[string]int)}
+	tpr := NewTpReader(dataDB, clr, testTPID, "")
+	if loaded, err := tpr.LoadRatingPlansFiltered(""); err != nil {
+		t.Fatal(err)
+	} else if !loaded {
+		t.Fatal("No rating plans loaded")
+	}
+	for key, cnt := range clr.calls {
+		if cnt != 1 {
+			t.Errorf("Queried %s %d times", key, cnt)
+		}
+	}
+	rplKeys, _ := dataDB.GetKeysForPrefix(utils.RATING_PLAN_PREFIX)
+	if len(rplKeys) != len(csvr.ratingPlans) {
+		t.Errorf("Expecting %d rating plans, received: %d", len(csvr.ratingPlans), len(rplKeys))
+	}
+	if rpl, err := dataDB.GetRatingPlan("STANDARD", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(csvr.ratingPlans["STANDARD"].DestinationRates, rpl.DestinationRates) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(csvr.ratingPlans["STANDARD"]), utils.ToJSON(rpl))
+	}
+	clr.calls = make(map[string]int)
+	if err := tpr.LoadAccountActionsFiltered(&utils.TPAccountActions{TPid: testTPID}); err != nil {
+		t.Fatal(err)
+	}
+	if len(clr.calls) == 0 {
+		t.Error("No actions queried")
+	}
+	for key, cnt := range clr.calls {
+		if cnt != 1 {
+			t.Errorf("Queried %s %d times", key, cnt)
+		}
+	}
+	acntKeys, _ := dataDB.GetKeysForPrefix(utils.ACCOUNT_PREFIX)
+	if len(acntKeys) != len(csvr.accountActions) {
+		t.Errorf("Expecting %d accounts, received: %d", len(csvr.accountActions), len(acntKeys))
+	}
+	if apl, err := dataDB.GetActionPlan("MORE_MINUTES", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if len(apl.AccountIDs) != len(csvr.actionPlans["MORE_MINUTES"].AccountIDs) {
+		t.Errorf("Expecting: %+v, received: %+v", csvr.actionPlans["MORE_MINUTES"].AccountIDs, apl.AccountIDs)
+	}
+}
@@ -102,6 +102,9 @@ func TestLoaderITLoadFromCSV(t *testing.T) {
 		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.USERS_CSV),
 		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.ALIASES_CSV),
 		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.ResourceLimitsCsv),
+		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.ExchangeRatesCsv),
+		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.HolidayCalendarsCsv),
+		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.DialPlansCsv),
 	), "", "")
 
 	if err = loader.LoadDestinations(); err != nil {
@@ -0,0 +1,300 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewMergedLoadReader returns a LoadReader merging the objects of tpids out of lr,
+// objects defined in more than one TP are resolved according to policy <*error|*first|*last>
+func NewMergedLoadReader(lr LoadReader, tpids []string, policy string) (*MergedLoadReader, error) {
+	if len(tpids) == 0 {
+		return nil, utils.NewErrMandatoryIeMissing("TPids")
+	}
+	switch policy {
+	case "":
+		policy = utils.MetaError
+	case utils.MetaError, utils.MetaFirst, utils.MetaLast:
+	default:
+		return nil, fmt.Errorf("unsupported conflict policy <%s>", policy)
+	}
+	return &MergedLoadReader{lr: lr, tpids: tpids, policy: policy}, nil
+}
+
+// MergedLoadReader combines base tariff plans with their overlays at load time,
+// the tpid passed to its methods is ignored in favour of the merged ones
+type MergedLoadReader struct {
+	lr     LoadReader
+	tpids  []string // in order of precedence for the *last policy, eg: base first, overlays after
+	policy string
+}
+
+// merge collects the items returned by get for each tpid into reply, a pointer to the slice of items,
+// items sharing the key are taken out of one TP only, multiple items of the same TP are kept together
+func (mlr *MergedLoadReader) merge(reply interface{}, get func(tpid string) (interface{}, error), key func(item interface{}) string) error {
+	var keys []string
+	items := make(map[string][]interface{})
+	owners := make(map[string]string) // key -> tpid the items are taken from
+	var found bool
+	for _, tpid := range mlr.tpids {
+		tpItems, err := get(tpid)
+		if err == utils.ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		found = true
+		lst := reflect.ValueOf(tpItems)
+		for i := 0; i < lst.Len(); i++ {
+			item := lst.Index(i).Interface()
+			k := key(item)
+			owner, has := owners[k]
+			switch {
+			case !has:
+				keys = append(keys, k)
+				owners[k] = tpid
+			case owner == tpid:
+			case mlr.policy == utils.MetaFirst:
+				continue
+			case mlr.policy == utils.MetaLast:
+				owners[k] = tpid
+				items[k] = nil
+			default:
+				return fmt.Errorf("%T <%s> defined in both TPids <%s> and <%s>", item, k, owner, tpid)
+			}
+			items[k] = append(items[k], item)
+		}
+	}
+	if !found {
+		return utils.ErrNotFound
+	}
+	rv := reflect.ValueOf(reply).Elem()
+	for _, k := range keys {
+		for _, item := range items[k] {
+			rv = reflect.Append(rv, reflect.ValueOf(item))
+		}
+	}
+	reflect.ValueOf(reply).Elem().Set(rv)
+	return nil
+}
+
+func (mlr *MergedLoadReader) GetTpIds() ([]string, error) {
+	return mlr.lr.GetTpIds()
+}
+
+// GetTpTableIds returns the ids out of all merged TPs, paginated once merged
+func (mlr *MergedLoadReader) GetTpTableIds(tpid, table string, distinct utils.TPDistinctIds,
+	filters map[string]string, pag *utils.Paginator) (ids []string, err error) {
+	idsMp := make(utils.StringMap)
+	for _, tpid := range mlr.tpids {
+		tpIDs, err := mlr.lr.GetTpTableIds(tpid, table, distinct, filters, nil)
+		if err != nil && err != utils.ErrNotFound {
+			return nil, err
+		}
+		for _, id := range tpIDs {
+			idsMp[id] = true
+		}
+	}
+	if len(idsMp) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	ids = idsMp.Slice()
+	sort.Strings(ids)
+	if pag != nil {
+		ids = pag.PaginateStringSlice(ids)
+	}
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPTimings(tpid, id string) (tps []*utils.ApierTPTiming, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPTimings(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.ApierTPTiming).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPDestinations(tpid, id string) (tps []*utils.TPDestination, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPDestinations(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPDestination).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPRates(tpid, id string) (tps []*utils.TPRate, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPRates(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPRate).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPDestinationRates(tpid, id string, pag *utils.Paginator) (tps []*utils.TPDestinationRate, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPDestinationRates(tpid, id, pag)
+	}, func(item interface{}) string { return item.(*utils.TPDestinationRate).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPRatingPlans(tpid, id string, pag *utils.Paginator) (tps []*utils.TPRatingPlan, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPRatingPlans(tpid, id, pag)
+	}, func(item interface{}) string { return item.(*utils.TPRatingPlan).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPRatingProfiles(filter *utils.TPRatingProfile) (tps []*utils.TPRatingProfile, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		tpFltr := new(utils.TPRatingProfile)
+		if filter != nil {
+			*tpFltr = *filter
+		}
+		tpFltr.TPid = tpid
+		return mlr.lr.GetTPRatingProfiles(tpFltr)
+	}, func(item interface{}) string { return item.(*utils.TPRatingProfile).KeyId() })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPSharedGroups(tpid, id string) (tps []*utils.TPSharedGroups, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPSharedGroups(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPSharedGroups).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPCdrStats(tpid, id string) (tps []*utils.TPCdrStats, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPCdrStats(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPCdrStats).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPLCRs(filter *utils.TPLcrRules) (tps []*utils.TPLcrRules, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		tpFltr := new(utils.TPLcrRules)
+		if filter != nil {
+			*tpFltr = *filter
+		}
+		tpFltr.TPid = tpid
+		return mlr.lr.GetTPLCRs(tpFltr)
+	}, func(item interface{}) string { return item.(*utils.TPLcrRules).GetLcrRuleId() })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPUsers(filter *utils.TPUsers) (tps []*utils.TPUsers, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		tpFltr := new(utils.TPUsers)
+		if filter != nil {
+			*tpFltr = *filter
+		}
+		tpFltr.TPid = tpid
+		return mlr.lr.GetTPUsers(tpFltr)
+	}, func(item interface{}) string { return item.(*utils.TPUsers).GetId() })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPAliases(filter *utils.TPAliases) (tps []*utils.TPAliases, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		tpFltr := new(utils.TPAliases)
+		if filter != nil {
+			*tpFltr = *filter
+		}
+		tpFltr.TPid = tpid
+		return mlr.lr.GetTPAliases(tpFltr)
+	}, func(item interface{}) string { return item.(*utils.TPAliases).GetId() })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPDerivedChargers(filter *utils.TPDerivedChargers) (tps []*utils.TPDerivedChargers, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		tpFltr := new(utils.TPDerivedChargers)
+		if filter != nil {
+			*tpFltr = *filter
+		}
+		tpFltr.TPid = tpid
+		return mlr.lr.GetTPDerivedChargers(tpFltr)
+	}, func(item interface{}) string { return item.(*utils.TPDerivedChargers).GetDerivedChargersKey() })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPActions(tpid, id string) (tps []*utils.TPActions, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPActions(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPActions).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPActionPlans(tpid, id string) (tps []*utils.TPActionPlan, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPActionPlans(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPActionPlan).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPActionTriggers(tpid, id string) (tps []*utils.TPActionTriggers, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPActionTriggers(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPActionTriggers).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPAccountActions(filter *utils.TPAccountActions) (tps []*utils.TPAccountActions, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		tpFltr := new(utils.TPAccountActions)
+		if filter != nil {
+			*tpFltr = *filter
+		}
+		tpFltr.TPid = tpid
+		return mlr.lr.GetTPAccountActions(tpFltr)
+	}, func(item interface{}) string { return item.(*utils.TPAccountActions).KeyId() })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPResourceLimits(tpid, id string) (tps []*utils.TPResourceLimit, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPResourceLimits(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPResourceLimit).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPExchangeRates(tpid, fromCurrency string) (tps []*utils.TPExchangeRate, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPExchangeRates(tpid, fromCurrency)
+	}, func(item interface{}) string {
+		er := item.(*utils.TPExchangeRate)
+		return utils.ConcatenatedKey(er.FromCurrency, er.ToCurrency)
+	})
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPHolidayCalendars(tpid, id string) (tps []*utils.TPHolidayCalendar, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPHolidayCalendars(tpid, id)
+	}, func(item interface{}) string { return item.(*utils.TPHolidayCalendar).ID })
+	return
+}
+
+func (mlr *MergedLoadReader) GetTPDialPlans(tpid, tenant string) (tps []*utils.TPDialPlan, err error) {
+	err = mlr.merge(&tps, func(tpid string) (interface{}, error) {
+		return mlr.lr.GetTPDialPlans(tpid, tenant)
+	}, func(item interface{}) string { return item.(*utils.TPDialPlan).Tenant })
+	return
+}
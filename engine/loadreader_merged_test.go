@@ -0,0 +1,102 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// testTPidsLoadReader returns the rates and rating profiles stored per tpid
+type testTPidsLoadReader struct {
+	LoadReader
+	rates map[string][]*utils.TPRate
+	rpfs  map[string][]*utils.TPRatingProfile
+}
+
+func (lr *testTPidsLoadReader) GetTPRates(tpid, id string) (rts []*utils.TPRate, err error) {
+	for _, rt := range lr.rates[tpid] {
+		if id == "" || rt.ID == id {
+			rts = append(rts, rt)
+		}
+	}
+	if len(rts) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return
+}
+
+func (lr *testTPidsLoadReader) GetTPRatingProfiles(filter *utils.TPRatingProfile) ([]*utils.TPRatingProfile, error) {
+	return lr.rpfs[filter.TPid], nil
+}
+
+func TestMergedLoadReader(t *testing.T) {
+	rtBase := &utils.TPRate{TPid: "BASE", ID: "RT_PEAK"}
+	rtBaseOff := &utils.TPRate{TPid: "BASE", ID: "RT_OFFPEAK"}
+	rtCustomer := &utils.TPRate{TPid: "CUSTOMER1", ID: "RT_PEAK"}
+	rtCustomerNew := &utils.TPRate{TPid: "CUSTOMER1", ID: "RT_PREMIUM"}
+	lr := &testTPidsLoadReader{
+		rates: map[string][]*utils.TPRate{
+			"BASE":      []*utils.TPRate{rtBase, rtBaseOff},
+			"CUSTOMER1": []*utils.TPRate{rtCustomer, rtCustomerNew},
+		},
+		rpfs: map[string][]*utils.TPRatingProfile{
+			"BASE": []*utils.TPRatingProfile{&utils.TPRatingProfile{TPid: "BASE", LoadId: "TEST", Direction: utils.OUT,
+				Tenant: "cgrates.org", Category: "call", Subject: "*any"}},
+		},
+	}
+	if _, err := NewMergedLoadReader(lr, []string{"BASE"}, "*unknown"); err == nil {
+		t.Error("Expecting error on unsupported policy")
+	}
+	mlr, err := NewMergedLoadReader(lr, []string{"BASE", "CUSTOMER1"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mlr.GetTPRates("", ""); err == nil {
+		t.Error("Expecting conflict error")
+	}
+	if rts, err := mlr.GetTPRates("", "RT_OFFPEAK"); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual([]*utils.TPRate{rtBaseOff}, rts) {
+		t.Errorf("Unexpected rates: %s", utils.ToJSON(rts))
+	}
+	if _, err := mlr.GetTPRates("", "RT_MISSING"); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	mlr.policy = utils.MetaFirst
+	eRts := []*utils.TPRate{rtBase, rtBaseOff, rtCustomerNew}
+	if rts, err := mlr.GetTPRates("", ""); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eRts, rts) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eRts), utils.ToJSON(rts))
+	}
+	mlr.policy = utils.MetaLast
+	eRts = []*utils.TPRate{rtCustomer, rtBaseOff, rtCustomerNew}
+	if rts, err := mlr.GetTPRates("", ""); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eRts, rts) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eRts), utils.ToJSON(rts))
+	}
+	if rpfs, err := mlr.GetTPRatingProfiles(&utils.TPRatingProfile{TPid: "BASE;CUSTOMER1"}); err != nil {
+		t.Error(err)
+	} else if len(rpfs) != 1 || rpfs[0].TPid != "BASE" {
+		t.Errorf("Unexpected rating profiles: %s", utils.ToJSON(rpfs))
+	}
+}
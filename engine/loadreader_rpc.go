@@ -0,0 +1,166 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"encoding/json"
+
+	"github.com/cgrates/cgrates/utils"
+	"github.com/cgrates/rpcclient"
+)
+
+// NewRPCLoadReader returns a LoadReader pulling the tariff plan out of the storDb of a remote engine
+func NewRPCLoadReader(conn rpcclient.RpcClientConnection) *RPCLoadReader {
+	return &RPCLoadReader{conn: conn}
+}
+
+// RPCLoadReader implements LoadReader over the ApierV1.GetTPLoadData API of another CGRateS engine
+type RPCLoadReader struct {
+	conn rpcclient.RpcClientConnection
+}
+
+// call queries the remote LoadReader method, decoding the result into reply
+func (lr *RPCLoadReader) call(method string, reply interface{}, args ...interface{}) error {
+	attrs := utils.AttrGetTPLoadData{Method: method, Args: make([]json.RawMessage, len(args))}
+	for i, arg := range args {
+		b, err := json.Marshal(arg)
+		if err != nil {
+			return err
+		}
+		attrs.Args[i] = b
+	}
+	var raw json.RawMessage
+	if err := lr.conn.Call("ApierV1.GetTPLoadData", attrs, &raw); err != nil {
+		if err.Error() == utils.ErrNotFound.Error() { // errors lose identity over the wire
+			return utils.ErrNotFound
+		}
+		return err
+	}
+	return json.Unmarshal(raw, reply)
+}
+
+func (lr *RPCLoadReader) GetTpIds() (ids []string, err error) {
+	err = lr.call("GetTpIds", &ids)
+	return
+}
+
+func (lr *RPCLoadReader) GetTpTableIds(tpid, table string, distinct utils.TPDistinctIds,
+	filters map[string]string, pag *utils.Paginator) (ids []string, err error) {
+	err = lr.call("GetTpTableIds", &ids, tpid, table, distinct, filters, pag)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPTimings(tpid, id string) (tps []*utils.ApierTPTiming, err error) {
+	err = lr.call("GetTPTimings", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPDestinations(tpid, id string) (tps []*utils.TPDestination, err error) {
+	err = lr.call("GetTPDestinations", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPRates(tpid, id string) (tps []*utils.TPRate, err error) {
+	err = lr.call("GetTPRates", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPDestinationRates(tpid, id string, pag *utils.Paginator) (tps []*utils.TPDestinationRate, err error) {
+	err = lr.call("GetTPDestinationRates", &tps, tpid, id, pag)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPRatingPlans(tpid, id string, pag *utils.Paginator) (tps []*utils.TPRatingPlan, err error) {
+	err = lr.call("GetTPRatingPlans", &tps, tpid, id, pag)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPRatingProfiles(filter *utils.TPRatingProfile) (tps []*utils.TPRatingProfile, err error) {
+	err = lr.call("GetTPRatingProfiles", &tps, filter)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPSharedGroups(tpid, id string) (tps []*utils.TPSharedGroups, err error) {
+	err = lr.call("GetTPSharedGroups", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPCdrStats(tpid, id string) (tps []*utils.TPCdrStats, err error) {
+	err = lr.call("GetTPCdrStats", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPLCRs(filter *utils.TPLcrRules) (tps []*utils.TPLcrRules, err error) {
+	err = lr.call("GetTPLCRs", &tps, filter)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPUsers(filter *utils.TPUsers) (tps []*utils.TPUsers, err error) {
+	err = lr.call("GetTPUsers", &tps, filter)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPAliases(filter *utils.TPAliases) (tps []*utils.TPAliases, err error) {
+	err = lr.call("GetTPAliases", &tps, filter)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPDerivedChargers(filter *utils.TPDerivedChargers) (tps []*utils.TPDerivedChargers, err error) {
+	err = lr.call("GetTPDerivedChargers", &tps, filter)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPActions(tpid, id string) (tps []*utils.TPActions, err error) {
+	err = lr.call("GetTPActions", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPActionPlans(tpid, id string) (tps []*utils.TPActionPlan, err error) {
+	err = lr.call("GetTPActionPlans", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPActionTriggers(tpid, id string) (tps []*utils.TPActionTriggers, err error) {
+	err = lr.call("GetTPActionTriggers", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPAccountActions(filter *utils.TPAccountActions) (tps []*utils.TPAccountActions, err error) {
+	err = lr.call("GetTPAccountActions", &tps, filter)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPResourceLimits(tpid, id string) (tps []*utils.TPResourceLimit, err error) {
+	err = lr.call("GetTPResourceLimits", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPExchangeRates(tpid, fromCurrency string) (tps []*utils.TPExchangeRate, err error) {
+	err = lr.call("GetTPExchangeRates", &tps, tpid, fromCurrency)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPHolidayCalendars(tpid, id string) (tps []*utils.TPHolidayCalendar, err error) {
+	err = lr.call("GetTPHolidayCalendars", &tps, tpid, id)
+	return
+}
+
+func (lr *RPCLoadReader) GetTPDialPlans(tpid, tenant string) (tps []*utils.TPDialPlan, err error) {
+	err = lr.call("GetTPDialPlans", &tps, tpid, tenant)
+	return
+}
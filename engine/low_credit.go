@@ -0,0 +1,44 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"github.com/cgrates/cgrates/utils"
+)
+
+var emergencyDestinations utils.StringMap // destination IDs authorized and debited regardless of the balance
+
+// SetEmergencyDestinations sets the destinations authorized and debited regardless of the balance
+func SetEmergencyDestinations(destIDs []string) {
+	emergencyDestinations = utils.NewStringMap(destIDs...)
+}
+
+// isEmergency returns true if the destination of the call matches one of the emergency destinations
+func (cd *CallDescriptor) isEmergency() bool {
+	if len(emergencyDestinations) == 0 || cd.Destination == "" || cd.Destination == utils.ANY {
+		return false
+	}
+	for _, dm := range matchReverseDestinations(dataStorage, cd.Destination) {
+		for _, dID := range dm.DestIDs {
+			if emergencyDestinations[dID] {
+				return true
+			}
+		}
+	}
+	return false
+}
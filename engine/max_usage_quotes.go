@@ -0,0 +1,121 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// limits the destinations quoted by one request
+const MaxUsageQuotesDestinations = 1000
+
+// MaxUsageQuotes asks for the usage the account can afford towards each of the destinations
+type MaxUsageQuotes struct {
+	Direction, Category, Tenant, Account, Subject, TOR string
+	Destinations                                       []string
+	TimeStart                                          time.Time
+	Usage                                              time.Duration // upper limit of the quoted usage
+}
+
+// MaxUsageQuote is the usage affordable towards Destination and its cost
+type MaxUsageQuote struct {
+	Destination string
+	MaxUsage    time.Duration // -1 when not limited
+	Cost        float64       // cost of MaxUsage, of the Usage asked when not limited
+	Currency    string
+	Error       string // the destination could not be quoted
+}
+
+// maxUsageQuote computes the MaxUsageQuote towards the destination of cd, on a dry run of the account
+func (cd *CallDescriptor) maxUsageQuote(account *Account) (q *MaxUsageQuote) {
+	q = &MaxUsageQuote{Destination: cd.Destination}
+	if err := LoadAlias(
+		&AttrMatchingAlias{
+			Destination: cd.Destination,
+			Direction:   cd.Direction,
+			Tenant:      cd.Tenant,
+			Category:    cd.Category,
+			Account:     cd.Account,
+			Subject:     cd.Subject,
+			Context:     utils.ALIAS_CONTEXT_RATING,
+		}, cd, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
+		q.Error = err.Error()
+		return
+	}
+	usage := cd.GetDuration()
+	var err error
+	if q.MaxUsage, err = cd.getMaxSessionDuration(account); err != nil {
+		q.Error = err.Error()
+		return
+	}
+	if q.MaxUsage != -1 {
+		usage = q.MaxUsage
+	}
+	cd.TimeEnd = cd.TimeStart.Add(usage)
+	cd.DurationIndex = usage
+	cc, err := cd.GetCost()
+	if err != nil {
+		q.Error = err.Error()
+		return
+	}
+	q.Cost, q.Currency = cc.Cost, cc.GetCurrency()
+	return
+}
+
+// GetMaxUsageQuotes returns the usage affordable towards each destination, loading the account and user profile only once
+func (rs *Responder) GetMaxUsageQuotes(arg *MaxUsageQuotes, reply *[]*MaxUsageQuote) error {
+	if len(arg.Destinations) > MaxUsageQuotesDestinations {
+		return fmt.Errorf("more than %d destinations", MaxUsageQuotesDestinations)
+	}
+	cd := &CallDescriptor{
+		Direction:     arg.Direction,
+		Category:      arg.Category,
+		Tenant:        arg.Tenant,
+		Account:       arg.Account,
+		Subject:       utils.FirstNonEmpty(arg.Subject, arg.Account),
+		TOR:           arg.TOR,
+		TimeStart:     arg.TimeStart,
+		TimeEnd:       arg.TimeStart.Add(arg.Usage),
+		DurationIndex: arg.Usage,
+	}
+	if err := LoadUserProfile(cd, utils.EXTRA_FIELDS); err != nil {
+		return err
+	}
+	quotes := make([]*MaxUsageQuote, len(arg.Destinations))
+	_, err := guardian.Guardian.Guard(func() (interface{}, error) {
+		account, err := cd.getAccount()
+		if err != nil {
+			return nil, err
+		}
+		for i, dst := range arg.Destinations {
+			dstCD := cd.Clone()
+			dstCD.Destination = dst
+			quotes[i] = dstCD.maxUsageQuote(account)
+		}
+		return nil, nil
+	}, 0, utils.ACCOUNT_PREFIX+cd.GetAccountKey())
+	if err != nil {
+		return err
+	}
+	*reply = quotes
+	return nil
+}
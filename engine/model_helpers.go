@@ -22,6 +22,7 @@ import (
 	"log"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -29,8 +30,19 @@ import (
 	"github.com/cgrates/cgrates/utils"
 )
 
+// csvFieldError is returned by csvLoad for values not fitting their model field
+type csvFieldError struct {
+	index int // position of the value within the record
+	err   error
+}
+
+func (fe *csvFieldError) Error() string {
+	return fe.err.Error()
+}
+
 func csvLoad(s interface{}, values []string) (interface{}, error) {
 	fieldValueMap := make(map[string]string)
+	fieldIndexMap := make(map[string]int)
 	st := reflect.TypeOf(s)
 	numFields := st.NumField()
 	for i := 0; i < numFields; i++ {
@@ -44,10 +56,12 @@ func csvLoad(s interface{}, values []string) (interface{}, error) {
 			}
 			if re != "" {
 				if matched, err := regexp.MatchString(re, values[idx]); !matched || err != nil {
-					return nil, fmt.Errorf("invalid %v.%v value %v", st.Name(), field.Name, values[idx])
+					return nil, &csvFieldError{index: idx,
+						err: fmt.Errorf("invalid %v.%v value %v", st.Name(), field.Name, values[idx])}
 				}
 			}
 			fieldValueMap[field.Name] = values[idx]
+			fieldIndexMap[field.Name] = idx
 		}
 	}
 	elem := reflect.New(st).Elem()
@@ -61,7 +75,8 @@ func csvLoad(s interface{}, values []string) (interface{}, error) {
 				}
 				value, err := strconv.ParseFloat(fieldValue, 64)
 				if err != nil {
-					return nil, fmt.Errorf(`invalid value "%s" for field %s.%s`, fieldValue, st.Name(), fieldName)
+					return nil, &csvFieldError{index: fieldIndexMap[fieldName],
+						err: fmt.Errorf(`invalid value "%s" for field %s.%s`, fieldValue, st.Name(), fieldName)}
 				}
 				field.SetFloat(value)
 			case reflect.Int:
@@ -70,7 +85,8 @@ func csvLoad(s interface{}, values []string) (interface{}, error) {
 				}
 				value, err := strconv.Atoi(fieldValue)
 				if err != nil {
-					return nil, fmt.Errorf(`invalid value "%s" for field %s.%s`, fieldValue, st.Name(), fieldName)
+					return nil, &csvFieldError{index: fieldIndexMap[fieldName],
+						err: fmt.Errorf(`invalid value "%s" for field %s.%s`, fieldValue, st.Name(), fieldName)}
 				}
 				field.SetInt(int64(value))
 			case reflect.Bool:
@@ -79,7 +95,8 @@ func csvLoad(s interface{}, values []string) (interface{}, error) {
 				}
 				value, err := strconv.ParseBool(fieldValue)
 				if err != nil {
-					return nil, fmt.Errorf(`invalid value "%s" for field %s.%s`, fieldValue, st.Name(), fieldName)
+					return nil, &csvFieldError{index: fieldIndexMap[fieldName],
+						err: fmt.Errorf(`invalid value "%s" for field %s.%s`, fieldValue, st.Name(), fieldName)}
 				}
 				field.SetBool(value)
 			case reflect.String:
@@ -198,13 +215,24 @@ func (tps TpDestinations) AsMapDestinations() (map[string]*Destination, error) {
 }
 
 // AsTPDestination converts TpDestinations into *utils.TPDestination
-func (tps TpDestinations) AsTPDestinations() (result []*utils.TPDestination) {
+// Prefixes of *ip type are converted into their CIDR notation
+func (tps TpDestinations) AsTPDestinations() (result []*utils.TPDestination, err error) {
 	md := make(map[string]*utils.TPDestination) // Should save us some CPU if we index here for big number of destinations to search
 	for _, tp := range tps {
+		prefix := tp.Prefix
+		switch tp.Type {
+		case "", utils.MetaPrefix:
+		case utils.MetaIP:
+			if prefix, err = utils.ParseIPPrefix(tp.Prefix); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported destination type: %s", tp.Type)
+		}
 		if d, hasIt := md[tp.Tag]; !hasIt {
-			md[tp.Tag] = &utils.TPDestination{TPid: tp.Tpid, ID: tp.Tag, Prefixes: []string{tp.Prefix}}
+			md[tp.Tag] = &utils.TPDestination{TPid: tp.Tpid, ID: tp.Tag, Prefixes: []string{prefix}}
 		} else {
-			d.Prefixes = append(d.Prefixes, tp.Prefix)
+			d.Prefixes = append(d.Prefixes, prefix)
 		}
 	}
 	result = make([]*utils.TPDestination, len(md))
@@ -219,11 +247,15 @@ func (tps TpDestinations) AsTPDestinations() (result []*utils.TPDestination) {
 func APItoModelDestination(d *utils.TPDestination) (result TpDestinations) {
 	if d != nil {
 		for _, p := range d.Prefixes {
-			result = append(result, TpDestination{
+			tpDst := TpDestination{
 				Tpid:   d.TPid,
 				Tag:    d.ID,
 				Prefix: p,
-			})
+			}
+			if utils.IsIPPrefix(p) {
+				tpDst.Type = utils.MetaIP
+			}
+			result = append(result, tpDst)
 		}
 		if len(d.Prefixes) == 0 {
 			result = append(result, TpDestination{
@@ -268,6 +300,17 @@ func MapTPTimings(tps []*utils.ApierTPTiming) (map[string]*utils.TPTiming, error
 		if len(times) > 1 {
 			t.EndTime = times[1]
 		}
+		if len(times) > 2 {
+			switch times[2] {
+			case "", utils.MetaWallClock, utils.MetaUTCOffset, utils.MetaFirstOccurrence, utils.MetaLastOccurrence:
+				t.DSTPolicy = times[2]
+			default:
+				return nil, fmt.Errorf("unsupported DST policy <%s> for timing: %s", times[2], tp.ID)
+			}
+		}
+		if len(times) > 3 && times[3] != "" {
+			t.HolidayCalendars = strings.Split(times[3], utils.InInFieldSep)
+		}
 		if _, found := result[tp.ID]; found {
 			return nil, fmt.Errorf("duplicate timing tag: %s", tp.ID)
 		}
@@ -516,12 +559,14 @@ func (tps TpRatingPlans) AsTPRatingPlans() (result []*utils.TPRatingPlan, err er
 func GetRateInterval(rpl *utils.TPRatingPlanBinding, dr *utils.DestinationRate) (i *RateInterval) {
 	i = &RateInterval{
 		Timing: &RITiming{
-			Years:     rpl.Timing().Years,
-			Months:    rpl.Timing().Months,
-			MonthDays: rpl.Timing().MonthDays,
-			WeekDays:  rpl.Timing().WeekDays,
-			StartTime: rpl.Timing().StartTime,
-			tag:       rpl.Timing().ID,
+			Years:            rpl.Timing().Years,
+			Months:           rpl.Timing().Months,
+			MonthDays:        rpl.Timing().MonthDays,
+			WeekDays:         rpl.Timing().WeekDays,
+			StartTime:        rpl.Timing().StartTime,
+			DSTPolicy:        rpl.Timing().DSTPolicy,
+			ID:               rpl.Timing().ID,
+			HolidayCalendars: rpl.Timing().HolidayCalendars,
 		},
 		Weight: rpl.Weight,
 		Rating: &RIRate{
@@ -534,6 +579,16 @@ func GetRateInterval(rpl *utils.TPRatingPlanBinding, dr *utils.DestinationRate)
 		},
 	}
 	for _, rl := range dr.Rate.RateSlots {
+		if i.Rating.TierPeriod == "" {
+			i.Rating.TierPeriod = rl.TierPeriod()
+		}
+		if i.Rating.Currency == "" {
+			i.Rating.Currency = rl.Currency()
+		}
+		if i.Rating.MinCharge == 0 {
+			i.Rating.MinCharge = rl.MinCharge()
+			i.Rating.MinChargeUsage = rl.MinChargeUsage()
+		}
 		i.Rating.Rates = append(i.Rating.Rates, &Rate{
 			GroupIntervalStart: rl.GroupIntervalStartDuration(),
 			Value:              rl.Rate,
@@ -606,6 +661,10 @@ func (tps TpRatingProfiles) AsMapTPRatingProfiles() (map[string]*utils.TPRatingP
 			RatingPlanId:     tp.RatingPlanTag,
 			FallbackSubjects: tp.FallbackSubjects,
 			CdrStatQueueIds:  tp.CdrStatQueueIds,
+			DeactivationTime: tp.DeactivationTime,
+			MaxCost:          tp.MaxCost,
+			MaxCostStrategy:  tp.MaxCostStrategy,
+			TrafficShare:     tp.TrafficShare,
 		}
 		if existing, exists := result[rp.KeyIdA()]; !exists {
 			rp.RatingPlanActivations = []*utils.TPRatingActivation{ra}
@@ -654,6 +713,10 @@ func APItoModelRatingProfile(rp *utils.TPRatingProfile) (result TpRatingProfiles
 				RatingPlanTag:    rpa.RatingPlanId,
 				FallbackSubjects: rpa.FallbackSubjects,
 				CdrStatQueueIds:  rpa.CdrStatQueueIds,
+				DeactivationTime: rpa.DeactivationTime,
+				MaxCost:          rpa.MaxCost,
+				MaxCostStrategy:  rpa.MaxCostStrategy,
+				TrafficShare:     rpa.TrafficShare,
 			})
 		}
 		if len(rp.RatingPlanActivations) == 0 {
@@ -879,6 +942,12 @@ func (tps TpActionPlans) AsMapTPActionPlans() (map[string]*utils.TPActionPlan, e
 			TimingId:  tp.TimingTag,
 			Weight:    tp.Weight,
 		}
+		if idx := strings.Index(tp.TimingTag, utils.INFIELD_SEP); idx != -1 { // catch up policy as timing option, eg: MONTHLY;*run_all_missed
+			a.TimingId, a.CatchUp = tp.TimingTag[:idx], tp.TimingTag[idx+1:]
+			if !utils.IsCatchUpPolicy(a.CatchUp) {
+				return nil, fmt.Errorf("unsupported catch up policy: %s", a.CatchUp)
+			}
+		}
 		if existing, exists := result[as.ID]; !exists {
 			as.ActionPlan = []*utils.TPActionTiming{a}
 			result[as.ID] = as
@@ -917,11 +986,15 @@ func MapTPActionTimings(s []*utils.TPActionPlan) map[string][]*utils.TPActionTim
 func APItoModelActionPlan(a *utils.TPActionPlan) (result TpActionPlans) {
 	if a != nil {
 		for _, ap := range a.ActionPlan {
+			timingTag := ap.TimingId
+			if ap.CatchUp != "" {
+				timingTag += utils.INFIELD_SEP + ap.CatchUp
+			}
 			result = append(result, TpActionPlan{
 				Tpid:       a.TPid,
 				Tag:        a.ID,
 				ActionsTag: ap.ActionsId,
-				TimingTag:  ap.TimingId,
+				TimingTag:  timingTag,
 				Weight:     ap.Weight,
 			})
 		}
@@ -1934,3 +2007,151 @@ func APItoResourceLimit(tpRL *utils.TPResourceLimit, timezone string) (rl *Resou
 	}
 	return rl, nil
 }
+
+type TpExchangeRates []*TpExchangeRate
+
+func (tps TpExchangeRates) AsTPExchangeRates() (result []*utils.TPExchangeRate) {
+	for _, tp := range tps {
+		result = append(result, &utils.TPExchangeRate{
+			TPid:         tp.Tpid,
+			FromCurrency: tp.FromCurrency,
+			ToCurrency:   tp.ToCurrency,
+			Rate:         tp.Rate,
+		})
+	}
+	return
+}
+
+func APItoModelExchangeRate(er *utils.TPExchangeRate) *TpExchangeRate {
+	return &TpExchangeRate{
+		Tpid:         er.TPid,
+		FromCurrency: er.FromCurrency,
+		ToCurrency:   er.ToCurrency,
+		Rate:         er.Rate,
+	}
+}
+
+func APItoExchangeRate(tpER *utils.TPExchangeRate) (*ExchangeRate, error) {
+	if !utils.IsCurrencyCode(tpER.FromCurrency) || !utils.IsCurrencyCode(tpER.ToCurrency) {
+		return nil, fmt.Errorf("invalid currencies for exchange rate: %s -> %s", tpER.FromCurrency, tpER.ToCurrency)
+	}
+	if tpER.Rate <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %v for %s -> %s", tpER.Rate, tpER.FromCurrency, tpER.ToCurrency)
+	}
+	return &ExchangeRate{
+		ID:           utils.ConcatenatedKey(tpER.FromCurrency, tpER.ToCurrency),
+		FromCurrency: tpER.FromCurrency,
+		ToCurrency:   tpER.ToCurrency,
+		Rate:         tpER.Rate,
+	}, nil
+}
+
+type TpHolidayCalendars []*TpHolidayCalendar
+
+func (tps TpHolidayCalendars) AsTPHolidayCalendars() (result []*utils.TPHolidayCalendar) {
+	mhc := make(map[string]*utils.TPHolidayCalendar)
+	for _, tp := range tps {
+		hc, found := mhc[tp.Tag]
+		if !found {
+			hc = &utils.TPHolidayCalendar{TPid: tp.Tpid, ID: tp.Tag}
+			mhc[tp.Tag] = hc
+			result = append(result, hc)
+		}
+		hc.Holidays = append(hc.Holidays, &utils.TPHoliday{Date: tp.Date, WeekDay: tp.WeekDay})
+	}
+	return
+}
+
+func APItoModelHolidayCalendar(hc *utils.TPHolidayCalendar) (result TpHolidayCalendars) {
+	for _, hd := range hc.Holidays {
+		result = append(result, &TpHolidayCalendar{
+			Tpid:    hc.TPid,
+			Tag:     hc.ID,
+			Date:    hd.Date,
+			WeekDay: hd.WeekDay,
+		})
+	}
+	return
+}
+
+func APItoHolidayCalendar(tpHC *utils.TPHolidayCalendar) (*HolidayCalendar, error) {
+	hc := &HolidayCalendar{ID: tpHC.ID, Holidays: make(map[string]time.Weekday, len(tpHC.Holidays))}
+	for _, hd := range tpHC.Holidays {
+		layout := holidayDateLayout
+		if len(hd.Date) == len(holidayRecurringLayout) {
+			layout = holidayRecurringLayout
+		}
+		if _, err := time.Parse(layout, hd.Date); err != nil {
+			return nil, fmt.Errorf("invalid date <%s> for holiday calendar: %s", hd.Date, tpHC.ID)
+		}
+		wd := time.Sunday
+		if hd.WeekDay != "" {
+			day, err := strconv.Atoi(hd.WeekDay)
+			if err != nil || day < 0 || day > 7 {
+				return nil, fmt.Errorf("invalid week day <%s> for holiday calendar: %s", hd.WeekDay, tpHC.ID)
+			}
+			wd = time.Weekday(day % 7) // %7 for sunday = 7 normalization
+		}
+		hc.Holidays[hd.Date] = wd
+	}
+	return hc, nil
+}
+
+type TpDialPlans []*TpDialPlan
+
+func (tps TpDialPlans) AsTPDialPlans() (result []*utils.TPDialPlan) {
+	mdp := make(map[string]*utils.TPDialPlan)
+	for _, tp := range tps {
+		dp, found := mdp[tp.Tenant]
+		if !found {
+			dp = &utils.TPDialPlan{TPid: tp.Tpid, Tenant: tp.Tenant}
+			mdp[tp.Tenant] = dp
+			result = append(result, dp)
+		}
+		dp.Rules = append(dp.Rules, &utils.TPDialPlanRule{Prefix: tp.Prefix, MinLength: tp.MinLength,
+			MaxLength: tp.MaxLength, Strip: tp.Strip, Add: tp.Add, Weight: tp.Weight})
+	}
+	return
+}
+
+func APItoModelDialPlan(dp *utils.TPDialPlan) (result TpDialPlans) {
+	for _, dpr := range dp.Rules {
+		result = append(result, &TpDialPlan{
+			Tpid:      dp.TPid,
+			Tenant:    dp.Tenant,
+			Prefix:    dpr.Prefix,
+			MinLength: dpr.MinLength,
+			MaxLength: dpr.MaxLength,
+			Strip:     dpr.Strip,
+			Add:       dpr.Add,
+			Weight:    dpr.Weight,
+		})
+	}
+	return
+}
+
+func APItoDialPlan(tpDP *utils.TPDialPlan) (*DialPlan, error) {
+	dp := &DialPlan{Tenant: tpDP.Tenant, Rules: make([]*DialPlanRule, len(tpDP.Rules))}
+	for i, tpDPR := range tpDP.Rules {
+		if tpDPR.Strip < 0 || tpDPR.MinLength < 0 || tpDPR.MaxLength < 0 ||
+			(tpDPR.MaxLength != 0 && tpDPR.MaxLength < tpDPR.MinLength) {
+			return nil, fmt.Errorf("invalid rule with prefix <%s> for dial plan: %s", tpDPR.Prefix, tpDP.Tenant)
+		}
+		dp.Rules[i] = &DialPlanRule{
+			Prefix:    tpDPR.Prefix,
+			MinLength: tpDPR.MinLength,
+			MaxLength: tpDPR.MaxLength,
+			Strip:     tpDPR.Strip,
+			Add:       tpDPR.Add,
+			Weight:    tpDPR.Weight,
+		}
+	}
+	// higher weight first, longer prefix first on equal weights
+	sort.SliceStable(dp.Rules, func(i, j int) bool {
+		if dp.Rules[i].Weight != dp.Rules[j].Weight {
+			return dp.Rules[i].Weight > dp.Rules[j].Weight
+		}
+		return len(dp.Rules[i].Prefix) > len(dp.Rules[j].Prefix)
+	})
+	return dp, nil
+}
@@ -25,7 +25,7 @@ import (
 )
 
 func TestModelHelperCsvLoad(t *testing.T) {
-	l, err := csvLoad(TpDestination{}, []string{"TEST_DEST", "+492"})
+	l, err := csvLoad(TpDestination{}, []string{"TEST_DEST", "+492", ""})
 	tpd, ok := l.(TpDestination)
 	if err != nil || !ok || tpd.Tag != "TEST_DEST" || tpd.Prefix != "+492" {
 		t.Errorf("model load failed: %+v", tpd)
@@ -57,9 +57,9 @@ func TestTPDestinationAsExportSlice(t *testing.T) {
 		Prefixes: []string{"49", "49176", "49151"},
 	}
 	expectedSlc := [][]string{
-		[]string{"TEST_DEST", "49"},
-		[]string{"TEST_DEST", "49176"},
-		[]string{"TEST_DEST", "49151"},
+		[]string{"TEST_DEST", "49", ""},
+		[]string{"TEST_DEST", "49176", ""},
+		[]string{"TEST_DEST", "49151", ""},
 	}
 	mdst := APItoModelDestination(tpDst)
 	var slc [][]string
@@ -79,11 +79,19 @@ func TestTpDestinationsAsTPDestinations(t *testing.T) {
 	tpd1 := TpDestination{Tpid: "TEST_TPID", Tag: "TEST_DEST", Prefix: "+491"}
 	tpd2 := TpDestination{Tpid: "TEST_TPID", Tag: "TEST_DEST", Prefix: "+492"}
 	tpd3 := TpDestination{Tpid: "TEST_TPID", Tag: "TEST_DEST", Prefix: "+493"}
+	tpd4 := TpDestination{Tpid: "TEST_TPID", Tag: "TEST_DEST", Prefix: "10.10.1.1", Type: utils.MetaIP}
+	tpd5 := TpDestination{Tpid: "TEST_TPID", Tag: "TEST_DEST", Prefix: "2001:db8:1::1/48", Type: utils.MetaIP}
 	eTPDestinations := []*utils.TPDestination{&utils.TPDestination{TPid: "TEST_TPID", ID: "TEST_DEST",
-		Prefixes: []string{"+491", "+492", "+493"}}}
-	if tpDst := TpDestinations([]TpDestination{tpd1, tpd2, tpd3}).AsTPDestinations(); !reflect.DeepEqual(eTPDestinations, tpDst) {
+		Prefixes: []string{"+491", "+492", "+493", "10.10.1.1/32", "2001:db8:1::/48"}}}
+	if tpDst, err := TpDestinations([]TpDestination{tpd1, tpd2, tpd3, tpd4, tpd5}).AsTPDestinations(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eTPDestinations, tpDst) {
 		t.Errorf("Expecting: %+v, received: %+v", eTPDestinations, tpDst)
 	}
+	tpd4.Prefix = "10.10.1"
+	if _, err := TpDestinations([]TpDestination{tpd1, tpd4}).AsTPDestinations(); err == nil {
+		t.Error("Expecting error on invalid IP prefix")
+	}
 
 }
 
@@ -241,8 +249,8 @@ func TestTPRatingProfileAsExportSlice(t *testing.T) {
 		},
 	}
 	expectedSlc := [][]string{
-		[]string{utils.OUT, "cgrates.org", "call", "*any", "2014-01-14T00:00:00Z", "TEST_RPLAN1", "subj1;subj2", ""},
-		[]string{utils.OUT, "cgrates.org", "call", "*any", "2014-01-15T00:00:00Z", "TEST_RPLAN2", "subj1;subj2", ""},
+		[]string{utils.OUT, "cgrates.org", "call", "*any", "2014-01-14T00:00:00Z", "TEST_RPLAN1", "subj1;subj2", "", "", "0", "", "0"},
+		[]string{utils.OUT, "cgrates.org", "call", "*any", "2014-01-15T00:00:00Z", "TEST_RPLAN2", "subj1;subj2", "", "", "0", "", "0"},
 	}
 
 	ms := APItoModelRatingProfile(tpRpf)
@@ -574,6 +582,29 @@ func TestTPActionTriggersAsExportSlice(t *testing.T) {
 	}
 }
 
+func TestTpActionPlansCatchUp(t *testing.T) {
+	tps := TpActionPlans{
+		TpActionPlan{Tpid: "TEST_TP", Tag: "MONTHLY_TOPUP", ActionsTag: "TOPUP_10", TimingTag: "MONTHLY;*run_all_missed", Weight: 10},
+	}
+	eAtms := []*utils.TPActionTiming{
+		&utils.TPActionTiming{ActionsId: "TOPUP_10", TimingId: "MONTHLY", Weight: 10, CatchUp: utils.MetaRunAllMissed},
+	}
+	aps, err := tps.AsMapTPActionPlans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(eAtms, aps["MONTHLY_TOPUP"].ActionPlan) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eAtms), utils.ToJSON(aps["MONTHLY_TOPUP"].ActionPlan))
+	}
+	if rcv := APItoModelActionPlan(aps["MONTHLY_TOPUP"]); !reflect.DeepEqual(tps, rcv) {
+		t.Errorf("Expecting: %+v, received: %+v", tps, rcv)
+	}
+	tps[0].TimingTag = "MONTHLY;*unsupported"
+	if _, err := tps.AsMapTPActionPlans(); err == nil {
+		t.Error("Expecting error for unsupported catch up policy")
+	}
+}
+
 func TestTPActionPlanAsExportSlice(t *testing.T) {
 	at := &utils.TPActionTriggers{
 		TPid: "TEST_TPID",
@@ -45,6 +45,7 @@ type TpDestination struct {
 	Tpid      string
 	Tag       string `index:"0" re:"\w+\s*,\s*"`
 	Prefix    string `index:"1" re:"\+?\d+.?\d*"`
+	Type      string `index:"2" re:""` // *prefix(default) or *ip for IP addresses and CIDR networks
 	CreatedAt time.Time
 }
 
@@ -66,10 +67,10 @@ type TpDestinationRate struct {
 	Tag              string  `index:"0" re:"\w+\s*"`
 	DestinationsTag  string  `index:"1" re:"\w+\s*|\*any"`
 	RatesTag         string  `index:"2" re:"\w+\s*"`
-	RoundingMethod   string  `index:"3" re:"\*up|\*down|\*middle"`
+	RoundingMethod   string  `index:"3" re:"\*up|\*down|\*middle|\*half_even|\*truncate"`
 	RoundingDecimals int     `index:"4" re:"\d+"`
 	MaxCost          float64 `index:"5" re:"\d+\.*\d*s*"`
-	MaxCostStrategy  string  `index:"6" re:"\*free|\*disconnect"`
+	MaxCostStrategy  string  `index:"6" re:"\*free|\*disconnect|\*cap"`
 	CreatedAt        time.Time
 }
 
@@ -87,14 +88,18 @@ type TpRatingProfile struct {
 	Id               int64
 	Tpid             string
 	Loadid           string
-	Direction        string `index:"0" re:"\*out\s*"`
-	Tenant           string `index:"1" re:"[0-9A-Za-z_\.]+\s*"`
-	Category         string `index:"2" re:"\w+\s*"`
-	Subject          string `index:"3" re:"\*any\s*|(\w+;?)+\s*"`
-	ActivationTime   string `index:"4" re:"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z"`
-	RatingPlanTag    string `index:"5" re:"\w+\s*"`
-	FallbackSubjects string `index:"6" re:"\w+\s*"`
-	CdrStatQueueIds  string `index:"7" re:"\w+\s*"`
+	Direction        string  `index:"0" re:"\*out\s*"`
+	Tenant           string  `index:"1" re:"[0-9A-Za-z_\.]+\s*"`
+	Category         string  `index:"2" re:"\w+\s*"`
+	Subject          string  `index:"3" re:"\*any\s*|(\w+\*?;?)+\s*"`
+	ActivationTime   string  `index:"4" re:"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z"`
+	RatingPlanTag    string  `index:"5" re:"\w+\s*"`
+	FallbackSubjects string  `index:"6" re:"\w+\s*"`
+	CdrStatQueueIds  string  `index:"7" re:"\w+\s*"`
+	DeactivationTime string  `index:"8" re:""` // optional, empty for never
+	MaxCost          float64 `index:"9" re:""` // optional per call cap
+	MaxCostStrategy  string  `index:"10" re:""`
+	TrafficShare     float64 `index:"11" re:""` // optional percentage of the accounts rated with this activation
 	CreatedAt        time.Time
 }
 
@@ -420,6 +425,7 @@ type TBLCDRs struct {
 	DisconnectCause string
 	ExtraFields     string
 	Cost            float64
+	ShadowCost      float64
 	CostDetails     string
 	CostSource      string
 	AccountSummary  string
@@ -466,6 +472,37 @@ type TpResourceLimit struct {
 	CreatedAt          time.Time
 }
 
+type TpExchangeRate struct {
+	ID           int64
+	Tpid         string
+	FromCurrency string  `index:"0" re:"^[A-Z]{3}$"`
+	ToCurrency   string  `index:"1" re:"^[A-Z]{3}$"`
+	Rate         float64 `index:"2" re:"\d+\.?\d*"`
+	CreatedAt    time.Time
+}
+
+type TpHolidayCalendar struct {
+	ID        int64
+	Tpid      string
+	Tag       string `index:"0" re:""`
+	Date      string `index:"1" re:"^([0-9]{4}-)?[0-9]{2}-[0-9]{2}$"`
+	WeekDay   string `index:"2" re:"^[0-7]?$"`
+	CreatedAt time.Time
+}
+
+type TpDialPlan struct {
+	ID        int64
+	Tpid      string
+	Tenant    string  `index:"0" re:""`
+	Prefix    string  `index:"1" re:"^[+]?[0-9]*$"`
+	MinLength int     `index:"2" re:""`
+	MaxLength int     `index:"3" re:""`
+	Strip     int     `index:"4" re:""`
+	Add       string  `index:"5" re:"^[0-9]*$"`
+	Weight    float64 `index:"6" re:""`
+	CreatedAt time.Time
+}
+
 type TBLVersion struct {
 	ID      uint
 	Item    string
@@ -0,0 +1,264 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+const notifyTimeout = 30 * time.Second // for each delivery attempt of a *mail or *sms action
+
+var (
+	notifiers   = make(map[string]Notifier)
+	notifiersMu sync.RWMutex
+)
+
+// Notifier delivers the messages of the *mail and *sms actions, executing the template with data
+type Notifier interface {
+	Send(ctx context.Context, channel, recipient, template string, data interface{}) error
+}
+
+// SetNotifier sets the Notifier delivering the messages of a channel <*mail|*sms>, nil disables the channel
+func SetNotifier(channel string, n Notifier) {
+	notifiersMu.Lock()
+	defer notifiersMu.Unlock()
+	if n == nil {
+		delete(notifiers, channel)
+		return
+	}
+	notifiers[channel] = n
+}
+
+func getNotifier(channel string) (Notifier, error) {
+	notifiersMu.RLock()
+	defer notifiersMu.RUnlock()
+	n, has := notifiers[channel]
+	if !has {
+		return nil, utils.ErrNotifierNotConfigured
+	}
+	return n, nil
+}
+
+// defaultNotifyTemplates are used by the *mail and *sms actions with no template in ExtraParameters
+var defaultNotifyTemplates = map[string]string{
+	utils.MetaMail: "Subject: [CGR Notification] Threshold hit on << if .Account >>account << .Account.ID >><< else >>StatsQueueId << .Sq.Id >><< end >>\n\n" +
+		"<< if .Account >>Balances: << json .Balances >><< else >>Metrics: << json .Sq.Metrics >><< end >>\n\nYours faithfully,\nCGR Monitor\n",
+	utils.SMS: "Threshold hit on << if .Account >><< .Account.ID >>, balances: << json .Balances >><< else >><< .Sq.Id >><< end >>",
+}
+
+/*
+*mail and *sms ExtraParameters are the recipients separated by ; followed, after the first comma, by the template
+executed with the same data as the one of *http_post, eg:
+
++4912345678;+4912345679,Your credit is low: << printf "%.2f" (index .Balances "*monetary") >> EUR
+
+For *mail, a first template line starting with Subject: sets the subject of the email.
+*/
+func notifyAction(channel string) actionTypeFunc {
+	return func(acc *Account, sq *StatsQueueTriggered, a *Action, acs Actions) error {
+		n, err := getNotifier(channel)
+		if err != nil {
+			return err
+		}
+		params := strings.SplitN(a.ExtraParameters, string(utils.CSV_SEP), 2)
+		if params[0] == "" {
+			return errors.New("missing recipients")
+		}
+		tmpl := defaultNotifyTemplates[channel]
+		if len(params) == 2 && params[1] != "" {
+			tmpl = params[1]
+		}
+		if _, err := parseActionTemplate(channel, tmpl); err != nil {
+			return err
+		}
+		if acc != nil { // the account is modified by the next actions while we deliver
+			acc = acc.Clone()
+		}
+		data := newActionTemplateData(acc, sq, a, acs)
+		attempts := config.CgrConfig().PosterAttempts
+		for _, recipient := range strings.Split(params[0], string(utils.FALLBACK_SEP)) {
+			go func(recipient string) {
+				var err error
+				for i := 0; i < attempts; i++ {
+					if i != 0 {
+						time.Sleep(time.Duration(i) * time.Second)
+					}
+					ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+					err = n.Send(ctx, channel, recipient, tmpl, data)
+					cancel()
+					if err == nil {
+						return
+					}
+				}
+				utils.Logger.Warning(fmt.Sprintf("<%s> Failed notifying %s after %d attempts, error: %s", channel, recipient, attempts, err.Error()))
+			}(recipient)
+		}
+		return nil
+	}
+}
+
+// renderNotification executes the template of a notification
+func renderNotification(tmpl string, data interface{}) (string, error) {
+	t, err := parseActionTemplate("notification", tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// NewSMTPNotifier creates the Notifier sending emails through the server, using STARTTLS and authenticating when supported
+func NewSMTPNotifier(server, authUser, authPass, fromAddr string) *SMTPNotifier {
+	return &SMTPNotifier{server: server, authUser: authUser, authPass: authPass, fromAddr: fromAddr}
+}
+
+// SMTPNotifier is the Notifier of the *mail actions
+type SMTPNotifier struct {
+	server, authUser, authPass, fromAddr string
+}
+
+func (sn *SMTPNotifier) Send(ctx context.Context, channel, recipient, tmpl string, data interface{}) error {
+	msg, err := renderNotification(tmpl, data)
+	if err != nil {
+		return err
+	}
+	subject := "[CGR Notification]"
+	if strings.HasPrefix(msg, "Subject:") {
+		idx := strings.Index(msg, "\n")
+		if idx == -1 {
+			idx = len(msg)
+		}
+		subject, msg = strings.TrimSpace(msg[len("Subject:"):idx]), strings.TrimLeft(msg[idx:], "\r\n")
+	}
+	addr := sn.server
+	if _, _, err := net.SplitHostPort(addr); err != nil { // no port configured
+		addr = net.JoinHostPort(addr, "25")
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, has := ctx.Deadline(); has {
+		conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok && sn.authUser != "" {
+		if err := c.Auth(smtp.PlainAuth("", sn.authUser, sn.authPass, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(sn.fromAddr); err != nil {
+		return err
+	}
+	if err := c.Rcpt(recipient); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		sn.fromAddr, recipient, subject, time.Now().Format(time.RFC1123Z), msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// NewHTTPSMSNotifier creates the Notifier posting the SMS messages as JSON towards an HTTP gateway
+func NewHTTPSMSNotifier(url, authUser, authPass, fromAddr string, skipTLSVerify bool, replyTimeout time.Duration) *HTTPSMSNotifier {
+	return &HTTPSMSNotifier{url: url, authUser: authUser, authPass: authPass, fromAddr: fromAddr,
+		client: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: skipTLSVerify}},
+			Timeout:   replyTimeout}}
+}
+
+// HTTPSMSNotifier is the Notifier of the *sms actions delivered through an HTTP gateway
+type HTTPSMSNotifier struct {
+	url, authUser, authPass, fromAddr string
+	client                            *http.Client
+}
+
+// HTTPSMS is the body posted towards the gateway
+type HTTPSMS struct {
+	From string
+	To   string
+	Text string
+}
+
+func (hn *HTTPSMSNotifier) Send(ctx context.Context, channel, recipient, tmpl string, data interface{}) error {
+	text, err := renderNotification(tmpl, data)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(&HTTPSMS{From: hn.fromAddr, To: recipient, Text: text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, hn.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if hn.authUser != "" {
+		req.SetBasicAuth(hn.authUser, hn.authPass)
+	}
+	resp, err := hn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body) // so the connection can be reused
+	if resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	return nil
+}
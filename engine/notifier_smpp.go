@@ -0,0 +1,191 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"unicode/utf16"
+)
+
+// SMPP v3.4 command IDs, the responses have the high bit set
+const (
+	smppGenericNack     uint32 = 0x80000000
+	smppBindTransmitter uint32 = 0x00000002
+	smppSubmitSm        uint32 = 0x00000004
+	smppUnbind          uint32 = 0x00000006
+	smppEnquireLink     uint32 = 0x00000015
+	smppRespMask        uint32 = 0x80000000
+
+	smppMaxShortMessage = 254    // longer messages go into the message_payload TLV
+	smppMessagePayload  = 0x0424 // message_payload TLV tag
+	smppDataCodingUCS2  = 0x08
+)
+
+// NewSMPPNotifier creates the Notifier submitting the SMS messages to an SMSC, binding as transmitter for each message
+func NewSMPPNotifier(address, systemID, password, sourceAddr string) *SMPPNotifier {
+	return &SMPPNotifier{address: address, systemID: systemID, password: password, sourceAddr: sourceAddr}
+}
+
+// SMPPNotifier is the Notifier of the *sms actions delivered over SMPP
+type SMPPNotifier struct {
+	address, systemID, password, sourceAddr string
+}
+
+func (sn *SMPPNotifier) Send(ctx context.Context, channel, recipient, tmpl string, data interface{}) error {
+	text, err := renderNotification(tmpl, data)
+	if err != nil {
+		return err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", sn.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, has := ctx.Deadline(); has {
+		conn.SetDeadline(deadline)
+	}
+	s := &smppSession{conn: conn}
+	var bind bytes.Buffer
+	smppWriteCString(&bind, sn.systemID)
+	smppWriteCString(&bind, sn.password)
+	smppWriteCString(&bind, "")    // system_type
+	bind.Write([]byte{0x34, 0, 0}) // interface_version 3.4, addr_ton, addr_npi
+	smppWriteCString(&bind, "")    // address_range
+	if err := s.call(smppBindTransmitter, bind.Bytes()); err != nil {
+		return err
+	}
+	if err := s.call(smppSubmitSm, smppSubmitSmBody(sn.sourceAddr, recipient, text)); err != nil {
+		return err
+	}
+	s.call(smppUnbind, nil) // the message was accepted, nothing to do on errors
+	return nil
+}
+
+func smppSubmitSmBody(source, destination, text string) []byte {
+	var b bytes.Buffer
+	smppWriteCString(&b, "") // service_type
+	b.Write(smppAddrTonNpi(source))
+	smppWriteCString(&b, strings.TrimPrefix(source, "+"))
+	b.Write(smppAddrTonNpi(destination))
+	smppWriteCString(&b, strings.TrimPrefix(destination, "+"))
+	b.Write([]byte{0, 0, 0}) // esm_class, protocol_id, priority_flag
+	smppWriteCString(&b, "") // schedule_delivery_time
+	smppWriteCString(&b, "") // validity_period
+	b.Write([]byte{0, 0})    // registered_delivery, replace_if_present_flag
+	msg, dataCoding := []byte(text), byte(0)
+	for _, r := range text {
+		if r > 0x7f { // beyond what the SMSC default alphabet safely carries
+			u := utf16.Encode([]rune(text))
+			msg = make([]byte, 2*len(u))
+			for i, c := range u {
+				binary.BigEndian.PutUint16(msg[2*i:], c)
+			}
+			dataCoding = smppDataCodingUCS2
+			break
+		}
+	}
+	b.Write([]byte{dataCoding, 0}) // data_coding, sm_default_msg_id
+	if len(msg) <= smppMaxShortMessage {
+		b.WriteByte(byte(len(msg)))
+		b.Write(msg)
+		return b.Bytes()
+	}
+	b.WriteByte(0) // sm_length, the message goes into message_payload
+	binary.Write(&b, binary.BigEndian, uint16(smppMessagePayload))
+	binary.Write(&b, binary.BigEndian, uint16(len(msg)))
+	b.Write(msg)
+	return b.Bytes()
+}
+
+// smppAddrTonNpi returns international/ISDN for numbers, alphanumeric/unknown for the rest
+func smppAddrTonNpi(addr string) []byte {
+	for _, r := range strings.TrimPrefix(addr, "+") {
+		if r < '0' || r > '9' {
+			return []byte{0x05, 0x00}
+		}
+	}
+	return []byte{0x01, 0x01}
+}
+
+func smppWriteCString(b *bytes.Buffer, s string) {
+	b.WriteString(s)
+	b.WriteByte(0)
+}
+
+// smppSession is a transmitter connection towards the SMSC
+type smppSession struct {
+	conn   net.Conn
+	seqNum uint32
+}
+
+// call sends one command and waits for its response, answering the enquire_link received meanwhile
+func (s *smppSession) call(cmdID uint32, body []byte) error {
+	s.seqNum++
+	if err := s.writePDU(cmdID, 0, s.seqNum, body); err != nil {
+		return err
+	}
+	for {
+		var hdr [16]byte
+		if _, err := io.ReadFull(s.conn, hdr[:]); err != nil {
+			return err
+		}
+		length := binary.BigEndian.Uint32(hdr[0:])
+		respID := binary.BigEndian.Uint32(hdr[4:])
+		status := binary.BigEndian.Uint32(hdr[8:])
+		seqNum := binary.BigEndian.Uint32(hdr[12:])
+		if length < 16 {
+			return fmt.Errorf("invalid SMPP PDU length: %d", length)
+		}
+		if _, err := io.CopyN(ioutil.Discard, s.conn, int64(length-16)); err != nil {
+			return err
+		}
+		switch {
+		case respID == smppEnquireLink:
+			if err := s.writePDU(smppEnquireLink|smppRespMask, 0, seqNum, nil); err != nil {
+				return err
+			}
+		case seqNum != s.seqNum:
+			continue // late response of something else
+		case respID == smppGenericNack || respID != cmdID|smppRespMask:
+			return fmt.Errorf("SMPP command 0x%08x answered with 0x%08x, status: 0x%08x", cmdID, respID, status)
+		case status != 0:
+			return fmt.Errorf("SMPP command 0x%08x failed with status: 0x%08x", cmdID, status)
+		default:
+			return nil
+		}
+	}
+}
+
+func (s *smppSession) writePDU(cmdID, status, seqNum uint32, body []byte) error {
+	pdu := make([]byte, 16+len(body))
+	binary.BigEndian.PutUint32(pdu[0:], uint32(len(pdu)))
+	binary.BigEndian.PutUint32(pdu[4:], cmdID)
+	binary.BigEndian.PutUint32(pdu[8:], status)
+	binary.BigEndian.PutUint32(pdu[12:], seqNum)
+	copy(pdu[16:], body)
+	_, err := s.conn.Write(pdu)
+	return err
+}
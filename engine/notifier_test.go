@@ -0,0 +1,238 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+type testNotification struct {
+	channel, recipient, text string
+}
+
+type testNotifier chan *testNotification
+
+func (tn testNotifier) Send(ctx context.Context, channel, recipient, tmpl string, data interface{}) error {
+	text, err := renderNotification(tmpl, data)
+	if err != nil {
+		return err
+	}
+	tn <- &testNotification{channel: channel, recipient: recipient, text: text}
+	return nil
+}
+
+func TestNotifyAction(t *testing.T) {
+	acc := &Account{ID: "cgrates.org:notify", BalanceMap: map[string]Balances{
+		utils.MONETARY: Balances{&Balance{Value: 1.5}, &Balance{Value: 2}}}}
+	a := &Action{ActionType: SEND_SMS, ExtraParameters: `+4912345;+4967890,Credit of << .Account.ID >>: << printf "%.2f" (index .Balances "*monetary") >>`}
+	actionFunc, _ := getActionFunc(SEND_SMS)
+	SetNotifier(utils.SMS, nil)
+	if err := actionFunc(acc, nil, a, nil); err != utils.ErrNotifierNotConfigured {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotifierNotConfigured, err)
+	}
+	tn := make(testNotifier, 2)
+	SetNotifier(utils.SMS, tn)
+	defer SetNotifier(utils.SMS, nil)
+	if err := actionFunc(acc, nil, a, nil); err != nil {
+		t.Fatal(err)
+	}
+	received := make(map[string]string)
+	for i := 0; i < 2; i++ {
+		select {
+		case n := <-tn:
+			if n.channel != utils.SMS {
+				t.Errorf("Unexpected channel: %s", n.channel)
+			}
+			received[n.recipient] = n.text
+		case <-time.After(time.Second):
+			t.Fatal("notification not sent")
+		}
+	}
+	if eReceived := map[string]string{"+4912345": "Credit of cgrates.org:notify: 3.50", "+4967890": "Credit of cgrates.org:notify: 3.50"}; !reflect.DeepEqual(eReceived, received) {
+		t.Errorf("Expecting: %v, received: %v", eReceived, received)
+	}
+	a.ExtraParameters = "+4912345" // default template
+	if err := actionFunc(acc, nil, a, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case n := <-tn:
+		if n.text != `Threshold hit on cgrates.org:notify, balances: {"*monetary":3.5}` {
+			t.Errorf("Unexpected text: %s", n.text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notification not sent")
+	}
+	a.ExtraParameters = "+4912345,<< .Account.ID"
+	if err := actionFunc(acc, nil, a, nil); err == nil {
+		t.Error("Expecting template error")
+	}
+}
+
+func TestHTTPSMSNotifier(t *testing.T) {
+	var received HTTPSMS
+	var user, pass string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ = r.BasicAuth()
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+	hn := NewHTTPSMSNotifier(ts.URL, "cgr", "secret", "CGRateS", false, time.Second)
+	if err := hn.Send(context.Background(), utils.SMS, "+4912345", "Hello << .ID >>", &Account{ID: "cgrates.org:1001"}); err != nil {
+		t.Fatal(err)
+	}
+	if eReceived := (HTTPSMS{From: "CGRateS", To: "+4912345", Text: "Hello cgrates.org:1001"}); received != eReceived || user != "cgr" || pass != "secret" {
+		t.Errorf("Unexpected request: %+v, auth: %s:%s", received, user, pass)
+	}
+}
+
+func TestSMPPNotifier(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	cmds := make(chan uint32, 3)
+	bodies := make(chan []byte, 3)
+	go func() { // minimal SMSC
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var hdr [16]byte
+			if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+				return
+			}
+			body := make([]byte, binary.BigEndian.Uint32(hdr[0:])-16)
+			if _, err := io.ReadFull(conn, body); err != nil {
+				return
+			}
+			cmdID := binary.BigEndian.Uint32(hdr[4:])
+			cmds <- cmdID
+			bodies <- body
+			binary.BigEndian.PutUint32(hdr[0:], 16)
+			binary.BigEndian.PutUint32(hdr[4:], cmdID|smppRespMask)
+			conn.Write(hdr[:])
+		}
+	}()
+	sn := NewSMPPNotifier(l.Addr().String(), "cgr", "secret", "CGRateS")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sn.Send(ctx, utils.SMS, "+4912345", "Hello << .ID >>", &Account{ID: "1001"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, eCmd := range []uint32{smppBindTransmitter, smppSubmitSm, smppUnbind} {
+		if cmd := <-cmds; cmd != eCmd {
+			t.Errorf("Expecting command: 0x%08x, received: 0x%08x", eCmd, cmd)
+		}
+		body := <-bodies
+		switch eCmd {
+		case smppBindTransmitter:
+			if !strings.HasPrefix(string(body), "cgr\x00secret\x00") {
+				t.Errorf("Unexpected bind: %q", body)
+			}
+		case smppSubmitSm:
+			if eBody := smppSubmitSmBody("CGRateS", "+4912345", "Hello 1001"); !reflect.DeepEqual(eBody, body) {
+				t.Errorf("Expecting: %q, received: %q", eBody, body)
+			}
+			if !strings.Contains(string(body), "\x01\x014912345\x00") || !strings.HasSuffix(string(body), "\x0aHello 1001") {
+				t.Errorf("Unexpected submit_sm: %q", body)
+			}
+		}
+	}
+	if body := smppSubmitSmBody("CGRateS", "+4912345", "Grüße"); !strings.Contains(string(body), "\x08\x00\x0a\x00G\x00r\x00\xfc\x00\xdf\x00e") {
+		t.Errorf("Unexpected UCS2 submit_sm: %q", body)
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := make(chan []string, 1)
+	go func() { // minimal SMTP server, no STARTTLS or AUTH
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var lines []string
+		inData := false
+		io.WriteString(conn, "220 localhost ESMTP\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if inData {
+				if line == "." {
+					inData = false
+					io.WriteString(conn, "250 OK\r\n")
+				} else {
+					lines = append(lines, line)
+				}
+				continue
+			}
+			switch strings.ToUpper(strings.SplitN(line, " ", 2)[0]) {
+			case "EHLO":
+				io.WriteString(conn, "250-localhost\r\n250 8BITMIME\r\n")
+			case "DATA":
+				inData = true
+				io.WriteString(conn, "354 Go ahead\r\n")
+			case "QUIT":
+				io.WriteString(conn, "221 Bye\r\n")
+				received <- lines
+				return
+			default:
+				lines = append(lines, line)
+				io.WriteString(conn, "250 OK\r\n")
+			}
+		}
+	}()
+	sn := NewSMTPNotifier(l.Addr().String(), "", "", "cgr@localhost")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sn.Send(ctx, utils.MetaMail, "ops@localhost", "Subject: Low credit on << .ID >>\n\nCredit left\n", &Account{ID: "1001"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Join(<-received, "\n")
+	for _, eLine := range []string{"MAIL FROM:<cgr@localhost>", "RCPT TO:<ops@localhost>", "To: ops@localhost", "Subject: Low credit on 1001", "\n\nCredit left"} {
+		if !strings.Contains(lines, eLine) {
+			t.Errorf("Missing %q in: %s", eLine, lines)
+		}
+	}
+}
@@ -45,7 +45,9 @@ type RITiming struct {
 	WeekDays           utils.WeekDays
 	StartTime, EndTime string // ##:##:## format
 	cronString         string
-	tag                string // loading validation only
+	ID                 string   // timing tag in the tariff plan
+	DSTPolicy          string   // *wall_clock(default), *utc_offset, *first_occurrence or *last_occurrence
+	HolidayCalendars   []string // holidays are matched against WeekDays as the week day configured in the calendar
 }
 
 func (rit *RITiming) CronString() string {
@@ -134,10 +136,10 @@ func (rit *RITiming) getRightMargin(t time.Time) (rigthtTime time.Time) {
 		min, _ = strconv.Atoi(split[1])
 		sec, _ = strconv.Atoi(split[2])
 		//log.Print("RIGHT1: ", time.Date(year, month, day, hour, min, sec, nsec, loc))
-		return time.Date(year, month, day, hour, min, sec, nsec, loc)
+		return rit.resolveDST(year, month, day, hour, min, sec, nsec, loc)
 	}
 	//log.Print("RIGHT2: ", time.Date(year, month, day, hour, min, sec, nsec, loc).Add(time.Second))
-	return time.Date(year, month, day, hour, min, sec, nsec, loc).Add(time.Second)
+	return rit.resolveDST(year, month, day, hour, min, sec, nsec, loc).Add(time.Second)
 }
 
 //Returns a time object that represents the start of the interval realtive to the received time
@@ -152,6 +154,34 @@ func (rit *RITiming) getLeftMargin(t time.Time) (rigthtTime time.Time) {
 		sec, _ = strconv.Atoi(split[2])
 	}
 	//log.Print("LEFT: ", time.Date(year, month, day, hour, min, sec, nsec, loc))
+	return rit.resolveDST(year, month, day, hour, min, sec, nsec, loc)
+}
+
+// resolveDST builds the margin time for the given wall clock according to DSTPolicy
+func (rit *RITiming) resolveDST(year int, month time.Month, day, hour, min, sec, nsec int, loc *time.Location) time.Time {
+	switch rit.DSTPolicy {
+	case utils.MetaUTCOffset: // offset pinned to the one in effect at the start of the day
+		return time.Date(year, month, day, 0, 0, 0, 0, loc).Add(time.Duration(hour)*time.Hour +
+			time.Duration(min)*time.Minute + time.Duration(sec)*time.Second + time.Duration(nsec))
+	case utils.MetaFirstOccurrence, utils.MetaLastOccurrence:
+		t := time.Date(year, month, day, hour, min, sec, nsec, loc)
+		_, offBefore := t.Add(-12 * time.Hour).Zone()
+		_, offAfter := t.Add(12 * time.Hour).Zone()
+		shift := time.Duration(offBefore-offAfter) * time.Second
+		if shift <= 0 { // no duplicated wall clock around t
+			return t
+		}
+		for _, alt := range []time.Time{t.Add(-shift), t.Add(shift)} {
+			if alt.Hour() != hour || alt.Minute() != min || alt.Second() != sec || alt.Day() != day {
+				continue
+			}
+			if (rit.DSTPolicy == utils.MetaFirstOccurrence && alt.Before(t)) ||
+				(rit.DSTPolicy == utils.MetaLastOccurrence && alt.After(t)) {
+				return alt
+			}
+		}
+		return t
+	}
 	return time.Date(year, month, day, hour, min, sec, nsec, loc)
 }
 
@@ -170,7 +200,7 @@ func (rit *RITiming) IsActiveAt(t time.Time) bool {
 		return false
 	}
 	// check for weekdays
-	if len(rit.WeekDays) > 0 && !rit.WeekDays.Contains(t.Weekday()) {
+	if len(rit.WeekDays) > 0 && !rit.WeekDays.Contains(rit.weekDay(t)) {
 		return false
 	}
 	//log.Print("Time: ", t)
@@ -189,6 +219,15 @@ func (rit *RITiming) IsActiveAt(t time.Time) bool {
 	return true
 }
 
+// weekDay returns the week day of t, replaced by the holiday one when listed in the timing calendars
+func (rit *RITiming) weekDay(t time.Time) time.Weekday {
+	if len(rit.HolidayCalendars) == 0 {
+		return t.Weekday()
+	}
+	wd, _ := holidayWeekDay(rit.HolidayCalendars, t)
+	return wd
+}
+
 // IsActive returns wheter the Timing is active now
 func (rit *RITiming) IsActive() bool {
 	return rit.IsActiveAt(time.Now())
@@ -203,7 +242,23 @@ func (rit *RITiming) IsBlank() bool {
 }
 
 func (rit *RITiming) Stringify() string {
-	return utils.Sha1(fmt.Sprintf("%v", rit))[:8]
+	// hash the layout prior to DSTPolicy so existing tags stay stable
+	legacy := fmt.Sprintf("%v", &struct {
+		Years              utils.Years
+		Months             utils.Months
+		MonthDays          utils.MonthDays
+		WeekDays           utils.WeekDays
+		StartTime, EndTime string
+		cronString         string
+		tag                string
+	}{rit.Years, rit.Months, rit.MonthDays, rit.WeekDays, rit.StartTime, rit.EndTime, rit.cronString, rit.ID})
+	if rit.DSTPolicy != "" && rit.DSTPolicy != utils.MetaWallClock {
+		legacy += utils.CONCATENATED_KEY_SEP + rit.DSTPolicy
+	}
+	if len(rit.HolidayCalendars) != 0 {
+		legacy += utils.CONCATENATED_KEY_SEP + strings.Join(rit.HolidayCalendars, utils.INFIELD_SEP)
+	}
+	return utils.Sha1(legacy)[:8]
 }
 
 // Separate structure used for rating plan size optimization
@@ -213,8 +268,12 @@ type RIRate struct {
 	RoundingDecimals int
 	MaxCost          float64
 	MaxCostStrategy  string
-	Rates            RateGroups // GroupRateInterval (start time): Rate
-	tag              string     // loading validation only
+	Rates            RateGroups    // GroupRateInterval (start time): Rate
+	TierPeriod       string        // when set, GroupIntervalStart counts the usage within this billing period instead of the call
+	Currency         string        // currency of the costs, empty for the one of the balances
+	MinCharge        float64       // least amount billed for the call, itemized as a separate increment
+	MinChargeUsage   time.Duration // the minimum charge applies only to calls shorter than this, 0 for all calls
+	tag              string        // loading validation only
 }
 
 func (rir *RIRate) Stringify() string {
@@ -222,9 +281,23 @@ func (rir *RIRate) Stringify() string {
 	for _, r := range rir.Rates {
 		str += r.Stringify()
 	}
+	if rir.TierPeriod != "" {
+		str += rir.TierPeriod
+	}
+	if rir.Currency != "" {
+		str += rir.Currency
+	}
+	if rir.MinCharge != 0 {
+		str += fmt.Sprintf("%v %v", rir.MinCharge, rir.MinChargeUsage)
+	}
 	return utils.Sha1(str)[:8]
 }
 
+// tierKey indexes the usage counted for the tiered rating
+func (rir *RIRate) tierKey() string {
+	return utils.ConcatenatedKey(rir.TierPeriod, rir.Stringify())
+}
+
 type Rate struct {
 	GroupIntervalStart time.Duration
 	Value              float64
@@ -349,15 +422,31 @@ func (i *RateInterval) Equal(o *RateInterval) bool {
 func (i *RateInterval) GetCost(duration, startSecond time.Duration) float64 {
 	price, _, rateUnit := i.
 		GetRateParameters(startSecond)
-	price /= rateUnit.Seconds()
-	d := duration.Seconds()
-	return d * price
+	if rateUnit <= 0 {
+		return duration.Seconds() * price / rateUnit.Seconds()
+	}
+	cost, err := utils.NewDecimalFromFloat64(price)
+	if err == nil {
+		cost, err = cost.MulDiv(int64(duration), int64(rateUnit))
+	}
+	if err != nil { // out of the fixed-point range
+		return duration.Seconds() * price / rateUnit.Seconds()
+	}
+	return cost.Float64()
 }
 
 // Gets the price for a the provided start second
 func (i *RateInterval) GetRateParameters(startSecond time.Duration) (rate float64, rateIncrement, rateUnit time.Duration) {
+	if price := i.getRate(startSecond); price != nil {
+		return price.Value, price.RateIncrement, price.RateUnit
+	}
+	return -1, -1, -1
+}
+
+// getRate returns the rate group applying at startSecond, nil if none
+func (i *RateInterval) getRate(startSecond time.Duration) *Rate {
 	if i.Rating == nil {
-		return -1, -1, -1
+		return nil
 	}
 	i.Rating.Rates.Sort()
 	for index, price := range i.Rating.Rates {
@@ -369,10 +458,10 @@ func (i *RateInterval) GetRateParameters(startSecond time.Duration) (rate float6
 			if price.RateUnit == 0 {
 				price.RateUnit = 1 * time.Second
 			}
-			return price.Value, price.RateIncrement, price.RateUnit
+			return price
 		}
 	}
-	return -1, -1, -1
+	return nil
 }
 
 func (ri *RateInterval) GetMaxCost() (float64, string) {
@@ -411,14 +500,59 @@ func (il *RateIntervalTimeSorter) Swap(i, j int) {
 	il.ris[i], il.ris[j] = il.ris[j], il.ris[i]
 }
 
-// we need higher weights earlyer in the list
-func (il *RateIntervalTimeSorter) Less(j, i int) bool {
-	if il.ris[i].Weight < il.ris[j].Weight {
-		return il.ris[i].Weight < il.ris[j].Weight
+// we need higher weights earlyer in the list, then the intervals in the order they start
+func (il *RateIntervalTimeSorter) Less(i, j int) bool {
+	less, _ := il.compare(il.ris[i], il.ris[j])
+	return less
+}
+
+// compare decides if ri1 wins over ri2 and by which rule, ties being broken in order by:
+// higher weight, earlier start relative to the reference time, cheaper connect fee plus first rate,
+// timing ID and finally the rating content so the order never depends on the loading one
+func (il *RateIntervalTimeSorter) compare(ri1, ri2 *RateInterval) (less bool, rule string) {
+	if ri1.Weight != ri2.Weight {
+		return ri1.Weight > ri2.Weight, RateIntervalRuleWeight
+	}
+	t1 := ri1.Timing.getLeftMargin(il.referenceTime)
+	t2 := ri2.Timing.getLeftMargin(il.referenceTime)
+	if !t1.Equal(t2) {
+		return t1.Before(t2), RateIntervalRuleStartTime
+	}
+	if p1, p2 := ri1.firstPrice(), ri2.firstPrice(); p1 != p2 {
+		return p1 < p2, RateIntervalRulePrice
+	}
+	if ri1.Timing.ID != ri2.Timing.ID {
+		return ri1.Timing.ID < ri2.Timing.ID, RateIntervalRuleTimingID
+	}
+	return ri1.ratingString() < ri2.ratingString(), RateIntervalRuleRating
+}
+
+// rules deciding between rate intervals matching the same time
+const (
+	RateIntervalRuleWeight    = "*weight"
+	RateIntervalRuleStartTime = "*start_time"
+	RateIntervalRulePrice     = "*price"
+	RateIntervalRuleTimingID  = "*timing_id"
+	RateIntervalRuleRating    = "*rating"
+)
+
+// firstPrice is the connect fee plus the first rate, used to prefer the cheaper of equal intervals
+func (i *RateInterval) firstPrice() (price float64) {
+	if i.Rating == nil {
+		return
+	}
+	price = i.Rating.ConnectFee
+	if len(i.Rating.Rates) != 0 {
+		price += i.Rating.Rates[0].Value
+	}
+	return
+}
+
+func (i *RateInterval) ratingString() string {
+	if i.Rating == nil {
+		return ""
 	}
-	t1 := il.ris[i].Timing.getLeftMargin(il.referenceTime)
-	t2 := il.ris[j].Timing.getLeftMargin(il.referenceTime)
-	return t1.After(t2)
+	return i.Rating.Stringify()
 }
 
 func (il *RateIntervalTimeSorter) Sort() []*RateInterval {
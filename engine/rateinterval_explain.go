@@ -0,0 +1,100 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// RateIntervalExplanation lists the rate intervals matching a time, the one applied first
+type RateIntervalExplanation struct {
+	RatingPlanID  string
+	MatchedPrefix string
+	MatchedDestID string
+	Candidates    []*RateIntervalCandidate
+}
+
+// RateIntervalCandidate is one rate interval matching the explained time
+type RateIntervalCandidate struct {
+	TimingID   string
+	Weight     float64
+	StartTime  time.Time // start of the timing occurrence containing the explained time
+	ConnectFee float64
+	Rate       float64 // first rate of the interval
+	Rule       string  // rule by which the previous candidate wins over this one, empty for the applied interval
+}
+
+// wins decides if ri1 applies instead of ri2 at the reference time and by which rule,
+// same as the rating, out of equal weights the interval started closest to the reference time applies
+func (il *RateIntervalTimeSorter) wins(ri1, ri2 *RateInterval) (bool, string) {
+	less, rule := il.compare(ri1, ri2)
+	if rule == RateIntervalRuleStartTime {
+		return !less, rule
+	}
+	return less, rule
+}
+
+// explainRateIntervals ranks the rate intervals containing the time
+func (ri *RatingInfo) explainRateIntervals(t time.Time) *RateIntervalExplanation {
+	expl := &RateIntervalExplanation{RatingPlanID: ri.RatingPlanId, MatchedPrefix: ri.MatchedPrefix, MatchedDestID: ri.MatchedDestId}
+	sorter := &RateIntervalTimeSorter{referenceTime: t}
+	for _, rIntrvl := range ri.RateIntervals {
+		if rIntrvl.Timing != nil && rIntrvl.Contains(t, false) {
+			sorter.ris = append(sorter.ris, rIntrvl)
+		}
+	}
+	sort.SliceStable(sorter.ris, func(i, j int) bool {
+		wins, _ := sorter.wins(sorter.ris[i], sorter.ris[j])
+		return wins
+	})
+	for idx, rIntrvl := range sorter.ris {
+		cndt := &RateIntervalCandidate{TimingID: rIntrvl.Timing.ID, Weight: rIntrvl.Weight,
+			StartTime: rIntrvl.Timing.getLeftMargin(t)}
+		if rIntrvl.Rating != nil {
+			cndt.ConnectFee = rIntrvl.Rating.ConnectFee
+			if len(rIntrvl.Rating.Rates) != 0 {
+				cndt.Rate = rIntrvl.Rating.Rates[0].Value
+			}
+		}
+		if idx != 0 {
+			_, cndt.Rule = sorter.wins(sorter.ris[idx-1], rIntrvl)
+		}
+		expl.Candidates = append(expl.Candidates, cndt)
+	}
+	return expl
+}
+
+// ExplainRateInterval reports which rate interval applies at TimeStart and why
+func (cd *CallDescriptor) ExplainRateInterval() (*RateIntervalExplanation, error) {
+	if err := cd.LoadRatingPlans(); err != nil {
+		return nil, err
+	}
+	var ri *RatingInfo
+	for _, rInfo := range cd.RatingInfos { // sorted by activation time
+		if !rInfo.ActivationTime.After(cd.TimeStart) {
+			ri = rInfo
+		}
+	}
+	if ri == nil {
+		return nil, utils.ErrNotFound
+	}
+	return ri.explainRateIntervals(cd.TimeStart), nil
+}
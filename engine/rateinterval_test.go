@@ -381,6 +381,10 @@ func TestRateIntervalCost(t *testing.T) {
 	if x != 0.1 {
 		t.Error("expected 0.1 was: ", x)
 	}
+	ri.Rating.Rates[0].Value = 1000000 // out of the fixed-point range
+	if x = ri.GetCost(24*time.Hour, 0); x != 1440000000 {
+		t.Error("expected 1440000000 was: ", x)
+	}
 }
 
 /*********************************Benchmarks**************************************/
@@ -392,3 +396,92 @@ func BenchmarkRateIntervalContainsDate(b *testing.B) {
 		i.Contains(d, false)
 	}
 }
+
+func TestRITimingDSTPolicy(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no timezone data: ", err)
+	}
+	fallBack := time.Date(2017, 11, 5, 12, 0, 0, 0, loc) // 01:00-02:00 occurs twice
+	rit := &RITiming{StartTime: "01:30:00", DSTPolicy: utils.MetaFirstOccurrence}
+	if lm := rit.getLeftMargin(fallBack).UTC(); !lm.Equal(time.Date(2017, 11, 5, 5, 30, 0, 0, time.UTC)) {
+		t.Error("Wrong first occurrence: ", lm)
+	}
+	rit.DSTPolicy = utils.MetaLastOccurrence
+	if lm := rit.getLeftMargin(fallBack).UTC(); !lm.Equal(time.Date(2017, 11, 5, 6, 30, 0, 0, time.UTC)) {
+		t.Error("Wrong last occurrence: ", lm)
+	}
+	rit = &RITiming{StartTime: "08:00:00", EndTime: "10:00:00"}
+	if lm := rit.getLeftMargin(fallBack).UTC(); !lm.Equal(time.Date(2017, 11, 5, 13, 0, 0, 0, time.UTC)) {
+		t.Error("Wrong wall clock margin: ", lm)
+	}
+	wallTag := rit.Stringify()
+	rit.DSTPolicy = utils.MetaUTCOffset
+	if lm := rit.getLeftMargin(fallBack).UTC(); !lm.Equal(time.Date(2017, 11, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Wrong pinned offset margin: ", lm)
+	}
+	if rm := rit.getRightMargin(fallBack).UTC(); !rm.Equal(time.Date(2017, 11, 5, 14, 0, 0, 0, time.UTC)) {
+		t.Error("Wrong pinned offset margin: ", rm)
+	}
+	if rit.Stringify() == wallTag {
+		t.Error("DST policy not part of the timing tag")
+	}
+	rit.DSTPolicy = utils.MetaWallClock
+	if rit.Stringify() != wallTag {
+		t.Error("Default DST policy changed the timing tag")
+	}
+}
+
+func TestRITimingHolidayCalendars(t *testing.T) {
+	if err := dataStorage.SetHolidayCalendar(&HolidayCalendar{ID: "HOL_TEST",
+		Holidays: map[string]time.Weekday{"12-25": time.Sunday, "2017-04-17": time.Saturday}}, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	rit := &RITiming{WeekDays: utils.WeekDays{time.Saturday, time.Sunday}, StartTime: "00:00:00",
+		HolidayCalendars: []string{"HOL_MISSING", "HOL_TEST"}}
+	for tm, active := range map[time.Time]bool{
+		time.Date(2017, 12, 25, 10, 0, 0, 0, time.UTC): true,  // recurring, Monday rated as Sunday
+		time.Date(2018, 12, 25, 10, 0, 0, 0, time.UTC): true,  // recurring on a Tuesday
+		time.Date(2017, 4, 17, 10, 0, 0, 0, time.UTC):  true,  // dated Easter Monday
+		time.Date(2018, 4, 17, 10, 0, 0, 0, time.UTC):  false, // dated holiday not recurring
+		time.Date(2017, 12, 26, 10, 0, 0, 0, time.UTC): false,
+		time.Date(2017, 12, 24, 10, 0, 0, 0, time.UTC): true, // real Sunday
+	} {
+		if rcv := rit.IsActiveAt(tm); rcv != active {
+			t.Errorf("Expecting %v at %v, received: %v", active, tm, rcv)
+		}
+	}
+	plain := &RITiming{WeekDays: utils.WeekDays{time.Saturday, time.Sunday}, StartTime: "00:00:00"}
+	if plain.IsActiveAt(time.Date(2017, 12, 25, 10, 0, 0, 0, time.UTC)) {
+		t.Error("Holiday matched without calendar")
+	}
+	if plain.Stringify() == rit.Stringify() {
+		t.Error("Timings with different holiday calendars share the same tag")
+	}
+}
+
+func TestRateIntervalExplain(t *testing.T) {
+	newRI := func(timingID, startTime string, weight, rate float64) *RateInterval {
+		return &RateInterval{Timing: &RITiming{ID: timingID, StartTime: startTime}, Weight: weight,
+			Rating: &RIRate{Rates: RateGroups{&Rate{Value: rate, RateIncrement: time.Second, RateUnit: time.Second}}}}
+	}
+	ri := &RatingInfo{RatingPlanId: "RP_EXPLAIN", MatchedPrefix: "0256", MatchedDestId: "NAT",
+		RateIntervals: RateIntervalList{newRI("T_ANY", "00:00:00", 10, 0.2), newRI("T_ANY2", "00:00:00", 10, 0.1),
+			newRI("T_DAY", "08:00:00", 20, 0.5), newRI("T_EVE", "12:00:00", 20, 0.6), newRI("T_NIGHT", "20:00:00", 30, 0.05)}}
+	tm := time.Date(2017, 1, 2, 18, 0, 0, 0, time.UTC)
+	expl := ri.explainRateIntervals(tm)
+	if expl.RatingPlanID != "RP_EXPLAIN" || len(expl.Candidates) != 4 {
+		t.Fatalf("Unexpected explanation: %s", utils.ToJSON(expl))
+	}
+	eRules := [][]string{{"T_EVE", ""}, {"T_DAY", RateIntervalRuleStartTime},
+		{"T_ANY2", RateIntervalRuleWeight}, {"T_ANY", RateIntervalRulePrice}}
+	for i, cndt := range expl.Candidates {
+		if cndt.TimingID != eRules[i][0] || cndt.Rule != eRules[i][1] {
+			t.Errorf("Candidate %d expecting: %v, received: %s", i, eRules[i], utils.ToJSON(cndt))
+		}
+	}
+	// the rating applies the explained winner
+	if ris := ri.SelectRatingIntevalsForTimespan(&TimeSpan{TimeStart: tm, TimeEnd: tm.Add(time.Minute)}); ris[0].Timing.ID != "T_EVE" {
+		t.Errorf("Unexpected rate interval applied: %s", utils.ToJSON(ris[0]))
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ratingDataGetter is the source of rating data used when rating a CallDescriptor
+type ratingDataGetter interface {
+	GetRatingPlan(string, bool, string) (*RatingPlan, error)
+	GetRatingProfile(string, bool, string) (*RatingProfile, error)
+	GetReverseDestination(string, bool, string) ([]string, error)
+}
+
+// ratingHistory is the rating data configured at a past moment, rebuilt out of the TPSnapshots taken till then.
+// Each snapshot is valid from its SnapshotTime till the next one, the items never snapshotted till then are read out of dataDB.
+type ratingHistory struct {
+	dataDB         DataDB
+	destinations   map[string]*Destination
+	ratingPlans    map[string]*RatingPlan
+	ratingProfiles map[string]*RatingProfile
+}
+
+func newRatingHistory(dataDB DataDB, asOf time.Time) (rh *ratingHistory, err error) {
+	ids, err := dataDB.GetTPSnapshotIDs()
+	if err != nil {
+		return
+	}
+	sort.Strings(ids) // oldest first so the newer versions override
+	rh = &ratingHistory{
+		dataDB:         dataDB,
+		destinations:   make(map[string]*Destination),
+		ratingPlans:    make(map[string]*RatingPlan),
+		ratingProfiles: make(map[string]*RatingProfile),
+	}
+	for _, id := range ids {
+		tps, err := getCachedTPSnapshot(dataDB, id)
+		if err != nil {
+			return nil, err
+		}
+		if tps.SnapshotTime.After(asOf) {
+			break
+		}
+		for _, d := range tps.Destinations {
+			rh.destinations[d.Id] = d
+		}
+		for _, rp := range tps.RatingPlans {
+			rh.ratingPlans[rp.Id] = rp
+		}
+		for _, rpf := range tps.RatingProfiles {
+			rh.ratingProfiles[rpf.Id] = rpf
+		}
+	}
+	return
+}
+
+// getCachedTPSnapshot caches the snapshots since they are never modified once written
+func getCachedTPSnapshot(dataDB DataDB, id string) (tps *TPSnapshot, err error) {
+	if x, ok := cache.Get(utils.TPSnapshotPrefix + id); ok {
+		return x.(*TPSnapshot), nil
+	}
+	if tps, err = dataDB.GetTPSnapshot(id); err != nil {
+		return
+	}
+	cache.Set(utils.TPSnapshotPrefix+id, tps, true, utils.NonTransactional)
+	return
+}
+
+func (rh *ratingHistory) GetRatingPlan(id string, skipCache bool, transactionID string) (*RatingPlan, error) {
+	if rp, has := rh.ratingPlans[id]; has {
+		return rp, nil
+	}
+	return rh.dataDB.GetRatingPlan(id, skipCache, transactionID)
+}
+
+func (rh *ratingHistory) GetRatingProfile(key string, skipCache bool, transactionID string) (*RatingProfile, error) {
+	if rpf, has := rh.ratingProfiles[key]; has {
+		return rpf, nil
+	}
+	return rh.dataDB.GetRatingProfile(key, skipCache, transactionID)
+}
+
+func (rh *ratingHistory) GetReverseDestination(prefix string, skipCache bool, transactionID string) (ids []string, err error) {
+	for id, d := range rh.destinations {
+		for _, p := range d.Prefixes {
+			if p == prefix {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	crntIDs, err := rh.dataDB.GetReverseDestination(prefix, skipCache, transactionID)
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	for _, id := range crntIDs {
+		if _, has := rh.destinations[id]; !has { // destination not snapshotted, keep the current one
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,53 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+// RatingHook is an extension point invoked around the cost calculation done by CallDescriptor.GetCost,
+// eg: to add surcharges or taxes or for custom logging. Debits are not hooked since they charge balances while rating.
+// PreRating may alter the CallDescriptor before it is rated and PostRating the resulting CallCost,
+// an error returned by either of them fails the cost calculation.
+type RatingHook interface {
+	PreRating(cd *CallDescriptor) error
+	PostRating(cd *CallDescriptor, cc *CallCost) error
+}
+
+var ratingHooks []RatingHook
+
+// RegisterRatingHook adds a hook invoked, in registration order, around each cost calculation
+// Meant to be called at startup, before rating requests are served
+func RegisterRatingHook(hook RatingHook) {
+	ratingHooks = append(ratingHooks, hook)
+}
+
+func preRatingHooks(cd *CallDescriptor) error {
+	for _, hook := range ratingHooks {
+		if err := hook.PreRating(cd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func postRatingHooks(cd *CallDescriptor, cc *CallCost) error {
+	for _, hook := range ratingHooks {
+		if err := hook.PostRating(cd, cc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -19,9 +19,13 @@ package engine
 
 import (
 	"encoding/json"
-	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/cgrates/cgrates/history"
+	"github.com/cgrates/cgrates/utils"
 )
 
 /*
@@ -32,6 +36,8 @@ type RatingPlan struct {
 	Timings          map[string]*RITiming
 	Ratings          map[string]*RIRate
 	DestinationRates map[string]RPRateList
+	parts            *ratingPlanParts      // destination parts of a split plan, loaded on demand
+	rateTables       map[string]*rateTable // compiled rates per destination, see SetRpCompiledPlans
 }
 
 type RPRate struct {
@@ -47,6 +53,17 @@ func (rpr *RPRate) Equal(orpr *RPRate) bool {
 type RPRateList []*RPRate
 
 func (rp *RatingPlan) RateIntervalList(dId string) RateIntervalList {
+	if rprl, has := rp.DestinationRates[dId]; has && rprl == nil {
+		part, err := rp.destinationPart(dId)
+		if err != nil {
+			utils.Logger.Warning("<RatingPlan> cannot load part " + ratingPlanPartKey(rp.Id, dId) + ": " + err.Error())
+			return nil
+		}
+		if !part.isSplit() {
+			return part.RateIntervalList(dId)
+		}
+		return nil
+	}
 	ril := make(RateIntervalList, len(rp.DestinationRates[dId]))
 	for i, rpr := range rp.DestinationRates[dId] {
 		ril[i] = &RateInterval{
@@ -94,6 +111,67 @@ func (rp *RatingPlan) AddRateInterval(dId string, ris ...*RateInterval) {
 	}
 }
 
+// RateIntervalPool interns the timings, ratings and rates of the rating plans built during one load
+// so identical intervals are shared across plans instead of being duplicated per destination
+type RateIntervalPool struct {
+	timings map[string]*RITiming
+	ratings map[string]*RIRate
+	rpRates map[RPRate]*RPRate
+	added   map[*RatingPlan]utils.StringMap // destID:timing:rating:weight already present in each plan
+	mux     sync.Mutex                      // rating plans can be built concurrently
+}
+
+func NewRateIntervalPool() *RateIntervalPool {
+	return &RateIntervalPool{
+		timings: make(map[string]*RITiming),
+		ratings: make(map[string]*RIRate),
+		rpRates: make(map[RPRate]*RPRate),
+		added:   make(map[*RatingPlan]utils.StringMap),
+	}
+}
+
+// AddRateInterval works like RatingPlan.AddRateInterval but shares the pooled instances and avoids the list scan
+func (pool *RateIntervalPool) AddRateInterval(rp *RatingPlan, dId string, ris ...*RateInterval) {
+	pool.mux.Lock()
+	defer pool.mux.Unlock()
+	if rp.DestinationRates == nil {
+		rp.Timings = make(map[string]*RITiming)
+		rp.Ratings = make(map[string]*RIRate)
+		rp.DestinationRates = make(map[string]RPRateList, 1)
+	}
+	for _, ri := range ris {
+		rpr := RPRate{Weight: ri.Weight}
+		if ri.Timing != nil {
+			rpr.Timing = ri.Timing.Stringify()
+			if _, has := pool.timings[rpr.Timing]; !has {
+				pool.timings[rpr.Timing] = ri.Timing
+			}
+			rp.Timings[rpr.Timing] = pool.timings[rpr.Timing]
+		}
+		if ri.Rating != nil {
+			rpr.Rating = ri.Rating.Stringify()
+			if _, has := pool.ratings[rpr.Rating]; !has {
+				pool.ratings[rpr.Rating] = ri.Rating
+			}
+			rp.Ratings[rpr.Rating] = pool.ratings[rpr.Rating]
+		}
+		if _, has := pool.added[rp]; !has {
+			pool.added[rp] = make(utils.StringMap)
+		}
+		addedKey := utils.ConcatenatedKey(dId, rpr.Timing, rpr.Rating, strconv.FormatFloat(rpr.Weight, 'f', -1, 64))
+		if pool.added[rp][addedKey] {
+			continue
+		}
+		pool.added[rp][addedKey] = true
+		pooledRpr, has := pool.rpRates[rpr]
+		if !has {
+			pooledRpr = &RPRate{Timing: rpr.Timing, Rating: rpr.Rating, Weight: rpr.Weight}
+			pool.rpRates[rpr] = pooledRpr
+		}
+		rp.DestinationRates[dId] = append(rp.DestinationRates[dId], pooledRpr)
+	}
+}
+
 func (rp *RatingPlan) Equal(o *RatingPlan) bool {
 	return rp.Id == o.Id
 }
@@ -110,11 +188,16 @@ func (rp *RatingPlan) GetHistoryRecord() history.Record {
 
 // IsValid determines if the rating plan covers a continous period of time
 func (rp *RatingPlan) isContinous() bool {
-	weekdays := make([]int, 7)
+	return len(rp.uncoveredWeekDays()) == 0
+}
+
+// uncoveredWeekDays returns the weekdays not covered from midnight by any of the timings
+func (rp *RatingPlan) uncoveredWeekDays() (missing utils.WeekDays) {
+	weekdays := make([]bool, 7)
 	for _, tm := range rp.Timings {
 		// if it is a blank timing than it will match all
 		if tm.IsBlank() {
-			return true
+			return nil
 		}
 		// skip the special timings (for specific dates)
 		if len(tm.Years) != 0 || len(tm.Months) != 0 || len(tm.MonthDays) != 0 {
@@ -124,40 +207,49 @@ func (rp *RatingPlan) isContinous() bool {
 		if tm.StartTime != "00:00:00" {
 			continue
 		}
-		//check if all weekdays are covered
 		for _, wd := range tm.WeekDays {
-			weekdays[wd] = 1
+			weekdays[wd] = true
 		}
-		allWeekdaysCovered := true
-		for _, wd := range weekdays {
-			if wd != 1 {
-				allWeekdaysCovered = false
-				break
-			}
+	}
+	for wd, covered := range weekdays {
+		if !covered {
+			missing = append(missing, time.Weekday(wd))
 		}
-		if allWeekdaysCovered {
-			return true
+	}
+	return
+}
+
+// isSane checks that the rate groups are increasing and each start is reachable with the previous increment
+func (rir *RIRate) isSane() bool {
+	rir.Rates.Sort()
+	for i, rate := range rir.Rates {
+		if i < (len(rir.Rates) - 1) {
+			nextRate := rir.Rates[i+1]
+			if nextRate.GroupIntervalStart <= rate.GroupIntervalStart {
+				return false
+			}
+			if rate.RateUnit == 0 || rate.RateIncrement == 0 {
+				return false
+			}
+			// integer math so sub-second increments are not lost on float rounding
+			if nextRate.GroupIntervalStart%rate.RateIncrement != 0 {
+				return false
+			}
 		}
 	}
-	return false
+	return true
+}
+
+// isSane checks that the timing does not mix specific dates with weekdays
+func (rit *RITiming) isSane() bool {
+	return !((len(rit.Years) != 0 || len(rit.Months) != 0 || len(rit.MonthDays) != 0) &&
+		len(rit.WeekDays) != 0)
 }
 
 func (rp *RatingPlan) getFirstUnsaneRating() string {
 	for _, rating := range rp.Ratings {
-		rating.Rates.Sort()
-		for i, rate := range rating.Rates {
-			if i < (len(rating.Rates) - 1) {
-				nextRate := rating.Rates[i+1]
-				if nextRate.GroupIntervalStart <= rate.GroupIntervalStart {
-					return rating.tag
-				}
-				if math.Mod(nextRate.GroupIntervalStart.Seconds(), rate.RateIncrement.Seconds()) != 0 {
-					return rating.tag
-				}
-				if rate.RateUnit == 0 || rate.RateIncrement == 0 {
-					return rating.tag
-				}
-			}
+		if !rating.isSane() {
+			return rating.tag
 		}
 	}
 	return ""
@@ -165,10 +257,51 @@ func (rp *RatingPlan) getFirstUnsaneRating() string {
 
 func (rp *RatingPlan) getFirstUnsaneTiming() string {
 	for _, timing := range rp.Timings {
-		if (len(timing.Years) != 0 || len(timing.Months) != 0 || len(timing.MonthDays) != 0) &&
-			len(timing.WeekDays) != 0 {
-			return timing.tag
+		if !timing.isSane() {
+			return timing.ID
 		}
 	}
 	return ""
 }
+
+// RatingPlanSanityReport lists all the problems found on a rating plan
+type RatingPlanSanityReport struct {
+	RatingPlanID      string
+	InvalidRatings    []string       // tags of the ratings with invalid rate groups
+	InvalidTimings    []string       // tags of the timings mixing dates with weekdays
+	UncoveredWeekDays utils.WeekDays // weekdays without a timing starting at midnight
+}
+
+// IsSane returns true when no problem was reported
+func (rpt *RatingPlanSanityReport) IsSane() bool {
+	return len(rpt.InvalidRatings) == 0 && len(rpt.InvalidTimings) == 0 &&
+		len(rpt.UncoveredWeekDays) == 0
+}
+
+// SanityReport checks the whole rating plan instead of stopping at the first problem
+func (rp *RatingPlan) SanityReport() *RatingPlanSanityReport {
+	rpt := &RatingPlanSanityReport{RatingPlanID: rp.Id}
+	for key, rating := range rp.Ratings {
+		if rating.isSane() {
+			continue
+		}
+		// tags are only known at load time, stored plans are reported by their key
+		if rating.tag != "" {
+			key = rating.tag
+		}
+		rpt.InvalidRatings = append(rpt.InvalidRatings, key)
+	}
+	for key, timing := range rp.Timings {
+		if timing.isSane() {
+			continue
+		}
+		if timing.ID != "" {
+			key = timing.ID
+		}
+		rpt.InvalidTimings = append(rpt.InvalidTimings, key)
+	}
+	sort.Strings(rpt.InvalidRatings)
+	sort.Strings(rpt.InvalidTimings)
+	rpt.UncoveredWeekDays = rp.uncoveredWeekDays()
+	return rpt
+}
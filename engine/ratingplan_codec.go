@@ -0,0 +1,397 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"sync"
+	"time"
+)
+
+// ratingPlanCodecVersion prefixes the binary encoded rating plans.
+// Legacy values are zlib streams of the DBDataEncoding marshaler and start with 0x78.
+// Version 2 adds the timing DSTPolicy, version 3 the rating TierPeriod, version 4 its Currency, version 5 the timing
+// HolidayCalendars, version 6 the rating MinCharge and version 7 the timing ID, older versions are still decoded.
+const ratingPlanCodecVersion byte = 7
+
+var (
+	errRatingPlanCodec = errors.New("corrupted rating plan encoding")
+	rpBufferPool       = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+	rpZWriterPool      = sync.Pool{New: func() interface{} { return zlib.NewWriter(nil) }}
+	rpZReaderPool      sync.Pool
+)
+
+// marshalRatingPlan encodes the rating plan in the compact binary format, compressed
+func marshalRatingPlan(rp *RatingPlan) ([]byte, error) {
+	raw := rpBufferPool.Get().(*bytes.Buffer)
+	defer rpBufferPool.Put(raw)
+	raw.Reset()
+	enc := &rpEncoder{buf: raw}
+	enc.encodeRatingPlan(rp)
+	out := new(bytes.Buffer)
+	out.WriteByte(ratingPlanCodecVersion)
+	zw := rpZWriterPool.Get().(*zlib.Writer)
+	defer rpZWriterPool.Put(zw)
+	zw.Reset(out)
+	if _, err := zw.Write(raw.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// unmarshalRatingPlan decodes both the binary format and the legacy one using ms
+func unmarshalRatingPlan(ms Marshaler, data []byte) (rp *RatingPlan, err error) {
+	if len(data) == 0 {
+		return nil, errRatingPlanCodec
+	}
+	version := data[0]
+	binaryEnc := version >= 1 && version <= ratingPlanCodecVersion
+	if binaryEnc {
+		data = data[1:]
+	}
+	var zr io.ReadCloser
+	if pooled := rpZReaderPool.Get(); pooled != nil {
+		zr = pooled.(io.ReadCloser)
+		err = zr.(zlib.Resetter).Reset(bytes.NewReader(data), nil)
+	} else {
+		zr, err = zlib.NewReader(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	out, err := ioutil.ReadAll(zr)
+	zr.Close()
+	rpZReaderPool.Put(zr)
+	if err != nil {
+		return nil, err
+	}
+	if !binaryEnc {
+		rp = new(RatingPlan)
+		if err = ms.Unmarshal(out, rp); err != nil {
+			return nil, err
+		}
+		compileRateTables(rp)
+		return
+	}
+	dec := &rpDecoder{data: out, version: version}
+	rp = dec.decodeRatingPlan()
+	if dec.err != nil {
+		return nil, dec.err
+	}
+	if rp.isSplit() {
+		rp.parts = &ratingPlanParts{parts: make(map[string]*RatingPlan)}
+	}
+	compileRateTables(rp)
+	return
+}
+
+type rpEncoder struct {
+	buf     *bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (enc *rpEncoder) putUvarint(v uint64) {
+	n := binary.PutUvarint(enc.scratch[:], v)
+	enc.buf.Write(enc.scratch[:n])
+}
+
+func (enc *rpEncoder) putVarint(v int64) {
+	n := binary.PutVarint(enc.scratch[:], v)
+	enc.buf.Write(enc.scratch[:n])
+}
+
+func (enc *rpEncoder) putFloat64(f float64) {
+	binary.LittleEndian.PutUint64(enc.scratch[:8], math.Float64bits(f))
+	enc.buf.Write(enc.scratch[:8])
+}
+
+func (enc *rpEncoder) putString(s string) {
+	enc.putUvarint(uint64(len(s)))
+	enc.buf.WriteString(s)
+}
+
+// putLen writes the length of a slice or map, 0 being reserved for nil
+func (enc *rpEncoder) putLen(l int, isNil bool) {
+	if isNil {
+		enc.putUvarint(0)
+		return
+	}
+	enc.putUvarint(uint64(l) + 1)
+}
+
+func (enc *rpEncoder) putInts(ints []int) {
+	enc.putLen(len(ints), ints == nil)
+	for _, i := range ints {
+		enc.putVarint(int64(i))
+	}
+}
+
+func (enc *rpEncoder) putStrings(strs []string) {
+	enc.putLen(len(strs), strs == nil)
+	for _, s := range strs {
+		enc.putString(s)
+	}
+}
+
+func (enc *rpEncoder) encodeRatingPlan(rp *RatingPlan) {
+	enc.putString(rp.Id)
+	enc.putLen(len(rp.Timings), rp.Timings == nil)
+	for tag, rit := range rp.Timings {
+		enc.putString(tag)
+		enc.putInts(rit.Years)
+		enc.putLen(len(rit.Months), rit.Months == nil)
+		for _, m := range rit.Months {
+			enc.putVarint(int64(m))
+		}
+		enc.putInts(rit.MonthDays)
+		enc.putLen(len(rit.WeekDays), rit.WeekDays == nil)
+		for _, wd := range rit.WeekDays {
+			enc.putVarint(int64(wd))
+		}
+		enc.putString(rit.StartTime)
+		enc.putString(rit.EndTime)
+		enc.putString(rit.DSTPolicy)
+		enc.putStrings(rit.HolidayCalendars)
+		enc.putString(rit.ID)
+	}
+	enc.putLen(len(rp.Ratings), rp.Ratings == nil)
+	for tag, rir := range rp.Ratings {
+		enc.putString(tag)
+		enc.putFloat64(rir.ConnectFee)
+		enc.putString(rir.RoundingMethod)
+		enc.putVarint(int64(rir.RoundingDecimals))
+		enc.putFloat64(rir.MaxCost)
+		enc.putString(rir.MaxCostStrategy)
+		enc.putString(rir.TierPeriod)
+		enc.putString(rir.Currency)
+		enc.putFloat64(rir.MinCharge)
+		enc.putVarint(int64(rir.MinChargeUsage))
+		enc.putLen(len(rir.Rates), rir.Rates == nil)
+		for _, rt := range rir.Rates {
+			enc.putVarint(int64(rt.GroupIntervalStart))
+			enc.putFloat64(rt.Value)
+			enc.putVarint(int64(rt.RateIncrement))
+			enc.putVarint(int64(rt.RateUnit))
+		}
+	}
+	enc.putLen(len(rp.DestinationRates), rp.DestinationRates == nil)
+	for dstID, rprl := range rp.DestinationRates {
+		enc.putString(dstID)
+		enc.putLen(len(rprl), rprl == nil)
+		for _, rpr := range rprl {
+			enc.putString(rpr.Timing)
+			enc.putString(rpr.Rating)
+			enc.putFloat64(rpr.Weight)
+		}
+	}
+}
+
+type rpDecoder struct {
+	data    []byte
+	pos     int
+	err     error
+	version byte
+}
+
+func (dec *rpDecoder) uvarint() uint64 {
+	if dec.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(dec.data[dec.pos:])
+	if n <= 0 {
+		dec.err = errRatingPlanCodec
+		return 0
+	}
+	dec.pos += n
+	return v
+}
+
+func (dec *rpDecoder) varint() int64 {
+	if dec.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(dec.data[dec.pos:])
+	if n <= 0 {
+		dec.err = errRatingPlanCodec
+		return 0
+	}
+	dec.pos += n
+	return v
+}
+
+func (dec *rpDecoder) float64() float64 {
+	if dec.err != nil {
+		return 0
+	}
+	if len(dec.data)-dec.pos < 8 {
+		dec.err = errRatingPlanCodec
+		return 0
+	}
+	f := math.Float64frombits(binary.LittleEndian.Uint64(dec.data[dec.pos:]))
+	dec.pos += 8
+	return f
+}
+
+func (dec *rpDecoder) string() string {
+	l := dec.uvarint()
+	if dec.err != nil {
+		return ""
+	}
+	if uint64(len(dec.data)-dec.pos) < l {
+		dec.err = errRatingPlanCodec
+		return ""
+	}
+	s := string(dec.data[dec.pos : dec.pos+int(l)])
+	dec.pos += int(l)
+	return s
+}
+
+// len returns the decoded length and false for nil
+func (dec *rpDecoder) len() (int, bool) {
+	l := dec.uvarint()
+	if dec.err != nil || l == 0 {
+		return 0, false
+	}
+	if l-1 > uint64(len(dec.data)-dec.pos) { // each item takes at least one byte
+		dec.err = errRatingPlanCodec
+		return 0, false
+	}
+	return int(l - 1), true
+}
+
+func (dec *rpDecoder) ints() []int {
+	l, notNil := dec.len()
+	if !notNil {
+		return nil
+	}
+	ints := make([]int, l)
+	for i := range ints {
+		ints[i] = int(dec.varint())
+	}
+	return ints
+}
+
+func (dec *rpDecoder) strings() []string {
+	l, notNil := dec.len()
+	if !notNil {
+		return nil
+	}
+	strs := make([]string, l)
+	for i := range strs {
+		strs[i] = dec.string()
+	}
+	return strs
+}
+
+func (dec *rpDecoder) decodeRatingPlan() *RatingPlan {
+	rp := &RatingPlan{Id: dec.string()}
+	if l, notNil := dec.len(); notNil {
+		rp.Timings = make(map[string]*RITiming, l)
+		for i := 0; i < l && dec.err == nil; i++ {
+			tag := dec.string()
+			rit := &RITiming{Years: dec.ints()}
+			if ml, notNil := dec.len(); notNil {
+				rit.Months = make([]time.Month, ml)
+				for j := range rit.Months {
+					rit.Months[j] = time.Month(dec.varint())
+				}
+			}
+			rit.MonthDays = dec.ints()
+			if wl, notNil := dec.len(); notNil {
+				rit.WeekDays = make([]time.Weekday, wl)
+				for j := range rit.WeekDays {
+					rit.WeekDays[j] = time.Weekday(dec.varint())
+				}
+			}
+			rit.StartTime = dec.string()
+			rit.EndTime = dec.string()
+			if dec.version > 1 {
+				rit.DSTPolicy = dec.string()
+			}
+			if dec.version > 4 {
+				rit.HolidayCalendars = dec.strings()
+			}
+			if dec.version > 6 {
+				rit.ID = dec.string()
+			}
+			rp.Timings[tag] = rit
+		}
+	}
+	if l, notNil := dec.len(); notNil {
+		rp.Ratings = make(map[string]*RIRate, l)
+		for i := 0; i < l && dec.err == nil; i++ {
+			tag := dec.string()
+			rir := &RIRate{
+				ConnectFee:       dec.float64(),
+				RoundingMethod:   dec.string(),
+				RoundingDecimals: int(dec.varint()),
+				MaxCost:          dec.float64(),
+				MaxCostStrategy:  dec.string(),
+			}
+			if dec.version > 2 {
+				rir.TierPeriod = dec.string()
+			}
+			if dec.version > 3 {
+				rir.Currency = dec.string()
+			}
+			if dec.version > 5 {
+				rir.MinCharge = dec.float64()
+				rir.MinChargeUsage = time.Duration(dec.varint())
+			}
+			if rl, notNil := dec.len(); notNil {
+				rir.Rates = make(RateGroups, rl)
+				for j := range rir.Rates {
+					rir.Rates[j] = &Rate{
+						GroupIntervalStart: time.Duration(dec.varint()),
+						Value:              dec.float64(),
+						RateIncrement:      time.Duration(dec.varint()),
+						RateUnit:           time.Duration(dec.varint()),
+					}
+				}
+			}
+			rp.Ratings[tag] = rir
+		}
+	}
+	if l, notNil := dec.len(); notNil {
+		rp.DestinationRates = make(map[string]RPRateList, l)
+		for i := 0; i < l && dec.err == nil; i++ {
+			dstID := dec.string()
+			var rprl RPRateList
+			if rl, notNil := dec.len(); notNil {
+				rprl = make(RPRateList, rl)
+				for j := range rprl {
+					rprl[j] = &RPRate{Timing: dec.string(), Rating: dec.string(), Weight: dec.float64()}
+				}
+			}
+			rp.DestinationRates[dstID] = rprl
+		}
+	}
+	if dec.err == nil && dec.pos != len(dec.data) {
+		dec.err = errRatingPlanCodec
+	}
+	return rp
+}
@@ -0,0 +1,150 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ratingPlanSplitSize is the number of destinations above which a rating plan is stored as a header plus one part per destination
+var ratingPlanSplitSize int
+
+// SetRatingPlanSplitSize sets the destinations threshold for splitting rating plans, 0 disables splitting
+func SetRatingPlanSplitSize(size int) {
+	ratingPlanSplitSize = size
+}
+
+// ratingPlanParts holds the destination parts of a split rating plan loaded so far
+type ratingPlanParts struct {
+	parts map[string]*RatingPlan
+	mux   sync.Mutex
+}
+
+// ratingPlanPartKey returns the storage key of one destination part of a rating plan
+func ratingPlanPartKey(planID, destID string) string {
+	return utils.RatingPlanPartPrefix + utils.ConcatenatedKey(planID, destID)
+}
+
+// isSplit returns true for rating plan headers, their destinations having no rates attached
+func (rp *RatingPlan) isSplit() bool {
+	for _, rprl := range rp.DestinationRates {
+		if rprl == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRatingPlan breaks a large rating plan into a header keeping only the destination IDs
+// and one part per destination with the timings and ratings referenced by its rates
+func splitRatingPlan(rp *RatingPlan) (header *RatingPlan, parts []*RatingPlan) {
+	if ratingPlanSplitSize == 0 || len(rp.DestinationRates) <= ratingPlanSplitSize || rp.isSplit() {
+		return rp, nil
+	}
+	header = &RatingPlan{
+		Id:               rp.Id,
+		Timings:          make(map[string]*RITiming),
+		Ratings:          make(map[string]*RIRate),
+		DestinationRates: make(map[string]RPRateList, len(rp.DestinationRates)),
+	}
+	for dID, rprl := range rp.DestinationRates {
+		header.DestinationRates[dID] = nil
+		part := &RatingPlan{
+			Id:               rp.Id,
+			Timings:          make(map[string]*RITiming),
+			Ratings:          make(map[string]*RIRate),
+			DestinationRates: map[string]RPRateList{dID: rprl},
+		}
+		for _, rpr := range rprl {
+			if tm, has := rp.Timings[rpr.Timing]; has {
+				part.Timings[rpr.Timing] = tm
+			}
+			if rt, has := rp.Ratings[rpr.Rating]; has {
+				part.Ratings[rpr.Rating] = rt
+			}
+		}
+		parts = append(parts, part)
+	}
+	return
+}
+
+// destinationPart returns the part of a split rating plan for one destination, loading it from the data db on first use
+// The parts are fetched outside the lock so the lookups of different destinations do not wait on each other
+func (rp *RatingPlan) destinationPart(dID string) (*RatingPlan, error) {
+	if rp.parts != nil {
+		rp.parts.mux.Lock()
+		part, has := rp.parts.parts[dID]
+		rp.parts.mux.Unlock()
+		if has {
+			return part, nil
+		}
+	}
+	part, err := dataStorage.GetRatingPlanPart(rp.Id, dID)
+	if err != nil {
+		return nil, err
+	}
+	if rp.parts != nil {
+		rp.parts.mux.Lock()
+		if loaded, has := rp.parts.parts[dID]; has { // loaded meanwhile by a concurrent lookup
+			part = loaded
+		} else {
+			rp.parts.parts[dID] = part
+		}
+		rp.parts.mux.Unlock()
+	}
+	return part, nil
+}
+
+// Unsplit returns the full rating plan, loading all the destination parts of a split one
+func (rp *RatingPlan) Unsplit() (*RatingPlan, error) {
+	if !rp.isSplit() {
+		return rp, nil
+	}
+	full := &RatingPlan{
+		Id:               rp.Id,
+		Timings:          make(map[string]*RITiming),
+		Ratings:          make(map[string]*RIRate),
+		DestinationRates: make(map[string]RPRateList, len(rp.DestinationRates)),
+	}
+	for dID, rprl := range rp.DestinationRates {
+		if rprl == nil {
+			part, err := rp.destinationPart(dID)
+			if err != nil {
+				return nil, fmt.Errorf("cannot load part %s: %s", ratingPlanPartKey(rp.Id, dID), err.Error())
+			}
+			for tmID, tm := range part.Timings {
+				full.Timings[tmID] = tm
+			}
+			for rtID, rt := range part.Ratings {
+				full.Ratings[rtID] = rt
+			}
+			rprl = part.DestinationRates[dID]
+		}
+		full.DestinationRates[dID] = rprl
+	}
+	for tmID, tm := range rp.Timings {
+		full.Timings[tmID] = tm
+	}
+	for rtID, rt := range rp.Ratings {
+		full.Ratings[rtID] = rt
+	}
+	return full, nil
+}
@@ -0,0 +1,143 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// rateTableSlots is the number of one minute slots in a week, the resolution of the compiled rate tables
+const rateTableSlots = 7 * 24 * 60
+
+// rpCompiledPlans are the IDs of the rating plans compiled into flat rate tables at load time, *any for all
+var rpCompiledPlans utils.StringMap
+
+// SetRpCompiledPlans selects the rating plans compiled into flat rate tables when loaded
+func SetRpCompiledPlans(planIDs []string) {
+	rpCompiledPlans = utils.NewStringMap(planIDs...)
+}
+
+// rateTable resolves in constant time the rate interval starting each minute of the week for one destination,
+// trading the memory of the table for the sorting and matching of the rate intervals on every timespan
+type rateTable struct {
+	intervals RateIntervalList       // sorted as the RateIntervalTimeSorter does
+	best      [rateTableSlots]uint16 // index in intervals of the best one for each slot
+}
+
+// rateTableSlot returns the slot of the week the time falls into
+func rateTableSlot(t time.Time) int {
+	return (int(t.Weekday())*24+t.Hour())*60 + t.Minute()
+}
+
+// compilesToRateTable returns true for the timings which match the same way during each minute of any week
+func compilesToRateTable(rit *RITiming) bool {
+	if rit == nil || len(rit.Years) != 0 || len(rit.Months) != 0 || len(rit.MonthDays) != 0 ||
+		len(rit.HolidayCalendars) != 0 || (rit.DSTPolicy != "" && rit.DSTPolicy != utils.MetaWallClock) {
+		return false
+	}
+	return (rit.StartTime == "" || strings.HasSuffix(rit.StartTime, ":00")) &&
+		(rit.EndTime == "" || strings.HasSuffix(rit.EndTime, ":59"))
+}
+
+// newRateTable compiles the rate intervals of one destination,
+// nil if their timings cannot be represented in a weekly table or leave minutes of the week uncovered
+func newRateTable(ril RateIntervalList) *rateTable {
+	if len(ril) == 0 || len(ril) > math.MaxUint16 {
+		return nil
+	}
+	for _, ri := range ril {
+		if !compilesToRateTable(ri.Timing) {
+			return nil
+		}
+	}
+	weekStart := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC) // a sunday
+	rt := &rateTable{intervals: (&RateIntervalTimeSorter{referenceTime: weekStart, ris: ril}).Sort()}
+	for slot := 0; slot < rateTableSlots; slot++ {
+		best, _, found := bestRateInterval(rt.intervals, weekStart.Add(time.Duration(slot)*time.Minute))
+		if !found {
+			return nil
+		}
+		rt.best[slot] = uint16(best)
+	}
+	return rt
+}
+
+// selectForTimespan does the job of RatingInfo.SelectRatingIntevalsForTimespan using the compiled table,
+// returns nil when the start of the timespan cannot be resolved from the table
+func (rt *rateTable) selectForTimespan(ts *TimeSpan) RateIntervalList {
+	t := ts.TimeStart
+	if t.Second() == 59 && t.Nanosecond() != 0 { // past the end margin of the timings ending within this minute
+		return nil
+	}
+	if t.Location() != time.UTC {
+		_, dayStartOffset := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Zone()
+		if _, dayEndOffset := time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location()).Zone(); dayStartOffset != dayEndOffset {
+			return nil // DST change during the day moves the margins of the timings
+		}
+	}
+	best := rt.best[rateTableSlot(t)]
+	return selectRateIntervals(rt.intervals, int(best), rt.intervals[best].Weight, ts)
+}
+
+// compileRateTables builds the rate tables of the destinations of rp if it is selected for compiling
+func compileRateTables(rp *RatingPlan) {
+	if !rpCompiledPlans[rp.Id] && !rpCompiledPlans[utils.ANY] {
+		return
+	}
+	shared := make(map[string]*rateTable) // destinations with the same rates share the table
+	for dID, rprl := range rp.DestinationRates {
+		if rprl == nil { // compiled when the part of the split plan is loaded
+			continue
+		}
+		keyParts := make([]string, len(rprl))
+		for i, rpr := range rprl {
+			keyParts[i] = utils.ConcatenatedKey(rpr.Timing, rpr.Rating, strconv.FormatFloat(rpr.Weight, 'f', -1, 64))
+		}
+		key := strings.Join(keyParts, utils.INFIELD_SEP)
+		rt, has := shared[key]
+		if !has {
+			rt = newRateTable(rp.RateIntervalList(dID))
+			shared[key] = rt
+		}
+		if rt == nil {
+			continue
+		}
+		if rp.rateTables == nil {
+			rp.rateTables = make(map[string]*rateTable)
+		}
+		rp.rateTables[dID] = rt
+	}
+}
+
+// rateTable returns the compiled rate table of the destination, nil if the plan or the destination was not compiled
+func (rp *RatingPlan) rateTable(dID string) *rateTable {
+	if rt, has := rp.rateTables[dID]; has {
+		return rt
+	}
+	if rprl, has := rp.DestinationRates[dID]; has && rprl == nil && rp.parts != nil {
+		if part, err := rp.destinationPart(dID); err == nil { // errors are reported out of RateIntervalList
+			return part.rateTables[dID]
+		}
+	}
+	return nil
+}
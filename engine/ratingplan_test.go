@@ -18,6 +18,8 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"bytes"
+	"compress/zlib"
 	"encoding/json"
 
 	"github.com/cgrates/cgrates/utils"
@@ -307,7 +309,7 @@ func TestRatingPlanIsContinousMissing(t *testing.T) {
 func TestRatingPlanSaneTimingsBad(t *testing.T) {
 	rpl := &RatingPlan{
 		Timings: map[string]*RITiming{
-			"one": &RITiming{Years: utils.Years{2015}, WeekDays: utils.WeekDays{time.Monday}, tag: "first"},
+			"one": &RITiming{Years: utils.Years{2015}, WeekDays: utils.WeekDays{time.Monday}, ID: "first"},
 		},
 	}
 	if crazyTiming := rpl.getFirstUnsaneTiming(); crazyTiming == "" {
@@ -318,8 +320,8 @@ func TestRatingPlanSaneTimingsBad(t *testing.T) {
 func TestRatingPlanSaneTimingsGood(t *testing.T) {
 	rpl := &RatingPlan{
 		Timings: map[string]*RITiming{
-			"one": &RITiming{Years: utils.Years{2015}, tag: "first"},
-			"two": &RITiming{WeekDays: utils.WeekDays{0, 1, 2, 3, 4}, StartTime: "00:00:00", tag: "second"},
+			"one": &RITiming{Years: utils.Years{2015}, ID: "first"},
+			"two": &RITiming{WeekDays: utils.WeekDays{0, 1, 2, 3, 4}, StartTime: "00:00:00", ID: "second"},
 		},
 	}
 	if crazyTiming := rpl.getFirstUnsaneTiming(); crazyTiming != "" {
@@ -454,3 +456,301 @@ func BenchmarkRatingPlanRestore(b *testing.B) {
 		dataStorage.GetRatingPlan(rp.Id, true, utils.NonTransactional)
 	}
 }
+
+func TestRateIntervalPoolAddRateInterval(t *testing.T) {
+	pool := NewRateIntervalPool()
+	ri := &RateInterval{
+		Timing: &RITiming{StartTime: "00:00:00"},
+		Rating: &RIRate{Rates: RateGroups{&Rate{Value: 0.1, RateIncrement: time.Second, RateUnit: time.Second}}},
+		Weight: 10,
+	}
+	riCopy := &RateInterval{
+		Timing: &RITiming{StartTime: "00:00:00"},
+		Rating: &RIRate{Rates: RateGroups{&Rate{Value: 0.1, RateIncrement: time.Second, RateUnit: time.Second}}},
+		Weight: 10,
+	}
+	rp1 := &RatingPlan{Id: "RP1"}
+	rp2 := &RatingPlan{Id: "RP2"}
+	pool.AddRateInterval(rp1, "NAT", ri, riCopy)
+	pool.AddRateInterval(rp1, "INT", riCopy)
+	pool.AddRateInterval(rp2, "NAT", riCopy)
+	if len(rp1.DestinationRates["NAT"]) != 1 || len(rp1.DestinationRates["INT"]) != 1 || len(rp2.DestinationRates["NAT"]) != 1 {
+		t.Fatalf("Unexpected destination rates: %s, %s", utils.ToJSON(rp1.DestinationRates), utils.ToJSON(rp2.DestinationRates))
+	}
+	if rp1.DestinationRates["NAT"][0] != rp2.DestinationRates["NAT"][0] ||
+		rp1.DestinationRates["NAT"][0] != rp1.DestinationRates["INT"][0] {
+		t.Error("Rates not shared between plans")
+	}
+	tmTag := ri.Timing.Stringify()
+	if rp2.Timings[tmTag] != ri.Timing {
+		t.Error("Timing not shared between plans")
+	}
+	if rp2.Ratings[ri.Rating.Stringify()] != ri.Rating {
+		t.Error("Rating not shared between plans")
+	}
+	plain := &RatingPlan{Id: "RP1"}
+	plain.AddRateInterval("NAT", riCopy)
+	if !reflect.DeepEqual(plain.RateIntervalList("NAT"), rp1.RateIntervalList("NAT")) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(plain.RateIntervalList("NAT")), utils.ToJSON(rp1.RateIntervalList("NAT")))
+	}
+}
+
+func testCodecRatingPlan() *RatingPlan {
+	rp := &RatingPlan{Id: "RP_CODEC"}
+	for i, dst := range []string{"NAT", "MOBILE", "INT", "PREMIUM"} {
+		rp.AddRateInterval(dst, &RateInterval{
+			Timing: &RITiming{Years: utils.Years{}, Months: utils.Months{time.January}, MonthDays: utils.MonthDays{},
+				WeekDays: utils.WeekDays{time.Monday, time.Friday}, StartTime: "00:00:00", DSTPolicy: utils.MetaUTCOffset,
+				HolidayCalendars: []string{"HOL_DE"}, ID: "WORKDAYS_JAN"},
+			Rating: &RIRate{ConnectFee: 0.1, RoundingMethod: utils.ROUNDING_MIDDLE, RoundingDecimals: 4,
+				MaxCost: 10, MaxCostStrategy: utils.MAX_COST_FREE, TierPeriod: utils.MetaMonthly, Currency: "USD",
+				MinCharge: 0.5, MinChargeUsage: 30 * time.Second,
+				Rates: RateGroups{&Rate{Value: float64(i) / 10, RateIncrement: time.Second, RateUnit: time.Minute},
+					&Rate{GroupIntervalStart: time.Minute, Value: 0.05, RateIncrement: 10 * time.Second, RateUnit: time.Minute}}},
+			Weight: 10,
+		}, &RateInterval{Timing: &RITiming{StartTime: "18:00:00"}, Weight: 20})
+	}
+	return rp
+}
+
+func TestRatingPlanCodec(t *testing.T) {
+	rp := testCodecRatingPlan()
+	data, err := marshalRatingPlan(rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != ratingPlanCodecVersion {
+		t.Errorf("Unexpected version byte: %v", data[0])
+	}
+	ms := NewCodecMsgpackMarshaler()
+	rcv, err := unmarshalRatingPlan(ms, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rp, rcv) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(rp), utils.ToJSON(rcv))
+	}
+	// legacy values are read with the configured marshaler
+	legacy, _ := ms.Marshal(rp)
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(legacy)
+	w.Close()
+	if rcv, err := unmarshalRatingPlan(ms, b.Bytes()); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(rp.RateIntervalList("NAT"), rcv.RateIntervalList("NAT")) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(rp.RateIntervalList("NAT")), utils.ToJSON(rcv.RateIntervalList("NAT")))
+	}
+	// binary payload cut short
+	var raw bytes.Buffer
+	(&rpEncoder{buf: &raw}).encodeRatingPlan(rp)
+	b.Reset()
+	b.WriteByte(ratingPlanCodecVersion)
+	w = zlib.NewWriter(&b)
+	w.Write(raw.Bytes()[:raw.Len()-3])
+	w.Close()
+	if _, err := unmarshalRatingPlan(ms, b.Bytes()); err != errRatingPlanCodec {
+		t.Errorf("Expecting: %v, received: %v", errRatingPlanCodec, err)
+	}
+}
+
+func BenchmarkRatingPlanMarshalBinary(b *testing.B) {
+	rp := testCodecRatingPlan()
+	for i := 0; i < b.N; i++ {
+		marshalRatingPlan(rp)
+	}
+}
+
+func BenchmarkRatingPlanMarshalMsgpack(b *testing.B) {
+	rp := testCodecRatingPlan()
+	ms := NewCodecMsgpackMarshaler()
+	for i := 0; i < b.N; i++ {
+		result, _ := ms.Marshal(rp)
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write(result)
+		w.Close()
+	}
+}
+
+func BenchmarkRatingPlanUnmarshalBinary(b *testing.B) {
+	ms := NewCodecMsgpackMarshaler()
+	data, _ := marshalRatingPlan(testCodecRatingPlan())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		unmarshalRatingPlan(ms, data)
+	}
+}
+
+func BenchmarkRatingPlanUnmarshalMsgpack(b *testing.B) {
+	ms := NewCodecMsgpackMarshaler()
+	result, _ := ms.Marshal(testCodecRatingPlan())
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(result)
+	w.Close()
+	data := buf.Bytes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		unmarshalRatingPlan(ms, data)
+	}
+}
+
+func TestRatingPlanSaneRatingsMilliseconds(t *testing.T) {
+	rpl := &RatingPlan{
+		Ratings: map[string]*RIRate{
+			"one": &RIRate{
+				tag: "first",
+				Rates: RateGroups{
+					&Rate{
+						GroupIntervalStart: 0,
+						RateIncrement:      100 * time.Millisecond,
+						RateUnit:           1 * time.Second,
+					},
+					&Rate{
+						GroupIntervalStart: 300 * time.Millisecond,
+						RateIncrement:      50 * time.Millisecond,
+						RateUnit:           100 * time.Millisecond,
+					},
+				},
+			},
+		},
+	}
+	if crazyRating := rpl.getFirstUnsaneRating(); crazyRating != "" {
+		t.Errorf("Error detecting bad rate groups in rating profile: %+v", rpl)
+	}
+	rpl.Ratings["one"].Rates[1].GroupIntervalStart = 350 * time.Millisecond
+	if crazyRating := rpl.getFirstUnsaneRating(); crazyRating == "" {
+		t.Errorf("Error detecting bad rate groups in rating profile: %+v", rpl)
+	}
+}
+
+func TestRatingPlanSanityReport(t *testing.T) {
+	badRates := RateGroups{
+		&Rate{GroupIntervalStart: 0, RateIncrement: 30 * time.Second, RateUnit: time.Second},
+		&Rate{GroupIntervalStart: 0, RateIncrement: 30 * time.Second, RateUnit: time.Second},
+	}
+	rpl := &RatingPlan{
+		Id: "RP_SANITY",
+		Timings: map[string]*RITiming{
+			"t1": &RITiming{Years: utils.Years{2015}, WeekDays: utils.WeekDays{1}, ID: "mixed1"},
+			"t2": &RITiming{Months: utils.Months{5}, WeekDays: utils.WeekDays{2}, ID: "mixed2"},
+			"t3": &RITiming{WeekDays: utils.WeekDays{1, 2, 3, 4, 5}, StartTime: "00:00:00", ID: "workdays"},
+		},
+		Ratings: map[string]*RIRate{
+			"r1": &RIRate{tag: "first", Rates: badRates},
+			"r2": &RIRate{Rates: RateGroups{
+				&Rate{GroupIntervalStart: 0, RateIncrement: 0},
+				&Rate{GroupIntervalStart: time.Minute, RateIncrement: time.Second},
+			}},
+			"r3": &RIRate{tag: "good", Rates: RateGroups{
+				&Rate{GroupIntervalStart: 0, RateIncrement: 30 * time.Second, RateUnit: time.Second},
+				&Rate{GroupIntervalStart: time.Minute, RateIncrement: time.Second, RateUnit: time.Second},
+			}},
+		},
+	}
+	eRpt := &RatingPlanSanityReport{
+		RatingPlanID:      "RP_SANITY",
+		InvalidRatings:    []string{"first", "r2"},
+		InvalidTimings:    []string{"mixed1", "mixed2"},
+		UncoveredWeekDays: utils.WeekDays{time.Sunday, time.Saturday},
+	}
+	rpt := rpl.SanityReport()
+	if !reflect.DeepEqual(eRpt, rpt) {
+		t.Errorf("Expecting: %+v, received: %+v", eRpt, rpt)
+	}
+	if rpt.IsSane() {
+		t.Error("Expecting insane report")
+	}
+	rpl = &RatingPlan{Timings: map[string]*RITiming{"blank": &RITiming{StartTime: "00:00:00"}}}
+	if rpt := rpl.SanityReport(); !rpt.IsSane() {
+		t.Errorf("Unexpected report: %+v", rpt)
+	}
+}
+
+func TestRatingPlanSplitLazyLoad(t *testing.T) {
+	SetRatingPlanSplitSize(1)
+	defer SetRatingPlanSplitSize(0)
+	rpl := &RatingPlan{Id: "RP_SPLIT"}
+	rpl.AddRateInterval("DST_1", &RateInterval{Timing: &RITiming{StartTime: "00:00:00"},
+		Rating: &RIRate{Rates: RateGroups{&Rate{Value: 1, RateIncrement: time.Second, RateUnit: time.Second}}}, Weight: 10})
+	rpl.AddRateInterval("DST_2", &RateInterval{Timing: &RITiming{StartTime: "08:00:00"},
+		Rating: &RIRate{Rates: RateGroups{&Rate{Value: 2, RateIncrement: time.Second, RateUnit: time.Second}}}, Weight: 20})
+	if err := dataStorage.SetRatingPlan(rpl, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	header, err := dataStorage.GetRatingPlan(rpl.Id, true, utils.NonTransactional)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !header.isSplit() || len(header.Timings) != 0 || len(header.Ratings) != 0 || len(header.DestinationRates) != 2 {
+		t.Fatalf("Unexpected header: %+v", header)
+	}
+	if ril := header.RateIntervalList("DST_2"); len(ril) != 1 || ril[0].Weight != 20 ||
+		ril[0].Timing.StartTime != "08:00:00" || ril[0].Rating.Rates[0].Value != 2 {
+		t.Errorf("Unexpected intervals: %+v", ril)
+	}
+	if len(header.parts.parts) != 1 {
+		t.Errorf("Expecting only the used part loaded, have: %+v", header.parts.parts)
+	}
+	full, err := header.Unsplit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(full.Timings) != 2 || len(full.Ratings) != 2 || !reflect.DeepEqual(rpl.DestinationRates, full.DestinationRates) {
+		t.Errorf("Expecting: %+v, received: %+v", rpl, full)
+	}
+	if err := dataStorage.RemoveRatingPlan(rpl.Id, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataStorage.GetRatingPlanPart(rpl.Id, "DST_1"); err != utils.ErrNotFound {
+		t.Errorf("Expecting not found, received: %v", err)
+	}
+	header.parts.parts = make(map[string]*RatingPlan) // parts missing from the data db
+	if _, err := header.Unsplit(); err == nil {
+		t.Error("Expecting error on missing part")
+	}
+}
+
+func TestRatingPlanRateTables(t *testing.T) {
+	rp := &RatingPlan{Id: "RP_COMPILED"}
+	peak := &RIRate{ConnectFee: 1, Rates: RateGroups{&Rate{Value: 2, RateIncrement: time.Minute, RateUnit: time.Minute}}}
+	offPeak := &RIRate{Rates: RateGroups{&Rate{Value: 1, RateIncrement: time.Minute, RateUnit: time.Minute}}}
+	weekend := &RIRate{Rates: RateGroups{&Rate{Value: 0.5, RateIncrement: time.Second, RateUnit: time.Minute}}}
+	for _, dID := range []string{"NAT", "MOB"} {
+		rp.AddRateInterval(dID,
+			&RateInterval{Timing: &RITiming{ID: "PEAK", WeekDays: utils.WeekDays{1, 2, 3, 4, 5}, StartTime: "08:00:00"}, Rating: peak, Weight: 10},
+			&RateInterval{Timing: &RITiming{ID: "OFFPEAK_MORNING", WeekDays: utils.WeekDays{1, 2, 3, 4, 5}, StartTime: "00:00:00"}, Rating: offPeak, Weight: 10},
+			&RateInterval{Timing: &RITiming{ID: "OFFPEAK_EVENING", WeekDays: utils.WeekDays{1, 2, 3, 4, 5}, StartTime: "19:30:00"}, Rating: offPeak, Weight: 10},
+			&RateInterval{Timing: &RITiming{ID: "WEEKEND", WeekDays: utils.WeekDays{6, 0}, StartTime: "00:00:00"}, Rating: weekend, Weight: 10})
+	}
+	rp.AddRateInterval("SPECIAL", &RateInterval{Timing: &RITiming{Years: utils.Years{2017}, StartTime: "00:00:00"}, Rating: peak, Weight: 10})
+	compileRateTables(rp)
+	if len(rp.rateTables) != 0 {
+		t.Error("Compiled rating plan not selected: ", len(rp.rateTables))
+	}
+	SetRpCompiledPlans([]string{"RP_COMPILED"})
+	defer SetRpCompiledPlans(nil)
+	compileRateTables(rp)
+	if len(rp.rateTables) != 2 || rp.rateTable("NAT") != rp.rateTable("MOB") {
+		t.Fatalf("Unexpected rate tables: %+v", rp.rateTables)
+	}
+	if rp.rateTable("SPECIAL") != nil {
+		t.Error("Compiled date specific timings")
+	}
+	compiled := RatingInfo{RateIntervals: rp.RateIntervalList("NAT"), rateTable: rp.rateTable("NAT")}
+	plain := RatingInfo{RateIntervals: rp.RateIntervalList("NAT")}
+	for start := time.Date(2017, 3, 6, 0, 0, 0, 0, time.UTC); start.Before(time.Date(2017, 3, 13, 0, 0, 0, 0, time.UTC)); start = start.Add(7*time.Minute + 13*time.Second) {
+		ts := &TimeSpan{TimeStart: start, TimeEnd: start.Add(95 * time.Minute)}
+		eRIs := plain.SelectRatingIntevalsForTimespan(ts)
+		if rcv := compiled.SelectRatingIntevalsForTimespan(ts); len(rcv) != len(eRIs) {
+			t.Fatalf("At %v expecting: %s, received: %s", start, utils.ToJSON(eRIs), utils.ToJSON(rcv))
+		} else {
+			for i := range rcv {
+				if !rcv[i].Equal(eRIs[i]) {
+					t.Fatalf("At %v expecting: %s, received: %s", start, utils.ToJSON(eRIs), utils.ToJSON(rcv))
+				}
+			}
+		}
+	}
+}
@@ -20,6 +20,7 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"sort"
 	"strings"
 	"time"
@@ -34,14 +35,27 @@ type RatingProfile struct {
 }
 
 type RatingPlanActivation struct {
-	ActivationTime  time.Time
-	RatingPlanId    string
-	FallbackKeys    []string
-	CdrStatQueueIds []string
+	ActivationTime   time.Time
+	RatingPlanId     string
+	FallbackKeys     []string
+	CdrStatQueueIds  []string
+	DeactivationTime time.Time // zero for never, previous activation applies again once passed
+	MaxCost          float64   // per call cap, overriding the one of the destination rates when MaxCostStrategy is set
+	MaxCostStrategy  string
+	TrafficShare     float64 // percentage of the accounts rated with this variant instead of the activation starting at the same time
+	variant          string  // rating plan of the A/B tested activation the account was assigned to
+}
+
+// activeAt checks if the activation is in effect at the given time
+func (rpa *RatingPlanActivation) activeAt(t time.Time) bool {
+	return !rpa.ActivationTime.After(t) &&
+		(rpa.DeactivationTime.IsZero() || rpa.DeactivationTime.After(t))
 }
 
 func (rpa *RatingPlanActivation) Equal(orpa *RatingPlanActivation) bool {
-	return rpa.ActivationTime == orpa.ActivationTime && rpa.RatingPlanId == orpa.RatingPlanId
+	return rpa.ActivationTime == orpa.ActivationTime && rpa.RatingPlanId == orpa.RatingPlanId &&
+		rpa.DeactivationTime == orpa.DeactivationTime && rpa.MaxCost == orpa.MaxCost &&
+		rpa.MaxCostStrategy == orpa.MaxCostStrategy && rpa.TrafficShare == orpa.TrafficShare
 }
 
 type RatingPlanActivations []*RatingPlanActivation
@@ -64,6 +78,7 @@ func (rpas RatingPlanActivations) Sort() {
 
 func (rpas RatingPlanActivations) GetActiveForCall(cd *CallDescriptor) RatingPlanActivations {
 	rpas.Sort()
+	rpas = rpas.selectVariants(cd)
 	lastBeforeCallStart := 0
 	firstAfterCallEnd := len(rpas)
 	for index, rpa := range rpas {
@@ -75,47 +90,163 @@ func (rpas RatingPlanActivations) GetActiveForCall(cd *CallDescriptor) RatingPla
 			break
 		}
 	}
-	return rpas[lastBeforeCallStart:firstAfterCallEnd]
+	active := rpas[lastBeforeCallStart:firstAfterCallEnd]
+	for _, rpa := range rpas[:firstAfterCallEnd] {
+		if !rpa.DeactivationTime.IsZero() {
+			return rpas[:firstAfterCallEnd].activePeriods(cd)
+		}
+	}
+	return active
+}
+
+// hasVariants reports whether any of the activations is A/B tested
+func (rpas RatingPlanActivations) hasVariants() bool {
+	for _, rpa := range rpas {
+		if rpa.TrafficShare > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// selectVariants keeps one activation out of the ones starting at the same time: a variant with TrafficShare
+// for that percentage of the accounts, hashed so an account always gets the same one, the control activation for the rest
+func (rpas RatingPlanActivations) selectVariants(cd *CallDescriptor) RatingPlanActivations {
+	if !rpas.hasVariants() {
+		return rpas
+	}
+	h := fnv.New32a()
+	h.Write([]byte(cd.GetAccountKey()))
+	bucket := float64(h.Sum32()%10000) / 100
+	var selected RatingPlanActivations
+	for i := 0; i < len(rpas); {
+		j := i + 1
+		for j < len(rpas) && rpas[j].ActivationTime.Equal(rpas[i].ActivationTime) {
+			j++
+		}
+		group := make(RatingPlanActivations, j-i)
+		copy(group, rpas[i:j])
+		i = j
+		if !group.hasVariants() { // not tested
+			selected = append(selected, group...)
+			continue
+		}
+		sort.Slice(group, func(k, l int) bool { return group[k].RatingPlanId < group[l].RatingPlanId })
+		chosen := group[len(group)-1] // the last variant gets the rest of the accounts without a control activation
+		var share float64
+		for _, rpa := range group {
+			if rpa.TrafficShare == 0 {
+				chosen = rpa
+				continue
+			}
+			if share += rpa.TrafficShare; bucket < share {
+				chosen = rpa
+				break
+			}
+		}
+		rpaVariant := *chosen
+		rpaVariant.variant = chosen.RatingPlanId
+		selected = append(selected, &rpaVariant)
+	}
+	return selected
+}
+
+// activePeriods splits the call on activation and deactivation times, returning for each period a copy of the
+// latest activation still in effect starting at the period start or an activation without RatingPlanId if none is
+func (rpas RatingPlanActivations) activePeriods(cd *CallDescriptor) (periods RatingPlanActivations) {
+	changeTimes := []time.Time{cd.TimeStart}
+	for _, rpa := range rpas {
+		for _, t := range []time.Time{rpa.ActivationTime, rpa.DeactivationTime} {
+			if t.After(cd.TimeStart) && t.Before(cd.TimeEnd) {
+				changeTimes = append(changeTimes, t)
+			}
+		}
+	}
+	sort.Slice(changeTimes, func(i, j int) bool { return changeTimes[i].Before(changeTimes[j]) })
+	var lastActive *RatingPlanActivation
+	for i, t := range changeTimes {
+		if i != 0 && t.Equal(changeTimes[i-1]) {
+			continue
+		}
+		var active *RatingPlanActivation
+		for j := len(rpas) - 1; j >= 0; j-- {
+			if rpas[j].activeAt(t) {
+				active = rpas[j]
+				break
+			}
+		}
+		if i != 0 && active == lastActive {
+			continue
+		}
+		lastActive = active
+		period := &RatingPlanActivation{ActivationTime: t}
+		if active != nil {
+			*period = *active
+			if i != 0 {
+				period.ActivationTime = t
+			}
+		}
+		periods = append(periods, period)
+	}
+	return
 }
 
 type RatingInfo struct {
-	MatchedSubject string
-	RatingPlanId   string
-	MatchedPrefix  string
-	MatchedDestId  string
-	ActivationTime time.Time
-	RateIntervals  RateIntervalList
-	FallbackKeys   []string
+	MatchedSubject  string
+	RatingPlanId    string
+	MatchedPrefix   string
+	MatchedDestId   string
+	ActivationTime  time.Time
+	RateIntervals   RateIntervalList
+	FallbackKeys    []string
+	MaxCost         float64 // cap of the rating profile, applies when MaxCostStrategy is set
+	MaxCostStrategy string
+	RatingVariant   string                   // rating plan of the A/B tested activation, empty if not tested
+	tierUsage       map[string]time.Duration // usage in the billing period of tiered ratings
+	rateTable       *rateTable               // compiled RateIntervals of the matched destination, if any
 }
 
 // SelectRatingIntevalsForTimespan orders rate intervals in time preserving only those which aply to the specified timestamp
 func (ri RatingInfo) SelectRatingIntevalsForTimespan(ts *TimeSpan) (result RateIntervalList) {
+	if ri.rateTable != nil {
+		if result = ri.rateTable.selectForTimespan(ts); result != nil {
+			return
+		}
+	}
 	sorter := &RateIntervalTimeSorter{referenceTime: ts.TimeStart, ris: ri.RateIntervals}
 	rateIntervals := sorter.Sort()
-	// get the rating interval closest to begining of timespan
+	bestRateIntervalIndex, bestIntervalWeight, _ := bestRateInterval(rateIntervals, ts.TimeStart)
+	return selectRateIntervals(rateIntervals, bestRateIntervalIndex, bestIntervalWeight, ts)
+}
+
+// bestRateInterval returns the index of the sorted rate interval closest to begining of timespan,
+// found being false if none of them contains the start of the timespan
+func bestRateInterval(rateIntervals RateIntervalList, tsStart time.Time) (bestRateIntervalIndex int, bestIntervalWeight float64, found bool) {
 	var delta time.Duration = -1
-	var bestRateIntervalIndex int
-	var bestIntervalWeight float64
 	for index, rateInterval := range rateIntervals {
-		if !rateInterval.Contains(ts.TimeStart, false) {
+		if !rateInterval.Contains(tsStart, false) {
 			continue
 		}
 		if rateInterval.Weight < bestIntervalWeight {
 			break // don't consider lower weights'
 		}
-		startTime := rateInterval.Timing.getLeftMargin(ts.TimeStart)
-		tmpDelta := ts.TimeStart.Sub(startTime)
-		if (startTime.Before(ts.TimeStart) ||
-			startTime.Equal(ts.TimeStart)) &&
+		startTime := rateInterval.Timing.getLeftMargin(tsStart)
+		tmpDelta := tsStart.Sub(startTime)
+		if (startTime.Before(tsStart) ||
+			startTime.Equal(tsStart)) &&
 			(delta == -1 || tmpDelta < delta) {
 			bestRateIntervalIndex = index
 			bestIntervalWeight = rateInterval.Weight
 			delta = tmpDelta
 		}
 	}
+	return bestRateIntervalIndex, bestIntervalWeight, delta != -1
+}
+
+// selectRateIntervals returns the best rate interval followed by the later ones influencing the timespan
+func selectRateIntervals(rateIntervals RateIntervalList, bestRateIntervalIndex int, bestIntervalWeight float64, ts *TimeSpan) (result RateIntervalList) {
 	result = append(result, rateIntervals[bestRateIntervalIndex])
 	// check if later rating intervals influence this timespan
-	//log.Print("RIS: ", utils.ToIJSON(rateIntervals))
 	for i := bestRateIntervalIndex + 1; i < len(rateIntervals); i++ {
 		if rateIntervals[i].Weight < bestIntervalWeight {
 			break // don't consider lower weights'
@@ -152,9 +283,24 @@ func (ris RatingInfos) String() string {
 }
 
 func (rpf *RatingProfile) GetRatingPlansForPrefix(cd *CallDescriptor) (err error) {
+	rdg, err := cd.ratingData()
+	if err != nil {
+		return
+	}
 	var ris RatingInfos
 	for index, rpa := range rpf.RatingPlanActivations.GetActiveForCall(cd) {
-		rpl, err := dataStorage.GetRatingPlan(rpa.RatingPlanId, false, utils.NonTransactional)
+		if rpa.RatingPlanId == "" { // deactivated period, not covered by this profile
+			var fbKeys []string
+			if fbKey := cd.GetKey(FALLBACK_SUBJECT); fbKey != rpf.Id {
+				fbKeys = []string{fbKey}
+			}
+			ris = append(ris, &RatingInfo{
+				ActivationTime: rpa.ActivationTime,
+				FallbackKeys:   fbKeys,
+			})
+			continue
+		}
+		rpl, err := rdg.GetRatingPlan(rpa.RatingPlanId, false, utils.NonTransactional)
 		if err != nil || rpl == nil {
 			utils.Logger.Err(fmt.Sprintf("Error checking destination: %v", err))
 			continue
@@ -170,19 +316,17 @@ func (rpf *RatingProfile) GetRatingPlansForPrefix(cd *CallDescriptor) (err error
 				destinationId = utils.ANY
 			}
 		} else {
-			for _, p := range utils.SplitPrefix(cd.Destination, MIN_PREFIX_MATCH) {
-				if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-					var bestWeight float64
-					for _, dID := range destIDs {
-						if _, ok := rpl.DestinationRates[dID]; ok {
-							ril := rpl.RateIntervalList(dID)
-							currentWeight := ril.GetWeight()
-							if currentWeight > bestWeight {
-								bestWeight = currentWeight
-								rps = ril
-								prefix = p
-								destinationId = dID
-							}
+			for _, dm := range matchReverseDestinations(rdg, cd.Destination) {
+				var bestWeight float64
+				for _, dID := range dm.DestIDs {
+					if _, ok := rpl.DestinationRates[dID]; ok {
+						ril := rpl.RateIntervalList(dID)
+						currentWeight := ril.GetWeight()
+						if currentWeight > bestWeight {
+							bestWeight = currentWeight
+							rps = ril
+							prefix = utils.DestinationKeyPrefix(dm.Prefix)
+							destinationId = dID
 						}
 					}
 				}
@@ -210,13 +354,17 @@ func (rpf *RatingProfile) GetRatingPlansForPrefix(cd *CallDescriptor) (err error
 		}
 		if len(prefix) > 0 {
 			ris = append(ris, &RatingInfo{
-				MatchedSubject: rpf.Id,
-				RatingPlanId:   rpl.Id,
-				MatchedPrefix:  prefix,
-				MatchedDestId:  destinationId,
-				ActivationTime: rpa.ActivationTime,
-				RateIntervals:  rps,
-				FallbackKeys:   rpa.FallbackKeys})
+				MatchedSubject:  rpf.Id,
+				RatingPlanId:    rpl.Id,
+				MatchedPrefix:   prefix,
+				MatchedDestId:   destinationId,
+				ActivationTime:  rpa.ActivationTime,
+				RateIntervals:   rps,
+				rateTable:       rpl.rateTable(destinationId),
+				FallbackKeys:    rpa.FallbackKeys,
+				MaxCost:         rpa.MaxCost,
+				MaxCostStrategy: rpa.MaxCostStrategy,
+				RatingVariant:   rpa.variant})
 		} else {
 			// add for fallback information
 			if len(rpa.FallbackKeys) > 0 {
@@ -255,10 +403,14 @@ type TenantRatingSubject struct {
 }
 
 func RatingProfileSubjectPrefixMatching(key string) (rp *RatingProfile, err error) {
-	if !rpSubjectPrefixMatching || strings.HasSuffix(key, utils.ANY) {
-		return dataStorage.GetRatingProfile(key, false, utils.NonTransactional)
+	return ratingProfileSubjectPrefixMatching(dataStorage, key)
+}
+
+func ratingProfileSubjectPrefixMatching(rdg ratingDataGetter, key string) (rp *RatingProfile, err error) {
+	if !rpSubjectPrefixMatching || strings.HasSuffix(key, utils.ANY) || strings.HasSuffix(key, utils.MASK_CHAR) {
+		return rdg.GetRatingProfile(key, false, utils.NonTransactional)
 	}
-	if rp, err = dataStorage.GetRatingProfile(key, false, utils.NonTransactional); err == nil && rp != nil { // rp nil represents cached no-result
+	if rp, err = rdg.GetRatingProfile(key, false, utils.NonTransactional); err == nil && rp != nil { // rp nil represents cached no-result
 		return
 	}
 	lastIndex := strings.LastIndex(key, utils.CONCATENATED_KEY_SEP)
@@ -266,9 +418,24 @@ func RatingProfileSubjectPrefixMatching(key string) (rp *RatingProfile, err erro
 	subject := key[lastIndex:]
 	lenSubject := len(subject)
 	for i := 1; i < lenSubject-1; i++ {
-		if rp, err = dataStorage.GetRatingProfile(baseKey+subject[:lenSubject-i], false, utils.NonTransactional); err == nil && rp != nil {
+		if rp, err = rdg.GetRatingProfile(baseKey+subject[:lenSubject-i], false, utils.NonTransactional); err == nil && rp != nil {
 			return
 		}
 	}
 	return
 }
+
+// ratingProfileSubjectWildcards returns the wildcard keys (eg: premium_*) able to match the subject of the key,
+// ordered from the most specific (longest literal prefix) to the least one
+func ratingProfileSubjectWildcards(key string) (wcKeys []string) {
+	lastIndex := strings.LastIndex(key, utils.CONCATENATED_KEY_SEP)
+	baseKey := key[:lastIndex+1]
+	subject := key[lastIndex+1:]
+	if subject == "" || subject == utils.ANY || strings.HasSuffix(subject, utils.MASK_CHAR) {
+		return
+	}
+	for i := len(subject); i > 0; i-- {
+		wcKeys = append(wcKeys, baseKey+subject[:i]+utils.MASK_CHAR)
+	}
+	return
+}
@@ -18,6 +18,8 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -399,3 +401,111 @@ func TestRatingProfileSubjectPrefixMatching(t *testing.T) {
 	}
 	rpSubjectPrefixMatching = false
 }
+
+func TestRatingProfileSubjectWildcards(t *testing.T) {
+	eKeys := []string{"*out:vdf:0:prem*", "*out:vdf:0:pre*", "*out:vdf:0:pr*", "*out:vdf:0:p*"}
+	if rcv := ratingProfileSubjectWildcards("*out:vdf:0:prem"); !reflect.DeepEqual(eKeys, rcv) {
+		t.Errorf("Expecting: %+v, received: %+v", eKeys, rcv)
+	}
+	if rcv := ratingProfileSubjectWildcards("*out:vdf:0:" + utils.ANY); len(rcv) != 0 {
+		t.Errorf("Unexpected keys: %+v", rcv)
+	}
+	if rcv := ratingProfileSubjectWildcards("*out:vdf:0:prem*"); len(rcv) != 0 {
+		t.Errorf("Unexpected keys: %+v", rcv)
+	}
+}
+
+func TestRatingProfileSubjectWildcardMatching(t *testing.T) {
+	for _, subj := range []string{"premium_*", "prem*"} {
+		rpf, err := dataStorage.GetRatingProfile("*out:vdf:0:rif", false, utils.NonTransactional)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wcRpf := *rpf
+		wcRpf.Id = "*out:vdf:0:" + subj
+		if err := dataStorage.SetRatingProfile(&wcRpf, utils.NonTransactional); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cd := &CallDescriptor{
+		TimeStart:   time.Date(2015, 8, 18, 22, 05, 0, 0, time.UTC),
+		TimeEnd:     time.Date(2015, 8, 18, 22, 06, 30, 0, time.UTC),
+		Tenant:      "vdf",
+		Category:    "0",
+		Direction:   utils.OUT,
+		Subject:     "premium_gold",
+		Destination: "0256098",
+	}
+	if err := cd.LoadRatingPlans(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cd.RatingInfos) == 0 || cd.RatingInfos[0].MatchedSubject != "*out:vdf:0:premium_*" {
+		t.Errorf("Unexpected rating infos: %+v", cd.RatingInfos)
+	}
+	cd.Subject = "standard_gold"
+	cd.RatingInfos = nil
+	if err := cd.LoadRatingPlans(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cd.RatingInfos) == 0 || cd.RatingInfos[0].MatchedSubject != "*out:vdf:0:*any" {
+		t.Errorf("Unexpected rating infos: %+v", cd.RatingInfos)
+	}
+}
+
+func TestRatingProfileActivationsDeactivationTime(t *testing.T) {
+	std := &RatingPlanActivation{ActivationTime: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), RatingPlanId: "RP_STANDARD"}
+	promo := &RatingPlanActivation{ActivationTime: time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC), RatingPlanId: "RP_PROMO",
+		DeactivationTime: time.Date(2017, 6, 30, 0, 0, 0, 0, time.UTC)}
+	rpas := RatingPlanActivations{promo, std}
+	// call crossing the end of the promotion continues on the standard plan
+	cd := &CallDescriptor{TimeStart: time.Date(2017, 6, 29, 23, 59, 0, 0, time.UTC), TimeEnd: time.Date(2017, 6, 30, 0, 1, 0, 0, time.UTC)}
+	active := rpas.GetActiveForCall(cd)
+	if len(active) != 2 || active[0].RatingPlanId != "RP_PROMO" || !active[0].ActivationTime.Equal(promo.ActivationTime) ||
+		active[1].RatingPlanId != "RP_STANDARD" || !active[1].ActivationTime.Equal(promo.DeactivationTime) {
+		t.Error("Wrong activations: ", utils.ToJSON(active))
+	}
+	// call after the promotion
+	cd = &CallDescriptor{TimeStart: time.Date(2017, 7, 2, 10, 0, 0, 0, time.UTC), TimeEnd: time.Date(2017, 7, 2, 10, 1, 0, 0, time.UTC)}
+	if active = rpas.GetActiveForCall(cd); len(active) != 1 || active[0].RatingPlanId != "RP_STANDARD" ||
+		!active[0].ActivationTime.Equal(std.ActivationTime) {
+		t.Error("Wrong activations: ", utils.ToJSON(active))
+	}
+	// nothing left active once the only activation expired
+	cd = &CallDescriptor{TimeStart: time.Date(2017, 7, 2, 10, 0, 0, 0, time.UTC), TimeEnd: time.Date(2017, 7, 2, 10, 1, 0, 0, time.UTC)}
+	if active = (RatingPlanActivations{promo}).GetActiveForCall(cd); len(active) != 1 ||
+		active[0].RatingPlanId != "" || !active[0].ActivationTime.Equal(cd.TimeStart) {
+		t.Error("Wrong activations: ", utils.ToJSON(active))
+	}
+}
+
+func TestRatingProfileActivationsTrafficShare(t *testing.T) {
+	aTime := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	rpas := RatingPlanActivations{
+		&RatingPlanActivation{ActivationTime: aTime, RatingPlanId: "RP_CONTROL"},
+		&RatingPlanActivation{ActivationTime: aTime, RatingPlanId: "RP_VARIANT", TrafficShare: 30},
+	}
+	variants := 0
+	for i := 0; i < 1000; i++ {
+		cd := &CallDescriptor{Tenant: "cgrates.org", Account: "acc" + strconv.Itoa(i),
+			TimeStart: time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC), TimeEnd: time.Date(2017, 6, 1, 10, 1, 0, 0, time.UTC)}
+		active := rpas.GetActiveForCall(cd)
+		if len(active) != 1 || active[0].variant != active[0].RatingPlanId {
+			t.Fatal("Wrong activations: ", utils.ToJSON(active))
+		}
+		if again := rpas.GetActiveForCall(cd); again[0].RatingPlanId != active[0].RatingPlanId {
+			t.Fatalf("Account %s switched from %s to %s", cd.Account, active[0].RatingPlanId, again[0].RatingPlanId)
+		}
+		if active[0].RatingPlanId == "RP_VARIANT" {
+			variants++
+		}
+	}
+	if variants < 250 || variants > 350 {
+		t.Errorf("Expecting about 300 accounts on the variant, got: %d", variants)
+	}
+	// activations without TrafficShare are not marked as variants
+	cd := &CallDescriptor{Tenant: "cgrates.org", Account: "acc1",
+		TimeStart: time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC), TimeEnd: time.Date(2017, 6, 1, 10, 1, 0, 0, time.UTC)}
+	if active := rpas[:1].GetActiveForCall(cd); len(active) != 1 || active[0].variant != "" {
+		t.Error("Wrong activations: ", utils.ToJSON(active))
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+	"github.com/cgrates/rpcclient"
+)
+
+var replicaFeeder *ReplicaFeeder
+
+// SetReplicaFeeder sets the feeder replicating the rated CDRs and the account changes, nil disables replication
+func SetReplicaFeeder(rf *ReplicaFeeder) {
+	replicaFeeder = rf
+}
+
+// ReplicaBatch is the data sent in one go towards the read-only replicas
+type ReplicaBatch struct {
+	CDRs     []*CDR
+	Accounts []*ReplicaAccount
+}
+
+// ReplicaAccount is an account state versioned so the replicas never overwrite a newer one
+type ReplicaAccount struct {
+	Sequence uint64 // increasing with each state queued by the primary
+	Account  *Account
+}
+
+// NewReplicaFeeder creates a feeder sending batches each interval, 0 interval sends each update as it happens
+func NewReplicaFeeder(conns rpcclient.RpcClientConnection, interval time.Duration) *ReplicaFeeder {
+	rf := &ReplicaFeeder{conns: conns, interval: interval, accounts: make(map[string]*ReplicaAccount),
+		sequence: uint64(time.Now().UnixNano())} // keeps increasing over the primary restarts
+	if interval > 0 {
+		go rf.loop()
+	}
+	return rf
+}
+
+// ReplicaFeeder queues the primary updates and feeds them to the replicas over ReplicaV1.Replicate
+type ReplicaFeeder struct {
+	conns    rpcclient.RpcClientConnection
+	interval time.Duration
+	flushMux sync.Mutex // one batch in flight at a time, keeping the updates in order
+	mu       sync.Mutex
+	cdrs     []*CDR
+	accounts map[string]*ReplicaAccount // only the last state of each account is sent
+	sequence uint64
+}
+
+func (rf *ReplicaFeeder) loop() {
+	for range time.Tick(rf.interval) {
+		if err := rf.Flush(); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<ReplicaFeeder> Failed replicating, error: %s", err.Error()))
+		}
+	}
+}
+
+// QueueCDRs schedules copies of the CDRs for replication
+func (rf *ReplicaFeeder) QueueCDRs(cdrs []*CDR) {
+	rf.mu.Lock()
+	for _, cdr := range cdrs {
+		rf.cdrs = append(rf.cdrs, cdr.Clone())
+	}
+	rf.mu.Unlock()
+	rf.flushIfNoInterval()
+}
+
+// QueueAccount schedules a copy of the account state for replication
+func (rf *ReplicaFeeder) QueueAccount(acc *Account) {
+	rf.mu.Lock()
+	rf.sequence++
+	rf.accounts[acc.ID] = &ReplicaAccount{Sequence: rf.sequence, Account: acc.Clone()}
+	rf.mu.Unlock()
+	rf.flushIfNoInterval()
+}
+
+func (rf *ReplicaFeeder) flushIfNoInterval() {
+	if rf.interval != 0 {
+		return
+	}
+	go func() {
+		if err := rf.Flush(); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<ReplicaFeeder> Failed replicating, error: %s", err.Error()))
+		}
+	}()
+}
+
+// pendingBatch takes out the queued updates
+func (rf *ReplicaFeeder) pendingBatch() *ReplicaBatch {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	batch := &ReplicaBatch{CDRs: rf.cdrs}
+	for _, acc := range rf.accounts {
+		batch.Accounts = append(batch.Accounts, acc)
+	}
+	rf.cdrs = nil
+	rf.accounts = make(map[string]*ReplicaAccount)
+	return batch
+}
+
+// requeue puts back a batch which failed, accounts updated in the meantime keep their newer state
+func (rf *ReplicaFeeder) requeue(batch *ReplicaBatch) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.cdrs = append(batch.CDRs, rf.cdrs...)
+	for _, acc := range batch.Accounts {
+		if pending, has := rf.accounts[acc.Account.ID]; !has || pending.Sequence < acc.Sequence {
+			rf.accounts[acc.Account.ID] = acc
+		}
+	}
+}
+
+// Flush sends the queued updates to the replicas, keeping them queued on errors
+func (rf *ReplicaFeeder) Flush() error {
+	rf.flushMux.Lock()
+	defer rf.flushMux.Unlock()
+	batch := rf.pendingBatch()
+	if len(batch.CDRs) == 0 && len(batch.Accounts) == 0 {
+		return nil
+	}
+	var reply string
+	if err := rf.conns.Call(utils.ReplicaV1Replicate, batch, &reply); err != nil {
+		rf.requeue(batch)
+		return err
+	}
+	return nil
+}
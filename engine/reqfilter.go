@@ -166,13 +166,11 @@ func (fltr *RequestFilter) passDestinations(req interface{}, extraFieldsLabel st
 		}
 		return false, err
 	}
-	for _, p := range utils.SplitPrefix(dst, MIN_PREFIX_MATCH) {
-		if destIDs, err := dataStorage.GetReverseDestination(p, false, utils.NonTransactional); err == nil {
-			for _, dID := range destIDs {
-				for _, valDstID := range fltr.Values {
-					if valDstID == dID {
-						return true, nil
-					}
+	for _, dm := range matchReverseDestinations(dataStorage, dst) {
+		for _, dID := range dm.DestIDs {
+			for _, valDstID := range fltr.Values {
+				if valDstID == dID {
+					return true, nil
 				}
 			}
 		}
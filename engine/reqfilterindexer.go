@@ -83,3 +83,22 @@ func (rfi *ReqFilterIndexer) IndexFilters(itemID string, reqFltrs []*RequestFilt
 func (rfi *ReqFilterIndexer) StoreIndexes() error {
 	return rfi.dataDB.SetReqFilterIndexes(rfi.dbKey, rfi.indexes)
 }
+
+// RemoveItemFromIndex removes itemID from all the indexes, marking the changed keys in chngdIndxKeys
+func (rfi *ReqFilterIndexer) RemoveItemFromIndex(itemID string) {
+	for fldName, fldValIdx := range rfi.indexes {
+		for fldVal, itemIDs := range fldValIdx {
+			if _, hasIt := itemIDs[itemID]; !hasIt {
+				continue
+			}
+			delete(itemIDs, itemID)
+			rfi.chngdIndxKeys[utils.ConcatenatedKey(fldName, fldVal)] = true
+			if len(itemIDs) == 0 {
+				delete(fldValIdx, fldVal)
+			}
+		}
+		if len(fldValIdx) == 0 {
+			delete(rfi.indexes, fldName)
+		}
+	}
+}
@@ -170,22 +170,115 @@ func NewResourceLimiterService(cfg *config.CGRConfig, dataDB DataDB, cdrStatS rp
 	if cdrStatS != nil && reflect.ValueOf(cdrStatS).IsNil() {
 		cdrStatS = nil
 	}
-	return &ResourceLimiterService{dataDB: dataDB, cdrStatS: cdrStatS}, nil
+	rls := &ResourceLimiterService{dataDB: dataDB, cdrStatS: cdrStatS,
+		usageSeries: make(map[string]*ResourceUsageSeries), stopSampling: make(chan struct{})}
+	if cfg != nil && cfg.ResourceLimiterCfg() != nil {
+		rls.samplesInterval = cfg.ResourceLimiterCfg().UsageSamplesInterval
+		rls.samplesSize = cfg.ResourceLimiterCfg().UsageSamplesSize
+		rls.dumpInterval = cfg.ResourceLimiterCfg().CacheDumpInterval
+	}
+	return rls, nil
 }
 
 // ResourcesLimiter is the service handling channel limits
 type ResourceLimiterService struct {
-	dataDB   DataDB // So we can load the data in cache and index it
-	cdrStatS rpcclient.RpcClientConnection
+	dataDB          DataDB // So we can load the data in cache and index it
+	cdrStatS        rpcclient.RpcClientConnection
+	samplesInterval time.Duration                   // utilization sampling interval, 0 disables sampling
+	samplesSize     int                             // samples kept per ResourceLimit
+	dumpInterval    time.Duration                   // persist usageSeries into dataDB, 0 only at shutdown
+	usageSeries     map[string]*ResourceUsageSeries // utilization time series indexed on ResourceLimit ID
+	seriesMux       sync.RWMutex
+	stopSampling    chan struct{}
 }
 
 // Called to start the service
 func (rls *ResourceLimiterService) ListenAndServe() error {
+	if rls.samplesInterval > 0 {
+		go rls.runUsageSampling()
+	}
 	return nil
 }
 
 // Called to shutdown the service
 func (rls *ResourceLimiterService) ServiceShutdown() error {
+	if rls.samplesInterval <= 0 {
+		return nil
+	}
+	close(rls.stopSampling)
+	return rls.dumpUsageSeries()
+}
+
+// runUsageSampling samples the ResourceLimits and persists the series until the service is shut down
+func (rls *ResourceLimiterService) runUsageSampling() {
+	sampleTicker := time.NewTicker(rls.samplesInterval)
+	defer sampleTicker.Stop()
+	var dumpTick <-chan time.Time
+	if rls.dumpInterval > 0 {
+		dumpTicker := time.NewTicker(rls.dumpInterval)
+		defer dumpTicker.Stop()
+		dumpTick = dumpTicker.C
+	}
+	for {
+		select {
+		case <-rls.stopSampling:
+			return
+		case t := <-sampleTicker.C:
+			if err := rls.sampleUsage(t); err != nil {
+				utils.Logger.Warning(fmt.Sprintf("<RLs> Failed sampling resource usage, error: %s", err.Error()))
+			}
+		case <-dumpTick:
+			if err := rls.dumpUsageSeries(); err != nil {
+				utils.Logger.Warning(fmt.Sprintf("<RLs> Failed dumping resource usage series, error: %s", err.Error()))
+			}
+		}
+	}
+}
+
+// sampleUsage adds one utilization sample for each ResourceLimit in dataDB
+func (rls *ResourceLimiterService) sampleUsage(t time.Time) error {
+	keys, err := rls.dataDB.GetKeysForPrefix(utils.ResourceLimitsPrefix)
+	if err != nil {
+		return err
+	}
+	rls.seriesMux.Lock()
+	defer rls.seriesMux.Unlock()
+	for _, key := range keys {
+		rlID := key[len(utils.ResourceLimitsPrefix):]
+		rl, err := rls.dataDB.GetResourceLimit(rlID, false, utils.NonTransactional)
+		if err != nil {
+			if err == utils.ErrNotFound { // removed in the meantime
+				continue
+			}
+			return err
+		}
+		rl.Lock()
+		smpl := &ResourceUsageSample{Time: t, Usage: rl.UsedUnits(), Limit: rl.Limit}
+		rl.Unlock()
+		rus, hasIt := rls.usageSeries[rlID]
+		if !hasIt {
+			if rus, err = rls.dataDB.GetResourceUsageSeries(rlID); err != nil {
+				if err != utils.ErrNotFound {
+					return err
+				}
+				rus = &ResourceUsageSeries{ID: rlID}
+			}
+			rls.usageSeries[rlID] = rus
+		}
+		rus.AddSample(smpl, rls.samplesSize)
+	}
+	return nil
+}
+
+// dumpUsageSeries persists the utilization series into dataDB
+func (rls *ResourceLimiterService) dumpUsageSeries() error {
+	rls.seriesMux.RLock()
+	defer rls.seriesMux.RUnlock()
+	for _, rus := range rls.usageSeries {
+		if err := rls.dataDB.SetResourceUsageSeries(rus); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -320,3 +413,43 @@ func (rls *ResourceLimiterService) V1ReleaseResource(attrs utils.AttrRLsResource
 	*reply = utils.OK
 	return nil
 }
+
+// V1GetResourceUsageSeries returns the utilization samples of a ResourceLimit, oldest first
+func (rls *ResourceLimiterService) V1GetResourceUsageSeries(args utils.AttrRLsUsageSeries, reply *[]*ResourceUsageSample) error {
+	if missing := utils.MissingStructFields(&args, []string{"ID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	var tStart time.Time
+	if args.TimeStart != "" {
+		var err error
+		if tStart, err = utils.ParseTimeDetectLayout(args.TimeStart, ""); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	}
+	rls.seriesMux.RLock()
+	rus, hasIt := rls.usageSeries[args.ID]
+	var smpls []*ResourceUsageSample
+	if hasIt {
+		smpls = rus.OrderedSamples()
+	}
+	rls.seriesMux.RUnlock()
+	if !hasIt { // sampling might be disabled on this node, fallback on what was persisted
+		var err error
+		if rus, err = rls.dataDB.GetResourceUsageSeries(args.ID); err != nil {
+			if err == utils.ErrNotFound {
+				return err
+			}
+			return utils.NewErrServerError(err)
+		}
+		smpls = rus.OrderedSamples()
+	}
+	retSmpls := make([]*ResourceUsageSample, 0, len(smpls))
+	for _, smpl := range smpls {
+		if smpl.Time.Before(tStart) {
+			continue
+		}
+		retSmpls = append(retSmpls, smpl)
+	}
+	*reply = retSmpls
+	return nil
+}
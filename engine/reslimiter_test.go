@@ -18,6 +18,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -291,3 +292,63 @@ func TestRLsAllocateResource(t *testing.T) {
 		t.Error("Duplicate ResourceUsage id should not be allowed")
 	}
 }
+
+func TestResourceUsageSeriesAddSample(t *testing.T) {
+	tm := time.Date(2017, 5, 1, 10, 0, 0, 0, time.UTC)
+	rus := &ResourceUsageSeries{ID: "RL1"}
+	for i := 0; i < 5; i++ {
+		rus.AddSample(&ResourceUsageSample{Time: tm.Add(time.Duration(i) * time.Minute), Usage: float64(i)}, 3)
+	}
+	if len(rus.Samples) != 3 || rus.Next != 2 {
+		t.Errorf("Unexpected series: %+v", rus)
+	}
+	var usages []float64
+	for _, smpl := range rus.OrderedSamples() {
+		usages = append(usages, smpl.Usage)
+	}
+	if eUsages := []float64{2, 3, 4}; !reflect.DeepEqual(eUsages, usages) {
+		t.Errorf("Expecting: %v, received: %v", eUsages, usages)
+	}
+	rus.AddSample(&ResourceUsageSample{Time: tm.Add(5 * time.Minute), Usage: 5}, 2) // shrink
+	usages = nil
+	for _, smpl := range rus.OrderedSamples() {
+		usages = append(usages, smpl.Usage)
+	}
+	if eUsages := []float64{4, 5}; !reflect.DeepEqual(eUsages, usages) {
+		t.Errorf("Expecting: %v, received: %v", eUsages, usages)
+	}
+}
+
+func TestRLsUsageSeries(t *testing.T) {
+	dataDB, _ := NewMapStorage()
+	rl := &ResourceLimit{ID: "RL_SAMPLED", Limit: 10, Usage: make(map[string]*ResourceUsage)}
+	if err := dataDB.SetResourceLimit(rl, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	rlS := &ResourceLimiterService{dataDB: dataDB, samplesSize: 2,
+		usageSeries: make(map[string]*ResourceUsageSeries), stopSampling: make(chan struct{})}
+	tm := time.Date(2017, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := rlS.sampleUsage(tm.Add(time.Duration(i) * time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var smpls []*ResourceUsageSample
+	if err := rlS.V1GetResourceUsageSeries(utils.AttrRLsUsageSeries{ID: "RL_SAMPLED",
+		TimeStart: "2017-05-01T10:02:00Z"}, &smpls); err != nil {
+		t.Error(err)
+	} else if eSmpls := []*ResourceUsageSample{{Time: tm.Add(2 * time.Minute), Limit: 10}}; !reflect.DeepEqual(eSmpls, smpls) {
+		t.Errorf("Expecting: %+v, received: %+v", eSmpls[0], smpls)
+	}
+	if err := rlS.dumpUsageSeries(); err != nil {
+		t.Error(err)
+	}
+	if rus, err := dataDB.GetResourceUsageSeries("RL_SAMPLED"); err != nil {
+		t.Error(err)
+	} else if len(rus.Samples) != 2 {
+		t.Errorf("Unexpected persisted series: %+v", rus)
+	}
+	if err := rlS.V1GetResourceUsageSeries(utils.AttrRLsUsageSeries{ID: "RL_MISSING"}, &smpls); err != utils.ErrNotFound {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"time"
+)
+
+// ResourceUsageSample is one utilization snapshot of a ResourceLimit
+type ResourceUsageSample struct {
+	Time  time.Time
+	Usage float64 // units used at sampling time
+	Limit float64 // limit configured at sampling time
+}
+
+// ResourceUsageSeries keeps the latest utilization samples of a ResourceLimit in a ring buffer
+type ResourceUsageSeries struct {
+	ID      string                 // ResourceLimit ID
+	Samples []*ResourceUsageSample // ring buffer, oldest sample at Next once full
+	Next    int                    // position to be overwritten by the next sample
+}
+
+// AddSample stores the sample, dropping the oldest one when more than size samples are kept
+func (rus *ResourceUsageSeries) AddSample(smpl *ResourceUsageSample, size int) {
+	if size <= 0 {
+		return
+	}
+	if len(rus.Samples) > size || (len(rus.Samples) < size && rus.Next != 0) { // size changed, rebuild the buffer in order
+		smpls := rus.OrderedSamples()
+		if len(smpls) > size {
+			smpls = smpls[len(smpls)-size:]
+		}
+		rus.Samples, rus.Next = smpls, 0
+	}
+	if len(rus.Samples) < size {
+		rus.Samples = append(rus.Samples, smpl)
+		return
+	}
+	rus.Samples[rus.Next] = smpl
+	rus.Next = (rus.Next + 1) % size
+}
+
+// OrderedSamples returns the samples from oldest to newest
+func (rus *ResourceUsageSeries) OrderedSamples() (smpls []*ResourceUsageSample) {
+	smpls = make([]*ResourceUsageSample, 0, len(rus.Samples))
+	smpls = append(smpls, rus.Samples[rus.Next:]...)
+	return append(smpls, rus.Samples[:rus.Next]...)
+}
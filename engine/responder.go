@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/cgrates/cgrates/cache"
@@ -69,7 +70,7 @@ func (rs *Responder) getCache() *cache.ResponseCache {
 RPC method thet provides the external RPC interface for getting the rating information.
 */
 func (rs *Responder) GetCost(arg *CallDescriptor, reply *CallCost) (err error) {
-	rs.cnt += 1
+	atomic.AddInt64(&rs.cnt, 1)
 	if arg.Subject == "" {
 		arg.Subject = arg.Account
 	}
@@ -403,16 +404,16 @@ func (rs *Responder) GetSessionRuns(ev *CDR, sRuns *[]*SessionRun) error {
 		return err
 	}
 	// replace aliases
-	if err := LoadAlias(
-		&AttrMatchingAlias{
-			Destination: ev.Destination,
-			Direction:   ev.Direction,
-			Tenant:      ev.Tenant,
-			Category:    ev.Category,
-			Account:     ev.Account,
-			Subject:     ev.Subject,
-			Context:     utils.ALIAS_CONTEXT_RATING,
-		}, ev, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
+	aliasAttr := &AttrMatchingAlias{
+		Destination: ev.Destination,
+		Direction:   ev.Direction,
+		Tenant:      ev.Tenant,
+		Category:    ev.Category,
+		Account:     ev.Account,
+		Subject:     ev.Subject,
+		Context:     utils.ALIAS_CONTEXT_RATING,
+	}
+	if err := LoadAlias(aliasAttr, ev, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
 		return err
 	}
 
@@ -429,11 +430,20 @@ func (rs *Responder) GetSessionRuns(ev *CDR, sRuns *[]*SessionRun) error {
 	}
 	dcs, _ = dcs.AppendDefaultRun()
 	//utils.Logger.Info(fmt.Sprintf("DCS: %v", len(dcs.Chargers)))
+	extraFields := ev.GetExtraFields() // before resolving references so these do not end up in the CallDescriptors
+	ev, err := ResolveDerivedChargersReferences(ev, dcs.Chargers, aliasAttr)
+	if err != nil {
+		return err
+	}
 	sesRuns := make([]*SessionRun, 0)
 	for _, dc := range dcs.Chargers {
 		if !utils.IsSliceMember([]string{utils.META_PREPAID, utils.PREPAID}, ev.GetReqType(dc.RequestTypeField)) {
 			continue // We only consider prepaid sessions
 		}
+		if dc.SplitChargingID != "" {
+			rs.getCache().Cache(cacheKey, &cache.CacheItem{Err: utils.ErrSplitChargingPrepaid})
+			return utils.ErrSplitChargingPrepaid
+		}
 		startTime, err := ev.GetAnswerTime(dc.AnswerTimeField, rs.Timezone)
 		if err != nil || startTime.IsZero() { // AnswerTime not parsable, try SetupTime
 			startTime, err = ev.GetSetupTime(dc.SetupTimeField, rs.Timezone)
@@ -447,7 +457,6 @@ func (rs *Responder) GetSessionRuns(ev *CDR, sRuns *[]*SessionRun) error {
 			rs.getCache().Cache(cacheKey, &cache.CacheItem{Err: err})
 			return errors.New("Error parsing answer event end time")
 		}
-		extraFields := ev.GetExtraFields()
 		cd := &CallDescriptor{
 			CgrID:       ev.GetCgrId(rs.Timezone),
 			RunID:       dc.RunID,
@@ -466,6 +475,10 @@ func (rs *Responder) GetSessionRuns(ev *CDR, sRuns *[]*SessionRun) error {
 			if _, hasFD := flags[utils.FlagForceDuration]; hasFD {
 				cd.ForceDuration = true
 			}
+			if _, hasDebug := flags[utils.FlagDebug]; hasDebug {
+				cd.Debug = true
+				cd.OriginID = ev.OriginID
+			}
 		}
 		sesRuns = append(sesRuns, &SessionRun{DerivedCharger: dc, CallDescriptor: cd})
 	}
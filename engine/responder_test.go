@@ -167,6 +167,17 @@ func TestResponderGetSessionRuns(t *testing.T) {
 		}
 		t.Errorf("Expecting: %+v, received: %+v", eSRuns, sesRuns)
 	}
+	// split charging is not applied by the sessions
+	splitDC := *extra1DC
+	splitDC.SplitChargingID = "SPC_CORP"
+	if err := dataStorage.SetDerivedChargers(utils.ConcatenatedKey("*out", testTenant, "call", "dan3", "dan3"),
+		&utils.DerivedChargers{Chargers: []*utils.DerivedCharger{&splitDC}}, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	cdr.CGRID, cdr.Account, cdr.Subject = utils.Sha1("splitprepaid"), "dan3", "dan3"
+	if err := rsponder.GetSessionRuns(cdr, &sesRuns); err != utils.ErrSplitChargingPrepaid {
+		t.Errorf("Expecting %v, received: %v", utils.ErrSplitChargingPrepaid, err)
+	}
 }
 
 func TestResponderGetLCR(t *testing.T) {
@@ -629,3 +640,111 @@ func TestResponderGobSMCost(t *testing.T) {
 		t.Error("wrong transmission")
 	}
 }
+
+func TestResponderSimulateCosts(t *testing.T) {
+	rs := &Responder{}
+	cs := &CostSimulation{Direction: utils.OUT, Category: "0", Tenant: "vdf", Subject: "rif", Destination: "0256",
+		Usage:     time.Minute,
+		TimeStart: time.Date(2012, time.February, 2, 17, 0, 0, 0, time.UTC),
+		TimeEnd:   time.Date(2012, time.February, 2, 18, 0, 0, 0, time.UTC)}
+	var samples []*CostSample
+	if err := rs.SimulateCosts(cs, &samples); err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("Unexpected samples: %s", utils.ToJSON(samples))
+	}
+	if samples[0].Cost != 61 || samples[1].Cost != 30 {
+		t.Errorf("Unexpected costs: %v, %v", samples[0].Cost, samples[1].Cost)
+	}
+	for i, eStartTime := range []string{"00:00:00", "18:00:00"} {
+		if len(samples[i].Ratings) != 1 {
+			t.Errorf("Unexpected ratings: %s", utils.ToJSON(samples[i].Ratings))
+			continue
+		}
+		rating := samples[i].Ratings[0]
+		if rating.RatingPlanId != "EVENING" ||
+			!rating.ActivationTime.Equal(time.Date(2012, time.January, 1, 0, 0, 0, 0, time.UTC)) ||
+			rating.RateInterval.Timing.StartTime != eStartTime {
+			t.Errorf("Unexpected rating: %s", utils.ToJSON(rating))
+		}
+	}
+	cs.Step = utils.MetaDaily
+	cs.TimeEnd = time.Date(2012, time.February, 5, 17, 0, 0, 0, time.UTC)
+	if err := rs.SimulateCosts(cs, &samples); err != nil {
+		t.Fatal(err)
+	} else if len(samples) != 4 || !samples[3].TimeStart.Equal(cs.TimeEnd) {
+		t.Errorf("Unexpected samples: %s", utils.ToJSON(samples))
+	}
+	cs.Subject = "unknown_subject"
+	cs.Tenant = "unknown_tenant"
+	if err := rs.SimulateCosts(cs, &samples); err != nil {
+		t.Fatal(err)
+	} else if samples[0].Error == "" {
+		t.Errorf("Expecting rating error, received: %s", utils.ToJSON(samples[0]))
+	}
+	cs.Step = utils.MetaMonthly
+	if err := rs.SimulateCosts(cs, &samples); err == nil {
+		t.Error("Expecting unsupported step error")
+	}
+}
+
+func TestResponderGetMaxUsageQuotes(t *testing.T) {
+	rs := &Responder{}
+	mq := &MaxUsageQuotes{Direction: utils.OUT, Category: "0", Tenant: "vdf", Account: "minu",
+		Destinations: []string{"0723", "0723045"},
+		TimeStart:    time.Date(2013, 10, 21, 18, 34, 0, 0, time.UTC),
+		Usage:        time.Minute}
+	var quotes []*MaxUsageQuote
+	if err := rs.GetMaxUsageQuotes(mq, &quotes); err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("Unexpected quotes: %s", utils.ToJSON(quotes))
+	}
+	for i, dst := range mq.Destinations {
+		cd := &CallDescriptor{Direction: mq.Direction, Category: mq.Category, Tenant: mq.Tenant,
+			Subject: mq.Account, Destination: dst,
+			TimeStart: mq.TimeStart, TimeEnd: mq.TimeStart.Add(mq.Usage)}
+		eMaxUsage, err := cd.GetMaxSessionDuration()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quotes[i].Destination != dst || quotes[i].MaxUsage != eMaxUsage || quotes[i].Error != "" {
+			t.Errorf("Unexpected quote for %s: %s", dst, utils.ToJSON(quotes[i]))
+		}
+	}
+	mq.Account = "unknown_account"
+	if err := rs.GetMaxUsageQuotes(mq, &quotes); err == nil {
+		t.Error("Expecting account not found error")
+	}
+}
+
+func TestResponderGetCostBatch(t *testing.T) {
+	rs := &Responder{}
+	newCD := func(hour int) *CallDescriptor {
+		tStart := time.Date(2012, time.February, 2, hour, 0, 0, 0, time.UTC)
+		return &CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "vdf", Subject: "rif", Destination: "0256",
+			TimeStart: tStart, TimeEnd: tStart.Add(time.Minute), DurationIndex: time.Minute}
+	}
+	cds := []*CallDescriptor{newCD(17), newCD(18), nil,
+		&CallDescriptor{Direction: utils.OUT, Category: "0", Tenant: "unknown_tenant", Subject: "unknown_subject", Destination: "0256",
+			TimeStart: time.Date(2012, time.February, 2, 17, 0, 0, 0, time.UTC), TimeEnd: time.Date(2012, time.February, 2, 17, 1, 0, 0, time.UTC)}}
+	var costs []*BatchCost
+	if err := rs.GetCostBatch(cds, &costs); err != nil {
+		t.Fatal(err)
+	}
+	if len(costs) != 4 {
+		t.Fatalf("Unexpected costs: %s", utils.ToJSON(costs))
+	}
+	if costs[0].CallCost == nil || costs[0].CallCost.Cost != 61 ||
+		costs[1].CallCost == nil || costs[1].CallCost.Cost != 30 {
+		t.Errorf("Unexpected costs: %s", utils.ToJSON(costs[:2]))
+	}
+	if costs[2].Error == "" || costs[3].Error == "" || costs[3].CallCost != nil {
+		t.Errorf("Expecting errors, received: %s", utils.ToJSON(costs[2:]))
+	}
+	if err := rs.GetCostBatch(make([]*CallDescriptor, MaxCostBatchSize+1), &costs); err == nil {
+		t.Error("Expecting batch size error")
+	}
+}
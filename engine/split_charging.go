@@ -0,0 +1,102 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// SplitCharging charges a derived run proportionally to multiple payers (eg: 70% company, 30% employee)
+type SplitCharging struct {
+	ID     string
+	Payers []*SplitPayer
+}
+
+// SplitPayer is charged its Weight out of the total weight of the payers
+type SplitPayer struct {
+	Tenant  string // empty for the tenant of the CDR
+	Account string
+	Subject string // empty to keep the rating subject of the CDR
+	Weight  float64
+}
+
+// Shares returns the fraction of the cost charged to each of the payers
+func (spc *SplitCharging) Shares() ([]float64, error) {
+	var total float64
+	for _, payer := range spc.Payers {
+		if payer.Account == "" || payer.Weight < 0 {
+			return nil, fmt.Errorf("invalid payer %+v in split charging %s", payer, spc.ID)
+		}
+		total += payer.Weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no weight in split charging %s", spc.ID)
+	}
+	shares := make([]float64, len(spc.Payers))
+	for i, payer := range spc.Payers {
+		shares[i] = payer.Weight / total
+	}
+	return shares, nil
+}
+
+// splitCDR forks the CDR run once per payer, the runs stay linked by the CGRID and the SplitChargingID extra field
+func (spc *SplitCharging) splitCDR(cdr *CDR) ([]*CDR, error) {
+	shares, err := spc.Shares()
+	if err != nil {
+		return nil, err
+	}
+	cdrs := make([]*CDR, len(spc.Payers))
+	for i, payer := range spc.Payers {
+		payerCDR := cdr.Clone()
+		payerCDR.RunID = utils.ConcatenatedKey(cdr.RunID, payer.Account)
+		if payer.Tenant != "" {
+			payerCDR.Tenant = payer.Tenant
+		}
+		payerCDR.Account = payer.Account
+		if payer.Subject != "" {
+			payerCDR.Subject = payer.Subject
+		}
+		if payerCDR.ExtraFields == nil {
+			payerCDR.ExtraFields = make(map[string]string)
+		}
+		payerCDR.ExtraFields[utils.SplitChargingID] = spc.ID
+		payerCDR.ExtraFields[utils.CostShare] = strconv.FormatFloat(shares[i], 'f', -1, 64)
+		cdrs[i] = payerCDR
+	}
+	return cdrs, nil
+}
+
+// withCostShare returns a copy of the rate interval charging only share out of its costs
+func (i *RateInterval) withCostShare(share float64) *RateInterval {
+	ri := *i
+	rating := *i.Rating
+	rating.ConnectFee *= share
+	rating.MaxCost *= share
+	rating.MinCharge *= share
+	rating.Rates = make(RateGroups, len(i.Rating.Rates))
+	for idx, rate := range i.Rating.Rates {
+		shared := *rate
+		shared.Value *= share
+		rating.Rates[idx] = &shared
+	}
+	ri.Rating = &rating
+	return &ri
+}
@@ -0,0 +1,216 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewBreakerDataDB protects the rating and account queries towards dataDB with a circuit breaker
+// While the circuit is open rating data is served out of cache, everything else is rejected with ErrCircuitOpen
+func NewBreakerDataDB(dataDB DataDB, cb *utils.CircuitBreaker) DataDB {
+	return &BreakerDataDB{DataDB: dataDB, cb: cb}
+}
+
+type BreakerDataDB struct {
+	DataDB
+	cb *utils.CircuitBreaker
+}
+
+// fromCache is the fallback used while the circuit is open
+func (bdb *BreakerDataDB) fromCache(key string) (interface{}, error) {
+	x, ok := cache.Get(key)
+	if !ok {
+		return nil, utils.ErrCircuitOpen
+	}
+	if x == nil {
+		return nil, utils.ErrNotFound
+	}
+	return x, nil
+}
+
+func (bdb *BreakerDataDB) GetRatingPlan(key string, skipCache bool, transactionID string) (rp *RatingPlan, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		rp, err = bdb.DataDB.GetRatingPlan(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.RATING_PLAN_PREFIX + key); err == nil {
+			rp = x.(*RatingPlan)
+		}
+	}
+	return
+}
+
+func (bdb *BreakerDataDB) GetRatingProfile(key string, skipCache bool, transactionID string) (rpf *RatingProfile, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		rpf, err = bdb.DataDB.GetRatingProfile(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.RATING_PROFILE_PREFIX + key); err == nil {
+			rpf = x.(*RatingProfile)
+		}
+	}
+	return
+}
+
+func (bdb *BreakerDataDB) GetDestination(key string, skipCache bool, transactionID string) (dest *Destination, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		dest, err = bdb.DataDB.GetDestination(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.DESTINATION_PREFIX + key); err == nil {
+			dest = x.(*Destination)
+		}
+	}
+	return
+}
+
+func (bdb *BreakerDataDB) GetReverseDestination(key string, skipCache bool, transactionID string) (ids []string, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		ids, err = bdb.DataDB.GetReverseDestination(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.REVERSE_DESTINATION_PREFIX + key); err == nil {
+			ids = x.([]string)
+		}
+	}
+	return
+}
+
+func (bdb *BreakerDataDB) GetLCR(key string, skipCache bool, transactionID string) (lcr *LCR, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		lcr, err = bdb.DataDB.GetLCR(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.LCR_PREFIX + key); err == nil {
+			lcr = x.(*LCR)
+		}
+	}
+	return
+}
+
+func (bdb *BreakerDataDB) GetDerivedChargers(key string, skipCache bool, transactionID string) (dcs *utils.DerivedChargers, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		dcs, err = bdb.DataDB.GetDerivedChargers(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.DERIVEDCHARGERS_PREFIX + key); err == nil {
+			dcs = x.(*utils.DerivedChargers)
+		}
+	}
+	return
+}
+
+func (bdb *BreakerDataDB) GetActions(key string, skipCache bool, transactionID string) (as Actions, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		as, err = bdb.DataDB.GetActions(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.ACTION_PREFIX + key); err == nil {
+			as = x.(Actions)
+		}
+	}
+	return
+}
+
+func (bdb *BreakerDataDB) GetSharedGroup(key string, skipCache bool, transactionID string) (sg *SharedGroup, err error) {
+	if err = bdb.cb.Call(func() (err error) {
+		sg, err = bdb.DataDB.GetSharedGroup(key, skipCache, transactionID)
+		return
+	}); err == utils.ErrCircuitOpen {
+		var x interface{}
+		if x, err = bdb.fromCache(utils.SHARED_GROUP_PREFIX + key); err == nil {
+			sg = x.(*SharedGroup)
+		}
+	}
+	return
+}
+
+// Accounts are not cached, requests for them are rejected while the circuit is open
+func (bdb *BreakerDataDB) GetAccount(key string) (acc *Account, err error) {
+	err = bdb.cb.Call(func() (err error) {
+		acc, err = bdb.DataDB.GetAccount(key)
+		return
+	})
+	return
+}
+
+func (bdb *BreakerDataDB) SetAccount(acc *Account) error {
+	return bdb.cb.Call(func() error {
+		return bdb.DataDB.SetAccount(acc)
+	})
+}
+
+// NewBreakerCdrStorage protects the CDR and SMCost queries towards cdrStorage with a circuit breaker
+func NewBreakerCdrStorage(cdrStorage CdrStorage, cb *utils.CircuitBreaker) CdrStorage {
+	return &BreakerCdrStorage{CdrStorage: cdrStorage, cb: cb}
+}
+
+type BreakerCdrStorage struct {
+	CdrStorage
+	cb *utils.CircuitBreaker
+}
+
+func (bcs *BreakerCdrStorage) SetCDR(cdr *CDR, allowUpdate bool) error {
+	return bcs.cb.Call(func() error {
+		return bcs.CdrStorage.SetCDR(cdr, allowUpdate)
+	})
+}
+
+func (bcs *BreakerCdrStorage) SetSMCost(smc *SMCost) error {
+	return bcs.cb.Call(func() error {
+		return bcs.CdrStorage.SetSMCost(smc)
+	})
+}
+
+func (bcs *BreakerCdrStorage) GetSMCosts(cgrid, runid, originHost, originIDPrfx string) (smcs []*SMCost, err error) {
+	err = bcs.cb.Call(func() (err error) {
+		smcs, err = bcs.CdrStorage.GetSMCosts(cgrid, runid, originHost, originIDPrfx)
+		return
+	})
+	return
+}
+
+func (bcs *BreakerCdrStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) (cdrs []*CDR, count int64, err error) {
+	err = bcs.cb.Call(func() (err error) {
+		cdrs, count, err = bcs.CdrStorage.GetCDRs(qryFltr, remove)
+		return
+	})
+	return
+}
+
+func (bcs *BreakerCdrStorage) GetCDRsAggregates(qryFltr *utils.CDRsFilter, groupBy []string) (aggrs []*utils.CDRsAggregate, err error) {
+	err = bcs.cb.Call(func() (err error) {
+		aggrs, err = bcs.CdrStorage.GetCDRsAggregates(qryFltr, groupBy)
+		return
+	})
+	return
+}
+
+// SetCDRIndexedFields is a schema operation, passed through without the circuit breaker
+func (bcs *BreakerCdrStorage) SetCDRIndexedFields(fields []string) error {
+	return bcs.CdrStorage.SetCDRIndexedFields(fields)
+}
@@ -0,0 +1,47 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestBreakerDataDBServeFromCache(t *testing.T) {
+	cb := utils.NewCircuitBreaker(1, 0, time.Minute)
+	cb.Call(func() error { return errors.New("BACKEND_DOWN") }) // open the circuit
+	bdb := NewBreakerDataDB(dataStorage, cb)
+	rp := &RatingPlan{Id: "RP_BREAKER"}
+	cache.Set(utils.RATING_PLAN_PREFIX+rp.Id, rp, true, "")
+	if rcv, err := bdb.GetRatingPlan(rp.Id, false, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if rcv != rp {
+		t.Errorf("Expecting: %+v, received: %+v", rp, rcv)
+	}
+	if _, err := bdb.GetRatingPlan("RP_NOT_CACHED", false, utils.NonTransactional); err != utils.ErrCircuitOpen {
+		t.Errorf("Expecting ErrCircuitOpen, received: %v", err)
+	}
+	if _, err := bdb.GetAccount("cgrates.org:breaker"); err != utils.ErrCircuitOpen {
+		t.Errorf("Expecting ErrCircuitOpen, received: %v", err)
+	}
+	cache.RemKey(utils.RATING_PLAN_PREFIX+rp.Id, true, "")
+}
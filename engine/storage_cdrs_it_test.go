@@ -818,6 +818,18 @@ func testGetCDRs(cfg *config.CGRConfig) error {
 	} else if len(CDRs) != 7 {
 		return fmt.Errorf("testGetCDRs #94, unexpected number of CDRs returned:  %+v", len(CDRs))
 	}
+	// Aggregates grouped by account
+	if aggrs, err := cdrStorage.GetCDRsAggregates(new(utils.CDRsFilter), []string{utils.ACCOUNT}); err != nil {
+		return fmt.Errorf("testGetCDRs #95, err: %v", err)
+	} else {
+		var cnt int64
+		for _, aggr := range aggrs {
+			cnt += aggr.Count
+		}
+		if cnt != 9 {
+			return fmt.Errorf("testGetCDRs #96, unexpected number of CDRs aggregated: %d", cnt)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,699 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	clickHouseTimeLayout = "2006-01-02 15:04:05.000000000"
+	clickHouseMaxBacklog = 10     // batches kept while ClickHouse is unreachable before refusing new CDRs
+	clickHouseRecentCDRs = 100000 // CDRs remembered for the duplicate checks
+)
+
+// NewClickHouseCdrStorage keeps the CDRs into the ClickHouse server reachable over HTTP at chURL,
+// the SMCosts staying into cdrStorage
+func NewClickHouseCdrStorage(cdrStorage CdrStorage, chURL, user, password string, batchSize int,
+	flushInterval, ttl time.Duration) (chs *ClickHouseCdrStorage, err error) {
+	chs = &ClickHouseCdrStorage{CdrStorage: cdrStorage, user: user, password: password,
+		batchSize: batchSize, httpClient: new(http.Client), recent: make(map[string]string)}
+	if chs.url, err = url.Parse(chURL); err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		if _, err = chs.exec(fmt.Sprintf("ALTER TABLE %s MODIFY TTL toDateTime(created_at) + INTERVAL %d SECOND",
+			utils.TBLCDRs, int64(ttl.Seconds())), nil); err != nil {
+			return nil, err
+		}
+	} else if _, err = chs.exec("SELECT 1", nil); err != nil {
+		return nil, err
+	}
+	if batchSize > 1 && flushInterval > 0 {
+		chs.stopFlush = make(chan struct{})
+		go chs.flushLoop(flushInterval)
+	}
+	return
+}
+
+// ClickHouseCdrStorage stores the CDRs into ClickHouse, inserting them in batches
+type ClickHouseCdrStorage struct {
+	CdrStorage
+	url        *url.URL
+	user       string
+	password   string
+	httpClient *http.Client
+	batchSize  int
+	batch      [][]byte          // JSON rows waiting to be inserted
+	recent     map[string]string // created_at of the CDRs queued or stored lately, indexed on clickHouseCDRKey
+	recentKeys []string          // keys of recent in the order they were added, oldest first
+	batchMux   sync.Mutex        // protects batch and recent
+	flushMux   sync.Mutex        // one insert at a time so the rows keep their order
+	stopFlush  chan struct{}
+}
+
+// clickHouseCDRKey identifies a CDR the same way the unique index of the SQL storages does
+func clickHouseCDRKey(cdr *CDR) string {
+	return utils.ConcatenatedKey(cdr.CGRID, cdr.RunID, cdr.OriginID)
+}
+
+// exec runs the query over the HTTP interface, data being the rows of an INSERT
+func (chs *ClickHouseCdrStorage) exec(query string, data []byte) ([]byte, error) {
+	u := *chs.url
+	params := u.Query()
+	params.Set("output_format_json_quote_64bit_integers", "0")
+	params.Set("mutations_sync", "1")
+	body := data
+	if data == nil {
+		body = []byte(query)
+	} else {
+		params.Set("query", query)
+	}
+	u.RawQuery = params.Encode()
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if chs.user != "" {
+		req.Header.Set("X-ClickHouse-User", chs.user)
+	}
+	if chs.password != "" {
+		req.Header.Set("X-ClickHouse-Key", chs.password)
+	}
+	resp, err := chs.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	reply, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("<ClickHouse> %s", strings.TrimSpace(string(reply)))
+	}
+	return reply, nil
+}
+
+// SetCDR queues the CDR for the next batch, updates being new versions of the row replacing the old ones on merges.
+// Without allowUpdate a CDR stored lately is refused with ErrExists, as the unique index of the SQL storages does,
+// older duplicates being replaced on merges by the ReplacingMergeTree engine.
+func (chs *ClickHouseCdrStorage) SetCDR(cdr *CDR, allowUpdate bool) error {
+	key := clickHouseCDRKey(cdr)
+	chRow := newClickHouseCDR(cdr)
+	chs.batchMux.Lock()
+	createdAt, has := chs.recent[key]
+	chs.batchMux.Unlock()
+	if has && !allowUpdate {
+		return utils.ErrExists
+	}
+	if !has && allowUpdate { // updates keep the creation time so the TTL is not restarted
+		var err error
+		if createdAt, err = chs.storedCreatedAt(cdr); err != nil && err != utils.ErrNotFound {
+			return err
+		}
+	}
+	if createdAt != "" {
+		chRow.CreatedAt = createdAt
+	}
+	chs.batchMux.Lock()
+	if createdAt, has := chs.recent[key]; has { // stored meanwhile by a concurrent call
+		if !allowUpdate {
+			chs.batchMux.Unlock()
+			return utils.ErrExists
+		}
+		chRow.CreatedAt = createdAt
+	}
+	row, err := json.Marshal(chRow)
+	if err != nil {
+		chs.batchMux.Unlock()
+		return err
+	}
+	if chs.batchSize <= 1 { // no batching, errors reach the caller
+		chs.remember(key, chRow.CreatedAt)
+		chs.batchMux.Unlock()
+		if _, err = chs.exec(clickHouseInsertQry(), row); err != nil && !allowUpdate {
+			chs.batchMux.Lock()
+			delete(chs.recent, key)
+			chs.batchMux.Unlock()
+		}
+		return err
+	}
+	if len(chs.batch) >= clickHouseMaxBacklog*chs.batchSize {
+		chs.batchMux.Unlock()
+		return utils.ErrResourceUnavailable
+	}
+	chs.batch = append(chs.batch, row)
+	chs.remember(key, chRow.CreatedAt)
+	full := len(chs.batch) >= chs.batchSize
+	chs.batchMux.Unlock()
+	if full {
+		if err := chs.flush(); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<ClickHouse> Failed inserting CDRs, will retry, error: %s", err.Error()))
+		}
+	}
+	return nil
+}
+
+// remember adds the CDR to the recent ones, forgetting the oldest over clickHouseRecentCDRs, batchMux should be held by the caller
+func (chs *ClickHouseCdrStorage) remember(key, createdAt string) {
+	if _, has := chs.recent[key]; !has {
+		chs.recentKeys = append(chs.recentKeys, key)
+	}
+	chs.recent[key] = createdAt
+	for len(chs.recentKeys) > clickHouseRecentCDRs {
+		delete(chs.recent, chs.recentKeys[0])
+		chs.recentKeys = chs.recentKeys[1:]
+	}
+}
+
+// storedCreatedAt returns the created_at of the CDR stored in ClickHouse, ErrNotFound if not stored
+// The lookup includes the columns of the sorting key so it only reads the granules of the CDR
+func (chs *ClickHouseCdrStorage) storedCreatedAt(cdr *CDR) (string, error) {
+	reply, err := chs.exec(fmt.Sprintf("SELECT min(created_at) AS created_at, count() AS count FROM %s WHERE tenant = %s AND toDate(setup_time) = %s AND cgrid = %s AND run_id = %s AND origin_id = %s FORMAT JSONEachRow",
+		utils.TBLCDRs, clickHouseQuote(cdr.Tenant), clickHouseQuote(cdr.SetupTime.UTC().Format("2006-01-02")),
+		clickHouseQuote(cdr.CGRID), clickHouseQuote(cdr.RunID), clickHouseQuote(cdr.OriginID)), nil)
+	if err != nil {
+		return "", err
+	}
+	var stored struct {
+		CreatedAt string `json:"created_at"`
+		Count     int64  `json:"count"`
+	}
+	if len(bytes.TrimSpace(reply)) != 0 {
+		if err := json.Unmarshal(reply, &stored); err != nil {
+			return "", err
+		}
+	}
+	if stored.Count == 0 {
+		return "", utils.ErrNotFound
+	}
+	return stored.CreatedAt, nil
+}
+
+func clickHouseInsertQry() string {
+	return fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", utils.TBLCDRs)
+}
+
+// flush inserts the queued CDRs, keeping them for the next flush on errors
+func (chs *ClickHouseCdrStorage) flush() error {
+	chs.flushMux.Lock()
+	defer chs.flushMux.Unlock()
+	chs.batchMux.Lock()
+	rows := chs.batch
+	chs.batch = nil
+	chs.batchMux.Unlock()
+	if len(rows) == 0 {
+		return nil
+	}
+	if _, err := chs.exec(clickHouseInsertQry(), bytes.Join(rows, []byte("\n"))); err != nil {
+		chs.batchMux.Lock()
+		chs.batch = append(rows, chs.batch...)
+		chs.batchMux.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (chs *ClickHouseCdrStorage) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-chs.stopFlush:
+			return
+		case <-ticker.C:
+			if err := chs.flush(); err != nil {
+				utils.Logger.Warning(fmt.Sprintf("<ClickHouse> Failed inserting CDRs, will retry, error: %s", err.Error()))
+			}
+		}
+	}
+}
+
+// Close inserts the queued CDRs, the storage keeping the SMCosts being closed by its owner
+func (chs *ClickHouseCdrStorage) Close() {
+	if chs.stopFlush != nil {
+		close(chs.stopFlush)
+	}
+	if err := chs.flush(); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<ClickHouse> Failed inserting CDRs on close, error: %s", err.Error()))
+	}
+}
+
+// SetCDRIndexedFields adds skipping indexes on the given extra fields
+func (chs *ClickHouseCdrStorage) SetCDRIndexedFields(fields []string) error {
+	for _, field := range fields {
+		if _, err := chs.exec(fmt.Sprintf("ALTER TABLE %s ADD INDEX IF NOT EXISTS %s extra_fields[%s] TYPE bloom_filter GRANULARITY 4",
+			utils.TBLCDRs, cdrIndexedFieldColumn(field), clickHouseQuote(field)), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCDRs has ability to remove the selected CDRs, count them or simply return them
+func (chs *ClickHouseCdrStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR, int64, error) {
+	if err := chs.flush(); err != nil { // make the queued CDRs visible
+		return nil, 0, err
+	}
+	where, err := clickHouseCdrsWhere(qryFltr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if remove {
+		if where == "" {
+			where = " WHERE 1"
+		}
+		_, err := chs.exec(fmt.Sprintf("ALTER TABLE %s DELETE%s", utils.TBLCDRs, where), nil)
+		return nil, 0, err
+	}
+	if qryFltr.Count {
+		reply, err := chs.exec(fmt.Sprintf("SELECT count() AS count FROM %s FINAL%s FORMAT JSONEachRow", utils.TBLCDRs, where), nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		var cnt struct {
+			Count int64 `json:"count"`
+		}
+		if err := json.Unmarshal(reply, &cnt); err != nil {
+			return nil, 0, err
+		}
+		return nil, cnt.Count, nil
+	}
+	reply, err := chs.exec(fmt.Sprintf("SELECT * FROM %s FINAL%s%s FORMAT JSONEachRow", utils.TBLCDRs, where,
+		clickHouseLimit(qryFltr.Paginator)), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	var cdrs []*CDR
+	dec := json.NewDecoder(bytes.NewReader(reply))
+	for dec.More() {
+		var row clickHouseCDR
+		if err := dec.Decode(&row); err != nil {
+			return nil, 0, err
+		}
+		cdr, err := row.AsCDR()
+		if err != nil {
+			return nil, 0, err
+		}
+		cdrs = append(cdrs, cdr)
+	}
+	if len(cdrs) == 0 {
+		return nil, 0, utils.ErrNotFound
+	}
+	return cdrs, 0, nil
+}
+
+// GetCDRsAggregates sums up the CDRs matching qryFltr inside ClickHouse, grouping them on groupBy fields <Account|Destination|*day|ExtraInfo>
+func (chs *ClickHouseCdrStorage) GetCDRsAggregates(qryFltr *utils.CDRsFilter, groupBy []string) ([]*utils.CDRsAggregate, error) {
+	if err := chs.flush(); err != nil {
+		return nil, err
+	}
+	where, err := clickHouseCdrsWhere(qryFltr)
+	if err != nil {
+		return nil, err
+	}
+	var slctExprs, grpExprs []string
+	for _, fld := range groupBy {
+		switch fld {
+		case utils.ACCOUNT:
+			slctExprs = append(slctExprs, "account")
+			grpExprs = append(grpExprs, "account")
+		case utils.DESTINATION:
+			slctExprs = append(slctExprs, "destination")
+			grpExprs = append(grpExprs, "destination")
+		case utils.MetaDay:
+			slctExprs = append(slctExprs, "toString(toDate(setup_time)) AS day")
+			grpExprs = append(grpExprs, "day")
+		case utils.ExtraInfo:
+			slctExprs = append(slctExprs, "extra_info")
+			grpExprs = append(grpExprs, "extra_info")
+		default:
+			return nil, fmt.Errorf("unsupported group by field: %s", fld)
+		}
+	}
+	qry := fmt.Sprintf("SELECT %s FROM %s FINAL%s", strings.Join(append(slctExprs,
+		"count() AS count", "sumIf(cost, cost > 0) AS cost", "sum(`usage`) AS usage"), ", "), utils.TBLCDRs, where)
+	if len(grpExprs) != 0 {
+		qry += " GROUP BY " + strings.Join(grpExprs, ", ")
+	}
+	reply, err := chs.exec(qry+" FORMAT JSONEachRow", nil)
+	if err != nil {
+		return nil, err
+	}
+	var aggrs []*utils.CDRsAggregate
+	dec := json.NewDecoder(bytes.NewReader(reply))
+	for dec.More() {
+		var row struct {
+			Account     string  `json:"account"`
+			Destination string  `json:"destination"`
+			Day         string  `json:"day"`
+			ExtraInfo   string  `json:"extra_info"`
+			Count       int64   `json:"count"`
+			Cost        float64 `json:"cost"`
+			Usage       float64 `json:"usage"`
+		}
+		if err := dec.Decode(&row); err != nil {
+			return nil, err
+		}
+		aggrs = append(aggrs, &utils.CDRsAggregate{Account: row.Account, Destination: row.Destination, Day: row.Day,
+			ExtraInfo: row.ExtraInfo, Count: row.Count, Cost: row.Cost, Usage: time.Duration(row.Usage * float64(time.Second))})
+	}
+	if len(aggrs) == 0 || aggrs[0].Count == 0 { // aggregating without groups returns one row even if nothing matches
+		return nil, utils.ErrNotFound
+	}
+	return aggrs, nil
+}
+
+// clickHouseCDR is the row of the cdrs table
+type clickHouseCDR struct {
+	OrderID         int64             `json:"order_id"`
+	CGRID           string            `json:"cgrid"`
+	RunID           string            `json:"run_id"`
+	OriginHost      string            `json:"origin_host"`
+	Source          string            `json:"source"`
+	OriginID        string            `json:"origin_id"`
+	ToR             string            `json:"tor"`
+	RequestType     string            `json:"request_type"`
+	Direction       string            `json:"direction"`
+	Tenant          string            `json:"tenant"`
+	Category        string            `json:"category"`
+	Account         string            `json:"account"`
+	Subject         string            `json:"subject"`
+	Destination     string            `json:"destination"`
+	SetupTime       string            `json:"setup_time"`
+	PDD             float64           `json:"pdd"`
+	AnswerTime      string            `json:"answer_time"`
+	Usage           float64           `json:"usage"`
+	Supplier        string            `json:"supplier"`
+	DisconnectCause string            `json:"disconnect_cause"`
+	ExtraFields     map[string]string `json:"extra_fields"`
+	CostSource      string            `json:"cost_source"`
+	Cost            float64           `json:"cost"`
+	ShadowCost      float64           `json:"shadow_cost"`
+	CostDetails     string            `json:"cost_details"`
+	AccountSummary  string            `json:"account_summary"`
+	ExtraInfo       string            `json:"extra_info"`
+	CreatedAt       string            `json:"created_at"`
+	UpdatedAt       string            `json:"updated_at"`
+}
+
+func newClickHouseCDR(cdr *CDR) *clickHouseCDR {
+	now := time.Now()
+	orderID := cdr.OrderID
+	if orderID == 0 {
+		orderID = now.UnixNano()
+	}
+	return &clickHouseCDR{
+		OrderID:         orderID,
+		CGRID:           cdr.CGRID,
+		RunID:           cdr.RunID,
+		OriginHost:      cdr.OriginHost,
+		Source:          cdr.Source,
+		OriginID:        cdr.OriginID,
+		ToR:             cdr.ToR,
+		RequestType:     cdr.RequestType,
+		Direction:       cdr.Direction,
+		Tenant:          cdr.Tenant,
+		Category:        cdr.Category,
+		Account:         cdr.Account,
+		Subject:         cdr.Subject,
+		Destination:     cdr.Destination,
+		SetupTime:       clickHouseTime(cdr.SetupTime),
+		PDD:             cdr.PDD.Seconds(),
+		AnswerTime:      clickHouseTime(cdr.AnswerTime),
+		Usage:           cdr.Usage.Seconds(),
+		Supplier:        cdr.Supplier,
+		DisconnectCause: cdr.DisconnectCause,
+		ExtraFields:     cdr.ExtraFields,
+		CostSource:      cdr.CostSource,
+		Cost:            cdr.Cost,
+		ShadowCost:      cdr.ShadowCost,
+		CostDetails:     cdr.CostDetailsJson(),
+		AccountSummary:  utils.ToJSON(cdr.AccountSummary),
+		ExtraInfo:       cdr.ExtraInfo,
+		CreatedAt:       clickHouseTime(now),
+		UpdatedAt:       clickHouseTime(now),
+	}
+}
+
+func (row *clickHouseCDR) AsCDR() (cdr *CDR, err error) {
+	cdr = &CDR{
+		CGRID:           row.CGRID,
+		RunID:           row.RunID,
+		OrderID:         row.OrderID,
+		OriginHost:      row.OriginHost,
+		Source:          row.Source,
+		OriginID:        row.OriginID,
+		ToR:             row.ToR,
+		RequestType:     row.RequestType,
+		Direction:       row.Direction,
+		Tenant:          row.Tenant,
+		Category:        row.Category,
+		Account:         row.Account,
+		Subject:         row.Subject,
+		Destination:     row.Destination,
+		PDD:             time.Duration(row.PDD * utils.NANO_MULTIPLIER),
+		Usage:           time.Duration(row.Usage * utils.NANO_MULTIPLIER),
+		Supplier:        row.Supplier,
+		DisconnectCause: row.DisconnectCause,
+		ExtraFields:     row.ExtraFields,
+		CostSource:      row.CostSource,
+		Cost:            row.Cost,
+		ShadowCost:      row.ShadowCost,
+		CostDetails:     new(CallCost),
+		ExtraInfo:       row.ExtraInfo,
+	}
+	if cdr.ExtraFields == nil {
+		cdr.ExtraFields = make(map[string]string)
+	}
+	if cdr.SetupTime, err = parseClickHouseTime(row.SetupTime); err != nil {
+		return nil, err
+	}
+	if cdr.AnswerTime, err = parseClickHouseTime(row.AnswerTime); err != nil {
+		return nil, err
+	}
+	if row.CostDetails != "" {
+		if err = json.Unmarshal([]byte(row.CostDetails), cdr.CostDetails); err != nil {
+			return nil, fmt.Errorf("JSON unmarshal callcost error for cgrid: %s, runid: %v, error: %s", row.CGRID, row.RunID, err.Error())
+		}
+	}
+	if cdr.AccountSummary, err = NewAccountSummaryFromJSON(row.AccountSummary); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// clickHouseTime formats t as DateTime64 in UTC, the zero time being stored as the epoch
+func clickHouseTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(clickHouseTimeLayout)
+}
+
+func parseClickHouseTime(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(clickHouseTimeLayout, s, time.UTC)
+	if err != nil || t.Equal(time.Unix(0, 0)) {
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+// clickHouseQuote returns s as string literal
+func clickHouseQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// clickHouseIn returns the condition of col being (or not) one of the vals, empty for no vals
+func clickHouseIn(col string, vals []string, not bool) string {
+	if len(vals) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(vals))
+	for i, val := range vals {
+		quoted[i] = clickHouseQuote(val)
+	}
+	op := "IN"
+	if not {
+		op = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s (%s)", col, op, strings.Join(quoted, ", "))
+}
+
+func clickHouseLimit(pag utils.Paginator) (limit string) {
+	if pag.Limit == nil && pag.Offset == nil {
+		return
+	}
+	limit = " ORDER BY order_id" // stable pages
+	if pag.Limit != nil {
+		limit += fmt.Sprintf(" LIMIT %d", *pag.Limit)
+	}
+	if pag.Offset != nil {
+		if pag.Limit == nil {
+			limit += " LIMIT 18446744073709551615"
+		}
+		limit += fmt.Sprintf(" OFFSET %d", *pag.Offset)
+	}
+	return
+}
+
+// clickHouseCdrsWhere builds the WHERE clause selecting the CDRs matching qryFltr
+func clickHouseCdrsWhere(qryFltr *utils.CDRsFilter) (string, error) {
+	conds := []string{
+		clickHouseIn("cgrid", qryFltr.CGRIDs, false),
+		clickHouseIn("cgrid", qryFltr.NotCGRIDs, true),
+		clickHouseIn("run_id", qryFltr.RunIDs, false),
+		clickHouseIn("run_id", qryFltr.NotRunIDs, true),
+		clickHouseIn("tor", qryFltr.ToRs, false),
+		clickHouseIn("tor", qryFltr.NotToRs, true),
+		clickHouseIn("origin_host", qryFltr.OriginHosts, false),
+		clickHouseIn("origin_host", qryFltr.NotOriginHosts, true),
+		clickHouseIn("source", qryFltr.Sources, false),
+		clickHouseIn("source", qryFltr.NotSources, true),
+		clickHouseIn("request_type", qryFltr.RequestTypes, false),
+		clickHouseIn("request_type", qryFltr.NotRequestTypes, true),
+		clickHouseIn("direction", qryFltr.Directions, false),
+		clickHouseIn("direction", qryFltr.NotDirections, true),
+		clickHouseIn("tenant", qryFltr.Tenants, false),
+		clickHouseIn("tenant", qryFltr.NotTenants, true),
+		clickHouseIn("category", qryFltr.Categories, false),
+		clickHouseIn("category", qryFltr.NotCategories, true),
+		clickHouseIn("account", qryFltr.Accounts, false),
+		clickHouseIn("account", qryFltr.NotAccounts, true),
+		clickHouseIn("subject", qryFltr.Subjects, false),
+		clickHouseIn("subject", qryFltr.NotSubjects, true),
+		clickHouseIn("supplier", qryFltr.Suppliers, false),
+		clickHouseIn("supplier", qryFltr.NotSuppliers, true),
+		clickHouseIn("disconnect_cause", qryFltr.DisconnectCauses, false),
+		clickHouseIn("disconnect_cause", qryFltr.NotDisconnectCauses, true),
+	}
+	if len(qryFltr.DestinationPrefixes) != 0 {
+		prfxConds := make([]string, len(qryFltr.DestinationPrefixes))
+		for i, prfx := range qryFltr.DestinationPrefixes {
+			prfxConds[i] = fmt.Sprintf("startsWith(destination, %s)", clickHouseQuote(prfx))
+		}
+		conds = append(conds, "("+strings.Join(prfxConds, " OR ")+")")
+	}
+	for _, prfx := range qryFltr.NotDestinationPrefixes {
+		conds = append(conds, fmt.Sprintf("NOT startsWith(destination, %s)", clickHouseQuote(prfx)))
+	}
+	for _, costs := range []struct {
+		vals []float64
+		op   string
+	}{{qryFltr.Costs, "IN"}, {qryFltr.NotCosts, "NOT IN"}} {
+		if len(costs.vals) == 0 {
+			continue
+		}
+		strCosts := make([]string, len(costs.vals))
+		for i, cost := range costs.vals {
+			strCosts[i] = strconv.FormatFloat(cost, 'f', -1, 64)
+		}
+		conds = append(conds, fmt.Sprintf("cost %s (%s)", costs.op, strings.Join(strCosts, ", ")))
+	}
+	if len(qryFltr.ExtraFields) != 0 { // any of them matching, as in SQL
+		var xfConds []string
+		for field, value := range qryFltr.ExtraFields {
+			if value == utils.MetaExists {
+				xfConds = append(xfConds, fmt.Sprintf("mapContains(extra_fields, %s)", clickHouseQuote(field)))
+			} else {
+				xfConds = append(xfConds, fmt.Sprintf("extra_fields[%s] = %s", clickHouseQuote(field), clickHouseQuote(value)))
+			}
+		}
+		conds = append(conds, "("+strings.Join(xfConds, " OR ")+")")
+	}
+	for field, value := range qryFltr.NotExtraFields {
+		if value == utils.MetaExists {
+			conds = append(conds, fmt.Sprintf("NOT mapContains(extra_fields, %s)", clickHouseQuote(field)))
+		} else {
+			conds = append(conds, fmt.Sprintf("extra_fields[%s] != %s", clickHouseQuote(field), clickHouseQuote(value)))
+		}
+	}
+	if qryFltr.OrderIDStart != nil {
+		conds = append(conds, fmt.Sprintf("order_id >= %d", *qryFltr.OrderIDStart))
+	}
+	if qryFltr.OrderIDEnd != nil {
+		conds = append(conds, fmt.Sprintf("order_id < %d", *qryFltr.OrderIDEnd))
+	}
+	for _, tm := range []struct {
+		col string
+		t   *time.Time
+		op  string
+	}{
+		{"setup_time", qryFltr.SetupTimeStart, ">="}, {"setup_time", qryFltr.SetupTimeEnd, "<"},
+		{"answer_time", qryFltr.AnswerTimeStart, ">="}, {"answer_time", qryFltr.AnswerTimeEnd, "<"},
+		{"created_at", qryFltr.CreatedAtStart, ">="}, {"created_at", qryFltr.CreatedAtEnd, "<"},
+		{"updated_at", qryFltr.UpdatedAtStart, ">="}, {"updated_at", qryFltr.UpdatedAtEnd, "<"},
+	} {
+		if tm.t != nil && !tm.t.IsZero() {
+			conds = append(conds, fmt.Sprintf("%s %s %s", tm.col, tm.op, clickHouseQuote(clickHouseTime(*tm.t))))
+		}
+	}
+	for _, dur := range []struct {
+		col, val, op string
+	}{
+		{"`usage`", qryFltr.MinUsage, ">="}, {"`usage`", qryFltr.MaxUsage, "<"},
+		{"pdd", qryFltr.MinPDD, ">="}, {"pdd", qryFltr.MaxPDD, "<"},
+	} {
+		if dur.val == "" {
+			continue
+		}
+		d, err := utils.ParseDurationWithSecs(dur.val)
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, fmt.Sprintf("%s %s %s", dur.col, dur.op, strconv.FormatFloat(d.Seconds(), 'f', -1, 64)))
+	}
+	if qryFltr.MinCost != nil {
+		if qryFltr.MaxCost != nil && *qryFltr.MinCost == 0.0 && *qryFltr.MaxCost == -1.0 { // skip the errors
+			conds = append(conds, "cost >= 0")
+		} else {
+			conds = append(conds, fmt.Sprintf("cost >= %s", strconv.FormatFloat(*qryFltr.MinCost, 'f', -1, 64)))
+			if qryFltr.MaxCost != nil {
+				conds = append(conds, fmt.Sprintf("cost < %s", strconv.FormatFloat(*qryFltr.MaxCost, 'f', -1, 64)))
+			}
+		}
+	} else if qryFltr.MaxCost != nil {
+		if *qryFltr.MaxCost == -1.0 { // non-rated CDRs
+			conds = append(conds, "cost = -1")
+		} else {
+			conds = append(conds, fmt.Sprintf("cost < %s", strconv.FormatFloat(*qryFltr.MaxCost, 'f', -1, 64)))
+		}
+	}
+	var nonEmpty []string
+	for _, cond := range conds {
+		if cond != "" {
+			nonEmpty = append(nonEmpty, cond)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(nonEmpty, " AND "), nil
+}
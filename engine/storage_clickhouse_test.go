@@ -0,0 +1,151 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestClickHouseCdrStorageBatching(t *testing.T) {
+	var mux sync.Mutex
+	var inserts int
+	var rows [][]byte
+	var selects, lookups []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		if qry := r.URL.Query().Get("query"); strings.HasPrefix(qry, "INSERT") {
+			inserts++
+			rows = append(rows, bytes.Split(body, []byte("\n"))...)
+			return
+		}
+		if strings.HasPrefix(string(body), "SELECT min(created_at)") { // created_at of the updated CDRs
+			lookups = append(lookups, string(body))
+			for _, row := range rows {
+				if bytes.Contains(row, []byte(`"cgrid":"CH1"`)) {
+					w.Write([]byte(`{"created_at":"2017-03-01 10:00:00.000000000","count":1}`))
+					return
+				}
+			}
+			w.Write([]byte(`{"created_at":"1970-01-01 00:00:00.000000000","count":0}`))
+			return
+		}
+		selects = append(selects, string(body))
+		if strings.HasPrefix(string(body), "SELECT * ") {
+			w.Write(bytes.Join(rows, []byte("\n")))
+		}
+	}))
+	defer srv.Close()
+	chs, err := NewClickHouseCdrStorage(nil, srv.URL, "default", "", 2, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cdr := &CDR{CGRID: "CH1", RunID: utils.META_DEFAULT, OriginID: "ch1", Tenant: "cgrates.org", Account: "1001",
+		Destination: "1002", SetupTime: time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC), Usage: 35 * time.Second,
+		ExtraFields: map[string]string{"Campaign": "spring"}, Cost: 1.2, CostDetails: new(CallCost)}
+	if err := chs.SetCDR(cdr, false); err != nil {
+		t.Fatal(err)
+	}
+	if inserts != 0 {
+		t.Errorf("Inserted before batch size reached: %d", inserts)
+	}
+	cdrs, _, err := chs.GetCDRs(&utils.CDRsFilter{Accounts: []string{"1001"}, DestinationPrefixes: []string{"10"},
+		ExtraFields: map[string]string{"Campaign": "spring"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserts != 1 {
+		t.Errorf("Expecting the queued CDR inserted before querying, inserts: %d", inserts)
+	}
+	qry := selects[len(selects)-1]
+	for _, cond := range []string{"account IN ('1001')", "startsWith(destination, '10')", "extra_fields['Campaign'] = 'spring'"} {
+		if !strings.Contains(qry, cond) {
+			t.Errorf("Missing condition %s in query: %s", cond, qry)
+		}
+	}
+	if len(cdrs) != 1 {
+		t.Fatalf("Received: %s", utils.ToJSON(cdrs))
+	}
+	if cdrs[0].CGRID != cdr.CGRID || cdrs[0].Usage != cdr.Usage || !cdrs[0].SetupTime.Equal(cdr.SetupTime) ||
+		!cdrs[0].AnswerTime.IsZero() || cdrs[0].ExtraFields["Campaign"] != "spring" || cdrs[0].Cost != cdr.Cost {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(cdr), utils.ToJSON(cdrs[0]))
+	}
+	if err := chs.SetCDR(cdr, false); err != utils.ErrExists {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrExists, err)
+	}
+	if err := chs.SetCDR(cdr, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := chs.SetCDR(cdr, false); err != utils.ErrExists { // out of the queued ones
+		t.Errorf("Expecting: %v, received: %v", utils.ErrExists, err)
+	}
+	chs.flush()
+	mux.Lock()
+	if len(lookups) != 0 {
+		t.Errorf("Unexpected lookups of the recent CDRs: %q", lookups)
+	}
+	if len(rows) != 2 || !bytes.Equal(createdAtOf(rows[0]), createdAtOf(rows[1])) {
+		t.Errorf("Expecting the update keeping created_at, received: %q", rows)
+	}
+	mux.Unlock()
+	// a CDR not stored lately is looked up on its sorting key when updated
+	chs, err = NewClickHouseCdrStorage(nil, srv.URL, "default", "", 2, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chs.SetCDR(cdr, true); err != nil {
+		t.Fatal(err)
+	}
+	newCDR := &CDR{CGRID: "CH2", RunID: utils.META_DEFAULT, OriginID: "ch2", Tenant: "cgrates.org",
+		SetupTime: time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC)}
+	if err := chs.SetCDR(newCDR, false); err != nil {
+		t.Fatal(err)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	if len(lookups) != 1 {
+		t.Fatalf("Expecting one lookup, received: %q", lookups)
+	}
+	for _, cond := range []string{"tenant = 'cgrates.org'", "toDate(setup_time) = '2017-03-01'", "cgrid = 'CH1'"} {
+		if !strings.Contains(lookups[0], cond) {
+			t.Errorf("Missing condition %s in query: %s", cond, lookups[0])
+		}
+	}
+	if len(rows) != 4 || !bytes.Contains(rows[2], []byte(`"created_at":"2017-03-01 10:00:00.000000000"`)) {
+		t.Errorf("Expecting the update keeping created_at, received: %q", rows)
+	}
+}
+
+// createdAtOf extracts the created_at field out of a JSON row
+func createdAtOf(row []byte) []byte {
+	idx := bytes.Index(row, []byte(`"created_at":`))
+	if idx == -1 {
+		return nil
+	}
+	return row[idx : idx+len(`"created_at":"2006-01-02 15:04:05.000000000"`)]
+}
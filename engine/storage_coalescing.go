@@ -0,0 +1,79 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"strconv"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewCoalescingDataDB shares one backend round trip between concurrent identical rating lookups towards dataDB
+func NewCoalescingDataDB(dataDB DataDB) DataDB {
+	return &CoalescingDataDB{DataDB: dataDB}
+}
+
+type CoalescingDataDB struct {
+	DataDB
+	sf utils.SingleFlight
+}
+
+// flightKey identifies identical queries
+func (cdb *CoalescingDataDB) flightKey(prefix, key string, skipCache bool, transactionID string) string {
+	return utils.ConcatenatedKey(prefix+key, strconv.FormatBool(skipCache), transactionID)
+}
+
+func (cdb *CoalescingDataDB) GetRatingPlan(key string, skipCache bool, transactionID string) (*RatingPlan, error) {
+	x, err := cdb.sf.Do(cdb.flightKey(utils.RATING_PLAN_PREFIX, key, skipCache, transactionID), func() (interface{}, error) {
+		return cdb.DataDB.GetRatingPlan(key, skipCache, transactionID)
+	})
+	rp, _ := x.(*RatingPlan)
+	return rp, err
+}
+
+func (cdb *CoalescingDataDB) GetRatingProfile(key string, skipCache bool, transactionID string) (*RatingProfile, error) {
+	x, err := cdb.sf.Do(cdb.flightKey(utils.RATING_PROFILE_PREFIX, key, skipCache, transactionID), func() (interface{}, error) {
+		return cdb.DataDB.GetRatingProfile(key, skipCache, transactionID)
+	})
+	rpf, _ := x.(*RatingProfile)
+	return rpf, err
+}
+
+func (cdb *CoalescingDataDB) GetDestination(key string, skipCache bool, transactionID string) (*Destination, error) {
+	x, err := cdb.sf.Do(cdb.flightKey(utils.DESTINATION_PREFIX, key, skipCache, transactionID), func() (interface{}, error) {
+		return cdb.DataDB.GetDestination(key, skipCache, transactionID)
+	})
+	dest, _ := x.(*Destination)
+	return dest, err
+}
+
+func (cdb *CoalescingDataDB) GetReverseDestination(key string, skipCache bool, transactionID string) ([]string, error) {
+	x, err := cdb.sf.Do(cdb.flightKey(utils.REVERSE_DESTINATION_PREFIX, key, skipCache, transactionID), func() (interface{}, error) {
+		return cdb.DataDB.GetReverseDestination(key, skipCache, transactionID)
+	})
+	ids, _ := x.([]string)
+	return ids, err
+}
+
+func (cdb *CoalescingDataDB) GetLCR(key string, skipCache bool, transactionID string) (*LCR, error) {
+	x, err := cdb.sf.Do(cdb.flightKey(utils.LCR_PREFIX, key, skipCache, transactionID), func() (interface{}, error) {
+		return cdb.DataDB.GetLCR(key, skipCache, transactionID)
+	})
+	lcr, _ := x.(*LCR)
+	return lcr, err
+}
@@ -0,0 +1,80 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// blockingRatingDataDB counts the rating plan lookups, holding them until released
+type blockingRatingDataDB struct {
+	DataDB
+	calls   int32
+	release chan struct{}
+}
+
+func (bdb *blockingRatingDataDB) GetRatingPlan(key string, skipCache bool, transactionID string) (*RatingPlan, error) {
+	atomic.AddInt32(&bdb.calls, 1)
+	<-bdb.release
+	if key == "RP_MISSING" {
+		return nil, utils.ErrNotFound
+	}
+	return &RatingPlan{Id: key}, nil
+}
+
+func TestCoalescingDataDBGetRatingPlan(t *testing.T) {
+	for _, rpID := range []string{"RP_COALESCED", "RP_MISSING"} {
+		backend := &blockingRatingDataDB{DataDB: dataStorage, release: make(chan struct{})}
+		cdb := NewCoalescingDataDB(backend)
+		var entered int32
+		var wg sync.WaitGroup
+		rps := make([]*RatingPlan, 10)
+		errs := make([]error, 10)
+		for i := range rps {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				atomic.AddInt32(&entered, 1)
+				rps[i], errs[i] = cdb.GetRatingPlan(rpID, false, utils.NonTransactional)
+			}(i)
+		}
+		for atomic.LoadInt32(&entered) != int32(len(rps)) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond) // let the lookups join the one in flight
+		close(backend.release)
+		wg.Wait()
+		if calls := atomic.LoadInt32(&backend.calls); calls != 1 {
+			t.Errorf("%s: expecting 1 backend call, received: %d", rpID, calls)
+		}
+		for i := range rps {
+			if rpID == "RP_MISSING" {
+				if errs[i] != utils.ErrNotFound || rps[i] != nil {
+					t.Errorf("Caller %d, expecting not found, received: %+v, %v", i, rps[i], errs[i])
+				}
+			} else if errs[i] != nil || rps[i] != rps[0] || rps[i].Id != rpID {
+				t.Errorf("Caller %d, unexpected result: %+v, %v", i, rps[i], errs[i])
+			}
+		}
+	}
+}
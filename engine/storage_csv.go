@@ -18,10 +18,13 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"bufio"
 	"encoding/csv"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/cgrates/cgrates/utils"
@@ -29,53 +32,176 @@ import (
 
 type CSVStorage struct {
 	sep        rune
-	readerFunc func(string, rune, int) (*csv.Reader, *os.File, error)
+	readerFunc func(string, rune, int) (*csvRecordReader, *os.File, error)
 	// file names
 	destinationsFn, ratesFn, destinationratesFn, timingsFn, destinationratetimingsFn, ratingprofilesFn,
-	sharedgroupsFn, lcrFn, actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn string
+	sharedgroupsFn, lcrFn, actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn, exchangeRatesFn, holidayCalendarsFn, dialPlansFn string
+	tolerant   bool            // quarantine the malformed rows instead of failing their category
+	quarantine []*CSVLoadError // rows left out by the tolerant mode
 }
 
 func NewFileCSVStorage(sep rune,
 	destinationsFn, timingsFn, ratesFn, destinationratesFn, destinationratetimingsFn, ratingprofilesFn, sharedgroupsFn, lcrFn,
-	actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn string) *CSVStorage {
+	actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn, exchangeRatesFn, holidayCalendarsFn, dialPlansFn string) *CSVStorage {
 	c := new(CSVStorage)
 	c.sep = sep
 	c.readerFunc = openFileCSVStorage
 	c.destinationsFn, c.timingsFn, c.ratesFn, c.destinationratesFn, c.destinationratetimingsFn, c.ratingprofilesFn,
-		c.sharedgroupsFn, c.lcrFn, c.actionsFn, c.actiontimingsFn, c.actiontriggersFn, c.accountactionsFn, c.derivedChargersFn, c.cdrStatsFn, c.usersFn, c.aliasesFn, c.resLimitsFn = destinationsFn, timingsFn,
-		ratesFn, destinationratesFn, destinationratetimingsFn, ratingprofilesFn, sharedgroupsFn, lcrFn, actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn
+		c.sharedgroupsFn, c.lcrFn, c.actionsFn, c.actiontimingsFn, c.actiontriggersFn, c.accountactionsFn, c.derivedChargersFn, c.cdrStatsFn, c.usersFn, c.aliasesFn, c.resLimitsFn, c.exchangeRatesFn, c.holidayCalendarsFn, c.dialPlansFn = destinationsFn, timingsFn,
+		ratesFn, destinationratesFn, destinationratetimingsFn, ratingprofilesFn, sharedgroupsFn, lcrFn, actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn, exchangeRatesFn, holidayCalendarsFn, dialPlansFn
 	return c
 }
 
 func NewStringCSVStorage(sep rune,
 	destinationsFn, timingsFn, ratesFn, destinationratesFn, destinationratetimingsFn, ratingprofilesFn, sharedgroupsFn, lcrFn,
-	actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn string) *CSVStorage {
+	actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn, exchangeRatesFn, holidayCalendarsFn, dialPlansFn string) *CSVStorage {
 	c := NewFileCSVStorage(sep, destinationsFn, timingsFn, ratesFn, destinationratesFn, destinationratetimingsFn,
-		ratingprofilesFn, sharedgroupsFn, lcrFn, actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn)
+		ratingprofilesFn, sharedgroupsFn, lcrFn, actionsFn, actiontimingsFn, actiontriggersFn, accountactionsFn, derivedChargersFn, cdrStatsFn, usersFn, aliasesFn, resLimitsFn, exchangeRatesFn, holidayCalendarsFn, dialPlansFn)
 	c.readerFunc = openStringCSVStorage
 	return c
 }
 
-func openFileCSVStorage(fn string, comma rune, nrFields int) (csvReader *csv.Reader, fp *os.File, err error) {
+// SetVariables enables the expansion of the ${NAME} references inside the sources
+func (csvs *CSVStorage) SetVariables(vars *TPVariables) {
+	if vars == nil {
+		return
+	}
+	readerFunc := csvs.readerFunc
+	csvs.readerFunc = func(fn string, comma rune, nrFields int) (*csvRecordReader, *os.File, error) {
+		csvReader, fp, err := readerFunc(fn, comma, nrFields)
+		if csvReader != nil {
+			csvReader.vars = vars
+		}
+		return csvReader, fp, err
+	}
+}
+
+// SetTolerant enables loading the remainder of a category when some of its rows are malformed,
+// the rows left out being available via QuarantinedRows
+func (csvs *CSVStorage) SetTolerant(tolerant bool) {
+	csvs.tolerant = tolerant
+}
+
+// QuarantinedRows returns the rows left out by the tolerant mode, in the order read
+func (csvs *CSVStorage) QuarantinedRows() []*CSVLoadError {
+	return csvs.quarantine
+}
+
+// rowError locates err on the last row read, returning nil if the row was quarantined
+func (csvs *CSVStorage) rowError(cr *csvRecordReader, err error) error {
+	le := cr.loadError(err)
+	if !csvs.tolerant {
+		return le
+	}
+	log.Print("quarantined row: ", le)
+	csvs.quarantine = append(csvs.quarantine, le)
+	return nil
+}
+
+// WriteQuarantineReport writes the quarantined rows as csv records: file, line, column, reason
+func WriteQuarantineReport(w io.Writer, rows []*CSVLoadError) error {
+	csvWriter := csv.NewWriter(w)
+	for _, row := range rows {
+		if err := csvWriter.Write([]string{row.FileName, strconv.Itoa(row.Line),
+			strconv.Itoa(row.Column), row.Err.Error()}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func openFileCSVStorage(fn string, comma rune, nrFields int) (csvReader *csvRecordReader, fp *os.File, err error) {
 	fp, err = os.Open(fn)
 	if err != nil {
 		return
 	}
-	csvReader = csv.NewReader(fp)
-	csvReader.Comma = comma
-	csvReader.Comment = utils.COMMENT_CHAR
-	csvReader.FieldsPerRecord = nrFields
-	csvReader.TrailingComma = true
+	csvReader = newCSVRecordReader(fn, fp, comma, nrFields)
+	return
+}
+
+func openStringCSVStorage(data string, comma rune, nrFields int) (csvReader *csvRecordReader, fp *os.File, err error) {
+	csvReader = newCSVRecordReader("", strings.NewReader(data), comma, nrFields)
 	return
 }
 
-func openStringCSVStorage(data string, comma rune, nrFields int) (csvReader *csv.Reader, fp *os.File, err error) {
-	csvReader = csv.NewReader(strings.NewReader(data))
+// CSVLoadError points towards the tariff plan row which failed to load
+type CSVLoadError struct {
+	FileName string
+	Line     int
+	Column   int // 0 if the error concerns the whole row
+	Err      error
+}
+
+func (le *CSVLoadError) Error() string {
+	pos := fmt.Sprintf("line %d", le.Line)
+	if le.Column != 0 {
+		pos += fmt.Sprintf(", column %d", le.Column)
+	}
+	if le.FileName != "" {
+		pos = fmt.Sprintf("file <%s>, %s", le.FileName, pos)
+	}
+	return pos + ": " + le.Err.Error()
+}
+
+// csvLineCounter hands the content to the csv reader one line at a time, counting the lines read
+type csvLineCounter struct {
+	rdr     *bufio.Reader
+	pending []byte
+	line    int
+}
+
+func (lc *csvLineCounter) Read(p []byte) (n int, err error) {
+	if len(lc.pending) == 0 {
+		if lc.pending, err = lc.rdr.ReadBytes('\n'); len(lc.pending) == 0 {
+			return 0, err
+		}
+		lc.line++
+	}
+	n = copy(p, lc.pending)
+	lc.pending = lc.pending[n:]
+	return n, nil
+}
+
+// csvRecordReader is a csv.Reader knowing the line of the last record read
+type csvRecordReader struct {
+	*csv.Reader
+	fileName string
+	lc       *csvLineCounter
+	vars     *TPVariables // expanded inside the records read, nil to disable
+}
+
+// Read returns the next record with its variables expanded
+func (cr *csvRecordReader) Read() (record []string, err error) {
+	if record, err = cr.Reader.Read(); err != nil || cr.vars == nil {
+		return
+	}
+	if err = cr.vars.expandRecord(record); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func newCSVRecordReader(fileName string, rdr io.Reader, comma rune, nrFields int) *csvRecordReader {
+	lc := &csvLineCounter{rdr: bufio.NewReader(rdr)}
+	csvReader := csv.NewReader(lc)
 	csvReader.Comma = comma
 	csvReader.Comment = utils.COMMENT_CHAR
 	csvReader.FieldsPerRecord = nrFields
 	csvReader.TrailingComma = true
-	return
+	return &csvRecordReader{Reader: csvReader, fileName: fileName, lc: lc}
+}
+
+// loadError locates err on the last record read
+func (cr *csvRecordReader) loadError(err error) *CSVLoadError {
+	le := &CSVLoadError{FileName: cr.fileName, Line: cr.lc.line, Err: err}
+	switch errVal := err.(type) {
+	case *csv.ParseError:
+		le.Line, le.Column, le.Err = errVal.Line, errVal.Column, errVal.Err
+	case *csvFieldError:
+		le.Column = errVal.index + 1
+	}
+	return le
 }
 
 func (csvs *CSVStorage) GetTPTimings(tpid, id string) ([]*utils.ApierTPTiming, error) {
@@ -91,12 +217,18 @@ func (csvs *CSVStorage) GetTPTimings(tpid, id string) ([]*utils.ApierTPTiming, e
 	var tpTimings TpTimings
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in timings csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in timings csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpTiming, err := csvLoad(TpTiming{}, record); err != nil {
-			log.Print("error loading timing: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading timing: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			tm := tpTiming.(TpTiming)
 			tm.Tpid = tpid
@@ -107,7 +239,8 @@ func (csvs *CSVStorage) GetTPTimings(tpid, id string) ([]*utils.ApierTPTiming, e
 }
 
 func (csvs *CSVStorage) GetTPDestinations(tpid, id string) ([]*utils.TPDestination, error) {
-	csvReader, fp, err := csvs.readerFunc(csvs.destinationsFn, csvs.sep, getColumnCount(TpDestination{}))
+	nrFields := getColumnCount(TpDestination{})
+	csvReader, fp, err := csvs.readerFunc(csvs.destinationsFn, csvs.sep, -1) // Type column is optional
 	if err != nil {
 		//log.Print("Could not load destinations file: ", err)
 		// allow writing of the other values
@@ -119,19 +252,34 @@ func (csvs *CSVStorage) GetTPDestinations(tpid, id string) ([]*utils.TPDestinati
 	var tpDests TpDestinations
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in destinations csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in destinations csv: ", err)
+				return nil, err
+			}
+			continue
+		}
+		if len(record) == nrFields-1 {
+			record = append(record, "")
 		}
 		if tpDest, err := csvLoad(TpDestination{}, record); err != nil {
-			log.Print("error loading destination: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading destination: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			d := tpDest.(TpDestination)
 			d.Tpid = tpid
+			if _, err := (TpDestinations{d}).AsTPDestinations(); err != nil {
+				if err = csvs.rowError(csvReader, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			tpDests = append(tpDests, d)
 		}
 	}
-	return tpDests.AsTPDestinations(), nil
+	return tpDests.AsTPDestinations()
 }
 
 func (csvs *CSVStorage) GetTPRates(tpid, id string) ([]*utils.TPRate, error) {
@@ -147,15 +295,27 @@ func (csvs *CSVStorage) GetTPRates(tpid, id string) ([]*utils.TPRate, error) {
 	var tpRates TpRates
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in rates csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in rates csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpRate, err := csvLoad(TpRate{}, record); err != nil {
-			log.Print("error loading rate: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading rate: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			r := tpRate.(TpRate)
 			r.Tpid = tpid
+			if _, err := (TpRates{r}).AsMapRates(); err != nil { // check the rate slot while the line is known
+				if err = csvs.rowError(csvReader, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			tpRates = append(tpRates, r)
 		}
 	}
@@ -179,12 +339,18 @@ func (csvs *CSVStorage) GetTPDestinationRates(tpid, id string, p *utils.Paginato
 	var tpDestinationRates TpDestinationRates
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in destinationrates csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in destinationrates csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpRate, err := csvLoad(TpDestinationRate{}, record); err != nil {
-			log.Print("error loading destination rate: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading destination rate: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			dr := tpRate.(TpDestinationRate)
 			dr.Tpid = tpid
@@ -211,12 +377,18 @@ func (csvs *CSVStorage) GetTPRatingPlans(tpid, id string, p *utils.Paginator) ([
 	var tpRatingPlans TpRatingPlans
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in rating plans csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in rating plans csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpRate, err := csvLoad(TpRatingPlan{}, record); err != nil {
-			log.Print("error loading rating plan: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading rating plan: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			rp := tpRate.(TpRatingPlan)
 			rp.Tpid = tpid
@@ -231,7 +403,8 @@ func (csvs *CSVStorage) GetTPRatingPlans(tpid, id string, p *utils.Paginator) ([
 }
 
 func (csvs *CSVStorage) GetTPRatingProfiles(filter *utils.TPRatingProfile) ([]*utils.TPRatingProfile, error) {
-	csvReader, fp, err := csvs.readerFunc(csvs.ratingprofilesFn, csvs.sep, getColumnCount(TpRatingProfile{}))
+	nrFields := getColumnCount(TpRatingProfile{})
+	csvReader, fp, err := csvs.readerFunc(csvs.ratingprofilesFn, csvs.sep, -1) // DeactivationTime, MaxCost, MaxCostStrategy and TrafficShare columns are optional
 	if err != nil {
 		//log.Print("Could not load rating profiles file: ", err)
 		// allow writing of the other values
@@ -243,12 +416,21 @@ func (csvs *CSVStorage) GetTPRatingProfiles(filter *utils.TPRatingProfile) ([]*u
 	var tpRatingProfiles TpRatingProfiles
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line rating profiles csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line rating profiles csv: ", err)
+				return nil, err
+			}
+			continue
+		}
+		for len(record) >= nrFields-4 && len(record) < nrFields {
+			record = append(record, "")
 		}
 		if tpRate, err := csvLoad(TpRatingProfile{}, record); err != nil {
-			log.Print("error loading rating profile: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading rating profile: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			rpf := tpRate.(TpRatingProfile)
 			if filter != nil {
@@ -278,12 +460,18 @@ func (csvs *CSVStorage) GetTPSharedGroups(tpid, id string) ([]*utils.TPSharedGro
 	var tpSharedGroups TpSharedGroups
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in shared groups csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in shared groups csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpRate, err := csvLoad(TpSharedGroup{}, record); err != nil {
-			log.Print("error loading shared group: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading shared group: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			sg := tpRate.(TpSharedGroup)
 			sg.Tpid = tpid
@@ -309,12 +497,19 @@ func (csvs *CSVStorage) GetTPLCRs(filter *utils.TPLcrRules) ([]*utils.TPLcrRules
 	}
 	var tpLCRs TpLcrRules
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
-		if tpRate, err := csvLoad(TpLcrRule{}, record); err != nil {
-			if err != nil {
+		if err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
 				log.Print("bad line in lcr rules csv: ", err)
 				return nil, err
 			}
-			return nil, err
+			continue
+		}
+		if tpRate, err := csvLoad(TpLcrRule{}, record); err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading lcr rule: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			lcr := tpRate.(TpLcrRule)
 			if filter != nil {
@@ -343,12 +538,18 @@ func (csvs *CSVStorage) GetTPActions(tpid, id string) ([]*utils.TPActions, error
 	var tpActions TpActions
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in actions csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in actions csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpAction, err := csvLoad(TpAction{}, record); err != nil {
-			log.Print("error loading action: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading action: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			a := tpAction.(TpAction)
 			a.Tpid = tpid
@@ -374,9 +575,19 @@ func (csvs *CSVStorage) GetTPActionPlans(tpid, id string) ([]*utils.TPActionPlan
 	}
 	var tpActionPlans TpActionPlans
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
+		if err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in action plans csv: ", err)
+				return nil, err
+			}
+			continue
+		}
 		if tpRate, err := csvLoad(TpActionPlan{}, record); err != nil {
-			log.Print("error loading action plan: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading action plan: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			ap := tpRate.(TpActionPlan)
 			ap.Tpid = tpid
@@ -403,12 +614,18 @@ func (csvs *CSVStorage) GetTPActionTriggers(tpid, id string) ([]*utils.TPActionT
 	var tpActionTriggers TpActionTriggers
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in action triggers csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in action triggers csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpAt, err := csvLoad(TpActionTrigger{}, record); err != nil {
-			log.Print("error loading action trigger: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading action trigger: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			at := tpAt.(TpActionTrigger)
 			at.Tpid = tpid
@@ -435,12 +652,18 @@ func (csvs *CSVStorage) GetTPAccountActions(filter *utils.TPAccountActions) ([]*
 	var tpAccountActions TpAccountActions
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in account actions csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in account actions csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpAa, err := csvLoad(TpAccountAction{}, record); err != nil {
-			log.Print("error loading account action: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading account action: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			aa := tpAa.(TpAccountAction)
 			if filter != nil {
@@ -470,12 +693,18 @@ func (csvs *CSVStorage) GetTPDerivedChargers(filter *utils.TPDerivedChargers) ([
 	var tpDerivedChargers TpDerivedChargers
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in derived chargers csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in derived chargers csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tp, err := csvLoad(TpDerivedCharger{}, record); err != nil {
-			log.Print("error loading derived charger: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading derived charger: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			dc := tp.(TpDerivedCharger)
 			if filter != nil {
@@ -505,12 +734,18 @@ func (csvs *CSVStorage) GetTPCdrStats(tpid, id string) ([]*utils.TPCdrStats, err
 	var tpCdrStats TpCdrStats
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in cdr stats csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in cdr stats csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpCdrStat, err := csvLoad(TpCdrstat{}, record); err != nil {
-			log.Print("error loading cdr stat: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading cdr stat: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			cs := tpCdrStat.(TpCdrstat)
 			cs.Tpid = tpid
@@ -537,12 +772,18 @@ func (csvs *CSVStorage) GetTPUsers(filter *utils.TPUsers) ([]*utils.TPUsers, err
 	var tpUsers TpUsers
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in users csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in users csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpUser, err := csvLoad(TpUser{}, record); err != nil {
-			log.Print("error loading user: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading user: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			u := tpUser.(TpUser)
 			if filter != nil {
@@ -571,12 +812,18 @@ func (csvs *CSVStorage) GetTPAliases(filter *utils.TPAliases) ([]*utils.TPAliase
 	var tpAliases TpAliases
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in aliases csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in aliases csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpAlias, err := csvLoad(TpAlias{}, record); err != nil {
-			log.Print("error loading alias: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading alias: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			u := tpAlias.(TpAlias)
 			if filter != nil {
@@ -605,12 +852,18 @@ func (csvs *CSVStorage) GetTPResourceLimits(tpid, id string) ([]*utils.TPResourc
 	var tpResLimits TpResourceLimits
 	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
 		if err != nil {
-			log.Print("bad line in resourcelimits csv: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in resourcelimits csv: ", err)
+				return nil, err
+			}
+			continue
 		}
 		if tpResLimit, err := csvLoad(TpResourceLimit{}, record); err != nil {
-			log.Print("error loading resourcelimit: ", err)
-			return nil, err
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading resourcelimit: ", err)
+				return nil, err
+			}
+			continue
 		} else {
 			tpLimit := tpResLimit.(TpResourceLimit)
 			tpLimit.Tpid = tpid
@@ -620,6 +873,117 @@ func (csvs *CSVStorage) GetTPResourceLimits(tpid, id string) ([]*utils.TPResourc
 	return tpResLimits.AsTPResourceLimits(), nil
 }
 
+func (csvs *CSVStorage) GetTPExchangeRates(tpid, fromCurrency string) ([]*utils.TPExchangeRate, error) {
+	csvReader, fp, err := csvs.readerFunc(csvs.exchangeRatesFn, csvs.sep, getColumnCount(TpExchangeRate{}))
+	if err != nil {
+		//log.Print("Could not load exchange rates file: ", err)
+		// allow writing of the other values
+		return nil, nil
+	}
+	if fp != nil {
+		defer fp.Close()
+	}
+	var tpExRates TpExchangeRates
+	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
+		if err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in exchange rates csv: ", err)
+				return nil, err
+			}
+			continue
+		}
+		if tpExRate, err := csvLoad(TpExchangeRate{}, record); err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading exchange rate: ", err)
+				return nil, err
+			}
+			continue
+		} else {
+			tpER := tpExRate.(TpExchangeRate)
+			if fromCurrency != "" && tpER.FromCurrency != fromCurrency {
+				continue
+			}
+			tpER.Tpid = tpid
+			tpExRates = append(tpExRates, &tpER)
+		}
+	}
+	return tpExRates.AsTPExchangeRates(), nil
+}
+
+func (csvs *CSVStorage) GetTPHolidayCalendars(tpid, id string) ([]*utils.TPHolidayCalendar, error) {
+	csvReader, fp, err := csvs.readerFunc(csvs.holidayCalendarsFn, csvs.sep, getColumnCount(TpHolidayCalendar{}))
+	if err != nil {
+		//log.Print("Could not load holiday calendars file: ", err)
+		// allow writing of the other values
+		return nil, nil
+	}
+	if fp != nil {
+		defer fp.Close()
+	}
+	var tpHolidays TpHolidayCalendars
+	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
+		if err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in holiday calendars csv: ", err)
+				return nil, err
+			}
+			continue
+		}
+		if tpHoliday, err := csvLoad(TpHolidayCalendar{}, record); err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading holiday calendar: ", err)
+				return nil, err
+			}
+			continue
+		} else {
+			tpHC := tpHoliday.(TpHolidayCalendar)
+			if id != "" && tpHC.Tag != id {
+				continue
+			}
+			tpHC.Tpid = tpid
+			tpHolidays = append(tpHolidays, &tpHC)
+		}
+	}
+	return tpHolidays.AsTPHolidayCalendars(), nil
+}
+
+func (csvs *CSVStorage) GetTPDialPlans(tpid, tenant string) ([]*utils.TPDialPlan, error) {
+	csvReader, fp, err := csvs.readerFunc(csvs.dialPlansFn, csvs.sep, getColumnCount(TpDialPlan{}))
+	if err != nil {
+		//log.Print("Could not load dial plans file: ", err)
+		// allow writing of the other values
+		return nil, nil
+	}
+	if fp != nil {
+		defer fp.Close()
+	}
+	var tpDPs TpDialPlans
+	for record, err := csvReader.Read(); err != io.EOF; record, err = csvReader.Read() {
+		if err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("bad line in dial plans csv: ", err)
+				return nil, err
+			}
+			continue
+		}
+		if tpRule, err := csvLoad(TpDialPlan{}, record); err != nil {
+			if err = csvs.rowError(csvReader, err); err != nil {
+				log.Print("error loading dial plan: ", err)
+				return nil, err
+			}
+			continue
+		} else {
+			tpDP := tpRule.(TpDialPlan)
+			if tenant != "" && tpDP.Tenant != tenant {
+				continue
+			}
+			tpDP.Tpid = tpid
+			tpDPs = append(tpDPs, &tpDP)
+		}
+	}
+	return tpDPs.AsTPDialPlans(), nil
+}
+
 func (csvs *CSVStorage) GetTpIds() ([]string, error) {
 	return nil, utils.ErrNotImplemented
 }
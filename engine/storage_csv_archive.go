@@ -0,0 +1,141 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// IsTPArchive returns true if the path points towards a supported tariff plan archive
+func IsTPArchive(fPath string) bool {
+	for _, sfx := range []string{utils.ZipSuffix, utils.TarSuffix, utils.TarGzSuffix, utils.TgzSuffix} {
+		if strings.HasSuffix(fPath, sfx) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewArchiveCSVStorage reads the tariff plan out of a .zip, .tar, .tar.gz or .tgz archive
+// Files are matched on their base name, so the archive can contain the TP folder itself
+func NewArchiveCSVStorage(sep rune, archivePath string) (*CSVStorage, error) {
+	files, err := readTPArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	return newFilesMapCSVStorage(sep, files), nil
+}
+
+// newFilesMapCSVStorage returns a CSVStorage reading from file contents indexed on file name
+func newFilesMapCSVStorage(sep rune, files map[string]string) *CSVStorage {
+	c := NewFileCSVStorage(sep, utils.DESTINATIONS_CSV, utils.TIMINGS_CSV, utils.RATES_CSV, utils.DESTINATION_RATES_CSV,
+		utils.RATING_PLANS_CSV, utils.RATING_PROFILES_CSV, utils.SHARED_GROUPS_CSV, utils.LCRS_CSV, utils.ACTIONS_CSV,
+		utils.ACTION_PLANS_CSV, utils.ACTION_TRIGGERS_CSV, utils.ACCOUNT_ACTIONS_CSV, utils.DERIVED_CHARGERS_CSV,
+		utils.CDR_STATS_CSV, utils.USERS_CSV, utils.ALIASES_CSV, utils.ResourceLimitsCsv, utils.ExchangeRatesCsv,
+		utils.HolidayCalendarsCsv, utils.DialPlansCsv)
+	c.readerFunc = func(fn string, comma rune, nrFields int) (*csvRecordReader, *os.File, error) {
+		content, has := files[fn]
+		if !has {
+			return nil, nil, utils.ErrNotFound
+		}
+		return newCSVRecordReader(fn, strings.NewReader(content), comma, nrFields), nil, nil
+	}
+	return c
+}
+
+// readTPArchive returns the content of the .csv files inside the archive, indexed on their base name
+func readTPArchive(archivePath string) (files map[string]string, err error) {
+	files = make(map[string]string)
+	addFile := func(fPath string, rdr io.Reader) error {
+		fName := path.Base(fPath)
+		if !strings.HasSuffix(fName, utils.CSVSuffix) {
+			return nil
+		}
+		if _, has := files[fName]; has {
+			return fmt.Errorf("duplicate file <%s> in archive <%s>", fName, archivePath)
+		}
+		content, err := ioutil.ReadAll(rdr)
+		if err != nil {
+			return err
+		}
+		files[fName] = string(content)
+		return nil
+	}
+	if strings.HasSuffix(archivePath, utils.ZipSuffix) {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		for _, zf := range zr.File {
+			if zf.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return nil, err
+			}
+			err = addFile(zf.Name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return files, nil
+	}
+	fp, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	var rdr io.Reader = fp
+	if strings.HasSuffix(archivePath, utils.TarGzSuffix) || strings.HasSuffix(archivePath, utils.TgzSuffix) {
+		gzr, err := gzip.NewReader(fp)
+		if err != nil {
+			return nil, err
+		}
+		defer gzr.Close()
+		rdr = gzr
+	}
+	tr := tar.NewReader(rdr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := addFile(hdr.Name, tr); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,112 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+var archiveTPFiles = map[string]string{
+	"tariffplans/" + utils.DESTINATIONS_CSV: "#Tag,Prefix\nDST_1002,1002\nDST_1003,1003\n",
+	"tariffplans/" + utils.TIMINGS_CSV:      "ALWAYS,*any,*any,*any,*any,00:00:00\n",
+	"tariffplans/README.md":                 "not a tariff plan file",
+}
+
+func testArchiveTPStorage(t *testing.T, archivePath string) {
+	if !IsTPArchive(archivePath) {
+		t.Fatalf("%s not detected as archive", archivePath)
+	}
+	csvStor, err := NewArchiveCSVStorage(utils.CSV_SEP, archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsts, err := csvStor.GetTPDestinations("TEST_ARCHIVE", ""); err != nil {
+		t.Error(err)
+	} else if len(dsts) != 2 {
+		t.Errorf("Unexpected destinations: %s", utils.ToJSON(dsts))
+	}
+	if tms, err := csvStor.GetTPTimings("TEST_ARCHIVE", ""); err != nil {
+		t.Error(err)
+	} else if len(tms) != 1 || tms[0].ID != "ALWAYS" {
+		t.Errorf("Unexpected timings: %s", utils.ToJSON(tms))
+	}
+	if rts, err := csvStor.GetTPRates("TEST_ARCHIVE", ""); err != nil || len(rts) != 0 {
+		t.Errorf("Unexpected rates: %s, err: %v", utils.ToJSON(rts), err)
+	}
+}
+
+func TestArchiveCSVStorageTarGz(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cgr_tparchive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	archivePath := path.Join(tmpDir, "tariffplans"+utils.TarGzSuffix)
+	fp, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzw := gzip.NewWriter(fp)
+	tw := tar.NewWriter(gzw)
+	for fName, content := range archiveTPFiles {
+		if err := tw.WriteHeader(&tar.Header{Name: fName, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gzw.Close()
+	fp.Close()
+	testArchiveTPStorage(t, archivePath)
+}
+
+func TestArchiveCSVStorageZip(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cgr_tparchive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	archivePath := path.Join(tmpDir, "tariffplans"+utils.ZipSuffix)
+	fp, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(fp)
+	for fName, content := range archiveTPFiles {
+		w, err := zw.Create(fName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	zw.Close()
+	fp.Close()
+	testArchiveTPStorage(t, archivePath)
+}
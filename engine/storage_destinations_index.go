@@ -0,0 +1,198 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sync"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewDestinationsIndexDataDB serves the reverse destinations of dataDB out of an in-memory prefix index of idxType <*tree|*sharded>,
+// kept in sync with the destination writes and cache reloads passing through it
+func NewDestinationsIndexDataDB(dataDB DataDB, idxType string) (*DestinationsIndexDataDB, error) {
+	idx, err := utils.NewPrefixIndex(idxType, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DestinationsIndexDataDB{DataDB: dataDB, idxType: idxType, idx: idx}, nil
+}
+
+type DestinationsIndexDataDB struct {
+	DataDB
+	idxType string
+	idx     utils.PrefixIndex
+	idxMux  sync.RWMutex // protects idx replacement on full reloads
+}
+
+func (ddb *DestinationsIndexDataDB) index() utils.PrefixIndex {
+	ddb.idxMux.RLock()
+	defer ddb.idxMux.RUnlock()
+	return ddb.idx
+}
+
+// LoadIndex (re)builds the prefix index out of the destinations stored in dataDB, bulk loading it before swapping it in
+func (ddb *DestinationsIndexDataDB) LoadIndex() (err error) {
+	keys, err := ddb.DataDB.GetKeysForPrefix(utils.DESTINATION_PREFIX)
+	if err != nil {
+		return
+	}
+	values := make(map[string][]string)
+	for _, key := range keys {
+		dest, err := ddb.DataDB.GetDestination(key[len(utils.DESTINATION_PREFIX):], false, utils.NonTransactional)
+		if err != nil {
+			return err
+		}
+		for _, p := range dest.Prefixes {
+			rvKey := utils.ReverseDestinationKey(p)
+			if !utils.IsSliceMember(values[rvKey], dest.Id) {
+				values[rvKey] = append(values[rvKey], dest.Id)
+			}
+		}
+	}
+	idx, err := utils.NewPrefixIndex(ddb.idxType, values)
+	if err != nil {
+		return
+	}
+	ddb.idxMux.Lock()
+	ddb.idx = idx
+	ddb.idxMux.Unlock()
+	return
+}
+
+// SplitDestination returns the reverse destination keys of dst, longest first, restricted to the indexed ones
+func (ddb *DestinationsIndexDataDB) SplitDestination(dst string) []string {
+	keys := utils.SplitDestination(dst, MIN_PREFIX_MATCH)
+	if len(keys) == 0 {
+		return nil
+	}
+	return ddb.index().MatchPrefixes(keys[0], len(keys[len(keys)-1]))
+}
+
+// MatchReverseDestinations returns the reverse destination keys of dst together with their destination IDs, longest first,
+// out of one index traversal
+func (ddb *DestinationsIndexDataDB) MatchReverseDestinations(dst string) ([]string, [][]string) {
+	keys := utils.SplitDestination(dst, MIN_PREFIX_MATCH)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return ddb.index().MatchValues(keys[0], len(keys[len(keys)-1]))
+}
+
+// refreshKey syncs the index with the reverse destination stored in dataDB
+func (ddb *DestinationsIndexDataDB) refreshKey(key string) error {
+	ids, err := ddb.DataDB.GetReverseDestination(key, false, utils.NonTransactional)
+	if err != nil && err != utils.ErrNotFound {
+		return err
+	}
+	ddb.index().Set(key, ids)
+	return nil
+}
+
+func (ddb *DestinationsIndexDataDB) GetReverseDestination(key string, skipCache bool, transactionID string) ([]string, error) {
+	if skipCache {
+		ids, err := ddb.DataDB.GetReverseDestination(key, skipCache, transactionID)
+		if err == nil || err == utils.ErrNotFound {
+			ddb.index().Set(key, ids)
+		}
+		return ids, err
+	}
+	if ids, has := ddb.index().Get(key); has {
+		return ids, nil
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (ddb *DestinationsIndexDataDB) SetReverseDestination(dest *Destination, transactionID string) (err error) {
+	if err = ddb.DataDB.SetReverseDestination(dest, transactionID); err != nil {
+		return
+	}
+	for _, p := range dest.Prefixes {
+		ddb.index().AddValue(utils.ReverseDestinationKey(p), dest.Id)
+	}
+	return
+}
+
+func (ddb *DestinationsIndexDataDB) UpdateReverseDestination(oldDest, newDest *Destination, transactionID string) (err error) {
+	if err = ddb.DataDB.UpdateReverseDestination(oldDest, newDest, transactionID); err != nil {
+		return
+	}
+	if oldDest != nil {
+		for _, p := range oldDest.Prefixes {
+			ddb.index().RemoveValue(utils.ReverseDestinationKey(p), oldDest.Id)
+		}
+	}
+	for _, p := range newDest.Prefixes {
+		ddb.index().AddValue(utils.ReverseDestinationKey(p), newDest.Id)
+	}
+	return
+}
+
+func (ddb *DestinationsIndexDataDB) RemoveDestination(destID, transactionID string) (err error) {
+	dest, err := ddb.DataDB.GetDestination(destID, false, transactionID)
+	if err != nil {
+		return
+	}
+	if err = ddb.DataDB.RemoveDestination(destID, transactionID); err != nil {
+		return
+	}
+	for _, p := range dest.Prefixes {
+		ddb.index().RemoveValue(utils.ReverseDestinationKey(p), destID)
+	}
+	return
+}
+
+// LoadRatingCache keeps the index in sync with the reverse destinations reloaded into cache
+func (ddb *DestinationsIndexDataDB) LoadRatingCache(dstIDs, rvDstIDs, rplIDs, rpfIDs, actIDs, aplIDs, aapIDs, atrgIDs, sgIDs, lcrIDs, dcIDs []string) (err error) {
+	if err = ddb.DataDB.LoadRatingCache(dstIDs, rvDstIDs, rplIDs, rpfIDs, actIDs, aplIDs, aapIDs, atrgIDs, sgIDs, lcrIDs, dcIDs); err != nil {
+		return
+	}
+	return ddb.syncKeys(rvDstIDs)
+}
+
+// CacheDataFromDB keeps the index in sync with the reverse destinations reloaded into cache
+func (ddb *DestinationsIndexDataDB) CacheDataFromDB(prefix string, IDs []string, mustBeCached bool) (err error) {
+	if err = ddb.DataDB.CacheDataFromDB(prefix, IDs, mustBeCached); err != nil || prefix != utils.REVERSE_DESTINATION_PREFIX {
+		return
+	}
+	return ddb.syncKeys(IDs)
+}
+
+// CacheDataGenerationFromDB keeps the index in sync with the reverse destinations reloaded into cache
+func (ddb *DestinationsIndexDataDB) CacheDataGenerationFromDB(prefixIDs map[string][]string, mustBeCached bool) (err error) {
+	if err = ddb.DataDB.CacheDataGenerationFromDB(prefixIDs, mustBeCached); err != nil {
+		return
+	}
+	if IDs, has := prefixIDs[utils.REVERSE_DESTINATION_PREFIX]; has {
+		return ddb.syncKeys(IDs)
+	}
+	return
+}
+
+// syncKeys refreshes the reverse destination keys out of dataDB, nil keys meaning all
+func (ddb *DestinationsIndexDataDB) syncKeys(keys []string) (err error) {
+	if keys == nil {
+		return ddb.LoadIndex()
+	}
+	for _, key := range keys {
+		if err = ddb.refreshKey(key); err != nil {
+			return
+		}
+	}
+	return
+}
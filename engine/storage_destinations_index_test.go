@@ -0,0 +1,72 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestDestinationsIndexDataDB(t *testing.T) {
+	for _, idxType := range []string{utils.MetaTree, utils.MetaSharded} {
+		ms, _ := NewMapStorage()
+		dst := &Destination{Id: "DST_IDX_777", Prefixes: []string{"777", "77712"}}
+		ms.SetDestination(dst, utils.NonTransactional)
+		ms.SetReverseDestination(dst, utils.NonTransactional)
+		ddb, err := NewDestinationsIndexDataDB(ms, idxType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ddb.LoadIndex(); err != nil {
+			t.Fatal(err)
+		}
+		eKeys := []string{"77712", "777"}
+		if rcv := ddb.SplitDestination("777123456"); !reflect.DeepEqual(eKeys, rcv) {
+			t.Errorf("Expecting: %+v, received: %+v", eKeys, rcv)
+		}
+		if ids, err := ddb.GetReverseDestination("777", false, utils.NonTransactional); err != nil || !reflect.DeepEqual([]string{dst.Id}, ids) {
+			t.Errorf("Received: %+v, err: %v", ids, err)
+		}
+		if dms := matchReverseDestinations(ddb, "777123456"); len(dms) != 2 || dms[0].Prefix != "77712" ||
+			!reflect.DeepEqual([]string{dst.Id}, dms[1].DestIDs) {
+			t.Errorf("%s unexpected matches: %s", idxType, utils.ToJSON(dms))
+		}
+		newDst := &Destination{Id: dst.Id, Prefixes: []string{"777", "7779"}}
+		if err := ddb.SetDestination(newDst, utils.NonTransactional); err != nil {
+			t.Fatal(err)
+		}
+		if err := ddb.UpdateReverseDestination(dst, newDst, utils.NonTransactional); err != nil {
+			t.Fatal(err)
+		}
+		eKeys = []string{"7779", "777"}
+		if rcv := ddb.SplitDestination("77791"); !reflect.DeepEqual(eKeys, rcv) {
+			t.Errorf("Expecting: %+v, received: %+v", eKeys, rcv)
+		}
+		if _, err := ddb.GetReverseDestination("77712", false, utils.NonTransactional); err != utils.ErrNotFound {
+			t.Errorf("Expecting ErrNotFound, received: %v", err)
+		}
+		if err := ddb.RemoveDestination(dst.Id, utils.NonTransactional); err != nil {
+			t.Fatal(err)
+		}
+		if rcv := ddb.SplitDestination("77791"); len(rcv) != 0 {
+			t.Errorf("Unexpected keys: %+v", rcv)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"reflect"
 
+	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/utils"
 	"github.com/ugorji/go/codec"
 	"gopkg.in/mgo.v2/bson"
@@ -47,6 +48,8 @@ type DataDB interface {
 	LoadRatingCache(dstIDs, rvDstIDs, rplIDs, rpfIDs, actIDs, aplIDs, aapIDs, atrgIDs, sgIDs, lcrIDs, dcIDs []string) error
 	GetRatingPlan(string, bool, string) (*RatingPlan, error)
 	SetRatingPlan(*RatingPlan, string) error
+	RemoveRatingPlan(string, string) error
+	GetRatingPlanPart(string, string) (*RatingPlan, error)
 	GetRatingProfile(string, bool, string) (*RatingProfile, error)
 	SetRatingProfile(*RatingProfile, string) error
 	RemoveRatingProfile(string, string) error
@@ -58,6 +61,7 @@ type DataDB interface {
 	UpdateReverseDestination(*Destination, *Destination, string) error
 	GetLCR(string, bool, string) (*LCR, error)
 	SetLCR(*LCR, string) error
+	RemoveLCR(string, string) error
 	SetCdrStats(*CdrStats) error
 	GetCdrStats(string) (*CdrStats, error)
 	GetAllCdrStats() ([]*CdrStats, error)
@@ -100,8 +104,26 @@ type DataDB interface {
 	GetResourceLimit(string, bool, string) (*ResourceLimit, error)
 	SetResourceLimit(*ResourceLimit, string) error
 	RemoveResourceLimit(string, string) error
+	GetResourceUsageSeries(string) (*ResourceUsageSeries, error)
+	SetResourceUsageSeries(*ResourceUsageSeries) error
+	GetExchangeRate(string, bool, string) (*ExchangeRate, error)
+	SetExchangeRate(*ExchangeRate, string) error
+	RemoveExchangeRate(string, string) error
+	GetHolidayCalendar(string, bool, string) (*HolidayCalendar, error)
+	SetHolidayCalendar(*HolidayCalendar, string) error
+	RemoveHolidayCalendar(string, string) error
+	GetDialPlan(string, bool, string) (*DialPlan, error)
+	SetDialPlan(*DialPlan, string) error
+	RemoveDialPlan(string, string) error
+	GetSplitCharging(string, bool, string) (*SplitCharging, error)
+	SetSplitCharging(*SplitCharging, string) error
+	RemoveSplitCharging(string, string) error
 	GetLoadHistory(int, bool, string) ([]*utils.LoadInstance, error)
 	AddLoadHistory(*utils.LoadInstance, int, string) error
+	GetTPSnapshot(string) (*TPSnapshot, error)
+	SetTPSnapshot(*TPSnapshot) error
+	RemoveTPSnapshot(string) error
+	GetTPSnapshotIDs() ([]string, error)
 	GetStructVersion() (*StructVersion, error)
 	SetStructVersion(*StructVersion) error
 	GetReqFilterIndexes(dbKey string) (indexes map[string]map[string]utils.StringMap, err error)
@@ -109,6 +131,8 @@ type DataDB interface {
 	MatchReqFilterIndex(dbKey, fieldValKey string) (itemIDs utils.StringMap, err error)
 	// CacheDataFromDB loads data to cache, prefix represents the cache prefix, IDs should be nil if all available data should be loaded
 	CacheDataFromDB(prefix string, IDs []string, mustBeCached bool) error // ToDo: Move this to dataManager
+	// CacheDataGenerationFromDB loads data of multiple prefixes to cache, swapped in for the readers only once completely loaded
+	CacheDataGenerationFromDB(prefixIDs map[string][]string, mustBeCached bool) error
 }
 
 type StorDB interface {
@@ -124,6 +148,8 @@ type CdrStorage interface {
 	GetSMCosts(cgrid, runid, originHost, originIDPrfx string) ([]*SMCost, error)
 	RemoveSMCost(*SMCost) error
 	GetCDRs(*utils.CDRsFilter, bool) ([]*CDR, int64, error)
+	GetCDRsAggregates(*utils.CDRsFilter, []string) ([]*utils.CDRsAggregate, error)
+	SetCDRIndexedFields([]string) error
 }
 
 type LoadStorage interface {
@@ -153,6 +179,9 @@ type LoadReader interface {
 	GetTPActionTriggers(string, string) ([]*utils.TPActionTriggers, error)
 	GetTPAccountActions(*utils.TPAccountActions) ([]*utils.TPAccountActions, error)
 	GetTPResourceLimits(string, string) ([]*utils.TPResourceLimit, error)
+	GetTPExchangeRates(string, string) ([]*utils.TPExchangeRate, error)
+	GetTPHolidayCalendars(string, string) ([]*utils.TPHolidayCalendar, error)
+	GetTPDialPlans(string, string) ([]*utils.TPDialPlan, error)
 }
 
 type LoadWriter interface {
@@ -174,6 +203,9 @@ type LoadWriter interface {
 	SetTPActionTriggers([]*utils.TPActionTriggers) error
 	SetTPAccountActions([]*utils.TPAccountActions) error
 	SetTPResourceLimits([]*utils.TPResourceLimit) error
+	SetTPExchangeRates([]*utils.TPExchangeRate) error
+	SetTPHolidayCalendars([]*utils.TPHolidayCalendar) error
+	SetTPDialPlans([]*utils.TPDialPlan) error
 }
 
 type Marshaler interface {
@@ -269,6 +301,21 @@ func (gm *GOBMarshaler) Unmarshal(data []byte, v interface{}) error {
 	return gob.NewDecoder(bytes.NewBuffer(data)).Decode(v)
 }
 
+// cacheGeneration stages the cache loads of all prefixes within one cache transaction, committed once all are loaded
+// so the readers see either the previous or the new generation of data, never a mix or the gaps in between
+func cacheGeneration(prefixIDs map[string][]string, mustBeCached bool,
+	cacheDataFromDB func(prefix string, IDs []string, mustBeCached bool, transactionID string) error) error {
+	transID := cache.BeginTransaction()
+	for prefix, IDs := range prefixIDs {
+		if err := cacheDataFromDB(prefix, IDs, mustBeCached, transID); err != nil {
+			cache.RollbackTransaction(transID)
+			return err
+		}
+	}
+	cache.CommitTransaction(transID)
+	return nil
+}
+
 // Decide the value of cacheCommit parameter based on transactionID
 func cacheCommit(transactionID string) bool {
 	if transactionID == utils.NonTransactional {
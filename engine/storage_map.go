@@ -249,9 +249,17 @@ func (ms *MapStorage) PreloadCacheForPrefix(prefix string) error {
 	return nil
 }
 
-// CacheDataFromDB loads data to cache,
-// prefix represents the cache prefix, IDs should be nil if all available data should be loaded
-func (ms *MapStorage) CacheDataFromDB(prefix string, IDs []string, mustBeCached bool) (err error) {
+// CacheDataFromDB loads the data of one prefix into cache as one generation, see CacheDataGenerationFromDB
+func (ms *MapStorage) CacheDataFromDB(prefix string, IDs []string, mustBeCached bool) error {
+	return ms.CacheDataGenerationFromDB(map[string][]string{prefix: IDs}, mustBeCached)
+}
+
+// CacheDataGenerationFromDB loads the data of multiple prefixes into cache, made visible to readers all at once
+func (ms *MapStorage) CacheDataGenerationFromDB(prefixIDs map[string][]string, mustBeCached bool) error {
+	return cacheGeneration(prefixIDs, mustBeCached, ms.cacheDataFromDB)
+}
+
+func (ms *MapStorage) cacheDataFromDB(prefix string, IDs []string, mustBeCached bool, transactionID string) (err error) {
 	if !utils.IsSliceMember([]string{utils.DESTINATION_PREFIX,
 		utils.REVERSE_DESTINATION_PREFIX,
 		utils.RATING_PLAN_PREFIX,
@@ -330,33 +338,33 @@ func (ms *MapStorage) CacheDataFromDB(prefix string, IDs []string, mustBeCached
 		}
 		switch prefix {
 		case utils.DESTINATION_PREFIX:
-			_, err = ms.GetDestination(dataID, true, utils.NonTransactional)
+			_, err = ms.GetDestination(dataID, true, transactionID)
 		case utils.REVERSE_DESTINATION_PREFIX:
-			_, err = ms.GetReverseDestination(dataID, true, utils.NonTransactional)
+			_, err = ms.GetReverseDestination(dataID, true, transactionID)
 		case utils.RATING_PLAN_PREFIX:
-			_, err = ms.GetRatingPlan(dataID, true, utils.NonTransactional)
+			_, err = ms.GetRatingPlan(dataID, true, transactionID)
 		case utils.RATING_PROFILE_PREFIX:
-			_, err = ms.GetRatingProfile(dataID, true, utils.NonTransactional)
+			_, err = ms.GetRatingProfile(dataID, true, transactionID)
 		case utils.ACTION_PREFIX:
-			_, err = ms.GetActions(dataID, true, utils.NonTransactional)
+			_, err = ms.GetActions(dataID, true, transactionID)
 		case utils.ACTION_PLAN_PREFIX:
-			_, err = ms.GetActionPlan(dataID, true, utils.NonTransactional)
+			_, err = ms.GetActionPlan(dataID, true, transactionID)
 		case utils.AccountActionPlansPrefix:
-			_, err = ms.GetAccountActionPlans(dataID, true, utils.NonTransactional)
+			_, err = ms.GetAccountActionPlans(dataID, true, transactionID)
 		case utils.ACTION_TRIGGER_PREFIX:
-			_, err = ms.GetActionTriggers(dataID, true, utils.NonTransactional)
+			_, err = ms.GetActionTriggers(dataID, true, transactionID)
 		case utils.SHARED_GROUP_PREFIX:
-			_, err = ms.GetSharedGroup(dataID, true, utils.NonTransactional)
+			_, err = ms.GetSharedGroup(dataID, true, transactionID)
 		case utils.DERIVEDCHARGERS_PREFIX:
-			_, err = ms.GetDerivedChargers(dataID, true, utils.NonTransactional)
+			_, err = ms.GetDerivedChargers(dataID, true, transactionID)
 		case utils.LCR_PREFIX:
-			_, err = ms.GetLCR(dataID, true, utils.NonTransactional)
+			_, err = ms.GetLCR(dataID, true, transactionID)
 		case utils.ALIASES_PREFIX:
-			_, err = ms.GetAlias(dataID, true, utils.NonTransactional)
+			_, err = ms.GetAlias(dataID, true, transactionID)
 		case utils.REVERSE_ALIASES_PREFIX:
-			_, err = ms.GetReverseAlias(dataID, true, utils.NonTransactional)
+			_, err = ms.GetReverseAlias(dataID, true, transactionID)
 		case utils.ResourceLimitsPrefix:
-			_, err = ms.GetResourceLimit(dataID, true, utils.NonTransactional)
+			_, err = ms.GetResourceLimit(dataID, true, transactionID)
 		}
 		if err != nil {
 			return utils.NewCGRError(utils.REDIS,
@@ -406,18 +414,9 @@ func (ms *MapStorage) GetRatingPlan(key string, skipCache bool, transactionID st
 	}
 	cCommit := cacheCommit(transactionID)
 	if values, ok := ms.dict[key]; ok {
-		b := bytes.NewBuffer(values)
-		r, err := zlib.NewReader(b)
-		if err != nil {
-			return nil, err
-		}
-		out, err := ioutil.ReadAll(r)
-		if err != nil {
+		if rp, err = unmarshalRatingPlan(ms.ms, values); err != nil {
 			return nil, err
 		}
-		r.Close()
-		rp = new(RatingPlan)
-		err = ms.ms.Unmarshal(out, rp)
 	} else {
 		cache.Set(key, nil, cCommit, transactionID)
 		return nil, utils.ErrNotFound
@@ -429,12 +428,22 @@ func (ms *MapStorage) GetRatingPlan(key string, skipCache bool, transactionID st
 func (ms *MapStorage) SetRatingPlan(rp *RatingPlan, transactionID string) (err error) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
-	result, err := ms.ms.Marshal(rp)
-	var b bytes.Buffer
-	w := zlib.NewWriter(&b)
-	w.Write(result)
-	w.Close()
-	ms.dict[utils.RATING_PLAN_PREFIX+rp.Id] = b.Bytes()
+	header, parts := splitRatingPlan(rp)
+	result, err := marshalRatingPlan(header)
+	if err != nil {
+		return
+	}
+	ms.removeRatingPlanParts(rp.Id)
+	for _, part := range parts {
+		var partResult []byte
+		if partResult, err = marshalRatingPlan(part); err != nil {
+			return
+		}
+		for dID := range part.DestinationRates {
+			ms.dict[ratingPlanPartKey(rp.Id, dID)] = partResult
+		}
+	}
+	ms.dict[utils.RATING_PLAN_PREFIX+rp.Id] = result
 	response := 0
 	if historyScribe != nil {
 		go historyScribe.Call("HistoryV1.Record", rp.GetHistoryRecord(), &response)
@@ -443,6 +452,36 @@ func (ms *MapStorage) SetRatingPlan(rp *RatingPlan, transactionID string) (err e
 	return
 }
 
+func (ms *MapStorage) RemoveRatingPlan(key string, transactionID string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.removeRatingPlanParts(key)
+	key = utils.RATING_PLAN_PREFIX + key
+	delete(ms.dict, key)
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+// removeRatingPlanParts deletes the destination parts of a split rating plan, the lock should be held by the caller
+func (ms *MapStorage) removeRatingPlanParts(planID string) {
+	prefix := utils.RatingPlanPartPrefix + planID + utils.CONCATENATED_KEY_SEP
+	for key := range ms.dict {
+		if strings.HasPrefix(key, prefix) {
+			delete(ms.dict, key)
+		}
+	}
+}
+
+func (ms *MapStorage) GetRatingPlanPart(planID, destID string) (rp *RatingPlan, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[ratingPlanPartKey(planID, destID)]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	return unmarshalRatingPlan(ms.ms, values)
+}
+
 func (ms *MapStorage) GetRatingProfile(key string, skipCache bool, transactionID string) (rpf *RatingProfile, err error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
@@ -486,8 +525,8 @@ func (ms *MapStorage) RemoveRatingProfile(key string, transactionID string) (err
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 	for k := range ms.dict {
-		if strings.HasPrefix(k, key) {
-			delete(ms.dict, key)
+		if strings.HasPrefix(k, utils.RATING_PROFILE_PREFIX+key) {
+			delete(ms.dict, k)
 			cache.RemKey(k, cacheCommit(transactionID), transactionID)
 			response := 0
 			rpf := &RatingProfile{Id: key}
@@ -531,6 +570,15 @@ func (ms *MapStorage) SetLCR(lcr *LCR, transactionID string) (err error) {
 	return
 }
 
+func (ms *MapStorage) RemoveLCR(id string, transactionID string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := utils.LCR_PREFIX + id
+	delete(ms.dict, key)
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
 func (ms *MapStorage) GetDestination(key string, skipCache bool, transactionID string) (dest *Destination, err error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
@@ -607,13 +655,14 @@ func (ms *MapStorage) GetReverseDestination(prefix string, skipCache bool, trans
 	} else {
 		ids = idMap.Slice()
 	}
+	utils.AddIPPrefixKey(prefix[len(utils.REVERSE_DESTINATION_PREFIX):])
 	cache.Set(prefix, ids, cacheCommit(transactionID), transactionID)
 	return
 }
 
 func (ms *MapStorage) SetReverseDestination(dest *Destination, transactionID string) (err error) {
 	for _, p := range dest.Prefixes {
-		key := utils.REVERSE_DESTINATION_PREFIX + p
+		key := utils.REVERSE_DESTINATION_PREFIX + utils.ReverseDestinationKey(p)
 		ms.mu.Lock()
 		ms.dict.sadd(key, dest.Id, ms.ms)
 		ms.mu.Unlock()
@@ -636,6 +685,7 @@ func (ms *MapStorage) RemoveDestination(destID string, transactionID string) (er
 	cache.RemKey(key, cacheCommit(transactionID), transactionID)
 
 	for _, prefix := range d.Prefixes {
+		prefix = utils.ReverseDestinationKey(prefix)
 		ms.mu.Lock()
 		ms.dict.srem(utils.REVERSE_DESTINATION_PREFIX+prefix, destID, ms.ms)
 		ms.mu.Unlock()
@@ -681,6 +731,7 @@ func (ms *MapStorage) UpdateReverseDestination(oldDest, newDest *Destination, tr
 	cCommit := cacheCommit(transactionID)
 	var err error
 	for _, obsoletePrefix := range obsoletePrefixes {
+		obsoletePrefix = utils.ReverseDestinationKey(obsoletePrefix)
 		ms.mu.Lock()
 		ms.dict.srem(utils.REVERSE_DESTINATION_PREFIX+obsoletePrefix, oldDest.Id, ms.ms)
 		ms.mu.Unlock()
@@ -689,6 +740,7 @@ func (ms *MapStorage) UpdateReverseDestination(oldDest, newDest *Destination, tr
 
 	// add the id to all new prefixes
 	for _, addedPrefix := range addedPrefixes {
+		addedPrefix = utils.ReverseDestinationKey(addedPrefix)
 		ms.mu.Lock()
 		ms.dict.sadd(utils.REVERSE_DESTINATION_PREFIX+addedPrefix, newDest.Id, ms.ms)
 		ms.mu.Unlock()
@@ -1034,6 +1086,60 @@ func (ms *MapStorage) RemoveAlias(key string, transactionID string) error {
 	return nil
 }
 
+func (ms *MapStorage) GetTPSnapshot(id string) (tps *TPSnapshot, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.TPSnapshotPrefix+id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	b := bytes.NewBuffer(values)
+	r, err := zlib.NewReader(b)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	r.Close()
+	err = ms.ms.Unmarshal(out, &tps)
+	return
+}
+
+func (ms *MapStorage) SetTPSnapshot(tps *TPSnapshot) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(tps)
+	if err != nil {
+		return
+	}
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(result)
+	w.Close()
+	ms.dict[utils.TPSnapshotPrefix+tps.ID] = b.Bytes()
+	return
+}
+
+func (ms *MapStorage) RemoveTPSnapshot(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.TPSnapshotPrefix+id)
+	return nil
+}
+
+func (ms *MapStorage) GetTPSnapshotIDs() (ids []string, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	for key := range ms.dict {
+		if strings.HasPrefix(key, utils.TPSnapshotPrefix) {
+			ids = append(ids, key[len(utils.TPSnapshotPrefix):])
+		}
+	}
+	return
+}
+
 func (ms *MapStorage) GetLoadHistory(limitItems int, skipCache bool, transactionID string) ([]*utils.LoadInstance, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
@@ -1427,6 +1533,212 @@ func (ms *MapStorage) RemoveResourceLimit(id string, transactionID string) error
 	return nil
 }
 
+func (ms *MapStorage) GetResourceUsageSeries(id string) (rus *ResourceUsageSeries, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.ResourceUsageSeriesPrefix+id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &rus)
+	return
+}
+
+func (ms *MapStorage) SetResourceUsageSeries(rus *ResourceUsageSeries) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(rus)
+	if err != nil {
+		return err
+	}
+	ms.dict[utils.ResourceUsageSeriesPrefix+rus.ID] = result
+	return nil
+}
+
+func (ms *MapStorage) GetExchangeRate(id string, skipCache bool, transactionID string) (er *ExchangeRate, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	key := utils.ExchangeRatesPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x != nil {
+				return x.(*ExchangeRate), nil
+			}
+			return nil, utils.ErrNotFound
+		}
+	}
+	values, ok := ms.dict[key]
+	if !ok {
+		cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		return nil, utils.ErrNotFound
+	}
+	if err = ms.ms.Unmarshal(values, &er); err != nil {
+		return nil, err
+	}
+	cache.Set(key, er, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MapStorage) SetExchangeRate(er *ExchangeRate, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(er)
+	if err != nil {
+		return err
+	}
+	key := utils.ExchangeRatesPrefix + er.ID
+	ms.dict[key] = result
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MapStorage) RemoveExchangeRate(id string, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := utils.ExchangeRatesPrefix + id
+	delete(ms.dict, key)
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MapStorage) GetHolidayCalendar(id string, skipCache bool, transactionID string) (hc *HolidayCalendar, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	key := utils.HolidayCalendarsPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x != nil {
+				return x.(*HolidayCalendar), nil
+			}
+			return nil, utils.ErrNotFound
+		}
+	}
+	values, ok := ms.dict[key]
+	if !ok {
+		cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		return nil, utils.ErrNotFound
+	}
+	if err = ms.ms.Unmarshal(values, &hc); err != nil {
+		return nil, err
+	}
+	cache.Set(key, hc, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MapStorage) SetHolidayCalendar(hc *HolidayCalendar, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(hc)
+	if err != nil {
+		return err
+	}
+	key := utils.HolidayCalendarsPrefix + hc.ID
+	ms.dict[key] = result
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MapStorage) RemoveHolidayCalendar(id string, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := utils.HolidayCalendarsPrefix + id
+	delete(ms.dict, key)
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MapStorage) GetDialPlan(tenant string, skipCache bool, transactionID string) (dp *DialPlan, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	key := utils.DialPlansPrefix + tenant
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x != nil {
+				return x.(*DialPlan), nil
+			}
+			return nil, utils.ErrNotFound
+		}
+	}
+	values, ok := ms.dict[key]
+	if !ok {
+		cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		return nil, utils.ErrNotFound
+	}
+	if err = ms.ms.Unmarshal(values, &dp); err != nil {
+		return nil, err
+	}
+	cache.Set(key, dp, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MapStorage) SetDialPlan(dp *DialPlan, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(dp)
+	if err != nil {
+		return err
+	}
+	key := utils.DialPlansPrefix + dp.Tenant
+	ms.dict[key] = result
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MapStorage) RemoveDialPlan(tenant string, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := utils.DialPlansPrefix + tenant
+	delete(ms.dict, key)
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MapStorage) GetSplitCharging(id string, skipCache bool, transactionID string) (spc *SplitCharging, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	key := utils.SplitChargingsPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x != nil {
+				return x.(*SplitCharging), nil
+			}
+			return nil, utils.ErrNotFound
+		}
+	}
+	values, ok := ms.dict[key]
+	if !ok {
+		cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		return nil, utils.ErrNotFound
+	}
+	if err = ms.ms.Unmarshal(values, &spc); err != nil {
+		return nil, err
+	}
+	cache.Set(key, spc, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MapStorage) SetSplitCharging(spc *SplitCharging, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(spc)
+	if err != nil {
+		return err
+	}
+	key := utils.SplitChargingsPrefix + spc.ID
+	ms.dict[key] = result
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MapStorage) RemoveSplitCharging(id string, transactionID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	key := utils.SplitChargingsPrefix + id
+	delete(ms.dict, key)
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
 func (ms *MapStorage) GetReqFilterIndexes(dbKey string) (indexes map[string]map[string]utils.StringMap, err error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"regexp"
 	"strings"
 	"time"
 
@@ -55,9 +56,16 @@ const (
 	colUsr = "users"
 	colCrs = "cdr_stats"
 	colLht = "load_history"
+	colTps = "tp_snapshots"
 	colVer = "versions"
 	colRL  = "resource_limits"
 	colRFI = "request_filter_indexes"
+	colRUS = "resource_usage_series"
+	colExr = "exchange_rates"
+	colHol = "holiday_calendars"
+	colSpc = "split_chargings"
+	colRpp = "rating_plan_parts"
+	colDpl = "dial_plans"
 )
 
 var (
@@ -86,6 +94,7 @@ var (
 	CostDetailsLow     = strings.ToLower(utils.COST_DETAILS)
 	DestinationLow     = strings.ToLower(utils.DESTINATION)
 	CostLow            = strings.ToLower(utils.COST)
+	ExtraInfoLow       = strings.ToLower(utils.ExtraInfo)
 )
 
 func NewMongoStorage(host, port, db, user, pass, storageType string, cdrsIndexes []string, cacheCfg *config.CacheConfig, loadHistorySize int) (ms *MongoStorage, err error) {
@@ -147,7 +156,7 @@ func (ms *MongoStorage) EnsureIndexes() (err error) {
 	}
 	var colectNames []string // collection names containing this index
 	if ms.storageType == utils.DataDB {
-		colectNames = []string{colAct, colApl, colAAp, colAtr, colDcs, colRls, colRpl, colLcr, colDst, colRds, colAls, colUsr, colLht}
+		colectNames = []string{colAct, colApl, colAAp, colAtr, colDcs, colRls, colRpl, colLcr, colDst, colRds, colAls, colUsr, colLht, colTps}
 	}
 	for _, col := range colectNames {
 		if err = db.C(col).EnsureIndex(idx); err != nil {
@@ -322,6 +331,10 @@ func (ms *MongoStorage) getColNameForPrefix(prefix string) (name string, ok bool
 		utils.LOADINST_KEY:               colLht,
 		utils.VERSION_PREFIX:             colVer,
 		utils.ResourceLimitsPrefix:       colRL,
+		utils.ExchangeRatesPrefix:        colExr,
+		utils.HolidayCalendarsPrefix:     colHol,
+		utils.DialPlansPrefix:            colDpl,
+		utils.SplitChargingsPrefix:       colSpc,
 	}
 	name, ok = colMap[prefix]
 	return
@@ -412,7 +425,7 @@ func (ms *MongoStorage) RebuildReverseForPrefix(prefix string) (err error) {
 }
 
 func (ms *MongoStorage) LoadRatingCache(dstIDs, rvDstIDs, rplIDs, rpfIDs, actIDs, aplIDs, aaPlIDs, atrgIDs, sgIDs, lcrIDs, dcIDs []string) (err error) {
-	for key, ids := range map[string][]string{
+	return ms.CacheDataGenerationFromDB(map[string][]string{
 		utils.DESTINATION_PREFIX:         dstIDs,
 		utils.REVERSE_DESTINATION_PREFIX: rvDstIDs,
 		utils.RATING_PLAN_PREFIX:         rplIDs,
@@ -424,31 +437,31 @@ func (ms *MongoStorage) LoadRatingCache(dstIDs, rvDstIDs, rplIDs, rpfIDs, actIDs
 		utils.SHARED_GROUP_PREFIX:        sgIDs,
 		utils.LCR_PREFIX:                 lcrIDs,
 		utils.DERIVEDCHARGERS_PREFIX:     dcIDs,
-	} {
-		if err = ms.CacheDataFromDB(key, ids, false); err != nil {
-			return
-		}
-	}
-	return
+	}, false)
 }
 
 func (ms *MongoStorage) LoadAccountingCache(alsIDs, rvAlsIDs, rlIDs []string) (err error) {
-	for key, ids := range map[string][]string{
+	return ms.CacheDataGenerationFromDB(map[string][]string{
 		utils.ALIASES_PREFIX:         alsIDs,
 		utils.REVERSE_ALIASES_PREFIX: rvAlsIDs,
 		utils.ResourceLimitsPrefix:   rlIDs,
-	} {
-		if err = ms.CacheDataFromDB(key, ids, false); err != nil {
-			return
-		}
-	}
-	return
+	}, false)
 }
 
 // CacheDataFromDB loads data to cache
 // prfx represents the cache prefix, ids should be nil if all available data should be loaded
 // mustBeCached specifies that data needs to be cached in order to be retrieved from db
-func (ms *MongoStorage) CacheDataFromDB(prfx string, ids []string, mustBeCached bool) (err error) {
+// CacheDataFromDB loads the data of one prefix into cache as one generation, see CacheDataGenerationFromDB
+func (ms *MongoStorage) CacheDataFromDB(prfx string, ids []string, mustBeCached bool) error {
+	return ms.CacheDataGenerationFromDB(map[string][]string{prfx: ids}, mustBeCached)
+}
+
+// CacheDataGenerationFromDB loads the data of multiple prefixes into cache, made visible to readers all at once
+func (ms *MongoStorage) CacheDataGenerationFromDB(prefixIDs map[string][]string, mustBeCached bool) error {
+	return cacheGeneration(prefixIDs, mustBeCached, ms.cacheDataFromDB)
+}
+
+func (ms *MongoStorage) cacheDataFromDB(prfx string, ids []string, mustBeCached bool, transactionID string) (err error) {
 	if !utils.IsSliceMember([]string{utils.DESTINATION_PREFIX,
 		utils.REVERSE_DESTINATION_PREFIX,
 		utils.RATING_PLAN_PREFIX,
@@ -527,33 +540,33 @@ func (ms *MongoStorage) CacheDataFromDB(prfx string, ids []string, mustBeCached
 		}
 		switch prfx {
 		case utils.DESTINATION_PREFIX:
-			_, err = ms.GetDestination(dataID, true, utils.NonTransactional)
+			_, err = ms.GetDestination(dataID, true, transactionID)
 		case utils.REVERSE_DESTINATION_PREFIX:
-			_, err = ms.GetReverseDestination(dataID, true, utils.NonTransactional)
+			_, err = ms.GetReverseDestination(dataID, true, transactionID)
 		case utils.RATING_PLAN_PREFIX:
-			_, err = ms.GetRatingPlan(dataID, true, utils.NonTransactional)
+			_, err = ms.GetRatingPlan(dataID, true, transactionID)
 		case utils.RATING_PROFILE_PREFIX:
-			_, err = ms.GetRatingProfile(dataID, true, utils.NonTransactional)
+			_, err = ms.GetRatingProfile(dataID, true, transactionID)
 		case utils.ACTION_PREFIX:
-			_, err = ms.GetActions(dataID, true, utils.NonTransactional)
+			_, err = ms.GetActions(dataID, true, transactionID)
 		case utils.ACTION_PLAN_PREFIX:
-			_, err = ms.GetActionPlan(dataID, true, utils.NonTransactional)
+			_, err = ms.GetActionPlan(dataID, true, transactionID)
 		case utils.AccountActionPlansPrefix:
-			_, err = ms.GetAccountActionPlans(dataID, true, utils.NonTransactional)
+			_, err = ms.GetAccountActionPlans(dataID, true, transactionID)
 		case utils.ACTION_TRIGGER_PREFIX:
-			_, err = ms.GetActionTriggers(dataID, true, utils.NonTransactional)
+			_, err = ms.GetActionTriggers(dataID, true, transactionID)
 		case utils.SHARED_GROUP_PREFIX:
-			_, err = ms.GetSharedGroup(dataID, true, utils.NonTransactional)
+			_, err = ms.GetSharedGroup(dataID, true, transactionID)
 		case utils.DERIVEDCHARGERS_PREFIX:
-			_, err = ms.GetDerivedChargers(dataID, true, utils.NonTransactional)
+			_, err = ms.GetDerivedChargers(dataID, true, transactionID)
 		case utils.LCR_PREFIX:
-			_, err = ms.GetLCR(dataID, true, utils.NonTransactional)
+			_, err = ms.GetLCR(dataID, true, transactionID)
 		case utils.ALIASES_PREFIX:
-			_, err = ms.GetAlias(dataID, true, utils.NonTransactional)
+			_, err = ms.GetAlias(dataID, true, transactionID)
 		case utils.REVERSE_ALIASES_PREFIX:
-			_, err = ms.GetReverseAlias(dataID, true, utils.NonTransactional)
+			_, err = ms.GetReverseAlias(dataID, true, transactionID)
 		case utils.ResourceLimitsPrefix:
-			_, err = ms.GetResourceLimit(dataID, true, utils.NonTransactional)
+			_, err = ms.GetResourceLimit(dataID, true, transactionID)
 		}
 		if err != nil {
 			return utils.NewCGRError(utils.MONGO,
@@ -572,7 +585,7 @@ func (ms *MongoStorage) GetKeysForPrefix(prefix string) (result []string, err er
 		return nil, fmt.Errorf("unsupported prefix in GetKeysForPrefix: %s", prefix)
 	}
 	category = prefix[:keyLen] // prefix lenght
-	subject = fmt.Sprintf("^%s", prefix[keyLen:])
+	subject = fmt.Sprintf("^%s", regexp.QuoteMeta(prefix[keyLen:]))
 	session := ms.session.Copy()
 	defer session.Close()
 	db := session.DB(ms.db)
@@ -697,7 +710,6 @@ func (ms *MongoStorage) GetRatingPlan(key string, skipCache bool, transactionID
 			return x.(*RatingPlan), nil
 		}
 	}
-	rp = new(RatingPlan)
 	var kv struct {
 		Key   string
 		Value []byte
@@ -711,17 +723,7 @@ func (ms *MongoStorage) GetRatingPlan(key string, skipCache bool, transactionID
 		}
 		return nil, err
 	}
-	b := bytes.NewBuffer(kv.Value)
-	r, err := zlib.NewReader(b)
-	if err != nil {
-		return nil, err
-	}
-	out, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	r.Close()
-	if err = ms.ms.Unmarshal(out, &rp); err != nil {
+	if rp, err = unmarshalRatingPlan(ms.ms, kv.Value); err != nil {
 		return nil, err
 	}
 	cache.Set(cacheKey, rp, cacheCommit(transactionID), transactionID)
@@ -729,20 +731,31 @@ func (ms *MongoStorage) GetRatingPlan(key string, skipCache bool, transactionID
 }
 
 func (ms *MongoStorage) SetRatingPlan(rp *RatingPlan, transactionID string) error {
-	result, err := ms.ms.Marshal(rp)
+	header, parts := splitRatingPlan(rp)
+	result, err := marshalRatingPlan(header)
 	if err != nil {
 		return err
 	}
-	var b bytes.Buffer
-	w := zlib.NewWriter(&b)
-	w.Write(result)
-	w.Close()
+	if err = ms.removeRatingPlanParts(rp.Id); err != nil {
+		return err
+	}
+	for _, part := range parts {
+		partResult, err := marshalRatingPlan(part)
+		if err != nil {
+			return err
+		}
+		for dID := range part.DestinationRates {
+			if err = ms.setRatingPlanPart(rp.Id, dID, partResult); err != nil {
+				return err
+			}
+		}
+	}
 	session, col := ms.conn(colRpl)
 	defer session.Close()
 	_, err = col.Upsert(bson.M{"key": rp.Id}, &struct {
 		Key   string
 		Value []byte
-	}{Key: rp.Id, Value: b.Bytes()})
+	}{Key: rp.Id, Value: result})
 	if err == nil && historyScribe != nil {
 		var response int
 		historyScribe.Call("HistoryV1.Record", rp.GetHistoryRecord(), &response)
@@ -750,6 +763,55 @@ func (ms *MongoStorage) SetRatingPlan(rp *RatingPlan, transactionID string) erro
 	return err
 }
 
+func (ms *MongoStorage) RemoveRatingPlan(key string, transactionID string) error {
+	if err := ms.removeRatingPlanParts(key); err != nil {
+		return err
+	}
+	session, col := ms.conn(colRpl)
+	defer session.Close()
+	if err := col.Remove(bson.M{"key": key}); err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	cache.RemKey(utils.RATING_PLAN_PREFIX+key, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MongoStorage) setRatingPlanPart(planID, destID string, value []byte) (err error) {
+	session, col := ms.conn(colRpp)
+	defer session.Close()
+	key := utils.ConcatenatedKey(planID, destID)
+	_, err = col.Upsert(bson.M{"key": key}, &struct {
+		Key    string
+		PlanID string
+		Value  []byte
+	}{Key: key, PlanID: planID, Value: value})
+	return
+}
+
+// removeRatingPlanParts deletes the destination parts of a split rating plan
+func (ms *MongoStorage) removeRatingPlanParts(planID string) (err error) {
+	session, col := ms.conn(colRpp)
+	defer session.Close()
+	_, err = col.RemoveAll(bson.M{"planid": planID})
+	return
+}
+
+func (ms *MongoStorage) GetRatingPlanPart(planID, destID string) (rp *RatingPlan, err error) {
+	var kv struct {
+		Key   string
+		Value []byte
+	}
+	session, col := ms.conn(colRpp)
+	defer session.Close()
+	if err = col.Find(bson.M{"key": utils.ConcatenatedKey(planID, destID)}).One(&kv); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return unmarshalRatingPlan(ms.ms, kv.Value)
+}
+
 func (ms *MongoStorage) GetRatingProfile(key string, skipCache bool, transactionID string) (rp *RatingProfile, err error) {
 	cacheKey := utils.RATING_PROFILE_PREFIX + key
 	if !skipCache {
@@ -847,6 +909,16 @@ func (ms *MongoStorage) SetLCR(lcr *LCR, transactionID string) (err error) {
 	return
 }
 
+func (ms *MongoStorage) RemoveLCR(id string, transactionID string) (err error) {
+	session, col := ms.conn(colLcr)
+	defer session.Close()
+	if err = col.Remove(bson.M{"key": id}); err != nil && err != mgo.ErrNotFound {
+		return
+	}
+	cache.RemKey(utils.LCR_PREFIX+id, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
 func (ms *MongoStorage) GetDestination(key string, skipCache bool, transactionID string) (result *Destination, err error) {
 	cacheKey := utils.DESTINATION_PREFIX + key
 	if !skipCache {
@@ -936,6 +1008,7 @@ func (ms *MongoStorage) GetReverseDestination(prefix string, skipCache bool, tra
 		return
 	}
 	ids = result.Value
+	utils.AddIPPrefixKey(prefix)
 	cache.Set(cacheKey, ids, cacheCommit(transactionID), transactionID)
 	return
 }
@@ -944,7 +1017,7 @@ func (ms *MongoStorage) SetReverseDestination(dest *Destination, transactionID s
 	session, col := ms.conn(colRds)
 	defer session.Close()
 	for _, p := range dest.Prefixes {
-		if _, err = col.Upsert(bson.M{"key": p}, bson.M{"$addToSet": bson.M{"value": dest.Id}}); err != nil {
+		if _, err = col.Upsert(bson.M{"key": utils.ReverseDestinationKey(p)}, bson.M{"$addToSet": bson.M{"value": dest.Id}}); err != nil {
 			break
 		}
 	}
@@ -972,6 +1045,7 @@ func (ms *MongoStorage) RemoveDestination(destID string, transactionID string) (
 	session, col = ms.conn(colRds)
 	defer session.Close()
 	for _, prefix := range d.Prefixes {
+		prefix = utils.ReverseDestinationKey(prefix)
 		err = col.Update(bson.M{"key": prefix}, bson.M{"$pull": bson.M{"value": destID}})
 		if err != nil {
 			return err
@@ -1022,6 +1096,7 @@ func (ms *MongoStorage) UpdateReverseDestination(oldDest, newDest *Destination,
 	cCommit := cacheCommit(transactionID)
 	var err error
 	for _, obsoletePrefix := range obsoletePrefixes {
+		obsoletePrefix = utils.ReverseDestinationKey(obsoletePrefix)
 		err = col.Update(bson.M{"key": obsoletePrefix}, bson.M{"$pull": bson.M{"value": oldDest.Id}})
 		if err != nil {
 			return err
@@ -1031,6 +1106,7 @@ func (ms *MongoStorage) UpdateReverseDestination(oldDest, newDest *Destination,
 
 	// add the id to all new prefixes
 	for _, addedPrefix := range addedPrefixes {
+		addedPrefix = utils.ReverseDestinationKey(addedPrefix)
 		_, err = col.Upsert(bson.M{"key": addedPrefix}, bson.M{"$addToSet": bson.M{"value": newDest.Id}})
 		if err != nil {
 			return err
@@ -1398,6 +1474,72 @@ func (ms *MongoStorage) RemoveAlias(key, transactionID string) (err error) {
 	return
 }
 
+func (ms *MongoStorage) GetTPSnapshot(id string) (tps *TPSnapshot, err error) {
+	var kv struct {
+		Key   string
+		Value []byte
+	}
+	session, col := ms.conn(colTps)
+	defer session.Close()
+	if err = col.Find(bson.M{"key": id}).One(&kv); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	b := bytes.NewBuffer(kv.Value)
+	r, err := zlib.NewReader(b)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	r.Close()
+	err = ms.ms.Unmarshal(out, &tps)
+	return
+}
+
+func (ms *MongoStorage) SetTPSnapshot(tps *TPSnapshot) error {
+	result, err := ms.ms.Marshal(tps)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(result)
+	w.Close()
+	session, col := ms.conn(colTps)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"key": tps.ID}, &struct {
+		Key   string
+		Value []byte
+	}{Key: tps.ID, Value: b.Bytes()})
+	return err
+}
+
+func (ms *MongoStorage) RemoveTPSnapshot(id string) error {
+	session, col := ms.conn(colTps)
+	defer session.Close()
+	if err := col.Remove(bson.M{"key": id}); err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+func (ms *MongoStorage) GetTPSnapshotIDs() (ids []string, err error) {
+	session, col := ms.conn(colTps)
+	defer session.Close()
+	var keyResult struct{ Key string }
+	iter := col.Find(nil).Select(bson.M{"key": 1}).Iter()
+	for iter.Next(&keyResult) {
+		ids = append(ids, keyResult.Key)
+	}
+	err = iter.Close()
+	return
+}
+
 // Limit will only retrieve the last n items out of history, newest first
 func (ms *MongoStorage) GetLoadHistory(limit int, skipCache bool, transactionID string) (loadInsts []*utils.LoadInstance, err error) {
 	if limit == 0 {
@@ -1897,6 +2039,202 @@ func (ms *MongoStorage) RemoveResourceLimit(id string, transactionID string) (er
 	return nil
 }
 
+func (ms *MongoStorage) GetResourceUsageSeries(id string) (rus *ResourceUsageSeries, err error) {
+	session, col := ms.conn(colRUS)
+	defer session.Close()
+	rus = new(ResourceUsageSeries)
+	if err = col.Find(bson.M{"id": id}).One(rus); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+func (ms *MongoStorage) SetResourceUsageSeries(rus *ResourceUsageSeries) (err error) {
+	session, col := ms.conn(colRUS)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"id": rus.ID}, rus)
+	return
+}
+
+func (ms *MongoStorage) GetExchangeRate(id string, skipCache bool, transactionID string) (er *ExchangeRate, err error) {
+	key := utils.ExchangeRatesPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*ExchangeRate), nil
+		}
+	}
+	session, col := ms.conn(colExr)
+	defer session.Close()
+	er = new(ExchangeRate)
+	if err = col.Find(bson.M{"id": id}).One(er); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		}
+		return nil, err
+	}
+	cache.Set(key, er, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) SetExchangeRate(er *ExchangeRate, transactionID string) (err error) {
+	session, col := ms.conn(colExr)
+	defer session.Close()
+	if _, err = col.Upsert(bson.M{"id": er.ID}, er); err != nil {
+		return
+	}
+	cache.RemKey(utils.ExchangeRatesPrefix+er.ID, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) RemoveExchangeRate(id string, transactionID string) (err error) {
+	session, col := ms.conn(colExr)
+	defer session.Close()
+	if err = col.Remove(bson.M{"id": id}); err != nil {
+		return
+	}
+	cache.RemKey(utils.ExchangeRatesPrefix+id, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MongoStorage) GetHolidayCalendar(id string, skipCache bool, transactionID string) (hc *HolidayCalendar, err error) {
+	key := utils.HolidayCalendarsPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*HolidayCalendar), nil
+		}
+	}
+	session, col := ms.conn(colHol)
+	defer session.Close()
+	hc = new(HolidayCalendar)
+	if err = col.Find(bson.M{"id": id}).One(hc); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		}
+		return nil, err
+	}
+	cache.Set(key, hc, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) SetHolidayCalendar(hc *HolidayCalendar, transactionID string) (err error) {
+	session, col := ms.conn(colHol)
+	defer session.Close()
+	if _, err = col.Upsert(bson.M{"id": hc.ID}, hc); err != nil {
+		return
+	}
+	cache.RemKey(utils.HolidayCalendarsPrefix+hc.ID, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) RemoveHolidayCalendar(id string, transactionID string) (err error) {
+	session, col := ms.conn(colHol)
+	defer session.Close()
+	if err = col.Remove(bson.M{"id": id}); err != nil {
+		return
+	}
+	cache.RemKey(utils.HolidayCalendarsPrefix+id, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MongoStorage) GetDialPlan(tenant string, skipCache bool, transactionID string) (dp *DialPlan, err error) {
+	key := utils.DialPlansPrefix + tenant
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*DialPlan), nil
+		}
+	}
+	session, col := ms.conn(colDpl)
+	defer session.Close()
+	dp = new(DialPlan)
+	if err = col.Find(bson.M{"tenant": tenant}).One(dp); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		}
+		return nil, err
+	}
+	cache.Set(key, dp, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) SetDialPlan(dp *DialPlan, transactionID string) (err error) {
+	session, col := ms.conn(colDpl)
+	defer session.Close()
+	if _, err = col.Upsert(bson.M{"tenant": dp.Tenant}, dp); err != nil {
+		return
+	}
+	cache.RemKey(utils.DialPlansPrefix+dp.Tenant, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) RemoveDialPlan(tenant string, transactionID string) (err error) {
+	session, col := ms.conn(colDpl)
+	defer session.Close()
+	if err = col.Remove(bson.M{"tenant": tenant}); err != nil {
+		return
+	}
+	cache.RemKey(utils.DialPlansPrefix+tenant, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
+func (ms *MongoStorage) GetSplitCharging(id string, skipCache bool, transactionID string) (spc *SplitCharging, err error) {
+	key := utils.SplitChargingsPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*SplitCharging), nil
+		}
+	}
+	session, col := ms.conn(colSpc)
+	defer session.Close()
+	spc = new(SplitCharging)
+	if err = col.Find(bson.M{"id": id}).One(spc); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		}
+		return nil, err
+	}
+	cache.Set(key, spc, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) SetSplitCharging(spc *SplitCharging, transactionID string) (err error) {
+	session, col := ms.conn(colSpc)
+	defer session.Close()
+	if _, err = col.Upsert(bson.M{"id": spc.ID}, spc); err != nil {
+		return
+	}
+	cache.RemKey(utils.SplitChargingsPrefix+spc.ID, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (ms *MongoStorage) RemoveSplitCharging(id string, transactionID string) (err error) {
+	session, col := ms.conn(colSpc)
+	defer session.Close()
+	if err = col.Remove(bson.M{"id": id}); err != nil {
+		return
+	}
+	cache.RemKey(utils.SplitChargingsPrefix+id, cacheCommit(transactionID), transactionID)
+	return nil
+}
+
 func (ms *MongoStorage) GetReqFilterIndexes(dbKey string) (indexes map[string]map[string]utils.StringMap, err error) {
 	session, col := ms.conn(colRFI)
 	defer session.Close()
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/cgrates/cgrates/utils"
+	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -379,6 +380,57 @@ func (ms *MongoStorage) GetTPResourceLimits(tpid, id string) ([]*utils.TPResourc
 	return results, err
 }
 
+func (ms *MongoStorage) GetTPExchangeRates(tpid, fromCurrency string) ([]*utils.TPExchangeRate, error) {
+	filter := bson.M{
+		"tpid": tpid,
+	}
+	if fromCurrency != "" {
+		filter["fromcurrency"] = fromCurrency
+	}
+	var results []*utils.TPExchangeRate
+	session, col := ms.conn(utils.TBLTPExchangeRates)
+	defer session.Close()
+	err := col.Find(filter).All(&results)
+	if len(results) == 0 {
+		return results, utils.ErrNotFound
+	}
+	return results, err
+}
+
+func (ms *MongoStorage) GetTPHolidayCalendars(tpid, id string) ([]*utils.TPHolidayCalendar, error) {
+	filter := bson.M{
+		"tpid": tpid,
+	}
+	if id != "" {
+		filter["id"] = id
+	}
+	var results []*utils.TPHolidayCalendar
+	session, col := ms.conn(utils.TBLTPHolidayCalendars)
+	defer session.Close()
+	err := col.Find(filter).All(&results)
+	if len(results) == 0 {
+		return results, utils.ErrNotFound
+	}
+	return results, err
+}
+
+func (ms *MongoStorage) GetTPDialPlans(tpid, tenant string) ([]*utils.TPDialPlan, error) {
+	filter := bson.M{
+		"tpid": tpid,
+	}
+	if tenant != "" {
+		filter["tenant"] = tenant
+	}
+	var results []*utils.TPDialPlan
+	session, col := ms.conn(utils.TBLTPDialPlans)
+	defer session.Close()
+	err := col.Find(filter).All(&results)
+	if len(results) == 0 {
+		return results, utils.ErrNotFound
+	}
+	return results, err
+}
+
 func (ms *MongoStorage) GetTPDerivedChargers(tp *utils.TPDerivedChargers) ([]*utils.TPDerivedChargers, error) {
 	filter := bson.M{"tpid": tp.TPid}
 	if tp.Direction != "" {
@@ -507,6 +559,12 @@ func (ms *MongoStorage) RemTpData(table, tpid string, args map[string]string) er
 		args["id"] = args["tag"]
 		delete(args, "tag")
 	}
+	for _, fld := range []string{"from_currency", "to_currency"} { // exchange rates are stored with the default field names
+		if val, has := args[fld]; has {
+			args[strings.Replace(fld, "_", "", -1)] = val
+			delete(args, fld)
+		}
+	}
 	if tpid != "" {
 		args["tpid"] = tpid
 	}
@@ -841,6 +899,48 @@ func (ms *MongoStorage) SetTPResourceLimits(tpRLs []*utils.TPResourceLimit) (err
 	return
 }
 
+func (ms *MongoStorage) SetTPExchangeRates(tpERs []*utils.TPExchangeRate) (err error) {
+	if len(tpERs) == 0 {
+		return
+	}
+	session, col := ms.conn(utils.TBLTPExchangeRates)
+	defer session.Close()
+	tx := col.Bulk()
+	for _, tp := range tpERs {
+		tx.Upsert(bson.M{"tpid": tp.TPid, "fromcurrency": tp.FromCurrency, "tocurrency": tp.ToCurrency}, tp)
+	}
+	_, err = tx.Run()
+	return
+}
+
+func (ms *MongoStorage) SetTPHolidayCalendars(tpHCs []*utils.TPHolidayCalendar) (err error) {
+	if len(tpHCs) == 0 {
+		return
+	}
+	session, col := ms.conn(utils.TBLTPHolidayCalendars)
+	defer session.Close()
+	tx := col.Bulk()
+	for _, tp := range tpHCs {
+		tx.Upsert(bson.M{"tpid": tp.TPid, "id": tp.ID}, tp)
+	}
+	_, err = tx.Run()
+	return
+}
+
+func (ms *MongoStorage) SetTPDialPlans(tpDPs []*utils.TPDialPlan) (err error) {
+	if len(tpDPs) == 0 {
+		return
+	}
+	session, col := ms.conn(utils.TBLTPDialPlans)
+	defer session.Close()
+	tx := col.Bulk()
+	for _, tp := range tpDPs {
+		tx.Upsert(bson.M{"tpid": tp.TPid, "tenant": tp.Tenant}, tp)
+	}
+	_, err = tx.Run()
+	return
+}
+
 func (ms *MongoStorage) SetSMCost(smc *SMCost) error {
 	if smc.CostDetails == nil {
 		return nil
@@ -905,6 +1005,21 @@ func (ms *MongoStorage) SetCDR(cdr *CDR, allowUpdate bool) (err error) {
 	return err
 }
 
+// SetCDRIndexedFields ensures indexes on the given CDR extra fields
+func (ms *MongoStorage) SetCDRIndexedFields(fields []string) (err error) {
+	session, col := ms.conn(utils.TBLCDRs)
+	defer session.Close()
+	for _, field := range fields {
+		if err = col.EnsureIndex(mgo.Index{
+			Key:        []string{"extrafields." + field},
+			Background: true,
+		}); err != nil {
+			return
+		}
+	}
+	return
+}
+
 func (ms *MongoStorage) cleanEmptyFilters(filters bson.M) {
 	for k, v := range filters {
 		switch value := v.(type) {
@@ -937,33 +1052,33 @@ func (ms *MongoStorage) cleanEmptyFilters(filters bson.M) {
 	}
 }
 
-//  _, err := col(utils.TBLCDRs).UpdateAll(bson.M{CGRIDLow: bson.M{"$in": cgrIds}}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
-func (ms *MongoStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR, int64, error) {
+// cdrsFilters builds the query filters selecting the CDRs matching qryFltr
+func (ms *MongoStorage) cdrsFilters(qryFltr *utils.CDRsFilter) (bson.M, error) {
 	var minPDD, maxPDD, minUsage, maxUsage *time.Duration
 	if len(qryFltr.MinPDD) != 0 {
 		if parsed, err := utils.ParseDurationWithSecs(qryFltr.MinPDD); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			minPDD = &parsed
 		}
 	}
 	if len(qryFltr.MaxPDD) != 0 {
 		if parsed, err := utils.ParseDurationWithSecs(qryFltr.MaxPDD); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			maxPDD = &parsed
 		}
 	}
 	if len(qryFltr.MinUsage) != 0 {
 		if parsed, err := utils.ParseDurationWithSecs(qryFltr.MinUsage); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			minUsage = &parsed
 		}
 	}
 	if len(qryFltr.MaxUsage) != 0 {
 		if parsed, err := utils.ParseDurationWithSecs(qryFltr.MaxUsage); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			maxUsage = &parsed
 		}
@@ -1066,6 +1181,74 @@ func (ms *MongoStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 			filters[CostLow] = bson.M{"$lt": *qryFltr.MaxCost}
 		}
 	}
+	return filters, nil
+}
+
+// GetCDRsAggregates sums up the CDRs matching qryFltr inside the database, grouping them on groupBy fields <Account|Destination|*day|ExtraInfo>
+func (ms *MongoStorage) GetCDRsAggregates(qryFltr *utils.CDRsFilter, groupBy []string) ([]*utils.CDRsAggregate, error) {
+	filters, err := ms.cdrsFilters(qryFltr)
+	if err != nil {
+		return nil, err
+	}
+	var grpID interface{} // nil groups everything together
+	if len(groupBy) != 0 {
+		grpFlds := bson.M{}
+		for _, fld := range groupBy {
+			switch fld {
+			case utils.ACCOUNT:
+				grpFlds[AccountLow] = "$" + AccountLow
+			case utils.DESTINATION:
+				grpFlds[DestinationLow] = "$" + DestinationLow
+			case utils.MetaDay:
+				grpFlds["day"] = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$" + SetupTimeLow}}
+			case utils.ExtraInfo:
+				grpFlds[ExtraInfoLow] = "$" + ExtraInfoLow
+			default:
+				return nil, fmt.Errorf("unsupported group by field: %s", fld)
+			}
+		}
+		grpID = grpFlds
+	}
+	pipeline := []bson.M{
+		bson.M{"$match": filters},
+		bson.M{"$group": bson.M{
+			"_id":   grpID,
+			"count": bson.M{"$sum": 1},
+			"cost":  bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$gt": []interface{}{"$" + CostLow, 0}}, "$" + CostLow, 0}}},
+			"usage": bson.M{"$sum": "$" + UsageLow},
+		}},
+	}
+	session, col := ms.conn(utils.TBLCDRs)
+	defer session.Close()
+	iter := col.Pipe(pipeline).Iter()
+	var aggrs []*utils.CDRsAggregate
+	var result struct {
+		ID    map[string]string `bson:"_id"`
+		Count int64             `bson:"count"`
+		Cost  float64           `bson:"cost"`
+		Usage int64             `bson:"usage"`
+	}
+	for iter.Next(&result) {
+		aggrs = append(aggrs, &utils.CDRsAggregate{Account: result.ID[AccountLow],
+			Destination: result.ID[DestinationLow], Day: result.ID["day"], ExtraInfo: result.ID[ExtraInfoLow],
+			Count: result.Count, Cost: result.Cost, Usage: time.Duration(result.Usage)})
+		result.ID = nil
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	if len(aggrs) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return aggrs, nil
+}
+
+//  _, err := col(utils.TBLCDRs).UpdateAll(bson.M{CGRIDLow: bson.M{"$in": cgrIds}}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+func (ms *MongoStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR, int64, error) {
+	filters, err := ms.cdrsFilters(qryFltr)
+	if err != nil {
+		return nil, 0, err
+	}
 	//file.WriteString(fmt.Sprintf("AFTER: %v\n", utils.ToIJSON(filters)))
 	//file.Close()
 	session, col := ms.conn(utils.TBLCDRs)
@@ -45,7 +45,7 @@ func NewMySQLStorage(host, port, name, user, password string, maxConn, maxIdleCo
 	mySQLStorage := new(MySQLStorage)
 	mySQLStorage.db = db
 	mySQLStorage.Db = db.DB()
-	return &SQLStorage{db.DB(), db, mySQLStorage, mySQLStorage}, nil
+	return &SQLStorage{Db: db.DB(), db: db, StorDB: mySQLStorage, SQLImpl: mySQLStorage}, nil
 }
 
 // SetVersions will set a slice of versions, updating existing
@@ -83,3 +83,7 @@ func (self *MySQLStorage) notExtraFieldsExistsQry(field string) string {
 func (self *MySQLStorage) notExtraFieldsValueQry(field, value string) string {
 	return fmt.Sprintf(" extra_fields NOT LIKE '%%\"%s\":\"%s\"%%'", field, value)
 }
+
+func (self *MySQLStorage) dayQry(field string) string {
+	return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", field)
+}
@@ -42,7 +42,7 @@ func NewPostgresStorage(host, port, name, user, password string, maxConn, maxIdl
 	postgressStorage := new(PostgresStorage)
 	postgressStorage.db = db
 	postgressStorage.Db = db.DB()
-	return &SQLStorage{db.DB(), db, postgressStorage, postgressStorage}, nil
+	return &SQLStorage{Db: db.DB(), db: db, StorDB: postgressStorage, SQLImpl: postgressStorage}, nil
 }
 
 type PostgresStorage struct {
@@ -87,3 +87,7 @@ func (self *PostgresStorage) notExtraFieldsExistsQry(field string) string {
 func (self *PostgresStorage) notExtraFieldsValueQry(field, value string) string {
 	return fmt.Sprintf(" NOT (extra_fields ?'%s' AND (extra_fields ->> '%s') = '%s')", field, field, value)
 }
+
+func (self *PostgresStorage) dayQry(field string) string {
+	return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", field)
+}
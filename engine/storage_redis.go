@@ -119,7 +119,7 @@ func (rs *RedisStorage) SelectDatabase(dbName string) (err error) {
 
 func (rs *RedisStorage) LoadRatingCache(dstIDs, rvDstIDs, rplIDs, rpfIDs, actIDs,
 	aplIDs, aaPlIDs, atrgIDs, sgIDs, lcrIDs, dcIDs []string) (err error) {
-	for key, ids := range map[string][]string{
+	return rs.CacheDataGenerationFromDB(map[string][]string{
 		utils.DESTINATION_PREFIX:         dstIDs,
 		utils.REVERSE_DESTINATION_PREFIX: rvDstIDs,
 		utils.RATING_PLAN_PREFIX:         rplIDs,
@@ -131,25 +131,15 @@ func (rs *RedisStorage) LoadRatingCache(dstIDs, rvDstIDs, rplIDs, rpfIDs, actIDs
 		utils.SHARED_GROUP_PREFIX:        sgIDs,
 		utils.LCR_PREFIX:                 lcrIDs,
 		utils.DERIVEDCHARGERS_PREFIX:     dcIDs,
-	} {
-		if err = rs.CacheDataFromDB(key, ids, false); err != nil {
-			return
-		}
-	}
-	return
+	}, false)
 }
 
 func (rs *RedisStorage) LoadAccountingCache(alsIDs, rvAlsIDs, rlIDs []string) (err error) {
-	for key, ids := range map[string][]string{
+	return rs.CacheDataGenerationFromDB(map[string][]string{
 		utils.ALIASES_PREFIX:         alsIDs,
 		utils.REVERSE_ALIASES_PREFIX: rvAlsIDs,
 		utils.ResourceLimitsPrefix:   rlIDs,
-	} {
-		if err = rs.CacheDataFromDB(key, ids, false); err != nil {
-			return
-		}
-	}
-	return
+	}, false)
 }
 
 func (rs *RedisStorage) RebuildReverseForPrefix(prefix string) (err error) {
@@ -215,7 +205,17 @@ func (rs *RedisStorage) RebuildReverseForPrefix(prefix string) (err error) {
 // CacheDataFromDB loads data to cache
 // prfx represents the cache prefix, ids should be nil if all available data should be loaded
 // mustBeCached specifies that data needs to be cached in order to be retrieved from db
-func (rs *RedisStorage) CacheDataFromDB(prfx string, ids []string, mustBeCached bool) (err error) {
+// CacheDataFromDB loads the data of one prefix into cache as one generation, see CacheDataGenerationFromDB
+func (rs *RedisStorage) CacheDataFromDB(prfx string, ids []string, mustBeCached bool) error {
+	return rs.CacheDataGenerationFromDB(map[string][]string{prfx: ids}, mustBeCached)
+}
+
+// CacheDataGenerationFromDB loads the data of multiple prefixes into cache, made visible to readers all at once
+func (rs *RedisStorage) CacheDataGenerationFromDB(prefixIDs map[string][]string, mustBeCached bool) error {
+	return cacheGeneration(prefixIDs, mustBeCached, rs.cacheDataFromDB)
+}
+
+func (rs *RedisStorage) cacheDataFromDB(prfx string, ids []string, mustBeCached bool, transactionID string) (err error) {
 	if !utils.IsSliceMember([]string{utils.DESTINATION_PREFIX,
 		utils.REVERSE_DESTINATION_PREFIX,
 		utils.RATING_PLAN_PREFIX,
@@ -294,33 +294,33 @@ func (rs *RedisStorage) CacheDataFromDB(prfx string, ids []string, mustBeCached
 		}
 		switch prfx {
 		case utils.DESTINATION_PREFIX:
-			_, err = rs.GetDestination(dataID, true, utils.NonTransactional)
+			_, err = rs.GetDestination(dataID, true, transactionID)
 		case utils.REVERSE_DESTINATION_PREFIX:
-			_, err = rs.GetReverseDestination(dataID, true, utils.NonTransactional)
+			_, err = rs.GetReverseDestination(dataID, true, transactionID)
 		case utils.RATING_PLAN_PREFIX:
-			_, err = rs.GetRatingPlan(dataID, true, utils.NonTransactional)
+			_, err = rs.GetRatingPlan(dataID, true, transactionID)
 		case utils.RATING_PROFILE_PREFIX:
-			_, err = rs.GetRatingProfile(dataID, true, utils.NonTransactional)
+			_, err = rs.GetRatingProfile(dataID, true, transactionID)
 		case utils.ACTION_PREFIX:
-			_, err = rs.GetActions(dataID, true, utils.NonTransactional)
+			_, err = rs.GetActions(dataID, true, transactionID)
 		case utils.ACTION_PLAN_PREFIX:
-			_, err = rs.GetActionPlan(dataID, true, utils.NonTransactional)
+			_, err = rs.GetActionPlan(dataID, true, transactionID)
 		case utils.AccountActionPlansPrefix:
-			_, err = rs.GetAccountActionPlans(dataID, true, utils.NonTransactional)
+			_, err = rs.GetAccountActionPlans(dataID, true, transactionID)
 		case utils.ACTION_TRIGGER_PREFIX:
-			_, err = rs.GetActionTriggers(dataID, true, utils.NonTransactional)
+			_, err = rs.GetActionTriggers(dataID, true, transactionID)
 		case utils.SHARED_GROUP_PREFIX:
-			_, err = rs.GetSharedGroup(dataID, true, utils.NonTransactional)
+			_, err = rs.GetSharedGroup(dataID, true, transactionID)
 		case utils.DERIVEDCHARGERS_PREFIX:
-			_, err = rs.GetDerivedChargers(dataID, true, utils.NonTransactional)
+			_, err = rs.GetDerivedChargers(dataID, true, transactionID)
 		case utils.LCR_PREFIX:
-			_, err = rs.GetLCR(dataID, true, utils.NonTransactional)
+			_, err = rs.GetLCR(dataID, true, transactionID)
 		case utils.ALIASES_PREFIX:
-			_, err = rs.GetAlias(dataID, true, utils.NonTransactional)
+			_, err = rs.GetAlias(dataID, true, transactionID)
 		case utils.REVERSE_ALIASES_PREFIX:
-			_, err = rs.GetReverseAlias(dataID, true, utils.NonTransactional)
+			_, err = rs.GetReverseAlias(dataID, true, transactionID)
 		case utils.ResourceLimitsPrefix:
-			_, err = rs.GetResourceLimit(dataID, true, utils.NonTransactional)
+			_, err = rs.GetResourceLimit(dataID, true, transactionID)
 		}
 		if err != nil {
 			return utils.NewCGRError(utils.REDIS,
@@ -368,19 +368,7 @@ func (rs *RedisStorage) GetRatingPlan(key string, skipCache bool, transactionID
 		}
 		return nil, err
 	}
-	b := bytes.NewBuffer(values)
-	r, err := zlib.NewReader(b)
-	if err != nil {
-		return nil, err
-	}
-	out, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	r.Close()
-	rp = new(RatingPlan)
-	err = rs.ms.Unmarshal(out, rp)
-	if err != nil {
+	if rp, err = unmarshalRatingPlan(rs.ms, values); err != nil {
 		return nil, err
 	}
 	cache.Set(key, rp, cacheCommit(transactionID), transactionID)
@@ -388,12 +376,26 @@ func (rs *RedisStorage) GetRatingPlan(key string, skipCache bool, transactionID
 }
 
 func (rs *RedisStorage) SetRatingPlan(rp *RatingPlan, transactionID string) (err error) {
-	result, err := rs.ms.Marshal(rp)
-	var b bytes.Buffer
-	w := zlib.NewWriter(&b)
-	w.Write(result)
-	w.Close()
-	err = rs.Cmd("SET", utils.RATING_PLAN_PREFIX+rp.Id, b.Bytes()).Err
+	header, parts := splitRatingPlan(rp)
+	result, err := marshalRatingPlan(header)
+	if err != nil {
+		return
+	}
+	if err = rs.removeRatingPlanParts(rp.Id); err != nil {
+		return
+	}
+	for _, part := range parts {
+		var partResult []byte
+		if partResult, err = marshalRatingPlan(part); err != nil {
+			return
+		}
+		for dID := range part.DestinationRates {
+			if err = rs.Cmd("SET", ratingPlanPartKey(rp.Id, dID), partResult).Err; err != nil {
+				return
+			}
+		}
+	}
+	err = rs.Cmd("SET", utils.RATING_PLAN_PREFIX+rp.Id, result).Err
 	if err == nil && historyScribe != nil {
 		response := 0
 		go historyScribe.Call("HistoryV1.Record", rp.GetHistoryRecord(), &response)
@@ -401,6 +403,38 @@ func (rs *RedisStorage) SetRatingPlan(rp *RatingPlan, transactionID string) (err
 	return
 }
 
+func (rs *RedisStorage) RemoveRatingPlan(key string, transactionID string) (err error) {
+	if err = rs.removeRatingPlanParts(key); err != nil {
+		return
+	}
+	key = utils.RATING_PLAN_PREFIX + key
+	if err = rs.Cmd("DEL", key).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+// removeRatingPlanParts deletes the destination parts of a split rating plan
+func (rs *RedisStorage) removeRatingPlanParts(planID string) (err error) {
+	keys, err := rs.Cmd("KEYS", utils.RatingPlanPartPrefix+planID+utils.CONCATENATED_KEY_SEP+"*").List()
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	return rs.Cmd("DEL", keys).Err
+}
+
+func (rs *RedisStorage) GetRatingPlanPart(planID, destID string) (rp *RatingPlan, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", ratingPlanPartKey(planID, destID)).Bytes(); err != nil {
+		if err.Error() == "wrong type" {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return unmarshalRatingPlan(rs.ms, values)
+}
+
 func (rs *RedisStorage) GetRatingProfile(key string, skipCache bool, transactionID string) (rpf *RatingProfile, err error) {
 	key = utils.RATING_PROFILE_PREFIX + key
 	if !skipCache {
@@ -498,6 +532,15 @@ func (rs *RedisStorage) SetLCR(lcr *LCR, transactionID string) (err error) {
 	return
 }
 
+func (rs *RedisStorage) RemoveLCR(id string, transactionID string) (err error) {
+	key := utils.LCR_PREFIX + id
+	if err = rs.Cmd("DEL", key).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
 // GetDestination retrieves a destination with id from  tp_db
 func (rs *RedisStorage) GetDestination(key string, skipCache bool, transactionID string) (dest *Destination, err error) {
 	key = utils.DESTINATION_PREFIX + key
@@ -572,13 +615,14 @@ func (rs *RedisStorage) GetReverseDestination(key string, skipCache bool, transa
 		err = utils.ErrNotFound
 		return
 	}
+	utils.AddIPPrefixKey(key[len(utils.REVERSE_DESTINATION_PREFIX):])
 	cache.Set(key, ids, cacheCommit(transactionID), transactionID)
 	return
 }
 
 func (rs *RedisStorage) SetReverseDestination(dest *Destination, transactionID string) (err error) {
 	for _, p := range dest.Prefixes {
-		key := utils.REVERSE_DESTINATION_PREFIX + p
+		key := utils.REVERSE_DESTINATION_PREFIX + utils.ReverseDestinationKey(p)
 		if err = rs.Cmd("SADD", key, dest.Id).Err; err != nil {
 			break
 		}
@@ -599,6 +643,7 @@ func (rs *RedisStorage) RemoveDestination(destID, transactionID string) (err err
 	}
 	cache.RemKey(key, cacheCommit(transactionID), transactionID)
 	for _, prefix := range d.Prefixes {
+		prefix = utils.ReverseDestinationKey(prefix)
 		err = rs.Cmd("SREM", utils.REVERSE_DESTINATION_PREFIX+prefix, destID).Err
 		if err != nil {
 			return err
@@ -647,6 +692,7 @@ func (rs *RedisStorage) UpdateReverseDestination(oldDest, newDest *Destination,
 	cCommit := cacheCommit(transactionID)
 	var err error
 	for _, obsoletePrefix := range obsoletePrefixes {
+		obsoletePrefix = utils.ReverseDestinationKey(obsoletePrefix)
 		err = rs.Cmd("SREM", utils.REVERSE_DESTINATION_PREFIX+obsoletePrefix, oldDest.Id).Err
 		if err != nil {
 			return err
@@ -656,6 +702,7 @@ func (rs *RedisStorage) UpdateReverseDestination(oldDest, newDest *Destination,
 
 	// add the id to all new prefixes
 	for _, addedPrefix := range addedPrefixes {
+		addedPrefix = utils.ReverseDestinationKey(addedPrefix)
 		err = rs.Cmd("SADD", utils.REVERSE_DESTINATION_PREFIX+addedPrefix, newDest.Id).Err
 		if err != nil {
 			return err
@@ -994,6 +1041,56 @@ func (rs *RedisStorage) RemoveAlias(id string, transactionID string) (err error)
 	return
 }
 
+func (rs *RedisStorage) GetTPSnapshot(id string) (tps *TPSnapshot, err error) {
+	values, err := rs.Cmd("GET", utils.TPSnapshotPrefix+id).Bytes()
+	if err != nil {
+		if err.Error() == "wrong type" { // did not find the snapshot
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	b := bytes.NewBuffer(values)
+	r, err := zlib.NewReader(b)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	r.Close()
+	err = rs.ms.Unmarshal(out, &tps)
+	return
+}
+
+func (rs *RedisStorage) SetTPSnapshot(tps *TPSnapshot) (err error) {
+	result, err := rs.ms.Marshal(tps)
+	if err != nil {
+		return
+	}
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(result)
+	w.Close()
+	return rs.Cmd("SET", utils.TPSnapshotPrefix+tps.ID, b.Bytes()).Err
+}
+
+func (rs *RedisStorage) RemoveTPSnapshot(id string) error {
+	return rs.Cmd("DEL", utils.TPSnapshotPrefix+id).Err
+}
+
+func (rs *RedisStorage) GetTPSnapshotIDs() (ids []string, err error) {
+	keys, err := rs.GetKeysForPrefix(utils.TPSnapshotPrefix)
+	if err != nil {
+		return
+	}
+	ids = make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key[len(utils.TPSnapshotPrefix):]
+	}
+	return
+}
+
 // Limit will only retrieve the last n items out of history, newest first
 func (rs *RedisStorage) GetLoadHistory(limit int, skipCache bool, transactionID string) ([]*utils.LoadInstance, error) {
 	if limit == 0 {
@@ -1439,6 +1536,214 @@ func (rs *RedisStorage) RemoveResourceLimit(id string, transactionID string) (er
 	return
 }
 
+func (rs *RedisStorage) GetResourceUsageSeries(id string) (rus *ResourceUsageSeries, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.ResourceUsageSeriesPrefix+id).Bytes(); err != nil {
+		if err.Error() == "wrong type" { // did not find the series
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &rus)
+	return
+}
+
+func (rs *RedisStorage) SetResourceUsageSeries(rus *ResourceUsageSeries) error {
+	result, err := rs.ms.Marshal(rus)
+	if err != nil {
+		return err
+	}
+	return rs.Cmd("SET", utils.ResourceUsageSeriesPrefix+rus.ID, result).Err
+}
+
+func (rs *RedisStorage) GetExchangeRate(id string, skipCache bool, transactionID string) (er *ExchangeRate, err error) {
+	key := utils.ExchangeRatesPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*ExchangeRate), nil
+		}
+	}
+	var values []byte
+	if values, err = rs.Cmd("GET", key).Bytes(); err != nil {
+		if err.Error() == "wrong type" { // did not find the exchange rate
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	if err = rs.ms.Unmarshal(values, &er); err != nil {
+		return
+	}
+	cache.Set(key, er, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) SetExchangeRate(er *ExchangeRate, transactionID string) (err error) {
+	result, err := rs.ms.Marshal(er)
+	if err != nil {
+		return err
+	}
+	key := utils.ExchangeRatesPrefix + er.ID
+	if err = rs.Cmd("SET", key, result).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) RemoveExchangeRate(id string, transactionID string) (err error) {
+	key := utils.ExchangeRatesPrefix + id
+	if err = rs.Cmd("DEL", key).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) GetHolidayCalendar(id string, skipCache bool, transactionID string) (hc *HolidayCalendar, err error) {
+	key := utils.HolidayCalendarsPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*HolidayCalendar), nil
+		}
+	}
+	var values []byte
+	if values, err = rs.Cmd("GET", key).Bytes(); err != nil {
+		if err.Error() == "wrong type" { // did not find the holiday calendar
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	if err = rs.ms.Unmarshal(values, &hc); err != nil {
+		return
+	}
+	cache.Set(key, hc, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) SetHolidayCalendar(hc *HolidayCalendar, transactionID string) (err error) {
+	result, err := rs.ms.Marshal(hc)
+	if err != nil {
+		return err
+	}
+	key := utils.HolidayCalendarsPrefix + hc.ID
+	if err = rs.Cmd("SET", key, result).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) RemoveHolidayCalendar(id string, transactionID string) (err error) {
+	key := utils.HolidayCalendarsPrefix + id
+	if err = rs.Cmd("DEL", key).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) GetDialPlan(tenant string, skipCache bool, transactionID string) (dp *DialPlan, err error) {
+	key := utils.DialPlansPrefix + tenant
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*DialPlan), nil
+		}
+	}
+	var values []byte
+	if values, err = rs.Cmd("GET", key).Bytes(); err != nil {
+		if err.Error() == "wrong type" { // did not find the dial plan
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	if err = rs.ms.Unmarshal(values, &dp); err != nil {
+		return
+	}
+	cache.Set(key, dp, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) SetDialPlan(dp *DialPlan, transactionID string) (err error) {
+	result, err := rs.ms.Marshal(dp)
+	if err != nil {
+		return err
+	}
+	key := utils.DialPlansPrefix + dp.Tenant
+	if err = rs.Cmd("SET", key, result).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) RemoveDialPlan(tenant string, transactionID string) (err error) {
+	key := utils.DialPlansPrefix + tenant
+	if err = rs.Cmd("DEL", key).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) GetSplitCharging(id string, skipCache bool, transactionID string) (spc *SplitCharging, err error) {
+	key := utils.SplitChargingsPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*SplitCharging), nil
+		}
+	}
+	var values []byte
+	if values, err = rs.Cmd("GET", key).Bytes(); err != nil {
+		if err.Error() == "wrong type" { // did not find the split charging
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	if err = rs.ms.Unmarshal(values, &spc); err != nil {
+		return
+	}
+	cache.Set(key, spc, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) SetSplitCharging(spc *SplitCharging, transactionID string) (err error) {
+	result, err := rs.ms.Marshal(spc)
+	if err != nil {
+		return err
+	}
+	key := utils.SplitChargingsPrefix + spc.ID
+	if err = rs.Cmd("SET", key, result).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (rs *RedisStorage) RemoveSplitCharging(id string, transactionID string) (err error) {
+	key := utils.SplitChargingsPrefix + id
+	if err = rs.Cmd("DEL", key).Err; err != nil {
+		return
+	}
+	cache.RemKey(key, cacheCommit(transactionID), transactionID)
+	return
+}
+
 func (rs *RedisStorage) GetReqFilterIndexes(dbKey string) (indexes map[string]map[string]utils.StringMap, err error) {
 	mp, err := rs.Cmd("HGETALL", dbKey).Map()
 	if err != nil {
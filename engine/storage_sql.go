@@ -36,6 +36,7 @@ type SQLImpl interface {
 	extraFieldsValueQry(string, string) string
 	notExtraFieldsExistsQry(string) string
 	notExtraFieldsValueQry(string, string) string
+	dayQry(string) string
 }
 
 type SQLStorage struct {
@@ -43,6 +44,7 @@ type SQLStorage struct {
 	db *gorm.DB
 	StorDB
 	SQLImpl
+	cdrsIndexedFields map[string]string // extra field name -> mirrored column name
 }
 
 func (self *SQLStorage) Close() {
@@ -190,7 +192,7 @@ func (self *SQLStorage) RemTpData(table, tpid string, args map[string]string) er
 	if len(table) == 0 { // Remove tpid out of all tables
 		for _, tblName := range []string{utils.TBLTPTimings, utils.TBLTPDestinations, utils.TBLTPRates, utils.TBLTPDestinationRates, utils.TBLTPRatingPlans, utils.TBLTPRateProfiles,
 			utils.TBLTPSharedGroups, utils.TBLTPCdrStats, utils.TBLTPLcrs, utils.TBLTPActions, utils.TBLTPActionPlans, utils.TBLTPActionTriggers, utils.TBLTPAccountActions,
-			utils.TBLTPDerivedChargers, utils.TBLTPAliases, utils.TBLTPUsers, utils.TBLTPResourceLimits} {
+			utils.TBLTPDerivedChargers, utils.TBLTPAliases, utils.TBLTPUsers, utils.TBLTPResourceLimits, utils.TBLTPExchangeRates, utils.TBLTPHolidayCalendars, utils.TBLTPDialPlans} {
 			if err := tx.Table(tblName).Where("tpid = ?", tpid).Delete(nil).Error; err != nil {
 				tx.Rollback()
 				return err
@@ -578,6 +580,70 @@ func (self *SQLStorage) SetTPResourceLimits(rls []*utils.TPResourceLimit) error
 	return nil
 }
 
+func (self *SQLStorage) SetTPExchangeRates(ers []*utils.TPExchangeRate) error {
+	if len(ers) == 0 {
+		return nil
+	}
+	tx := self.db.Begin()
+	for _, er := range ers {
+		// Remove previous
+		if err := tx.Where(&TpExchangeRate{Tpid: er.TPid, FromCurrency: er.FromCurrency, ToCurrency: er.ToCurrency}).Delete(TpExchangeRate{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Save(APItoModelExchangeRate(er)).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (self *SQLStorage) SetTPHolidayCalendars(hcs []*utils.TPHolidayCalendar) error {
+	if len(hcs) == 0 {
+		return nil
+	}
+	tx := self.db.Begin()
+	for _, hc := range hcs {
+		// Remove previous
+		if err := tx.Where(&TpHolidayCalendar{Tpid: hc.TPid, Tag: hc.ID}).Delete(TpHolidayCalendar{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		for _, mhc := range APItoModelHolidayCalendar(hc) {
+			if err := tx.Save(mhc).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (self *SQLStorage) SetTPDialPlans(dps []*utils.TPDialPlan) error {
+	if len(dps) == 0 {
+		return nil
+	}
+	tx := self.db.Begin()
+	for _, dp := range dps {
+		// Remove previous
+		if err := tx.Where(&TpDialPlan{Tpid: dp.TPid, Tenant: dp.Tenant}).Delete(TpDialPlan{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		for _, mdp := range APItoModelDialPlan(dp) {
+			if err := tx.Save(mdp).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
 func (self *SQLStorage) SetSMCost(smc *SMCost) error {
 	if smc.CostDetails == nil {
 		return nil
@@ -698,11 +764,18 @@ func (self *SQLStorage) SetCDR(cdr *CDR, allowUpdate bool) error {
 		ExtraFields:     string(extraFields),
 		CostSource:      cdr.CostSource,
 		Cost:            cdr.Cost,
+		ShadowCost:      cdr.ShadowCost,
 		CostDetails:     cdr.CostDetailsJson(),
 		AccountSummary:  utils.ToJSON(cdr.AccountSummary),
 		ExtraInfo:       cdr.ExtraInfo,
 		CreatedAt:       time.Now(),
 	})
+	if saved.Error == nil {
+		if err := self.setCDRIndexedFields(tx, cdr); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
 	if saved.Error != nil {
 		tx.Rollback()
 		if !allowUpdate {
@@ -731,6 +804,7 @@ func (self *SQLStorage) SetCDR(cdr *CDR, allowUpdate bool) error {
 				ExtraFields:     string(extraFields),
 				CostSource:      cdr.CostSource,
 				Cost:            cdr.Cost,
+				ShadowCost:      cdr.ShadowCost,
 				CostDetails:     cdr.CostDetailsJson(),
 				AccountSummary:  utils.ToJSON(cdr.AccountSummary),
 				ExtraInfo:       cdr.ExtraInfo,
@@ -741,15 +815,64 @@ func (self *SQLStorage) SetCDR(cdr *CDR, allowUpdate bool) error {
 			tx.Rollback()
 			return updated.Error
 		}
+		if err := self.setCDRIndexedFields(tx, cdr); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 	tx.Commit()
 	return nil
 }
 
-// GetCDRs has ability to remove the selected CDRs, count them or simply return them
-// qryFltr.Unscoped will ignore soft deletes or delete records permanently
-func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR, int64, error) {
-	var cdrs []*CDR
+// cdrIndexedFieldColumn returns the column name mirroring an extra field
+func cdrIndexedFieldColumn(field string) string {
+	col := []rune("xf_" + strings.ToLower(field))
+	for i, r := range col {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			col[i] = '_'
+		}
+	}
+	return string(col)
+}
+
+// SetCDRIndexedFields mirrors the given extra fields into dedicated indexed columns, creating them if missing
+func (self *SQLStorage) SetCDRIndexedFields(fields []string) error {
+	indexedFields := make(map[string]string)
+	for _, field := range fields {
+		col := cdrIndexedFieldColumn(field)
+		if !self.db.Dialect().HasColumn(utils.TBLCDRs, col) {
+			if err := self.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s VARCHAR(255) NOT NULL DEFAULT ''",
+				utils.TBLCDRs, col)).Error; err != nil {
+				return err
+			}
+		}
+		idxName := utils.TBLCDRs + "_" + col
+		if !self.db.Dialect().HasIndex(utils.TBLCDRs, idxName) {
+			if err := self.db.Table(utils.TBLCDRs).AddIndex(idxName, col).Error; err != nil {
+				return err
+			}
+		}
+		indexedFields[field] = col
+	}
+	self.cdrsIndexedFields = indexedFields
+	return nil
+}
+
+// setCDRIndexedFields populates the indexed columns out of CDR extra fields
+func (self *SQLStorage) setCDRIndexedFields(tx *gorm.DB, cdr *CDR) error {
+	if len(self.cdrsIndexedFields) == 0 {
+		return nil
+	}
+	cols := make(map[string]interface{})
+	for field, col := range self.cdrsIndexedFields {
+		cols[col] = cdr.ExtraFields[field]
+	}
+	return tx.Table(utils.TBLCDRs).Where("cgrid = ? AND run_id = ? AND origin_id = ?",
+		cdr.CGRID, cdr.RunID, cdr.OriginID).Updates(cols).Error
+}
+
+// cdrsQuery builds the query selecting the CDRs matching qryFltr
+func (self *SQLStorage) cdrsQuery(qryFltr *utils.CDRsFilter) (*gorm.DB, error) {
 	q := self.db.Table(utils.TBLCDRs).Select("*")
 	if qryFltr.Unscoped {
 		q = q.Unscoped()
@@ -868,7 +991,9 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 			if needOr {
 				qIds.WriteString(" OR")
 			}
-			if value == utils.MetaExists {
+			if col, indexed := self.cdrsIndexedFields[field]; indexed && value != utils.MetaExists {
+				qIds.WriteString(fmt.Sprintf(" %s.%s = '%s'", utils.TBLCDRs, col, value))
+			} else if value == utils.MetaExists {
 				qIds.WriteString(self.SQLImpl.extraFieldsExistsQry(field))
 			} else {
 				qIds.WriteString(self.SQLImpl.extraFieldsValueQry(field, value))
@@ -885,7 +1010,9 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 			if needAnd {
 				qIds.WriteString(" AND")
 			}
-			if value == utils.MetaExists {
+			if col, indexed := self.cdrsIndexedFields[field]; indexed && value != utils.MetaExists {
+				qIds.WriteString(fmt.Sprintf(" %s.%s != '%s'", utils.TBLCDRs, col, value))
+			} else if value == utils.MetaExists {
 				qIds.WriteString(self.SQLImpl.notExtraFieldsExistsQry(field))
 			} else {
 				qIds.WriteString(self.SQLImpl.notExtraFieldsValueQry(field, value))
@@ -927,7 +1054,7 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 	}
 	if len(qryFltr.MinUsage) != 0 {
 		if minUsage, err := utils.ParseDurationWithSecs(qryFltr.MinUsage); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			if self.db.Dialect().GetName() == utils.MYSQL { // MySQL needs escaping for usage
 				q = q.Where("`usage` >= ?", minUsage.Seconds())
@@ -938,7 +1065,7 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 	}
 	if len(qryFltr.MaxUsage) != 0 {
 		if maxUsage, err := utils.ParseDurationWithSecs(qryFltr.MaxUsage); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			if self.db.Dialect().GetName() == utils.MYSQL { // MySQL needs escaping for usage
 				q = q.Where("`usage` < ?", maxUsage.Seconds())
@@ -950,7 +1077,7 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 	}
 	if len(qryFltr.MinPDD) != 0 {
 		if minPDD, err := utils.ParseDurationWithSecs(qryFltr.MinPDD); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			q = q.Where("pdd >= ?", minPDD.Seconds())
 		}
@@ -958,7 +1085,7 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 	}
 	if len(qryFltr.MaxPDD) != 0 {
 		if maxPDD, err := utils.ParseDurationWithSecs(qryFltr.MaxPDD); err != nil {
-			return nil, 0, err
+			return nil, err
 		} else {
 			q = q.Where("pdd < ?", maxPDD.Seconds())
 		}
@@ -986,6 +1113,85 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 	if qryFltr.Paginator.Offset != nil {
 		q = q.Offset(*qryFltr.Paginator.Offset)
 	}
+	return q, nil
+}
+
+// GetCDRsAggregates sums up the CDRs matching qryFltr inside the database, grouping them on groupBy fields <Account|Destination|*day|ExtraInfo>
+func (self *SQLStorage) GetCDRsAggregates(qryFltr *utils.CDRsFilter, groupBy []string) ([]*utils.CDRsAggregate, error) {
+	q, err := self.cdrsQuery(qryFltr)
+	if err != nil {
+		return nil, err
+	}
+	grpExprs := make([]string, len(groupBy))
+	for i, fld := range groupBy {
+		switch fld {
+		case utils.ACCOUNT:
+			grpExprs[i] = "account"
+		case utils.DESTINATION:
+			grpExprs[i] = "destination"
+		case utils.MetaDay:
+			grpExprs[i] = self.SQLImpl.dayQry("setup_time")
+		case utils.ExtraInfo:
+			grpExprs[i] = "extra_info"
+		default:
+			return nil, fmt.Errorf("unsupported group by field: %s", fld)
+		}
+	}
+	slctExprs := append(append([]string{}, grpExprs...), "COUNT(*)",
+		"SUM(CASE WHEN cost > 0 THEN cost ELSE 0 END)", fmt.Sprintf("SUM(%s)", self.db.Dialect().Quote("usage")))
+	q = q.Select(strings.Join(slctExprs, ", "))
+	if len(grpExprs) != 0 {
+		q = q.Group(strings.Join(grpExprs, ", "))
+	}
+	rows, err := q.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var aggrs []*utils.CDRsAggregate
+	for rows.Next() {
+		grpVals := make([]sql.NullString, len(groupBy))
+		var cnt int64
+		var cost, usage sql.NullFloat64
+		dest := make([]interface{}, 0, len(groupBy)+3)
+		for i := range grpVals {
+			dest = append(dest, &grpVals[i])
+		}
+		if err := rows.Scan(append(dest, &cnt, &cost, &usage)...); err != nil {
+			return nil, err
+		}
+		aggr := &utils.CDRsAggregate{Count: cnt, Cost: cost.Float64,
+			Usage: time.Duration(usage.Float64 * float64(time.Second))}
+		for i, fld := range groupBy {
+			switch fld {
+			case utils.ACCOUNT:
+				aggr.Account = grpVals[i].String
+			case utils.DESTINATION:
+				aggr.Destination = grpVals[i].String
+			case utils.MetaDay:
+				aggr.Day = grpVals[i].String
+			case utils.ExtraInfo:
+				aggr.ExtraInfo = grpVals[i].String
+			}
+		}
+		aggrs = append(aggrs, aggr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(aggrs) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return aggrs, nil
+}
+
+// GetCDRs has ability to remove the selected CDRs, count them or simply return them
+func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR, int64, error) {
+	var cdrs []*CDR
+	q, err := self.cdrsQuery(qryFltr)
+	if err != nil {
+		return nil, 0, err
+	}
 	if remove { // Remove CDRs instead of querying them
 		if err := q.Delete(nil).Error; err != nil {
 			q.Rollback()
@@ -1048,6 +1254,7 @@ func (self *SQLStorage) GetCDRs(qryFltr *utils.CDRsFilter, remove bool) ([]*CDR,
 			ExtraFields:     extraFieldsMp,
 			CostSource:      result.CostSource,
 			Cost:            result.Cost,
+			ShadowCost:      result.ShadowCost,
 			CostDetails:     &callCost,
 			AccountSummary:  acntSummary,
 			ExtraInfo:       result.ExtraInfo,
@@ -1069,10 +1276,13 @@ func (self *SQLStorage) GetTPDestinations(tpid, id string) (uTPDsts []*utils.TPD
 	if err := q.Find(&tpDests).Error; err != nil {
 		return nil, err
 	}
-	if len(tpDests.AsTPDestinations()) == 0 {
-		return tpDests.AsTPDestinations(), utils.ErrNotFound
+	if uTPDsts, err = tpDests.AsTPDestinations(); err != nil {
+		return nil, err
 	}
-	return tpDests.AsTPDestinations(), nil
+	if len(uTPDsts) == 0 {
+		return uTPDsts, utils.ErrNotFound
+	}
+	return
 }
 
 func (self *SQLStorage) GetTPRates(tpid, id string) ([]*utils.TPRate, error) {
@@ -1508,6 +1718,54 @@ func (self *SQLStorage) GetTPResourceLimits(tpid, id string) ([]*utils.TPResourc
 	return arls, nil
 }
 
+func (self *SQLStorage) GetTPExchangeRates(tpid, fromCurrency string) ([]*utils.TPExchangeRate, error) {
+	var ers TpExchangeRates
+	q := self.db.Where("tpid = ?", tpid)
+	if len(fromCurrency) != 0 {
+		q = q.Where("from_currency = ?", fromCurrency)
+	}
+	if err := q.Find(&ers).Error; err != nil {
+		return nil, err
+	}
+	aers := ers.AsTPExchangeRates()
+	if len(aers) == 0 {
+		return aers, utils.ErrNotFound
+	}
+	return aers, nil
+}
+
+func (self *SQLStorage) GetTPHolidayCalendars(tpid, id string) ([]*utils.TPHolidayCalendar, error) {
+	var hcs TpHolidayCalendars
+	q := self.db.Where("tpid = ?", tpid)
+	if len(id) != 0 {
+		q = q.Where("tag = ?", id)
+	}
+	if err := q.Find(&hcs).Error; err != nil {
+		return nil, err
+	}
+	ahcs := hcs.AsTPHolidayCalendars()
+	if len(ahcs) == 0 {
+		return ahcs, utils.ErrNotFound
+	}
+	return ahcs, nil
+}
+
+func (self *SQLStorage) GetTPDialPlans(tpid, tenant string) ([]*utils.TPDialPlan, error) {
+	var dps TpDialPlans
+	q := self.db.Where("tpid = ?", tpid)
+	if len(tenant) != 0 {
+		q = q.Where("tenant = ?", tenant)
+	}
+	if err := q.Find(&dps).Error; err != nil {
+		return nil, err
+	}
+	adps := dps.AsTPDialPlans()
+	if len(adps) == 0 {
+		return adps, utils.ErrNotFound
+	}
+	return adps, nil
+}
+
 // GetVersions returns slice of all versions or a specific version if tag is specified
 func (self *SQLStorage) GetVersions(itm string) (vrs Versions, err error) {
 	q := self.db.Model(&TBLVersion{})
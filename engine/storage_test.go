@@ -248,6 +248,26 @@ func TestStorageDisabledAccount(t *testing.T) {
 	}
 }
 
+func TestStorageRemoveRatingProfile(t *testing.T) {
+	ms, _ := NewMapStorage()
+	for _, id := range []string{"*out:cgrates.org:call:dan", "*out:cgrates.org:call:danb"} {
+		if err := ms.SetRatingProfile(&RatingProfile{Id: id}, utils.NonTransactional); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ms.RemoveRatingProfile("*out:cgrates.org:call:danb", utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	if _, err := ms.GetRatingProfile("*out:cgrates.org:call:danb", true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+	if rpf, err := ms.GetRatingProfile("*out:cgrates.org:call:dan", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if rpf.Id != "*out:cgrates.org:call:dan" {
+		t.Errorf("Unexpected rating profile: %+v", rpf)
+	}
+}
+
 // Install fails to detect them and starting server will panic, these tests will fix this
 func TestStoreInterfaces(t *testing.T) {
 	rds := new(RedisStorage)
@@ -482,3 +502,44 @@ func BenchmarkMarshallerBincStoreRestore(b *testing.B) {
 		ms.Unmarshal(result, ub1)
 	}
 }
+
+func TestStorageCacheGeneration(t *testing.T) {
+	key := utils.RATING_PLAN_PREFIX + "TEST_GENERATION"
+	if err := cacheGeneration(map[string][]string{utils.RATING_PLAN_PREFIX: nil}, true,
+		func(prefix string, IDs []string, mustBeCached bool, transactionID string) error {
+			cache.Set(key, "gen1", false, transactionID)
+			if _, hasIt := cache.Get(key); hasIt {
+				t.Error("Partial generation visible in cache")
+			}
+			return nil
+		}); err != nil {
+		t.Fatal(err)
+	}
+	if x, hasIt := cache.Get(key); !hasIt || x.(string) != "gen1" {
+		t.Errorf("Expecting gen1, received: %v", x)
+	}
+	if err := cacheGeneration(map[string][]string{utils.RATING_PLAN_PREFIX: nil}, true,
+		func(prefix string, IDs []string, mustBeCached bool, transactionID string) error {
+			cache.Set(key, "gen2", false, transactionID)
+			return utils.ErrNotFound
+		}); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+	if x, hasIt := cache.Get(key); !hasIt || x.(string) != "gen1" {
+		t.Errorf("Failed generation replaced cache: %v", x)
+	}
+	cache.RemKey(key, true, utils.NonTransactional)
+}
+
+func TestCdrIndexedFieldColumn(t *testing.T) {
+	for field, eCol := range map[string]string{
+		"TrunkID":      "xf_trunkid",
+		"Disconnect-X": "xf_disconnect_x",
+		"field_1":      "xf_field_1",
+		"a b;c":        "xf_a_b_c",
+	} {
+		if col := cdrIndexedFieldColumn(field); col != eCol {
+			t.Errorf("Field: %s, expecting: %s, received: %s", field, eCol, col)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewTieredDataDB serves accounts, rating plans and rating profiles out of a local DataDB in front of the remote, authoritative one
+// Objects are promoted to the local store on first access, written through to both stores and demoted after hotTTL without access
+func NewTieredDataDB(local, remote DataDB, hotTTL time.Duration) *TieredDataDB {
+	tdb := &TieredDataDB{DataDB: remote, local: local, hotTTL: hotTTL, lastHit: make(map[string]time.Time)}
+	if hotTTL > 0 {
+		go tdb.demoteLoop()
+	}
+	return tdb
+}
+
+type TieredDataDB struct {
+	DataDB  // remote store, authoritative
+	local   DataDB
+	hotTTL  time.Duration
+	mu      sync.Mutex
+	lastHit map[string]time.Time // promoted keys with their last access
+}
+
+func (tdb *TieredDataDB) touch(key string) {
+	tdb.mu.Lock()
+	tdb.lastHit[key] = time.Now()
+	tdb.mu.Unlock()
+}
+
+func (tdb *TieredDataDB) forget(key string) {
+	tdb.mu.Lock()
+	delete(tdb.lastHit, key)
+	tdb.mu.Unlock()
+}
+
+// fromCache mirrors the cache lookup done by the stores so the local misses do not mask the cached objects
+func (tdb *TieredDataDB) fromCache(key string, skipCache bool) (x interface{}, err error, has bool) {
+	if skipCache {
+		return
+	}
+	if x, has = cache.Get(key); has && x == nil {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
+func (tdb *TieredDataDB) GetRatingPlan(key string, skipCache bool, transactionID string) (rp *RatingPlan, err error) {
+	if x, err, has := tdb.fromCache(utils.RATING_PLAN_PREFIX+key, skipCache); has {
+		if err != nil {
+			return nil, err
+		}
+		return x.(*RatingPlan), nil
+	}
+	if rp, err = tdb.local.GetRatingPlan(key, true, transactionID); err == nil {
+		tdb.touch(utils.RATING_PLAN_PREFIX + key)
+		return
+	}
+	if rp, err = tdb.DataDB.GetRatingPlan(key, true, transactionID); err != nil {
+		return
+	}
+	if err := tdb.local.SetRatingPlan(rp, transactionID); err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<TieredDataDB> could not promote rating plan %s, error: %s", key, err.Error()))
+	} else {
+		tdb.touch(utils.RATING_PLAN_PREFIX + key)
+	}
+	cache.Set(utils.RATING_PLAN_PREFIX+key, rp, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (tdb *TieredDataDB) SetRatingPlan(rp *RatingPlan, transactionID string) (err error) {
+	if err = tdb.DataDB.SetRatingPlan(rp, transactionID); err != nil {
+		return
+	}
+	if err = tdb.local.SetRatingPlan(rp, transactionID); err != nil {
+		return
+	}
+	tdb.touch(utils.RATING_PLAN_PREFIX + rp.Id)
+	return
+}
+
+func (tdb *TieredDataDB) RemoveRatingPlan(key string, transactionID string) (err error) {
+	if err = tdb.DataDB.RemoveRatingPlan(key, transactionID); err != nil {
+		return
+	}
+	tdb.forget(utils.RATING_PLAN_PREFIX + key)
+	return tdb.local.RemoveRatingPlan(key, transactionID)
+}
+
+func (tdb *TieredDataDB) GetRatingProfile(key string, skipCache bool, transactionID string) (rpf *RatingProfile, err error) {
+	if x, err, has := tdb.fromCache(utils.RATING_PROFILE_PREFIX+key, skipCache); has {
+		if err != nil {
+			return nil, err
+		}
+		return x.(*RatingProfile), nil
+	}
+	if rpf, err = tdb.local.GetRatingProfile(key, true, transactionID); err == nil {
+		tdb.touch(utils.RATING_PROFILE_PREFIX + key)
+		return
+	}
+	if rpf, err = tdb.DataDB.GetRatingProfile(key, true, transactionID); err != nil {
+		return
+	}
+	if err := tdb.local.SetRatingProfile(rpf, transactionID); err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<TieredDataDB> could not promote rating profile %s, error: %s", key, err.Error()))
+	} else {
+		tdb.touch(utils.RATING_PROFILE_PREFIX + key)
+	}
+	cache.Set(utils.RATING_PROFILE_PREFIX+key, rpf, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (tdb *TieredDataDB) SetRatingProfile(rpf *RatingProfile, transactionID string) (err error) {
+	if err = tdb.DataDB.SetRatingProfile(rpf, transactionID); err != nil {
+		return
+	}
+	if err = tdb.local.SetRatingProfile(rpf, transactionID); err != nil {
+		return
+	}
+	tdb.touch(utils.RATING_PROFILE_PREFIX + rpf.Id)
+	return
+}
+
+func (tdb *TieredDataDB) RemoveRatingProfile(key string, transactionID string) (err error) {
+	if err = tdb.DataDB.RemoveRatingProfile(key, transactionID); err != nil {
+		return
+	}
+	tdb.forget(utils.RATING_PROFILE_PREFIX + key)
+	return tdb.local.RemoveRatingProfile(key, transactionID)
+}
+
+func (tdb *TieredDataDB) GetAccount(key string) (acc *Account, err error) {
+	if acc, err = tdb.local.GetAccount(key); err == nil {
+		tdb.touch(utils.ACCOUNT_PREFIX + key)
+		return
+	}
+	if acc, err = tdb.DataDB.GetAccount(key); err != nil {
+		return
+	}
+	if err := tdb.local.SetAccount(acc); err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<TieredDataDB> could not promote account %s, error: %s", key, err.Error()))
+	} else {
+		tdb.touch(utils.ACCOUNT_PREFIX + key)
+	}
+	return
+}
+
+func (tdb *TieredDataDB) SetAccount(acc *Account) (err error) {
+	if err = tdb.DataDB.SetAccount(acc); err != nil {
+		return
+	}
+	if err = tdb.local.SetAccount(acc); err != nil {
+		return
+	}
+	tdb.touch(utils.ACCOUNT_PREFIX + acc.ID)
+	return
+}
+
+func (tdb *TieredDataDB) RemoveAccount(key string) (err error) {
+	if err = tdb.DataDB.RemoveAccount(key); err != nil {
+		return
+	}
+	tdb.forget(utils.ACCOUNT_PREFIX + key)
+	return tdb.local.RemoveAccount(key)
+}
+
+// CacheDataFromDB demotes the reloaded objects so the local store does not serve stale copies
+func (tdb *TieredDataDB) CacheDataFromDB(prefix string, IDs []string, mustBeCached bool) (err error) {
+	if prefix == utils.RATING_PLAN_PREFIX || prefix == utils.RATING_PROFILE_PREFIX {
+		var keys []string
+		if IDs == nil {
+			tdb.mu.Lock()
+			for key := range tdb.lastHit {
+				if strings.HasPrefix(key, prefix) {
+					keys = append(keys, key)
+				}
+			}
+			tdb.mu.Unlock()
+		} else {
+			for _, id := range IDs {
+				keys = append(keys, prefix+id)
+			}
+		}
+		var recache []string // demoting drops the cached copies
+		for _, key := range keys {
+			if _, has := cache.Get(key); has {
+				recache = append(recache, key[len(prefix):])
+			}
+			tdb.demoteKey(key)
+		}
+		if err = tdb.DataDB.CacheDataFromDB(prefix, IDs, mustBeCached); err != nil || !mustBeCached || len(recache) == 0 {
+			return
+		}
+		return tdb.DataDB.CacheDataFromDB(prefix, recache, false)
+	}
+	return tdb.DataDB.CacheDataFromDB(prefix, IDs, mustBeCached)
+}
+
+// CacheDataGenerationFromDB reloads prefix by prefix since demoting the rating objects drops their cached copies
+func (tdb *TieredDataDB) CacheDataGenerationFromDB(prefixIDs map[string][]string, mustBeCached bool) (err error) {
+	if _, has := prefixIDs[utils.RATING_PLAN_PREFIX]; !has {
+		if _, has = prefixIDs[utils.RATING_PROFILE_PREFIX]; !has {
+			return tdb.DataDB.CacheDataGenerationFromDB(prefixIDs, mustBeCached)
+		}
+	}
+	for prefix, IDs := range prefixIDs {
+		if err = tdb.CacheDataFromDB(prefix, IDs, mustBeCached); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// demoteKey removes the object from the local store, remote one stays untouched
+func (tdb *TieredDataDB) demoteKey(key string) {
+	var err error
+	switch {
+	case strings.HasPrefix(key, utils.RATING_PLAN_PREFIX):
+		err = tdb.local.RemoveRatingPlan(key[len(utils.RATING_PLAN_PREFIX):], utils.NonTransactional)
+	case strings.HasPrefix(key, utils.RATING_PROFILE_PREFIX):
+		err = tdb.local.RemoveRatingProfile(key[len(utils.RATING_PROFILE_PREFIX):], utils.NonTransactional)
+	case strings.HasPrefix(key, utils.ACCOUNT_PREFIX):
+		err = tdb.local.RemoveAccount(key[len(utils.ACCOUNT_PREFIX):])
+	}
+	if err != nil && err != utils.ErrNotFound {
+		utils.Logger.Warning(fmt.Sprintf("<TieredDataDB> could not demote %s, error: %s", key, err.Error()))
+		return
+	}
+	tdb.forget(key)
+}
+
+// Demote removes from the local store the objects not accessed since olderThan
+func (tdb *TieredDataDB) Demote(olderThan time.Time) {
+	var keys []string
+	tdb.mu.Lock()
+	for key, lastHit := range tdb.lastHit {
+		if lastHit.Before(olderThan) {
+			keys = append(keys, key)
+		}
+	}
+	tdb.mu.Unlock()
+	for _, key := range keys {
+		tdb.demoteKey(key)
+	}
+}
+
+// HotKeys returns the keys currently served by the local store
+func (tdb *TieredDataDB) HotKeys() (keys []string) {
+	tdb.mu.Lock()
+	defer tdb.mu.Unlock()
+	for key := range tdb.lastHit {
+		keys = append(keys, key)
+	}
+	return
+}
+
+func (tdb *TieredDataDB) demoteLoop() {
+	for {
+		time.Sleep(tdb.hotTTL / 2)
+		tdb.Demote(time.Now().Add(-tdb.hotTTL))
+	}
+}
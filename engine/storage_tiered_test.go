@@ -0,0 +1,73 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestTieredDataDBPromoteDemote(t *testing.T) {
+	local, _ := NewMapStorage()
+	remote, _ := NewMapStorage()
+	tdb := NewTieredDataDB(local, remote, 0)
+	acc := &Account{ID: "cgrates.org:tiered"}
+	remote.SetAccount(acc)
+	if has, _ := local.HasData(utils.ACCOUNT_PREFIX, acc.ID); has {
+		t.Fatal("Account should not be local yet")
+	}
+	if rcv, err := tdb.GetAccount(acc.ID); err != nil || rcv.ID != acc.ID {
+		t.Fatalf("Received: %+v, err: %v", rcv, err)
+	}
+	if has, _ := local.HasData(utils.ACCOUNT_PREFIX, acc.ID); !has {
+		t.Error("Account not promoted")
+	}
+	rp := &RatingPlan{Id: "RP_TIERED"}
+	if err := tdb.SetRatingPlan(rp, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := remote.HasData(utils.RATING_PLAN_PREFIX, rp.Id); !has {
+		t.Error("Rating plan not written through")
+	}
+	if _, err := tdb.GetRatingPlan("RP_MISSING", false, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting not found, received: %v", err)
+	}
+	if hot := tdb.HotKeys(); len(hot) != 2 {
+		t.Errorf("Unexpected hot keys: %v", hot)
+	}
+	tdb.Demote(time.Now().Add(time.Minute))
+	if hot := tdb.HotKeys(); len(hot) != 0 {
+		t.Errorf("Unexpected hot keys: %v", hot)
+	}
+	if has, _ := local.HasData(utils.ACCOUNT_PREFIX, acc.ID); has {
+		t.Error("Account not demoted")
+	}
+	if has, _ := local.HasData(utils.RATING_PLAN_PREFIX, rp.Id); has {
+		t.Error("Rating plan not demoted")
+	}
+	if rcv, err := tdb.GetRatingPlan(rp.Id, false, utils.NonTransactional); err != nil || rcv.Id != rp.Id {
+		t.Errorf("Received: %+v, err: %v", rcv, err)
+	} else if has, _ := local.HasData(utils.RATING_PLAN_PREFIX, rp.Id); !has {
+		t.Error("Rating plan not promoted")
+	}
+	cache.RemKey(utils.RATING_PLAN_PREFIX+rp.Id, true, "")
+	cache.RemKey(utils.RATING_PLAN_PREFIX+"RP_MISSING", true, "")
+}
@@ -0,0 +1,545 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// xlsxSheetModels are the models used to validate the cells of each TP sheet
+var xlsxSheetModels = map[string]interface{}{
+	utils.DESTINATIONS_CSV:      TpDestination{},
+	utils.TIMINGS_CSV:           TpTiming{},
+	utils.RATES_CSV:             TpRate{},
+	utils.DESTINATION_RATES_CSV: TpDestinationRate{},
+	utils.RATING_PLANS_CSV:      TpRatingPlan{},
+	utils.RATING_PROFILES_CSV:   TpRatingProfile{},
+	utils.SHARED_GROUPS_CSV:     TpSharedGroup{},
+	utils.LCRS_CSV:              TpLcrRule{},
+	utils.ACTIONS_CSV:           TpAction{},
+	utils.ACTION_PLANS_CSV:      TpActionPlan{},
+	utils.ACTION_TRIGGERS_CSV:   TpActionTrigger{},
+	utils.ACCOUNT_ACTIONS_CSV:   TpAccountAction{},
+	utils.DERIVED_CHARGERS_CSV:  TpDerivedCharger{},
+	utils.CDR_STATS_CSV:         TpCdrstat{},
+	utils.USERS_CSV:             TpUser{},
+	utils.ALIASES_CSV:           TpAlias{},
+	utils.ResourceLimitsCsv:     TpResourceLimit{},
+	utils.ExchangeRatesCsv:      TpExchangeRate{},
+	utils.HolidayCalendarsCsv:   TpHolidayCalendar{},
+	utils.DialPlansCsv:          TpDialPlan{},
+}
+
+var (
+	googleSheetsRegexp  = regexp.MustCompile(`^https://docs\.google\.com/spreadsheets/d/([A-Za-z0-9_-]+)`)
+	xlsxDownloadTimeout = time.Minute
+)
+
+// XLSXSheetMapping reads a TP category out of a sheet maintained in its own layout
+type XLSXSheetMapping struct {
+	Sheet    string   // name of the sheet, defaults to the one of the category
+	Columns  []string // sheet column for each TP column, eg: ["B", "A", "", "D"], empty ones are left blank
+	SkipRows int      // header rows to ignore
+}
+
+// XLSXMappings are the sheet mappings indexed on category, eg: Rates or Rates.csv
+type XLSXMappings map[string]*XLSXSheetMapping
+
+// LoadXLSXMappingsFile reads the sheet mappings out of a JSON file
+func LoadXLSXMappingsFile(fPath string) (XLSXMappings, error) {
+	content, err := ioutil.ReadFile(fPath)
+	if err != nil {
+		return nil, err
+	}
+	var mappings XLSXMappings
+	if err := json.Unmarshal(content, &mappings); err != nil {
+		return nil, fmt.Errorf("file <%s>: %s", fPath, err.Error())
+	}
+	return mappings, nil
+}
+
+// byFileName returns the mappings indexed on the CSV file name of their category
+func (mappings XLSXMappings) byFileName() (map[string]*XLSXSheetMapping, error) {
+	byFName := make(map[string]*XLSXSheetMapping)
+	for category, mapping := range mappings {
+		fName := category
+		if !strings.HasSuffix(fName, utils.CSVSuffix) {
+			fName += utils.CSVSuffix
+		}
+		if _, isTP := xlsxSheetModels[fName]; !isTP {
+			return nil, fmt.Errorf("unknown tariff plan category <%s> in sheet mappings", category)
+		}
+		byFName[fName] = mapping
+	}
+	return byFName, nil
+}
+
+// sourceColumns returns the sheet column for each of the nrFields TP columns, -1 for the ones left blank
+func (mapping *XLSXSheetMapping) sourceColumns(nrFields int) ([]int, error) {
+	srcCols := make([]int, nrFields)
+	if mapping == nil || len(mapping.Columns) == 0 {
+		for i := range srcCols {
+			srcCols[i] = i
+		}
+		return srcCols, nil
+	}
+	if len(mapping.Columns) > nrFields {
+		return nil, fmt.Errorf("%d columns mapped, expecting maximum %d", len(mapping.Columns), nrFields)
+	}
+	for i := range srcCols {
+		srcCols[i] = -1
+		if i >= len(mapping.Columns) || mapping.Columns[i] == "" {
+			continue
+		}
+		colIdx, err := xlsxColumnIndex(strings.ToUpper(mapping.Columns[i]))
+		if err != nil {
+			return nil, err
+		}
+		srcCols[i] = colIdx
+	}
+	return srcCols, nil
+}
+
+// NewXLSXStorage reads the tariff plan out of an .xlsx workbook
+// Each TP category is read out of the sheet named as the CSV file, with or without the .csv suffix, eg: Rates
+// The columns follow the ones of the CSV files unless mapped otherwise, rows starting with # are ignored
+func NewXLSXStorage(sep rune, xlsxPath string, mappings XLSXMappings) (*CSVStorage, error) {
+	files, err := readTPWorkbook(sep, xlsxPath, mappings)
+	if err != nil {
+		return nil, err
+	}
+	return newFilesMapCSVStorage(sep, files), nil
+}
+
+// IsRemoteWorkbook returns true for workbooks to be downloaded, eg: Google Sheets or .xlsx URLs
+func IsRemoteWorkbook(fPath string) bool {
+	return strings.HasPrefix(fPath, "http://") || strings.HasPrefix(fPath, "https://")
+}
+
+// NewRemoteXLSXStorage reads the tariff plan out of a workbook downloaded from wbURL
+// Google Sheets documents are exported as .xlsx, hence they need to be shared with anyone having the link
+func NewRemoteXLSXStorage(sep rune, wbURL string, mappings XLSXMappings) (*CSVStorage, error) {
+	files, err := downloadTPWorkbook(sep, wbURL, mappings)
+	if err != nil {
+		return nil, err
+	}
+	return newFilesMapCSVStorage(sep, files), nil
+}
+
+// workbookURL returns the .xlsx export URL of a Google Sheets document, other URLs are returned as they are
+func workbookURL(wbURL string) string {
+	if m := googleSheetsRegexp.FindStringSubmatch(wbURL); m != nil {
+		return "https://docs.google.com/spreadsheets/d/" + m[1] + "/export?format=xlsx"
+	}
+	return wbURL
+}
+
+// downloadTPWorkbook returns the TP sheets of the workbook at wbURL converted to CSV, indexed on the CSV file name
+func downloadTPWorkbook(sep rune, wbURL string, mappings XLSXMappings) (map[string]string, error) {
+	client := &http.Client{Timeout: xlsxDownloadTimeout}
+	resp, err := client.Get(workbookURL(wbURL))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading <%s>: %s", wbURL, resp.Status)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil { // eg: login page instead of the workbook for documents not shared
+		return nil, fmt.Errorf("<%s> is not an .xlsx workbook: %s", wbURL, err.Error())
+	}
+	return readTPWorkbookZip(sep, wbURL, zr, mappings)
+}
+
+type xlsxWorkbook struct {
+	WorkbookPr struct {
+		Date1904 bool `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.T
+	}
+	var s string
+	for _, r := range rt.Runs {
+		s += r.T
+	}
+	return s
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID         int    `xml:"numFmtId,attr"`
+		FormatCode string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxCell struct {
+	Ref       string        `xml:"r,attr"`
+	Type      string        `xml:"t,attr"`
+	Style     int           `xml:"s,attr"`
+	Value     string        `xml:"v"`
+	InlineStr *xlsxRichText `xml:"is"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Ref   int        `xml:"r,attr"`
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// xlsxReader decodes the cells of a workbook into their CSV representation
+type xlsxReader struct {
+	files         map[string]*zip.File
+	sharedStrings []string
+	dateStyles    map[int]bool // indexes of cell styles formatting numbers as date/time
+	date1904      bool
+}
+
+func (xr *xlsxReader) decode(fPath string, v interface{}) (bool, error) {
+	zf, has := xr.files[fPath]
+	if !has {
+		return false, nil
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		return true, err
+	}
+	defer rc.Close()
+	return true, xml.NewDecoder(rc).Decode(v)
+}
+
+// isDateFormat returns true for the number formats displaying dates or times
+func isDateFormat(numFmtID int, formatCode string) bool {
+	if (numFmtID >= 14 && numFmtID <= 22) || (numFmtID >= 45 && numFmtID <= 47) {
+		return true
+	}
+	if formatCode == "" {
+		return false
+	}
+	var inQuotes bool
+	for _, r := range strings.ToLower(formatCode) {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case r == 'y' || r == 'd' || r == 'h' || r == 's':
+			return true
+		}
+	}
+	return false
+}
+
+// dateValue converts the serial number of a date styled cell into its TP representation
+func (xr *xlsxReader) dateValue(serial float64) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if xr.date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days, frac := math.Modf(serial)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(math.Floor(frac*86400+0.5)) * time.Second)
+	if days == 0 { // time only, eg: timings
+		return t.Format("15:04:05")
+	}
+	return t.Format("2006-01-02T15:04:05Z")
+}
+
+// cellValue returns the string value of a cell, error cells are rejected
+func (xr *xlsxReader) cellValue(c xlsxCell) (string, error) {
+	switch c.Type {
+	case "s":
+		idx, err := strconv.Atoi(c.Value)
+		if err != nil || idx < 0 || idx >= len(xr.sharedStrings) {
+			return "", fmt.Errorf("invalid shared string reference <%s>", c.Value)
+		}
+		return xr.sharedStrings[idx], nil
+	case "inlineStr":
+		if c.InlineStr == nil {
+			return "", nil
+		}
+		return c.InlineStr.String(), nil
+	case "str", "d":
+		return c.Value, nil
+	case "b":
+		return strconv.FormatBool(c.Value == "1"), nil
+	case "e":
+		return "", fmt.Errorf("error value <%s>", c.Value)
+	}
+	if c.Value == "" { // numeric cell without value
+		return "", nil
+	}
+	f, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number <%s>", c.Value)
+	}
+	if xr.dateStyles[c.Style] {
+		return xr.dateValue(f), nil
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// xlsxColumnIndex returns the zero based column index out of a cell reference, eg: C4 returns 2
+func xlsxColumnIndex(ref string) (idx int, err error) {
+	var i int
+	for i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z' {
+		idx = idx*26 + int(ref[i]-'A') + 1
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid cell reference <%s>", ref)
+	}
+	return idx - 1, nil
+}
+
+// xlsxColumnName returns the column letters out of the zero based column index
+func xlsxColumnName(idx int) (name string) {
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		name = string(rune('A'+(idx-1)%26)) + name
+	}
+	return
+}
+
+// validateXLSXRow checks the data type of each cell against the model field on the same column
+func validateXLSXRow(model interface{}, sheet string, rowNr int, record []string, srcCols []int) error {
+	st := reflect.TypeOf(model)
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		idx, err := strconv.Atoi(field.Tag.Get("index"))
+		if err != nil || idx >= len(record) || record[idx] == "" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Float64:
+			_, err = strconv.ParseFloat(record[idx], 64)
+		case reflect.Int:
+			_, err = strconv.Atoi(record[idx])
+		case reflect.Bool:
+			_, err = strconv.ParseBool(record[idx])
+		}
+		if err != nil {
+			return fmt.Errorf("sheet <%s>, cell %s%d: invalid %s value <%s> for %s",
+				sheet, xlsxColumnName(srcCols[idx]), rowNr, field.Type.Kind(), record[idx], field.Name)
+		}
+	}
+	return nil
+}
+
+// sheetCSV converts the rows of a TP sheet into CSV content, validating the cells on the way
+func (xr *xlsxReader) sheetCSV(sep rune, sheet string, ws *xlsxWorksheet, model interface{}, mapping *XLSXSheetMapping) (string, error) {
+	nrFields := getColumnCount(model)
+	srcCols, err := mapping.sourceColumns(nrFields)
+	if err != nil {
+		return "", fmt.Errorf("sheet <%s>: %s", sheet, err.Error())
+	}
+	buf := new(bytes.Buffer)
+	csvWriter := csv.NewWriter(buf)
+	csvWriter.Comma = sep
+	for i, row := range ws.Rows {
+		rowNr := row.Ref
+		if rowNr == 0 {
+			rowNr = i + 1
+		}
+		if mapping != nil && rowNr <= mapping.SkipRows {
+			continue
+		}
+		cells := make(map[int]string)
+		for j, c := range row.Cells {
+			colIdx := j
+			if c.Ref != "" {
+				var err error
+				if colIdx, err = xlsxColumnIndex(c.Ref); err != nil {
+					return "", fmt.Errorf("sheet <%s>: %s", sheet, err.Error())
+				}
+			}
+			val, err := xr.cellValue(c)
+			if err != nil {
+				return "", fmt.Errorf("sheet <%s>, cell %s%d: %s", sheet, xlsxColumnName(colIdx), rowNr, err.Error())
+			}
+			if val == "" {
+				continue
+			}
+			if colIdx >= nrFields && (mapping == nil || len(mapping.Columns) == 0) {
+				return "", fmt.Errorf("sheet <%s>, cell %s%d: column out of range, expecting %d columns",
+					sheet, xlsxColumnName(colIdx), rowNr, nrFields)
+			}
+			cells[colIdx] = val
+		}
+		record := make([]string, nrFields)
+		var hasData bool
+		for i, srcCol := range srcCols {
+			if val, has := cells[srcCol]; has {
+				record[i] = val
+				hasData = true
+			}
+		}
+		if !hasData {
+			continue
+		}
+		if !strings.HasPrefix(record[0], string(utils.COMMENT_CHAR)) {
+			if err := validateXLSXRow(model, sheet, rowNr, record, srcCols); err != nil {
+				return "", err
+			}
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return "", err
+		}
+	}
+	csvWriter.Flush()
+	return buf.String(), csvWriter.Error()
+}
+
+// readTPWorkbook returns the TP sheets of the workbook converted to CSV, indexed on the CSV file name
+func readTPWorkbook(sep rune, xlsxPath string, mappings XLSXMappings) (files map[string]string, err error) {
+	zr, err := zip.OpenReader(xlsxPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readTPWorkbookZip(sep, xlsxPath, &zr.Reader, mappings)
+}
+
+// readTPWorkbookZip converts the TP sheets out of the workbook archive, xlsxPath is used for reporting
+func readTPWorkbookZip(sep rune, xlsxPath string, zr *zip.Reader, mappings XLSXMappings) (files map[string]string, err error) {
+	fNameMappings, err := mappings.byFileName()
+	if err != nil {
+		return nil, err
+	}
+	sheetFNames := make(map[string]string) // sheets mapped on a different name than their category
+	for fName, mapping := range fNameMappings {
+		if mapping != nil && mapping.Sheet != "" {
+			sheetFNames[mapping.Sheet] = fName
+		}
+	}
+	xr := &xlsxReader{files: make(map[string]*zip.File), dateStyles: make(map[int]bool)}
+	for _, zf := range zr.File {
+		xr.files[zf.Name] = zf
+	}
+	var wb xlsxWorkbook
+	if has, err := xr.decode("xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("no workbook found in <%s>", xlsxPath)
+	}
+	xr.date1904 = wb.WorkbookPr.Date1904
+	var rels xlsxRelationships
+	if _, err = xr.decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetPaths := make(map[string]string)
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			sheetPaths[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			sheetPaths[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+	var sst xlsxSharedStrings
+	if _, err = xr.decode("xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+	for _, si := range sst.Items {
+		xr.sharedStrings = append(xr.sharedStrings, si.String())
+	}
+	var styles xlsxStyles
+	if _, err = xr.decode("xl/styles.xml", &styles); err != nil {
+		return nil, err
+	}
+	fmtCodes := make(map[int]string)
+	for _, nf := range styles.NumFmts {
+		fmtCodes[nf.ID] = nf.FormatCode
+	}
+	for i, xf := range styles.CellXfs {
+		if isDateFormat(xf.NumFmtID, fmtCodes[xf.NumFmtID]) {
+			xr.dateStyles[i] = true
+		}
+	}
+	files = make(map[string]string)
+	for _, sheet := range wb.Sheets {
+		fName, isMapped := sheetFNames[sheet.Name]
+		if !isMapped {
+			if fName = sheet.Name; !strings.HasSuffix(fName, utils.CSVSuffix) {
+				fName += utils.CSVSuffix
+			}
+			if mapping, has := fNameMappings[fName]; has && mapping != nil && mapping.Sheet != "" {
+				continue // category read out of another sheet
+			}
+		}
+		model, isTP := xlsxSheetModels[fName]
+		if !isTP { // other sheets like notes are ignored
+			continue
+		}
+		var ws xlsxWorksheet
+		if has, err := xr.decode(sheetPaths[sheet.RID], &ws); err != nil {
+			return nil, fmt.Errorf("sheet <%s>: %s", sheet.Name, err.Error())
+		} else if !has {
+			return nil, fmt.Errorf("sheet <%s> not found in <%s>", sheet.Name, xlsxPath)
+		}
+		if files[fName], err = xr.sheetCSV(sep, sheet.Name, &ws, model, fNameMappings[fName]); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
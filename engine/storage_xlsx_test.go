@@ -0,0 +1,207 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+const xlsxTestWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Destinations" sheetId="1" r:id="rId1"/><sheet name="Rates" sheetId="2" r:id="rId2"/>
+<sheet name="RatingProfiles" sheetId="3" r:id="rId3"/><sheet name="Notes" sheetId="4" r:id="rId4"/></sheets>
+</workbook>`
+
+const xlsxTestRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet3.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet4.xml"/>
+</Relationships>`
+
+const xlsxTestSharedStrings = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>#Tag</t></si><si><t>Prefix</t></si><si><t>DST_1002</t></si><si><r><t>RT_</t></r><r><t>1CNT</t></r></si>
+</sst>`
+
+const xlsxTestStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<cellXfs count="2"><xf numFmtId="0"/><xf numFmtId="14"/></cellXfs>
+</styleSheet>`
+
+const xlsxTestDestinations = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>1002</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>DST_LAN</t></is></c><c r="B3" t="inlineStr"><is><t>10.0.0.0/8</t></is></c><c r="C3" t="inlineStr"><is><t>*ip</t></is></c></row>
+</sheetData></worksheet>`
+
+const xlsxTestRates = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>0</v></c>%s<c r="D2" t="inlineStr"><is><t>60s</t></is></c><c r="E2" t="inlineStr"><is><t>1s</t></is></c><c r="F2" t="inlineStr"><is><t>0s</t></is></c></row>
+</sheetData></worksheet>`
+
+const xlsxTestRatingProfiles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="str"><v>*out</v></c><c r="B1" t="str"><v>cgrates.org</v></c><c r="C1" t="str"><v>call</v></c><c r="D1" t="str"><v>*any</v></c><c r="E1" s="1"><v>41275.5</v></c><c r="F1" t="str"><v>RP_1CNT</v></c></row>
+</sheetData></worksheet>`
+
+const xlsxTestNotes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>not a tariff plan sheet</t></is></c><c r="Z1"><v>1</v></c></row>
+</sheetData></worksheet>`
+
+func writeTestWorkbook(t *testing.T, xlsxPath, rateCell string) {
+	fp, err := os.Create(xlsxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fp.Close()
+	zw := zip.NewWriter(fp)
+	for fName, content := range map[string]string{
+		"xl/workbook.xml":            xlsxTestWorkbook,
+		"xl/_rels/workbook.xml.rels": xlsxTestRels,
+		"xl/sharedStrings.xml":       xlsxTestSharedStrings,
+		"xl/styles.xml":              xlsxTestStyles,
+		"xl/worksheets/sheet1.xml":   xlsxTestDestinations,
+		"xl/worksheets/sheet2.xml":   strings.Replace(xlsxTestRates, "%s", rateCell, 1),
+		"xl/worksheets/sheet3.xml":   xlsxTestRatingProfiles,
+		"xl/worksheets/sheet4.xml":   xlsxTestNotes,
+	} {
+		w, err := zw.Create(fName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestXLSXStorage(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cgr_tpxlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	xlsxPath := path.Join(tmpDir, "tariffplan"+utils.XLSXSuffix)
+	writeTestWorkbook(t, xlsxPath, `<c r="C2"><v>1.0000000000000001E-2</v></c>`)
+	xlsxStor, err := NewXLSXStorage(utils.CSV_SEP, xlsxPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsts, err := xlsxStor.GetTPDestinations("TEST_XLSX", ""); err != nil {
+		t.Error(err)
+	} else if len(dsts) != 2 {
+		t.Errorf("Unexpected destinations: %s", utils.ToJSON(dsts))
+	}
+	if rts, err := xlsxStor.GetTPRates("TEST_XLSX", ""); err != nil {
+		t.Error(err)
+	} else if len(rts) != 1 || rts[0].ID != "RT_1CNT" || rts[0].RateSlots[0].Rate != 0.01 {
+		t.Errorf("Unexpected rates: %s", utils.ToJSON(rts))
+	}
+	if rpfs, err := xlsxStor.GetTPRatingProfiles(&utils.TPRatingProfile{TPid: "TEST_XLSX"}); err != nil {
+		t.Error(err)
+	} else if len(rpfs) != 1 || rpfs[0].RatingPlanActivations[0].ActivationTime != "2013-01-01T12:00:00Z" {
+		t.Errorf("Unexpected rating profiles: %s", utils.ToJSON(rpfs))
+	}
+	writeTestWorkbook(t, xlsxPath, `<c r="C2" t="inlineStr"><is><t>0.1O</t></is></c>`)
+	if _, err := NewXLSXStorage(utils.CSV_SEP, xlsxPath, nil); err == nil || !strings.Contains(err.Error(), "cell C2") {
+		t.Errorf("Expecting cell validation error, received: %v", err)
+	}
+}
+
+func TestXLSXStorageMappings(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cgr_tpxlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	xlsxPath := path.Join(tmpDir, "tariffplan"+utils.XLSXSuffix)
+	writeTestWorkbook(t, xlsxPath, `<c r="C2"><v>0.01</v></c>`)
+	mappings := XLSXMappings{"Destinations": &XLSXSheetMapping{Columns: []string{"B", "A"}, SkipRows: 1}}
+	xlsxStor, err := NewXLSXStorage(utils.CSV_SEP, xlsxPath, mappings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsts, err := xlsxStor.GetTPDestinations("TEST_XLSX", ""); err != nil {
+		t.Error(err)
+	} else if len(dsts) != 2 {
+		t.Errorf("Unexpected destinations: %s", utils.ToJSON(dsts))
+	} else {
+		for _, dst := range dsts {
+			if dst.ID == "1002" && dst.Prefixes[0] != "DST_1002" {
+				t.Errorf("Unexpected destination: %s", utils.ToJSON(dst))
+			}
+		}
+	}
+	if _, err := NewXLSXStorage(utils.CSV_SEP, xlsxPath,
+		XLSXMappings{"Unknown": &XLSXSheetMapping{}}); err == nil {
+		t.Error("Expecting error on unknown category")
+	}
+}
+
+func TestRemoteXLSXStorage(t *testing.T) {
+	if rcv := workbookURL("https://docs.google.com/spreadsheets/d/1aBc-_9/edit#gid=0"); rcv != "https://docs.google.com/spreadsheets/d/1aBc-_9/export?format=xlsx" {
+		t.Errorf("Unexpected URL: %s", rcv)
+	}
+	tmpDir, err := ioutil.TempDir("", "cgr_tpxlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	xlsxPath := path.Join(tmpDir, "tariffplan"+utils.XLSXSuffix)
+	writeTestWorkbook(t, xlsxPath, `<c r="C2"><v>0.01</v></c>`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tariffplan.xlsx" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, xlsxPath)
+	}))
+	defer srv.Close()
+	if !IsRemoteWorkbook(srv.URL + "/tariffplan.xlsx") {
+		t.Error("Expecting remote workbook")
+	}
+	xlsxStor, err := NewRemoteXLSXStorage(utils.CSV_SEP, srv.URL+"/tariffplan.xlsx", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rts, err := xlsxStor.GetTPRates("TEST_XLSX", ""); err != nil {
+		t.Error(err)
+	} else if len(rts) != 1 || rts[0].ID != "RT_1CNT" {
+		t.Errorf("Unexpected rates: %s", utils.ToJSON(rts))
+	}
+	if _, err := NewRemoteXLSXStorage(utils.CSV_SEP, srv.URL+"/missing.xlsx", nil); err == nil ||
+		!strings.Contains(err.Error(), "404") {
+		t.Errorf("Expecting not found error, received: %v", err)
+	}
+}
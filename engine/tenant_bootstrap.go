@@ -0,0 +1,263 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// TenantBootstrap lists the IDs of the objects created for the new tenant
+type TenantBootstrap struct {
+	RatingProfiles  []string
+	DerivedChargers []string
+	ActionPlans     []string
+	CdrStats        []string
+}
+
+// NewTenantBootstrapper prepares copying into tenant the definitions of tmplTenant
+func NewTenantBootstrapper(dataDB DataDB, tmplTenant, tenant string) *TenantBootstrapper {
+	return &TenantBootstrapper{dataDB: dataDB, tmplTenant: tmplTenant, tenant: tenant,
+		queueIDs: make(map[string]string)}
+}
+
+// TenantBootstrapper copies the rating profiles, derived chargers, action plans and stats queues of a template tenant,
+// rewriting the template tenant out of their IDs
+type TenantBootstrapper struct {
+	dataDB     DataDB
+	tmplTenant string
+	tenant     string
+	queueIDs   map[string]string // template stats queue IDs towards the copies
+}
+
+// rewriteID replaces the template tenant in id, prefixing with the new tenant the IDs not containing it
+func (tb *TenantBootstrapper) rewriteID(id string) string {
+	if strings.Contains(id, tb.tmplTenant) {
+		return strings.Replace(id, tb.tmplTenant, tb.tenant, -1)
+	}
+	return tb.tenant + "_" + id
+}
+
+// rewriteKey replaces the tenant of a *direction:tenant:... key, leaving the other keys untouched
+func (tb *TenantBootstrapper) rewriteKey(key string) (string, bool) {
+	keySplt := strings.SplitN(key, utils.CONCATENATED_KEY_SEP, 3)
+	if len(keySplt) != 3 || keySplt[1] != tb.tmplTenant {
+		return key, false
+	}
+	return utils.ConcatenatedKey(keySplt[0], tb.tenant, keySplt[2]), true
+}
+
+// Bootstrap writes the copies, refusing with ErrExists to overwrite the objects of the new tenant unless overwrite is set
+func (tb *TenantBootstrapper) Bootstrap(overwrite bool) (tnb *TenantBootstrap, err error) {
+	if tb.tmplTenant == "" || tb.tenant == "" || tb.tmplTenant == tb.tenant {
+		return nil, utils.ErrInvalidTenant
+	}
+	tnb = new(TenantBootstrap)
+	cdrStats, err := tb.cdrStats() // first, the rating profiles referencing them
+	if err != nil {
+		return nil, err
+	}
+	rpfs, err := tb.ratingProfiles()
+	if err != nil {
+		return nil, err
+	}
+	dcKeys, dcs, err := tb.derivedChargers()
+	if err != nil {
+		return nil, err
+	}
+	apls, err := tb.actionPlans()
+	if err != nil {
+		return nil, err
+	}
+	if !overwrite {
+		for _, cs := range cdrStats {
+			if _, err := tb.dataDB.GetCdrStats(cs.Id); err == nil {
+				return nil, utils.ErrExists
+			}
+		}
+		for _, rpf := range rpfs {
+			if has, err := tb.dataDB.HasData(utils.RATING_PROFILE_PREFIX, rpf.Id); err != nil {
+				return nil, err
+			} else if has {
+				return nil, utils.ErrExists
+			}
+		}
+		for _, dcKey := range dcKeys {
+			if has, err := tb.dataDB.HasData(utils.DERIVEDCHARGERS_PREFIX, dcKey); err != nil {
+				return nil, err
+			} else if has {
+				return nil, utils.ErrExists
+			}
+		}
+		for _, apl := range apls {
+			if has, err := tb.dataDB.HasData(utils.ACTION_PLAN_PREFIX, apl.Id); err != nil {
+				return nil, err
+			} else if has {
+				return nil, utils.ErrExists
+			}
+		}
+	}
+	for _, cs := range cdrStats {
+		if err = tb.dataDB.SetCdrStats(cs); err != nil {
+			return nil, err
+		}
+		tnb.CdrStats = append(tnb.CdrStats, cs.Id)
+	}
+	for _, rpf := range rpfs {
+		if err = tb.dataDB.SetRatingProfile(rpf, utils.NonTransactional); err != nil {
+			return nil, err
+		}
+		tnb.RatingProfiles = append(tnb.RatingProfiles, rpf.Id)
+	}
+	for i, dcKey := range dcKeys {
+		if err = tb.dataDB.SetDerivedChargers(dcKey, dcs[i], utils.NonTransactional); err != nil {
+			return nil, err
+		}
+		tnb.DerivedChargers = append(tnb.DerivedChargers, dcKey)
+	}
+	for _, apl := range apls {
+		if err = tb.dataDB.SetActionPlan(apl.Id, apl, true, utils.NonTransactional); err != nil {
+			return nil, err
+		}
+		tnb.ActionPlans = append(tnb.ActionPlans, apl.Id)
+	}
+	return
+}
+
+// cdrStats copies the stats queues filtering on the template tenant
+func (tb *TenantBootstrapper) cdrStats() (copies []*CdrStats, err error) {
+	allCs, err := tb.dataDB.GetAllCdrStats()
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	for _, cs := range allCs {
+		if !utils.IsSliceMember(cs.Tenant, tb.tmplTenant) {
+			continue
+		}
+		cpy := *cs
+		cpy.Id = tb.rewriteID(cs.Id)
+		cpy.Tenant = []string{tb.tenant}
+		cpy.Triggers = make(ActionTriggers, len(cs.Triggers))
+		for i, at := range cs.Triggers {
+			cpy.Triggers[i] = at.Clone()
+		}
+		tb.queueIDs[cs.Id] = cpy.Id
+		copies = append(copies, &cpy)
+	}
+	return
+}
+
+// ratingProfiles copies the rating profiles of the template tenant, together with their fallbacks in the same tenant
+func (tb *TenantBootstrapper) ratingProfiles() (copies []*RatingProfile, err error) {
+	keys, err := tb.dataDB.GetKeysForPrefix(utils.RATING_PROFILE_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		rpfID, ok := tb.rewriteKey(key[len(utils.RATING_PROFILE_PREFIX):])
+		if !ok {
+			continue
+		}
+		rpf, err := tb.dataDB.GetRatingProfile(key[len(utils.RATING_PROFILE_PREFIX):], true, utils.NonTransactional)
+		if err != nil {
+			return nil, err
+		}
+		cpy := &RatingProfile{Id: rpfID, RatingPlanActivations: make(RatingPlanActivations, len(rpf.RatingPlanActivations))}
+		for i, rpa := range rpf.RatingPlanActivations {
+			rpaCpy := *rpa
+			rpaCpy.FallbackKeys = make([]string, len(rpa.FallbackKeys))
+			for j, fbKey := range rpa.FallbackKeys {
+				rpaCpy.FallbackKeys[j], _ = tb.rewriteKey(fbKey)
+			}
+			rpaCpy.CdrStatQueueIds = make([]string, len(rpa.CdrStatQueueIds))
+			for j, qID := range rpa.CdrStatQueueIds {
+				if cpyID, has := tb.queueIDs[qID]; has {
+					qID = cpyID
+				}
+				rpaCpy.CdrStatQueueIds[j] = qID
+			}
+			cpy.RatingPlanActivations[i] = &rpaCpy
+		}
+		copies = append(copies, cpy)
+	}
+	return
+}
+
+// derivedChargers copies the derived chargers of the template tenant, the static tenant fields pointing to the new tenant
+func (tb *TenantBootstrapper) derivedChargers() (keys []string, copies []*utils.DerivedChargers, err error) {
+	dcKeys, err := tb.dataDB.GetKeysForPrefix(utils.DERIVEDCHARGERS_PREFIX)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range dcKeys {
+		cpyKey, ok := tb.rewriteKey(key[len(utils.DERIVEDCHARGERS_PREFIX):])
+		if !ok {
+			continue
+		}
+		dcs, err := tb.dataDB.GetDerivedChargers(key[len(utils.DERIVEDCHARGERS_PREFIX):], true, utils.NonTransactional)
+		if err != nil {
+			return nil, nil, err
+		}
+		cpy := &utils.DerivedChargers{DestinationIDs: dcs.DestinationIDs.Clone(), Chargers: make([]*utils.DerivedCharger, len(dcs.Chargers))}
+		for i, dc := range dcs.Chargers {
+			tenantFld := dc.TenantField
+			if tenantFld == utils.STATIC_VALUE_PREFIX+tb.tmplTenant {
+				tenantFld = utils.STATIC_VALUE_PREFIX + tb.tenant
+			}
+			if cpy.Chargers[i], err = utils.NewDerivedCharger(dc.RunID, dc.RunFilters, dc.RequestTypeField, dc.DirectionField,
+				tenantFld, dc.CategoryField, dc.AccountField, dc.SubjectField, dc.DestinationField, dc.SetupTimeField,
+				dc.PDDField, dc.AnswerTimeField, dc.UsageField, dc.SupplierField, dc.DisconnectCauseField,
+				dc.RatedField, dc.CostField); err != nil {
+				return nil, nil, err
+			}
+			cpy.Chargers[i].SplitChargingID = dc.SplitChargingID
+		}
+		keys = append(keys, cpyKey)
+		copies = append(copies, cpy)
+	}
+	return
+}
+
+// actionPlans copies the action plans of the template tenant accounts or with the template tenant in their ID,
+// without accounts, attached to the ones of the new tenant once created
+func (tb *TenantBootstrapper) actionPlans() (copies []*ActionPlan, err error) {
+	apls, err := tb.dataDB.GetAllActionPlans()
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	for aplID, apl := range apls {
+		tmplPlan := strings.Contains(aplID, tb.tmplTenant)
+		for acntID := range apl.AccountIDs {
+			if tmplPlan {
+				break
+			}
+			tmplPlan = strings.HasPrefix(acntID, tb.tmplTenant+utils.CONCATENATED_KEY_SEP)
+		}
+		if !tmplPlan {
+			continue
+		}
+		cpy := &ActionPlan{Id: tb.rewriteID(aplID), AccountIDs: make(utils.StringMap)}
+		for _, at := range apl.ActionTimings {
+			cpy.ActionTimings = append(cpy.ActionTimings, &ActionTiming{Uuid: utils.GenUUID(), Timing: at.Timing,
+				ActionsID: at.ActionsID, Weight: at.Weight, CatchUp: at.CatchUp})
+		}
+		copies = append(copies, cpy)
+	}
+	return
+}
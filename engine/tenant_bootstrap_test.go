@@ -0,0 +1,88 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestTenantBootstrap(t *testing.T) {
+	ms, _ := NewMapStorage()
+	ms.SetCdrStats(&CdrStats{Id: "tmpl_ASR", Tenant: []string{"tmpl"}, Metrics: []string{ASR}})
+	ms.SetCdrStats(&CdrStats{Id: "OTHER_ASR", Tenant: []string{"other"}})
+	ms.SetRatingProfile(&RatingProfile{Id: "*out:tmpl:call:*any", RatingPlanActivations: RatingPlanActivations{
+		&RatingPlanActivation{RatingPlanId: "RP_RETAIL", FallbackKeys: []string{"*out:tmpl:call:fallback"},
+			CdrStatQueueIds: []string{"tmpl_ASR"}}}}, utils.NonTransactional)
+	ms.SetRatingProfile(&RatingProfile{Id: "*out:other:call:*any", RatingPlanActivations: RatingPlanActivations{
+		&RatingPlanActivation{RatingPlanId: "RP_OTHER"}}}, utils.NonTransactional)
+	dc, _ := utils.NewDerivedCharger("reseller", "", "", "", "^tmpl", "", "", "", "", "", "", "", "", "", "", "", "")
+	ms.SetDerivedChargers("*out:tmpl:call:*any:*any", &utils.DerivedChargers{Chargers: []*utils.DerivedCharger{dc}}, utils.NonTransactional)
+	ms.SetActionPlan("MONTHLY_FEE", &ActionPlan{Id: "MONTHLY_FEE", AccountIDs: utils.StringMap{"tmpl:1001": true},
+		ActionTimings: []*ActionTiming{&ActionTiming{Uuid: "at1", ActionsID: "FEE", Weight: 10}}}, true, utils.NonTransactional)
+	ms.SetActionPlan("OTHER_PLAN", &ActionPlan{Id: "OTHER_PLAN", AccountIDs: utils.StringMap{"other:1001": true}}, true, utils.NonTransactional)
+	tnb, err := NewTenantBootstrapper(ms, "tmpl", "reseller1").Bootstrap(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eTnb := &TenantBootstrap{RatingProfiles: []string{"*out:reseller1:call:*any"}, DerivedChargers: []string{"*out:reseller1:call:*any:*any"},
+		ActionPlans: []string{"reseller1_MONTHLY_FEE"}, CdrStats: []string{"reseller1_ASR"}}
+	if !reflect.DeepEqual(eTnb, tnb) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eTnb), utils.ToJSON(tnb))
+	}
+	if rpf, err := ms.GetRatingProfile("*out:reseller1:call:*any", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if rpa := rpf.RatingPlanActivations[0]; rpa.RatingPlanId != "RP_RETAIL" ||
+		!reflect.DeepEqual(rpa.FallbackKeys, []string{"*out:reseller1:call:fallback"}) || !reflect.DeepEqual(rpa.CdrStatQueueIds, []string{"reseller1_ASR"}) {
+		t.Errorf("Unexpected rating profile: %s", utils.ToJSON(rpf))
+	}
+	if dcs, err := ms.GetDerivedChargers("*out:reseller1:call:*any:*any", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if dcs.Chargers[0].RunID != "reseller" || dcs.Chargers[0].TenantField != "^reseller1" {
+		t.Errorf("Unexpected derived chargers: %s", utils.ToJSON(dcs))
+	}
+	if apl, err := ms.GetActionPlan("reseller1_MONTHLY_FEE", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if len(apl.AccountIDs) != 0 || len(apl.ActionTimings) != 1 || apl.ActionTimings[0].ActionsID != "FEE" ||
+		apl.ActionTimings[0].Uuid == "at1" {
+		t.Errorf("Unexpected action plan: %s", utils.ToJSON(apl))
+	}
+	if cs, err := ms.GetCdrStats("reseller1_ASR"); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(cs.Tenant, []string{"reseller1"}) || !reflect.DeepEqual(cs.Metrics, []string{ASR}) {
+		t.Errorf("Unexpected stats queue: %s", utils.ToJSON(cs))
+	}
+	if _, err := NewTenantBootstrapper(ms, "tmpl", "reseller1").Bootstrap(false); err != utils.ErrExists {
+		t.Errorf("Expecting ErrExists, received: %v", err)
+	}
+	if _, err := NewTenantBootstrapper(ms, "tmpl", "reseller1").Bootstrap(true); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewTenantBootstrapper(ms, "tmpl", "tmpl").Bootstrap(false); err != utils.ErrInvalidTenant {
+		t.Errorf("Expecting ErrInvalidTenant, received: %v", err)
+	}
+	keys, _ := ms.GetKeysForPrefix(utils.RATING_PROFILE_PREFIX)
+	sort.Strings(keys)
+	if eKeys := []string{utils.RATING_PROFILE_PREFIX + "*out:other:call:*any", utils.RATING_PROFILE_PREFIX + "*out:reseller1:call:*any",
+		utils.RATING_PROFILE_PREFIX + "*out:tmpl:call:*any"}; !reflect.DeepEqual(eKeys, keys) {
+		t.Errorf("Expecting: %v, received: %v", eKeys, keys)
+	}
+}
@@ -78,6 +78,7 @@ type MonetaryInfo struct {
 	ID           string
 	Value        float64
 	RateInterval *RateInterval
+	ExchangeRate float64 // converted the cost towards the currency of the balance, 0 if not converted
 }
 
 func (mi *MonetaryInfo) Clone() *MonetaryInfo {
@@ -93,9 +94,18 @@ func (mi *MonetaryInfo) Equal(other *MonetaryInfo) bool {
 		return false
 	}
 	return mi.UUID == other.UUID &&
+		mi.ExchangeRate == other.ExchangeRate &&
 		reflect.DeepEqual(mi.RateInterval, other.RateInterval)
 }
 
+// balanceAmount returns the cost as debited out of the balance, in its currency
+func (mi *MonetaryInfo) balanceAmount(cost float64) float64 {
+	if mi.ExchangeRate == 0 {
+		return cost
+	}
+	return cost * mi.ExchangeRate
+}
+
 type UnitInfo struct {
 	UUID          string
 	ID            string
@@ -302,7 +312,9 @@ func (incr *Increment) GetCompressFactor() int {
 }
 
 func (incr *Increment) GetCost() float64 {
-	return float64(incr.GetCompressFactor()) * incr.Cost
+	var cost utils.DecimalSum
+	cost.AddMul(incr.Cost, int64(incr.GetCompressFactor()))
+	return cost.Float64()
 }
 
 type Increments []*Increment
@@ -385,11 +397,11 @@ func (incs Increments) SharingSignature(other Increments) bool {
 }
 
 func (incs Increments) GetTotalCost() float64 {
-	cost := 0.0
+	var cost utils.DecimalSum
 	for _, increment := range incs {
-		cost += increment.GetCost()
+		cost.AddMul(increment.Cost, int64(increment.GetCompressFactor()))
 	}
-	return utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	return utils.Round(cost.Float64(), globalRoundingDecimals, utils.ROUNDING_MIDDLE)
 }
 
 func (incs Increments) Length() (length int) {
@@ -437,7 +449,9 @@ func (ts *TimeSpan) CalculateCost() float64 {
 		}
 		return ts.RateInterval.GetCost(ts.GetDuration(), ts.GetGroupStart())
 	} else {
-		return ts.Increments.GetTotalCost() * float64(ts.GetCompressFactor())
+		var cost utils.DecimalSum
+		cost.AddMul(ts.Increments.GetTotalCost(), int64(ts.GetCompressFactor()))
+		return cost.Float64()
 	}
 }
 
@@ -449,6 +463,24 @@ func (ts *TimeSpan) setRatingInfo(rp *RatingInfo) {
 	ts.RatingPlanId = rp.RatingPlanId
 }
 
+// ratingVariant returns the A/B tested rating plan the timespans were rated with, if any
+func (tss TimeSpans) ratingVariant() string {
+	for _, ts := range tss {
+		if ts.ratingInfo != nil && ts.ratingInfo.RatingVariant != "" {
+			return ts.ratingInfo.RatingVariant
+		}
+	}
+	return ""
+}
+
+// getMaxCost returns the cap of the rating profile if any, otherwise the one of the rate interval
+func (ts *TimeSpan) getMaxCost() (float64, string) {
+	if ts.ratingInfo != nil && ts.ratingInfo.MaxCostStrategy != "" {
+		return ts.ratingInfo.MaxCost, ts.ratingInfo.MaxCostStrategy
+	}
+	return ts.RateInterval.GetMaxCost()
+}
+
 func (ts *TimeSpan) createIncrementsSlice() {
 	if ts.RateInterval == nil {
 		return
@@ -504,11 +536,13 @@ func (ts *TimeSpan) SplitByRateInterval(i *RateInterval, data bool) (nts *TimeSp
 	// split by GroupStart
 	if i.Rating != nil {
 		i.Rating.Rates.Sort()
+		grpStart := ts.groupStart(i)
+		grpEnd := ts.DurationIndex + ts.tierOffset(i)
 		for _, rate := range i.Rating.Rates {
-			if ts.GetGroupStart() < rate.GroupIntervalStart && ts.GetGroupEnd() > rate.GroupIntervalStart {
+			if grpStart < rate.GroupIntervalStart && grpEnd > rate.GroupIntervalStart {
 				//log.Print("Splitting")
 				ts.SetRateInterval(i)
-				splitTime := ts.TimeStart.Add(rate.GroupIntervalStart - ts.GetGroupStart())
+				splitTime := ts.TimeStart.Add(rate.GroupIntervalStart - grpStart)
 				nts = &TimeSpan{
 					TimeStart: splitTime,
 					TimeEnd:   ts.TimeEnd,
@@ -667,15 +701,29 @@ func (ts *TimeSpan) SplitByDay() (newTs *TimeSpan) {
 
 // Returns the starting time of this timespan
 func (ts *TimeSpan) GetGroupStart() time.Duration {
+	return ts.groupStart(ts.RateInterval)
+}
+
+func (ts *TimeSpan) GetGroupEnd() time.Duration {
+	return ts.DurationIndex + ts.tierOffset(ts.RateInterval)
+}
+
+// groupStart returns the group position of the timespan within the rate groups of ri
+func (ts *TimeSpan) groupStart(ri *RateInterval) time.Duration {
 	s := ts.DurationIndex - ts.GetDuration()
 	if s < 0 {
 		s = 0
 	}
-	return s
+	return s + ts.tierOffset(ri)
 }
 
-func (ts *TimeSpan) GetGroupEnd() time.Duration {
-	return ts.DurationIndex
+// tierOffset returns the usage already counted in the billing period for tiered ratings
+func (ts *TimeSpan) tierOffset(ri *RateInterval) time.Duration {
+	if ri == nil || ri.Rating == nil || ri.Rating.TierPeriod == "" ||
+		ts.ratingInfo == nil || ts.ratingInfo.tierUsage == nil {
+		return 0
+	}
+	return ts.ratingInfo.tierUsage[ri.Rating.tierKey()]
 }
 
 // sets the DurationIndex attribute to reflect new timespan
@@ -754,7 +802,7 @@ func (ts *TimeSpan) hasBetterRateIntervalThan(interval *RateInterval) bool {
 		return false
 	}
 	ownPrice, _, _ := ts.RateInterval.GetRateParameters(ts.GetGroupStart())
-	otherPrice, _, _ := interval.GetRateParameters(ts.GetGroupStart())
+	otherPrice, _, _ := interval.GetRateParameters(ts.groupStart(interval))
 	// if own price is smaller than it's better
 	if ownPrice < otherPrice {
 		return true
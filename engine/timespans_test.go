@@ -1969,3 +1969,13 @@ func TestTSMerge(t *testing.T) {
 		t.Errorf("Expecting: %+v, received: %+v", eMergedTSS, tss1)
 	}
 }
+
+func TestIncrementsGetTotalCostOverflow(t *testing.T) {
+	incs := Increments{
+		&Increment{Cost: 1e10, CompressFactor: 2},
+		&Increment{Cost: 0.1, CompressFactor: 1},
+	}
+	if cost := incs.GetTotalCost(); cost != 2e10+0.1 {
+		t.Errorf("Expecting %v, received: %v", 2e10+0.1, cost)
+	}
+}
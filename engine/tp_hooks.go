@@ -0,0 +1,100 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	TPHookAfterLoad  = "*after_load"  // run by LoadCategories once the category was loaded
+	TPHookAfterWrite = "*after_write" // run by WriteToDatabase once the loaded data was written
+)
+
+// TpReaderHook allows custom validation or enrichment of the loaded data, an error aborts the load
+type TpReaderHook func(tpr *TpReader, category string) error
+
+type tpHookKey struct {
+	stage, category string
+}
+
+type tpHooks map[tpHookKey][]TpReaderHook
+
+func (hks tpHooks) add(stage, category string, hook TpReaderHook) error {
+	if stage != TPHookAfterLoad && stage != TPHookAfterWrite {
+		return fmt.Errorf("unsupported hook stage: %s", stage)
+	}
+	if _, has := utils.NewStringMap(TPLoadCategories...)[category]; !has {
+		return fmt.Errorf("unsupported hook category: %s", category)
+	}
+	key := tpHookKey{stage, category}
+	hks[key] = append(hks[key], hook)
+	return nil
+}
+
+var (
+	globalTpHooks    = make(tpHooks)
+	globalTpHooksMux sync.RWMutex
+)
+
+// RegisterTpReaderHook adds a hook executed by all the TpReaders, before their own ones
+func RegisterTpReaderHook(stage, category string, hook TpReaderHook) error {
+	globalTpHooksMux.Lock()
+	defer globalTpHooksMux.Unlock()
+	return globalTpHooks.add(stage, category, hook)
+}
+
+// RegisterHook adds a hook executed by this TpReader only, hooks run in their registration order
+func (tpr *TpReader) RegisterHook(stage, category string, hook TpReaderHook) error {
+	if tpr.hooks == nil {
+		tpr.hooks = make(tpHooks)
+	}
+	return tpr.hooks.add(stage, category, hook)
+}
+
+// runHooks executes the hooks registered for the stage and category
+func (tpr *TpReader) runHooks(stage, category string) error {
+	key := tpHookKey{stage, category}
+	globalTpHooksMux.RLock()
+	hooks := append(append([]TpReaderHook{}, globalTpHooks[key]...), tpr.hooks[key]...)
+	globalTpHooksMux.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(tpr, category); err != nil {
+			return fmt.Errorf("%s hook for %s: %v", stage, category, err)
+		}
+	}
+	return nil
+}
+
+// DataDB returns the storage the reader writes into
+func (tpr *TpReader) DataDB() DataDB {
+	return tpr.dataStorage
+}
+
+// LoadedDestinations returns the destinations loaded so far, indexed on their ID
+func (tpr *TpReader) LoadedDestinations() map[string]*Destination {
+	return tpr.destinations
+}
+
+// AddAlias queues an alias to be written together with the loaded ones
+func (tpr *TpReader) AddAlias(al *Alias) {
+	tpr.aliases[al.GetId()] = al
+}
@@ -0,0 +1,134 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// Lint reports the objects of the tariff plan which are defined but never referenced:
+// rates out of destination rates, destination rates out of rating plans, timings out of rating and action plans,
+// actions out of action plans and triggers, destinations out of used destination rates, LCRs and balance filters
+func (tpr *TpReader) Lint() (issues []string, err error) {
+	tpTimings, err := tpr.lr.GetTPTimings(tpr.tpid, "")
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpDsts, err := tpr.lr.GetTPDestinations(tpr.tpid, "")
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpRates, err := tpr.lr.GetTPRates(tpr.tpid, "")
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpDrs, err := tpr.lr.GetTPDestinationRates(tpr.tpid, "", nil)
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpRpls, err := tpr.lr.GetTPRatingPlans(tpr.tpid, "", nil)
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpLcrs, err := tpr.lr.GetTPLCRs(&utils.TPLcrRules{TPid: tpr.tpid})
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpActs, err := tpr.lr.GetTPActions(tpr.tpid, "")
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpAPls, err := tpr.lr.GetTPActionPlans(tpr.tpid, "")
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	tpATrs, err := tpr.lr.GetTPActionTriggers(tpr.tpid, "")
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	usedTimings, usedDrs, usedActs, usedDsts := make(utils.StringMap), make(utils.StringMap), make(utils.StringMap), make(utils.StringMap)
+	useDsts := func(dstIDs string) {
+		for _, dstID := range strings.Split(dstIDs, utils.INFIELD_SEP) {
+			usedDsts[strings.TrimPrefix(dstID, "!")] = true // negative filters are references as well
+		}
+	}
+	for _, tpRpl := range tpRpls {
+		for _, rplBnd := range tpRpl.RatingPlanBindings {
+			usedDrs[rplBnd.DestinationRatesId] = true
+			usedTimings[rplBnd.TimingId] = true
+		}
+	}
+	usedRates := make(utils.StringMap)
+	for _, tpDr := range tpDrs {
+		if !usedDrs[tpDr.ID] {
+			issues = append(issues, fmt.Sprintf("DestinationRate <%s> not referenced by any RatingPlan", tpDr.ID))
+		}
+		for _, dr := range tpDr.DestinationRates {
+			usedRates[dr.RateId] = true
+			if usedDrs[tpDr.ID] {
+				usedDsts[dr.DestinationId] = true
+			}
+		}
+	}
+	for _, tpRate := range tpRates {
+		if !usedRates[tpRate.ID] {
+			issues = append(issues, fmt.Sprintf("Rate <%s> not referenced by any DestinationRate", tpRate.ID))
+		}
+	}
+	for _, tpLcr := range tpLcrs {
+		for _, rule := range tpLcr.Rules {
+			useDsts(rule.DestinationId)
+		}
+	}
+	for _, tpAPl := range tpAPls {
+		for _, at := range tpAPl.ActionPlan {
+			usedActs[at.ActionsId] = true
+			usedTimings[at.TimingId] = true
+		}
+	}
+	for _, tpATr := range tpATrs {
+		for _, atr := range tpATr.ActionTriggers {
+			usedActs[atr.ActionsId] = true
+			useDsts(atr.BalanceDestinationIds)
+		}
+	}
+	for _, tpAct := range tpActs {
+		if !usedActs[tpAct.ID] {
+			issues = append(issues, fmt.Sprintf("Actions <%s> not attached to any ActionPlan or ActionTrigger", tpAct.ID))
+		}
+		for _, act := range tpAct.Actions {
+			useDsts(act.DestinationIds)
+		}
+	}
+	for _, tpTiming := range tpTimings {
+		if !usedTimings[tpTiming.ID] {
+			issues = append(issues, fmt.Sprintf("Timing <%s> not used by any RatingPlan or ActionPlan", tpTiming.ID))
+		}
+	}
+	for _, tpDst := range tpDsts {
+		if !usedDsts[tpDst.ID] {
+			issues = append(issues, fmt.Sprintf("Destination <%s> not used by any RatingPlan, LCR or balance filter", tpDst.ID))
+		}
+	}
+	sort.Strings(issues)
+	return issues, nil
+}
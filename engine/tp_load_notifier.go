@@ -0,0 +1,166 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+var (
+	tpLoadNotifier   TPLoadNotifier
+	tpLoadInstanceID string
+)
+
+// SetTPLoadNotifier sets the notifier of the tariff plan loads, instanceID identifying this engine in the events, nil disables notifications
+func SetTPLoadNotifier(tln TPLoadNotifier, instanceID string) {
+	tpLoadNotifier = tln
+	tpLoadInstanceID = instanceID
+}
+
+// TPLoadNotificationsEnabled is used to skip computing the diff when nobody listens
+func TPLoadNotificationsEnabled() bool {
+	return tpLoadNotifier != nil
+}
+
+// TPLoadEvent is published when a tariff plan load starts, succeeds or fails
+type TPLoadEvent struct {
+	Event      string // <*tp_load_started|*tp_load_succeeded|*tp_load_failed>
+	InstanceID string // engine the tariff plan was loaded into
+	Source     string // TPid or folder the tariff plan was loaded out of
+	Error      string // populated on *tp_load_failed
+	Diff       TPLoadDiff
+	Time       time.Time
+}
+
+// TPLoadDiff summarizes the changes brought by a load, keyed by load category, eg: *rating_plans
+type TPLoadDiff map[string]*TPLoadDiffEntry
+
+// TPLoadDiffEntry counts the objects written for one category
+type TPLoadDiffEntry struct {
+	Loaded  int // objects written
+	Added   int // objects missing before the load, only for categories where DataDB can tell
+	Updated int // objects overwritten by the load, only for categories where DataDB can tell
+}
+
+// TPLoadNotifier publishes the tariff plan load events towards external systems
+type TPLoadNotifier interface {
+	NotifyTPLoad(*TPLoadEvent) error
+}
+
+// NewTPLoadPoster creates a TPLoadNotifier posting the events as JSON towards each of the addresses
+func NewTPLoadPoster(addresses []string, attempts int, fallbackDir string, httpPoster *utils.HTTPPoster) *TPLoadPoster {
+	tlp := &TPLoadPoster{posters: make([]*jsonPoster, len(addresses))}
+	for i, addr := range addresses {
+		tlp.posters[i] = newJSONPoster(addr, attempts, fallbackDir, httpPoster)
+	}
+	return tlp
+}
+
+// TPLoadPoster is the TPLoadNotifier implementation based on the CDR export posters
+type TPLoadPoster struct {
+	posters []*jsonPoster
+}
+
+// NotifyTPLoad posts to all addresses, returning the last error met
+func (tlp *TPLoadPoster) NotifyTPLoad(ev *TPLoadEvent) (err error) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	for _, jp := range tlp.posters {
+		if errPost := jp.post(utils.TPLoadsPoster, body); errPost != nil {
+			err = fmt.Errorf("%s: %s", jp.address, errPost.Error())
+		}
+	}
+	return
+}
+
+// NotifyTPLoad publishes asynchronously the event of the load out of source, loadErr being considered for *tp_load_failed
+func NotifyTPLoad(event, source string, diff TPLoadDiff, loadErr error) {
+	if tpLoadNotifier == nil {
+		return
+	}
+	ev := &TPLoadEvent{Event: event, InstanceID: tpLoadInstanceID, Source: source, Diff: diff, Time: time.Now()}
+	if loadErr != nil {
+		ev.Error = loadErr.Error()
+	}
+	go func() {
+		if err := tpLoadNotifier.NotifyTPLoad(ev); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<TPLoadNotifier> Failed notifying %s for <%s>, error: %s", ev.Event, ev.Source, err.Error()))
+		}
+	}()
+}
+
+// NotifyTPLoadDone publishes *tp_load_succeeded or, for loadErr not nil, *tp_load_failed
+func NotifyTPLoadDone(source string, diff TPLoadDiff, loadErr error) {
+	if loadErr != nil {
+		NotifyTPLoad(utils.MetaTPLoadFailed, source, diff, loadErr)
+	} else {
+		NotifyTPLoad(utils.MetaTPLoadSucceeded, source, diff, nil)
+	}
+}
+
+// tpLoadDiffPrefixes maps the load categories to the DataDB prefixes of the objects they write
+var tpLoadDiffPrefixes = map[string]string{
+	utils.MetaDestinations:     utils.DESTINATION_PREFIX,
+	utils.MetaRatingPlans:      utils.RATING_PLAN_PREFIX,
+	utils.MetaRatingProfiles:   utils.RATING_PROFILE_PREFIX,
+	utils.MetaSharedGroups:     utils.SHARED_GROUP_PREFIX,
+	utils.MetaLCRs:             utils.LCR_PREFIX,
+	utils.MetaActions:          utils.ACTION_PREFIX,
+	utils.MetaActionPlans:      utils.ACTION_PLAN_PREFIX,
+	utils.MetaActionTriggers:   utils.ACTION_TRIGGER_PREFIX,
+	utils.MetaDerivedChargers:  utils.DERIVEDCHARGERS_PREFIX,
+	utils.MetaCdrStats:         utils.CDR_STATS_PREFIX,
+	utils.MetaUsers:            utils.USERS_PREFIX,
+	utils.MetaAliases:          utils.ALIASES_PREFIX,
+	utils.MetaResourceLimits:   utils.ResourceLimitsPrefix,
+	utils.MetaExchangeRates:    utils.ExchangeRatesPrefix,
+	utils.MetaHolidayCalendars: utils.HolidayCalendarsPrefix,
+	utils.MetaDialPlans:        utils.DialPlansPrefix,
+}
+
+// Diff compares the loaded data with the one in DataDB, needs to be called before WriteToDatabase
+func (tpr *TpReader) Diff() (diff TPLoadDiff) {
+	diff = make(TPLoadDiff)
+	for categ, prefix := range tpLoadDiffPrefixes {
+		ids, _ := tpr.GetLoadedIds(prefix)
+		if len(ids) == 0 {
+			continue
+		}
+		entry := &TPLoadDiffEntry{Loaded: len(ids)}
+		for _, id := range ids {
+			has, err := tpr.dataStorage.HasData(prefix, id)
+			if err != nil { // category not supported by HasData
+				entry.Added, entry.Updated = 0, 0
+				break
+			}
+			if has {
+				entry.Updated++
+			} else {
+				entry.Added++
+			}
+		}
+		diff[categ] = entry
+	}
+	return
+}
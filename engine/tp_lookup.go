@@ -0,0 +1,214 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// maximum number of bindings processed in parallel by the filtered loads
+const filteredLoadWorkers = 8
+
+// tpLookup shares the prerequisite TP objects between the bindings of one filtered load,
+// each of them being queried out of StorDB at most once per invocation
+type tpLookup struct {
+	tpr     *TpReader
+	sf      utils.SingleFlight // coalesces the queries in progress
+	mux     sync.RWMutex
+	results map[string]*tpLookupResult
+}
+
+// tpLookupResult is the outcome of querying one TP object
+type tpLookupResult struct {
+	val interface{}
+	err error
+}
+
+func newTpLookup(tpr *TpReader) *tpLookup {
+	return &tpLookup{tpr: tpr, results: make(map[string]*tpLookupResult)}
+}
+
+// result returns the stored outcome of fetching key, nil if not fetched yet
+func (lk *tpLookup) result(key string) *tpLookupResult {
+	lk.mux.RLock()
+	defer lk.mux.RUnlock()
+	return lk.results[key]
+}
+
+// get returns the result of fetch for key, calling it only for the first caller
+func (lk *tpLookup) get(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if res := lk.result(key); res != nil {
+		return res.val, res.err
+	}
+	return lk.sf.Do(key, func() (interface{}, error) {
+		if res := lk.result(key); res != nil { // finished after our check above
+			return res.val, res.err
+		}
+		val, err := fetch()
+		lk.mux.Lock()
+		lk.results[key] = &tpLookupResult{val: val, err: err}
+		lk.mux.Unlock()
+		return val, err
+	})
+}
+
+func (lk *tpLookup) timing(id string) (*utils.TPTiming, error) {
+	tm, err := lk.get(utils.ConcatenatedKey(utils.TBLTPTimings, id), func() (interface{}, error) {
+		tptm, err := lk.tpr.lr.GetTPTimings(lk.tpr.tpid, id)
+		if err != nil {
+			return nil, fmt.Errorf("no timing with id %s: %v", id, err)
+		}
+		var tm *utils.TPTiming
+		if len(tptm) != 0 {
+			tms, err := MapTPTimings(tptm)
+			if err != nil {
+				return nil, err
+			}
+			tm = tms[id]
+		}
+		if tm == nil {
+			tm = lk.tpr.timings[id] // predefined ones, ie: *any
+		}
+		if tm == nil {
+			return nil, fmt.Errorf("no timing with id %s", id)
+		}
+		return tm, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tm.(*utils.TPTiming), nil
+}
+
+func (lk *tpLookup) destinationRate(id string) (*utils.TPDestinationRate, error) {
+	dr, err := lk.get(utils.ConcatenatedKey(utils.TBLTPDestinationRates, id), func() (interface{}, error) {
+		tpdrm, err := lk.tpr.lr.GetTPDestinationRates(lk.tpr.tpid, id, nil)
+		if err != nil || len(tpdrm) == 0 {
+			return nil, fmt.Errorf("no DestinationRates profile with id %s: %v", id, err)
+		}
+		drm, err := MapTPDestinationRates(tpdrm)
+		if err != nil {
+			return nil, err
+		}
+		dr, has := drm[id]
+		if !has {
+			return nil, fmt.Errorf("no DestinationRates profile with id %s", id)
+		}
+		return dr, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dr.(*utils.TPDestinationRate), nil
+}
+
+func (lk *tpLookup) rate(id string) (*utils.TPRate, error) {
+	rt, err := lk.get(utils.ConcatenatedKey(utils.TBLTPRates, id), func() (interface{}, error) {
+		tprt, err := lk.tpr.lr.GetTPRates(lk.tpr.tpid, id)
+		if err != nil || len(tprt) == 0 {
+			return nil, fmt.Errorf("no Rates profile with id %s: %v", id, err)
+		}
+		rts, err := MapTPRates(tprt)
+		if err != nil {
+			return nil, err
+		}
+		rt, has := rts[id]
+		if !has {
+			return nil, fmt.Errorf("no Rates profile with id %s", id)
+		}
+		return rt, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rt.(*utils.TPRate), nil
+}
+
+// destination returns the destinations defined in StorDB with id, none if they should be taken from DataDB
+func (lk *tpLookup) destination(id string) ([]*Destination, error) {
+	dms, err := lk.get(utils.ConcatenatedKey(utils.TBLTPDestinations, id), func() (interface{}, error) {
+		tpDests, err := lk.tpr.lr.GetTPDestinations(lk.tpr.tpid, id)
+		if err != nil {
+			return nil, err
+		}
+		dms := make([]*Destination, 0)
+		for _, tpDst := range tpDests {
+			if tpDst.ID == id {
+				dms = append(dms, NewDestinationFromTPDestination(tpDst))
+			}
+		}
+		if len(dms) == 0 {
+			if lk.tpr.dataStorage == nil {
+				return nil, fmt.Errorf("could not get destination for tag %v", id)
+			}
+			if _, err = lk.tpr.dataStorage.HasData(utils.DESTINATION_PREFIX, id); err != nil {
+				return nil, err
+			}
+		}
+		return dms, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dms.([]*Destination), nil
+}
+
+// tpActions returns the actions with id, indexed on it
+func (lk *tpLookup) tpActions(id string) (map[string][]*utils.TPAction, error) {
+	acts, err := lk.get(utils.ConcatenatedKey(utils.TBLTPActions, id), func() (interface{}, error) {
+		tpas, err := lk.tpr.lr.GetTPActions(lk.tpr.tpid, id)
+		if err != nil {
+			return nil, err
+		}
+		return MapTPActions(tpas)[id], nil // some LoadReaders, eg: CSV, do not filter on id
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(acts.([]*utils.TPAction)) == 0 {
+		return map[string][]*utils.TPAction{}, nil
+	}
+	return map[string][]*utils.TPAction{id: acts.([]*utils.TPAction)}, nil
+}
+
+// processConcurrently calls process for the n items using at most filteredLoadWorkers goroutines,
+// returning the first error encountered
+func processConcurrently(n int, process func(i int) error) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	workers := make(chan struct{}, filteredLoadWorkers)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			if err := process(i); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
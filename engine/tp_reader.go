@@ -23,15 +23,19 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/guardian"
 	"github.com/cgrates/cgrates/structmatcher"
 	"github.com/cgrates/cgrates/utils"
+	"github.com/cgrates/rpcclient"
 )
 
 type TpReader struct {
 	tpid             string
 	timezone         string
+	tenantTimezones  map[string]string // timezone overrides per tenant
 	dataStorage      DataDB
 	lr               LoadReader
 	actions          map[string][]*Action
@@ -40,6 +44,7 @@ type TpReader struct {
 	accountActions   map[string]*Account
 	dirtyRpAliases   []*TenantRatingSubject // used to clean aliases that might have changed
 	dirtyAccAliases  []*TenantAccount       // used to clean aliases that might have changed
+	riPool           *RateIntervalPool      // shares identical rate intervals between the loaded rating plans
 	destinations     map[string]*Destination
 	timings          map[string]*utils.TPTiming
 	rates            map[string]*utils.TPRate
@@ -53,9 +58,18 @@ type TpReader struct {
 	users            map[string]*UserProfile
 	aliases          map[string]*Alias
 	resLimits        map[string]*utils.TPResourceLimit
+	exchangeRates    map[string]*utils.TPExchangeRate
+	holidayCalendars map[string]*utils.TPHolidayCalendar
+	dialPlans        map[string]*utils.TPDialPlan
 	revDests,
 	revAliases,
 	acntActionPlans map[string][]string
+	danglingRefs []string                      // references towards missing objects, reported by CheckIntegrity
+	reloadCache  bool                          // refresh the cache for the keys written by WriteToDatabase
+	cacheConn    rpcclient.RpcClientConnection // rater owning the cache, local cache package if nil
+	compressDsts bool                          // collapse the redundant destination prefixes on load
+	noReverses   utils.StringMap               // reverse indexes not rebuilt by WriteToDatabase
+	hooks        tpHooks                       // custom processing registered per category
 }
 
 func NewTpReader(db DataDB, lr LoadReader, tpid, timezone string) *TpReader {
@@ -66,6 +80,116 @@ func NewTpReader(db DataDB, lr LoadReader, tpid, timezone string) *TpReader {
 		lr:          lr,
 	}
 	tpr.Init()
+	return tpr
+}
+
+// NewMergedTpReader loads tpids in one pass, base TPs first followed by their overlays,
+// objects defined in more than one TP are resolved according to policy <*error|*first|*last>
+func NewMergedTpReader(db DataDB, lr LoadReader, tpids []string, policy, timezone string) (*TpReader, error) {
+	mlr, err := NewMergedLoadReader(lr, tpids, policy)
+	if err != nil {
+		return nil, err
+	}
+	return NewTpReader(db, mlr, strings.Join(tpids, utils.INFIELD_SEP), timezone), nil
+}
+
+// SetCompressDestinations enables collapsing the redundant prefixes within LoadDestinations
+func (tpr *TpReader) SetCompressDestinations(compress bool) {
+	tpr.compressDsts = compress
+}
+
+// SetDisabledReverses selects the reverse indexes which WriteToDatabase will not rebuild
+// <*destinations|*account_action_plans|*aliases>
+func (tpr *TpReader) SetDisabledReverses(categs []string) error {
+	for _, categ := range categs {
+		if !utils.IsSliceMember([]string{utils.MetaDestinations, utils.MetaAccountActionPlans, utils.MetaAliases}, categ) {
+			return fmt.Errorf("unsupported reverse category: %s", categ)
+		}
+	}
+	tpr.noReverses = utils.NewStringMap(categs...)
+	return nil
+}
+
+// SetTenantTimezones overrides the default timezone of the reader for the tenants in tzs
+func (tpr *TpReader) SetTenantTimezones(tzs map[string]string) {
+	tpr.tenantTimezones = tzs
+}
+
+// tenantTimezone returns the timezone used to parse the timestamps belonging to tenant
+func (tpr *TpReader) tenantTimezone(tenant string) string {
+	if tz, has := tpr.tenantTimezones[tenant]; has {
+		return tz
+	}
+	return tpr.timezone
+}
+
+// parseActivationTime accepts the utils.ParseDate formats and falls back on layout detection within the tenant timezone,
+// explicit timezones are accepted as suffix, eg: 2017-03-26 03:00:00[Europe/Berlin]
+func (tpr *TpReader) parseActivationTime(actTime, tenant string) (time.Time, error) {
+	if at, err := utils.ParseDate(actTime); err == nil {
+		return at, nil
+	}
+	return utils.ParseTimeDetectLayoutStrict(actTime, tpr.tenantTimezone(tenant))
+}
+
+// actionTriggersTimezones returns the timezone of the tenant owning each action triggers profile
+// Profiles shared between tenants keep the default timezone
+func (tpr *TpReader) actionTriggersTimezones() (map[string]string, error) {
+	atrsTZ := make(map[string]string)
+	if len(tpr.tenantTimezones) == 0 {
+		return atrsTZ, nil
+	}
+	tpAAs, err := tpr.lr.GetTPAccountActions(&utils.TPAccountActions{TPid: tpr.tpid})
+	if err != nil && err != utils.ErrNotFound {
+		return nil, err
+	}
+	atrsTenant := make(map[string]string)
+	for _, tpAA := range tpAAs {
+		if tpAA.ActionTriggersId == "" {
+			continue
+		}
+		if tenant, has := atrsTenant[tpAA.ActionTriggersId]; has && tenant != tpAA.Tenant {
+			atrsTenant[tpAA.ActionTriggersId] = "" // shared
+			continue
+		}
+		atrsTenant[tpAA.ActionTriggersId] = tpAA.Tenant
+	}
+	for atrsID, tenant := range atrsTenant {
+		if tenant != "" {
+			atrsTZ[atrsID] = tpr.tenantTimezone(tenant)
+		}
+	}
+	return atrsTZ, nil
+}
+
+func (tpr *TpReader) Init() {
+	tpr.actions = make(map[string][]*Action)
+	tpr.actionPlans = make(map[string]*ActionPlan)
+	tpr.actionsTriggers = make(map[string]ActionTriggers)
+	tpr.rates = make(map[string]*utils.TPRate)
+	tpr.destinations = make(map[string]*Destination)
+	tpr.destinationRates = make(map[string]*utils.TPDestinationRate)
+	tpr.timings = make(map[string]*utils.TPTiming)
+	tpr.ratingPlans = make(map[string]*RatingPlan)
+	tpr.ratingProfiles = make(map[string]*RatingProfile)
+	tpr.sharedGroups = make(map[string]*SharedGroup)
+	tpr.lcrs = make(map[string]*LCR)
+	tpr.accountActions = make(map[string]*Account)
+	tpr.cdrStats = make(map[string]*CdrStats)
+	tpr.users = make(map[string]*UserProfile)
+	tpr.aliases = make(map[string]*Alias)
+	tpr.derivedChargers = make(map[string]*utils.DerivedChargers)
+	tpr.resLimits = make(map[string]*utils.TPResourceLimit)
+	tpr.exchangeRates = make(map[string]*utils.TPExchangeRate)
+	tpr.holidayCalendars = make(map[string]*utils.TPHolidayCalendar)
+	tpr.dialPlans = make(map[string]*utils.TPDialPlan)
+	tpr.revDests = make(map[string][]string)
+	tpr.revAliases = make(map[string][]string)
+	tpr.acntActionPlans = make(map[string][]string)
+	tpr.danglingRefs = nil
+	tpr.dirtyRpAliases = nil
+	tpr.dirtyAccAliases = nil
+	tpr.riPool = NewRateIntervalPool()
 	//add *any and *asap timing tag (in case of no timings file)
 	tpr.timings[utils.ANY] = &utils.TPTiming{
 		ID:        utils.ANY,
@@ -103,30 +227,6 @@ func NewTpReader(db DataDB, lr LoadReader, tpid, timezone string) *TpReader {
 		StartTime: utils.MetaHourly,
 		EndTime:   "",
 	}
-	return tpr
-}
-
-func (tpr *TpReader) Init() {
-	tpr.actions = make(map[string][]*Action)
-	tpr.actionPlans = make(map[string]*ActionPlan)
-	tpr.actionsTriggers = make(map[string]ActionTriggers)
-	tpr.rates = make(map[string]*utils.TPRate)
-	tpr.destinations = make(map[string]*Destination)
-	tpr.destinationRates = make(map[string]*utils.TPDestinationRate)
-	tpr.timings = make(map[string]*utils.TPTiming)
-	tpr.ratingPlans = make(map[string]*RatingPlan)
-	tpr.ratingProfiles = make(map[string]*RatingProfile)
-	tpr.sharedGroups = make(map[string]*SharedGroup)
-	tpr.lcrs = make(map[string]*LCR)
-	tpr.accountActions = make(map[string]*Account)
-	tpr.cdrStats = make(map[string]*CdrStats)
-	tpr.users = make(map[string]*UserProfile)
-	tpr.aliases = make(map[string]*Alias)
-	tpr.derivedChargers = make(map[string]*utils.DerivedChargers)
-	tpr.resLimits = make(map[string]*utils.TPResourceLimit)
-	tpr.revDests = make(map[string][]string)
-	tpr.revAliases = make(map[string][]string)
-	tpr.acntActionPlans = make(map[string][]string)
 }
 
 func (tpr *TpReader) LoadDestinationsFiltered(tag string) (bool, error) {
@@ -158,7 +258,15 @@ func (tpr *TpReader) LoadDestinations() (err error) {
 	}
 	for _, tpDst := range tps {
 		tpr.destinations[tpDst.ID] = NewDestinationFromTPDestination(tpDst)
+	}
+	if tpr.compressDsts {
+		if removed := compressDestinations(tpr.destinations); removed != 0 {
+			utils.Logger.Info(fmt.Sprintf("<TpReader> compressed destinations of TP %s, removed %d prefixes", tpr.tpid, removed))
+		}
+	}
+	for _, tpDst := range tps {
 		for _, prfx := range tpr.destinations[tpDst.ID].Prefixes {
+			prfx = utils.ReverseDestinationKey(prfx)
 			if _, hasIt := tpr.revDests[prfx]; !hasIt {
 				tpr.revDests[prfx] = make([]string, 0)
 			}
@@ -218,7 +326,7 @@ func (tpr *TpReader) LoadDestinationRates() (err error) {
 		for _, dr := range drs.DestinationRates {
 			rate, exists := tpr.rates[dr.RateId]
 			if !exists {
-				return fmt.Errorf("could not find rate for tag %v", dr.RateId)
+				tpr.addDanglingRef("DestinationRate", drs.ID, "Rate", dr.RateId)
 			}
 			dr.Rate = rate
 			destinationExists := dr.DestinationId == utils.ANY
@@ -231,7 +339,7 @@ func (tpr *TpReader) LoadDestinationRates() (err error) {
 				}
 			}
 			if !destinationExists {
-				return fmt.Errorf("could not get destination for tag %v", dr.DestinationId)
+				tpr.addDanglingRef("DestinationRate", drs.ID, "Destination", dr.DestinationId)
 			}
 		}
 	}
@@ -240,82 +348,97 @@ func (tpr *TpReader) LoadDestinationRates() (err error) {
 
 // Returns true, nil in case of load success, false, nil in case of RatingPlan  not found dataStorage
 func (tpr *TpReader) LoadRatingPlansFiltered(tag string) (bool, error) {
-	mpRpls, err := tpr.lr.GetTPRatingPlans(tpr.tpid, tag, nil)
+	rpls, dsts, err := tpr.ratingPlansFiltered(tag, newTpLookup(tpr))
 	if err != nil {
 		return false, err
-	} else if len(mpRpls) == 0 {
+	} else if len(rpls) == 0 {
 		return false, nil
 	}
+	for _, destination := range dsts {
+		tpr.dataStorage.SetDestination(destination, utils.NonTransactional)
+		tpr.dataStorage.SetReverseDestination(destination, utils.NonTransactional)
+	}
+	for _, ratingPlan := range rpls {
+		if err := tpr.dataStorage.SetRatingPlan(ratingPlan, utils.NonTransactional); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
 
+// ratingPlansFiltered builds the rating plans matching tag out of StorDB, resolving timings, destination rates and rates
+// through the lookup shared by the plans built concurrently,
+// returns also the destinations referenced which are not yet in dataStorage
+func (tpr *TpReader) ratingPlansFiltered(tag string, lk *tpLookup) (rpls []*RatingPlan, dsts []*Destination, err error) {
+	mpRpls, err := tpr.lr.GetTPRatingPlans(tpr.tpid, tag, nil)
+	if err != nil {
+		return nil, nil, err
+	} else if len(mpRpls) == 0 {
+		return nil, nil, nil
+	}
 	bindings := MapTPRatingPlanBindings(mpRpls)
-
-	for tag, rplBnds := range bindings {
-		ratingPlan := &RatingPlan{Id: tag}
-		for _, rp := range rplBnds {
-			tptm, err := tpr.lr.GetTPTimings(tpr.tpid, rp.TimingId)
-			if err != nil || len(tptm) == 0 {
-				return false, fmt.Errorf("no timing with id %s: %v", rp.TimingId, err)
-			}
-			tm, err := MapTPTimings(tptm)
-			if err != nil {
-				return false, err
+	var rplIDs []string
+	for rplID := range bindings {
+		if tag != "" && rplID != tag { // some LoadReaders, eg: CSV, do not filter on tag
+			continue
+		}
+		rplIDs = append(rplIDs, rplID)
+	}
+	rpls = make([]*RatingPlan, len(rplIDs))
+	dstIDs := make([]utils.StringMap, len(rplIDs)) // destinations referenced by each plan
+	if err = processConcurrently(len(rplIDs), func(i int) (err error) {
+		rpls[i], dstIDs[i], err = tpr.ratingPlanFiltered(rplIDs[i], bindings[rplIDs[i]], lk)
+		return
+	}); err != nil {
+		return nil, nil, err
+	}
+	added := make(utils.StringMap)
+	for _, ids := range dstIDs {
+		for dstID := range ids {
+			if added[dstID] {
+				continue
 			}
+			added[dstID] = true
+			dms, _ := lk.destination(dstID) // resolved already while building the plan
+			dsts = append(dsts, dms...)
+		}
+	}
+	return
+}
 
-			rp.SetTiming(tm[rp.TimingId])
-			tpdrm, err := tpr.lr.GetTPDestinationRates(tpr.tpid, rp.DestinationRatesId, nil)
-			if err != nil || len(tpdrm) == 0 {
-				return false, fmt.Errorf("no DestinationRates profile with id %s: %v", rp.DestinationRatesId, err)
-			}
-			drm, err := MapTPDestinationRates(tpdrm)
+// ratingPlanFiltered builds one rating plan out of its bindings, returning also the IDs of the destinations referenced
+func (tpr *TpReader) ratingPlanFiltered(rplID string, rplBnds []*utils.TPRatingPlanBinding,
+	lk *tpLookup) (ratingPlan *RatingPlan, dstIDs utils.StringMap, err error) {
+	ratingPlan = &RatingPlan{Id: rplID}
+	dstIDs = make(utils.StringMap)
+	for _, rp := range rplBnds {
+		tm, err := lk.timing(rp.TimingId)
+		if err != nil {
+			return nil, nil, err
+		}
+		rp.SetTiming(tm)
+		drs, err := lk.destinationRate(rp.DestinationRatesId)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, drate := range drs.DestinationRates {
+			rt, err := lk.rate(drate.RateId)
 			if err != nil {
-				return false, err
+				return nil, nil, err
 			}
-			for _, drate := range drm[rp.DestinationRatesId].DestinationRates {
-				tprt, err := tpr.lr.GetTPRates(tpr.tpid, drate.RateId)
-				if err != nil || len(tprt) == 0 {
-					return false, fmt.Errorf("no Rates profile with id %s: %v", drate.RateId, err)
-				}
-				rt, err := MapTPRates(tprt)
-				if err != nil {
-					return false, err
-				}
-
-				drate.Rate = rt[drate.RateId]
-				ratingPlan.AddRateInterval(drate.DestinationId, GetRateInterval(rp, drate))
-				if drate.DestinationId == utils.ANY {
-					continue // no need of loading the destinations in this case
-				}
-				tpDests, err := tpr.lr.GetTPDestinations(tpr.tpid, drate.DestinationId)
-				if err != nil {
-					return false, err
-				}
-				dms := make([]*Destination, len(tpDests))
-				for i, tpDst := range tpDests {
-					dms[i] = NewDestinationFromTPDestination(tpDst)
-				}
-				destsExist := len(dms) != 0
-				if !destsExist && tpr.dataStorage != nil {
-					if dbExists, err := tpr.dataStorage.HasData(utils.DESTINATION_PREFIX, drate.DestinationId); err != nil {
-						return false, err
-					} else if dbExists {
-						destsExist = true
-					}
-					continue
-				}
-				if !destsExist {
-					return false, fmt.Errorf("could not get destination for tag %v", drate.DestinationId)
-				}
-				for _, destination := range dms {
-					tpr.dataStorage.SetDestination(destination, utils.NonTransactional)
-					tpr.dataStorage.SetReverseDestination(destination, utils.NonTransactional)
-				}
+			dr := *drate // the destination rate is shared between plans, do not modify it
+			dr.Rate = rt
+			tpr.riPool.AddRateInterval(ratingPlan, dr.DestinationId, GetRateInterval(rp, &dr))
+			if dr.DestinationId == utils.ANY {
+				continue // no need of loading the destinations in this case
 			}
-		}
-		if err := tpr.dataStorage.SetRatingPlan(ratingPlan, utils.NonTransactional); err != nil {
-			return false, err
+			if _, err := lk.destination(dr.DestinationId); err != nil {
+				return nil, nil, err
+			}
+			dstIDs[dr.DestinationId] = true
 		}
 	}
-	return true, nil
+	return
 }
 
 func (tpr *TpReader) LoadRatingPlans() (err error) {
@@ -328,20 +451,26 @@ func (tpr *TpReader) LoadRatingPlans() (err error) {
 		for _, rplBnd := range rplBnds {
 			t, exists := tpr.timings[rplBnd.TimingId]
 			if !exists {
-				return fmt.Errorf("could not get timing for tag %v", rplBnd.TimingId)
+				tpr.addDanglingRef("RatingPlan", tag, "Timing", rplBnd.TimingId)
 			}
-			rplBnd.SetTiming(t)
-			drs, exists := tpr.destinationRates[rplBnd.DestinationRatesId]
-			if !exists {
-				return fmt.Errorf("could not find destination rate for tag %v", rplBnd.DestinationRatesId)
+			drs, drsExists := tpr.destinationRates[rplBnd.DestinationRatesId]
+			if !drsExists {
+				tpr.addDanglingRef("RatingPlan", tag, "DestinationRate", rplBnd.DestinationRatesId)
 			}
+			if !exists || !drsExists {
+				continue
+			}
+			rplBnd.SetTiming(t)
 			plan, exists := tpr.ratingPlans[tag]
 			if !exists {
 				plan = &RatingPlan{Id: tag}
 				tpr.ratingPlans[plan.Id] = plan
 			}
 			for _, dr := range drs.DestinationRates {
-				plan.AddRateInterval(dr.DestinationId, GetRateInterval(rplBnd, dr))
+				if dr.Rate == nil { // dangling reference, reported by CheckIntegrity
+					continue
+				}
+				tpr.riPool.AddRateInterval(plan, dr.DestinationId, GetRateInterval(rplBnd, dr))
 			}
 		}
 	}
@@ -362,10 +491,14 @@ func (tpr *TpReader) LoadRatingProfilesFiltered(qriedRpf *utils.TPRatingProfile)
 	for _, tpRpf := range rpfs {
 		resultRatingProfile = &RatingProfile{Id: tpRpf.KeyId()}
 		for _, tpRa := range tpRpf.RatingPlanActivations {
-			at, err := utils.ParseDate(tpRa.ActivationTime)
+			at, err := tpr.parseActivationTime(tpRa.ActivationTime, tpRpf.Tenant)
 			if err != nil {
 				return fmt.Errorf("cannot parse activation time from %v", tpRa.ActivationTime)
 			}
+			dt, err := tpr.parseActivationTime(tpRa.DeactivationTime, tpRpf.Tenant)
+			if err != nil {
+				return fmt.Errorf("cannot parse deactivation time from %v", tpRa.DeactivationTime)
+			}
 			_, exists := tpr.ratingPlans[tpRa.RatingPlanId]
 			if !exists && tpr.dataStorage != nil {
 				if exists, err = tpr.dataStorage.HasData(utils.RATING_PLAN_PREFIX, tpRa.RatingPlanId); err != nil {
@@ -377,10 +510,14 @@ func (tpr *TpReader) LoadRatingProfilesFiltered(qriedRpf *utils.TPRatingProfile)
 			}
 			resultRatingProfile.RatingPlanActivations = append(resultRatingProfile.RatingPlanActivations,
 				&RatingPlanActivation{
-					ActivationTime:  at,
-					RatingPlanId:    tpRa.RatingPlanId,
-					FallbackKeys:    utils.FallbackSubjKeys(tpRpf.Direction, tpRpf.Tenant, tpRpf.Category, tpRa.FallbackSubjects),
-					CdrStatQueueIds: strings.Split(tpRa.CdrStatQueueIds, utils.INFIELD_SEP),
+					ActivationTime:   at,
+					RatingPlanId:     tpRa.RatingPlanId,
+					FallbackKeys:     utils.FallbackSubjKeys(tpRpf.Direction, tpRpf.Tenant, tpRpf.Category, tpRa.FallbackSubjects),
+					CdrStatQueueIds:  strings.Split(tpRa.CdrStatQueueIds, utils.INFIELD_SEP),
+					DeactivationTime: dt,
+					MaxCost:          tpRa.MaxCost,
+					MaxCostStrategy:  tpRa.MaxCostStrategy,
+					TrafficShare:     tpRa.TrafficShare,
 				})
 		}
 		if err := tpr.dataStorage.SetRatingProfile(resultRatingProfile, utils.NonTransactional); err != nil {
@@ -390,6 +527,90 @@ func (tpr *TpReader) LoadRatingProfilesFiltered(qriedRpf *utils.TPRatingProfile)
 	return nil
 }
 
+// ReloadRatingProfile re-reads one rating profile (eg: *out:cgrates.org:call:1001) out of StorDB together with the
+// rating plans, destination rates, rates and destinations it references, writing them in one transaction
+func (tpr *TpReader) ReloadRatingProfile(keyID string) (err error) {
+	keyParts := strings.SplitN(keyID, utils.CONCATENATED_KEY_SEP, 4) // subject can contain separator
+	if len(keyParts) != 4 || utils.IsSliceMember(append([]string{}, keyParts...), "") {
+		return fmt.Errorf("wrong rating profile id: %s", keyID)
+	}
+	tpRpfs, err := tpr.lr.GetTPRatingProfiles(&utils.TPRatingProfile{TPid: tpr.tpid,
+		Direction: keyParts[0], Tenant: keyParts[1], Category: keyParts[2], Subject: keyParts[3]})
+	if err != nil {
+		return err
+	}
+	mpTpRpfs, err := MapTPRatingProfiles(tpRpfs)
+	if err != nil {
+		return err
+	}
+	rpf := &RatingProfile{Id: keyID}
+	rpls := make(map[string]*RatingPlan)
+	var dsts []*Destination
+	lk := newTpLookup(tpr)
+	for _, tpRpf := range mpTpRpfs {
+		if tpRpf.KeyId() != keyID {
+			continue
+		}
+		for _, tpRa := range tpRpf.RatingPlanActivations {
+			at, err := tpr.parseActivationTime(tpRa.ActivationTime, tpRpf.Tenant)
+			if err != nil {
+				return fmt.Errorf("cannot parse activation time from %v", tpRa.ActivationTime)
+			}
+			dt, err := tpr.parseActivationTime(tpRa.DeactivationTime, tpRpf.Tenant)
+			if err != nil {
+				return fmt.Errorf("cannot parse deactivation time from %v", tpRa.DeactivationTime)
+			}
+			if _, resolved := rpls[tpRa.RatingPlanId]; !resolved {
+				rplsFltrd, dstsFltrd, err := tpr.ratingPlansFiltered(tpRa.RatingPlanId, lk)
+				if err != nil {
+					return err
+				} else if len(rplsFltrd) == 0 {
+					return fmt.Errorf("could not load rating plans for tag: %v", tpRa.RatingPlanId)
+				}
+				rpls[tpRa.RatingPlanId] = rplsFltrd[0]
+				dsts = append(dsts, dstsFltrd...)
+			}
+			rpf.RatingPlanActivations = append(rpf.RatingPlanActivations,
+				&RatingPlanActivation{
+					ActivationTime:   at,
+					RatingPlanId:     tpRa.RatingPlanId,
+					FallbackKeys:     utils.FallbackSubjKeys(tpRpf.Direction, tpRpf.Tenant, tpRpf.Category, tpRa.FallbackSubjects),
+					CdrStatQueueIds:  strings.Split(tpRa.CdrStatQueueIds, utils.INFIELD_SEP),
+					DeactivationTime: dt,
+					MaxCost:          tpRa.MaxCost,
+					MaxCostStrategy:  tpRa.MaxCostStrategy,
+					TrafficShare:     tpRa.TrafficShare,
+				})
+		}
+	}
+	if len(rpf.RatingPlanActivations) == 0 {
+		return utils.ErrNotFound
+	}
+	// all references resolved, write them out
+	transID := utils.GenUUID()
+	defer func() {
+		if err != nil {
+			cache.RollbackTransaction(transID)
+		} else {
+			cache.CommitTransaction(transID)
+		}
+	}()
+	for _, dst := range dsts {
+		if err = tpr.dataStorage.SetDestination(dst, transID); err != nil {
+			return
+		}
+		if err = tpr.dataStorage.SetReverseDestination(dst, transID); err != nil {
+			return
+		}
+	}
+	for _, rpl := range rpls {
+		if err = tpr.dataStorage.SetRatingPlan(rpl, transID); err != nil {
+			return
+		}
+	}
+	return tpr.dataStorage.SetRatingProfile(rpf, transID)
+}
+
 func (tpr *TpReader) LoadRatingProfiles() (err error) {
 	tps, err := tpr.lr.GetTPRatingProfiles(&utils.TPRatingProfile{TPid: tpr.tpid})
 	if err != nil {
@@ -402,10 +623,14 @@ func (tpr *TpReader) LoadRatingProfiles() (err error) {
 	for _, tpRpf := range mpTpRpfs {
 		rpf := &RatingProfile{Id: tpRpf.KeyId()}
 		for _, tpRa := range tpRpf.RatingPlanActivations {
-			at, err := utils.ParseDate(tpRa.ActivationTime)
+			at, err := tpr.parseActivationTime(tpRa.ActivationTime, tpRpf.Tenant)
 			if err != nil {
 				return fmt.Errorf("cannot parse activation time from %v", tpRa.ActivationTime)
 			}
+			dt, err := tpr.parseActivationTime(tpRa.DeactivationTime, tpRpf.Tenant)
+			if err != nil {
+				return fmt.Errorf("cannot parse deactivation time from %v", tpRa.DeactivationTime)
+			}
 			_, exists := tpr.ratingPlans[tpRa.RatingPlanId]
 			if !exists && tpr.dataStorage != nil { // Only query if there is a connection, eg on dry run there is none
 				if exists, err = tpr.dataStorage.HasData(utils.RATING_PLAN_PREFIX, tpRa.RatingPlanId); err != nil {
@@ -413,14 +638,19 @@ func (tpr *TpReader) LoadRatingProfiles() (err error) {
 				}
 			}
 			if !exists {
-				return fmt.Errorf("could not load rating plans for tag: %v", tpRa.RatingPlanId)
+				tpr.addDanglingRef("RatingProfile", tpRpf.KeyId(), "RatingPlan", tpRa.RatingPlanId)
+				continue
 			}
 			rpf.RatingPlanActivations = append(rpf.RatingPlanActivations,
 				&RatingPlanActivation{
-					ActivationTime:  at,
-					RatingPlanId:    tpRa.RatingPlanId,
-					FallbackKeys:    utils.FallbackSubjKeys(tpRpf.Direction, tpRpf.Tenant, tpRpf.Category, tpRa.FallbackSubjects),
-					CdrStatQueueIds: strings.Split(tpRa.CdrStatQueueIds, utils.INFIELD_SEP),
+					ActivationTime:   at,
+					RatingPlanId:     tpRa.RatingPlanId,
+					FallbackKeys:     utils.FallbackSubjKeys(tpRpf.Direction, tpRpf.Tenant, tpRpf.Category, tpRa.FallbackSubjects),
+					CdrStatQueueIds:  strings.Split(tpRa.CdrStatQueueIds, utils.INFIELD_SEP),
+					DeactivationTime: dt,
+					MaxCost:          tpRa.MaxCost,
+					MaxCostStrategy:  tpRa.MaxCostStrategy,
+					TrafficShare:     tpRa.TrafficShare,
 				})
 		}
 		tpr.ratingProfiles[tpRpf.KeyId()] = rpf
@@ -489,7 +719,7 @@ func (tpr *TpReader) LoadLCRs() (err error) {
 					}
 				}
 				if !found {
-					return fmt.Errorf("[LCR] could not find ratingProfiles with prefix %s", ratingProfileSearchKey)
+					tpr.addDanglingRef("LCR", tpLcr.GetLcrRuleId(), "RatingProfile", ratingProfileSearchKey+"*")
 				}
 
 				// check destination tags
@@ -501,11 +731,14 @@ func (tpr *TpReader) LoadLCRs() (err error) {
 						}
 					}
 					if !found {
-						return fmt.Errorf("[LCR] could not find destination with tag %s", rule.DestinationId)
+						tpr.addDanglingRef("LCR", tpLcr.GetLcrRuleId(), "Destination", rule.DestinationId)
 					}
 				}
 				tag := utils.LCRKey(tpLcr.Direction, tpLcr.Tenant, tpLcr.Category, tpLcr.Account, tpLcr.Subject)
-				activationTime, _ := utils.ParseTimeDetectLayout(rule.ActivationTime, tpr.timezone)
+				activationTime, err := utils.ParseTimeDetectLayoutStrict(rule.ActivationTime, tpr.tenantTimezone(tpLcr.Tenant))
+				if err != nil {
+					return fmt.Errorf("[LCR] %s, ActivationTime: %s", tpLcr.GetLcrRuleId(), err.Error())
+				}
 
 				lcr, found := tpr.lcrs[tag]
 				if !found {
@@ -578,11 +811,9 @@ func (tpr *TpReader) LoadActions() (err error) {
 			}
 
 			if tpact.Units != "" && tpact.Units != utils.ANY {
-				vf, err := utils.ParseBalanceFilterValue(tpact.Units)
-				if err != nil {
+				if err := acts[idx].Balance.SetUnits(tpact.Units); err != nil {
 					return err
 				}
-				acts[idx].Balance.Value = vf
 			}
 
 			if tpact.BalanceWeight != "" && tpact.BalanceWeight != utils.ANY {
@@ -633,12 +864,14 @@ func (tpr *TpReader) LoadActions() (err error) {
 				for _, timingID := range timingIds {
 					if timing, found := tpr.timings[timingID]; found {
 						acts[idx].Balance.Timings = append(acts[idx].Balance.Timings, &RITiming{
-							Years:     timing.Years,
-							Months:    timing.Months,
-							MonthDays: timing.MonthDays,
-							WeekDays:  timing.WeekDays,
-							StartTime: timing.StartTime,
-							EndTime:   timing.EndTime,
+							Years:            timing.Years,
+							Months:           timing.Months,
+							MonthDays:        timing.MonthDays,
+							WeekDays:         timing.WeekDays,
+							StartTime:        timing.StartTime,
+							EndTime:          timing.EndTime,
+							DSTPolicy:        timing.DSTPolicy,
+							HolidayCalendars: timing.HolidayCalendars,
 						})
 					} else {
 						return fmt.Errorf("could not find timing: %v", timingID)
@@ -667,11 +900,14 @@ func (tpr *TpReader) LoadActionPlans() (err error) {
 				}
 			}
 			if !exists {
-				return fmt.Errorf("[ActionPlans] Could not load the action for tag: %v", at.ActionsId)
+				tpr.addDanglingRef("ActionPlan", atId, "Actions", at.ActionsId)
 			}
-			t, exists := tpr.timings[at.TimingId]
-			if !exists {
-				return fmt.Errorf("[ActionPlans] Could not load the timing for tag: %v", at.TimingId)
+			t, tExists := tpr.timings[at.TimingId]
+			if !tExists {
+				tpr.addDanglingRef("ActionPlan", atId, "Timing", at.TimingId)
+			}
+			if !exists || !tExists {
+				continue
 			}
 			var actPln *ActionPlan
 			if actPln, exists = tpr.actionPlans[atId]; !exists {
@@ -692,6 +928,7 @@ func (tpr *TpReader) LoadActionPlans() (err error) {
 					},
 				},
 				ActionsID: at.ActionsId,
+				CatchUp:   at.CatchUp,
 			})
 
 			tpr.actionPlans[atId] = actPln
@@ -705,15 +942,23 @@ func (tpr *TpReader) LoadActionTriggers() (err error) {
 	if err != nil {
 		return err
 	}
+	atrsTZ, err := tpr.actionTriggersTimezones()
+	if err != nil {
+		return err
+	}
 	storAts := MapTPActionTriggers(tps)
 	for key, atrsLst := range storAts {
+		timezone, has := atrsTZ[key]
+		if !has {
+			timezone = tpr.timezone
+		}
 		atrs := make([]*ActionTrigger, len(atrsLst))
 		for idx, atr := range atrsLst {
-			expirationDate, err := utils.ParseTimeDetectLayout(atr.ExpirationDate, tpr.timezone)
+			expirationDate, err := utils.ParseTimeDetectLayoutStrict(atr.ExpirationDate, timezone)
 			if err != nil {
 				return err
 			}
-			activationDate, err := utils.ParseTimeDetectLayout(atr.ActivationDate, tpr.timezone)
+			activationDate, err := utils.ParseTimeDetectLayoutStrict(atr.ActivationDate, timezone)
 			if err != nil {
 				return err
 			}
@@ -754,7 +999,7 @@ func (tpr *TpReader) LoadActionTriggers() (err error) {
 				atrs[idx].Balance.Weight = utils.Float64Pointer(u)
 			}
 			if atr.BalanceExpirationDate != "" && atr.BalanceExpirationDate != utils.ANY && atr.ExpirationDate != utils.UNLIMITED {
-				u, err := utils.ParseTimeDetectLayout(atr.BalanceExpirationDate, tpr.timezone)
+				u, err := utils.ParseTimeDetectLayout(atr.BalanceExpirationDate, timezone)
 				if err != nil {
 					return err
 				}
@@ -809,329 +1054,348 @@ func (tpr *TpReader) LoadAccountActionsFiltered(qriedAA *utils.TPAccountActions)
 	if err != nil {
 		return err
 	}
+	aas := make([]*utils.TPAccountActions, 0, len(storAas))
 	for _, accountAction := range storAas {
-		id := accountAction.KeyId()
-		var actionIDs []string // collects action ids
-		// action timings
-		if accountAction.ActionPlanId != "" {
-			// get old userBalanceIds
-			exitingAccountIds := make(utils.StringMap)
-			existingActionPlan, err := tpr.dataStorage.GetActionPlan(accountAction.ActionPlanId, true, utils.NonTransactional)
-			if err == nil && existingActionPlan != nil {
-				exitingAccountIds = existingActionPlan.AccountIDs
-			}
-
-			tpap, err := tpr.lr.GetTPActionPlans(tpr.tpid, accountAction.ActionPlanId)
-			if err != nil {
-				return errors.New(err.Error() + " (ActionPlan): " + accountAction.ActionPlanId)
-			} else if len(tpap) == 0 {
-				return fmt.Errorf("no action plan with id <%s>", accountAction.ActionPlanId)
-			}
-			aps := MapTPActionTimings(tpap)
-			var actionPlan *ActionPlan
-			ats := aps[accountAction.ActionPlanId]
-			for _, at := range ats {
-				// Check action exists before saving it inside actionTiming key
-				// ToDo: try saving the key after the actions was retrieved in order to save one query here.
-				if actions, err := tpr.lr.GetTPActions(tpr.tpid, at.ActionsId); err != nil {
-					return errors.New(err.Error() + " (Actions): " + at.ActionsId)
-				} else if len(actions) == 0 {
-					return fmt.Errorf("no action with id <%s>", at.ActionsId)
-				}
-				var t *utils.TPTiming
-				if at.TimingId != utils.ASAP {
-					tptm, err := tpr.lr.GetTPTimings(tpr.tpid, at.TimingId)
-					if err != nil {
-						return errors.New(err.Error() + " (Timing): " + at.TimingId)
-					} else if len(tptm) == 0 {
-						return fmt.Errorf("no timing with id <%s>", at.TimingId)
-					}
-					tm, err := MapTPTimings(tptm)
-					if err != nil {
-						return err
-					}
-					t = tm[at.TimingId]
-				} else {
-					t = tpr.timings[at.TimingId] // *asap
+		aas = append(aas, accountAction)
+	}
+	lk := newTpLookup(tpr)
+	return processConcurrently(len(aas), func(i int) error {
+		return tpr.loadAccountActionFiltered(aas[i], lk)
+	})
+}
+
+// loadAccountActionFiltered writes one account together with its action plan, triggers and actions
+func (tpr *TpReader) loadAccountActionFiltered(accountAction *utils.TPAccountActions, lk *tpLookup) (err error) {
+	id := accountAction.KeyId()
+	var actionIDs []string // collects action ids
+	// action timings
+	if accountAction.ActionPlanId != "" {
+		// the action plan is shared with the accounts loaded concurrently
+		apActIDs, err := guardian.Guardian.Guard(func() (interface{}, error) {
+			return tpr.loadAccountActionPlan(accountAction, lk)
+		}, 0, utils.ACTION_PLAN_PREFIX+accountAction.ActionPlanId)
+		if err != nil {
+			return err
+		}
+		actionIDs = append(actionIDs, apActIDs.([]string)...)
+	}
+	// action triggers
+	var actionTriggers ActionTriggers
+	//ActionTriggerPriotityList []*ActionTrigger
+	if accountAction.ActionTriggersId != "" {
+		tpatrs, err := tpr.lr.GetTPActionTriggers(tpr.tpid, accountAction.ActionTriggersId)
+		if err != nil {
+			return errors.New(err.Error() + " (ActionTriggers): " + accountAction.ActionTriggersId)
+		}
+		atrs := MapTPActionTriggers(tpatrs)
+		atrsMap := make(map[string][]*ActionTrigger)
+		for key, atrsLst := range atrs {
+			atrs := make([]*ActionTrigger, len(atrsLst))
+			for idx, atr := range atrsLst {
+				minSleep, _ := utils.ParseDurationWithSecs(atr.MinSleep)
+				expTime, err := utils.ParseTimeDetectLayoutStrict(atr.ExpirationDate, tpr.tenantTimezone(accountAction.Tenant))
+				if err != nil {
+					return errors.New(err.Error() + " (ActionTriggers): " + accountAction.ActionTriggersId)
 				}
-				if actionPlan == nil {
-					actionPlan = &ActionPlan{
-						Id: accountAction.ActionPlanId,
-					}
+				actTime, err := utils.ParseTimeDetectLayoutStrict(atr.ActivationDate, tpr.tenantTimezone(accountAction.Tenant))
+				if err != nil {
+					return errors.New(err.Error() + " (ActionTriggers): " + accountAction.ActionTriggersId)
 				}
-				actionPlan.ActionTimings = append(actionPlan.ActionTimings, &ActionTiming{
-					Uuid:   utils.GenUUID(),
-					Weight: at.Weight,
-					Timing: &RateInterval{
-						Timing: &RITiming{
-							Months:    t.Months,
-							MonthDays: t.MonthDays,
-							WeekDays:  t.WeekDays,
-							StartTime: t.StartTime,
-						},
-					},
-					ActionsID: at.ActionsId,
-				})
-				// collect action ids from timings
-				actionIDs = append(actionIDs, at.ActionsId)
-				exitingAccountIds[id] = true
-				actionPlan.AccountIDs = exitingAccountIds
-			}
-			// write tasks
-			for _, at := range actionPlan.ActionTimings {
-				if at.IsASAP() {
-					for accID := range actionPlan.AccountIDs {
-						t := &Task{
-							Uuid:      utils.GenUUID(),
-							AccountID: accID,
-							ActionsID: at.ActionsID,
-						}
-						if err = tpr.dataStorage.PushTask(t); err != nil {
-							return err
-						}
-					}
+				if atr.UniqueID == "" {
+					atr.UniqueID = utils.GenUUID()
+				}
+				atrs[idx] = &ActionTrigger{
+					ID:             key,
+					UniqueID:       atr.UniqueID,
+					ThresholdType:  atr.ThresholdType,
+					ThresholdValue: atr.ThresholdValue,
+					Recurrent:      atr.Recurrent,
+					MinSleep:       minSleep,
+					ExpirationDate: expTime,
+					ActivationDate: actTime,
+					Balance:        &BalanceFilter{},
+					Weight:         atr.Weight,
+					ActionsID:      atr.ActionsId,
+				}
+				if atr.BalanceId != "" && atr.BalanceId != utils.ANY {
+					atrs[idx].Balance.ID = utils.StringPointer(atr.BalanceId)
 				}
-			}
-			// write action plan
-			if err = tpr.dataStorage.SetActionPlan(accountAction.ActionPlanId, actionPlan, false, utils.NonTransactional); err != nil {
-				return errors.New(err.Error() + " (SetActionPlan): " + accountAction.ActionPlanId)
-			}
-			if err = tpr.dataStorage.SetAccountActionPlans(id, []string{accountAction.ActionPlanId}, false); err != nil {
-				return err
-			}
-			if err = tpr.dataStorage.CacheDataFromDB(utils.AccountActionPlansPrefix, []string{id}, true); err != nil {
-				return err
-			}
-		}
-		// action triggers
-		var actionTriggers ActionTriggers
-		//ActionTriggerPriotityList []*ActionTrigger
-		if accountAction.ActionTriggersId != "" {
-			tpatrs, err := tpr.lr.GetTPActionTriggers(tpr.tpid, accountAction.ActionTriggersId)
-			if err != nil {
-				return errors.New(err.Error() + " (ActionTriggers): " + accountAction.ActionTriggersId)
-			}
-			atrs := MapTPActionTriggers(tpatrs)
-			atrsMap := make(map[string][]*ActionTrigger)
-			for key, atrsLst := range atrs {
-				atrs := make([]*ActionTrigger, len(atrsLst))
-				for idx, atr := range atrsLst {
-					minSleep, _ := utils.ParseDurationWithSecs(atr.MinSleep)
-					expTime, _ := utils.ParseTimeDetectLayout(atr.ExpirationDate, tpr.timezone)
-					actTime, _ := utils.ParseTimeDetectLayout(atr.ActivationDate, tpr.timezone)
-					if atr.UniqueID == "" {
-						atr.UniqueID = utils.GenUUID()
-					}
-					atrs[idx] = &ActionTrigger{
-						ID:             key,
-						UniqueID:       atr.UniqueID,
-						ThresholdType:  atr.ThresholdType,
-						ThresholdValue: atr.ThresholdValue,
-						Recurrent:      atr.Recurrent,
-						MinSleep:       minSleep,
-						ExpirationDate: expTime,
-						ActivationDate: actTime,
-						Balance:        &BalanceFilter{},
-						Weight:         atr.Weight,
-						ActionsID:      atr.ActionsId,
-					}
-					if atr.BalanceId != "" && atr.BalanceId != utils.ANY {
-						atrs[idx].Balance.ID = utils.StringPointer(atr.BalanceId)
-					}
-
-					if atr.BalanceType != "" && atr.BalanceType != utils.ANY {
-						atrs[idx].Balance.Type = utils.StringPointer(atr.BalanceType)
-					}
-
-					if atr.BalanceWeight != "" && atr.BalanceWeight != utils.ANY {
-						u, err := strconv.ParseFloat(atr.BalanceWeight, 64)
-						if err != nil {
-							return err
-						}
-						atrs[idx].Balance.Weight = utils.Float64Pointer(u)
-					}
-					if atr.BalanceExpirationDate != "" && atr.BalanceExpirationDate != utils.ANY && atr.ExpirationDate != utils.UNLIMITED {
-						u, err := utils.ParseTimeDetectLayout(atr.BalanceExpirationDate, tpr.timezone)
-						if err != nil {
-							return err
-						}
-						atrs[idx].Balance.ExpirationDate = utils.TimePointer(u)
-					}
-					if atr.BalanceRatingSubject != "" && atr.BalanceRatingSubject != utils.ANY {
-						atrs[idx].Balance.RatingSubject = utils.StringPointer(atr.BalanceRatingSubject)
-					}
 
-					if atr.BalanceCategories != "" && atr.BalanceCategories != utils.ANY {
-						atrs[idx].Balance.Categories = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceCategories))
-					}
-					if atr.BalanceDirections != "" && atr.BalanceDirections != utils.ANY {
-						atrs[idx].Balance.Directions = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceDirections))
-					}
-					if atr.BalanceDestinationIds != "" && atr.BalanceDestinationIds != utils.ANY {
-						atrs[idx].Balance.DestinationIDs = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceDestinationIds))
-					}
-					if atr.BalanceSharedGroups != "" && atr.BalanceSharedGroups != utils.ANY {
-						atrs[idx].Balance.SharedGroups = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceSharedGroups))
-					}
-					if atr.BalanceTimingTags != "" && atr.BalanceTimingTags != utils.ANY {
-						atrs[idx].Balance.TimingIDs = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceTimingTags))
-					}
-					if atr.BalanceBlocker != "" && atr.BalanceBlocker != utils.ANY {
-						u, err := strconv.ParseBool(atr.BalanceBlocker)
-						if err != nil {
-							return err
-						}
-						atrs[idx].Balance.Blocker = utils.BoolPointer(u)
-					}
-					if atr.BalanceDisabled != "" && atr.BalanceDisabled != utils.ANY {
-						u, err := strconv.ParseBool(atr.BalanceDisabled)
-						if err != nil {
-							return err
-						}
-						atrs[idx].Balance.Disabled = utils.BoolPointer(u)
-					}
+				if atr.BalanceType != "" && atr.BalanceType != utils.ANY {
+					atrs[idx].Balance.Type = utils.StringPointer(atr.BalanceType)
 				}
-				atrsMap[key] = atrs
-			}
-			actionTriggers = atrsMap[accountAction.ActionTriggersId]
-			// collect action ids from triggers
-			for _, atr := range actionTriggers {
-				actionIDs = append(actionIDs, atr.ActionsID)
-			}
-			// write action triggers
-			err = tpr.dataStorage.SetActionTriggers(accountAction.ActionTriggersId, actionTriggers, utils.NonTransactional)
-			if err != nil {
-				return errors.New(err.Error() + " (SetActionTriggers): " + accountAction.ActionTriggersId)
-			}
-		}
 
-		// actions
-		facts := make(map[string][]*Action)
-		for _, actId := range actionIDs {
-			tpas, err := tpr.lr.GetTPActions(tpr.tpid, actId)
-			if err != nil {
-				return err
-			}
-			as := MapTPActions(tpas)
-			for tag, tpacts := range as {
-				acts := make([]*Action, len(tpacts))
-				for idx, tpact := range tpacts {
-					// check filter field
-					if len(tpact.Filter) > 0 {
-						if _, err := structmatcher.NewStructMatcher(tpact.Filter); err != nil {
-							return fmt.Errorf("error parsing action %s filter field: %v", tag, err)
-						}
-					}
-					acts[idx] = &Action{
-						Id:         tag,
-						ActionType: tpact.Identifier,
-						//BalanceType:      tpact.BalanceType,
-						Weight:           tpact.Weight,
-						ExtraParameters:  tpact.ExtraParameters,
-						ExpirationString: tpact.ExpiryTime,
-						Filter:           tpact.Filter,
-						Balance:          &BalanceFilter{},
-					}
-					if tpact.BalanceId != "" && tpact.BalanceId != utils.ANY {
-						acts[idx].Balance.ID = utils.StringPointer(tpact.BalanceId)
-					}
-					if tpact.BalanceType != "" && tpact.BalanceType != utils.ANY {
-						acts[idx].Balance.Type = utils.StringPointer(tpact.BalanceType)
+				if atr.BalanceWeight != "" && atr.BalanceWeight != utils.ANY {
+					u, err := strconv.ParseFloat(atr.BalanceWeight, 64)
+					if err != nil {
+						return err
 					}
-
-					if tpact.Units != "" && tpact.Units != utils.ANY {
-						vf, err := utils.ParseBalanceFilterValue(tpact.Units)
-						if err != nil {
-							return err
-						}
-						acts[idx].Balance.Value = vf
+					atrs[idx].Balance.Weight = utils.Float64Pointer(u)
+				}
+				if atr.BalanceExpirationDate != "" && atr.BalanceExpirationDate != utils.ANY && atr.ExpirationDate != utils.UNLIMITED {
+					u, err := utils.ParseTimeDetectLayout(atr.BalanceExpirationDate, tpr.tenantTimezone(accountAction.Tenant))
+					if err != nil {
+						return err
 					}
+					atrs[idx].Balance.ExpirationDate = utils.TimePointer(u)
+				}
+				if atr.BalanceRatingSubject != "" && atr.BalanceRatingSubject != utils.ANY {
+					atrs[idx].Balance.RatingSubject = utils.StringPointer(atr.BalanceRatingSubject)
+				}
 
-					if tpact.BalanceWeight != "" && tpact.BalanceWeight != utils.ANY {
-						u, err := strconv.ParseFloat(tpact.BalanceWeight, 64)
-						if err != nil {
-							return err
-						}
-						acts[idx].Balance.Weight = utils.Float64Pointer(u)
+				if atr.BalanceCategories != "" && atr.BalanceCategories != utils.ANY {
+					atrs[idx].Balance.Categories = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceCategories))
+				}
+				if atr.BalanceDirections != "" && atr.BalanceDirections != utils.ANY {
+					atrs[idx].Balance.Directions = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceDirections))
+				}
+				if atr.BalanceDestinationIds != "" && atr.BalanceDestinationIds != utils.ANY {
+					atrs[idx].Balance.DestinationIDs = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceDestinationIds))
+				}
+				if atr.BalanceSharedGroups != "" && atr.BalanceSharedGroups != utils.ANY {
+					atrs[idx].Balance.SharedGroups = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceSharedGroups))
+				}
+				if atr.BalanceTimingTags != "" && atr.BalanceTimingTags != utils.ANY {
+					atrs[idx].Balance.TimingIDs = utils.StringMapPointer(utils.ParseStringMap(atr.BalanceTimingTags))
+				}
+				if atr.BalanceBlocker != "" && atr.BalanceBlocker != utils.ANY {
+					u, err := strconv.ParseBool(atr.BalanceBlocker)
+					if err != nil {
+						return err
 					}
-					if tpact.RatingSubject != "" && tpact.RatingSubject != utils.ANY {
-						acts[idx].Balance.RatingSubject = utils.StringPointer(tpact.RatingSubject)
+					atrs[idx].Balance.Blocker = utils.BoolPointer(u)
+				}
+				if atr.BalanceDisabled != "" && atr.BalanceDisabled != utils.ANY {
+					u, err := strconv.ParseBool(atr.BalanceDisabled)
+					if err != nil {
+						return err
 					}
+					atrs[idx].Balance.Disabled = utils.BoolPointer(u)
+				}
+			}
+			atrsMap[key] = atrs
+		}
+		actionTriggers = atrsMap[accountAction.ActionTriggersId]
+		// collect action ids from triggers
+		for _, atr := range actionTriggers {
+			actionIDs = append(actionIDs, atr.ActionsID)
+		}
+		// write action triggers
+		err = tpr.dataStorage.SetActionTriggers(accountAction.ActionTriggersId, actionTriggers, utils.NonTransactional)
+		if err != nil {
+			return errors.New(err.Error() + " (SetActionTriggers): " + accountAction.ActionTriggersId)
+		}
+	}
 
-					if tpact.Categories != "" && tpact.Categories != utils.ANY {
-						acts[idx].Balance.Categories = utils.StringMapPointer(utils.ParseStringMap(tpact.Categories))
-					}
-					if tpact.Directions != "" && tpact.Directions != utils.ANY {
-						acts[idx].Balance.Directions = utils.StringMapPointer(utils.ParseStringMap(tpact.Directions))
-					}
-					if tpact.DestinationIds != "" && tpact.DestinationIds != utils.ANY {
-						acts[idx].Balance.DestinationIDs = utils.StringMapPointer(utils.ParseStringMap(tpact.DestinationIds))
+	// actions
+	facts := make(map[string][]*Action)
+	for _, actId := range actionIDs {
+		as, err := lk.tpActions(actId)
+		if err != nil {
+			return err
+		}
+		for tag, tpacts := range as {
+			acts := make([]*Action, len(tpacts))
+			for idx, tpact := range tpacts {
+				// check filter field
+				if len(tpact.Filter) > 0 {
+					if _, err := structmatcher.NewStructMatcher(tpact.Filter); err != nil {
+						return fmt.Errorf("error parsing action %s filter field: %v", tag, err)
 					}
-					if tpact.SharedGroups != "" && tpact.SharedGroups != utils.ANY {
-						acts[idx].Balance.SharedGroups = utils.StringMapPointer(utils.ParseStringMap(tpact.SharedGroups))
+				}
+				acts[idx] = &Action{
+					Id:         tag,
+					ActionType: tpact.Identifier,
+					//BalanceType:      tpact.BalanceType,
+					Weight:           tpact.Weight,
+					ExtraParameters:  tpact.ExtraParameters,
+					ExpirationString: tpact.ExpiryTime,
+					Filter:           tpact.Filter,
+					Balance:          &BalanceFilter{},
+				}
+				if tpact.BalanceId != "" && tpact.BalanceId != utils.ANY {
+					acts[idx].Balance.ID = utils.StringPointer(tpact.BalanceId)
+				}
+				if tpact.BalanceType != "" && tpact.BalanceType != utils.ANY {
+					acts[idx].Balance.Type = utils.StringPointer(tpact.BalanceType)
+				}
+
+				if tpact.Units != "" && tpact.Units != utils.ANY {
+					if err := acts[idx].Balance.SetUnits(tpact.Units); err != nil {
+						return err
 					}
-					if tpact.TimingTags != "" && tpact.TimingTags != utils.ANY {
-						acts[idx].Balance.TimingIDs = utils.StringMapPointer(utils.ParseStringMap(tpact.TimingTags))
+				}
+
+				if tpact.BalanceWeight != "" && tpact.BalanceWeight != utils.ANY {
+					u, err := strconv.ParseFloat(tpact.BalanceWeight, 64)
+					if err != nil {
+						return err
 					}
-					if tpact.BalanceBlocker != "" && tpact.BalanceBlocker != utils.ANY {
-						u, err := strconv.ParseBool(tpact.BalanceBlocker)
-						if err != nil {
-							return err
-						}
-						acts[idx].Balance.Blocker = utils.BoolPointer(u)
+					acts[idx].Balance.Weight = utils.Float64Pointer(u)
+				}
+				if tpact.RatingSubject != "" && tpact.RatingSubject != utils.ANY {
+					acts[idx].Balance.RatingSubject = utils.StringPointer(tpact.RatingSubject)
+				}
+
+				if tpact.Categories != "" && tpact.Categories != utils.ANY {
+					acts[idx].Balance.Categories = utils.StringMapPointer(utils.ParseStringMap(tpact.Categories))
+				}
+				if tpact.Directions != "" && tpact.Directions != utils.ANY {
+					acts[idx].Balance.Directions = utils.StringMapPointer(utils.ParseStringMap(tpact.Directions))
+				}
+				if tpact.DestinationIds != "" && tpact.DestinationIds != utils.ANY {
+					acts[idx].Balance.DestinationIDs = utils.StringMapPointer(utils.ParseStringMap(tpact.DestinationIds))
+				}
+				if tpact.SharedGroups != "" && tpact.SharedGroups != utils.ANY {
+					acts[idx].Balance.SharedGroups = utils.StringMapPointer(utils.ParseStringMap(tpact.SharedGroups))
+				}
+				if tpact.TimingTags != "" && tpact.TimingTags != utils.ANY {
+					acts[idx].Balance.TimingIDs = utils.StringMapPointer(utils.ParseStringMap(tpact.TimingTags))
+				}
+				if tpact.BalanceBlocker != "" && tpact.BalanceBlocker != utils.ANY {
+					u, err := strconv.ParseBool(tpact.BalanceBlocker)
+					if err != nil {
+						return err
 					}
-					if tpact.BalanceDisabled != "" && tpact.BalanceDisabled != utils.ANY {
-						u, err := strconv.ParseBool(tpact.BalanceDisabled)
-						if err != nil {
-							return err
-						}
-						acts[idx].Balance.Disabled = utils.BoolPointer(u)
+					acts[idx].Balance.Blocker = utils.BoolPointer(u)
+				}
+				if tpact.BalanceDisabled != "" && tpact.BalanceDisabled != utils.ANY {
+					u, err := strconv.ParseBool(tpact.BalanceDisabled)
+					if err != nil {
+						return err
 					}
-					// load action timings from tags
-					if tpact.TimingTags != "" {
-						timingIds := strings.Split(tpact.TimingTags, utils.INFIELD_SEP)
-						for _, timingID := range timingIds {
-							if timing, found := tpr.timings[timingID]; found {
-								acts[idx].Balance.Timings = append(acts[idx].Balance.Timings, &RITiming{
-									Years:     timing.Years,
-									Months:    timing.Months,
-									MonthDays: timing.MonthDays,
-									WeekDays:  timing.WeekDays,
-									StartTime: timing.StartTime,
-									EndTime:   timing.EndTime,
-								})
-							} else {
-								return fmt.Errorf("could not find timing: %v", timingID)
-							}
+					acts[idx].Balance.Disabled = utils.BoolPointer(u)
+				}
+				// load action timings from tags
+				if tpact.TimingTags != "" {
+					timingIds := strings.Split(tpact.TimingTags, utils.INFIELD_SEP)
+					for _, timingID := range timingIds {
+						if timing, found := tpr.timings[timingID]; found {
+							acts[idx].Balance.Timings = append(acts[idx].Balance.Timings, &RITiming{
+								Years:            timing.Years,
+								Months:           timing.Months,
+								MonthDays:        timing.MonthDays,
+								WeekDays:         timing.WeekDays,
+								StartTime:        timing.StartTime,
+								EndTime:          timing.EndTime,
+								DSTPolicy:        timing.DSTPolicy,
+								HolidayCalendars: timing.HolidayCalendars,
+							})
+						} else {
+							return fmt.Errorf("could not find timing: %v", timingID)
 						}
 					}
 				}
-				facts[tag] = acts
-			}
-		}
-		// write actions
-		for k, as := range facts {
-			err = tpr.dataStorage.SetActions(k, as, utils.NonTransactional)
-			if err != nil {
-				return err
 			}
+			facts[tag] = acts
 		}
-		ub, err := tpr.dataStorage.GetAccount(id)
+	}
+	// write actions
+	for k, as := range facts {
+		err = tpr.dataStorage.SetActions(k, as, utils.NonTransactional)
 		if err != nil {
-			ub = &Account{
-				ID: id,
-			}
-		}
-		ub.ActionTriggers = actionTriggers
-		// init counters
-		ub.InitCounters()
-		if err := tpr.dataStorage.SetAccount(ub); err != nil {
 			return err
 		}
 	}
+	ub, err := tpr.dataStorage.GetAccount(id)
+	if err != nil {
+		ub = &Account{
+			ID: id,
+		}
+	}
+	ub.ActionTriggers = actionTriggers
+	// init counters
+	ub.InitCounters()
+	if err := tpr.dataStorage.SetAccount(ub); err != nil {
+		return err
+	}
 	return nil
 }
 
+// loadAccountActionPlan adds the account to its action plan, returning the IDs of the actions scheduled
+func (tpr *TpReader) loadAccountActionPlan(accountAction *utils.TPAccountActions, lk *tpLookup) (actionIDs []string, err error) {
+	id := accountAction.KeyId()
+	// get old userBalanceIds
+	exitingAccountIds := make(utils.StringMap)
+	existingActionPlan, err := tpr.dataStorage.GetActionPlan(accountAction.ActionPlanId, true, utils.NonTransactional)
+	if err == nil && existingActionPlan != nil {
+		exitingAccountIds = existingActionPlan.AccountIDs
+	}
+	tpap, err := tpr.lr.GetTPActionPlans(tpr.tpid, accountAction.ActionPlanId)
+	if err != nil {
+		return nil, errors.New(err.Error() + " (ActionPlan): " + accountAction.ActionPlanId)
+	} else if len(tpap) == 0 {
+		return nil, fmt.Errorf("no action plan with id <%s>", accountAction.ActionPlanId)
+	}
+	aps := MapTPActionTimings(tpap)
+	var actionPlan *ActionPlan
+	ats := aps[accountAction.ActionPlanId]
+	for _, at := range ats {
+		// Check action exists before saving it inside actionTiming key
+		if actions, err := lk.tpActions(at.ActionsId); err != nil {
+			return nil, errors.New(err.Error() + " (Actions): " + at.ActionsId)
+		} else if len(actions[at.ActionsId]) == 0 {
+			return nil, fmt.Errorf("no action with id <%s>", at.ActionsId)
+		}
+		var t *utils.TPTiming
+		if at.TimingId != utils.ASAP {
+			if t, err = lk.timing(at.TimingId); err != nil {
+				return nil, errors.New(err.Error() + " (Timing): " + at.TimingId)
+			}
+		} else {
+			t = tpr.timings[at.TimingId] // *asap
+		}
+		if actionPlan == nil {
+			actionPlan = &ActionPlan{
+				Id: accountAction.ActionPlanId,
+			}
+		}
+		actionPlan.ActionTimings = append(actionPlan.ActionTimings, &ActionTiming{
+			Uuid:   utils.GenUUID(),
+			Weight: at.Weight,
+			Timing: &RateInterval{
+				Timing: &RITiming{
+					Months:    t.Months,
+					MonthDays: t.MonthDays,
+					WeekDays:  t.WeekDays,
+					StartTime: t.StartTime,
+				},
+			},
+			ActionsID: at.ActionsId,
+		})
+		// collect action ids from timings
+		actionIDs = append(actionIDs, at.ActionsId)
+		exitingAccountIds[id] = true
+		actionPlan.AccountIDs = exitingAccountIds
+	}
+	// write tasks
+	for _, at := range actionPlan.ActionTimings {
+		if at.IsASAP() {
+			for accID := range actionPlan.AccountIDs {
+				t := &Task{
+					Uuid:      utils.GenUUID(),
+					AccountID: accID,
+					ActionsID: at.ActionsID,
+				}
+				if err = tpr.dataStorage.PushTask(t); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	// write action plan
+	if err = tpr.dataStorage.SetActionPlan(accountAction.ActionPlanId, actionPlan, false, utils.NonTransactional); err != nil {
+		return nil, errors.New(err.Error() + " (SetActionPlan): " + accountAction.ActionPlanId)
+	}
+	if err = tpr.dataStorage.SetAccountActionPlans(id, []string{accountAction.ActionPlanId}, false); err != nil {
+		return nil, err
+	}
+	if err = tpr.dataStorage.CacheDataFromDB(utils.AccountActionPlansPrefix, []string{id}, true); err != nil {
+		return nil, err
+	}
+	return
+}
+
 func (tpr *TpReader) LoadAccountActions() (err error) {
 	tps, err := tpr.lr.GetTPAccountActions(&utils.TPAccountActions{TPid: tpr.tpid})
 	if err != nil {
@@ -1151,7 +1415,7 @@ func (tpr *TpReader) LoadAccountActions() (err error) {
 		if aa.ActionTriggersId != "" {
 			var exists bool
 			if aTriggers, exists = tpr.actionsTriggers[aa.ActionTriggersId]; !exists {
-				return fmt.Errorf("could not get action triggers for tag %s", aa.ActionTriggersId)
+				tpr.addDanglingRef("AccountActions", aaKeyID, "ActionTriggers", aa.ActionTriggersId)
 			}
 		}
 		ub := &Account{
@@ -1165,7 +1429,8 @@ func (tpr *TpReader) LoadAccountActions() (err error) {
 		if aa.ActionPlanId != "" {
 			actionPlan, exists := tpr.actionPlans[aa.ActionPlanId]
 			if !exists {
-				return fmt.Errorf("could not get action plan for tag %v", aa.ActionPlanId)
+				tpr.addDanglingRef("AccountActions", aaKeyID, "ActionPlan", aa.ActionPlanId)
+				continue
 			}
 			if actionPlan.AccountIDs == nil {
 				actionPlan.AccountIDs = make(utils.StringMap)
@@ -1251,8 +1516,14 @@ func (tpr *TpReader) LoadCdrStatsFiltered(tag string, save bool) (err error) {
 						atrs := make([]*ActionTrigger, len(atrsLst))
 						for idx, atr := range atrsLst {
 							minSleep, _ := utils.ParseDurationWithSecs(atr.MinSleep)
-							expTime, _ := utils.ParseTimeDetectLayout(atr.ExpirationDate, tpr.timezone)
-							actTime, _ := utils.ParseTimeDetectLayout(atr.ActivationDate, tpr.timezone)
+							expTime, err := utils.ParseTimeDetectLayoutStrict(atr.ExpirationDate, tpr.timezone)
+							if err != nil {
+								return errors.New(err.Error() + " (ActionTriggers): " + triggerTag)
+							}
+							actTime, err := utils.ParseTimeDetectLayoutStrict(atr.ActivationDate, tpr.timezone)
+							if err != nil {
+								return errors.New(err.Error() + " (ActionTriggers): " + triggerTag)
+							}
 							if atr.UniqueID == "" {
 								atr.UniqueID = utils.GenUUID()
 							}
@@ -1383,11 +1654,9 @@ func (tpr *TpReader) LoadCdrStatsFiltered(tag string, save bool) (err error) {
 					}
 
 					if tpact.Units != "" && tpact.Units != utils.ANY {
-						vf, err := utils.ParseBalanceFilterValue(tpact.Units)
-						if err != nil {
+						if err := acts[idx].Balance.SetUnits(tpact.Units); err != nil {
 							return err
 						}
-						acts[idx].Balance.Value = vf
 					}
 
 					if tpact.BalanceWeight != "" && tpact.BalanceWeight != utils.ANY {
@@ -1586,9 +1855,11 @@ func (tpr *TpReader) LoadAliases() error {
 	return err
 }
 
-func (tpr *TpReader) LoadResourceLimitsFiltered(tag string) error {
+// LoadResourceLimitsFiltered loads the ResourceLimits matching tag, on save writing them to dataDB together with their indexes.
+// A tag not found anymore in the tariff plan removes the ResourceLimit and its indexes from dataDB.
+func (tpr *TpReader) LoadResourceLimitsFiltered(tag string, save bool) error {
 	rls, err := tpr.lr.GetTPResourceLimits(tpr.tpid, tag)
-	if err != nil {
+	if err != nil && err != utils.ErrNotFound {
 		return err
 	}
 	mapRLs := make(map[string]*utils.TPResourceLimit)
@@ -1596,81 +1867,206 @@ func (tpr *TpReader) LoadResourceLimitsFiltered(tag string) error {
 		mapRLs[rl.ID] = rl
 	}
 	tpr.resLimits = mapRLs
-	return nil
-}
-
-func (tpr *TpReader) LoadResourceLimits() error {
-	return tpr.LoadResourceLimitsFiltered("")
-}
-
-func (tpr *TpReader) LoadAll() (err error) {
-	if err = tpr.LoadDestinations(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	if !save {
+		return nil
 	}
-	if err = tpr.LoadTimings(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	rlIdxr, err := NewReqFilterIndexer(tpr.dataStorage, utils.ResourceLimitsIndex)
+	if err != nil {
+		return err
 	}
-	if err = tpr.LoadRates(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	if tag != "" && len(mapRLs) == 0 { // deleted from tariff plan
+		if err := tpr.dataStorage.RemoveResourceLimit(tag, utils.NonTransactional); err != nil && err != utils.ErrNotFound {
+			return err
+		}
+		rlIdxr.RemoveItemFromIndex(tag)
 	}
-	if err = tpr.LoadDestinationRates(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for _, tpRL := range mapRLs {
+		rl, err := APItoResourceLimit(tpRL, tpr.timezone)
+		if err != nil {
+			return err
+		}
+		if err = tpr.dataStorage.SetResourceLimit(rl, utils.NonTransactional); err != nil {
+			return err
+		}
+		cache.RemKey(utils.ResourceLimitsPrefix+rl.ID, true, utils.NonTransactional)
+		rlIdxr.RemoveItemFromIndex(rl.ID) // filters might have changed
+		rlIdxr.IndexFilters(rl.ID, rl.Filters)
 	}
-	if err = tpr.LoadRatingPlans(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	if err := rlIdxr.StoreIndexes(); err != nil {
+		return err
 	}
-	if err = tpr.LoadRatingProfiles(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for idxKey := range rlIdxr.ChangedKeys() { // cached index matches are stale now
+		cache.RemKey(utils.ResourceLimitsIndex+idxKey, true, utils.NonTransactional)
 	}
-	if err = tpr.LoadSharedGroups(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	return nil
+}
+
+func (tpr *TpReader) LoadResourceLimits() error {
+	return tpr.LoadResourceLimitsFiltered("", false)
+}
+
+func (tpr *TpReader) LoadExchangeRates() error {
+	tps, err := tpr.lr.GetTPExchangeRates(tpr.tpid, "")
+	if err != nil {
+		return err
 	}
-	if err = tpr.LoadLCRs(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for _, tpER := range tps {
+		if _, err := APItoExchangeRate(tpER); err != nil {
+			return err
+		}
+		tpr.exchangeRates[utils.ConcatenatedKey(tpER.FromCurrency, tpER.ToCurrency)] = tpER
 	}
-	if err = tpr.LoadActions(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	return nil
+}
+
+func (tpr *TpReader) LoadHolidayCalendars() error {
+	tps, err := tpr.lr.GetTPHolidayCalendars(tpr.tpid, "")
+	if err != nil {
+		return err
 	}
-	if err = tpr.LoadActionPlans(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for _, tpHC := range tps {
+		if _, err := APItoHolidayCalendar(tpHC); err != nil {
+			return err
+		}
+		tpr.holidayCalendars[tpHC.ID] = tpHC
 	}
-	if err = tpr.LoadActionTriggers(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	return nil
+}
+
+func (tpr *TpReader) LoadDialPlans() error {
+	tps, err := tpr.lr.GetTPDialPlans(tpr.tpid, "")
+	if err != nil {
+		return err
 	}
-	if err = tpr.LoadAccountActions(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for _, tpDP := range tps {
+		if _, err := APItoDialPlan(tpDP); err != nil {
+			return err
+		}
+		tpr.dialPlans[tpDP.Tenant] = tpDP
 	}
-	if err = tpr.LoadDerivedChargers(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	return nil
+}
+
+func (tpr *TpReader) LoadAll() (err error) {
+	return tpr.LoadCategories(nil, nil)
+}
+
+// TPLoadCategories are the categories accepted by LoadCategories, in their loading order
+var TPLoadCategories = []string{utils.MetaDestinations, utils.MetaTimings, utils.MetaRates, utils.MetaDestinationRates,
+	utils.MetaRatingPlans, utils.MetaRatingProfiles, utils.MetaSharedGroups, utils.MetaLCRs, utils.MetaActions,
+	utils.MetaActionPlans, utils.MetaActionTriggers, utils.MetaAccountActions, utils.MetaDerivedChargers,
+	utils.MetaCdrStats, utils.MetaUsers, utils.MetaAliases, utils.MetaResourceLimits, utils.MetaExchangeRates,
+	utils.MetaHolidayCalendars, utils.MetaDialPlans}
+
+// tpLoadDependencies are the categories which need to be loaded together with the one used as key
+var tpLoadDependencies = map[string][]string{
+	utils.MetaDestinationRates: []string{utils.MetaRates},
+	utils.MetaRatingPlans:      []string{utils.MetaTimings, utils.MetaDestinationRates},
+	utils.MetaActionPlans:      []string{utils.MetaTimings, utils.MetaActions},
+	utils.MetaAccountActions:   []string{utils.MetaActionPlans, utils.MetaActionTriggers, utils.MetaActions},
+}
+
+// LoadCategories loads the categories in include, all if empty, skipping the ones in exclude
+// Categories required by the included ones are loaded as well, eg: *rating_plans pulls in *timings,
+// excluding one of them is refused
+func (tpr *TpReader) LoadCategories(include, exclude []string) (err error) {
+	loadFuncs := map[string]func() error{
+		utils.MetaDestinations:     tpr.LoadDestinations,
+		utils.MetaTimings:          tpr.LoadTimings,
+		utils.MetaRates:            tpr.LoadRates,
+		utils.MetaDestinationRates: tpr.LoadDestinationRates,
+		utils.MetaRatingPlans:      tpr.LoadRatingPlans,
+		utils.MetaRatingProfiles:   tpr.LoadRatingProfiles,
+		utils.MetaSharedGroups:     tpr.LoadSharedGroups,
+		utils.MetaLCRs:             tpr.LoadLCRs,
+		utils.MetaActions:          tpr.LoadActions,
+		utils.MetaActionPlans:      tpr.LoadActionPlans,
+		utils.MetaActionTriggers:   tpr.LoadActionTriggers,
+		utils.MetaAccountActions:   tpr.LoadAccountActions,
+		utils.MetaDerivedChargers:  tpr.LoadDerivedChargers,
+		utils.MetaCdrStats:         tpr.LoadCdrStats,
+		utils.MetaUsers:            tpr.LoadUsers,
+		utils.MetaAliases:          tpr.LoadAliases,
+		utils.MetaResourceLimits:   tpr.LoadResourceLimits,
+		utils.MetaExchangeRates:    tpr.LoadExchangeRates,
+		utils.MetaHolidayCalendars: tpr.LoadHolidayCalendars,
+		utils.MetaDialPlans:        tpr.LoadDialPlans,
+	}
+	for _, categ := range append(append([]string{}, include...), exclude...) {
+		if _, has := loadFuncs[categ]; !has {
+			return fmt.Errorf("unsupported load category: %s", categ)
+		}
+	}
+	toLoad := utils.NewStringMap(TPLoadCategories...)
+	if len(include) != 0 {
+		toLoad = utils.NewStringMap(include...)
+		for added := true; added; { // add dependencies until nothing changes
+			added = false
+			for categ := range toLoad {
+				for _, dep := range tpLoadDependencies[categ] {
+					if _, has := toLoad[dep]; !has {
+						toLoad[dep] = true
+						added = true
+					}
+				}
+			}
+		}
 	}
-	if err = tpr.LoadCdrStats(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for _, categ := range exclude {
+		delete(toLoad, categ)
 	}
-	if err = tpr.LoadUsers(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for _, categ := range TPLoadCategories {
+		if _, has := toLoad[categ]; !has {
+			continue
+		}
+		for _, dep := range tpLoadDependencies[categ] {
+			if _, has := toLoad[dep]; !has {
+				return fmt.Errorf("cannot exclude load category %s, required by %s", dep, categ)
+			}
+		}
 	}
-	if err = tpr.LoadAliases(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	for _, categ := range TPLoadCategories {
+		if _, has := toLoad[categ]; !has {
+			continue
+		}
+		if err = loadFuncs[categ](); err != nil && err.Error() != utils.NotFoundCaps {
+			return
+		}
+		if err = tpr.runHooks(TPHookAfterLoad, categ); err != nil {
+			return
+		}
 	}
-	if err = tpr.LoadResourceLimits(); err != nil && err.Error() != utils.NotFoundCaps {
-		return
+	return tpr.CheckIntegrity()
+}
+
+// addDanglingRef records a reference towards a missing object, the referencing one is skipped at load
+func (tpr *TpReader) addDanglingRef(objType, objID, refType, refID string) {
+	tpr.danglingRefs = append(tpr.danglingRefs, fmt.Sprintf("%s <%s> references missing %s <%s>", objType, objID, refType, refID))
+}
+
+// CheckIntegrity is the referential integrity phase run before writing into dataDb,
+// returns all the dangling references found over the loaded objects at once
+func (tpr *TpReader) CheckIntegrity() error {
+	if len(tpr.danglingRefs) == 0 {
+		return nil
 	}
-	return nil
+	return utils.NewErrDanglingReferences(tpr.danglingRefs)
 }
 
 func (tpr *TpReader) IsValid() bool {
 	valid := true
 	for rplTag, rpl := range tpr.ratingPlans {
-		if !rpl.isContinous() {
-			log.Printf("The rating plan %s is not covering all weekdays", rplTag)
-			valid = false
+		rpt := rpl.SanityReport()
+		if len(rpt.UncoveredWeekDays) != 0 {
+			log.Printf("The rating plan %s is not covering weekdays %v", rplTag, rpt.UncoveredWeekDays)
 		}
-		if crazyRate := rpl.getFirstUnsaneRating(); crazyRate != "" {
+		for _, crazyRate := range rpt.InvalidRatings {
 			log.Printf("The rate %s is invalid", crazyRate)
-			valid = false
 		}
-		if crazyTiming := rpl.getFirstUnsaneTiming(); crazyTiming != "" {
+		for _, crazyTiming := range rpt.InvalidTimings {
 			log.Printf("The timing %s is invalid", crazyTiming)
+		}
+		if !rpt.IsSane() {
 			valid = false
 		}
 	}
@@ -1678,6 +2074,9 @@ func (tpr *TpReader) IsValid() bool {
 }
 
 func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err error) {
+	if err = tpr.CheckIntegrity(); err != nil {
+		return
+	}
 	if tpr.dataStorage == nil {
 		return errors.New("no database connection")
 	}
@@ -1902,8 +2301,53 @@ func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err
 			log.Print("\t", rl.ID)
 		}
 	}
+	if verbose {
+		log.Print("ExchangeRates:")
+	}
+	for _, tpER := range tpr.exchangeRates {
+		er, err := APItoExchangeRate(tpER)
+		if err != nil {
+			return err
+		}
+		if err = tpr.dataStorage.SetExchangeRate(er, utils.NonTransactional); err != nil {
+			return err
+		}
+		if verbose {
+			log.Print("\t", er.ID)
+		}
+	}
+	if verbose {
+		log.Print("HolidayCalendars:")
+	}
+	for _, tpHC := range tpr.holidayCalendars {
+		hc, err := APItoHolidayCalendar(tpHC)
+		if err != nil {
+			return err
+		}
+		if err = tpr.dataStorage.SetHolidayCalendar(hc, utils.NonTransactional); err != nil {
+			return err
+		}
+		if verbose {
+			log.Print("\t", hc.ID)
+		}
+	}
+	if verbose {
+		log.Print("DialPlans:")
+	}
+	for _, tpDP := range tpr.dialPlans {
+		dp, err := APItoDialPlan(tpDP)
+		if err != nil {
+			return err
+		}
+		if err = tpr.dataStorage.SetDialPlan(dp, utils.NonTransactional); err != nil {
+			return err
+		}
+		if verbose {
+			log.Print("\t", dp.Tenant)
+		}
+	}
 	if !disable_reverse {
-		if len(tpr.destinations) > 0 {
+		if len(tpr.destinations) > 0 && !tpr.noReverses[utils.MetaDestinations] {
 			if verbose {
 				log.Print("Rebuilding reverse destinations")
 			}
@@ -1911,7 +2355,7 @@ func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err
 				return err
 			}
 		}
-		if len(tpr.acntActionPlans) > 0 {
+		if len(tpr.acntActionPlans) > 0 && !tpr.noReverses[utils.MetaAccountActionPlans] {
 			if verbose {
 				log.Print("Rebuilding account action plans")
 			}
@@ -1919,7 +2363,7 @@ func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err
 				return err
 			}
 		}
-		if len(tpr.aliases) > 0 {
+		if len(tpr.aliases) > 0 && !tpr.noReverses[utils.MetaAliases] {
 			if verbose {
 				log.Print("Rebuilding reverse aliases")
 			}
@@ -1939,6 +2383,7 @@ func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err
 				if rl, err := APItoResourceLimit(tpRL, tpr.timezone); err != nil {
 					return err
 				} else {
+					rlIdxr.RemoveItemFromIndex(rl.ID) // filters might have changed
 					rlIdxr.IndexFilters(rl.ID, rl.Filters)
 				}
 			}
@@ -1950,68 +2395,84 @@ func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err
 			}
 		}
 	}
+	for _, categ := range TPLoadCategories {
+		if err = tpr.runHooks(TPHookAfterWrite, categ); err != nil {
+			return
+		}
+	}
+	if tpSnapshotsSize > 0 {
+		if verbose {
+			log.Print("Writing tariff plan snapshot")
+		}
+		if _, err = tpr.writeSnapshot(); err != nil {
+			return
+		}
+	}
+	if tpr.reloadCache {
+		if verbose {
+			log.Print("Reloading cache")
+		}
+		if err = tpr.ReloadCache(flush); err != nil {
+			return
+		}
+	}
 	return
 }
 
-func (tpr *TpReader) ShowStatistics() {
-	// destinations
-	destCount := len(tpr.destinations)
-	log.Print("Destinations: ", destCount)
-	prefixDist := make(map[int]int, 50)
-	prefixCount := 0
-	for _, d := range tpr.destinations {
-		prefixDist[len(d.Prefixes)] += 1
-		prefixCount += len(d.Prefixes)
-	}
-	log.Print("Avg Prefixes: ", prefixCount/destCount)
-	log.Print("Prefixes distribution:")
-	for k, v := range prefixDist {
-		log.Printf("%d: %d", k, v)
-	}
-	// rating plans
-	rplCount := len(tpr.ratingPlans)
-	log.Print("Rating plans: ", rplCount)
-	destRatesDist := make(map[int]int, 50)
-	destRatesCount := 0
-	for _, rpl := range tpr.ratingPlans {
-		destRatesDist[len(rpl.DestinationRates)] += 1
-		destRatesCount += len(rpl.DestinationRates)
-	}
-	log.Print("Avg Destination Rates: ", destRatesCount/rplCount)
-	log.Print("Destination Rates distribution:")
-	for k, v := range destRatesDist {
-		log.Printf("%d: %d", k, v)
-	}
-	// rating profiles
-	rpfCount := len(tpr.ratingProfiles)
-	log.Print("Rating profiles: ", rpfCount)
-	activDist := make(map[int]int, 50)
-	activCount := 0
-	for _, rpf := range tpr.ratingProfiles {
-		activDist[len(rpf.RatingPlanActivations)] += 1
-		activCount += len(rpf.RatingPlanActivations)
-	}
-	log.Print("Avg Activations: ", activCount/rpfCount)
-	log.Print("Activation distribution:")
-	for k, v := range activDist {
-		log.Printf("%d: %d", k, v)
+// cacheReloadPrefixes are the cache partitions refreshed out of the loaded IDs
+var cacheReloadPrefixes = []string{utils.DESTINATION_PREFIX, utils.REVERSE_DESTINATION_PREFIX,
+	utils.RATING_PLAN_PREFIX, utils.RATING_PROFILE_PREFIX, utils.ACTION_PREFIX, utils.ACTION_PLAN_PREFIX,
+	utils.AccountActionPlansPrefix, utils.ACTION_TRIGGER_PREFIX, utils.SHARED_GROUP_PREFIX,
+	utils.DERIVEDCHARGERS_PREFIX, utils.LCR_PREFIX, utils.ALIASES_PREFIX, utils.REVERSE_ALIASES_PREFIX,
+	utils.ResourceLimitsPrefix}
+
+// SetCacheReload makes WriteToDatabase refresh the cache for the keys written,
+// through the ApierV1.ReloadCache of raterConn or within the local cache if raterConn is nil
+func (tpr *TpReader) SetCacheReload(reload bool, raterConn rpcclient.RpcClientConnection) {
+	tpr.reloadCache = reload
+	tpr.cacheConn = raterConn
+}
+
+// LoadedArgsCache returns the loaded IDs for each cache partition, as expected by the cache reload APIs
+func (tpr *TpReader) LoadedArgsCache() utils.ArgsCache {
+	loadedIDs := func(prfx string) *[]string {
+		ids, _ := tpr.GetLoadedIds(prfx)
+		return &ids
+	}
+	return utils.ArgsCache{
+		DestinationIDs:        loadedIDs(utils.DESTINATION_PREFIX),
+		ReverseDestinationIDs: loadedIDs(utils.REVERSE_DESTINATION_PREFIX),
+		RatingPlanIDs:         loadedIDs(utils.RATING_PLAN_PREFIX),
+		RatingProfileIDs:      loadedIDs(utils.RATING_PROFILE_PREFIX),
+		ActionIDs:             loadedIDs(utils.ACTION_PREFIX),
+		ActionPlanIDs:         loadedIDs(utils.ACTION_PLAN_PREFIX),
+		AccountActionPlanIDs:  loadedIDs(utils.AccountActionPlansPrefix),
+		ActionTriggerIDs:      loadedIDs(utils.ACTION_TRIGGER_PREFIX),
+		SharedGroupIDs:        loadedIDs(utils.SHARED_GROUP_PREFIX),
+		LCRids:                loadedIDs(utils.LCR_PREFIX),
+		DerivedChargerIDs:     loadedIDs(utils.DERIVEDCHARGERS_PREFIX),
+		AliasIDs:              loadedIDs(utils.ALIASES_PREFIX),
+		ReverseAliasIDs:       loadedIDs(utils.REVERSE_ALIASES_PREFIX),
+		ResourceLimitIDs:      loadedIDs(utils.ResourceLimitsPrefix),
 	}
-	// actions
-	log.Print("Actions: ", len(tpr.actions))
-	// action plans
-	log.Print("Action plans: ", len(tpr.actionPlans))
-	// action trigers
-	log.Print("Action trigers: ", len(tpr.actionsTriggers))
-	// account actions
-	log.Print("Account actions: ", len(tpr.accountActions))
-	// derivedChargers
-	log.Print("Derived Chargers: ", len(tpr.derivedChargers))
-	// lcr rules
-	log.Print("LCR rules: ", len(tpr.lcrs))
-	// cdr stats
-	log.Print("CDR stats: ", len(tpr.cdrStats))
-	// resource limits
-	log.Print("ResourceLimits: ", len(tpr.resLimits))
+}
+
+// ReloadCache refreshes the cached entries of the loaded keys in one go, flush empties the cache instead
+func (tpr *TpReader) ReloadCache(flush bool) (err error) {
+	if tpr.cacheConn != nil {
+		var reply string
+		return tpr.cacheConn.Call("ApierV1.ReloadCache",
+			utils.AttrReloadCache{ArgsCache: tpr.LoadedArgsCache(), FlushAll: flush}, &reply)
+	}
+	if flush {
+		cache.Flush()
+		return
+	}
+	prefixIDs := make(map[string][]string, len(cacheReloadPrefixes))
+	for _, prfx := range cacheReloadPrefixes {
+		prefixIDs[prfx], _ = tpr.GetLoadedIds(prfx)
+	}
+	return tpr.dataStorage.CacheDataGenerationFromDB(prefixIDs, true) // readers never see a half reloaded tariff plan
 }
 
 // Returns the identities loaded for a specific category, useful for cache reloads
@@ -2129,6 +2590,30 @@ func (tpr *TpReader) GetLoadedIds(categ string) ([]string, error) {
 			i++
 		}
 		return keys, nil
+	case utils.ExchangeRatesPrefix:
+		keys := make([]string, len(tpr.exchangeRates))
+		i := 0
+		for k := range tpr.exchangeRates {
+			keys[i] = k
+			i++
+		}
+		return keys, nil
+	case utils.HolidayCalendarsPrefix:
+		keys := make([]string, len(tpr.holidayCalendars))
+		i := 0
+		for k := range tpr.holidayCalendars {
+			keys[i] = k
+			i++
+		}
+		return keys, nil
+	case utils.DialPlansPrefix:
+		keys := make([]string, len(tpr.dialPlans))
+		i := 0
+		for k := range tpr.dialPlans {
+			keys[i] = k
+			i++
+		}
+		return keys, nil
 	case utils.ACTION_TRIGGER_PREFIX:
 		keys := make([]string, len(tpr.actionsTriggers))
 		i := 0
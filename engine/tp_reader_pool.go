@@ -0,0 +1,108 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sync"
+
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewTpReaderPool creates a pool of TpReaders loading out of lr into dataDB
+func NewTpReaderPool(dataDB DataDB, lr LoadReader, timezone string, tenantTimezones map[string]string) *TpReaderPool {
+	return &TpReaderPool{dataDB: dataDB, lr: lr, timezone: timezone, tenantTimezones: tenantTimezones}
+}
+
+// TpReaderPool hands out TpReaders so parallel loads never share the internal maps,
+// loads of the same TPID are serialized while different TPIDs are loaded in parallel
+type TpReaderPool struct {
+	dataDB          DataDB
+	lr              LoadReader
+	timezone        string
+	tenantTimezones map[string]string
+	readers         sync.Pool // released readers, reused by the next loads
+}
+
+// Load passes to loadFunc a TpReader reserved for tpid, the reader is released once loadFunc returns
+func (pool *TpReaderPool) Load(tpid string, loadFunc func(*TpReader) error) (err error) {
+	_, err = guardian.Guardian.Guard(func() (interface{}, error) {
+		tpr := pool.get(tpid)
+		defer pool.put(tpr)
+		return nil, loadFunc(tpr)
+	}, 0, utils.TpLoadLockPrefix+tpid)
+	return
+}
+
+// LoadMerged passes to loadFunc a TpReader merging tpids according to policy, loads of any of the tpids are serialized
+func (pool *TpReaderPool) LoadMerged(tpids []string, policy string, loadFunc func(*TpReader) error) (err error) {
+	tpr, err := NewMergedTpReader(pool.dataDB, pool.lr, tpids, policy, pool.timezone)
+	if err != nil {
+		return err
+	}
+	tpr.SetTenantTimezones(pool.tenantTimezones)
+	lockIDs := make([]string, len(tpids))
+	for i, tpid := range tpids {
+		lockIDs[i] = utils.TpLoadLockPrefix + tpid
+	}
+	_, err = guardian.Guardian.Guard(func() (interface{}, error) {
+		return nil, loadFunc(tpr)
+	}, 0, lockIDs...)
+	return
+}
+
+func (pool *TpReaderPool) get(tpid string) (tpr *TpReader) {
+	if rdr := pool.readers.Get(); rdr != nil {
+		tpr = rdr.(*TpReader)
+		tpr.tpid = tpid
+	} else {
+		tpr = NewTpReader(pool.dataDB, pool.lr, tpid, pool.timezone)
+	}
+	tpr.SetTenantTimezones(pool.tenantTimezones)
+	return
+}
+
+func (pool *TpReaderPool) put(tpr *TpReader) {
+	tpr.Init() // release the loaded data
+	tpr.SetCacheReload(false, nil)
+	tpr.SetCompressDestinations(false)
+	tpr.SetDisabledReverses(nil)
+	tpr.hooks = nil // registered for the released load only
+	pool.readers.Put(tpr)
+}
+
+var (
+	tpLoadListeners   []func()
+	tpLoadListenersMu sync.RWMutex
+)
+
+// RegisterTPLoadListener registers f to be called after each tariff plan load
+func RegisterTPLoadListener(f func()) {
+	tpLoadListenersMu.Lock()
+	tpLoadListeners = append(tpLoadListeners, f)
+	tpLoadListenersMu.Unlock()
+}
+
+// NotifyTPLoaded informs the listeners, asynchronously, that a tariff plan load completed
+func NotifyTPLoaded() {
+	tpLoadListenersMu.RLock()
+	defer tpLoadListenersMu.RUnlock()
+	for _, f := range tpLoadListeners {
+		go f()
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestTpReaderPoolLoad(t *testing.T) {
+	pool := NewTpReaderPool(dataStorage, csvr.lr, "", nil)
+	var running int32
+	var wg sync.WaitGroup
+	for _, tpid := range []string{"TP1", "TP1", "TP2"} {
+		wg.Add(1)
+		go func(tpid string) {
+			defer wg.Done()
+			if err := pool.Load(tpid, func(tpr *TpReader) error {
+				if tpid == "TP1" {
+					if atomic.AddInt32(&running, 1) != 1 {
+						t.Error("Parallel loads for the same TPID")
+					}
+					defer atomic.AddInt32(&running, -1)
+				}
+				if tpr.tpid != tpid {
+					t.Errorf("Expecting tpid: %s, received: %s", tpid, tpr.tpid)
+				}
+				if _, has := tpr.timings[utils.ASAP]; !has {
+					t.Error("Missing default timings")
+				}
+				time.Sleep(10 * time.Millisecond)
+				return tpr.LoadDestinations()
+			}); err != nil {
+				t.Error(err)
+			}
+		}(tpid)
+	}
+	wg.Wait()
+	pool.Load("TP3", func(tpr *TpReader) error {
+		if len(tpr.destinations) != 0 {
+			t.Errorf("Data not released: %+v", tpr.destinations)
+		}
+		return tpr.RegisterHook(TPHookAfterLoad, utils.MetaDestinations, func(*TpReader, string) error { return nil })
+	})
+	pool.Load("TP3", func(tpr *TpReader) error {
+		if len(tpr.hooks) != 0 {
+			t.Errorf("Hooks not released: %+v", tpr.hooks)
+		}
+		return nil
+	})
+}
+
+func TestTpLoadListeners(t *testing.T) {
+	notified := make(chan struct{}, 2)
+	RegisterTPLoadListener(func() { notified <- struct{}{} })
+	RegisterTPLoadListener(func() { notified <- struct{}{} })
+	NotifyTPLoaded()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-notified:
+		case <-time.After(time.Second):
+			t.Fatal("listener not notified on tariff plan load")
+		}
+	}
+}
+
+type testTPLoadNotifier chan *TPLoadEvent
+
+func (tln testTPLoadNotifier) NotifyTPLoad(ev *TPLoadEvent) error {
+	tln <- ev
+	return nil
+}
+
+func TestTPLoadNotifications(t *testing.T) {
+	ms, _ := NewMapStorage()
+	ms.SetDestination(&Destination{Id: "DST_OLD", Prefixes: []string{"49"}}, utils.NonTransactional)
+	tpr := NewTpReader(ms, csvr.lr, "", "")
+	tpr.destinations["DST_OLD"] = &Destination{Id: "DST_OLD", Prefixes: []string{"49", "40"}}
+	tpr.destinations["DST_NEW"] = &Destination{Id: "DST_NEW", Prefixes: []string{"41"}}
+	tpr.users["cgrates.org:user1"] = &UserProfile{Tenant: "cgrates.org", UserName: "user1"}
+	diff := tpr.Diff()
+	eDiff := TPLoadDiff{
+		utils.MetaDestinations: &TPLoadDiffEntry{Loaded: 2, Added: 1, Updated: 1},
+		utils.MetaUsers:        &TPLoadDiffEntry{Loaded: 1},
+	}
+	if !reflect.DeepEqual(eDiff, diff) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eDiff), utils.ToJSON(diff))
+	}
+	tln := make(testTPLoadNotifier, 2)
+	SetTPLoadNotifier(tln, "engine1")
+	defer SetTPLoadNotifier(nil, "")
+	NotifyTPLoadDone("TEST_TP", diff, errors.New("invalid data"))
+	select {
+	case ev := <-tln:
+		if ev.Event != utils.MetaTPLoadFailed || ev.InstanceID != "engine1" || ev.Source != "TEST_TP" ||
+			ev.Error != "invalid data" || !reflect.DeepEqual(ev.Diff, diff) {
+			t.Errorf("Unexpected event: %s", utils.ToJSON(ev))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No tariff plan load event received")
+	}
+	NotifyTPLoadDone("TEST_TP", diff, nil)
+	select {
+	case ev := <-tln:
+		if ev.Event != utils.MetaTPLoadSucceeded || ev.Error != "" {
+			t.Errorf("Unexpected event: %s", utils.ToJSON(ev))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No tariff plan load event received")
+	}
+}
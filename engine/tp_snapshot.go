@@ -0,0 +1,197 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+var tpSnapshotsSize int // number of tariff plan snapshots to keep, 0 disables snapshots
+
+// SetTPSnapshotsSize sets the number of rating snapshots kept in DataDB, 0 disables snapshotting
+func SetTPSnapshotsSize(size int) {
+	tpSnapshotsSize = size
+}
+
+// TPSnapshot is the rating configuration written to DataDB by one TpReader.WriteToDatabase
+type TPSnapshot struct {
+	ID             string // version ID, sorts by creation time
+	TPid           string
+	SnapshotTime   time.Time
+	ValidUntil     time.Time           // SnapshotTime of the next snapshot, populated on listing, zero for the current one
+	LoadedIDs      map[string][]string // manifest of the IDs loaded, indexed on category prefix
+	Destinations   []*Destination
+	RatingPlans    []*RatingPlan
+	RatingProfiles []*RatingProfile
+	LCRs           []*LCR
+}
+
+// Manifest returns the snapshot without the rating data
+func (tps *TPSnapshot) Manifest() *TPSnapshot {
+	return &TPSnapshot{ID: tps.ID, TPid: tps.TPid, SnapshotTime: tps.SnapshotTime, LoadedIDs: tps.LoadedIDs}
+}
+
+// snapshotCategories are the prefixes recorded in the snapshot manifest
+var snapshotCategories = []string{utils.DESTINATION_PREFIX, utils.REVERSE_DESTINATION_PREFIX, utils.RATING_PLAN_PREFIX,
+	utils.RATING_PROFILE_PREFIX, utils.ACTION_PREFIX, utils.ACTION_PLAN_PREFIX, utils.AccountActionPlansPrefix,
+	utils.SHARED_GROUP_PREFIX, utils.DERIVEDCHARGERS_PREFIX, utils.LCR_PREFIX, utils.CDR_STATS_PREFIX,
+	utils.USERS_PREFIX, utils.ALIASES_PREFIX, utils.ResourceLimitsPrefix}
+
+// writeSnapshot stores the rating data loaded by the reader as a new version, discarding the oldest ones over tpSnapshotsSize
+func (tpr *TpReader) writeSnapshot() (tps *TPSnapshot, err error) {
+	now := time.Now()
+	tps = &TPSnapshot{
+		ID:           strconv.FormatInt(now.UnixNano(), 10),
+		TPid:         tpr.tpid,
+		SnapshotTime: now,
+		LoadedIDs:    make(map[string][]string),
+	}
+	for _, categ := range snapshotCategories {
+		if ids, _ := tpr.GetLoadedIds(categ); len(ids) != 0 {
+			sort.Strings(ids)
+			tps.LoadedIDs[categ] = ids
+		}
+	}
+	for _, d := range tpr.destinations {
+		tps.Destinations = append(tps.Destinations, d)
+	}
+	for _, rp := range tpr.ratingPlans {
+		tps.RatingPlans = append(tps.RatingPlans, rp)
+	}
+	for _, rpf := range tpr.ratingProfiles {
+		tps.RatingProfiles = append(tps.RatingProfiles, rpf)
+	}
+	for _, lcr := range tpr.lcrs {
+		tps.LCRs = append(tps.LCRs, lcr)
+	}
+	if err = tpr.dataStorage.SetTPSnapshot(tps); err != nil {
+		return
+	}
+	ids, err := tpr.dataStorage.GetTPSnapshotIDs()
+	if err != nil {
+		return
+	}
+	sort.Strings(ids)
+	for len(ids) > tpSnapshotsSize {
+		if err = tpr.dataStorage.RemoveTPSnapshot(ids[0]); err != nil {
+			return
+		}
+		cache.RemKey(utils.TPSnapshotPrefix+ids[0], true, utils.NonTransactional)
+		ids = ids[1:]
+	}
+	return
+}
+
+// GetTPSnapshots returns the manifests of the snapshots stored in dataDB, newest first
+func GetTPSnapshots(dataDB DataDB) (snapshots []*TPSnapshot, err error) {
+	ids, err := dataDB.GetTPSnapshotIDs()
+	if err != nil {
+		return
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	for i, id := range ids {
+		tps, err := dataDB.GetTPSnapshot(id)
+		if err != nil {
+			return nil, err
+		}
+		mnfst := tps.Manifest()
+		if i != 0 {
+			mnfst.ValidUntil = snapshots[i-1].SnapshotTime
+		}
+		snapshots = append(snapshots, mnfst)
+	}
+	return
+}
+
+// RollbackTPSnapshot writes back the rating data of snapshot id, after removing the destinations, rating plans,
+// rating profiles and LCRs loaded by the newer snapshots which are not part of it
+func RollbackTPSnapshot(dataDB DataDB, id string) (tps *TPSnapshot, err error) {
+	if tps, err = dataDB.GetTPSnapshot(id); err != nil {
+		return
+	}
+	ids, err := dataDB.GetTPSnapshotIDs()
+	if err != nil {
+		return
+	}
+	removers := []struct {
+		categ  string
+		remove func(string, string) error
+	}{
+		{utils.RATING_PROFILE_PREFIX, dataDB.RemoveRatingProfile},
+		{utils.RATING_PLAN_PREFIX, dataDB.RemoveRatingPlan},
+		{utils.LCR_PREFIX, dataDB.RemoveLCR},
+		{utils.DESTINATION_PREFIX, dataDB.RemoveDestination},
+	}
+	keepIDs := make(map[string]utils.StringMap, len(removers))
+	for _, rmv := range removers {
+		keepIDs[rmv.categ] = utils.NewStringMap(tps.LoadedIDs[rmv.categ]...)
+	}
+	for _, newerID := range ids {
+		if newerID <= id {
+			continue
+		}
+		newer, err := dataDB.GetTPSnapshot(newerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rmv := range removers {
+			for _, itmID := range newer.LoadedIDs[rmv.categ] {
+				if _, has := keepIDs[rmv.categ][itmID]; has {
+					continue
+				}
+				if err := rmv.remove(itmID, utils.NonTransactional); err != nil && err != utils.ErrNotFound {
+					return nil, err
+				}
+				keepIDs[rmv.categ][itmID] = true // no need to remove it twice
+			}
+		}
+	}
+	for _, d := range tps.Destinations {
+		oldDest, err := dataDB.GetDestination(d.Id, true, utils.NonTransactional)
+		if err != nil && err != utils.ErrNotFound {
+			return nil, err
+		}
+		if err := dataDB.SetDestination(d, utils.NonTransactional); err != nil {
+			return nil, err
+		}
+		if err := dataDB.UpdateReverseDestination(oldDest, d, utils.NonTransactional); err != nil {
+			return nil, err
+		}
+	}
+	for _, rp := range tps.RatingPlans {
+		if err = dataDB.SetRatingPlan(rp, utils.NonTransactional); err != nil {
+			return
+		}
+	}
+	for _, rpf := range tps.RatingProfiles {
+		if err = dataDB.SetRatingProfile(rpf, utils.NonTransactional); err != nil {
+			return
+		}
+	}
+	for _, lcr := range tps.LCRs {
+		if err = dataDB.SetLCR(lcr, utils.NonTransactional); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,104 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestTPSnapshotWriteRollback(t *testing.T) {
+	dataDB, _ := NewMapStorage()
+	SetTPSnapshotsSize(2)
+	defer SetTPSnapshotsSize(0)
+	tpr := NewTpReader(dataDB, csvr.lr, testTPID, "")
+	if err := tpr.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpr.WriteToDatabase(false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err := GetTPSnapshots(dataDB)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(snapshots) != 1 || len(snapshots[0].RatingPlans) != 0 ||
+		len(snapshots[0].LoadedIDs[utils.RATING_PLAN_PREFIX]) != len(tpr.ratingPlans) {
+		t.Fatalf("Unexpected snapshots: %s", utils.ToJSON(snapshots))
+	}
+	firstID := snapshots[0].ID
+	// second load adds a destination, a rating plan, a rating profile and a LCR
+	dst := &Destination{Id: "DST_ROLLBACK", Prefixes: []string{"4917"}}
+	tpr.destinations[dst.Id] = dst
+	rp := &RatingPlan{Id: "RP_ROLLBACK"}
+	tpr.ratingPlans[rp.Id] = rp
+	rpf := &RatingProfile{Id: "*out:cgrates.org:call:rollback",
+		RatingPlanActivations: RatingPlanActivations{&RatingPlanActivation{RatingPlanId: rp.Id}}}
+	tpr.ratingProfiles[rpf.Id] = rpf
+	lcr := &LCR{Direction: utils.OUT, Tenant: "cgrates.org", Category: "call", Account: "rollback", Subject: "*any"}
+	tpr.lcrs[lcr.GetId()] = lcr
+	if err := tpr.WriteToDatabase(false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataDB.GetDestination(dst.Id, true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	if _, err := dataDB.GetRatingPlan(rp.Id, true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	if _, err := dataDB.GetRatingProfile(rpf.Id, true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	if _, err := dataDB.GetLCR(lcr.GetId(), true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	if _, err := RollbackTPSnapshot(dataDB, firstID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataDB.GetDestination(dst.Id, true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+	if ids, err := dataDB.GetReverseDestination(utils.ReverseDestinationKey("4917"), true, utils.NonTransactional); err == nil && utils.IsSliceMember(ids, dst.Id) {
+		t.Errorf("Unexpected reverse destination: %v", ids)
+	}
+	if _, err := dataDB.GetRatingPlan(rp.Id, true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+	if _, err := dataDB.GetRatingProfile(rpf.Id, true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+	if _, err := dataDB.GetLCR(lcr.GetId(), true, utils.NonTransactional); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+	// the data of the restored snapshot is kept
+	if _, err := dataDB.GetRatingPlan("STANDARD", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	}
+	// third load pushes the oldest snapshot out
+	if err := tpr.WriteToDatabase(false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := dataDB.GetTPSnapshotIDs(); err != nil {
+		t.Error(err)
+	} else if len(ids) != 2 {
+		t.Errorf("Unexpected snapshot IDs: %v", ids)
+	}
+	if _, err := dataDB.GetTPSnapshot(firstID); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+// TPStats summarizes the data loaded by a TpReader
+type TPStats struct {
+	Destinations                 int
+	AvgPrefixes                  float64
+	PrefixesDistribution         map[int]int // number of prefixes: number of destinations
+	RatingPlans                  int
+	AvgDestinationRates          float64
+	DestinationRatesDistribution map[int]int // number of destination rates: number of rating plans
+	RateIntervalsDistribution    map[int]int // number of rate intervals: number of rates inside rating plans
+	RatingProfiles               int
+	AvgActivations               float64
+	ActivationsDistribution      map[int]int // number of activations: number of rating profiles
+	Actions                      int
+	ActionPlans                  int
+	ActionTriggers               int
+	AccountActions               int
+	SharedGroups                 int
+	DerivedChargers              int
+	LCRs                         int
+	CdrStats                     int
+	Users                        int
+	Aliases                      int
+	ResourceLimits               int
+	ExchangeRates                int
+	HolidayCalendars             int
+	DialPlans                    int
+	MemoryEstimates              map[string]int // encoded size in bytes per data type
+}
+
+// avgPerItem returns total/count without failing on empty data
+func avgPerItem(total, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+// Statistics builds the TPStats out of the data loaded so far
+func (tpr *TpReader) Statistics() (stats *TPStats) {
+	stats = &TPStats{
+		Destinations:                 len(tpr.destinations),
+		PrefixesDistribution:         make(map[int]int),
+		RatingPlans:                  len(tpr.ratingPlans),
+		DestinationRatesDistribution: make(map[int]int),
+		RateIntervalsDistribution:    make(map[int]int),
+		RatingProfiles:               len(tpr.ratingProfiles),
+		ActivationsDistribution:      make(map[int]int),
+		Actions:                      len(tpr.actions),
+		ActionPlans:                  len(tpr.actionPlans),
+		ActionTriggers:               len(tpr.actionsTriggers),
+		AccountActions:               len(tpr.accountActions),
+		SharedGroups:                 len(tpr.sharedGroups),
+		DerivedChargers:              len(tpr.derivedChargers),
+		LCRs:                         len(tpr.lcrs),
+		CdrStats:                     len(tpr.cdrStats),
+		Users:                        len(tpr.users),
+		Aliases:                      len(tpr.aliases),
+		ResourceLimits:               len(tpr.resLimits),
+		ExchangeRates:                len(tpr.exchangeRates),
+		HolidayCalendars:             len(tpr.holidayCalendars),
+		DialPlans:                    len(tpr.dialPlans),
+		MemoryEstimates:              make(map[string]int),
+	}
+	var prefixCount int
+	for _, d := range tpr.destinations {
+		stats.PrefixesDistribution[len(d.Prefixes)] += 1
+		prefixCount += len(d.Prefixes)
+	}
+	stats.AvgPrefixes = avgPerItem(prefixCount, stats.Destinations)
+	var destRatesCount int
+	for _, rpl := range tpr.ratingPlans {
+		stats.DestinationRatesDistribution[len(rpl.DestinationRates)] += 1
+		destRatesCount += len(rpl.DestinationRates)
+		for _, rt := range rpl.Ratings {
+			stats.RateIntervalsDistribution[len(rt.Rates)] += 1
+		}
+	}
+	stats.AvgDestinationRates = avgPerItem(destRatesCount, stats.RatingPlans)
+	var activCount int
+	for _, rpf := range tpr.ratingProfiles {
+		stats.ActivationsDistribution[len(rpf.RatingPlanActivations)] += 1
+		activCount += len(rpf.RatingPlanActivations)
+	}
+	stats.AvgActivations = avgPerItem(activCount, stats.RatingProfiles)
+	ms := NewCodecMsgpackMarshaler()
+	for name, data := range map[string]interface{}{
+		"Destinations":     tpr.destinations,
+		"RatingPlans":      tpr.ratingPlans,
+		"RatingProfiles":   tpr.ratingProfiles,
+		"Actions":          tpr.actions,
+		"ActionPlans":      tpr.actionPlans,
+		"ActionTriggers":   tpr.actionsTriggers,
+		"AccountActions":   tpr.accountActions,
+		"SharedGroups":     tpr.sharedGroups,
+		"DerivedChargers":  tpr.derivedChargers,
+		"LCRs":             tpr.lcrs,
+		"CdrStats":         tpr.cdrStats,
+		"Users":            tpr.users,
+		"Aliases":          tpr.aliases,
+		"ResourceLimits":   tpr.resLimits,
+		"ExchangeRates":    tpr.exchangeRates,
+		"HolidayCalendars": tpr.holidayCalendars,
+		"DialPlans":        tpr.dialPlans,
+	} {
+		if b, err := ms.Marshal(data); err == nil {
+			stats.MemoryEstimates[name] = len(b)
+		}
+	}
+	return
+}
@@ -0,0 +1,106 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+var tpVariableRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// TPVariables resolves the ${NAME} references inside the tariff plan sources,
+// allowing the same template to be loaded for multiple tenants/brands
+type TPVariables struct {
+	Values map[string]string // explicit values, taking precedence over environment
+	UseEnv bool              // resolve names missing from Values out of the environment
+}
+
+func (tpv *TPVariables) lookup(name string) (string, bool) {
+	if val, has := tpv.Values[name]; has {
+		return val, true
+	}
+	if tpv.UseEnv {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+// Expand replaces the variable references inside value, erroring on undefined ones
+func (tpv *TPVariables) Expand(value string) (string, error) {
+	if tpv == nil || !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var errUndefined error
+	expanded := tpVariableRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		val, has := tpv.lookup(name)
+		if !has && errUndefined == nil {
+			errUndefined = fmt.Errorf("undefined variable <%s>", name)
+		}
+		return val
+	})
+	if errUndefined != nil {
+		return "", errUndefined
+	}
+	return expanded, nil
+}
+
+// expandRecord expands the variables inside each value of a csv record
+func (tpv *TPVariables) expandRecord(record []string) error {
+	for i, val := range record {
+		expanded, err := tpv.Expand(val)
+		if err != nil {
+			return &csvFieldError{index: i, err: err}
+		}
+		record[i] = expanded
+	}
+	return nil
+}
+
+// LoadTPVariablesFile reads the NAME=VALUE definitions out of a values file,
+// ignoring empty lines and the ones starting with the comment character
+func LoadTPVariablesFile(fPath string) (map[string]string, error) {
+	fp, err := os.Open(fPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	vals := make(map[string]string)
+	scanner := bufio.NewScanner(fp)
+	for lnNr := 1; scanner.Scan(); lnNr++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, string(utils.COMMENT_CHAR)) {
+			continue
+		}
+		nameVal := strings.SplitN(line, "=", 2)
+		if len(nameVal) != 2 || len(strings.TrimSpace(nameVal[0])) == 0 {
+			return nil, fmt.Errorf("file <%s>, line %d: invalid variable definition <%s>", fPath, lnNr, line)
+		}
+		vals[strings.TrimSpace(nameVal[0])] = strings.TrimSpace(nameVal[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
@@ -0,0 +1,94 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestTPVariablesExpand(t *testing.T) {
+	os.Setenv("CGR_TEST_TPVAR", "envVal")
+	defer os.Unsetenv("CGR_TEST_TPVAR")
+	tpVars := &TPVariables{Values: map[string]string{"TENANT": "cgrates.org", "PEAK_RATE": "0.2"}}
+	if rcv, err := tpVars.Expand("RT_${TENANT}_${PEAK_RATE}"); err != nil {
+		t.Error(err)
+	} else if rcv != "RT_cgrates.org_0.2" {
+		t.Errorf("Received: %s", rcv)
+	}
+	if rcv, err := tpVars.Expand("~Account:s/^(\\d+)$/$1/"); err != nil { // no braces, no expansion
+		t.Error(err)
+	} else if rcv != "~Account:s/^(\\d+)$/$1/" {
+		t.Errorf("Received: %s", rcv)
+	}
+	if _, err := tpVars.Expand("${CGR_TEST_TPVAR}"); err == nil || err.Error() != "undefined variable <CGR_TEST_TPVAR>" {
+		t.Error(err)
+	}
+	tpVars.UseEnv = true
+	if rcv, err := tpVars.Expand("${CGR_TEST_TPVAR}"); err != nil {
+		t.Error(err)
+	} else if rcv != "envVal" {
+		t.Errorf("Received: %s", rcv)
+	}
+}
+
+func TestLoadTPVariablesFile(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "tpvars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	fPath := path.Join(tmpDir, "vars.env")
+	if err := ioutil.WriteFile(fPath, []byte("# brand values\nTENANT = cgrates.org\n\nPEAK_RATE=0.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	eVals := map[string]string{"TENANT": "cgrates.org", "PEAK_RATE": "0.2"}
+	if vals, err := LoadTPVariablesFile(fPath); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eVals, vals) {
+		t.Errorf("Expecting: %+v, received: %+v", eVals, vals)
+	}
+	if err := ioutil.WriteFile(fPath, []byte("TENANT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTPVariablesFile(fPath); err == nil {
+		t.Error("Expecting error on invalid definition")
+	}
+}
+
+func TestCSVStorageVariables(t *testing.T) {
+	rates := `RT_${BRAND},0,${PEAK_RATE},60s,1s,0s`
+	csvStorage := NewStringCSVStorage(',', "", "", rates, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "")
+	csvStorage.SetVariables(&TPVariables{Values: map[string]string{"BRAND": "GOLD", "PEAK_RATE": "0.2"}})
+	if tpRates, err := csvStorage.GetTPRates("TEST", ""); err != nil {
+		t.Fatal(err)
+	} else if len(tpRates) != 1 || tpRates[0].ID != "RT_GOLD" || tpRates[0].RateSlots[0].Rate != 0.2 {
+		t.Errorf("Unexpected rates: %s", utils.ToJSON(tpRates))
+	}
+	csvStorage = NewStringCSVStorage(',', "", "", rates, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "")
+	csvStorage.SetVariables(&TPVariables{Values: map[string]string{"BRAND": "GOLD"}})
+	if _, err := csvStorage.GetTPRates("TEST", ""); err == nil ||
+		err.Error() != "line 1, column 3: undefined variable <PEAK_RATE>" {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,368 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// TPGenTier is one group of destinations sharing the same prices
+type TPGenTier struct {
+	ID            string
+	Prefixes      []string
+	ConnectFee    map[string]float64 // indexed on currency
+	PeakRate      map[string]float64
+	OffPeakRate   map[string]float64
+	RateUnit      string
+	RateIncrement string
+}
+
+// TPGenPeakHours defines the peak interval, everything else is considered off-peak
+type TPGenPeakHours struct {
+	WeekDays  []int  // 0 for Sunday
+	StartTime string // eg: 08:00:00
+	EndTime   string
+}
+
+// TPGenSpec is the input of the tariff plan scaffolding generator
+// Each currency gets its own rating plan, reachable through a rating subject named after the currency,
+// with only one currency the rating profile uses the *any subject
+type TPGenSpec struct {
+	TPid           string
+	Tenant         string
+	Category       string
+	ActivationTime string
+	Currencies     []string
+	PeakHours      TPGenPeakHours
+	Tiers          []*TPGenTier
+}
+
+// Generated timing IDs
+const (
+	TPGenPeak           = "PEAK"
+	TPGenOffPeakMorning = "OFFPEAK_MORNING"
+	TPGenOffPeakEvening = "OFFPEAK_EVENING"
+	TPGenOffPeakDays    = "OFFPEAK_DAYS"
+)
+
+func (spec *TPGenSpec) validate() error {
+	if missing := utils.MissingStructFields(spec, []string{"TPid", "Tenant", "Category", "Currencies", "Tiers"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if len(spec.PeakHours.WeekDays) == 0 || spec.PeakHours.StartTime == "" || spec.PeakHours.EndTime == "" {
+		return utils.NewErrMandatoryIeMissing("PeakHours")
+	}
+	if spec.PeakHours.StartTime >= spec.PeakHours.EndTime {
+		return errors.New("PeakHours StartTime should be before EndTime")
+	}
+	for _, wd := range spec.PeakHours.WeekDays {
+		if wd < 0 || wd > 6 {
+			return fmt.Errorf("invalid PeakHours week day: %d", wd)
+		}
+	}
+	for _, tier := range spec.Tiers {
+		if tier.ID == "" || len(tier.Prefixes) == 0 {
+			return fmt.Errorf("tier <%s> needs ID and Prefixes", tier.ID)
+		}
+		for _, cur := range spec.Currencies {
+			if _, has := tier.PeakRate[cur]; !has {
+				return fmt.Errorf("tier <%s> misses PeakRate for currency <%s>", tier.ID, cur)
+			}
+			if _, has := tier.OffPeakRate[cur]; !has {
+				return fmt.Errorf("tier <%s> misses OffPeakRate for currency <%s>", tier.ID, cur)
+			}
+		}
+	}
+	return nil
+}
+
+// timings returns the peak timing together with the off-peak ones covering the rest of the week
+func (spec *TPGenSpec) timings() []*utils.ApierTPTiming {
+	peakDays := make([]string, len(spec.PeakHours.WeekDays))
+	isPeakDay := make(map[int]bool)
+	for i, wd := range spec.PeakHours.WeekDays {
+		peakDays[i] = strconv.Itoa(wd)
+		isPeakDay[wd] = true
+	}
+	var offPeakDays []string
+	for wd := 0; wd < 7; wd++ {
+		if !isPeakDay[wd] {
+			offPeakDays = append(offPeakDays, strconv.Itoa(wd))
+		}
+	}
+	peakWeekDays := strings.Join(peakDays, utils.INFIELD_SEP)
+	tms := []*utils.ApierTPTiming{
+		&utils.ApierTPTiming{TPid: spec.TPid, ID: TPGenOffPeakMorning, Years: utils.ANY, Months: utils.ANY,
+			MonthDays: utils.ANY, WeekDays: peakWeekDays, Time: "00:00:00"},
+		&utils.ApierTPTiming{TPid: spec.TPid, ID: TPGenPeak, Years: utils.ANY, Months: utils.ANY,
+			MonthDays: utils.ANY, WeekDays: peakWeekDays, Time: spec.PeakHours.StartTime},
+		&utils.ApierTPTiming{TPid: spec.TPid, ID: TPGenOffPeakEvening, Years: utils.ANY, Months: utils.ANY,
+			MonthDays: utils.ANY, WeekDays: peakWeekDays, Time: spec.PeakHours.EndTime},
+	}
+	if len(offPeakDays) != 0 {
+		tms = append(tms, &utils.ApierTPTiming{TPid: spec.TPid, ID: TPGenOffPeakDays, Years: utils.ANY, Months: utils.ANY,
+			MonthDays: utils.ANY, WeekDays: strings.Join(offPeakDays, utils.INFIELD_SEP), Time: "00:00:00"})
+	}
+	return tms
+}
+
+// GenerateTP builds a consistent set of tariff plan records out of spec, indexed on the .csv file name
+func GenerateTP(spec *TPGenSpec) (map[string][]interface{}, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	tpData := make(map[string][]interface{})
+	for _, tier := range spec.Tiers {
+		for _, mdl := range APItoModelDestination(&utils.TPDestination{TPid: spec.TPid, ID: "DST_" + tier.ID, Prefixes: tier.Prefixes}) {
+			tpData[utils.DESTINATIONS_CSV] = append(tpData[utils.DESTINATIONS_CSV], mdl)
+		}
+	}
+	tms := spec.timings()
+	for _, tm := range tms {
+		tpData[utils.TIMINGS_CSV] = append(tpData[utils.TIMINGS_CSV], APItoModelTiming(tm))
+	}
+	actTime := spec.ActivationTime
+	if actTime == "" {
+		actTime = "2017-01-01T00:00:00Z"
+	}
+	for _, cur := range spec.Currencies {
+		drPeak := &utils.TPDestinationRate{TPid: spec.TPid, ID: "DR_" + cur + "_PEAK"}
+		drOffPeak := &utils.TPDestinationRate{TPid: spec.TPid, ID: "DR_" + cur + "_OFFPEAK"}
+		for _, tier := range spec.Tiers {
+			rateUnit, rateIncrement := tier.RateUnit, tier.RateIncrement
+			if rateUnit == "" {
+				rateUnit = "60s"
+			}
+			if rateIncrement == "" {
+				rateIncrement = rateUnit
+			}
+			for _, dr := range []struct {
+				tpDR  *utils.TPDestinationRate
+				rtID  string
+				price float64
+			}{
+				{drPeak, "RT_" + tier.ID + "_" + cur + "_PEAK", tier.PeakRate[cur]},
+				{drOffPeak, "RT_" + tier.ID + "_" + cur + "_OFFPEAK", tier.OffPeakRate[cur]},
+			} {
+				rt := &utils.TPRate{TPid: spec.TPid, ID: dr.rtID, RateSlots: []*utils.RateSlot{
+					&utils.RateSlot{ConnectFee: tier.ConnectFee[cur], Rate: dr.price,
+						RateUnit: rateUnit, RateIncrement: rateIncrement, GroupIntervalStart: "0s"}}}
+				for _, mdl := range APItoModelRate(rt) {
+					tpData[utils.RATES_CSV] = append(tpData[utils.RATES_CSV], mdl)
+				}
+				dr.tpDR.DestinationRates = append(dr.tpDR.DestinationRates, &utils.DestinationRate{
+					DestinationId: "DST_" + tier.ID, RateId: dr.rtID,
+					RoundingMethod: utils.ROUNDING_MIDDLE, RoundingDecimals: 4})
+			}
+		}
+		for _, dr := range []*utils.TPDestinationRate{drPeak, drOffPeak} {
+			for _, mdl := range APItoModelDestinationRate(dr) {
+				tpData[utils.DESTINATION_RATES_CSV] = append(tpData[utils.DESTINATION_RATES_CSV], mdl)
+			}
+		}
+		rp := &utils.TPRatingPlan{TPid: spec.TPid, ID: "RP_" + cur}
+		for _, tm := range tms {
+			drID := drOffPeak.ID
+			if tm.ID == TPGenPeak {
+				drID = drPeak.ID
+			}
+			rp.RatingPlanBindings = append(rp.RatingPlanBindings,
+				&utils.TPRatingPlanBinding{DestinationRatesId: drID, TimingId: tm.ID, Weight: 10})
+		}
+		for _, mdl := range APItoModelRatingPlan(rp) {
+			tpData[utils.RATING_PLANS_CSV] = append(tpData[utils.RATING_PLANS_CSV], mdl)
+		}
+		subject := cur
+		if len(spec.Currencies) == 1 {
+			subject = utils.ANY
+		}
+		rpf := &utils.TPRatingProfile{TPid: spec.TPid, LoadId: utils.CSV_LOAD, Direction: utils.OUT,
+			Tenant: spec.Tenant, Category: spec.Category, Subject: subject,
+			RatingPlanActivations: []*utils.TPRatingActivation{
+				&utils.TPRatingActivation{ActivationTime: actTime, RatingPlanId: rp.ID}}}
+		for _, mdl := range APItoModelRatingProfile(rpf) {
+			tpData[utils.RATING_PROFILES_CSV] = append(tpData[utils.RATING_PROFILES_CSV], mdl)
+		}
+	}
+	return tpData, nil
+}
+
+// TPGenLoadSpec is the input of the synthetic tariff plan generator used in performance testing
+// The accounts are spread in turn over the rating plans, each plan pricing the destinations in PriceBands bands
+type TPGenLoadSpec struct {
+	TPid                   string
+	Tenant                 string
+	Category               string
+	Destinations           int
+	PrefixesPerDestination int      // defaults to 1
+	CountryCodes           []string // the prefixes start with one of these, the first ones being more frequent
+	PriceBands             int      // distinct prices within one rating plan, defaults to 10
+	RatingPlans            int      // defaults to 1
+	Accounts               int
+	Balance                float64 // *monetary balance of each account
+	Seed                   int64   // the same seed generates the same tariff plan
+}
+
+// tpGenCountryCodes are used for the prefixes when the spec does not provide its own
+var tpGenCountryCodes = []string{"1", "44", "49", "33", "39", "34", "86", "91", "7", "81"}
+
+func (spec *TPGenLoadSpec) validate() error {
+	if missing := utils.MissingStructFields(spec, []string{"TPid", "Tenant", "Category"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if spec.Destinations <= 0 {
+		return errors.New("Destinations should be greater than 0")
+	}
+	if spec.Accounts < 0 || spec.PrefixesPerDestination < 0 || spec.PriceBands < 0 || spec.RatingPlans < 0 {
+		return errors.New("Accounts, PrefixesPerDestination, PriceBands and RatingPlans cannot be negative")
+	}
+	if spec.PrefixesPerDestination == 0 {
+		spec.PrefixesPerDestination = 1
+	}
+	if spec.PriceBands == 0 {
+		spec.PriceBands = 10
+	}
+	if spec.RatingPlans == 0 {
+		spec.RatingPlans = 1
+	}
+	if len(spec.CountryCodes) == 0 {
+		spec.CountryCodes = tpGenCountryCodes
+	}
+	return nil
+}
+
+// GenerateLoadTestTP builds a synthetic tariff plan sized by spec, indexed on the .csv file name
+func GenerateLoadTestTP(spec *TPGenLoadSpec) (map[string][]interface{}, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	rnd := rand.New(rand.NewSource(spec.Seed))
+	ccIdx := rand.NewZipf(rnd, 1.1, 1, uint64(len(spec.CountryCodes)-1))
+	tpData := make(map[string][]interface{})
+	usedPrefixes := make(map[string]bool)
+	dstIDs := make([]string, spec.Destinations)
+	for i := range dstIDs {
+		dstIDs[i] = fmt.Sprintf("DST_%07d", i)
+		tpDst := &utils.TPDestination{TPid: spec.TPid, ID: dstIDs[i]}
+		for len(tpDst.Prefixes) < spec.PrefixesPerDestination {
+			prefix := spec.CountryCodes[ccIdx.Uint64()]
+			for nrDigits := 2 + rnd.Intn(4); nrDigits > 0 || usedPrefixes[prefix]; nrDigits-- { // extend till unique
+				prefix += strconv.Itoa(rnd.Intn(10))
+			}
+			usedPrefixes[prefix] = true
+			tpDst.Prefixes = append(tpDst.Prefixes, prefix)
+		}
+		for _, mdl := range APItoModelDestination(tpDst) {
+			tpData[utils.DESTINATIONS_CSV] = append(tpData[utils.DESTINATIONS_CSV], mdl)
+		}
+	}
+	for p := 0; p < spec.RatingPlans; p++ {
+		tpDR := &utils.TPDestinationRate{TPid: spec.TPid, ID: fmt.Sprintf("DR_LOAD_%d", p)}
+		rtIDs := make([]string, spec.PriceBands)
+		for b := range rtIDs {
+			rtIDs[b] = fmt.Sprintf("RT_LOAD_%d_%d", p, b)
+			rt := &utils.TPRate{TPid: spec.TPid, ID: rtIDs[b], RateSlots: []*utils.RateSlot{
+				&utils.RateSlot{ConnectFee: float64(rnd.Intn(10)) / 100, Rate: float64(1+rnd.Intn(100)) / 100,
+					RateUnit: "60s", RateIncrement: "1s", GroupIntervalStart: "0s"}}}
+			for _, mdl := range APItoModelRate(rt) {
+				tpData[utils.RATES_CSV] = append(tpData[utils.RATES_CSV], mdl)
+			}
+		}
+		for _, dstID := range dstIDs {
+			tpDR.DestinationRates = append(tpDR.DestinationRates, &utils.DestinationRate{
+				DestinationId: dstID, RateId: rtIDs[rnd.Intn(len(rtIDs))],
+				RoundingMethod: utils.ROUNDING_MIDDLE, RoundingDecimals: 4})
+		}
+		for _, mdl := range APItoModelDestinationRate(tpDR) {
+			tpData[utils.DESTINATION_RATES_CSV] = append(tpData[utils.DESTINATION_RATES_CSV], mdl)
+		}
+		rp := &utils.TPRatingPlan{TPid: spec.TPid, ID: fmt.Sprintf("RP_LOAD_%d", p), RatingPlanBindings: []*utils.TPRatingPlanBinding{
+			&utils.TPRatingPlanBinding{DestinationRatesId: tpDR.ID, TimingId: utils.ANY, Weight: 10}}}
+		for _, mdl := range APItoModelRatingPlan(rp) {
+			tpData[utils.RATING_PLANS_CSV] = append(tpData[utils.RATING_PLANS_CSV], mdl)
+		}
+	}
+	if spec.Accounts == 0 {
+		return tpData, nil
+	}
+	acts := &utils.TPActions{TPid: spec.TPid, ID: "ACT_LOAD_TOPUP", Actions: []*utils.TPAction{
+		&utils.TPAction{Identifier: TOPUP_RESET, BalanceType: utils.MONETARY, Directions: utils.OUT,
+			Units: strconv.FormatFloat(spec.Balance, 'f', -1, 64), ExpiryTime: utils.UNLIMITED, BalanceWeight: "10", Weight: 10}}}
+	for _, mdl := range APItoModelAction(acts) {
+		tpData[utils.ACTIONS_CSV] = append(tpData[utils.ACTIONS_CSV], mdl)
+	}
+	apl := &utils.TPActionPlan{TPid: spec.TPid, ID: "AP_LOAD_TOPUP", ActionPlan: []*utils.TPActionTiming{
+		&utils.TPActionTiming{ActionsId: acts.ID, TimingId: utils.ASAP, Weight: 10}}}
+	for _, mdl := range APItoModelActionPlan(apl) {
+		tpData[utils.ACTION_PLANS_CSV] = append(tpData[utils.ACTION_PLANS_CSV], mdl)
+	}
+	for i := 0; i < spec.Accounts; i++ {
+		acnt := fmt.Sprintf("load%07d", i)
+		rpf := &utils.TPRatingProfile{TPid: spec.TPid, LoadId: utils.CSV_LOAD, Direction: utils.OUT,
+			Tenant: spec.Tenant, Category: spec.Category, Subject: acnt,
+			RatingPlanActivations: []*utils.TPRatingActivation{
+				&utils.TPRatingActivation{ActivationTime: "2017-01-01T00:00:00Z", RatingPlanId: fmt.Sprintf("RP_LOAD_%d", i%spec.RatingPlans)}}}
+		for _, mdl := range APItoModelRatingProfile(rpf) {
+			tpData[utils.RATING_PROFILES_CSV] = append(tpData[utils.RATING_PROFILES_CSV], mdl)
+		}
+		tpData[utils.ACCOUNT_ACTIONS_CSV] = append(tpData[utils.ACCOUNT_ACTIONS_CSV], *APItoModelAccountAction(
+			&utils.TPAccountActions{TPid: spec.TPid, LoadId: utils.CSV_LOAD, Tenant: spec.Tenant, Account: acnt, ActionPlanId: apl.ID}))
+	}
+	return tpData, nil
+}
+
+// WriteTPCSVs writes the tariff plan records as .csv files inside dirPath
+func WriteTPCSVs(dirPath string, sep rune, tpData map[string][]interface{}) error {
+	for fName, mdls := range tpData {
+		fp, err := os.Create(path.Join(dirPath, fName))
+		if err != nil {
+			return err
+		}
+		csvWriter := csv.NewWriter(fp)
+		csvWriter.Comma = sep
+		for _, mdl := range mdls {
+			record, err := csvDump(mdl)
+			if err != nil {
+				fp.Close()
+				return err
+			}
+			if err := csvWriter.Write(record); err != nil {
+				fp.Close()
+				return err
+			}
+		}
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			fp.Close()
+			return err
+		}
+		if err := fp.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
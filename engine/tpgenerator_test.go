@@ -0,0 +1,125 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestGenerateTP(t *testing.T) {
+	spec := &TPGenSpec{TPid: "TP_GEN", Tenant: "cgrates.org", Category: "call",
+		Currencies: []string{"EUR", "USD"},
+		PeakHours:  TPGenPeakHours{WeekDays: []int{1, 2, 3, 4, 5}, StartTime: "08:00:00", EndTime: "19:00:00"},
+		Tiers: []*TPGenTier{
+			&TPGenTier{ID: "NATIONAL", Prefixes: []string{"49"},
+				PeakRate:    map[string]float64{"EUR": 0.2, "USD": 0.24},
+				OffPeakRate: map[string]float64{"EUR": 0.1, "USD": 0.12}},
+		}}
+	tmpDir, err := ioutil.TempDir("", "cgr_tpgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	tpData, err := GenerateTP(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteTPCSVs(tmpDir, utils.CSV_SEP, tpData); err != nil {
+		t.Fatal(err)
+	}
+	tpr := NewTpReader(nil, NewFileCSVStorage(utils.CSV_SEP,
+		path.Join(tmpDir, utils.DESTINATIONS_CSV),
+		path.Join(tmpDir, utils.TIMINGS_CSV),
+		path.Join(tmpDir, utils.RATES_CSV),
+		path.Join(tmpDir, utils.DESTINATION_RATES_CSV),
+		path.Join(tmpDir, utils.RATING_PLANS_CSV),
+		path.Join(tmpDir, utils.RATING_PROFILES_CSV),
+		"", "", "", "", "", "", "", "", "", "", "", "", "", ""), "", "")
+	if err := tpr.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpr.ratingPlans) != 2 || len(tpr.ratingProfiles) != 2 || len(tpr.destinations) != 1 {
+		t.Errorf("Unexpected rating plans: %d, profiles: %d, destinations: %d",
+			len(tpr.ratingPlans), len(tpr.ratingProfiles), len(tpr.destinations))
+	}
+	if _, has := tpr.ratingProfiles["*out:cgrates.org:call:USD"]; !has {
+		t.Errorf("Missing USD rating profile, have: %+v", tpr.ratingProfiles)
+	}
+	spec.Tiers[0].PeakRate = nil
+	if _, err := GenerateTP(spec); err == nil {
+		t.Error("Expecting error on missing PeakRate")
+	}
+}
+
+func TestGenerateLoadTestTP(t *testing.T) {
+	spec := &TPGenLoadSpec{TPid: "TP_LOAD", Tenant: "cgrates.org", Category: "call",
+		Destinations: 50, PrefixesPerDestination: 2, RatingPlans: 3, Accounts: 20, Balance: 10, Seed: 1}
+	tpData, err := GenerateLoadTestTP(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameTPData, err := GenerateLoadTestTP(spec); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(tpData, sameTPData) {
+		t.Error("Same seed generated different tariff plans")
+	}
+	tmpDir, err := ioutil.TempDir("", "cgr_tpgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := WriteTPCSVs(tmpDir, utils.CSV_SEP, tpData); err != nil {
+		t.Fatal(err)
+	}
+	dataDB, _ := NewMapStorage()
+	tpr := NewTpReader(dataDB, NewFileCSVStorage(utils.CSV_SEP,
+		path.Join(tmpDir, utils.DESTINATIONS_CSV),
+		path.Join(tmpDir, utils.TIMINGS_CSV),
+		path.Join(tmpDir, utils.RATES_CSV),
+		path.Join(tmpDir, utils.DESTINATION_RATES_CSV),
+		path.Join(tmpDir, utils.RATING_PLANS_CSV),
+		path.Join(tmpDir, utils.RATING_PROFILES_CSV),
+		"", "",
+		path.Join(tmpDir, utils.ACTIONS_CSV),
+		path.Join(tmpDir, utils.ACTION_PLANS_CSV),
+		"",
+		path.Join(tmpDir, utils.ACCOUNT_ACTIONS_CSV),
+		"", "", "", "", "", "", "", ""), "", "")
+	if err := tpr.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpr.destinations) != 50 || len(tpr.ratingPlans) != 3 || len(tpr.ratingProfiles) != 20 || len(tpr.accountActions) != 20 {
+		t.Errorf("Unexpected destinations: %d, rating plans: %d, rating profiles: %d, accounts: %d",
+			len(tpr.destinations), len(tpr.ratingPlans), len(tpr.ratingProfiles), len(tpr.accountActions))
+	}
+	for _, dst := range tpr.destinations {
+		if len(dst.Prefixes) != 2 {
+			t.Errorf("Unexpected destination: %+v", dst)
+		}
+	}
+	spec.Destinations = 0
+	if _, err := GenerateLoadTestTP(spec); err == nil {
+		t.Error("Expecting error on missing Destinations")
+	}
+}
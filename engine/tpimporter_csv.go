@@ -22,19 +22,23 @@ import (
 	"io/ioutil"
 	"log"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/cgrates/cgrates/utils"
 )
 
 // Import tariff plan from csv into storDb
 type TPCSVImporter struct {
-	TPid     string     // Load data on this tpid
-	StorDb   LoadWriter // StorDb connection handle
-	DirPath  string     // Directory path to import from
-	Sep      rune       // Separator in the csv file
-	Verbose  bool       // If true will print a detailed information instead of silently discarding it
-	ImportId string     // Use this to differentiate between imports (eg: when autogenerating fields like RatingProfileId
-	csvr     LoadReader
+	TPid      string       // Load data on this tpid
+	StorDb    LoadWriter   // StorDb connection handle
+	DirPath   string       // Directory path to import from
+	Sep       rune         // Separator in the csv file
+	Verbose   bool         // If true will print a detailed information instead of silently discarding it
+	ImportId  string       // Use this to differentiate between imports (eg: when autogenerating fields like RatingProfileId
+	Variables *TPVariables // Expand the ${NAME} references inside the files, nil to disable
+	Mappings  XLSXMappings // Layout of the workbook sheets, when importing out of one
+	csvr      LoadReader
 }
 
 // Maps csv file to handler which should process it. Defined like this since tests on 1.0.3 were failing on Travis.
@@ -57,10 +61,22 @@ var fileHandlers = map[string]func(*TPCSVImporter, string) error{
 	utils.USERS_CSV:             (*TPCSVImporter).importUsers,
 	utils.ALIASES_CSV:           (*TPCSVImporter).importAliases,
 	utils.ResourceLimitsCsv:     (*TPCSVImporter).importResourceLimits,
+	utils.ExchangeRatesCsv:      (*TPCSVImporter).importExchangeRates,
+	utils.HolidayCalendarsCsv:   (*TPCSVImporter).importHolidayCalendars,
+	utils.DialPlansCsv:          (*TPCSVImporter).importDialPlans,
 }
 
 func (self *TPCSVImporter) Run() error {
-	self.csvr = NewFileCSVStorage(self.Sep,
+	if IsTPArchive(self.DirPath) {
+		return self.runArchive()
+	}
+	if IsRemoteWorkbook(self.DirPath) {
+		return self.runRemoteWorkbook()
+	}
+	if strings.HasSuffix(self.DirPath, utils.XLSXSuffix) {
+		return self.runWorkbook()
+	}
+	csvStorage := NewFileCSVStorage(self.Sep,
 		path.Join(self.DirPath, utils.DESTINATIONS_CSV),
 		path.Join(self.DirPath, utils.TIMINGS_CSV),
 		path.Join(self.DirPath, utils.RATES_CSV),
@@ -78,7 +94,12 @@ func (self *TPCSVImporter) Run() error {
 		path.Join(self.DirPath, utils.USERS_CSV),
 		path.Join(self.DirPath, utils.ALIASES_CSV),
 		path.Join(self.DirPath, utils.ResourceLimitsCsv),
+		path.Join(self.DirPath, utils.ExchangeRatesCsv),
+		path.Join(self.DirPath, utils.HolidayCalendarsCsv),
+		path.Join(self.DirPath, utils.DialPlansCsv),
 	)
+	csvStorage.SetVariables(self.Variables)
+	self.csvr = csvStorage
 	files, _ := ioutil.ReadDir(self.DirPath)
 	for _, f := range files {
 		fHandler, hasName := fileHandlers[f.Name()]
@@ -92,6 +113,55 @@ func (self *TPCSVImporter) Run() error {
 	return nil
 }
 
+// runArchive imports the tariff plan out of an archive instead of a folder
+func (self *TPCSVImporter) runArchive() error {
+	files, err := readTPArchive(self.DirPath)
+	if err != nil {
+		return err
+	}
+	return self.importFiles(files)
+}
+
+// runWorkbook imports the tariff plan out of the sheets of an .xlsx workbook
+func (self *TPCSVImporter) runWorkbook() error {
+	files, err := readTPWorkbook(self.Sep, self.DirPath, self.Mappings)
+	if err != nil {
+		return err
+	}
+	return self.importFiles(files)
+}
+
+// runRemoteWorkbook imports the tariff plan out of a downloaded workbook, eg: Google Sheets
+func (self *TPCSVImporter) runRemoteWorkbook() error {
+	files, err := downloadTPWorkbook(self.Sep, self.DirPath, self.Mappings)
+	if err != nil {
+		return err
+	}
+	return self.importFiles(files)
+}
+
+// importFiles imports the CSV contents indexed on their file name
+func (self *TPCSVImporter) importFiles(files map[string]string) error {
+	csvStorage := newFilesMapCSVStorage(self.Sep, files)
+	csvStorage.SetVariables(self.Variables)
+	self.csvr = csvStorage
+	fNames := make([]string, 0, len(files))
+	for fName := range files {
+		fNames = append(fNames, fName)
+	}
+	sort.Strings(fNames)
+	for _, fName := range fNames {
+		fHandler, hasName := fileHandlers[fName]
+		if !hasName {
+			continue
+		}
+		if err := fHandler(self, fName); err != nil {
+			utils.Logger.Err(fmt.Sprintf("<TPCSVImporter> Importing file: %s, got error: %s", fName, err.Error()))
+		}
+	}
+	return nil
+}
+
 // Handler importing timings from file, saved row by row to storDb
 func (self *TPCSVImporter) importTimings(fn string) error {
 	if self.Verbose {
@@ -357,3 +427,36 @@ func (self *TPCSVImporter) importResourceLimits(fn string) error {
 	}
 	return self.StorDb.SetTPResourceLimits(rls)
 }
+
+func (self *TPCSVImporter) importExchangeRates(fn string) error {
+	if self.Verbose {
+		log.Printf("Processing file: <%s> ", fn)
+	}
+	ers, err := self.csvr.GetTPExchangeRates(self.TPid, "")
+	if err != nil {
+		return err
+	}
+	return self.StorDb.SetTPExchangeRates(ers)
+}
+
+func (self *TPCSVImporter) importHolidayCalendars(fn string) error {
+	if self.Verbose {
+		log.Printf("Processing file: <%s> ", fn)
+	}
+	hcs, err := self.csvr.GetTPHolidayCalendars(self.TPid, "")
+	if err != nil {
+		return err
+	}
+	return self.StorDb.SetTPHolidayCalendars(hcs)
+}
+
+func (self *TPCSVImporter) importDialPlans(fn string) error {
+	if self.Verbose {
+		log.Printf("Processing file: <%s> ", fn)
+	}
+	dps, err := self.csvr.GetTPDialPlans(self.TPid, "")
+	if err != nil {
+		return err
+	}
+	return self.StorDb.SetTPDialPlans(dps)
+}
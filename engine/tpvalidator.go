@@ -0,0 +1,193 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"strconv"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewTPValidator returns a validator checking TP objects against their references in lr
+// dataDB is optional and used as fallback for objects already loaded
+func NewTPValidator(lr LoadReader, dataDB DataDB) *TPValidator {
+	return &TPValidator{lr: lr, dataDB: dataDB}
+}
+
+// TPValidator checks individual tariff plan objects before they are stored,
+// so editors working one object at a time get immediate feedback on broken references
+type TPValidator struct {
+	lr     LoadReader
+	dataDB DataDB
+}
+
+// ValidateTPRate makes sure all the RateSlots have parsable durations
+func (tpv *TPValidator) ValidateTPRate(tpRate *utils.TPRate) error {
+	for _, rs := range tpRate.RateSlots {
+		if err := rs.SetDurations(); err != nil {
+			return utils.NewErrInvalidTPField("RateSlots", err.Error())
+		}
+	}
+	return nil
+}
+
+// ValidateTPDestinationRate checks the referenced destinations and rates within the same TPid
+func (tpv *TPValidator) ValidateTPDestinationRate(tpDR *utils.TPDestinationRate) error {
+	for _, dr := range tpDR.DestinationRates {
+		switch dr.RoundingMethod {
+		case "", utils.ROUNDING_UP, utils.ROUNDING_MIDDLE, utils.ROUNDING_DOWN,
+			utils.ROUNDING_HALF_EVEN, utils.ROUNDING_TRUNCATE:
+		default:
+			return utils.NewErrInvalidTPField("RoundingMethod", dr.RoundingMethod)
+		}
+		switch dr.MaxCostStrategy {
+		case "", utils.MAX_COST_FREE, utils.MAX_COST_DISCONNECT, utils.MAX_COST_CAP:
+		default:
+			return utils.NewErrInvalidTPField("MaxCostStrategy", dr.MaxCostStrategy)
+		}
+		if has, err := tpv.hasRate(tpDR.TPid, dr.RateId); err != nil {
+			return err
+		} else if !has {
+			return utils.NewErrBrokenReference("Rate", dr.RateId)
+		}
+		if dr.DestinationId == utils.ANY {
+			continue
+		}
+		if has, err := tpv.hasDestination(tpDR.TPid, dr.DestinationId); err != nil {
+			return err
+		} else if !has {
+			return utils.NewErrBrokenReference("Destination", dr.DestinationId)
+		}
+	}
+	return nil
+}
+
+// ValidateTPRatingPlan checks the referenced timings and destination rates within the same TPid
+func (tpv *TPValidator) ValidateTPRatingPlan(tpRP *utils.TPRatingPlan) error {
+	for _, rpb := range tpRP.RatingPlanBindings {
+		if rpb.TimingId != utils.ANY && rpb.TimingId != utils.ASAP {
+			if has, err := tpv.hasTiming(tpRP.TPid, rpb.TimingId); err != nil {
+				return err
+			} else if !has {
+				return utils.NewErrBrokenReference("Timing", rpb.TimingId)
+			}
+		}
+		if has, err := tpv.hasDestinationRate(tpRP.TPid, rpb.DestinationRatesId); err != nil {
+			return err
+		} else if !has {
+			return utils.NewErrBrokenReference("DestinationRate", rpb.DestinationRatesId)
+		}
+	}
+	return nil
+}
+
+// ValidateTPRatingProfile checks activation times and the referenced rating plans
+func (tpv *TPValidator) ValidateTPRatingProfile(tpRpf *utils.TPRatingProfile) error {
+	for _, ra := range tpRpf.RatingPlanActivations {
+		if _, err := utils.ParseDate(ra.ActivationTime); err != nil {
+			return utils.NewErrInvalidTPField("ActivationTime", ra.ActivationTime)
+		}
+		switch ra.MaxCostStrategy {
+		case "", utils.MAX_COST_FREE, utils.MAX_COST_DISCONNECT, utils.MAX_COST_CAP:
+		default:
+			return utils.NewErrInvalidTPField("MaxCostStrategy", ra.MaxCostStrategy)
+		}
+		if ra.TrafficShare < 0 || ra.TrafficShare > 100 {
+			return utils.NewErrInvalidTPField("TrafficShare", strconv.FormatFloat(ra.TrafficShare, 'f', -1, 64))
+		}
+		if has, err := tpv.hasRatingPlan(tpRpf.TPid, ra.RatingPlanId); err != nil {
+			return err
+		} else if !has {
+			return utils.NewErrBrokenReference("RatingPlan", ra.RatingPlanId)
+		}
+	}
+	return nil
+}
+
+func (tpv *TPValidator) hasTiming(tpid, id string) (bool, error) {
+	tms, err := tpv.lr.GetTPTimings(tpid, id)
+	if err != nil && err != utils.ErrNotFound {
+		return false, err
+	}
+	for _, tm := range tms {
+		if tm.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (tpv *TPValidator) hasDestination(tpid, id string) (bool, error) {
+	dsts, err := tpv.lr.GetTPDestinations(tpid, id)
+	if err != nil && err != utils.ErrNotFound {
+		return false, err
+	}
+	for _, dst := range dsts {
+		if dst.ID == id {
+			return true, nil
+		}
+	}
+	return tpv.hasData(utils.DESTINATION_PREFIX, id)
+}
+
+func (tpv *TPValidator) hasRate(tpid, id string) (bool, error) {
+	rts, err := tpv.lr.GetTPRates(tpid, id)
+	if err != nil && err != utils.ErrNotFound {
+		return false, err
+	}
+	for _, rt := range rts {
+		if rt.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (tpv *TPValidator) hasDestinationRate(tpid, id string) (bool, error) {
+	drs, err := tpv.lr.GetTPDestinationRates(tpid, id, nil)
+	if err != nil && err != utils.ErrNotFound {
+		return false, err
+	}
+	for _, dr := range drs {
+		if dr.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (tpv *TPValidator) hasRatingPlan(tpid, id string) (bool, error) {
+	rps, err := tpv.lr.GetTPRatingPlans(tpid, id, nil)
+	if err != nil && err != utils.ErrNotFound {
+		return false, err
+	}
+	for _, rp := range rps {
+		if rp.ID == id {
+			return true, nil
+		}
+	}
+	return tpv.hasData(utils.RATING_PLAN_PREFIX, id)
+}
+
+// hasData checks objects which were already loaded into DataDB out of another TP
+func (tpv *TPValidator) hasData(category, id string) (bool, error) {
+	if tpv.dataDB == nil {
+		return false, nil
+	}
+	return tpv.dataDB.HasData(category, id)
+}
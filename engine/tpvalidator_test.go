@@ -0,0 +1,103 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestTPValidatorRate(t *testing.T) {
+	tpv := NewTPValidator(csvr.lr, nil)
+	tpRate := &utils.TPRate{TPid: testTPID, ID: "RT_TEST",
+		RateSlots: []*utils.RateSlot{&utils.RateSlot{Rate: 0.1, RateUnit: "60s", RateIncrement: "1s", GroupIntervalStart: "0s"}}}
+	if err := tpv.ValidateTPRate(tpRate); err != nil {
+		t.Error(err)
+	}
+	tpRate.RateSlots[0].RateIncrement = "1x"
+	if err := tpv.ValidateTPRate(tpRate); err == nil {
+		t.Error("Expecting error on invalid RateIncrement")
+	}
+}
+
+func TestTPValidatorDestinationRate(t *testing.T) {
+	tpv := NewTPValidator(csvr.lr, nil)
+	tpDR := &utils.TPDestinationRate{TPid: testTPID, ID: "DR_TEST",
+		DestinationRates: []*utils.DestinationRate{
+			&utils.DestinationRate{DestinationId: "GERMANY", RateId: "R1", RoundingMethod: utils.ROUNDING_MIDDLE},
+			&utils.DestinationRate{DestinationId: utils.ANY, RateId: "R2"},
+		}}
+	if err := tpv.ValidateTPDestinationRate(tpDR); err != nil {
+		t.Error(err)
+	}
+	tpDR.DestinationRates[0].RateId = "R_MISSING"
+	if err := tpv.ValidateTPDestinationRate(tpDR); err == nil || err.Error() != utils.NewErrBrokenReference("Rate", "R_MISSING").Error() {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	tpDR.DestinationRates[0].RateId = "R1"
+	tpDR.DestinationRates[0].DestinationId = "DST_MISSING"
+	if err := tpv.ValidateTPDestinationRate(tpDR); err == nil || err.Error() != utils.NewErrBrokenReference("Destination", "DST_MISSING").Error() {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	tpDR.DestinationRates[0].DestinationId = "GERMANY"
+	tpDR.DestinationRates[0].RoundingMethod = "*sideways"
+	if err := tpv.ValidateTPDestinationRate(tpDR); err == nil {
+		t.Error("Expecting error on invalid RoundingMethod")
+	}
+}
+
+func TestTPValidatorRatingPlan(t *testing.T) {
+	tpv := NewTPValidator(csvr.lr, nil)
+	tpRP := &utils.TPRatingPlan{TPid: testTPID, ID: "RP_TEST",
+		RatingPlanBindings: []*utils.TPRatingPlanBinding{
+			&utils.TPRatingPlanBinding{DestinationRatesId: "RT_STANDARD", TimingId: "WORKDAYS_00", Weight: 10},
+			&utils.TPRatingPlanBinding{DestinationRatesId: "RT_STD_WEEKEND", TimingId: utils.ANY, Weight: 10},
+		}}
+	if err := tpv.ValidateTPRatingPlan(tpRP); err != nil {
+		t.Error(err)
+	}
+	tpRP.RatingPlanBindings[0].TimingId = "TM_MISSING"
+	if err := tpv.ValidateTPRatingPlan(tpRP); err == nil || err.Error() != utils.NewErrBrokenReference("Timing", "TM_MISSING").Error() {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	tpRP.RatingPlanBindings[0].TimingId = "WORKDAYS_00"
+	tpRP.RatingPlanBindings[1].DestinationRatesId = "DR_MISSING"
+	if err := tpv.ValidateTPRatingPlan(tpRP); err == nil || err.Error() != utils.NewErrBrokenReference("DestinationRate", "DR_MISSING").Error() {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestTPValidatorRatingProfile(t *testing.T) {
+	tpv := NewTPValidator(csvr.lr, nil)
+	tpRpf := &utils.TPRatingProfile{TPid: testTPID, LoadId: "TEST", Direction: utils.OUT, Tenant: "cgrates.org", Category: "call", Subject: "*any",
+		RatingPlanActivations: []*utils.TPRatingActivation{
+			&utils.TPRatingActivation{ActivationTime: "2012-01-01T00:00:00Z", RatingPlanId: "STANDARD"}}}
+	if err := tpv.ValidateTPRatingProfile(tpRpf); err != nil {
+		t.Error(err)
+	}
+	tpRpf.RatingPlanActivations[0].RatingPlanId = "RP_MISSING"
+	if err := tpv.ValidateTPRatingProfile(tpRpf); err == nil || err.Error() != utils.NewErrBrokenReference("RatingPlan", "RP_MISSING").Error() {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	tpRpf.RatingPlanActivations[0].RatingPlanId = "STANDARD"
+	tpRpf.RatingPlanActivations[0].ActivationTime = "not a date"
+	if err := tpv.ValidateTPRatingProfile(tpRpf); err == nil {
+		t.Error("Expecting error on invalid ActivationTime")
+	}
+}
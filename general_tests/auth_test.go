@@ -62,7 +62,7 @@ RP_ANY,DR_ANY_1CNT,*any,10`
 	aliases := ``
 	resLimits := ``
 	csvr := engine.NewTpReader(dbAuth, engine.NewStringCSVStorage(',', destinations, timings, rates, destinationRates, ratingPlans, ratingProfiles,
-		sharedGroups, lcrs, actions, actionPlans, actionTriggers, accountActions, derivedCharges, cdrStats, users, aliases, resLimits), "", "")
+		sharedGroups, lcrs, actions, actionPlans, actionTriggers, accountActions, derivedCharges, cdrStats, users, aliases, resLimits, "", "", ""), "", "")
 	if err := csvr.LoadAll(); err != nil {
 		t.Fatal(err)
 	}
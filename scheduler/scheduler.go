@@ -25,9 +25,12 @@ import (
 	"time"
 
 	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/guardian"
 	"github.com/cgrates/cgrates/utils"
 )
 
+const catchUpLimit = 1000 // maximum number of missed executions of one action timing run at start
+
 type Scheduler struct {
 	sync.RWMutex
 	queue                           engine.ActionTimingPriorityList
@@ -96,6 +99,7 @@ func (s *Scheduler) Loop() {
 		start := a0.GetNextStartTime(now)
 		if start.Equal(now) || start.Before(now) {
 			go a0.Execute(s.actSucessChan, s.actFailedChan)
+			go s.recordExecution(a0, start)
 			// if after execute the next start time is in the past then
 			// do not add it to the queue
 			a0.ResetStartTimeCache()
@@ -168,13 +172,14 @@ func (s *Scheduler) loadActionPlans() {
 			if at.IsASAP() {
 				continue
 			}
+			at.SetAccountIDs(actionPlan.AccountIDs) // copy the accounts
+			at.SetActionPlanID(actionPlan.Id)
 			now := time.Now()
+			s.catchUp(at, now)
 			if at.GetNextStartTime(now).Before(now) {
 				// the task is obsolete, do not add it to the queue
 				continue
 			}
-			at.SetAccountIDs(actionPlan.AccountIDs) // copy the accounts
-			at.SetActionPlanID(actionPlan.Id)
 			s.queue = append(s.queue, at)
 
 		}
@@ -183,6 +188,59 @@ func (s *Scheduler) loadActionPlans() {
 	utils.Logger.Info(fmt.Sprintf("<Scheduler> queued %d action plans", len(s.queue)))
 }
 
+// catchUp runs the executions of at missed while the scheduler was down, according to its CatchUp policy
+func (s *Scheduler) catchUp(at *engine.ActionTiming, now time.Time) {
+	missed, err := at.MissedExecutions(now, catchUpLimit)
+	if err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<Scheduler> Cannot catch up action timing %s on action plan %s: %v", at.Uuid, at.GetActionPlanID(), err))
+		return
+	}
+	if len(missed) == 0 {
+		return
+	}
+	if len(missed) == catchUpLimit {
+		utils.Logger.Warning(fmt.Sprintf("<Scheduler> Catch up of action timing %s on action plan %s limited to %d executions",
+			at.Uuid, at.GetActionPlanID(), catchUpLimit))
+	}
+	utils.Logger.Info(fmt.Sprintf("<Scheduler> Catching up %d missed executions of %s on action plan %s", len(missed), at.ActionsID, at.GetActionPlanID()))
+	go func() {
+		for range missed {
+			at.Execute(s.actSucessChan, s.actFailedChan)
+		}
+		s.recordExecution(at, missed[len(missed)-1])
+	}()
+}
+
+// recordExecution stores the scheduled time of the last execution of at, used to catch up after downtime
+func (s *Scheduler) recordExecution(at *engine.ActionTiming, lastExec time.Time) {
+	if at.CatchUp == "" || at.CatchUp == utils.MetaSkip {
+		return
+	}
+	if _, err := guardian.Guardian.Guard(func() (interface{}, error) {
+		at.LastExecution = lastExec
+		apl, err := s.storage.GetActionPlan(at.GetActionPlanID(), true, utils.NonTransactional)
+		if err != nil {
+			return 0, err
+		}
+		var found bool
+		for _, storedAt := range apl.ActionTimings {
+			if storedAt.Uuid == at.Uuid {
+				storedAt.LastExecution = lastExec
+				found = true
+			}
+		}
+		if !found { // action plan changed meanwhile
+			return 0, nil
+		}
+		if err := s.storage.SetActionPlan(at.GetActionPlanID(), apl, true, utils.NonTransactional); err != nil {
+			return 0, err
+		}
+		return 0, s.storage.CacheDataFromDB(utils.ACTION_PLAN_PREFIX, []string{at.GetActionPlanID()}, true)
+	}, 0, utils.ACTION_PLAN_PREFIX); err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<Scheduler> Cannot record execution of action timing %s on action plan %s: %v", at.Uuid, at.GetActionPlanID(), err))
+	}
+}
+
 func (s *Scheduler) restart() {
 	if s.schedulerStarted {
 		s.restartLoop <- true
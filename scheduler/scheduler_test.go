@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
 )
 
 func TestSchedulerUpdateActStats(t *testing.T) {
@@ -45,3 +46,29 @@ func TestSchedulerUpdateActStats(t *testing.T) {
 		t.Errorf("Wrong stats: %+v", sched.actSuccessStats)
 	}
 }
+
+func TestSchedulerRecordExecution(t *testing.T) {
+	ms, _ := engine.NewMapStorage()
+	at := &engine.ActionTiming{
+		Uuid:      "at_catchup",
+		Timing:    &engine.RateInterval{Timing: &engine.RITiming{MonthDays: utils.MonthDays{1}, StartTime: "00:00:00"}},
+		ActionsID: "TOPUP_10",
+		CatchUp:   utils.MetaRunAllMissed,
+	}
+	if err := ms.SetActionPlan("AP_CATCHUP", &engine.ActionPlan{Id: "AP_CATCHUP", ActionTimings: []*engine.ActionTiming{at}}, true, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	sched := &Scheduler{storage: ms}
+	qAt := &engine.ActionTiming{Uuid: at.Uuid, Timing: at.Timing, ActionsID: at.ActionsID, CatchUp: at.CatchUp}
+	qAt.SetActionPlanID("AP_CATCHUP")
+	lastExec := time.Date(2017, 4, 1, 0, 0, 0, 0, time.UTC)
+	sched.recordExecution(qAt, lastExec)
+	if apl, err := ms.GetActionPlan("AP_CATCHUP", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !apl.ActionTimings[0].LastExecution.Equal(lastExec) {
+		t.Errorf("Expecting: %v, received: %v", lastExec, apl.ActionTimings[0].LastExecution)
+	}
+	if !qAt.LastExecution.Equal(lastExec) {
+		t.Errorf("Expecting: %v, received: %v", lastExec, qAt.LastExecution)
+	}
+}
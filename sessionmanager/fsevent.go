@@ -74,6 +74,7 @@ const (
 
 	VAR_CGR_DISCONNECT_CAUSE = "variable_" + utils.CGR_DISCONNECT_CAUSE
 	VAR_CGR_CMPUTELCR        = "variable_" + utils.CGR_COMPUTELCR
+	EVENT_TIMESTAMP          = "Event-Date-Timestamp" // microseconds since epoch when FreeSWITCH generated the event
 )
 
 // Nice printing for the event object.
@@ -92,6 +93,15 @@ func (fsev FSEvent) AsEvent(body string) engine.Event {
 	return fsev
 }
 
+// GetEventTime returns the time FreeSWITCH generated the event at, zero if missing
+func (fsev FSEvent) GetEventTime() time.Time {
+	usecs, err := strconv.ParseInt(fsev[EVENT_TIMESTAMP], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, usecs*int64(time.Microsecond))
+}
+
 func (fsev FSEvent) GetName() string {
 	return fsev[NAME]
 }
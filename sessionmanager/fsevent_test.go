@@ -670,3 +670,13 @@ func TestSliceAsFsArray(t *testing.T) {
 // Make sure processing of the hangup event produces the same output as FS-JSON CDR
 func TestSyncFsEventWithJsonCdr(t *testing.T) {
 }
+
+func TestFsEvGetEventTime(t *testing.T) {
+	ev := FSEvent{EVENT_TIMESTAMP: "1436280728471153"}
+	if evTime := ev.GetEventTime(); !evTime.Equal(time.Unix(1436280728, 471153000)) {
+		t.Errorf("Unexpected event time: %v", evTime)
+	}
+	if evTime := (FSEvent{}).GetEventTime(); !evTime.IsZero() {
+		t.Errorf("Unexpected event time: %v", evTime)
+	}
+}
@@ -65,6 +65,7 @@ type FSSessionManager struct {
 func (sm *FSSessionManager) createHandlers() map[string][]func(string, string) {
 	ca := func(body, connId string) {
 		ev := new(FSEvent).AsEvent(body)
+		utils.ClockSkews.Observe(connId, ev.(FSEvent).GetEventTime())
 		sm.onChannelAnswer(ev, connId)
 	}
 	ch := func(body, connId string) {
@@ -78,6 +79,7 @@ func (sm *FSSessionManager) createHandlers() map[string][]func(string, string) {
 	if sm.cfg.SubscribePark {
 		cp := func(body, connId string) {
 			ev := new(FSEvent).AsEvent(body)
+			utils.ClockSkews.Observe(connId, ev.(FSEvent).GetEventTime())
 			sm.onChannelPark(ev, connId)
 		}
 		handlers["CHANNEL_PARK"] = []func(string, string){cp}
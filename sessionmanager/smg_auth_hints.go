@@ -0,0 +1,94 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package sessionmanager
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// authBlockingErrs are the authorization errors reported as block reasons instead of failing the request
+var authBlockingErrs = []error{utils.ErrUnauthorizedDestination, utils.ErrAccountDisabled, utils.ErrInsufficientCredit,
+	utils.ErrAccountNotFound, utils.ErrRatingPlanNotFound}
+
+// AuthHint is the authorization outcome for one destination
+type AuthHint struct {
+	MaxUsage    time.Duration
+	BlockReason string // why the usage is not allowed, eg: INSUFFICIENT_CREDIT, UNAUTHORIZED_DESTINATION
+}
+
+// MaxUsageWithHints is the authorization reply completed with hints for the configured destination classes
+type MaxUsageWithHints struct {
+	AuthHint
+	DestinationHints map[string]*AuthHint // per destination class in auth_hint_destinations
+}
+
+// newAuthHint converts the result of a max usage query into an AuthHint, returns error if not a blocking one
+func newAuthHint(maxUsage time.Duration, err error) (*AuthHint, error) {
+	if err != nil {
+		for _, blckErr := range authBlockingErrs {
+			if strings.Contains(err.Error(), blckErr.Error()) { // errors can arrive over RPC as strings
+				return &AuthHint{BlockReason: blckErr.Error()}, nil
+			}
+		}
+		return nil, err
+	}
+	hint := &AuthHint{MaxUsage: maxUsage}
+	if maxUsage == 0 {
+		hint.BlockReason = utils.ErrInsufficientCredit.Error()
+	}
+	return hint, nil
+}
+
+// derivedMaxUsage queries RALs for the maximum usage of the event, considering derived chargers
+func (smg *SMGeneric) derivedMaxUsage(gev SMGenericEvent) (maxUsage time.Duration, err error) {
+	gev[utils.EVENT_NAME] = utils.CGR_AUTHORIZATION
+	var maxDur float64
+	if err = smg.rals.Call("Responder.GetDerivedMaxSessionTime", gev.AsStoredCdr(config.CgrConfig(), smg.Timezone), &maxDur); err != nil {
+		return
+	}
+	return time.Duration(maxDur), nil
+}
+
+// GetMaxUsageWithHints authorizes the event and the destination classes configured in auth_hint_destinations
+func (smg *SMGeneric) GetMaxUsageWithHints(gev SMGenericEvent) (mu *MaxUsageWithHints, err error) {
+	maxUsage, err := smg.GetMaxUsage(gev)
+	hint, err := newAuthHint(maxUsage, err)
+	if err != nil {
+		return nil, err
+	}
+	mu = &MaxUsageWithHints{AuthHint: *hint}
+	if len(smg.cgrCfg.SmGenericConfig.AuthHintDsts) == 0 {
+		return
+	}
+	mu.DestinationHints = make(map[string]*AuthHint)
+	originID := gev.GetOriginID(utils.META_DEFAULT)
+	for dstClass, dstNumber := range smg.cgrCfg.SmGenericConfig.AuthHintDsts {
+		hintEv := gev.Clone()
+		hintEv[utils.DESTINATION] = dstNumber
+		hintEv[utils.ACCID] = originID + utils.CONCATENATED_KEY_SEP + dstClass // distinct CGRID so RALs will not serve it from cache
+		maxUsage, err := smg.derivedMaxUsage(hintEv)
+		if mu.DestinationHints[dstClass], err = newAuthHint(maxUsage, err); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
@@ -0,0 +1,127 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package sessionmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	smgInitiateSession  = "InitiateSession"
+	smgUpdateSession    = "UpdateSession"
+	smgTerminateSession = "TerminateSession"
+	smgChargeEvent      = "ChargeEvent"
+	smgProcessCDR       = "ProcessCDR"
+)
+
+// smgDedupEntry correlates the events of one call reported by redundant agents
+type smgDedupEntry struct {
+	sync.Mutex                                       // serializes the processing of the call events
+	originID, originHost string                      // identity of the first agent reporting the call, used for all events
+	event                SMGenericEvent              // field values seen so far, merged according to dedup_merge_policy
+	replies              map[string]*cache.CacheItem // successful replies per method
+	timer                *time.Timer                 // removes the entry after dedup_ttl without events
+}
+
+// mergeEvent returns the event to be processed on behalf of the first agent, updating the merged field values
+// Conflicting values sent by other agents are resolved with policy, first agent's own values always win
+func (de *smgDedupEntry) mergeEvent(gev SMGenericEvent, isOwner bool, policy string) SMGenericEvent {
+	ev := make(SMGenericEvent, len(gev))
+	for fld, val := range gev {
+		if mrgVal, has := de.event[fld]; has && !isOwner && policy == utils.MetaFirst {
+			val = mrgVal
+		}
+		ev[fld] = val
+		de.event[fld] = val
+	}
+	ev[utils.ACCID] = de.originID
+	ev[utils.CDRHOST] = de.originHost
+	return ev
+}
+
+// dedupKey builds the correlation key out of the dedup_keys fields, false if dedup does not apply to the event
+func (smg *SMGeneric) dedupKey(gev SMGenericEvent) (string, bool) {
+	dedupKeys := smg.cgrCfg.SmGenericConfig.DedupKeys
+	if len(dedupKeys) == 0 {
+		return "", false
+	}
+	vals := make([]string, len(dedupKeys))
+	for i, fldName := range dedupKeys {
+		val, err := gev.GetFieldAsString(fldName)
+		if err != nil || val == "" {
+			return "", false
+		}
+		vals[i] = val
+	}
+	return utils.ConcatenatedKey(vals...), true
+}
+
+// lockDedupEntry returns the locked entry for the correlation key, creating it out of gev if not present
+func (smg *SMGeneric) lockDedupEntry(dKey string, gev SMGenericEvent) *smgDedupEntry {
+	ttl := smg.cgrCfg.SmGenericConfig.DedupTTL
+	smg.dedupMux.Lock()
+	de, has := smg.dedupEntries[dKey]
+	if !has {
+		de = &smgDedupEntry{originID: gev.GetOriginID(utils.META_DEFAULT),
+			originHost: gev.GetOriginatorIP(utils.META_DEFAULT),
+			event:      make(SMGenericEvent), replies: make(map[string]*cache.CacheItem)}
+		de.timer = time.AfterFunc(ttl, func() {
+			smg.dedupMux.Lock()
+			delete(smg.dedupEntries, dKey)
+			smg.dedupMux.Unlock()
+		})
+		smg.dedupEntries[dKey] = de
+	} else {
+		de.timer.Reset(ttl)
+	}
+	smg.dedupMux.Unlock()
+	de.Lock()
+	return de
+}
+
+// dedupProcess runs process for the event unless another agent already reported it for the same call,
+// in which case the reply of the original event is returned
+// UpdateSession is periodic so it is processed only for the first agent, the other ones receive its last reply
+func (smg *SMGeneric) dedupProcess(method string, gev SMGenericEvent,
+	process func(SMGenericEvent) (interface{}, error)) (interface{}, error) {
+	dKey, canDedup := smg.dedupKey(gev)
+	if !canDedup {
+		return process(gev)
+	}
+	de := smg.lockDedupEntry(dKey, gev)
+	defer de.Unlock()
+	isOwner := gev.GetOriginID(utils.META_DEFAULT) == de.originID &&
+		gev.GetOriginatorIP(utils.META_DEFAULT) == de.originHost
+	if item, has := de.replies[method]; has && (!isOwner || method != smgUpdateSession) {
+		return item.Value, item.Err
+	}
+	if method == smgUpdateSession && !isOwner {
+		if item, has := de.replies[smgInitiateSession]; has {
+			return item.Value, item.Err
+		}
+	}
+	reply, err := process(de.mergeEvent(gev, isOwner, smg.cgrCfg.SmGenericConfig.DedupMergePolicy))
+	if err == nil {
+		de.replies[method] = &cache.CacheItem{Value: reply}
+	}
+	return reply, err
+}
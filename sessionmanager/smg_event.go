@@ -68,6 +68,24 @@ func (self SMGenericEvent) GetOriginID(fieldName string) string {
 	return result
 }
 
+// GetMasterOriginID returns the OriginID of the master session this leg is correlated to
+func (self SMGenericEvent) GetMasterOriginID(fieldName string) string {
+	if fieldName == utils.META_DEFAULT {
+		fieldName = utils.MasterOriginID
+	}
+	result, _ := utils.ConvertIfaceToString(self[fieldName])
+	return result
+}
+
+// GetMasterCGRID returns the CGRID of the master session, empty if the event is not a leg
+func (self SMGenericEvent) GetMasterCGRID(mOIDFieldName string) string {
+	masterOID := self.GetMasterOriginID(mOIDFieldName)
+	if masterOID == "" {
+		return ""
+	}
+	return utils.Sha1(masterOID, self.GetOriginatorIP(utils.META_DEFAULT))
+}
+
 func (self SMGenericEvent) GetSessionIds() []string {
 	return []string{self.GetOriginID(utils.META_DEFAULT)}
 }
@@ -307,6 +325,15 @@ func (self SMGenericEvent) GetOriginatorIP(fieldName string) string {
 	return result
 }
 
+// GetEventTime returns the time the agent generated the event at, zero if missing
+func (self SMGenericEvent) GetEventTime(timezone string) (time.Time, error) {
+	result, _ := utils.ConvertIfaceToString(self[utils.EventTime])
+	if result == "" {
+		return time.Time{}, nil
+	}
+	return utils.ParseTimeDetectLayout(result, timezone)
+}
+
 func (self SMGenericEvent) GetCdrSource() string {
 	return utils.SMG + "_" + self.GetName()
 }
@@ -0,0 +1,106 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package sessionmanager
+
+import (
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ArgsGetSessionLegs identifies the master session of a multi-leg call
+type ArgsGetSessionLegs struct {
+	MasterOriginID string
+	OriginHost     string
+}
+
+// SessionLegCharges is the charging status of one session run, master or leg
+type SessionLegCharges struct {
+	CGRID    string
+	OriginID string
+	RunID    string
+	Account  string
+	Master   bool // true for the master session
+	Usage    time.Duration
+	Cost     float64
+}
+
+// SessionLegs groups the charges of the sessions correlated under one master
+type SessionLegs struct {
+	MasterCGRID string
+	Legs        []*SessionLegCharges
+	Usage       map[string]time.Duration // aggregated usage, per RunID
+	Cost        map[string]float64       // aggregated cost, per RunID
+}
+
+// indexLeg correlates a leg session with its master
+func (smg *SMGeneric) indexLeg(masterCGRID, legCGRID string) {
+	smg.legsMux.Lock()
+	defer smg.legsMux.Unlock()
+	if _, hasIt := smg.legsIndex[masterCGRID]; !hasIt {
+		smg.legsIndex[masterCGRID] = make(utils.StringMap)
+	}
+	smg.legsIndex[masterCGRID][legCGRID] = true
+	smg.legsRIndex[legCGRID] = masterCGRID
+}
+
+// unindexLeg removes the correlation of a leg with its master, true if the leg was indexed
+func (smg *SMGeneric) unindexLeg(legCGRID string) bool {
+	smg.legsMux.Lock()
+	defer smg.legsMux.Unlock()
+	masterCGRID, hasIt := smg.legsRIndex[legCGRID]
+	if !hasIt {
+		return false
+	}
+	delete(smg.legsIndex[masterCGRID], legCGRID)
+	if len(smg.legsIndex[masterCGRID]) == 0 {
+		delete(smg.legsIndex, masterCGRID)
+	}
+	delete(smg.legsRIndex, legCGRID)
+	return true
+}
+
+// sessionLegs returns the charges of the master session and of all active legs correlated to it
+func (smg *SMGeneric) sessionLegs(masterCGRID string) (sLegs *SessionLegs, err error) {
+	smg.legsMux.RLock()
+	cgrIDs := []string{masterCGRID}
+	for legCGRID := range smg.legsIndex[masterCGRID] {
+		cgrIDs = append(cgrIDs, legCGRID)
+	}
+	smg.legsMux.RUnlock()
+	sLegs = &SessionLegs{MasterCGRID: masterCGRID,
+		Usage: make(map[string]time.Duration), Cost: make(map[string]float64)}
+	for _, cgrID := range cgrIDs {
+		for _, sGrp := range smg.getSessions(cgrID, false) {
+			for _, s := range sGrp {
+				lc := s.legCharges()
+				lc.Master = cgrID == masterCGRID
+				sLegs.Legs = append(sLegs.Legs, lc)
+				sLegs.Usage[lc.RunID] += lc.Usage
+				sLegs.Cost[lc.RunID] += lc.Cost
+			}
+		}
+	}
+	if len(sLegs.Legs) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	for runID, cost := range sLegs.Cost {
+		sLegs.Cost[runID] = utils.Round(cost, smg.cgrCfg.RoundingDecimals, utils.ROUNDING_MIDDLE)
+	}
+	return
+}
@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/engine"
 	"github.com/cgrates/cgrates/utils"
 	"github.com/cgrates/rpcclient"
@@ -50,6 +51,11 @@ type SMGSession struct {
 	LastDebit     time.Duration // last real debited duration
 	TotalUsage    time.Duration // sum of lastUsage
 
+	lastUpdate    time.Time     // when the last interim update was processed
+	lastEventTime time.Time     // EventTime of the last interim update processed
+	lastMaxUsage  time.Duration // usage granted on the last interim update
+
+	lowCreditGrace time.Duration // usage left to debit into negative once the balance is exhausted
 }
 
 // Called in case of automatic debits
@@ -84,6 +90,49 @@ func (self *SMGSession) debitLoop(debitInterval time.Duration) {
 	}
 }
 
+// interimDebit applies the agent interim updates handling before debiting
+func (self *SMGSession) interimDebit(dur time.Duration, lastUsed *time.Duration, evTime time.Time, iuCfg *config.InterimUpdateCfg) (time.Duration, error) {
+	if iuCfg == nil {
+		iuCfg = new(config.InterimUpdateCfg)
+	}
+	self.mux.Lock()
+	now := time.Now()
+	if !evTime.IsZero() && !self.lastEventTime.IsZero() && evTime.Before(self.lastEventTime) {
+		if evTime.Before(self.lastEventTime.Add(-iuCfg.OutOfOrderTolerance)) {
+			self.mux.Unlock()
+			return 0, utils.ErrOutOfOrderUpdate
+		}
+		evTime = self.lastEventTime // within tolerance, do not move backwards
+	}
+	if !self.lastUpdate.IsZero() {
+		elapsed := now.Sub(self.lastUpdate)
+		if elapsed < iuCfg.MinInterval { // too frequent, answer out of the previous reservation
+			remaining := self.lastMaxUsage - elapsed
+			self.mux.Unlock()
+			if remaining < 0 {
+				remaining = 0
+			}
+			return remaining, nil
+		}
+		if lastUsed == nil && iuCfg.ExtrapolateUsage {
+			if elapsed > self.LastDebit {
+				elapsed = self.LastDebit
+			}
+			lastUsed = &elapsed
+		}
+	}
+	self.lastUpdate = now
+	self.lastEventTime = evTime
+	self.mux.Unlock()
+	maxDur, err := self.debit(dur, lastUsed)
+	if err == nil {
+		self.mux.Lock()
+		self.lastMaxUsage = maxDur
+		self.mux.Unlock()
+	}
+	return maxDur, err
+}
+
 // Attempts to debit a duration, returns maximum duration which can be debitted or error
 func (self *SMGSession) debit(dur time.Duration, lastUsed *time.Duration) (time.Duration, error) {
 	self.mux.Lock()
@@ -115,7 +164,12 @@ func (self *SMGSession) debit(dur time.Duration, lastUsed *time.Duration) (time.
 	self.CD.TimeEnd = self.CD.TimeStart.Add(dur)
 	self.CD.DurationIndex += dur
 	cc := &engine.CallCost{}
-	if err := self.rals.Call("Responder.MaxDebit", self.CD, cc); err != nil || cc.GetDuration() == 0 {
+	err := self.rals.Call("Responder.MaxDebit", self.CD, cc)
+	if err == nil && cc.GetDuration() < dur && self.lowCreditGrace > 0 &&
+		(self.CD.LoopIndex > 0 || cc.GetDuration() > 0) { // grace only for sessions already started
+		err = self.debitGrace(cc, dur)
+	}
+	if err != nil || cc.GetDuration() == 0 {
 		self.LastUsage = 0
 		self.LastDebit = 0
 		return 0, err
@@ -138,6 +192,9 @@ func (self *SMGSession) debit(dur time.Duration, lastUsed *time.Duration) (time.
 	self.CD.LoopIndex += 1
 	self.LastDebit = initialExtraDuration + ccDuration
 	self.TotalUsage += self.LastUsage
+	if cc.RatingVariant != "" {
+		self.EventStart[utils.RatingVariant] = cc.RatingVariant
+	}
 	ec := engine.NewEventCostFromCallCost(cc, self.CGRID, self.RunID)
 	if self.EventCost == nil {
 		self.EventCost = ec
@@ -150,6 +207,29 @@ func (self *SMGSession) debit(dur time.Duration, lastUsed *time.Duration) (time.
 	return requestedDuration, nil
 }
 
+// debitGrace continues the debit limited by the balance with a forced one, out of the grace usage left
+func (self *SMGSession) debitGrace(cc *engine.CallCost, dur time.Duration) error {
+	maxDebited := cc.GetDuration()
+	graceDur := utils.MinDuration(dur-maxDebited, self.lowCreditGrace)
+	graceCD := self.CD.Clone()
+	if maxDebited != 0 {
+		graceCD.TimeStart = cc.GetEndTime()
+	}
+	graceCD.TimeEnd = graceCD.TimeStart.Add(graceDur)
+	graceCD.DurationIndex += maxDebited + graceDur - dur
+	graceCC := &engine.CallCost{}
+	if err := self.rals.Call("Responder.Debit", graceCD, graceCC); err != nil {
+		return err
+	}
+	self.lowCreditGrace -= graceDur
+	if maxDebited == 0 {
+		*cc = *graceCC
+	} else {
+		cc.Merge(graceCC)
+	}
+	return nil
+}
+
 // Send disconnect order to remote connection
 func (self *SMGSession) disconnectSession(reason string) error {
 	self.EventStart[utils.USAGE] = strconv.FormatFloat(self.TotalUsage.Seconds(), 'f', -1, 64) // Set the usage to total one debitted
@@ -292,3 +372,20 @@ func (self *SMGSession) AsActiveSession(timezone string) *ActiveSession {
 	}
 	return aSession
 }
+
+// legCharges returns the usage and cost charged so far on this session run
+func (self *SMGSession) legCharges() *SessionLegCharges {
+	self.mux.Lock() // EventCost caches the computed cost
+	defer self.mux.Unlock()
+	lc := &SessionLegCharges{
+		CGRID:    self.CGRID,
+		OriginID: self.EventStart.GetOriginID(utils.META_DEFAULT),
+		RunID:    self.RunID,
+		Account:  self.EventStart.GetAccount(utils.META_DEFAULT),
+		Usage:    self.TotalUsage,
+	}
+	if self.EventCost != nil {
+		lc.Cost = self.EventCost.GetCost()
+	}
+	return lc
+}
@@ -79,7 +79,10 @@ func NewSMGeneric(cgrCfg *config.CGRConfig, rals rpcclient.RpcClientConnection,
 		pSessionsIndex:     make(map[string]map[string]map[string]utils.StringMap),
 		pSessionsRIndex:    make(map[string][]*riFieldNameVal),
 		sessionTerminators: make(map[string]*smgSessionTerminator),
-		responseCache:      cache.NewResponseCache(cgrCfg.ResponseCacheTTL)}
+		responseCache:      cache.NewResponseCache(cgrCfg.ResponseCacheTTL),
+		dedupEntries:       make(map[string]*smgDedupEntry),
+		legsIndex:          make(map[string]utils.StringMap),
+		legsRIndex:         make(map[string]string)}
 }
 
 type SMGeneric struct {
@@ -102,6 +105,11 @@ type SMGeneric struct {
 	sessionTerminators map[string]*smgSessionTerminator                 // terminate and cleanup the session if timer expires
 	sTsMux             sync.RWMutex                                     // protects sessionTerminators
 	responseCache      *cache.ResponseCache                             // cache replies here
+	dedupEntries       map[string]*smgDedupEntry                        // events of one call reported by redundant agents, indexed on correlation key
+	dedupMux           sync.Mutex                                       // protects dedupEntries
+	legsIndex          map[string]utils.StringMap                       // map[masterCGRID]utils.StringMap[legCGRID], correlates multi-leg sessions
+	legsRIndex         map[string]string                                // map[legCGRID]masterCGRID, used on remove
+	legsMux            sync.RWMutex                                     // protects legsIndex
 }
 
 // riFieldNameVal is a reverse index entry
@@ -172,31 +180,51 @@ func (smg *SMGeneric) resetTerminatorTimer(cgrID string, ttl time.Duration, ttlL
 
 // ttlTerminate is called when a session times-out
 func (smg *SMGeneric) ttlTerminate(s *SMGSession, tmtr *smgSessionTerminator) {
-	debitUsage := tmtr.ttl
-	if tmtr.ttlUsage != nil {
-		debitUsage = *tmtr.ttlUsage
-	}
 	aSessions := smg.getSessions(s.CGRID, false)
 	if len(aSessions) == 0 { // will not continue if the session is not longer active
 		return
 	}
-	for _, s := range aSessions[s.CGRID] {
-		s.debit(debitUsage, tmtr.ttlLastUsed)
-	}
-	smg.sessionEnd(s.CGRID, s.TotalUsage)
+	smg.sessionEnd(s.CGRID, smg.ttlUsage(s, aSessions[s.CGRID], tmtr))
 	cdr := s.EventStart.AsStoredCdr(smg.cgrCfg, smg.Timezone)
 	cdr.Usage = s.TotalUsage
+	if cdr.ExtraFields == nil {
+		cdr.ExtraFields = make(map[string]string)
+	}
+	cdr.ExtraFields[utils.ReviewReason] = utils.MetaSessionTTL // usage estimated, not reported by the agent
 	var reply string
 	smg.cdrsrv.Call("CdrsV1.ProcessCDR", cdr, &reply)
 	smg.replicateSessionsWithID(s.CGRID, false, smg.smgReplConns)
 }
 
+// ttlUsage estimates the usage of the timed-out session out of the session_ttl_policy
+func (smg *SMGeneric) ttlUsage(s *SMGSession, runs []*SMGSession, tmtr *smgSessionTerminator) time.Duration {
+	switch smg.cgrCfg.SmGenericConfig.SessionTTLPolicy {
+	case utils.MetaLastUpdate:
+		return s.TotalUsage
+	case utils.ZERO:
+		return 0
+	case utils.MetaMaxReservation:
+		return s.TotalUsage + s.ExtraDuration
+	}
+	debitUsage := tmtr.ttl
+	if tmtr.ttlUsage != nil {
+		debitUsage = *tmtr.ttlUsage
+	}
+	for _, run := range runs {
+		run.debit(debitUsage, tmtr.ttlLastUsed)
+	}
+	return s.TotalUsage
+}
+
 func (smg *SMGeneric) recordASession(s *SMGSession) {
 	smg.aSessionsMux.Lock()
 	smg.activeSessions[s.CGRID] = append(smg.activeSessions[s.CGRID], s)
 	smg.setSessionTerminator(s)
 	smg.indexSession(s, false)
 	smg.aSessionsMux.Unlock()
+	if masterCGRID := s.EventStart.GetMasterCGRID(utils.META_DEFAULT); masterCGRID != "" && masterCGRID != s.CGRID {
+		smg.indexLeg(masterCGRID, s.CGRID)
+	}
 }
 
 // Remove session from session list, removes all related in case of multiple runs, true if item was found
@@ -213,6 +241,7 @@ func (smg *SMGeneric) unrecordASession(cgrID string) bool {
 	}
 	smg.sTsMux.RUnlock()
 	smg.unindexSession(cgrID, false)
+	smg.unindexLeg(cgrID)
 	return true
 }
 
@@ -375,7 +404,7 @@ func (smg *SMGeneric) sessionStart(evStart SMGenericEvent, clntConn rpcclient.Rp
 		stopDebitChan := make(chan struct{})
 		for _, sessionRun := range sessionRuns {
 			s := &SMGSession{CGRID: cgrID, EventStart: evStart, RunID: sessionRun.DerivedCharger.RunID, Timezone: smg.Timezone,
-				rals: smg.rals, cdrsrv: smg.cdrsrv, CD: sessionRun.CallDescriptor, clntConn: clntConn}
+				rals: smg.rals, cdrsrv: smg.cdrsrv, CD: sessionRun.CallDescriptor, clntConn: clntConn, lowCreditGrace: smg.cgrCfg.LowCreditGrace}
 			smg.recordASession(s)
 			//utils.Logger.Info(fmt.Sprintf("<SMGeneric> Starting session: %s, runId: %s", sessionId, s.runId))
 			if smg.cgrCfg.SmGenericConfig.DebitInterval != 0 {
@@ -651,14 +680,7 @@ func (smg *SMGeneric) GetMaxUsage(gev SMGenericEvent) (maxUsage time.Duration, e
 		return (item.Value.(time.Duration)), item.Err
 	}
 	defer smg.responseCache.Cache(cacheKey, &cache.CacheItem{Value: maxUsage, Err: err})
-	gev[utils.EVENT_NAME] = utils.CGR_AUTHORIZATION
-	storedCdr := gev.AsStoredCdr(config.CgrConfig(), smg.Timezone)
-	var maxDur float64
-	if err = smg.rals.Call("Responder.GetDerivedMaxSessionTime", storedCdr, &maxDur); err != nil {
-		return
-	}
-	maxUsage = time.Duration(maxDur)
-	return
+	return smg.derivedMaxUsage(gev)
 }
 
 func (smg *SMGeneric) GetLCRSuppliers(gev SMGenericEvent) (suppls []string, err error) {
@@ -693,8 +715,16 @@ func (smg *SMGeneric) GetLCRSuppliers(gev SMGenericEvent) (suppls []string, err
 
 // Called on session start
 func (smg *SMGeneric) InitiateSession(gev SMGenericEvent, clnt rpcclient.RpcClientConnection) (maxUsage time.Duration, err error) {
+	reply, err := smg.dedupProcess(smgInitiateSession, gev, func(ev SMGenericEvent) (interface{}, error) {
+		return smg.initiateSession(ev, clnt)
+	})
+	maxUsage, _ = reply.(time.Duration)
+	return
+}
+
+func (smg *SMGeneric) initiateSession(gev SMGenericEvent, clnt rpcclient.RpcClientConnection) (maxUsage time.Duration, err error) {
 	cgrID := gev.GetCGRID(utils.META_DEFAULT)
-	cacheKey := "InitiateSession" + cgrID
+	cacheKey := smgInitiateSession + cgrID
 	if item, err := smg.responseCache.Get(cacheKey); err == nil && item != nil {
 		return item.Value.(time.Duration), item.Err
 	}
@@ -708,7 +738,7 @@ func (smg *SMGeneric) InitiateSession(gev SMGenericEvent, clnt rpcclient.RpcClie
 		maxUsage = time.Duration(-1 * time.Second)
 		return
 	}
-	maxUsage, err = smg.UpdateSession(gev, clnt)
+	maxUsage, err = smg.updateSession(gev, clnt)
 	if err != nil || maxUsage == 0 {
 		smg.sessionEnd(cgrID, 0)
 	}
@@ -717,8 +747,16 @@ func (smg *SMGeneric) InitiateSession(gev SMGenericEvent, clnt rpcclient.RpcClie
 
 // Execute debits for usage/maxUsage
 func (smg *SMGeneric) UpdateSession(gev SMGenericEvent, clnt rpcclient.RpcClientConnection) (maxUsage time.Duration, err error) {
+	reply, err := smg.dedupProcess(smgUpdateSession, gev, func(ev SMGenericEvent) (interface{}, error) {
+		return smg.updateSession(ev, clnt)
+	})
+	maxUsage, _ = reply.(time.Duration)
+	return
+}
+
+func (smg *SMGeneric) updateSession(gev SMGenericEvent, clnt rpcclient.RpcClientConnection) (maxUsage time.Duration, err error) {
 	cgrID := gev.GetCGRID(utils.META_DEFAULT)
-	cacheKey := "UpdateSession" + cgrID
+	cacheKey := smgUpdateSession + cgrID
 	if item, err := smg.responseCache.Get(cacheKey); err == nil && item != nil {
 		return item.Value.(time.Duration), item.Err
 	}
@@ -754,6 +792,10 @@ func (smg *SMGeneric) UpdateSession(gev SMGenericEvent, clnt rpcclient.RpcClient
 		}
 		return
 	}
+	var evTime time.Time
+	if evTime, err = gev.GetEventTime(smg.Timezone); err != nil {
+		return
+	}
 	aSessions := smg.getSessions(cgrID, false)
 	if len(aSessions) == 0 {
 		if aSessions = smg.passiveToActive(cgrID); len(aSessions) == 0 {
@@ -763,9 +805,10 @@ func (smg *SMGeneric) UpdateSession(gev SMGenericEvent, clnt rpcclient.RpcClient
 		}
 	}
 	defer smg.replicateSessionsWithID(gev.GetCGRID(utils.META_DEFAULT), false, smg.smgReplConns)
+	iuCfg := smg.cgrCfg.SmGenericConfig.InterimUpdateCfg(gev.GetOriginatorIP(utils.META_DEFAULT))
 	for _, s := range aSessions[cgrID] {
 		var maxDur time.Duration
-		if maxDur, err = s.debit(maxUsage, lastUsed); err != nil {
+		if maxDur, err = s.interimDebit(maxUsage, lastUsed, evTime, iuCfg); err != nil {
 			return
 		} else if maxDur < maxUsage {
 			maxUsage = maxDur
@@ -776,8 +819,15 @@ func (smg *SMGeneric) UpdateSession(gev SMGenericEvent, clnt rpcclient.RpcClient
 
 // Called on session end, should stop debit loop
 func (smg *SMGeneric) TerminateSession(gev SMGenericEvent, clnt rpcclient.RpcClientConnection) (err error) {
+	_, err = smg.dedupProcess(smgTerminateSession, gev, func(ev SMGenericEvent) (interface{}, error) {
+		return nil, smg.terminateSession(ev, clnt)
+	})
+	return
+}
+
+func (smg *SMGeneric) terminateSession(gev SMGenericEvent, clnt rpcclient.RpcClientConnection) (err error) {
 	cgrID := gev.GetCGRID(utils.META_DEFAULT)
-	cacheKey := "TerminateSession" + cgrID
+	cacheKey := smgTerminateSession + cgrID
 	if item, err := smg.responseCache.Get(cacheKey); err == nil && item != nil {
 		return item.Err
 	}
@@ -847,8 +897,16 @@ func (smg *SMGeneric) TerminateSession(gev SMGenericEvent, clnt rpcclient.RpcCli
 
 // Processes one time events (eg: SMS)
 func (smg *SMGeneric) ChargeEvent(gev SMGenericEvent) (maxUsage time.Duration, err error) {
+	reply, err := smg.dedupProcess(smgChargeEvent, gev, func(ev SMGenericEvent) (interface{}, error) {
+		return smg.chargeEvent(ev)
+	})
+	maxUsage, _ = reply.(time.Duration)
+	return
+}
+
+func (smg *SMGeneric) chargeEvent(gev SMGenericEvent) (maxUsage time.Duration, err error) {
 	cgrID := gev.GetCGRID(utils.META_DEFAULT)
-	cacheKey := "ChargeEvent" + cgrID
+	cacheKey := smgChargeEvent + cgrID
 	if item, err := smg.responseCache.Get(cacheKey); err == nil && item != nil {
 		return item.Value.(time.Duration), item.Err
 	}
@@ -951,8 +1009,15 @@ func (smg *SMGeneric) ChargeEvent(gev SMGenericEvent) (maxUsage time.Duration, e
 }
 
 func (smg *SMGeneric) ProcessCDR(gev SMGenericEvent) (err error) {
+	_, err = smg.dedupProcess(smgProcessCDR, gev, func(ev SMGenericEvent) (interface{}, error) {
+		return nil, smg.processCDR(ev)
+	})
+	return
+}
+
+func (smg *SMGeneric) processCDR(gev SMGenericEvent) (err error) {
 	cgrID := gev.GetCGRID(utils.META_DEFAULT)
-	cacheKey := "ProcessCDR" + cgrID
+	cacheKey := smgProcessCDR + cgrID
 	if item, err := smg.responseCache.Get(cacheKey); err == nil && item != nil {
 		return item.Err
 	}
@@ -1037,6 +1102,16 @@ func (smg *SMGeneric) BiRPCV2GetMaxUsage(clnt rpcclient.RpcClientConnection, ev
 	return nil
 }
 
+// BiRPCV2GetMaxUsageWithHints returns the maximum usage together with block reasons and hints for the configured destination classes
+func (smg *SMGeneric) BiRPCV2GetMaxUsageWithHints(clnt rpcclient.RpcClientConnection, ev SMGenericEvent, reply *MaxUsageWithHints) error {
+	mu, err := smg.GetMaxUsageWithHints(ev)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = *mu
+	return nil
+}
+
 /// Returns list of suppliers which can be used for the request
 func (smg *SMGeneric) BiRPCV1GetLCRSuppliers(clnt rpcclient.RpcClientConnection, ev SMGenericEvent, suppliers *[]string) error {
 	if supls, err := smg.GetLCRSuppliers(ev); err != nil {
@@ -1190,6 +1265,19 @@ func (smg *SMGeneric) BiRPCV1GetPassiveSessionsCount(clnt rpcclient.RpcClientCon
 	return nil
 }
 
+// BiRPCV1GetSessionLegs returns per-leg and aggregated charges of the sessions correlated under a master session
+func (smg *SMGeneric) BiRPCV1GetSessionLegs(clnt rpcclient.RpcClientConnection, args ArgsGetSessionLegs, reply *SessionLegs) error {
+	if args.MasterOriginID == "" {
+		return utils.NewErrMandatoryIeMissing(utils.MasterOriginID)
+	}
+	sLegs, err := smg.sessionLegs(utils.Sha1(args.MasterOriginID, args.OriginHost))
+	if err != nil {
+		return err
+	}
+	*reply = *sLegs
+	return nil
+}
+
 type ArgsSetPassiveSessions struct {
 	CGRID    string
 	Sessions []*SMGSession
@@ -19,9 +19,12 @@ package sessionmanager
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/engine"
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -532,3 +535,260 @@ func TestGetPassiveSessions(t *testing.T) {
 		t.Errorf("PassiveSessions: %+v", pSS)
 	}
 }
+
+type testSMGCdrs struct {
+	cdrs []*engine.CDR
+}
+
+func (tsc *testSMGCdrs) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	tsc.cdrs = append(tsc.cdrs, args.(*engine.CDR))
+	*reply.(*string) = utils.OK
+	return nil
+}
+
+func TestSMGDedup(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.SmGenericConfig.DedupKeys = []string{utils.ACCID}
+	cdrs := new(testSMGCdrs)
+	smg := NewSMGeneric(cfg, nil, cdrs, nil, "UTC")
+	ev1 := SMGenericEvent{utils.EVENT_NAME: "TEST_EVENT", utils.ACCID: "dedup1", utils.CDRHOST: "10.0.0.1",
+		utils.TENANT: "cgrates.org", utils.ACCOUNT: "1001", utils.USAGE: "1m"}
+	ev2 := SMGenericEvent{utils.EVENT_NAME: "TEST_EVENT", utils.ACCID: "dedup1", utils.CDRHOST: "10.0.0.2",
+		utils.TENANT: "cgrates.org", utils.ACCOUNT: "1001", utils.USAGE: "2m", "Extra1": "Value1"}
+	if err := smg.ProcessCDR(ev1); err != nil {
+		t.Error(err)
+	}
+	if err := smg.ProcessCDR(ev2); err != nil {
+		t.Error(err)
+	}
+	if len(cdrs.cdrs) != 1 {
+		t.Fatalf("Expecting one CDR, received: %s", utils.ToJSON(cdrs.cdrs))
+	}
+	var processed SMGenericEvent
+	capture := func(ev SMGenericEvent) (interface{}, error) {
+		processed = ev
+		return nil, nil
+	}
+	smg.dedupProcess(smgTerminateSession, ev2, capture)
+	if processed[utils.CDRHOST] != "10.0.0.1" || processed[utils.USAGE] != "1m" || processed["Extra1"] != "Value1" {
+		t.Errorf("Unexpected merged event: %+v", processed)
+	}
+	processed = nil
+	smg.dedupProcess(smgTerminateSession, ev1, capture)
+	if processed != nil {
+		t.Errorf("Duplicate event processed: %+v", processed)
+	}
+	cfg.SmGenericConfig.DedupMergePolicy = utils.MetaLast
+	ev2[utils.ACCID], ev1[utils.ACCID] = "dedup2", "dedup2"
+	smg.dedupProcess(smgInitiateSession, ev1, capture)
+	smg.dedupProcess(smgTerminateSession, ev2, capture)
+	if processed[utils.CDRHOST] != "10.0.0.1" || processed[utils.USAGE] != "2m" {
+		t.Errorf("Unexpected merged event: %+v", processed)
+	}
+}
+
+func TestSMGSessionLegs(t *testing.T) {
+	smg := NewSMGeneric(smgCfg, nil, nil, nil, "UTC")
+	mstrEv := SMGenericEvent{utils.ACCID: "conf1", utils.ACCOUNT: "1001", utils.CDRHOST: "127.0.0.1"}
+	leg1Ev := SMGenericEvent{utils.ACCID: "leg1", utils.ACCOUNT: "1002", utils.CDRHOST: "127.0.0.1", utils.MasterOriginID: "conf1"}
+	leg2Ev := SMGenericEvent{utils.ACCID: "leg2", utils.ACCOUNT: "1003", utils.CDRHOST: "127.0.0.1", utils.MasterOriginID: "conf1"}
+	mstrCGRID := mstrEv.GetCGRID(utils.META_DEFAULT)
+	if leg1Ev.GetMasterCGRID(utils.META_DEFAULT) != mstrCGRID {
+		t.Errorf("Expecting master CGRID: %s, received: %s", mstrCGRID, leg1Ev.GetMasterCGRID(utils.META_DEFAULT))
+	}
+	if mstrEv.GetMasterCGRID(utils.META_DEFAULT) != "" {
+		t.Error("Master should not be a leg")
+	}
+	smg.recordASession(&SMGSession{CGRID: mstrCGRID, RunID: utils.META_DEFAULT, EventStart: mstrEv,
+		TotalUsage: time.Duration(2 * time.Minute), EventCost: &engine.EventCost{Cost: utils.Float64Pointer(1.2)}})
+	smg.recordASession(&SMGSession{CGRID: leg1Ev.GetCGRID(utils.META_DEFAULT), RunID: utils.META_DEFAULT, EventStart: leg1Ev,
+		TotalUsage: time.Duration(time.Minute), EventCost: &engine.EventCost{Cost: utils.Float64Pointer(0.6)}})
+	smg.recordASession(&SMGSession{CGRID: leg2Ev.GetCGRID(utils.META_DEFAULT), RunID: utils.META_DEFAULT, EventStart: leg2Ev,
+		TotalUsage: time.Duration(30 * time.Second)})
+	var sLegs SessionLegs
+	if err := smg.BiRPCV1GetSessionLegs(nil, ArgsGetSessionLegs{MasterOriginID: "conf1", OriginHost: "127.0.0.1"}, &sLegs); err != nil {
+		t.Fatal(err)
+	}
+	if len(sLegs.Legs) != 3 || !sLegs.Legs[0].Master {
+		t.Errorf("Unexpected legs: %+v", sLegs.Legs)
+	}
+	if sLegs.Usage[utils.META_DEFAULT] != time.Duration(210*time.Second) {
+		t.Errorf("Unexpected usage: %v", sLegs.Usage)
+	}
+	if sLegs.Cost[utils.META_DEFAULT] != 1.8 {
+		t.Errorf("Unexpected cost: %v", sLegs.Cost)
+	}
+	smg.unrecordASession(leg1Ev.GetCGRID(utils.META_DEFAULT))
+	smg.unrecordASession(leg2Ev.GetCGRID(utils.META_DEFAULT))
+	if len(smg.legsIndex) != 0 || len(smg.legsRIndex) != 0 {
+		t.Errorf("Legs not unindexed: %+v, %+v", smg.legsIndex, smg.legsRIndex)
+	}
+	smg.unrecordASession(mstrCGRID)
+	if err := smg.BiRPCV1GetSessionLegs(nil, ArgsGetSessionLegs{MasterOriginID: "conf1", OriginHost: "127.0.0.1"}, &sLegs); err != utils.ErrNotFound {
+		t.Errorf("Expecting ErrNotFound, received: %v", err)
+	}
+}
+
+type testSMGRals struct{}
+
+func (tsr *testSMGRals) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	switch dst := args.(*engine.CDR).Destination; {
+	case strings.HasPrefix(dst, "+1"):
+		return utils.NewErrServerError(utils.ErrUnauthorizedDestination)
+	case strings.HasPrefix(dst, "+44"):
+		*reply.(*float64) = 0
+	default:
+		*reply.(*float64) = float64(time.Hour)
+	}
+	return nil
+}
+
+func TestSMGGetMaxUsageWithHints(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	smg := NewSMGeneric(cfg, new(testSMGRals), nil, nil, "UTC")
+	ev := SMGenericEvent{utils.EVENT_NAME: "TEST_EVENT", utils.ACCID: "hints1", utils.CDRHOST: "10.0.0.1",
+		utils.TENANT: "cgrates.org", utils.ACCOUNT: "1001", utils.DESTINATION: "+4986517174963"}
+	var mu MaxUsageWithHints
+	if err := smg.BiRPCV2GetMaxUsageWithHints(nil, ev.Clone(), &mu); err != nil {
+		t.Fatal(err)
+	} else if mu.MaxUsage != time.Hour || mu.BlockReason != "" || mu.DestinationHints != nil {
+		t.Errorf("Unexpected reply: %+v", mu)
+	}
+	cfg.SmGenericConfig.AuthHintDsts = map[string]string{"international": "+12125551234", "uk": "+442071234567"}
+	ev[utils.ACCID] = "hints2"
+	eHints := map[string]*AuthHint{
+		"international": &AuthHint{BlockReason: utils.ErrUnauthorizedDestination.Error()},
+		"uk":            &AuthHint{BlockReason: utils.ErrInsufficientCredit.Error()},
+	}
+	if err := smg.BiRPCV2GetMaxUsageWithHints(nil, ev.Clone(), &mu); err != nil {
+		t.Fatal(err)
+	} else if mu.MaxUsage != time.Hour || !reflect.DeepEqual(eHints, mu.DestinationHints) {
+		t.Errorf("Unexpected reply: %+v, hints: %+v", mu, mu.DestinationHints)
+	}
+	ev[utils.ACCID] = "hints3"
+	ev[utils.DESTINATION] = "+12125550000"
+	if err := smg.BiRPCV2GetMaxUsageWithHints(nil, ev.Clone(), &mu); err != nil {
+		t.Fatal(err)
+	} else if mu.BlockReason != utils.ErrUnauthorizedDestination.Error() {
+		t.Errorf("Unexpected reply: %+v", mu)
+	}
+}
+
+func TestSMGTTLPolicy(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cdrs := new(testSMGCdrs)
+	smg := NewSMGeneric(cfg, nil, cdrs, nil, "UTC")
+	for i, tc := range []struct {
+		policy string
+		usage  time.Duration
+	}{
+		{utils.MetaLastUpdate, 30 * time.Second},
+		{utils.ZERO, 0},
+		{utils.MetaMaxReservation, 40 * time.Second},
+	} {
+		cfg.SmGenericConfig.SessionTTLPolicy = tc.policy
+		ev := SMGenericEvent{utils.EVENT_NAME: "TEST_EVENT", utils.ACCID: "stale" + tc.policy,
+			utils.CDRHOST: "127.0.0.1", utils.TENANT: "cgrates.org", utils.ACCOUNT: "1001"}
+		s := &SMGSession{CGRID: ev.GetCGRID(utils.META_DEFAULT), RunID: utils.META_DEFAULT, EventStart: ev,
+			TotalUsage: 30 * time.Second, ExtraDuration: 10 * time.Second}
+		smg.recordASession(s)
+		smg.ttlTerminate(s, &smgSessionTerminator{ttl: time.Minute})
+		if len(smg.getSessions(s.CGRID, false)) != 0 {
+			t.Errorf("Session not terminated for policy: %s", tc.policy)
+		}
+		if len(cdrs.cdrs) != i+1 {
+			t.Fatalf("Unexpected CDRs: %s", utils.ToJSON(cdrs.cdrs))
+		}
+		if cdr := cdrs.cdrs[i]; cdr.Usage != tc.usage || cdr.ExtraFields[utils.ReviewReason] != utils.MetaSessionTTL {
+			t.Errorf("Policy: %s, unexpected CDR: %s", tc.policy, utils.ToJSON(cdr))
+		}
+	}
+}
+
+func TestSMGSessionInterimDebit(t *testing.T) {
+	iuCfg := &config.InterimUpdateCfg{MinInterval: time.Minute, OutOfOrderTolerance: 5 * time.Second}
+	lastEvTime := time.Date(2016, 1, 5, 18, 30, 59, 0, time.UTC)
+	s := &SMGSession{CGRID: "interimDebit", lastUpdate: time.Now(), lastEventTime: lastEvTime, lastMaxUsage: 30 * time.Second}
+	// out of order beyond tolerance
+	if _, err := s.interimDebit(30*time.Second, nil, lastEvTime.Add(-6*time.Second), iuCfg); err != utils.ErrOutOfOrderUpdate {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrOutOfOrderUpdate, err)
+	}
+	// out of order within tolerance, answered out of the previous reservation since too close to the previous update
+	if maxDur, err := s.interimDebit(30*time.Second, nil, lastEvTime.Add(-4*time.Second), iuCfg); err != nil {
+		t.Error(err)
+	} else if maxDur <= 29*time.Second || maxDur > 30*time.Second {
+		t.Errorf("Unexpected maxDur: %v", maxDur)
+	}
+	if !s.lastEventTime.Equal(lastEvTime) {
+		t.Errorf("Unexpected lastEventTime: %v", s.lastEventTime)
+	}
+	s.lastUpdate = time.Now().Add(-2 * time.Minute)
+	if maxDur, err := s.interimDebit(30*time.Second, nil, lastEvTime, &config.InterimUpdateCfg{MinInterval: 3 * time.Minute}); err != nil {
+		t.Error(err)
+	} else if maxDur != 0 {
+		t.Errorf("Unexpected maxDur: %v", maxDur)
+	}
+}
+
+// testSMGGraceRals debits at most maxDebit out of the balance
+type testSMGGraceRals struct {
+	maxDebit time.Duration
+	forced   []time.Duration
+}
+
+func (tsr *testSMGGraceRals) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	cd := args.(*engine.CallDescriptor)
+	timeEnd := cd.TimeEnd
+	switch serviceMethod {
+	case "Responder.MaxDebit":
+		if maxEnd := cd.TimeStart.Add(tsr.maxDebit); maxEnd.Before(timeEnd) {
+			timeEnd = maxEnd
+		}
+	case "Responder.Debit":
+		tsr.forced = append(tsr.forced, cd.GetDuration())
+	}
+	*reply.(*engine.CallCost) = engine.CallCost{Timespans: engine.TimeSpans{&engine.TimeSpan{TimeStart: cd.TimeStart, TimeEnd: timeEnd}}}
+	return nil
+}
+
+func TestSMGSessionDebitLowCreditGrace(t *testing.T) {
+	rals := &testSMGGraceRals{maxDebit: 10 * time.Second}
+	s := &SMGSession{CGRID: "lowCreditGrace", rals: rals, lowCreditGrace: 25 * time.Second,
+		CD: &engine.CallDescriptor{TimeStart: time.Date(2016, 1, 5, 18, 30, 0, 0, time.UTC)}}
+	for i, eDebit := range []time.Duration{30 * time.Second, 15 * time.Second, 10 * time.Second} {
+		if maxDebit, err := s.debit(30*time.Second, nil); err != nil {
+			t.Fatal(err)
+		} else if maxDebit != eDebit {
+			t.Errorf("Debit %d, expecting: %v, received: %v", i, eDebit, maxDebit)
+		}
+	}
+	if eForced := []time.Duration{20 * time.Second, 5 * time.Second}; !reflect.DeepEqual(eForced, rals.forced) {
+		t.Errorf("Expecting forced debits: %v, received: %v", eForced, rals.forced)
+	}
+	if s.TotalUsage != 55*time.Second || s.lowCreditGrace != 0 {
+		t.Errorf("Unexpected session: %+v", s)
+	}
+	// no grace for sessions starting on an exhausted balance
+	rals = new(testSMGGraceRals)
+	s = &SMGSession{CGRID: "lowCreditGraceEmpty", rals: rals, lowCreditGrace: 25 * time.Second,
+		CD: &engine.CallDescriptor{TimeStart: time.Date(2016, 1, 5, 18, 30, 0, 0, time.UTC)}}
+	if maxDebit, err := s.debit(30*time.Second, nil); err != nil {
+		t.Fatal(err)
+	} else if maxDebit != 0 {
+		t.Errorf("Expecting no debit, received: %v", maxDebit)
+	}
+	if len(rals.forced) != 0 || s.lowCreditGrace != 25*time.Second {
+		t.Errorf("Unexpected forced debits: %v, grace left: %v", rals.forced, s.lowCreditGrace)
+	}
+}
+
+func TestSMGenericEventGetEventTime(t *testing.T) {
+	if evTime, err := (SMGenericEvent{utils.EventTime: "2016-01-05 18:31:05"}).GetEventTime("UTC"); err != nil {
+		t.Error(err)
+	} else if !evTime.Equal(time.Date(2016, 1, 5, 18, 31, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected event time: %v", evTime)
+	}
+	if evTime, err := (SMGenericEvent{}).GetEventTime("UTC"); err != nil || !evTime.IsZero() {
+		t.Errorf("Unexpected event time: %v, error: %v", evTime, err)
+	}
+}
@@ -18,8 +18,10 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -105,10 +107,15 @@ type RateSlot struct {
 	Rate                  float64 // Rate applied
 	RateUnit              string  //  Number of billing units this rate applies to
 	RateIncrement         string  // This rate will apply in increments of duration
-	GroupIntervalStart    string  // Group position
+	GroupIntervalStart    string  // Group position, optionally followed by the tier period, the currency, the minimum charge and the usage unit (eg: 1000m;*monthly;USD;*min_charge:0.5:30s;*unit:MB)
 	rateUnitDur           time.Duration
 	rateIncrementDur      time.Duration
 	groupIntervalStartDur time.Duration
+	tierPeriod            string
+	currency              string
+	minCharge             float64
+	minChargeUsage        time.Duration
+	unit                  string
 	tag                   string // load validation only
 }
 
@@ -121,9 +128,36 @@ func (self *RateSlot) SetDurations() error {
 	if self.rateIncrementDur, err = ParseDurationWithSecs(self.RateIncrement); err != nil {
 		return err
 	}
-	if self.groupIntervalStartDur, err = ParseDurationWithSecs(self.GroupIntervalStart); err != nil {
+	grpStart := strings.Split(self.GroupIntervalStart, INFIELD_SEP)
+	if self.groupIntervalStartDur, err = ParseDurationWithSecs(grpStart[0]); err != nil {
 		return err
 	}
+	for _, opt := range grpStart[1:] {
+		switch {
+		case opt == MetaDaily || opt == MetaWeekly || opt == MetaMonthly || opt == MetaYearly:
+			self.tierPeriod = opt
+		case IsCurrencyCode(opt):
+			self.currency = opt
+		case strings.HasPrefix(opt, MetaMinCharge+InInFieldSep):
+			if err = self.setMinCharge(strings.TrimPrefix(opt, MetaMinCharge+InInFieldSep)); err != nil {
+				return err
+			}
+		case strings.HasPrefix(opt, MetaUnit+InInFieldSep):
+			self.unit = strings.TrimPrefix(opt, MetaUnit+InInFieldSep)
+		default:
+			return fmt.Errorf("unsupported group interval option: %s", opt)
+		}
+	}
+	if self.unit != "" { // usage values are given in unit, convert them to the base units
+		factor, err := UsageUnitFactor(self.unit)
+		if err != nil {
+			return err
+		}
+		for _, dur := range []*time.Duration{&self.rateUnitDur, &self.rateIncrementDur,
+			&self.groupIntervalStartDur, &self.minChargeUsage} {
+			*dur = time.Duration(float64(*dur) * factor)
+		}
+	}
 	return nil
 }
 func (self *RateSlot) RateUnitDuration() time.Duration {
@@ -136,6 +170,51 @@ func (self *RateSlot) GroupIntervalStartDuration() time.Duration {
 	return self.groupIntervalStartDur
 }
 
+// TierPeriod returns the billing period over which GroupIntervalStart counts the usage, empty for per call groups
+func (self *RateSlot) TierPeriod() string {
+	return self.tierPeriod
+}
+
+// Currency returns the currency the rate is expressed in, empty for the default one
+func (self *RateSlot) Currency() string {
+	return self.currency
+}
+
+// setMinCharge parses the AMOUNT[:USAGE] value of the *min_charge option
+func (self *RateSlot) setMinCharge(val string) (err error) {
+	vals := strings.Split(val, InInFieldSep)
+	if len(vals) > 2 {
+		return fmt.Errorf("unsupported minimum charge: %s", val)
+	}
+	if self.minCharge, err = strconv.ParseFloat(vals[0], 64); err != nil {
+		return err
+	}
+	if self.minCharge < 0 {
+		return fmt.Errorf("negative minimum charge: %s", val)
+	}
+	if len(vals) == 2 {
+		if self.minChargeUsage, err = ParseDurationWithSecs(vals[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MinCharge returns the least amount billed for a call, 0 when not set
+func (self *RateSlot) MinCharge() float64 {
+	return self.minCharge
+}
+
+// MinChargeUsage returns the call usage under which the minimum charge applies, 0 for any usage
+func (self *RateSlot) MinChargeUsage() time.Duration {
+	return self.minChargeUsage
+}
+
+// Unit returns the usage unit the durations of the slot are given in, empty for the base units
+func (self *RateSlot) Unit() string {
+	return self.unit
+}
+
 type TPDestinationRate struct {
 	TPid             string             // Tariff plan id
 	ID               string             // DestinationRate profile id
@@ -170,6 +249,9 @@ type TPTiming struct {
 	WeekDays  WeekDays
 	StartTime string
 	EndTime   string
+	DSTPolicy string // how wall-clock margins are resolved around DST changes
+	// holiday calendars whose dates are matched as their configured week day
+	HolidayCalendars []string
 }
 
 func NewTiming(timingInfo ...string) (rt *TPTiming) {
@@ -184,6 +266,12 @@ func NewTiming(timingInfo ...string) (rt *TPTiming) {
 	if len(times) > 1 {
 		rt.EndTime = times[1]
 	}
+	if len(times) > 2 {
+		rt.DSTPolicy = times[2]
+	}
+	if len(times) > 3 && times[3] != "" {
+		rt.HolidayCalendars = strings.Split(times[3], InInFieldSep)
+	}
 	return
 }
 
@@ -258,6 +346,10 @@ type TPRatingActivation struct {
 	RatingPlanId     string // Id of RatingPlan profile
 	FallbackSubjects string // So we follow the api
 	CdrStatQueueIds  string
+	DeactivationTime string // Time when this profile stops being active, empty for never
+	MaxCost          float64
+	MaxCostStrategy  string  // Cap the cost of the calls, overriding the one of the destination rates
+	TrafficShare     float64 // Percentage of the accounts rated with this activation out of the ones sharing its ActivationTime
 }
 
 // Helper to return the subject fallback keys we need in dataDb
@@ -562,6 +654,7 @@ type TPActionTiming struct {
 	ActionsId string  // Actions id
 	TimingId  string  // Timing profile id
 	Weight    float64 // Binding's weight
+	CatchUp   string  // Executions missed while the scheduler was down: <""|*skip|*run_once_on_start|*run_all_missed>
 }
 
 type TPActionTriggers struct {
@@ -703,6 +796,11 @@ type CacheStats struct {
 	Aliases             int
 	ReverseAliases      int
 	ResourceLimits      int
+	// size accounting, the bytes only when max_bytes is configured
+	DestinationsBytes     int64
+	DestinationsEvictions int64
+	RatingPlansBytes      int64
+	RatingPlansEvictions  int64
 }
 
 type AttrExpFileCdrs struct {
@@ -879,6 +977,7 @@ type AttrRateCdrs struct {
 	RerateErrors        bool     // Rerate previous CDRs with errors (makes sense for reqtype rated and pseudoprepaid
 	RerateRated         bool     // Rerate CDRs which were previously rated (makes sense for reqtype rated and pseudoprepaid)
 	SendToStats         bool     // Set to true if the CDRs should be sent to stats server
+	RatingAsOfSetupTime bool     // Rate with the rating data configured at the CDR SetupTime instead of the current one
 }
 
 func (attrRateCDRs *AttrRateCdrs) AsCDRsFilter(timezone string) (*CDRsFilter, error) {
@@ -923,10 +1022,15 @@ func (attrRateCDRs *AttrRateCdrs) AsCDRsFilter(timezone string) (*CDRsFilter, er
 }
 
 type AttrLoadTpFromFolder struct {
-	FolderPath string // Take files from folder absolute path
-	DryRun     bool   // Do not write to database but parse only
-	FlushDb    bool   // Flush previous data before loading new one
-	Validate   bool   // Run structural checks on data
+	FolderPath        string            // Take files from folder absolute path
+	DryRun            bool              // Do not write to database but parse only
+	FlushDb           bool              // Flush previous data before loading new one
+	Validate          bool              // Run structural checks on data
+	Categories        []string          // Load only these categories, eg: *destinations, empty for all
+	ExcludeCategories []string          // Categories not to be loaded
+	Variables         map[string]string // Values for the ${NAME} references inside the files
+	CompressDsts      bool              // Collapse the redundant destination prefixes
+	DisableReverses   []string          // Reverse indexes not to be rebuilt <*destinations|*account_action_plans|*aliases>
 }
 
 type AttrImportTPFromFolder struct {
@@ -934,6 +1038,7 @@ type AttrImportTPFromFolder struct {
 	FolderPath   string
 	RunId        string
 	CsvSeparator string
+	Variables    map[string]string // Values for the ${NAME} references inside the files
 }
 
 type AttrGetDestination struct {
@@ -1041,6 +1146,17 @@ type CDRsFilter struct {
 	Paginator
 }
 
+// CDRsAggregate holds the totals of one group of CDRs aggregated inside StorDB
+type CDRsAggregate struct {
+	Account     string        // populated when grouping by Account
+	Destination string        // populated when grouping by Destination
+	Day         string        // SetupTime day as YYYY-MM-DD, populated when grouping by *day
+	ExtraInfo   string        // rating error, populated when grouping by ExtraInfo
+	Count       int64         // number of CDRs in the group
+	Cost        float64       // sum of the costs, unrated CDRs not considered
+	Usage       time.Duration // sum of the usage
+}
+
 // RPCCDRsFilter is a filter used in Rpc calls
 // RPCCDRsFilter is slightly different than CDRsFilter by using string instead of Time filters
 type RPCCDRsFilter struct {
@@ -1237,9 +1353,10 @@ type AttrGetCallCost struct {
 
 type AttrRateCDRs struct {
 	RPCCDRsFilter
-	StoreCDRs     *bool
-	SendToStatS   *bool // Set to true if the CDRs should be sent to stats server
-	ReplicateCDRs *bool // Replicate results
+	StoreCDRs           *bool
+	SendToStatS         *bool // Set to true if the CDRs should be sent to stats server
+	ReplicateCDRs       *bool // Replicate results
+	RatingAsOfSetupTime *bool // Rate with the rating data configured at the CDR SetupTime instead of the current one
 }
 
 type AttrSetBalance struct {
@@ -1259,6 +1376,7 @@ type AttrSetBalance struct {
 	SharedGroups   *string
 	Blocker        *bool
 	Disabled       *bool
+	Unit           *string // usage unit of the Value (eg: MB), empty for the base units
 }
 
 type TPResourceLimit struct {
@@ -1273,6 +1391,42 @@ type TPResourceLimit struct {
 	ActionTriggerIDs   []string // Thresholds to check after changing Limit
 }
 
+// TPExchangeRate converts the amounts rated in FromCurrency towards ToCurrency
+type TPExchangeRate struct {
+	TPid         string
+	FromCurrency string
+	ToCurrency   string
+	Rate         float64 // units of ToCurrency for one unit of FromCurrency
+}
+
+// TPHolidayCalendar groups the dates to be rated as a specific week day
+type TPHolidayCalendar struct {
+	TPid     string
+	ID       string
+	Holidays []*TPHoliday
+}
+
+type TPHoliday struct {
+	Date    string // YYYY-MM-DD or MM-DD for the dates recurring each year
+	WeekDay string // week day the date is rated as, Sunday(0) if empty
+}
+
+// TPDialPlan groups the rules normalizing the numbers of one tenant to E.164
+type TPDialPlan struct {
+	TPid   string
+	Tenant string
+	Rules  []*TPDialPlanRule
+}
+
+type TPDialPlanRule struct {
+	Prefix    string  // number prefix the rule applies to, empty to match all
+	MinLength int     // minimum number length the rule applies to, 0 for no limit
+	MaxLength int     // maximum number length the rule applies to, 0 for no limit
+	Strip     int     // number of leading digits removed
+	Add       string  // prefix added after stripping, eg: the country code
+	Weight    float64 // rules with higher weight are tried first
+}
+
 type TPRequestFilter struct {
 	Type      string   // Filter type (*string, *timing, *rsr_filters, *cdr_stats)
 	FieldName string   // Name of the field providing us the Values to check (used in case of some )
@@ -1296,6 +1450,17 @@ type AttrRLsResourceUsage struct {
 	Units   float64
 }
 
+// AttrGetTPLoadData queries one of the LoadReader methods of a remote storDb
+type AttrGetTPLoadData struct {
+	Method string            // LoadReader method, eg: GetTPDestinations
+	Args   []json.RawMessage // JSON encoded method arguments
+}
+
+type AttrRLsUsageSeries struct {
+	ID        string // ResourceLimit identifier
+	TimeStart string // Only return samples taken at or after this time, optional
+}
+
 // AsActivationTime converts TPActivationInterval into ActivationInterval
 func (tpAI *TPActivationInterval) AsActivationInterval(timezone string) (ai *ActivationInterval, err error) {
 	var at, et time.Time
@@ -20,6 +20,7 @@ package utils
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewDTCSFromRPKey(t *testing.T) {
@@ -59,3 +60,59 @@ func TestPaginatorPaginateStringSlice(t *testing.T) {
 		t.Errorf("Expecting: %+v, received: %+v", eOut, rcv)
 	}
 }
+
+func TestRateSlotSetDurationsCurrency(t *testing.T) {
+	rs := &RateSlot{RateUnit: "60s", RateIncrement: "60s", GroupIntervalStart: "0s;USD"}
+	if err := rs.SetDurations(); err != nil {
+		t.Fatal(err)
+	}
+	if rs.Currency() != "USD" {
+		t.Errorf("Expecting: USD, received: %s", rs.Currency())
+	}
+	rs = &RateSlot{RateUnit: "60s", RateIncrement: "60s", GroupIntervalStart: "0s;usd"}
+	if err := rs.SetDurations(); err == nil {
+		t.Error("Expecting error for invalid currency code")
+	}
+}
+
+func TestRateSlotSetDurationsMinCharge(t *testing.T) {
+	rs := &RateSlot{RateUnit: "60s", RateIncrement: "1s", GroupIntervalStart: "0s;USD;*min_charge:0.5:30s"}
+	if err := rs.SetDurations(); err != nil {
+		t.Fatal(err)
+	}
+	if rs.MinCharge() != 0.5 || rs.MinChargeUsage() != 30*time.Second || rs.Currency() != "USD" {
+		t.Errorf("Received: %v %v %s", rs.MinCharge(), rs.MinChargeUsage(), rs.Currency())
+	}
+	rs = &RateSlot{RateUnit: "60s", RateIncrement: "1s", GroupIntervalStart: "0s;*min_charge:1"}
+	if err := rs.SetDurations(); err != nil || rs.MinCharge() != 1 || rs.MinChargeUsage() != 0 {
+		t.Error(err, rs.MinCharge(), rs.MinChargeUsage())
+	}
+	for _, grp := range []string{"0s;*min_charge:x", "0s;*min_charge:-1", "0s;*min_charge:1:2s:3s"} {
+		rs = &RateSlot{RateUnit: "60s", RateIncrement: "1s", GroupIntervalStart: grp}
+		if err := rs.SetDurations(); err == nil {
+			t.Errorf("Expecting error for %s", grp)
+		}
+	}
+}
+
+func TestRateSlotSetDurationsMilliseconds(t *testing.T) {
+	rs := &RateSlot{RateUnit: "1s", RateIncrement: "100ms", GroupIntervalStart: "0.25"}
+	if err := rs.SetDurations(); err != nil {
+		t.Fatal(err)
+	}
+	if rs.RateUnitDuration() != time.Second || rs.RateIncrementDuration() != 100*time.Millisecond ||
+		rs.GroupIntervalStartDuration() != 250*time.Millisecond {
+		t.Errorf("Received: %v %v %v", rs.RateUnitDuration(), rs.RateIncrementDuration(), rs.GroupIntervalStartDuration())
+	}
+}
+
+func TestRateSlotSetDurationsUnit(t *testing.T) {
+	rs := &RateSlot{RateUnit: "1", RateIncrement: "0.5", GroupIntervalStart: "10;*unit:KB"}
+	if err := rs.SetDurations(); err != nil {
+		t.Fatal(err)
+	}
+	if rs.Unit() != "KB" || rs.RateUnitDuration() != 1024*time.Second ||
+		rs.RateIncrementDuration() != 512*time.Second || rs.GroupIntervalStartDuration() != 10240*time.Second {
+		t.Error(rs.Unit(), rs.RateUnitDuration(), rs.RateIncrementDuration(), rs.GroupIntervalStartDuration())
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// NewCircuitBreaker creates a CircuitBreaker opening after maxFailures consecutive failed or slow calls,
+// maxFailures <= 0 disables it
+func NewCircuitBreaker(maxFailures int, slowCall, openInterval time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{maxFailures: maxFailures, slowCall: slowCall, openInterval: openInterval}
+}
+
+// CircuitBreaker stops calling a failing backend for openInterval, after which one trial call is let through
+type CircuitBreaker struct {
+	sync.Mutex
+	maxFailures  int
+	slowCall     time.Duration // calls lasting longer are counted as failed, 0 to disable
+	openInterval time.Duration
+	failures     int
+	openedAt     time.Time // zero while the circuit is closed
+	trial        bool      // half-open, trial call in progress
+}
+
+// allow decides whether a call can reach the backend
+func (cb *CircuitBreaker) allow() bool {
+	cb.Lock()
+	defer cb.Unlock()
+	if cb.openedAt.IsZero() {
+		return true
+	}
+	if cb.trial || time.Since(cb.openedAt) < cb.openInterval {
+		return false
+	}
+	cb.trial = true
+	return true
+}
+
+// record updates the state out of the result of a call
+func (cb *CircuitBreaker) record(failed bool) {
+	cb.Lock()
+	defer cb.Unlock()
+	if !failed {
+		cb.failures = 0
+		cb.openedAt = time.Time{}
+		cb.trial = false
+		return
+	}
+	cb.failures++
+	if cb.trial || cb.failures >= cb.maxFailures {
+		cb.openedAt = time.Now()
+		cb.trial = false
+	}
+}
+
+// Call executes f if the circuit allows it, returning ErrCircuitOpen otherwise
+// ErrNotFound is considered a valid answer from the backend
+func (cb *CircuitBreaker) Call(f func() error) (err error) {
+	if cb == nil || cb.maxFailures <= 0 {
+		return f()
+	}
+	if !cb.allow() {
+		return ErrCircuitOpen
+	}
+	start := time.Now()
+	err = f()
+	cb.record((err != nil && err != ErrNotFound) ||
+		(cb.slowCall != 0 && time.Since(start) > cb.slowCall))
+	return
+}
+
+// IsOpen returns true while calls are rejected
+func (cb *CircuitBreaker) IsOpen() bool {
+	if cb == nil {
+		return false
+	}
+	cb.Lock()
+	defer cb.Unlock()
+	return !cb.openedAt.IsZero()
+}
@@ -0,0 +1,82 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpenClose(t *testing.T) {
+	cb := NewCircuitBreaker(2, 0, 10*time.Millisecond)
+	errBackend := errors.New("BACKEND_DOWN")
+	failing := func() error { return errBackend }
+	if err := cb.Call(func() error { return ErrNotFound }); err != ErrNotFound {
+		t.Error(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := cb.Call(failing); err != errBackend {
+			t.Error(err)
+		}
+	}
+	if !cb.IsOpen() {
+		t.Error("Circuit should be open")
+	}
+	if err := cb.Call(func() error { return nil }); err != ErrCircuitOpen {
+		t.Errorf("Expecting ErrCircuitOpen, received: %v", err)
+	}
+	time.Sleep(15 * time.Millisecond)
+	if err := cb.Call(failing); err != errBackend { // failed trial opens it again
+		t.Error(err)
+	}
+	if err := cb.Call(func() error { return nil }); err != ErrCircuitOpen {
+		t.Errorf("Expecting ErrCircuitOpen, received: %v", err)
+	}
+	time.Sleep(15 * time.Millisecond)
+	if err := cb.Call(func() error { return nil }); err != nil {
+		t.Error(err)
+	}
+	if cb.IsOpen() {
+		t.Error("Circuit should be closed")
+	}
+}
+
+func TestCircuitBreakerSlowCall(t *testing.T) {
+	cb := NewCircuitBreaker(1, time.Millisecond, time.Second)
+	if err := cb.Call(func() error { time.Sleep(5 * time.Millisecond); return nil }); err != nil {
+		t.Error(err)
+	}
+	if !cb.IsOpen() {
+		t.Error("Circuit should be open after slow call")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var cb *CircuitBreaker
+	if err := cb.Call(func() error { return ErrServerError }); err != ErrServerError {
+		t.Error(err)
+	}
+	cb = NewCircuitBreaker(0, 0, time.Second)
+	for i := 0; i < 3; i++ {
+		cb.Call(func() error { return ErrServerError })
+	}
+	if cb.IsOpen() {
+		t.Error("Disabled circuit should stay closed")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ClockSkews tracks the clock skew towards the agents/peers sending us timestamps
+var ClockSkews = NewClockSkewMonitor(0)
+
+// ClockSkewStats is the skew observed towards one peer as returned over APIs
+type ClockSkewStats struct {
+	Skew         time.Duration // last observed, positive if the peer clock is ahead of ours
+	MaxSkew      time.Duration // highest absolute skew observed
+	LastObserved time.Time
+	Observations int64
+	Warnings     int64 // observations above threshold
+}
+
+// NewClockSkewMonitor returns a monitor warning on skews above threshold, 0 to disable
+func NewClockSkewMonitor(threshold time.Duration) *ClockSkewMonitor {
+	return &ClockSkewMonitor{threshold: threshold, peers: make(map[string]*ClockSkewStats)}
+}
+
+// ClockSkewMonitor compares the timestamps received from peers against our clock
+type ClockSkewMonitor struct {
+	sync.RWMutex
+	threshold time.Duration
+	peers     map[string]*ClockSkewStats
+}
+
+// SetThreshold changes the skew above which warnings are issued, 0 to disable the monitoring
+func (csm *ClockSkewMonitor) SetThreshold(threshold time.Duration) {
+	csm.Lock()
+	csm.threshold = threshold
+	csm.Unlock()
+}
+
+// Observe records the skew of the timestamp sent by peer, returning true if it exceeds the threshold
+// Only the first observation above threshold out of a series gets logged as warning
+func (csm *ClockSkewMonitor) Observe(peer string, peerTime time.Time) bool {
+	if peerTime.IsZero() {
+		return false
+	}
+	now := time.Now()
+	csm.Lock()
+	defer csm.Unlock()
+	if csm.threshold == 0 {
+		return false
+	}
+	stats, has := csm.peers[peer]
+	if !has {
+		stats = new(ClockSkewStats)
+		csm.peers[peer] = stats
+	}
+	skew := peerTime.Sub(now)
+	absSkew := absDuration(skew)
+	prevExceeded := stats.Observations != 0 && absDuration(stats.Skew) > csm.threshold
+	stats.Skew = skew
+	if absSkew > stats.MaxSkew {
+		stats.MaxSkew = absSkew
+	}
+	stats.LastObserved = now
+	stats.Observations++
+	if absSkew <= csm.threshold {
+		return false
+	}
+	stats.Warnings++
+	if !prevExceeded {
+		Logger.Warning(fmt.Sprintf("<ClockSkew> Clock of peer <%s> differs by %v from ours, activation times and session usage may be affected", peer, skew))
+	}
+	return true
+}
+
+// Stats returns a copy of the skews observed per peer
+func (csm *ClockSkewMonitor) Stats() map[string]*ClockSkewStats {
+	csm.RLock()
+	defer csm.RUnlock()
+	stats := make(map[string]*ClockSkewStats, len(csm.peers))
+	for peer, peerStats := range csm.peers {
+		stsCpy := *peerStats
+		stats[peer] = &stsCpy
+	}
+	return stats
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
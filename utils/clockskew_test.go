@@ -0,0 +1,51 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockSkewMonitor(t *testing.T) {
+	csm := NewClockSkewMonitor(0)
+	if csm.Observe("peer1", time.Now().Add(time.Hour)) || len(csm.Stats()) != 0 {
+		t.Error("Observed with monitoring disabled")
+	}
+	csm.SetThreshold(2 * time.Second)
+	if csm.Observe("peer1", time.Now()) {
+		t.Error("Skew above threshold")
+	}
+	if !csm.Observe("peer1", time.Now().Add(-time.Minute)) {
+		t.Error("Skew below threshold")
+	}
+	if csm.Observe("peer1", time.Time{}) { // missing timestamp
+		t.Error("Skew above threshold")
+	}
+	stats := csm.Stats()
+	if peerStats, has := stats["peer1"]; !has {
+		t.Errorf("Unexpected stats: %+v", stats)
+	} else if peerStats.Observations != 2 || peerStats.Warnings != 1 ||
+		peerStats.Skew > -time.Minute+time.Second || peerStats.MaxSkew < time.Minute-time.Second {
+		t.Errorf("Unexpected stats: %+v", peerStats)
+	}
+	stats["peer1"].Warnings = 10 // copies returned
+	if csm.Stats()["peer1"].Warnings != 1 {
+		t.Error("Stats not copied")
+	}
+}
@@ -18,7 +18,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package utils
 
 var (
-	CDRExportFormats = []string{DRYRUN, MetaFileCSV, MetaFileFWV, MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap}
+	CDRExportFormats = []string{DRYRUN, MetaFileCSV, MetaFileFWV, MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap, MetaNATSjsonCDR, MetaNATSjsonMap}
 	PrimaryCdrFields = []string{CGRID, CDRSOURCE, CDRHOST, ACCID, TOR, REQTYPE, DIRECTION, TENANT, CATEGORY, ACCOUNT, SUBJECT, DESTINATION, SETUP_TIME, PDD, ANSWER_TIME, USAGE,
 		SUPPLIER, DISCONNECT_CAUSE, COST, RATED, PartialField, MEDI_RUNID}
 	GitLastLog                  string // If set, it will be processed as part of versioning
@@ -29,12 +29,16 @@ var (
 		META_HTTP_POST:  CONTENT_FORM,
 		MetaAMQPjsonCDR: CONTENT_JSON,
 		MetaAMQPjsonMap: CONTENT_JSON,
+		MetaNATSjsonCDR: CONTENT_JSON,
+		MetaNATSjsonMap: CONTENT_JSON,
 	}
 	CDREFileSuffixes = map[string]string{
 		MetaHTTPjsonCDR: JSNSuffix,
 		MetaHTTPjsonMap: JSNSuffix,
 		MetaAMQPjsonCDR: JSNSuffix,
 		MetaAMQPjsonMap: JSNSuffix,
+		MetaNATSjsonCDR: JSNSuffix,
+		MetaNATSjsonMap: JSNSuffix,
 		META_HTTP_POST:  FormSuffix,
 		MetaFileCSV:     CSVSuffix,
 		MetaFileFWV:     FWVSuffix,
@@ -81,6 +85,9 @@ const (
 	TBLTPUsers                    = "tp_users"
 	TBLTPAliases                  = "tp_aliases"
 	TBLTPResourceLimits           = "tp_resource_limits"
+	TBLTPExchangeRates            = "tp_exchange_rates"
+	TBLTPHolidayCalendars         = "tp_holiday_calendars"
+	TBLTPDialPlans                = "tp_dial_plans"
 	TBLSMCosts                    = "sm_costs"
 	TBLCDRs                       = "cdrs"
 	TBLVersions                   = "versions"
@@ -101,14 +108,21 @@ const (
 	USERS_CSV                     = "Users.csv"
 	ALIASES_CSV                   = "Aliases.csv"
 	ResourceLimitsCsv             = "ResourceLimits.csv"
+	ExchangeRatesCsv              = "ExchangeRates.csv"
+	HolidayCalendarsCsv           = "HolidayCalendars.csv"
+	DialPlansCsv                  = "DialPlans.csv"
 	ROUNDING_UP                   = "*up"
 	ROUNDING_MIDDLE               = "*middle"
 	ROUNDING_DOWN                 = "*down"
+	ROUNDING_HALF_EVEN            = "*half_even"
+	ROUNDING_TRUNCATE             = "*truncate"
 	ANY                           = "*any"
 	UNLIMITED                     = "*unlimited"
 	ZERO                          = "*zero"
 	ASAP                          = "*asap"
 	USERS                         = "*users"
+	UserFieldPrefix               = "*user."  // references a user profile attribute inside derived chargers, eg: *user.billing_group
+	AliasFieldPrefix              = "*alias." // references an alias output inside derived chargers, eg: *alias.Subject
 	COMMENT_CHAR                  = '#'
 	CSV_SEP                       = ','
 	FALLBACK_SEP                  = ';'
@@ -129,8 +143,11 @@ const (
 	ACCID                         = "OriginID"
 	InitialOriginID               = "InitialOriginID"
 	OriginIDPrefix                = "OriginIDPrefix"
+	MasterOriginID                = "MasterOriginID"
+	MergedIntoAccount             = "MergedIntoAccount"
 	CDRSOURCE                     = "Source"
 	CDRHOST                       = "OriginHost"
+	EventTime                     = "EventTime"
 	REQTYPE                       = "RequestType"
 	DIRECTION                     = "Direction"
 	TENANT                        = "Tenant"
@@ -147,6 +164,9 @@ const (
 	MEDI_RUNID                    = "RunID"
 	COST                          = "Cost"
 	COST_DETAILS                  = "CostDetails"
+	ShadowCost                    = "ShadowCost"
+	SplitChargingID               = "SplitChargingID"
+	CostShare                     = "CostShare"
 	RATED                         = "rated"
 	RATED_FLD                     = "Rated"
 	PartialField                  = "Partial"
@@ -159,6 +179,8 @@ const (
 	DRYRUN                        = "dry_run"
 	META_COMBIMED                 = "*combimed"
 	MetaInternal                  = "*internal"
+	MetaTree                      = "*tree"
+	MetaSharded                   = "*sharded"
 	ZERO_RATING_SUBJECT_PREFIX    = "*zero"
 	OK                            = "OK"
 	CDRE_FIXED_WIDTH              = "fwv"
@@ -178,6 +200,7 @@ const (
 	VOICE                         = "*voice"
 	MAX_COST_FREE                 = "*free"
 	MAX_COST_DISCONNECT           = "*disconnect"
+	MAX_COST_CAP                  = "*cap"
 	HOURS                         = "hours"
 	MINUTES                       = "minutes"
 	NANOSECONDS                   = "nanoseconds"
@@ -213,12 +236,19 @@ const (
 	REVERSE_ALIASES_PREFIX        = "rls_"
 	ResourceLimitsPrefix          = "rlm_"
 	ResourceLimitsIndex           = "rli_"
+	ResourceUsageSeriesPrefix     = "rus_"
+	ExchangeRatesPrefix           = "exr_"
+	HolidayCalendarsPrefix        = "hol_"
+	DialPlansPrefix               = "dpl_"
+	SplitChargingsPrefix          = "spc_"
+	RatingPlanPartPrefix          = "rpp_"
 	CDR_STATS_PREFIX              = "cst_"
 	TEMP_DESTINATION_PREFIX       = "tmp_"
 	LOG_CALL_COST_PREFIX          = "cco_"
 	LOG_ACTION_TIMMING_PREFIX     = "ltm_"
 	LOG_ACTION_TRIGGER_PREFIX     = "ltr_"
 	VERSION_PREFIX                = "ver_"
+	TPSnapshotPrefix              = "tps_"
 	LOG_ERR                       = "ler_"
 	LOG_CDR                       = "cdr_"
 	LOG_MEDIATED_CDR              = "mcd_"
@@ -242,6 +272,10 @@ const (
 	MetaHTTPjsonMap               = "*http_json_map"
 	MetaAMQPjsonCDR               = "*amqp_json_cdr"
 	MetaAMQPjsonMap               = "*amqp_json_map"
+	MetaNATSjsonCDR               = "*nats_json_cdr"
+	MetaNATSjsonMap               = "*nats_json_map"
+	MetaJSONCDR                   = "*json_cdr"
+	MetaJSONMap                   = "*json_map"
 	NANO_MULTIPLIER               = 1000000000
 	CGR_AUTHORIZE                 = "CGR_AUTHORIZE"
 	CONFIG_DIR                    = "/etc/cgrates/"
@@ -275,6 +309,7 @@ const (
 	MetaEmpty                     = "*empty"
 	CALL                          = "call"
 	EXTRA_FIELDS                  = "ExtraFields"
+	ExtraInfo                     = "ExtraInfo"
 	META_SURETAX                  = "*sure_tax"
 	SURETAX                       = "suretax"
 	DIAMETER_AGENT                = "diameter_agent"
@@ -307,12 +342,17 @@ const (
 	UpdatedAt                    = "UpdatedAt"
 	HandlerArgSep                = "|"
 	FlagForceDuration            = "fd"
+	FlagDebug                    = "debug"
+	MetaMail                     = "*mail"
+	MetaHTTP                     = "*http"
+	MetaSMPP                     = "*smpp"
 	InstanceID                   = "InstanceID"
 	ActiveGoroutines             = "ActiveGoroutines"
 	SessionTTL                   = "SessionTTL"
 	SessionTTLMaxDelay           = "SessionTTLMaxDelay"
 	SessionTTLLastUsed           = "SessionTTLLastUsed"
 	SessionTTLUsage              = "SessionTTLUsage"
+	ReviewReason                 = "ReviewReason"
 	HandlerSubstractUsage        = "*substract_usage"
 	XML                          = "xml"
 	MetaGOBrpc                   = "*gob"
@@ -348,6 +388,7 @@ const (
 	SchedulerNotRunningCaps      = "SCHEDULLER_NOT_RUNNING"
 	MetaScheduler                = "*scheduler"
 	MetaCostDetails              = "*cost_details"
+	RatingVariant                = "RatingVariant"
 	MetaAccounts                 = "*accounts"
 	Migrator                     = "migrator"
 	UnsupportedMigrationTask     = "unsupported migration task"
@@ -361,6 +402,11 @@ const (
 	FormSuffix                   = ".form"
 	CSVSuffix                    = ".csv"
 	FWVSuffix                    = ".fwv"
+	ZipSuffix                    = ".zip"
+	TarSuffix                    = ".tar"
+	TarGzSuffix                  = ".tar.gz"
+	TgzSuffix                    = ".tgz"
+	XLSXSuffix                   = ".xlsx"
 	CONTENT_JSON                 = "json"
 	CONTENT_FORM                 = "form"
 	CONTENT_TEXT                 = "text"
@@ -372,4 +418,69 @@ const (
 	Accounts                     = "Accounts"
 	MetaEveryMinute              = "*every_minute"
 	MetaHourly                   = "*hourly"
+	MetaDaily                    = "*daily"
+	MetaDay                      = "*day"
+	MetaJobRunning               = "*running"
+	MetaJobCompleted             = "*completed"
+	MetaJobFailed                = "*failed"
+	MetaJobCancelled             = "*cancelled"
+	MetaWeekly                   = "*weekly"
+	MetaMonthly                  = "*monthly"
+	MetaYearly                   = "*yearly"
+	MetaMinCharge                = "*min_charge"
+	MetaUnit                     = "*unit"
+	BalancesPoster               = "blc"
+	TPLoadsPoster                = "tpl"
+	MetaTPLoadStarted            = "*tp_load_started"
+	MetaTPLoadSucceeded          = "*tp_load_succeeded"
+	MetaTPLoadFailed             = "*tp_load_failed"
+	MetaRolloverFull             = "*full"
+	MetaRolloverCapped           = "*capped"
+	MetaRolloverExpiring         = "*expiring"
+	MetaDebit                    = "*debit"
+	MetaRefund                   = "*refund"
+	MetaRefundRounding           = "*refund_rounding"
+	MetaActions                  = "*actions"
+	MetaDestinations             = "*destinations"
+	MetaTimings                  = "*timings"
+	MetaRates                    = "*rates"
+	MetaDestinationRates         = "*destination_rates"
+	MetaRatingPlans              = "*rating_plans"
+	MetaRatingProfiles           = "*rating_profiles"
+	MetaSharedGroups             = "*shared_groups"
+	MetaLCRs                     = "*lcrs"
+	MetaActionPlans              = "*action_plans"
+	MetaActionTriggers           = "*action_triggers"
+	MetaAccountActions           = "*account_actions"
+	MetaAccountActionPlans       = "*account_action_plans"
+	MetaDerivedChargers          = "*derived_chargers"
+	MetaCdrStats                 = "*cdr_stats"
+	MetaUsers                    = "*users"
+	MetaAliases                  = "*aliases"
+	MetaResourceLimits           = "*resource_limits"
+	MetaSkip                     = "*skip"
+	MetaRunOnceOnStart           = "*run_once_on_start"
+	MetaRunAllMissed             = "*run_all_missed"
+	MetaExchangeRates            = "*exchange_rates"
+	MetaHolidayCalendars         = "*holiday_calendars"
+	MetaDialPlans                = "*dial_plans"
+	MetaBalanceExpiry            = "*balance_expiry"
+	MetaActionTrigger            = "*action_trigger"
+	MetaPrefix                   = "*prefix"
+	MetaIP                       = "*ip"
+	MetaIPv4                     = "*ip4"
+	MetaIPv6                     = "*ip6"
+	MetaFirst                    = "*first"
+	MetaLast                     = "*last"
+	MetaLastUpdate               = "*last_update"
+	MetaMaxReservation           = "*max_reservation"
+	MetaSessionTTL               = "*session_ttl"
+	MetaError                    = "*error"
+	MetaWallClock                = "*wall_clock"
+	MetaUTCOffset                = "*utc_offset"
+	MetaFirstOccurrence          = "*first_occurrence"
+	MetaLastOccurrence           = "*last_occurrence"
+	MetaUnknown                  = "*unknown"
+	MetaSum                      = "*sum"
+	TpLoadLockPrefix             = "tpl_"
 )
@@ -111,6 +111,18 @@ func UUIDSha1Prefix() string {
 	return Sha1(GenUUID())[:7]
 }
 
+var currencyCodeRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// IsCurrencyCode checks for an ISO 4217 like currency code, eg: EUR
+func IsCurrencyCode(code string) bool {
+	return currencyCodeRegexp.MatchString(code)
+}
+
+// IsCatchUpPolicy checks for a scheduler catch up policy, empty meaning the default *skip
+func IsCatchUpPolicy(policy string) bool {
+	return policy == "" || policy == MetaSkip || policy == MetaRunOnceOnStart || policy == MetaRunAllMissed
+}
+
 // Round return rounded version of x with prec precision.
 //
 // Special cases are:
@@ -142,6 +154,21 @@ func Round(x float64, prec int, method string) float64 {
 		} else {
 			rounder = math.Floor(intermed)
 		}
+	case ROUNDING_HALF_EVEN: // bankers rounding, ties go to the even neighbour
+		eps := math.Pow10(-maxPrec)
+		rounder = math.Floor(intermed)
+		diff := intermed - rounder
+		if diff > 0.5+eps ||
+			(math.Abs(diff-0.5) <= eps && math.Mod(rounder, 2) != 0) {
+			rounder++
+		}
+	case ROUNDING_TRUNCATE: // drop the extra decimals, rounding towards zero
+		eps := math.Pow10(-maxPrec)
+		if intermed < 0 {
+			rounder = math.Ceil(intermed - eps)
+		} else {
+			rounder = math.Floor(intermed + eps)
+		}
 	default:
 		rounder = intermed
 	}
@@ -206,6 +233,60 @@ func ParseTimeDetectLayout(tmStr string, timezone string) (time.Time, error) {
 	return nilTime, errors.New("Unsupported time format")
 }
 
+// ParseTimeDetectLayoutStrict parses like ParseTimeDetectLayout, additionally accepting an explicit timezone suffix,
+// eg: 2017-03-26 01:30:00[Europe/Berlin], and rejecting the local times skipped or repeated by DST transitions
+func ParseTimeDetectLayoutStrict(tmStr string, timezone string) (time.Time, error) {
+	tmStr = strings.TrimSpace(tmStr)
+	if strings.HasSuffix(tmStr, "]") {
+		if idx := strings.LastIndex(tmStr, "["); idx != -1 {
+			tmStr, timezone = strings.TrimSpace(tmStr[:idx]), tmStr[idx+1:len(tmStr)-1]
+		}
+	}
+	t, err := ParseTimeDetectLayout(tmStr, timezone)
+	if err != nil || t.IsZero() || tmStr == META_NOW {
+		return t, err
+	}
+	// local layouts are the ones resolving to different instants in different timezones
+	wall, err := ParseTimeDetectLayout(tmStr, "UTC")
+	if err != nil {
+		return t, err
+	}
+	if farT, err := ParseTimeDetectLayout(tmStr, "Etc/GMT-14"); err != nil || farT.Equal(wall) {
+		return t, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return t, err
+	}
+	if err := checkLocalTime(wall, loc); err != nil {
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+// checkLocalTime makes sure the wall clock (expressed in UTC) matches exactly one instant within loc
+func checkLocalTime(wall time.Time, loc *time.Location) error {
+	const wallLayout = "2006-01-02 15:04:05.999999999"
+	wallStr := wall.Format(wallLayout)
+	var matches []time.Time
+	for _, probe := range []time.Duration{-12 * time.Hour, 12 * time.Hour} { // offsets in use before and after a possible transition
+		_, offset := wall.Add(probe).In(loc).Zone()
+		cand := wall.Add(-time.Duration(offset) * time.Second)
+		if cand.In(loc).Format(wallLayout) != wallStr ||
+			(len(matches) != 0 && matches[0].Equal(cand)) {
+			continue
+		}
+		matches = append(matches, cand)
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("local time <%s> does not exist in <%s>, skipped by DST transition", wallStr, loc)
+	case 2:
+		return fmt.Errorf("local time <%s> is ambiguous in <%s>, repeated by DST transition", wallStr, loc)
+	}
+	return nil
+}
+
 func ParseDate(date string) (expDate time.Time, err error) {
 	date = strings.TrimSpace(date)
 	switch {
@@ -272,6 +353,26 @@ func ParseDurationWithSecs(durStr string) (time.Duration, error) {
 	return time.ParseDuration(durStr)
 }
 
+// UsageUnits holds the base units (as reported by the agents, eg: bytes for *data) within one named unit
+var UsageUnits = map[string]float64{
+	"B":  1,
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+	"TB": 1 << 40,
+}
+
+// UsageUnitFactor returns the base units within one unit, given either by name or as numeric factor
+func UsageUnitFactor(unit string) (float64, error) {
+	if factor, has := UsageUnits[unit]; has {
+		return factor, nil
+	}
+	if factor, err := strconv.ParseFloat(unit, 64); err == nil && factor > 0 {
+		return factor, nil
+	}
+	return 0, fmt.Errorf("unsupported usage unit: %s", unit)
+}
+
 func AccountKey(tenant, account string) string {
 	return fmt.Sprintf("%s:%s", tenant, account)
 }
@@ -602,6 +703,41 @@ func GetEndOfMonth(ref time.Time) time.Time {
 	return eom.Add(-time.Second)
 }
 
+// GetPeriodStart returns the beginning of the *hourly, *daily, *weekly(starting Monday), *monthly or *yearly period containing ref
+func GetPeriodStart(period string, ref time.Time) (time.Time, error) {
+	year, month, day := ref.Date()
+	switch period {
+	case MetaHourly:
+		return time.Date(year, month, day, ref.Hour(), 0, 0, 0, ref.Location()), nil
+	case MetaDaily:
+		return time.Date(year, month, day, 0, 0, 0, 0, ref.Location()), nil
+	case MetaWeekly:
+		return time.Date(year, month, day-(int(ref.Weekday())+6)%7, 0, 0, 0, 0, ref.Location()), nil
+	case MetaMonthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, ref.Location()), nil
+	case MetaYearly:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, ref.Location()), nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported period: %s", period)
+}
+
+// AddPeriod returns the time one period after ref
+func AddPeriod(period string, ref time.Time) (time.Time, error) {
+	switch period {
+	case MetaHourly:
+		return ref.Add(time.Hour), nil
+	case MetaDaily:
+		return ref.AddDate(0, 0, 1), nil
+	case MetaWeekly:
+		return ref.AddDate(0, 0, 7), nil
+	case MetaMonthly:
+		return ref.AddDate(0, 1, 0), nil
+	case MetaYearly:
+		return ref.AddDate(1, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported period: %s", period)
+}
+
 // formats number in K,M,G, etc.
 func SizeFmt(num float64, suffix string) string {
 	if suffix == "" {
@@ -83,6 +83,28 @@ func TestRoundByMethodDown2(t *testing.T) {
 	}
 }
 
+func TestRoundByMethodHalfEven(t *testing.T) {
+	for x, expected := range map[float64]float64{
+		12.25: 12.2, 12.35: 12.4, 12.251: 12.3, -12.25: -12.2, 2.675: 2.68} {
+		prec := 1
+		if x == 2.675 {
+			prec = 2
+		}
+		if result := Round(x, prec, ROUNDING_HALF_EVEN); result != expected {
+			t.Errorf("Error rounding half even %v: sould be %v was %v", x, expected, result)
+		}
+	}
+}
+
+func TestRoundByMethodTruncate(t *testing.T) {
+	for x, expected := range map[float64]float64{
+		12.29: 12.2, 0.29: 0.2, -12.29: -12.2} {
+		if result := Round(x, 1, ROUNDING_TRUNCATE); result != expected {
+			t.Errorf("Error truncating %v: sould be %v was %v", x, expected, result)
+		}
+	}
+}
+
 func TestParseTimeDetectLayout(t *testing.T) {
 	tmStr := "2013-12-30T15:00:01Z"
 	expectedTime := time.Date(2013, 12, 30, 15, 0, 1, 0, time.UTC)
@@ -815,3 +837,78 @@ func TestGZIPGUnZIP(t *testing.T) {
 		t.Error("not matching initial source")
 	}
 }
+
+func TestGetPeriodStart(t *testing.T) {
+	ref := time.Date(2016, 11, 17, 14, 35, 12, 0, time.UTC) // Thursday
+	for period, eStart := range map[string]time.Time{
+		MetaHourly:  time.Date(2016, 11, 17, 14, 0, 0, 0, time.UTC),
+		MetaDaily:   time.Date(2016, 11, 17, 0, 0, 0, 0, time.UTC),
+		MetaWeekly:  time.Date(2016, 11, 14, 0, 0, 0, 0, time.UTC),
+		MetaMonthly: time.Date(2016, 11, 1, 0, 0, 0, 0, time.UTC),
+		MetaYearly:  time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if start, err := GetPeriodStart(period, ref); err != nil {
+			t.Error(err)
+		} else if !start.Equal(eStart) {
+			t.Errorf("Period %s, expecting: %v, received: %v", period, eStart, start)
+		}
+	}
+	if _, err := GetPeriodStart("*fortnightly", ref); err == nil {
+		t.Error("Expecting error on unsupported period")
+	}
+}
+
+func TestParseTimeDetectLayoutStrict(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eTime := time.Date(2017, 3, 26, 3, 30, 0, 0, berlin)
+	if rcv, err := ParseTimeDetectLayoutStrict("2017-03-26 03:30:00", "Europe/Berlin"); err != nil {
+		t.Error(err)
+	} else if !rcv.Equal(eTime) {
+		t.Errorf("Expecting: %v, received: %v", eTime, rcv)
+	}
+	if rcv, err := ParseTimeDetectLayoutStrict("2017-03-26 03:30:00[Europe/Berlin]", "UTC"); err != nil {
+		t.Error(err)
+	} else if !rcv.Equal(eTime) {
+		t.Errorf("Expecting: %v, received: %v", eTime, rcv)
+	}
+	if _, err := ParseTimeDetectLayoutStrict("2017-03-26 02:30:00", "Europe/Berlin"); err == nil ||
+		err.Error() != "local time <2017-03-26 02:30:00> does not exist in <Europe/Berlin>, skipped by DST transition" {
+		t.Error(err)
+	}
+	if _, err := ParseTimeDetectLayoutStrict("2017-10-29 02:30:00[Europe/Berlin]", ""); err == nil ||
+		err.Error() != "local time <2017-10-29 02:30:00> is ambiguous in <Europe/Berlin>, repeated by DST transition" {
+		t.Error(err)
+	}
+	// explicit offsets and timestamps are not affected by transitions
+	eTime = time.Date(2017, 10, 29, 0, 30, 0, 0, time.UTC)
+	if rcv, err := ParseTimeDetectLayoutStrict("2017-10-29T02:30:00+02:00", "Europe/Berlin"); err != nil {
+		t.Error(err)
+	} else if !rcv.Equal(eTime) {
+		t.Errorf("Expecting: %v, received: %v", eTime, rcv)
+	}
+	if rcv, err := ParseTimeDetectLayoutStrict("1509237000", "Europe/Berlin"); err != nil {
+		t.Error(err)
+	} else if !rcv.Equal(eTime) {
+		t.Errorf("Expecting: %v, received: %v", eTime, rcv)
+	}
+	if _, err := ParseTimeDetectLayoutStrict("2017-10-29 02:30:00[Unknown/Zone]", ""); err == nil {
+		t.Error("Expecting error on unknown timezone")
+	}
+}
+
+func TestUsageUnitFactor(t *testing.T) {
+	if f, err := UsageUnitFactor("MB"); err != nil || f != 1048576 {
+		t.Error(err, f)
+	}
+	if f, err := UsageUnitFactor("1000"); err != nil || f != 1000 {
+		t.Error(err, f)
+	}
+	for _, unit := range []string{"", "mb", "0", "-1"} {
+		if _, err := UsageUnitFactor(unit); err == nil {
+			t.Errorf("Expecting error for unit: <%s>", unit)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"math"
+	"math/big"
+)
+
+// MaxDecimalPrecision keeps room in the int64 for values up to 9.2e9
+const MaxDecimalPrecision = 9
+
+var (
+	decimalPrecision = 9
+	decimalScale     = int64(1000000000)
+)
+
+// SetDecimalPrecision sets the number of decimals kept by Decimal values, limited to 0-MaxDecimalPrecision
+func SetDecimalPrecision(prec int) {
+	if prec < 0 {
+		prec = 0
+	} else if prec > MaxDecimalPrecision {
+		prec = MaxDecimalPrecision
+	}
+	decimalPrecision = prec
+	decimalScale = int64(math.Pow10(prec))
+}
+
+// DecimalPrecision returns the number of decimals kept by Decimal values
+func DecimalPrecision() int {
+	return decimalPrecision
+}
+
+// maxDecimalScaled is 2^63, the first scaled float64 not fitting a Decimal
+const maxDecimalScaled = float64(1 << 63)
+
+// Decimal is a fixed-point number used for rate arithmetic and cost accumulation,
+// avoiding the rounding drift of float64 sums
+// The rates and costs are still stored as float64 (Rate.Value, Increment.Cost, TimeSpan.Cost),
+// only the computations over them are done in Decimal
+type Decimal int64
+
+// NewDecimalFromFloat64 converts f rounding half away from zero at the global precision,
+// ErrDecimalOverflow if f does not fit a Decimal
+func NewDecimalFromFloat64(f float64) (Decimal, error) {
+	if f < 0 {
+		d, err := NewDecimalFromFloat64(-f)
+		return -d, err
+	}
+	scaled := math.Floor(f*float64(decimalScale) + 0.5)
+	if !(scaled < maxDecimalScaled) { // NaN included
+		return 0, ErrDecimalOverflow
+	}
+	return Decimal(scaled), nil
+}
+
+// Float64 converts back to float64
+func (d Decimal) Float64() float64 {
+	return float64(d) / float64(decimalScale)
+}
+
+// Add returns d+o, ErrDecimalOverflow if the sum does not fit a Decimal
+func (d Decimal) Add(o Decimal) (Decimal, error) {
+	s := d + o
+	if (o > 0 && s < d) || (o < 0 && s > d) {
+		return 0, ErrDecimalOverflow
+	}
+	return s, nil
+}
+
+// Sub returns d-o, ErrDecimalOverflow if the difference does not fit a Decimal
+func (d Decimal) Sub(o Decimal) (Decimal, error) {
+	s := d - o
+	if (o > 0 && s > d) || (o < 0 && s < d) {
+		return 0, ErrDecimalOverflow
+	}
+	return s, nil
+}
+
+// MulInt returns d*n, ErrDecimalOverflow if the product does not fit a Decimal
+func (d Decimal) MulInt(n int64) (Decimal, error) {
+	if d == 0 || n == 0 {
+		return 0, nil
+	}
+	p := d * Decimal(n)
+	if p/Decimal(n) != d || (n == -1 && d == math.MinInt64) {
+		return 0, ErrDecimalOverflow
+	}
+	return p, nil
+}
+
+// MulDiv returns d*num/den rounded half away from zero, without overflowing the intermediate product,
+// ErrDecimalOverflow if the result does not fit a Decimal
+func (d Decimal) MulDiv(num, den int64) (Decimal, error) {
+	if den == 0 {
+		return 0, nil
+	}
+	prod := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(num))
+	bigDen := big.NewInt(den)
+	quo, rem := new(big.Int).QuoRem(prod, bigDen, new(big.Int))
+	// compare 2*|rem| with |den| to decide the rounding direction
+	rem.Abs(rem).Lsh(rem, 1)
+	if rem.Cmp(bigDen.Abs(bigDen)) >= 0 {
+		if (prod.Sign() < 0) != (den < 0) {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	if !quo.IsInt64() {
+		return 0, ErrDecimalOverflow
+	}
+	return Decimal(quo.Int64()), nil
+}
+
+// DecimalSum adds up float64 values in Decimal, falling back to float64 arithmetic once out of the fixed-point range
+// The zero value is an empty sum
+type DecimalSum struct {
+	dec      Decimal
+	flt      float64 // the sum after the overflow
+	overflow bool
+}
+
+// AddMul adds f multiplied by n to the sum
+func (ds *DecimalSum) AddMul(f float64, n int64) {
+	if !ds.overflow {
+		d, err := NewDecimalFromFloat64(f)
+		if err == nil {
+			d, err = d.MulInt(n)
+		}
+		if err == nil {
+			d, err = ds.dec.Add(d)
+		}
+		if err == nil {
+			ds.dec = d
+			return
+		}
+		ds.overflow = true
+		ds.flt = ds.dec.Float64()
+	}
+	ds.flt += f * float64(n)
+}
+
+// Float64 returns the sum
+func (ds *DecimalSum) Float64() float64 {
+	if ds.overflow {
+		return ds.flt
+	}
+	return ds.dec.Float64()
+}
@@ -0,0 +1,95 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecimalArithmetic(t *testing.T) {
+	// float64 sum drifts: 0.1 added ten times is not 1
+	var fSum float64
+	var dSum DecimalSum
+	for i := 0; i < 10; i++ {
+		fSum += 0.1
+		dSum.AddMul(0.1, 1)
+	}
+	if fSum == 1 {
+		t.Error("Expecting float drift")
+	}
+	if dSum.Float64() != 1 {
+		t.Errorf("Expecting 1, received: %v", dSum.Float64())
+	}
+	// 0.6 per minute for 20 seconds
+	rate, _ := NewDecimalFromFloat64(0.6)
+	if cost, err := rate.MulDiv(int64(20*time.Second), int64(time.Minute)); err != nil || cost.Float64() != 0.2 {
+		t.Errorf("Expecting 0.2, received: %v, err: %v", cost.Float64(), err)
+	}
+	rate, _ = NewDecimalFromFloat64(-0.000000001)
+	if cost, err := rate.MulDiv(1, 2); err != nil || cost.Float64() != -0.000000001 {
+		t.Errorf("Expecting rounding away from zero, received: %v, err: %v", cost.Float64(), err)
+	}
+	rate, _ = NewDecimalFromFloat64(1000000)
+	if _, err := rate.MulDiv(int64(24*time.Hour), int64(time.Second)); err != ErrDecimalOverflow {
+		t.Errorf("Expecting overflow, received: %v", err)
+	}
+	rate, _ = NewDecimalFromFloat64(0.25)
+	discount, _ := NewDecimalFromFloat64(0.05)
+	if cost, err := rate.MulInt(3); err != nil {
+		t.Error(err)
+	} else if cost, err = cost.Sub(discount); err != nil || cost.Float64() != 0.7 {
+		t.Errorf("Expecting 0.7, received: %v, err: %v", cost.Float64(), err)
+	}
+	defer SetDecimalPrecision(DecimalPrecision())
+	SetDecimalPrecision(2)
+	if d, err := NewDecimalFromFloat64(1.006); err != nil || d.Float64() != 1.01 {
+		t.Errorf("Expecting 1.01, received: %v, err: %v", d.Float64(), err)
+	}
+	if SetDecimalPrecision(15); DecimalPrecision() != MaxDecimalPrecision {
+		t.Errorf("Expecting precision capped at %d, received: %d", MaxDecimalPrecision, DecimalPrecision())
+	}
+}
+
+func TestDecimalOverflow(t *testing.T) {
+	for _, f := range []float64{1e10, -1e10, math.NaN(), math.Inf(1)} {
+		if _, err := NewDecimalFromFloat64(f); err != ErrDecimalOverflow {
+			t.Errorf("Expecting overflow converting %v, received: %v", f, err)
+		}
+	}
+	if _, err := Decimal(math.MaxInt64).Add(1); err != ErrDecimalOverflow {
+		t.Errorf("Expecting overflow, received: %v", err)
+	}
+	if _, err := Decimal(math.MinInt64).Sub(1); err != ErrDecimalOverflow {
+		t.Errorf("Expecting overflow, received: %v", err)
+	}
+	if _, err := Decimal(math.MinInt64).MulInt(-1); err != ErrDecimalOverflow {
+		t.Errorf("Expecting overflow, received: %v", err)
+	}
+	if _, err := Decimal(math.MaxInt64 / 2).MulInt(3); err != ErrDecimalOverflow {
+		t.Errorf("Expecting overflow, received: %v", err)
+	}
+	// the sum goes on in float64 once out of range
+	var sum DecimalSum
+	sum.AddMul(0.5, 2)
+	sum.AddMul(5e9, 2)
+	if rcv := sum.Float64(); rcv != 1e10+1 {
+		t.Errorf("Expecting %v, received: %v", 1e10+1, rcv)
+	}
+}
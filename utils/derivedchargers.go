@@ -146,6 +146,7 @@ type DerivedCharger struct {
 	DisconnectCauseField    string      // Field containing disconnect cause information
 	CostField               string      // Field containing cost information
 	RatedField              string      // Field marking rated request in CDR
+	SplitChargingID         string      // Charge the run proportionally to the payers of this split charging
 	rsrRunFilters           []*RSRField // Storage for compiled Regexp in case of RSRFields
 	rsrRequestTypeField     *RSRField
 	rsrDirectionField       *RSRField
@@ -181,7 +182,8 @@ func (dc *DerivedCharger) Equal(other *DerivedCharger) bool {
 		dc.SupplierField == other.SupplierField &&
 		dc.DisconnectCauseField == other.DisconnectCauseField &&
 		dc.CostField == other.CostField &&
-		dc.RatedField == other.RatedField
+		dc.RatedField == other.RatedField &&
+		dc.SplitChargingID == other.SplitChargingID
 }
 
 func DerivedChargersKey(direction, tenant, category, account, subject string) string {
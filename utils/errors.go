@@ -20,6 +20,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -28,6 +29,7 @@ var (
 	ErrTimedOut                = errors.New("TIMED_OUT")
 	ErrServerError             = errors.New("SERVER_ERROR")
 	ErrMaxRecursionDepth       = errors.New("MAX_RECURSION_DEPTH")
+	ErrFallbackLoop            = errors.New("FALLBACK_LOOP")
 	ErrMandatoryIeMissing      = errors.New("MANDATORY_IE_MISSING")
 	ErrExists                  = errors.New("EXISTS")
 	ErrBrokenReference         = errors.New("BROKEN_REFERENCE")
@@ -43,6 +45,18 @@ var (
 	ErrNotConvertible          = errors.New("NOT_CONVERTIBLE")
 	ErrResourceUnavailable     = errors.New("RESOURCE_UNAVAILABLE")
 	ErrNoActiveSession         = errors.New("NO_ACTIVE_SESSION")
+	ErrCircuitOpen             = errors.New("CIRCUIT_OPEN")
+	ErrJobRunning              = errors.New("JOB_RUNNING")
+	ErrJobCancelled            = errors.New("JOB_CANCELLED")
+	ErrJobFinished             = errors.New("JOB_FINISHED")
+	ErrJobNotCancellable       = errors.New("JOB_NOT_CANCELLABLE")
+	ErrReadOnly                = errors.New("READ_ONLY")
+	ErrOutOfOrderUpdate        = errors.New("OUT_OF_ORDER_UPDATE")
+	ErrInvalidParent           = errors.New("INVALID_PARENT")
+	ErrInvalidTenant           = errors.New("INVALID_TENANT")
+	ErrNotifierNotConfigured   = errors.New("NOTIFIER_NOT_CONFIGURED")
+	ErrDecimalOverflow         = errors.New("DECIMAL_OVERFLOW")
+	ErrSplitChargingPrepaid    = errors.New("SPLIT_CHARGING_ON_PREPAID")
 )
 
 // NewCGRError initialises a new CGRError
@@ -89,11 +103,36 @@ func NewErrServerError(err error) error {
 	return fmt.Errorf("SERVER_ERROR: %s", err)
 }
 
+// NewErrBrokenReference is returned when an object points towards another one which cannot be found
+func NewErrBrokenReference(objType, objID string) error {
+	return fmt.Errorf("%s:%s:%s", ErrBrokenReference, objType, objID)
+}
+
+// NewErrDanglingReferences groups all the references towards missing objects into one error
+func NewErrDanglingReferences(refs []string) error {
+	return fmt.Errorf("%s:%d:%s", ErrBrokenReference, len(refs), strings.Join(refs, "; "))
+}
+
+// NewErrMaxRecursionDepth names the rating subjects followed past the maximum fallback depth
+func NewErrMaxRecursionDepth(subjects []string) error {
+	return fmt.Errorf("%s:%s", ErrMaxRecursionDepth, strings.Join(subjects, "->"))
+}
+
+// NewErrFallbackLoop names the rating subjects falling back on each other
+func NewErrFallbackLoop(subjects []string) error {
+	return fmt.Errorf("%s:%s", ErrFallbackLoop, strings.Join(subjects, "->"))
+}
+
+// NewErrInvalidTPField signals a tariff plan field with a value we cannot process
+func NewErrInvalidTPField(field, value string) error {
+	return fmt.Errorf("INVALID_TP_FIELD:%s:%s", field, value)
+}
+
 // Centralized returns for APIs
 func APIErrorHandler(err error) error {
 	cgrErr, ok := err.(*CGRError)
 	if !ok {
-		if err == ErrNotFound {
+		if err == ErrNotFound || err == ErrCircuitOpen { // retriable, pass it unchanged
 			return err
 		} else {
 			return NewErrServerError(err)
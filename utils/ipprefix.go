@@ -0,0 +1,169 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ipPrefixLengths are the network lengths of the IP reverse destination keys known, per address family,
+// matching an address probing only these lengths instead of all of them
+var (
+	ipPrefixLengths    = map[string][]bool{MetaIPv4: make([]bool, 8*net.IPv4len+1), MetaIPv6: make([]bool, 8*net.IPv6len+1)}
+	ipPrefixLengthsMux sync.RWMutex
+)
+
+// addIPPrefixLength records nrBits as used by the IP reverse destination keys of family
+func addIPPrefixLength(family string, nrBits int) {
+	ipPrefixLengthsMux.RLock()
+	known := ipPrefixLengths[family][nrBits]
+	ipPrefixLengthsMux.RUnlock()
+	if known {
+		return
+	}
+	ipPrefixLengthsMux.Lock()
+	ipPrefixLengths[family][nrBits] = true
+	ipPrefixLengthsMux.Unlock()
+}
+
+// AddIPPrefixKey records the network length of an IP reverse destination key found in the data db, other keys being ignored
+// The lengths are never forgotten, stale ones costing only some extra lookups
+func AddIPPrefixKey(key string) {
+	for _, family := range []string{MetaIPv4, MetaIPv6} {
+		if strings.HasPrefix(key, family+":") {
+			if nrBits := len(key) - len(family) - 1; nrBits < len(ipPrefixLengths[family]) {
+				addIPPrefixLength(family, nrBits)
+			}
+			return
+		}
+	}
+}
+
+// ParseIPPrefix parses an IP address or network, returning it in CIDR notation
+// Single addresses are considered full length networks, eg: 10.0.0.1 becomes 10.0.0.1/32
+func ParseIPPrefix(pfx string) (string, error) {
+	if !strings.Contains(pfx, "/") {
+		ip := net.ParseIP(pfx)
+		if ip == nil {
+			return "", fmt.Errorf("invalid IP prefix: %s", pfx)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+	_, ipNet, err := net.ParseCIDR(pfx)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP prefix: %s", pfx)
+	}
+	return ipNet.String(), nil
+}
+
+// IsIPPrefix returns true if the destination prefix is an IP network in CIDR notation
+func IsIPPrefix(pfx string) bool {
+	if !strings.Contains(pfx, "/") {
+		return false
+	}
+	_, _, err := net.ParseCIDR(pfx)
+	return err == nil
+}
+
+// ipBitsKey returns the key out of the first nrBits of the address, one character per bit
+func ipBitsKey(ip net.IP, nrBits int) string {
+	key := make([]byte, nrBits)
+	for i := 0; i < nrBits; i++ {
+		key[i] = '0'
+		if ip[i/8]&(0x80>>uint(i%8)) != 0 {
+			key[i] = '1'
+		}
+	}
+	if len(ip) == net.IPv4len {
+		return MetaIPv4 + ":" + string(key)
+	}
+	return MetaIPv6 + ":" + string(key)
+}
+
+// ReverseDestinationKey returns the key indexing the destination prefix within reverse destinations
+// IP networks are indexed on their network bits so they can be matched prefix-like
+func ReverseDestinationKey(pfx string) string {
+	if !strings.Contains(pfx, "/") {
+		return pfx
+	}
+	_, ipNet, err := net.ParseCIDR(pfx)
+	if err != nil {
+		return pfx
+	}
+	ip := ipNet.IP
+	if len(ipNet.Mask) == net.IPv4len {
+		ip = ip.To4()
+	}
+	ones, _ := ipNet.Mask.Size()
+	key := ipBitsKey(ip, ones)
+	AddIPPrefixKey(key)
+	return key
+}
+
+// SplitDestination returns the reverse destination keys to match dst against, longest first
+// IP addresses are split on the network lengths of the IP reverse destinations known, anything else is handled by SplitPrefix
+func SplitDestination(dst string, minLength int) []string {
+	ip := net.ParseIP(dst)
+	if ip == nil {
+		return SplitPrefix(dst, minLength)
+	}
+	family := MetaIPv6
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		family = MetaIPv4
+	}
+	nrBits := len(ip) * 8
+	fullKey := ipBitsKey(ip, nrBits)
+	keyPrfxLen := len(fullKey) - nrBits
+	var subs []string
+	ipPrefixLengthsMux.RLock()
+	for i := nrBits; i >= 0; i-- {
+		if ipPrefixLengths[family][i] {
+			subs = append(subs, fullKey[:keyPrfxLen+i])
+		}
+	}
+	ipPrefixLengthsMux.RUnlock()
+	return subs
+}
+
+// DestinationKeyPrefix returns the destination prefix out of a reverse destination key,
+// converting back the keys built out of IP networks into CIDR notation
+func DestinationKeyPrefix(key string) string {
+	var ip net.IP
+	switch {
+	case strings.HasPrefix(key, MetaIPv4+":"):
+		ip = make(net.IP, net.IPv4len)
+	case strings.HasPrefix(key, MetaIPv6+":"):
+		ip = make(net.IP, net.IPv6len)
+	default:
+		return key
+	}
+	bits := key[len(MetaIPv4)+1:]
+	for i, b := range bits {
+		if b == '1' {
+			ip[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return (&net.IPNet{IP: ip, Mask: net.CIDRMask(len(bits), len(ip)*8)}).String()
+}
@@ -0,0 +1,77 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseIPPrefix(t *testing.T) {
+	for pfx, ePfx := range map[string]string{"10.0.0.1": "10.0.0.1/32", "10.1.2.3/8": "10.0.0.0/8",
+		"2001:db8::1": "2001:db8::1/128", "2001:db8:1:2::/32": "2001:db8::/32"} {
+		if rcv, err := ParseIPPrefix(pfx); err != nil {
+			t.Error(err)
+		} else if rcv != ePfx {
+			t.Errorf("Prefix %s, expecting: %s, received: %s", pfx, ePfx, rcv)
+		}
+	}
+	if _, err := ParseIPPrefix("10.0.0"); err == nil {
+		t.Error("Expecting error on invalid prefix")
+	}
+}
+
+func TestReverseDestinationKey(t *testing.T) {
+	if key := ReverseDestinationKey("0723"); key != "0723" {
+		t.Errorf("Unexpected key: %s", key)
+	}
+	if key := ReverseDestinationKey("10.0.0.0/12"); key != "*ip4:000010100000" {
+		t.Errorf("Unexpected key: %s", key)
+	}
+	if key := ReverseDestinationKey("2001:db8::/16"); key != "*ip6:0010000000000001" {
+		t.Errorf("Unexpected key: %s", key)
+	}
+	for _, pfx := range []string{"10.0.0.0/12", "192.168.1.1/32", "2001:db8::/32", "0.0.0.0/0"} {
+		if rcv := DestinationKeyPrefix(ReverseDestinationKey(pfx)); rcv != pfx {
+			t.Errorf("Expecting: %s, received: %s", pfx, rcv)
+		}
+	}
+}
+
+func TestSplitDestination(t *testing.T) {
+	if subs := SplitDestination("0723", 1); !reflect.DeepEqual(subs, []string{"0723", "072", "07", "0"}) {
+		t.Errorf("Unexpected subs: %v", subs)
+	}
+	ipPrefixLengths = map[string][]bool{MetaIPv4: make([]bool, 8*net.IPv4len+1), MetaIPv6: make([]bool, 8*net.IPv6len+1)}
+	ReverseDestinationKey("10.0.0.0/8")
+	ReverseDestinationKey("10.0.0.1/32")
+	AddIPPrefixKey("*ip4:")
+	AddIPPrefixKey("0723")
+	if subs := SplitDestination("10.0.0.1", 1); !reflect.DeepEqual(subs,
+		[]string{"*ip4:00001010000000000000000000000001", "*ip4:00001010", "*ip4:"}) {
+		t.Errorf("Unexpected subs: %v", subs)
+	}
+	if subs := SplitDestination("2001:db8::1", 1); len(subs) != 0 {
+		t.Errorf("Unexpected subs: %v", subs)
+	}
+	AddIPPrefixKey("*ip6:00100000000000010000110110111000")
+	if subs := SplitDestination("2001:db8::1", 1); !reflect.DeepEqual(subs, []string{"*ip6:00100000000000010000110110111000"}) {
+		t.Errorf("Unexpected subs: %v", subs)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// JobStatus is the snapshot of a Job as returned over APIs
+type JobStatus struct {
+	ID        string
+	Name      string
+	Status    string  // <*running|*completed|*failed|*cancelled>
+	Progress  float64 // 0 to 1, when the job reports it
+	StartTime time.Time
+	EndTime   time.Time
+	Error     string
+}
+
+// Job is an asynchronous operation tracked by the JobManager
+type Job struct {
+	sync.RWMutex
+	id          string
+	name        string
+	status      string
+	progress    float64
+	startTime   time.Time
+	endTime     time.Time
+	result      interface{}
+	err         error
+	cancellable bool // the job checks Cancelled between its steps
+	cancel      chan struct{}
+	cancelled   bool
+}
+
+// SetProgress is called by the job to report done out of total items processed
+func (job *Job) SetProgress(done, total int) {
+	if job == nil || total == 0 {
+		return
+	}
+	job.Lock()
+	job.progress = float64(done) / float64(total)
+	job.Unlock()
+}
+
+// Cancelled is polled by the job between steps, true if cancellation was requested
+func (job *Job) Cancelled() bool {
+	if job == nil {
+		return false
+	}
+	select {
+	case <-job.cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status returns a snapshot of the job
+func (job *Job) Status() *JobStatus {
+	job.RLock()
+	defer job.RUnlock()
+	js := &JobStatus{ID: job.id, Name: job.name, Status: job.status, Progress: job.progress,
+		StartTime: job.startTime, EndTime: job.endTime}
+	if job.err != nil {
+		js.Error = job.err.Error()
+	}
+	return js
+}
+
+func (job *Job) finished() bool {
+	return job.status != MetaJobRunning
+}
+
+// NewJobManager constructs a JobManager, keeping finished jobs for ttl (0 keeps them forever)
+func NewJobManager(ttl time.Duration) *JobManager {
+	return &JobManager{ttl: ttl, jobs: make(map[string]*Job)}
+}
+
+// JobManager runs long operations in the background, tracking their status and results
+type JobManager struct {
+	sync.RWMutex
+	ttl  time.Duration
+	jobs map[string]*Job
+}
+
+// StartJob runs the handler in the background and returns the ID of the job
+// A cancellable handler should stop when job.Cancelled, returning ErrJobCancelled
+func (jm *JobManager) StartJob(name string, cancellable bool, handler func(job *Job) (interface{}, error)) string {
+	job := &Job{id: GenUUID(), name: name, status: MetaJobRunning,
+		startTime: time.Now(), cancellable: cancellable, cancel: make(chan struct{})}
+	jm.Lock()
+	jm.removeExpired()
+	jm.jobs[job.id] = job
+	jm.Unlock()
+	go func() {
+		result, err := handler(job)
+		job.Lock()
+		job.endTime = time.Now()
+		switch { // out of what the handler did, it may have finished before seeing the cancellation
+		case err == ErrJobCancelled:
+			job.status = MetaJobCancelled
+		case err != nil:
+			job.status = MetaJobFailed
+			job.err = err
+		default:
+			job.status = MetaJobCompleted
+			job.result = result
+			job.progress = 1
+		}
+		job.Unlock()
+	}()
+	return job.id
+}
+
+// removeExpired cleans the finished jobs older than ttl, jm should be locked by the caller
+func (jm *JobManager) removeExpired() {
+	if jm.ttl == 0 {
+		return
+	}
+	for id, job := range jm.jobs {
+		job.RLock()
+		expired := job.finished() && time.Since(job.endTime) > jm.ttl
+		job.RUnlock()
+		if expired {
+			delete(jm.jobs, id)
+		}
+	}
+}
+
+func (jm *JobManager) getJob(id string) (*Job, error) {
+	jm.RLock()
+	defer jm.RUnlock()
+	job, has := jm.jobs[id]
+	if !has {
+		return nil, ErrNotFound
+	}
+	return job, nil
+}
+
+// GetJobStatus returns the status of the job with id
+func (jm *JobManager) GetJobStatus(id string) (*JobStatus, error) {
+	job, err := jm.getJob(id)
+	if err != nil {
+		return nil, err
+	}
+	return job.Status(), nil
+}
+
+// GetJobsStatus returns the status of all jobs known
+func (jm *JobManager) GetJobsStatus() (jss []*JobStatus) {
+	jm.RLock()
+	defer jm.RUnlock()
+	for _, job := range jm.jobs {
+		jss = append(jss, job.Status())
+	}
+	return
+}
+
+// GetJobResult returns the result of a completed job or the error it failed with
+func (jm *JobManager) GetJobResult(id string) (interface{}, error) {
+	job, err := jm.getJob(id)
+	if err != nil {
+		return nil, err
+	}
+	job.RLock()
+	defer job.RUnlock()
+	switch job.status {
+	case MetaJobRunning:
+		return nil, ErrJobRunning
+	case MetaJobCancelled:
+		return nil, ErrJobCancelled
+	case MetaJobFailed:
+		return nil, job.err
+	}
+	return job.result, nil
+}
+
+// CancelJob requests cancellation of a running job, the job stops at its next check
+func (jm *JobManager) CancelJob(id string) error {
+	job, err := jm.getJob(id)
+	if err != nil {
+		return err
+	}
+	job.Lock()
+	defer job.Unlock()
+	if job.finished() {
+		return ErrJobFinished
+	}
+	if !job.cancellable {
+		return ErrJobNotCancellable
+	}
+	if !job.cancelled {
+		job.cancelled = true
+		close(job.cancel)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func waitJobFinished(jm *JobManager, id string) *JobStatus {
+	for i := 0; i < 100; i++ {
+		if js, _ := jm.GetJobStatus(id); js.Status != MetaJobRunning {
+			return js
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	js, _ := jm.GetJobStatus(id)
+	return js
+}
+
+func TestJobManagerCompleted(t *testing.T) {
+	jm := NewJobManager(0)
+	release := make(chan struct{})
+	id := jm.StartJob("test", true, func(job *Job) (interface{}, error) {
+		job.SetProgress(1, 2)
+		<-release
+		return "result", nil
+	})
+	if _, err := jm.GetJobResult(id); err != ErrJobRunning {
+		t.Errorf("Expecting: %v, received: %v", ErrJobRunning, err)
+	}
+	close(release)
+	if js := waitJobFinished(jm, id); js.Status != MetaJobCompleted || js.Progress != 1 || js.Name != "test" {
+		t.Errorf("Unexpected job status: %+v", js)
+	}
+	if rcv, err := jm.GetJobResult(id); err != nil {
+		t.Error(err)
+	} else if rcv != "result" {
+		t.Errorf("Unexpected result: %v", rcv)
+	}
+	if err := jm.CancelJob(id); err != ErrJobFinished {
+		t.Errorf("Expecting: %v, received: %v", ErrJobFinished, err)
+	}
+	if _, err := jm.GetJobStatus("unknown"); err != ErrNotFound {
+		t.Error(err)
+	}
+}
+
+func TestJobManagerFailedAndCancelled(t *testing.T) {
+	jm := NewJobManager(0)
+	errFail := errors.New("FAILED")
+	failID := jm.StartJob("fail", false, func(job *Job) (interface{}, error) {
+		return nil, errFail
+	})
+	if js := waitJobFinished(jm, failID); js.Status != MetaJobFailed || js.Error != errFail.Error() {
+		t.Errorf("Unexpected job status: %+v", js)
+	}
+	if _, err := jm.GetJobResult(failID); err != errFail {
+		t.Errorf("Expecting: %v, received: %v", errFail, err)
+	}
+	started := make(chan struct{})
+	cancelID := jm.StartJob("cancel", true, func(job *Job) (interface{}, error) {
+		close(started)
+		for !job.Cancelled() {
+			time.Sleep(time.Millisecond)
+		}
+		return nil, ErrJobCancelled
+	})
+	<-started
+	if err := jm.CancelJob(cancelID); err != nil {
+		t.Error(err)
+	}
+	if js := waitJobFinished(jm, cancelID); js.Status != MetaJobCancelled || js.Error != "" {
+		t.Errorf("Unexpected job status: %+v", js)
+	}
+	if _, err := jm.GetJobResult(cancelID); err != ErrJobCancelled {
+		t.Errorf("Expecting: %v, received: %v", ErrJobCancelled, err)
+	}
+	if jss := jm.GetJobsStatus(); len(jss) != 2 {
+		t.Errorf("Unexpected jobs: %+v", jss)
+	}
+}
+
+func TestJobManagerNotCancellable(t *testing.T) {
+	jm := NewJobManager(0)
+	release := make(chan struct{})
+	id := jm.StartJob("load", false, func(job *Job) (interface{}, error) {
+		<-release
+		return "loaded", nil
+	})
+	if err := jm.CancelJob(id); err != ErrJobNotCancellable {
+		t.Errorf("Expecting: %v, received: %v", ErrJobNotCancellable, err)
+	}
+	close(release)
+	if js := waitJobFinished(jm, id); js.Status != MetaJobCompleted {
+		t.Errorf("Unexpected job status: %+v", js)
+	}
+	// cancelled too late, the result of the job is kept
+	release = make(chan struct{})
+	started := make(chan struct{})
+	lateID := jm.StartJob("late", true, func(job *Job) (interface{}, error) {
+		close(started)
+		<-release
+		return "done", nil
+	})
+	<-started
+	if err := jm.CancelJob(lateID); err != nil {
+		t.Error(err)
+	}
+	close(release)
+	if js := waitJobFinished(jm, lateID); js.Status != MetaJobCompleted {
+		t.Errorf("Unexpected job status: %+v", js)
+	}
+	if rcv, err := jm.GetJobResult(lateID); err != nil || rcv != "done" {
+		t.Errorf("Unexpected result: %v, error: %v", rcv, err)
+	}
+}
+
+func TestJobManagerExpired(t *testing.T) {
+	jm := NewJobManager(time.Nanosecond)
+	id := jm.StartJob("expired", false, func(job *Job) (interface{}, error) { return nil, nil })
+	waitJobFinished(jm, id)
+	time.Sleep(time.Millisecond)
+	jm.StartJob("new", false, func(job *Job) (interface{}, error) { return nil, nil })
+	if _, err := jm.GetJobStatus(id); err != ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", ErrNotFound, err)
+	}
+}
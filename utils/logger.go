@@ -22,6 +22,8 @@ import (
 	"log"
 	"log/syslog"
 	"runtime"
+	"sync"
+	"time"
 )
 
 var Logger LoggerInterface
@@ -53,6 +55,7 @@ type LoggerInterface interface {
 	Notice(m string) error
 	Info(m string) error
 	Debug(m string) error
+	RecentErrors() []string
 }
 
 // log severities following rfc3164
@@ -67,10 +70,15 @@ const (
 	LOGLEVEL_DEBUG
 )
 
+// number of error messages kept in memory for diagnostics
+const RecentErrorsSize = 100
+
 // Logs to standard output
 type StdLogger struct {
-	logLevel int
-	syslog   *syslog.Writer
+	logLevel   int
+	syslog     *syslog.Writer
+	recentErrs []string // last error and above messages, oldest first
+	recentMux  sync.RWMutex
 }
 
 func (sl *StdLogger) Close() (err error) {
@@ -95,8 +103,28 @@ func (sl *StdLogger) SetLogLevel(level int) {
 	sl.logLevel = level
 }
 
+// recordError keeps the message in the recent errors ring, independent of log level so it is available for diagnostics
+func (sl *StdLogger) recordError(level, m string) {
+	sl.recentMux.Lock()
+	if len(sl.recentErrs) == RecentErrorsSize {
+		sl.recentErrs = sl.recentErrs[1:]
+	}
+	sl.recentErrs = append(sl.recentErrs, fmt.Sprintf("%s [%s]%s", time.Now().Format(time.RFC3339), level, m))
+	sl.recentMux.Unlock()
+}
+
+// RecentErrors returns a copy of the last error and above messages logged
+func (sl *StdLogger) RecentErrors() (errs []string) {
+	sl.recentMux.RLock()
+	errs = make([]string, len(sl.recentErrs))
+	copy(errs, sl.recentErrs)
+	sl.recentMux.RUnlock()
+	return
+}
+
 // Alert logs to syslog with alert level
 func (sl *StdLogger) Alert(m string) (err error) {
+	sl.recordError("ALERT", m)
 	if sl.logLevel < LOGLEVEL_ALERT {
 		return
 	}
@@ -110,6 +138,7 @@ func (sl *StdLogger) Alert(m string) (err error) {
 
 // Crit logs to syslog with critical level
 func (sl *StdLogger) Crit(m string) (err error) {
+	sl.recordError("CRITICAL", m)
 	if sl.logLevel < LOGLEVEL_CRITICAL {
 		return
 	}
@@ -136,6 +165,7 @@ func (sl *StdLogger) Debug(m string) (err error) {
 
 // Emerg logs to syslog with emergency level
 func (sl *StdLogger) Emerg(m string) (err error) {
+	sl.recordError("EMERGENCY", m)
 	if sl.logLevel < LOGLEVEL_EMERGENCY {
 		return
 	}
@@ -149,6 +179,7 @@ func (sl *StdLogger) Emerg(m string) (err error) {
 
 // Err logs to syslog with error level
 func (sl *StdLogger) Err(m string) (err error) {
+	sl.recordError("ERROR", m)
 	if sl.logLevel < LOGLEVEL_ERROR {
 		return
 	}
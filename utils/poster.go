@@ -26,19 +26,23 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/cgrates/cgrates/guardian"
+	"github.com/nats-io/nats.go"
 	"github.com/streadway/amqp"
 )
 
 func init() {
 	AMQPPostersCache = &AMQPCachedPosters{cache: make(map[string]*AMQPPoster)} // Initialize the cache for amqpPosters
+	NATSPostersCache = &NATSCachedPosters{cache: make(map[string]*NATSPoster)}
 }
 
 var AMQPPostersCache *AMQPCachedPosters
+var NATSPostersCache *NATSCachedPosters
 
 // Post without automatic failover
 func HttpJsonPost(url string, skipTlsVerify bool, content []byte) ([]byte, error) {
@@ -68,7 +72,7 @@ func NewFallbackFileNameFronString(fileName string) (ffn *FallbackFileName, err
 	moduleIdx := strings.Index(fileName, HandlerArgSep)
 	ffn.Module = fileName[:moduleIdx]
 	var supportedModule bool
-	for _, prfx := range []string{ActionsPoster, CDRPoster} {
+	for _, prfx := range []string{ActionsPoster, CDRPoster, BalancesPoster, TPLoadsPoster} {
 		if strings.HasPrefix(ffn.Module, prfx) {
 			supportedModule = true
 			break
@@ -78,7 +82,7 @@ func NewFallbackFileNameFronString(fileName string) (ffn *FallbackFileName, err
 		return nil, fmt.Errorf("unsupported module: %s", ffn.Module)
 	}
 	fileNameWithoutModule := fileName[moduleIdx+1:]
-	for _, trspt := range []string{MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap} {
+	for _, trspt := range []string{MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap, MetaNATSjsonCDR, MetaNATSjsonMap} {
 		if strings.HasPrefix(fileNameWithoutModule, trspt) {
 			ffn.Transport = trspt
 			break
@@ -321,7 +325,117 @@ func (pstr *AMQPPoster) NewPostChannel() (postChan *amqp.Channel, err error) {
 
 // writeToFile writes the content in the file with fileName on amqp.fallbackFileDir
 func (pstr *AMQPPoster) writeToFile(fileName string, content []byte) (err error) {
-	fallbackFilePath := path.Join(pstr.fallbackFileDir, fileName)
+	return writeFallbackFile(path.Join(pstr.fallbackFileDir, fileName), content)
+}
+
+// NATSCachedPosters is used to cache mutliple NATSPoster connections based on the address
+type NATSCachedPosters struct {
+	sync.Mutex
+	cache map[string]*NATSPoster
+}
+
+// GetNATSPoster creates a new poster only if not already cached
+// uses dialURL as cache key
+func (pc *NATSCachedPosters) GetNATSPoster(dialURL string, attempts int, fallbackFileDir string) (natsPoster *NATSPoster, err error) {
+	pc.Lock()
+	defer pc.Unlock()
+	if _, hasIt := pc.cache[dialURL]; !hasIt {
+		if pstr, err := NewNATSPoster(dialURL, attempts, fallbackFileDir); err != nil {
+			return nil, err
+		} else {
+			pc.cache[dialURL] = pstr
+		}
+	}
+	return pc.cache[dialURL], nil
+}
+
+// "nats://localhost:4222/?subject=cgrates_cdrs&jetstream=true"
+func NewNATSPoster(dialURL string, attempts int, fallbackFileDir string) (*NATSPoster, error) {
+	u, err := url.Parse(dialURL)
+	if err != nil {
+		return nil, err
+	}
+	qry := u.Query()
+	pstr := &NATSPoster{dialURL: strings.Split(dialURL, "?")[0], subject: "cgrates_cdrs",
+		attempts: attempts, fallbackFileDir: fallbackFileDir}
+	if vals, has := qry["subject"]; has && len(vals) != 0 {
+		pstr.subject = vals[0]
+	}
+	if vals, has := qry["jetstream"]; has && len(vals) != 0 {
+		if pstr.jetStream, err = strconv.ParseBool(vals[0]); err != nil {
+			return nil, err
+		}
+	}
+	return pstr, nil
+}
+
+// NATSPoster publishes on a NATS subject, through JetStream when the stored (acknowledged) delivery is needed
+type NATSPoster struct {
+	dialURL         string
+	subject         string // subject where we publish
+	jetStream       bool
+	attempts        int
+	fallbackFileDir string
+	sync.Mutex      // protect connection
+	conn            *nats.Conn
+	js              nats.JetStreamContext
+}
+
+// Post publishes the content, writing it into fallbackFileName if all attempts fail
+func (pstr *NATSPoster) Post(content []byte, fallbackFileName string) (err error) {
+	fib := Fib()
+	for i := 0; i < pstr.attempts; i++ {
+		if err = pstr.publish(content); err == nil {
+			return
+		}
+		pstr.Close() // reconnect on next attempt
+		if i+1 < pstr.attempts {
+			time.Sleep(time.Duration(fib()) * time.Second)
+		}
+	}
+	if err != nil && fallbackFileName != META_NONE {
+		err = writeFallbackFile(path.Join(pstr.fallbackFileDir, fallbackFileName), content)
+	}
+	return
+}
+
+func (pstr *NATSPoster) publish(content []byte) (err error) {
+	pstr.Lock()
+	defer pstr.Unlock()
+	if pstr.conn == nil {
+		if pstr.conn, err = nats.Connect(pstr.dialURL, nats.Name(CGRateS)); err != nil {
+			return
+		}
+		if pstr.jetStream {
+			if pstr.js, err = pstr.conn.JetStream(); err != nil {
+				pstr.conn.Close()
+				pstr.conn = nil
+				return
+			}
+		}
+	}
+	if pstr.jetStream {
+		_, err = pstr.js.Publish(pstr.subject, content) // waits for the stream acknowledgement
+		return
+	}
+	if err = pstr.conn.Publish(pstr.subject, content); err != nil {
+		return
+	}
+	return pstr.conn.Flush()
+}
+
+func (pstr *NATSPoster) Close() {
+	pstr.Lock()
+	if pstr.conn != nil {
+		pstr.conn.Close()
+	}
+	pstr.conn = nil
+	pstr.js = nil
+	pstr.Unlock()
+}
+
+// writeFallbackFile writes the content which could not be posted in the file at fallbackFilePath
+func writeFallbackFile(fallbackFilePath string, content []byte) (err error) {
 	_, err = guardian.Guardian.Guard(func() (interface{}, error) {
 		fileOut, err := os.Create(fallbackFilePath)
 		if err != nil {
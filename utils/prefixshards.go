@@ -0,0 +1,185 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"sync"
+)
+
+// NewPrefixShards returns a PrefixShards bulk loaded with values
+func NewPrefixShards(values map[string][]string) *PrefixShards {
+	var maxLen int
+	lenCounts := make(map[int]int)
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		lenCounts[len(key)]++
+		if len(key) > maxLen {
+			maxLen = len(key)
+		}
+	}
+	ps := &PrefixShards{shards: make([]map[string][]string, maxLen+1)}
+	for keyLen, cnt := range lenCounts {
+		ps.shards[keyLen] = make(map[string][]string, cnt)
+	}
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		ps.shards[len(key)][key] = vals
+		ps.len++
+	}
+	return ps
+}
+
+// PrefixShards indexes string values on keys sharded by the key length, matching the prefixes of a number
+// with one lookup per indexed length. Value slices are never modified in place so they can be safely shared with the callers
+type PrefixShards struct {
+	sync.RWMutex
+	shards []map[string][]string // index is the key length, nil for lengths without keys
+	len    int
+}
+
+func (ps *PrefixShards) shard(keyLen int, create bool) map[string][]string {
+	if keyLen >= len(ps.shards) {
+		if !create {
+			return nil
+		}
+		shards := make([]map[string][]string, keyLen+1)
+		copy(shards, ps.shards)
+		ps.shards = shards
+	}
+	if ps.shards[keyLen] == nil && create {
+		ps.shards[keyLen] = make(map[string][]string)
+	}
+	return ps.shards[keyLen]
+}
+
+// Set indexes the values on key, empty values removing the key
+func (ps *PrefixShards) Set(key string, values []string) {
+	if len(values) == 0 {
+		ps.Remove(key)
+		return
+	}
+	ps.Lock()
+	shard := ps.shard(len(key), true)
+	if _, has := shard[key]; !has {
+		ps.len++
+	}
+	shard[key] = values
+	ps.Unlock()
+}
+
+// AddValue adds val to the values of key if not already there
+func (ps *PrefixShards) AddValue(key, val string) {
+	ps.Lock()
+	defer ps.Unlock()
+	shard := ps.shard(len(key), true)
+	crntVals, has := shard[key]
+	if !has {
+		ps.len++
+	} else if IsSliceMember(crntVals, val) {
+		return
+	}
+	values := make([]string, len(crntVals), len(crntVals)+1)
+	copy(values, crntVals)
+	shard[key] = append(values, val)
+}
+
+// RemoveValue removes val out of the values of key, removing the key once no values are left
+func (ps *PrefixShards) RemoveValue(key, val string) {
+	ps.Lock()
+	defer ps.Unlock()
+	shard := ps.shard(len(key), false)
+	crntVals, has := shard[key]
+	if !has {
+		return
+	}
+	values := make([]string, 0, len(crntVals))
+	for _, v := range crntVals {
+		if v != val {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		ps.remove(key)
+		return
+	}
+	shard[key] = values
+}
+
+// Remove drops key out of the index
+func (ps *PrefixShards) Remove(key string) {
+	ps.Lock()
+	ps.remove(key)
+	ps.Unlock()
+}
+
+func (ps *PrefixShards) remove(key string) {
+	shard := ps.shard(len(key), false)
+	if _, has := shard[key]; !has {
+		return
+	}
+	delete(shard, key)
+	ps.len--
+	if len(shard) == 0 {
+		ps.shards[len(key)] = nil
+	}
+}
+
+// Get returns the values indexed on key
+func (ps *PrefixShards) Get(key string) ([]string, bool) {
+	ps.RLock()
+	defer ps.RUnlock()
+	values, has := ps.shard(len(key), false)[key]
+	return values, has
+}
+
+// MatchPrefixes returns the keys being prefixes of s, at least minLength long, longest first
+func (ps *PrefixShards) MatchPrefixes(s string, minLength int) (keys []string) {
+	keys, _ = ps.MatchValues(s, minLength)
+	return
+}
+
+// MatchValues returns the keys being prefixes of s together with their values, longest first
+func (ps *PrefixShards) MatchValues(s string, minLength int) (keys []string, values [][]string) {
+	ps.RLock()
+	defer ps.RUnlock()
+	keyLen := len(s)
+	if keyLen >= len(ps.shards) {
+		keyLen = len(ps.shards) - 1
+	}
+	for ; keyLen >= minLength && keyLen >= 0; keyLen-- {
+		if ps.shards[keyLen] == nil {
+			continue
+		}
+		if vals, has := ps.shards[keyLen][s[:keyLen]]; has {
+			keys = append(keys, s[:keyLen])
+			values = append(values, vals)
+		}
+	}
+	return
+}
+
+// Len returns the number of keys indexed
+func (ps *PrefixShards) Len() int {
+	ps.RLock()
+	defer ps.RUnlock()
+	return ps.len
+}
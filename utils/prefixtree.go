@@ -0,0 +1,255 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PrefixIndex indexes string values on keys for longest prefix matching
+type PrefixIndex interface {
+	Set(key string, values []string)
+	AddValue(key, val string)
+	RemoveValue(key, val string)
+	Remove(key string)
+	Get(key string) ([]string, bool)
+	MatchPrefixes(s string, minLength int) []string
+	MatchValues(s string, minLength int) ([]string, [][]string)
+	Len() int
+}
+
+// NewPrefixIndex returns the PrefixIndex of idxType bulk loaded with values: <*tree|*sharded>
+func NewPrefixIndex(idxType string, values map[string][]string) (PrefixIndex, error) {
+	switch idxType {
+	case MetaTree:
+		pt := NewPrefixTree()
+		for key, vals := range values {
+			pt.Set(key, vals)
+		}
+		return pt, nil
+	case MetaSharded:
+		return NewPrefixShards(values), nil
+	}
+	return nil, fmt.Errorf("unsupported prefix index type: <%s>", idxType)
+}
+
+// NewPrefixTree returns an empty PrefixTree
+func NewPrefixTree() *PrefixTree {
+	return &PrefixTree{root: new(prefixNode)}
+}
+
+// PrefixTree is a radix tree indexing string values on keys, built for longest prefix matching
+// Value slices are never modified in place so they can be safely shared with the callers
+type PrefixTree struct {
+	sync.RWMutex
+	root *prefixNode
+	len  int
+}
+
+type prefixNode struct {
+	label    string // part of the key leading from parent to this node
+	children map[byte]*prefixNode
+	values   []string // nil for intermediary nodes
+}
+
+func commonPrefixLen(a, b string) (i int) {
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return
+}
+
+// node returns the node of the key, creating it together with the missing path when needed
+func (pt *PrefixTree) node(key string, create bool) *prefixNode {
+	n := pt.root
+	for len(key) != 0 {
+		child, has := n.children[key[0]]
+		if !has {
+			if !create {
+				return nil
+			}
+			if n.children == nil {
+				n.children = make(map[byte]*prefixNode)
+			}
+			child = &prefixNode{label: key}
+			n.children[key[0]] = child
+			return child
+		}
+		cl := commonPrefixLen(key, child.label)
+		if cl != len(child.label) {
+			if !create {
+				return nil
+			}
+			// split the edge on the common part
+			split := &prefixNode{label: key[:cl], children: map[byte]*prefixNode{child.label[cl]: child}}
+			child.label = child.label[cl:]
+			n.children[key[0]] = split
+			child = split
+		}
+		n = child
+		key = key[cl:]
+	}
+	return n
+}
+
+// Set indexes the values on key, empty values removing the key
+func (pt *PrefixTree) Set(key string, values []string) {
+	if len(values) == 0 {
+		pt.Remove(key)
+		return
+	}
+	pt.Lock()
+	n := pt.node(key, true)
+	if n.values == nil {
+		pt.len++
+	}
+	n.values = values
+	pt.Unlock()
+}
+
+// AddValue adds val to the values of key if not already there
+func (pt *PrefixTree) AddValue(key, val string) {
+	pt.Lock()
+	defer pt.Unlock()
+	n := pt.node(key, true)
+	if n.values == nil {
+		pt.len++
+	} else if IsSliceMember(n.values, val) {
+		return
+	}
+	values := make([]string, len(n.values), len(n.values)+1)
+	copy(values, n.values)
+	n.values = append(values, val)
+}
+
+// RemoveValue removes val out of the values of key, removing the key once no values are left
+func (pt *PrefixTree) RemoveValue(key, val string) {
+	pt.Lock()
+	defer pt.Unlock()
+	n := pt.node(key, false)
+	if n == nil || n.values == nil {
+		return
+	}
+	values := make([]string, 0, len(n.values))
+	for _, v := range n.values {
+		if v != val {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		pt.remove(key)
+		return
+	}
+	n.values = values
+}
+
+// Remove drops key out of the tree
+func (pt *PrefixTree) Remove(key string) {
+	pt.Lock()
+	pt.remove(key)
+	pt.Unlock()
+}
+
+func (pt *PrefixTree) remove(key string) {
+	var parent *prefixNode
+	n := pt.root
+	for len(key) != 0 {
+		child, has := n.children[key[0]]
+		if !has || !strings.HasPrefix(key, child.label) {
+			return
+		}
+		parent = n
+		n = child
+		key = key[len(child.label):]
+	}
+	if n.values == nil {
+		return
+	}
+	n.values = nil
+	pt.len--
+	if parent == nil { // root
+		return
+	}
+	if len(n.children) == 0 {
+		delete(parent.children, n.label[0])
+		n = parent
+		if n == pt.root || n.values != nil {
+			return
+		}
+	}
+	if len(n.children) == 1 { // merge with the only child
+		for _, child := range n.children {
+			n.label += child.label
+			n.values = child.values
+			n.children = child.children
+		}
+	}
+}
+
+// Get returns the values indexed on key
+func (pt *PrefixTree) Get(key string) ([]string, bool) {
+	pt.RLock()
+	defer pt.RUnlock()
+	if n := pt.node(key, false); n != nil && n.values != nil {
+		return n.values, true
+	}
+	return nil, false
+}
+
+// MatchPrefixes returns the keys being prefixes of s, at least minLength long, longest first
+func (pt *PrefixTree) MatchPrefixes(s string, minLength int) (keys []string) {
+	keys, _ = pt.MatchValues(s, minLength)
+	return
+}
+
+// MatchValues returns the keys being prefixes of s together with their values, longest first
+func (pt *PrefixTree) MatchValues(s string, minLength int) (keys []string, values [][]string) {
+	pt.RLock()
+	defer pt.RUnlock()
+	n := pt.root
+	var consumed int
+	for {
+		if n.values != nil && consumed >= minLength {
+			keys = append(keys, s[:consumed])
+			values = append(values, n.values)
+		}
+		if consumed == len(s) {
+			break
+		}
+		child, has := n.children[s[consumed]]
+		if !has || !strings.HasPrefix(s[consumed:], child.label) {
+			break
+		}
+		n = child
+		consumed += len(child.label)
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+		values[i], values[j] = values[j], values[i]
+	}
+	return
+}
+
+// Len returns the number of keys indexed
+func (pt *PrefixTree) Len() int {
+	pt.RLock()
+	defer pt.RUnlock()
+	return pt.len
+}
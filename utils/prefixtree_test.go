@@ -0,0 +1,119 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestPrefixTreeMatchPrefixes(t *testing.T) {
+	pt := NewPrefixTree()
+	pt.Set("4986517174963", []string{"DST_DE_MOBILE"})
+	pt.Set("49", []string{"DST_DE"})
+	pt.Set("498651", []string{"DST_DE_MUNICH"})
+	pt.AddValue("49", "DST_EU")
+	pt.AddValue("49", "DST_EU")
+	pt.Set("4986", []string{"DST_DE_BAVARIA"})
+	if pt.Len() != 4 {
+		t.Errorf("Unexpected length: %d", pt.Len())
+	}
+	eKeys := []string{"4986517174963", "498651", "4986", "49"}
+	if rcv := pt.MatchPrefixes("4986517174963", 1); !reflect.DeepEqual(eKeys, rcv) {
+		t.Errorf("Expecting: %+v, received: %+v", eKeys, rcv)
+	}
+	eKeys = []string{"498651", "4986"}
+	if rcv := pt.MatchPrefixes("49865171", 3); !reflect.DeepEqual(eKeys, rcv) {
+		t.Errorf("Expecting: %+v, received: %+v", eKeys, rcv)
+	}
+	if rcv := pt.MatchPrefixes("4812", 1); len(rcv) != 0 {
+		t.Errorf("Unexpected keys: %+v", rcv)
+	}
+	if ids, has := pt.Get("49"); !has || !reflect.DeepEqual([]string{"DST_DE", "DST_EU"}, ids) {
+		t.Errorf("Unexpected values: %+v", ids)
+	}
+	if _, has := pt.Get("498"); has {
+		t.Error("Intermediary node returned as key")
+	}
+	pt.RemoveValue("49", "DST_DE")
+	pt.RemoveValue("4986", "DST_DE_BAVARIA")
+	pt.Remove("4986517174963")
+	eKeys = []string{"498651", "49"}
+	if rcv := pt.MatchPrefixes("4986517174963", 1); !reflect.DeepEqual(eKeys, rcv) {
+		t.Errorf("Expecting: %+v, received: %+v", eKeys, rcv)
+	}
+	if ids, has := pt.Get("49"); !has || !reflect.DeepEqual([]string{"DST_EU"}, ids) {
+		t.Errorf("Unexpected values: %+v", ids)
+	}
+	if pt.Len() != 2 {
+		t.Errorf("Unexpected length: %d", pt.Len())
+	}
+	pt.Remove("498651")
+	pt.Remove("49")
+	if pt.Len() != 0 || len(pt.root.children) != 0 {
+		t.Errorf("Tree not empty: %+v", pt.root.children)
+	}
+}
+
+func TestPrefixShardsMatchValues(t *testing.T) {
+	ps := NewPrefixShards(map[string][]string{
+		"49":     []string{"DST_DE"},
+		"498651": []string{"DST_DE_MUNICH"},
+		"4986":   nil, // skipped on bulk load
+	})
+	ps.AddValue("49", "DST_EU")
+	ps.AddValue("49", "DST_EU")
+	ps.Set("4986517174963", []string{"DST_DE_MOBILE"})
+	if ps.Len() != 3 {
+		t.Errorf("Unexpected length: %d", ps.Len())
+	}
+	eKeys := []string{"4986517174963", "498651", "49"}
+	eVals := [][]string{{"DST_DE_MOBILE"}, {"DST_DE_MUNICH"}, {"DST_DE", "DST_EU"}}
+	if keys, vals := ps.MatchValues("49865171749631", 1); !reflect.DeepEqual(eKeys, keys) || !reflect.DeepEqual(eVals, vals) {
+		t.Errorf("Expecting: %+v, %+v, received: %+v, %+v", eKeys, eVals, keys, vals)
+	}
+	if rcv := ps.MatchPrefixes("49865171", 3); !reflect.DeepEqual([]string{"498651"}, rcv) {
+		t.Errorf("Unexpected keys: %+v", rcv)
+	}
+	ps.RemoveValue("49", "DST_DE")
+	ps.Remove("4986517174963")
+	if ids, has := ps.Get("49"); !has || !reflect.DeepEqual([]string{"DST_EU"}, ids) {
+		t.Errorf("Unexpected values: %+v", ids)
+	}
+	if _, has := ps.Get("4986517174963"); has || ps.Len() != 2 {
+		t.Errorf("Unexpected length: %d", ps.Len())
+	}
+}
+
+func BenchmarkPrefixTreeMatchPrefixes(b *testing.B) {
+	pt := NewPrefixTree()
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000000; i++ {
+		pt.AddValue(strconv.FormatInt(1e11+rnd.Int63n(9e11), 10)[:3+rnd.Intn(7)], "DST_"+strconv.Itoa(i%1000))
+	}
+	numbers := make([]string, 1000)
+	for i := range numbers {
+		numbers[i] = strconv.FormatInt(1e11+rnd.Int63n(9e11), 10)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pt.MatchPrefixes(numbers[i%len(numbers)], 1)
+	}
+}
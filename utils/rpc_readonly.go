@@ -0,0 +1,127 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+	"net/rpc"
+	"strings"
+	"sync"
+)
+
+// ReplicaV1Replicate is the method feeding the read-only engines, the only write they accept
+const ReplicaV1Replicate = "ReplicaV1.Replicate"
+
+// method prefixes of the queries served by read-only engines
+var readOnlyMethodPrefixes = []string{"Get", "Count", "Ping", "Status"}
+
+// IsReadOnlyMethod checks if the RPC method is a query or the replication feed
+func IsReadOnlyMethod(serviceMethod string) bool {
+	if serviceMethod == ReplicaV1Replicate {
+		return true
+	}
+	method := serviceMethod[strings.LastIndex(serviceMethod, ".")+1:]
+	for _, prefix := range readOnlyMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyServerCodec rejects the requests of methods not served in read-only mode with ErrReadOnly
+type readOnlyServerCodec struct {
+	rpc.ServerCodec
+	mu       sync.Mutex
+	rejected map[uint64]bool
+}
+
+func newReadOnlyServerCodec(codec rpc.ServerCodec) *readOnlyServerCodec {
+	return &readOnlyServerCodec{ServerCodec: codec, rejected: make(map[uint64]bool)}
+}
+
+func (c *readOnlyServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	if err := c.ServerCodec.ReadRequestHeader(r); err != nil {
+		return err
+	}
+	if !IsReadOnlyMethod(r.ServiceMethod) {
+		c.mu.Lock()
+		c.rejected[r.Seq] = true
+		c.mu.Unlock()
+		r.ServiceMethod = "" // ill-formed for the server, discarding the body without calling the method
+	}
+	return nil
+}
+
+func (c *readOnlyServerCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	c.mu.Lock()
+	if c.rejected[r.Seq] {
+		delete(c.rejected, r.Seq)
+		r.Error = ErrReadOnly.Error()
+	}
+	c.mu.Unlock()
+	return c.ServerCodec.WriteResponse(r, body)
+}
+
+// gobServerCodec mirrors the one used by rpc.ServeConn, which is not exported for wrapping
+type gobServerCodec struct {
+	rwc    io.ReadWriteCloser
+	dec    *gob.Decoder
+	enc    *gob.Encoder
+	encBuf *bufio.Writer
+	closed bool
+}
+
+func newGobServerCodec(conn io.ReadWriteCloser) *gobServerCodec {
+	buf := bufio.NewWriter(conn)
+	return &gobServerCodec{rwc: conn, dec: gob.NewDecoder(conn), enc: gob.NewEncoder(buf), encBuf: buf}
+}
+
+func (c *gobServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	return c.dec.Decode(r)
+}
+
+func (c *gobServerCodec) ReadRequestBody(body interface{}) error {
+	return c.dec.Decode(body)
+}
+
+func (c *gobServerCodec) WriteResponse(r *rpc.Response, body interface{}) (err error) {
+	if err = c.enc.Encode(r); err != nil {
+		if c.encBuf.Flush() == nil {
+			c.Close() // gob couldn't encode the header, shut down the connection
+		}
+		return
+	}
+	if err = c.enc.Encode(body); err != nil {
+		if c.encBuf.Flush() == nil {
+			c.Close()
+		}
+		return
+	}
+	return c.encBuf.Flush()
+}
+
+func (c *gobServerCodec) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.rwc.Close()
+}
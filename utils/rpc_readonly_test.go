@@ -0,0 +1,80 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"testing"
+)
+
+type readOnlyTestSrv struct{}
+
+func (readOnlyTestSrv) GetValue(arg int, reply *int) error {
+	*reply = arg
+	return nil
+}
+
+func (readOnlyTestSrv) SetValue(arg int, reply *int) error {
+	*reply = arg
+	return nil
+}
+
+func TestIsReadOnlyMethod(t *testing.T) {
+	for method, expected := range map[string]bool{
+		"ApierV1.GetAccount":      true,
+		"CDRStatsV1.GetMetrics":   true,
+		"ApierV2.CountCdrs":       true,
+		"Responder.Status":        true,
+		ReplicaV1Replicate:        true,
+		"ApierV1.SetAccount":      false,
+		"Responder.Debit":         false,
+		"ApierV1.RemoveAccount":   false,
+		"ReplicaV1.GetReplicated": true,
+	} {
+		if rcv := IsReadOnlyMethod(method); rcv != expected {
+			t.Errorf("%s expecting: %v, received: %v", method, expected, rcv)
+		}
+	}
+}
+
+func TestReadOnlyServerCodec(t *testing.T) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("ReadOnlyTest", readOnlyTestSrv{}); err != nil {
+		t.Fatal(err)
+	}
+	srvConn, clntConn := net.Pipe()
+	go srv.ServeCodec(newReadOnlyServerCodec(jsonrpc.NewServerCodec(srvConn)))
+	clnt := jsonrpc.NewClient(clntConn)
+	defer clnt.Close()
+	var reply int
+	if err := clnt.Call("ReadOnlyTest.GetValue", 3, &reply); err != nil {
+		t.Error(err)
+	} else if reply != 3 {
+		t.Errorf("Expecting: 3, received: %d", reply)
+	}
+	if err := clnt.Call("ReadOnlyTest.SetValue", 4, &reply); err == nil || err.Error() != ErrReadOnly.Error() {
+		t.Errorf("Expecting: %v, received: %v", ErrReadOnly, err)
+	}
+	if err := clnt.Call("ReadOnlyTest.GetValue", 5, &reply); err != nil { // connection still usable
+		t.Error(err)
+	} else if reply != 5 {
+		t.Errorf("Expecting: 5, received: %d", reply)
+	}
+}
@@ -38,9 +38,22 @@ import (
 type Server struct {
 	rpcEnabled  bool
 	httpEnabled bool
+	readOnly    bool // serve only the queries and the replication feed
 	birpcSrv    *rpc2.Server
 }
 
+// SetReadOnly restricts the RPC methods served to the ones accepted by read-only engines
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
+func (s *Server) serveCodec(codec rpc.ServerCodec) {
+	if s.readOnly {
+		codec = newReadOnlyServerCodec(codec)
+	}
+	rpc.ServeCodec(codec)
+}
+
 func (s *Server) RpcRegister(rcvr interface{}) {
 	rpc.Register(rcvr)
 	s.rpcEnabled = true
@@ -104,7 +117,7 @@ func (s *Server) ServeJSON(addr string) {
 			continue
 		}
 		//utils.Logger.Info(fmt.Sprintf("<CGRServer> New incoming connection: %v", conn.RemoteAddr()))
-		go jsonrpc.ServeConn(conn)
+		go s.serveCodec(jsonrpc.NewServerCodec(conn))
 	}
 
 }
@@ -137,15 +150,21 @@ func (s *Server) ServeGOB(addr string) {
 		}
 
 		//utils.Logger.Info(fmt.Sprintf("<CGRServer> New incoming connection: %v", conn.RemoteAddr()))
-		go rpc.ServeConn(conn)
+		if s.readOnly {
+			go s.serveCodec(newGobServerCodec(conn))
+		} else {
+			go rpc.ServeConn(conn)
+		}
 	}
 }
 
-func handleRequest(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	w.Header().Set("Content-Type", "application/json")
-	res := NewRPCRequest(r.Body).Call()
-	io.Copy(w, res)
+	rpcReq := NewRPCRequest(r.Body)
+	go s.serveCodec(jsonrpc.NewServerCodec(rpcReq))
+	<-rpcReq.done
+	io.Copy(w, rpcReq.rw)
 }
 
 func (s *Server) ServeHTTP(addr string, jsonRPCURL string, wsRPCURL string, useBasicAuth bool, userList map[string]string) {
@@ -153,9 +172,9 @@ func (s *Server) ServeHTTP(addr string, jsonRPCURL string, wsRPCURL string, useB
 		s.httpEnabled = true
 		Logger.Info("<HTTP> enabling handler for JSON-RPC")
 		if useBasicAuth {
-			http.HandleFunc(jsonRPCURL, use(handleRequest, basicAuth(userList)))
+			http.HandleFunc(jsonRPCURL, use(s.handleRequest, basicAuth(userList)))
 		} else {
-			http.HandleFunc(jsonRPCURL, handleRequest)
+			http.HandleFunc(jsonRPCURL, s.handleRequest)
 		}
 	}
 
@@ -163,7 +182,7 @@ func (s *Server) ServeHTTP(addr string, jsonRPCURL string, wsRPCURL string, useB
 		s.httpEnabled = true
 		Logger.Info("<HTTP> enabling handler for WebSocket connections")
 		wsHandler := websocket.Handler(func(ws *websocket.Conn) {
-			jsonrpc.ServeConn(ws)
+			s.serveCodec(jsonrpc.NewServerCodec(ws))
 		})
 		if useBasicAuth {
 			http.HandleFunc(wsRPCURL, use(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,59 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"sync"
+)
+
+// flightCall is an in-progress or finished SingleFlight call
+type flightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// SingleFlight coalesces concurrent calls sharing the same key into one execution
+// The zero value is ready to use
+type SingleFlight struct {
+	sync.Mutex
+	calls map[string]*flightCall
+}
+
+// Do executes fn once for all concurrent callers with the same key, each of them receiving its result
+func (sf *SingleFlight) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	sf.Lock()
+	if sf.calls == nil {
+		sf.calls = make(map[string]*flightCall)
+	}
+	if c, has := sf.calls[key]; has {
+		sf.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := new(flightCall)
+	c.wg.Add(1)
+	sf.calls[key] = c
+	sf.Unlock()
+	c.val, c.err = fn()
+	c.wg.Done()
+	sf.Lock()
+	delete(sf.calls, key)
+	sf.Unlock()
+	return c.val, c.err
+}